@@ -25,6 +25,17 @@ type BackendGroupSpec struct {
 	// VirtualServices defines the set of virtual services in this BackendGroup.
 	VirtualServices []VirtualServiceReference `json:"virtualservices,omitempty"`
 
+	// VirtualServiceSelector selects virtual services by labels to include in this BackendGroup,
+	// in addition to the ones listed in VirtualServices.
+	// If unspecified, no virtual services are selected by labels.
+	// +optional
+	VirtualServiceSelector *metav1.LabelSelector `json:"virtualServiceSelector,omitempty"`
+
+	// NamespaceSelector selects the namespaces VirtualServiceSelector applies to.
+	// If unspecified, only virtual services in the BackendGroup's namespace are selected.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
 	// A reference to k8s Mesh CR that this BackendGroup belongs to.
 	// The admission controller populates it using Meshes's selector, and prevents users from setting this field.
 	//
@@ -36,6 +47,16 @@ type BackendGroupSpec struct {
 
 // BackendGroupStatus defines the observed state of BackendGroup
 type BackendGroupStatus struct {
+	// VirtualServices is the resolved set of virtual services in this BackendGroup,
+	// including both listed and selected virtual services.
+	// +optional
+	VirtualServices []VirtualServiceReference `json:"virtualServices,omitempty"`
+	// VirtualServiceCount is the number of virtual services in this BackendGroup.
+	// +optional
+	VirtualServiceCount *int64 `json:"virtualServiceCount,omitempty"`
+	// The generation observed by the BackendGroup controller.
+	// +optional
+	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
 }
 
 // BackendGroupReference holds a reference to BackendGroup.appmesh.k8s.aws
@@ -52,6 +73,8 @@ type BackendGroupReference struct {
 // +kubebuilder:resource:categories=all
 // +kubebuilder:subresource:status
 // +kubebuilder:pruning:PreserveUnknownFields
+// +kubebuilder:printcolumn:name="VIRTUALSERVICES",type="integer",JSONPath=".status.virtualServiceCount",description="The number of virtual services in this BackendGroup"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // BackendGroup is the Schema for the backendgroups API
 type BackendGroup struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -280,6 +280,10 @@ type GatewayRouteStatus struct {
 	// The current GatewayRoute status.
 	// +optional
 	Conditions []GatewayRouteCondition `json:"conditions,omitempty"`
+	// WaitingFor lists the unresolved dependencies that block reconcile of this GatewayRoute, empty once all dependencies are resolved.
+	// +optional
+	WaitingFor []DependencyReference `json:"waitingFor,omitempty"`
+
 	// The generation observed by the GatewayRoute controller.
 	// +optional
 	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
@@ -83,6 +83,76 @@ type MeshSpec struct {
 	MeshOwner *string `json:"meshOwner,omitempty"`
 	// +optional
 	ServiceDiscovery *MeshServiceDiscovery `json:"meshServiceDiscovery,omitempty"`
+	// Defaults for mesh members that leave the corresponding settings unspecified.
+	// Settings specified on the member always take precedence over the mesh defaults.
+	// +optional
+	Defaults *MeshDefaults `json:"defaults,omitempty"`
+	// MembersDeletionPolicy specifies how members of the mesh are handled when the Mesh is deleted.
+	// Defaults to Block.
+	// +optional
+	MembersDeletionPolicy *MeshMembersDeletionPolicy `json:"membersDeletionPolicy,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Block;Cascade;Orphan
+type MeshMembersDeletionPolicy string
+
+const (
+	// MeshMembersDeletionPolicyBlock blocks Mesh deletion until all its members are deleted.
+	MeshMembersDeletionPolicyBlock MeshMembersDeletionPolicy = "Block"
+	// MeshMembersDeletionPolicyCascade deletes members of the Mesh in dependency order before deleting the Mesh.
+	MeshMembersDeletionPolicyCascade MeshMembersDeletionPolicy = "Cascade"
+	// MeshMembersDeletionPolicyOrphan leaves members of the Mesh, and the AppMesh Mesh they belong to, in place.
+	MeshMembersDeletionPolicyOrphan MeshMembersDeletionPolicy = "Orphan"
+)
+
+// MeshDefaults defines the defaults for members of the mesh.
+type MeshDefaults struct {
+	// Defaults for VirtualNodes in the mesh.
+	// +optional
+	VirtualNode *VirtualNodeDefaults `json:"virtualNode,omitempty"`
+	// Defaults for VirtualGateways in the mesh.
+	// +optional
+	VirtualGateway *VirtualGatewayDefaults `json:"virtualGateway,omitempty"`
+}
+
+// VirtualNodeDefaults defines the defaults for VirtualNodes in the mesh.
+type VirtualNodeDefaults struct {
+	// The default client policy, applied to VirtualNodes that don't specify backendDefaults.clientPolicy.
+	// +optional
+	BackendDefaults *MeshVirtualNodeBackendDefaults `json:"backendDefaults,omitempty"`
+	// The default listener timeout, applied to VirtualNode listeners that don't specify timeout.
+	// Only the timeout matching the listener's protocol is applied.
+	// +optional
+	ListenerTimeout *ListenerTimeout `json:"listenerTimeout,omitempty"`
+	// The default logging, applied to VirtualNodes that don't specify logging.
+	// +optional
+	Logging *Logging `json:"logging,omitempty"`
+}
+
+// VirtualGatewayDefaults defines the defaults for VirtualGateways in the mesh.
+type VirtualGatewayDefaults struct {
+	// The default client policy, applied to VirtualGateways that don't specify backendDefaults.clientPolicy.
+	// +optional
+	BackendDefaults *MeshVirtualGatewayBackendDefaults `json:"backendDefaults,omitempty"`
+	// The default logging, applied to VirtualGateways that don't specify logging.
+	// +optional
+	Logging *VirtualGatewayLogging `json:"logging,omitempty"`
+}
+
+// MeshVirtualNodeBackendDefaults defines the backend defaults for VirtualNodes in the mesh.
+// It only holds the backend defaults that mesh defaults apply.
+type MeshVirtualNodeBackendDefaults struct {
+	// The default client policy.
+	// +optional
+	ClientPolicy *ClientPolicy `json:"clientPolicy,omitempty"`
+}
+
+// MeshVirtualGatewayBackendDefaults defines the backend defaults for VirtualGateways in the mesh.
+// It only holds the backend defaults that mesh defaults apply.
+type MeshVirtualGatewayBackendDefaults struct {
+	// The default client policy.
+	// +optional
+	ClientPolicy *VirtualGatewayClientPolicy `json:"clientPolicy,omitempty"`
 }
 
 type MeshServiceDiscovery struct {
@@ -101,11 +171,27 @@ type MeshStatus struct {
 	// +optional
 	Conditions []MeshCondition `json:"conditions,omitempty"`
 
+	// MembersDeletion reports the deletion of mesh members while the Mesh is being deleted.
+	// +optional
+	MembersDeletion *MeshMembersDeletion `json:"membersDeletion,omitempty"`
+
 	// The generation observed by the Mesh controller.
 	// +optional
 	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
 }
 
+// MeshMembersDeletion tracks the deletion of mesh members by membersDeletionPolicy.
+type MeshMembersDeletion struct {
+	// Policy is the membersDeletionPolicy the Mesh is being deleted with.
+	Policy MeshMembersDeletionPolicy `json:"policy"`
+	// Deleting lists the kinds of members being deleted by Cascade policy.
+	// +optional
+	Deleting []string `json:"deleting,omitempty"`
+	// PendingMembers is the number of remaining members by kind.
+	// +optional
+	PendingMembers map[string]int64 `json:"pendingMembers,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
 // +kubebuilder:subresource:status
@@ -0,0 +1,96 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Enum=VirtualNode;VirtualService;VirtualRouter;GatewayRoute;BackendGroup
+type MeshReferenceGrantFromKind string
+
+const (
+	MeshReferenceGrantFromKindVirtualNode    MeshReferenceGrantFromKind = "VirtualNode"
+	MeshReferenceGrantFromKindVirtualService MeshReferenceGrantFromKind = "VirtualService"
+	MeshReferenceGrantFromKindVirtualRouter  MeshReferenceGrantFromKind = "VirtualRouter"
+	MeshReferenceGrantFromKindGatewayRoute   MeshReferenceGrantFromKind = "GatewayRoute"
+	MeshReferenceGrantFromKindBackendGroup   MeshReferenceGrantFromKind = "BackendGroup"
+)
+
+// +kubebuilder:validation:Enum=VirtualNode;VirtualService;VirtualRouter;BackendGroup
+type MeshReferenceGrantToKind string
+
+const (
+	MeshReferenceGrantToKindVirtualNode    MeshReferenceGrantToKind = "VirtualNode"
+	MeshReferenceGrantToKindVirtualService MeshReferenceGrantToKind = "VirtualService"
+	MeshReferenceGrantToKindVirtualRouter  MeshReferenceGrantToKind = "VirtualRouter"
+	MeshReferenceGrantToKindBackendGroup   MeshReferenceGrantToKind = "BackendGroup"
+)
+
+// MeshReferenceGrantFrom describes a set of resources that are trusted to reference resources in the grant's namespace.
+type MeshReferenceGrantFrom struct {
+	// Kind is the kind of the referencing resource.
+	Kind MeshReferenceGrantFromKind `json:"kind"`
+	// Namespace is the namespace of the referencing resource.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// MeshReferenceGrantTo describes a set of resources in the grant's namespace that may be referenced.
+type MeshReferenceGrantTo struct {
+	// Kind is the kind of the referenced resource.
+	Kind MeshReferenceGrantToKind `json:"kind"`
+	// Name is the name of the referenced resource.
+	// If unspecified, all resources of Kind in the grant's namespace may be referenced.
+	// +optional
+	Name *string `json:"name,omitempty"`
+}
+
+// MeshReferenceGrantSpec defines the desired state of MeshReferenceGrant
+type MeshReferenceGrantSpec struct {
+	// From describes the trusted namespaces and kinds that can reference the resources described in To.
+	// +kubebuilder:validation:MinItems=1
+	From []MeshReferenceGrantFrom `json:"from"`
+	// To describes the resources in this namespace that may be referenced by the resources described in From.
+	// +kubebuilder:validation:MinItems=1
+	To []MeshReferenceGrantTo `json:"to"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories=all
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// MeshReferenceGrant is the Schema for the meshreferencegrants API.
+// It is created in the namespace of the referenced resources, and permits resources in other namespaces to reference them.
+type MeshReferenceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MeshReferenceGrantSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MeshReferenceGrantList contains a list of MeshReferenceGrant
+type MeshReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MeshReferenceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MeshReferenceGrant{}, &MeshReferenceGrantList{})
+}
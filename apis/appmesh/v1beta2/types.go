@@ -249,3 +249,28 @@ const (
 	IpPreferenceIPv4 string = "IPv4_ONLY"
 	IpPreferenceIPv6 string = "IPv6_ONLY"
 )
+
+// +kubebuilder:validation:Enum=NotFound;NotActive;AWSNameNotApplied
+type DependencyReason string
+
+const (
+	// DependencyReasonNotFound means the dependency doesn't exist yet.
+	DependencyReasonNotFound DependencyReason = "NotFound"
+	// DependencyReasonNotActive means the AppMesh resource of the dependency isn't active yet.
+	DependencyReasonNotActive DependencyReason = "NotActive"
+	// DependencyReasonAWSNameNotApplied means the dependency isn't reconciled with its current awsName yet.
+	DependencyReasonAWSNameNotApplied DependencyReason = "AWSNameNotApplied"
+)
+
+// DependencyReference refers to an unresolved dependency that blocks reconcile of an object.
+type DependencyReference struct {
+	// Kind of the dependency, e.g. Mesh or VirtualNode.
+	Kind string `json:"kind"`
+	// Namespace of the dependency, empty for cluster scoped dependencies.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the dependency.
+	Name string `json:"name"`
+	// Reason why the dependency is unresolved.
+	Reason DependencyReason `json:"reason"`
+}
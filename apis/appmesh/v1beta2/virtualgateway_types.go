@@ -26,6 +26,11 @@ type VirtualGatewayConditionType string
 const (
 	// VirtualGatewayActive is True when the AppMesh VirtualGateway has been created or found via the API
 	VirtualGatewayActive VirtualGatewayConditionType = "VirtualGatewayActive"
+	// VirtualGatewaySPIRERegistered is True when the SPIFFE IDs of VirtualGateway's SDS certificates have been registered with SPIRE
+	VirtualGatewaySPIRERegistered VirtualGatewayConditionType = "SPIRERegistered"
+	// VirtualGatewayCloudMapDNSConfigSynced is False when the routing policy or DNS record types of VirtualGateway's CloudMap services
+	// differ from spec.serviceDiscovery.awsCloudMap.dnsConfig, which can't be changed on existing services
+	VirtualGatewayCloudMapDNSConfigSynced VirtualGatewayConditionType = "CloudMapDNSConfigSynced"
 )
 
 // +kubebuilder:validation:Enum=grpc;http;http2
@@ -338,6 +343,10 @@ type VirtualGatewaySpec struct {
 	// A reference to an object that represents the defaults for backend GatewayRoutes.
 	// +optional
 	BackendDefaults *VirtualGatewayBackendDefaults `json:"backendDefaults,omitempty"`
+	// The service discovery information for the virtual gateway.
+	// The controller registers pods of the virtual gateway into it, it's not part of AppMesh VirtualGateway.
+	// +optional
+	ServiceDiscovery *VirtualGatewayServiceDiscovery `json:"serviceDiscovery,omitempty"`
 
 	// A reference to k8s Mesh CR that this VirtualGateway belongs to.
 	// The admission controller populates it using Meshes's selector, and prevents users from setting this field.
@@ -348,6 +357,13 @@ type VirtualGatewaySpec struct {
 	MeshRef *MeshReference `json:"meshRef,omitempty"`
 }
 
+// VirtualGatewayServiceDiscovery refers to the service discovery of VirtualGateway pods.
+type VirtualGatewayServiceDiscovery struct {
+	// Specifies any AWS Cloud Map information for the virtual gateway.
+	// +optional
+	AWSCloudMap *AWSCloudMapServiceDiscovery `json:"awsCloudMap,omitempty"`
+}
+
 // VirtualGatewayStatus defines the observed state of VirtualGateway
 type VirtualGatewayStatus struct {
 	// VirtualGatewayARN is the AppMesh VirtualGateway object's Amazon Resource Name
@@ -356,6 +372,17 @@ type VirtualGatewayStatus struct {
 	// The current VirtualGateway status.
 	// +optional
 	Conditions []VirtualGatewayCondition `json:"conditions,omitempty"`
+	// The mesh defaults in effect for this VirtualGateway, i.e. the settings inherited from the mesh because the VirtualGateway leaves them unspecified.
+	// +optional
+	AppliedMeshDefaults *VirtualGatewayDefaults `json:"appliedMeshDefaults,omitempty"`
+
+	// WaitingFor lists the unresolved dependencies that block reconcile of this VirtualGateway, empty once all dependencies are resolved.
+	// +optional
+	WaitingFor []DependencyReference `json:"waitingFor,omitempty"`
+
+	// CloudMapListenerServices lists the CloudMap services of listeners other than the first one, which have pods registered.
+	// +optional
+	CloudMapListenerServices []CloudMapListenerService `json:"cloudMapListenerServices,omitempty"`
 
 	// The generation observed by the VirtualGateway controller.
 	// +optional
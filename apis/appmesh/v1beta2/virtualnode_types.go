@@ -312,6 +312,30 @@ type AWSCloudMapInstanceAttribute struct {
 	Value string `json:"value"`
 }
 
+// AWSCloudMapInstanceAttributeTemplate is an AWS Cloud Map service instance attribute whose value is rendered from pod metadata.
+type AWSCloudMapInstanceAttributeTemplate struct {
+	// The name of an AWS Cloud Map service instance attribute key.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=255
+	Key string `json:"key"`
+	// The Go template of the AWS Cloud Map service instance attribute value, e.g. `{{ .Labels.version }}`.
+	// The attribute is omitted if its value renders to an empty string.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	Value string `json:"value"`
+}
+
+// AWSCloudMapPodLabelsSelection selects pod labels that are exported as AWS Cloud Map service instance attributes.
+type AWSCloudMapPodLabelsSelection struct {
+	// Patterns of pod label keys to export, e.g. `app.kubernetes.io/*`.
+	// All pod labels are exported if empty.
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Patterns of pod label keys not to export. It takes precedence over include.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
 // AWSCloudMapServiceDiscovery refers to https://docs.aws.amazon.com/app-mesh/latest/APIReference/API_AwsCloudMapServiceDiscovery.html
 type AWSCloudMapServiceDiscovery struct {
 	// The name of the AWS Cloud Map namespace to use.
@@ -325,6 +349,67 @@ type AWSCloudMapServiceDiscovery struct {
 	// A string map that contains attributes with values that you can use to filter instances by any custom attribute that you specified when you registered the instance
 	// +optional
 	Attributes []AWSCloudMapInstanceAttribute `json:"attributes,omitempty"`
+	// Additional attributes of registered instances, whose values are rendered from pod metadata.
+	// Unlike attributes, they're not used to filter instances for the virtual node.
+	// +optional
+	InstanceAttributes []AWSCloudMapInstanceAttributeTemplate `json:"instanceAttributes,omitempty"`
+	// Selects pod labels that are exported as attributes of registered instances.
+	// All pod labels are exported if not specified.
+	// +optional
+	PodLabels *AWSCloudMapPodLabelsSelection `json:"podLabels,omitempty"`
+	// The DNS configuration of the AWS Cloud Map service, only used for services in DNS namespaces.
+	// +optional
+	DNSConfig *AWSCloudMapDNSConfig `json:"dnsConfig,omitempty"`
+	// The number of 30-second intervals that AWS Cloud Map waits after an instance is reported unhealthy
+	// before it changes the instance's health status, only used when custom health check is enabled.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	CustomHealthCheckFailureThreshold *int64 `json:"customHealthCheckFailureThreshold,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=A;AAAA;SRV
+type AWSCloudMapDNSRecordType string
+
+const (
+	AWSCloudMapDNSRecordTypeA    AWSCloudMapDNSRecordType = "A"
+	AWSCloudMapDNSRecordTypeAAAA AWSCloudMapDNSRecordType = "AAAA"
+	AWSCloudMapDNSRecordTypeSRV  AWSCloudMapDNSRecordType = "SRV"
+)
+
+// +kubebuilder:validation:Enum=MULTIVALUE;WEIGHTED
+type AWSCloudMapRoutingPolicy string
+
+const (
+	AWSCloudMapRoutingPolicyMultivalue AWSCloudMapRoutingPolicy = "MULTIVALUE"
+	AWSCloudMapRoutingPolicyWeighted   AWSCloudMapRoutingPolicy = "WEIGHTED"
+)
+
+// AWSCloudMapDNSConfig refers to https://docs.aws.amazon.com/cloud-map/latest/api/API_DnsConfig.html
+type AWSCloudMapDNSConfig struct {
+	// The TTL in seconds of DNS records, defaults to the controller's --cloudmap-dns-ttl.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=2147483647
+	// +optional
+	TTL *int64 `json:"ttl,omitempty"`
+	// The routing policy of DNS records, defaults to MULTIVALUE.
+	// +optional
+	RoutingPolicy *AWSCloudMapRoutingPolicy `json:"routingPolicy,omitempty"`
+	// The types of DNS records, defaults to A or AAAA by the cluster's IP family.
+	// Specify both A and AAAA for dual-stack pods.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=3
+	// +optional
+	RecordTypes []AWSCloudMapDNSRecordType `json:"recordTypes,omitempty"`
+}
+
+// CloudMapListenerService is the CloudMap service that pods are registered into for a listener other than the first one.
+// Clients that need the port of such listener discover the instances of this service, whose instance port is the listener port.
+type CloudMapListenerService struct {
+	// Port of the listener.
+	Port PortNumber `json:"port"`
+	// ServiceName is the name of CloudMap service in the namespace of serviceDiscovery, in the form of <serviceName>-<port>.
+	ServiceName string `json:"serviceName"`
 }
 
 // DNSServiceDiscovery refers to https://docs.aws.amazon.com/app-mesh/latest/APIReference/API_DnsServiceDiscovery.html
@@ -396,6 +481,11 @@ type VirtualNodeConditionType string
 const (
 	// VirtualNodeActive is True when the AppMesh VirtualNode has been created or found via the API
 	VirtualNodeActive VirtualNodeConditionType = "VirtualNodeActive"
+	// VirtualNodeSPIRERegistered is True when the SPIFFE IDs of VirtualNode's SDS certificates have been registered with SPIRE
+	VirtualNodeSPIRERegistered VirtualNodeConditionType = "SPIRERegistered"
+	// VirtualNodeCloudMapDNSConfigSynced is False when the routing policy or DNS record types of VirtualNode's CloudMap services
+	// differ from spec.serviceDiscovery.awsCloudMap.dnsConfig, which can't be changed on existing services
+	VirtualNodeCloudMapDNSConfigSynced VirtualNodeConditionType = "CloudMapDNSConfigSynced"
 )
 
 type VirtualNodeCondition struct {
@@ -465,12 +555,60 @@ type VirtualNodeStatus struct {
 	// The current VirtualNode status.
 	// +optional
 	Conditions []VirtualNodeCondition `json:"conditions,omitempty"`
+	// The mesh defaults in effect for this VirtualNode, i.e. the settings inherited from the mesh because the VirtualNode leaves them unspecified.
+	// +optional
+	AppliedMeshDefaults *VirtualNodeDefaults `json:"appliedMeshDefaults,omitempty"`
+
+	// AWSNameMigration tracks the migration from the AppMesh VirtualNode of the previous awsName, nil if no migration is in progress.
+	// +optional
+	AWSNameMigration *VirtualNodeAWSNameMigration `json:"awsNameMigration,omitempty"`
+
+	// WaitingFor lists the unresolved dependencies that block reconcile of this VirtualNode, empty once all dependencies are resolved.
+	// +optional
+	WaitingFor []DependencyReference `json:"waitingFor,omitempty"`
+
+	// CloudMapListenerServices lists the CloudMap services of listeners other than the first one, which have pods registered.
+	// +optional
+	CloudMapListenerServices []CloudMapListenerService `json:"cloudMapListenerServices,omitempty"`
 
 	// The generation observed by the VirtualNode controller.
 	// +optional
 	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=UpdatingReferences;WaitingForPods;DeletingPrevious
+type VirtualNodeAWSNameMigrationPhase string
+
+const (
+	// VirtualNodeAWSNameMigrationUpdatingReferences waits for VirtualServices and VirtualRouters to reference the AppMesh VirtualNode of the new awsName.
+	VirtualNodeAWSNameMigrationUpdatingReferences VirtualNodeAWSNameMigrationPhase = "UpdatingReferences"
+	// VirtualNodeAWSNameMigrationWaitingForPods waits for pods injected with the previous AppMesh VirtualNode to be replaced.
+	VirtualNodeAWSNameMigrationWaitingForPods VirtualNodeAWSNameMigrationPhase = "WaitingForPods"
+	// VirtualNodeAWSNameMigrationDeletingPrevious deletes the AppMesh VirtualNode of the previous awsName.
+	VirtualNodeAWSNameMigrationDeletingPrevious VirtualNodeAWSNameMigrationPhase = "DeletingPrevious"
+)
+
+// VirtualNodeAWSNameMigration tracks the migration of a VirtualNode to a new awsName.
+// The AppMesh VirtualNode of the new awsName is created first, and the previous one is deleted
+// once nothing references or uses it anymore.
+type VirtualNodeAWSNameMigration struct {
+	// PreviousAWSName is the awsName the VirtualNode is migrated from.
+	PreviousAWSName string `json:"previousAWSName"`
+	// PreviousVirtualNodeARN is the Amazon Resource Name of the AppMesh VirtualNode of the previous awsName.
+	PreviousVirtualNodeARN string `json:"previousVirtualNodeARN"`
+	// Phase is the current phase of the migration.
+	Phase VirtualNodeAWSNameMigrationPhase `json:"phase"`
+	// PendingReferences are VirtualServices and VirtualRouters that still reference the previous AppMesh VirtualNode,
+	// in the form of kind/namespace/name.
+	// +optional
+	PendingReferences []string `json:"pendingReferences,omitempty"`
+	// PendingPods is the number of pods that are still injected with the previous AppMesh VirtualNode.
+	// +optional
+	PendingPods int64 `json:"pendingPods,omitempty"`
+	// StartTime is when the migration started.
+	StartTime metav1.Time `json:"startTime"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:categories=all
 // +kubebuilder:subresource:status
@@ -326,6 +326,10 @@ type VirtualRouterStatus struct {
 	// +optional
 	Conditions []VirtualRouterCondition `json:"conditions,omitempty"`
 
+	// WaitingFor lists the unresolved dependencies that block reconcile of this VirtualRouter, empty once all dependencies are resolved.
+	// +optional
+	WaitingFor []DependencyReference `json:"waitingFor,omitempty"`
+
 	// The generation observed by the VirtualRouter controller.
 	// +optional
 	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
@@ -104,6 +104,10 @@ type VirtualServiceStatus struct {
 	// +optional
 	Conditions []VirtualServiceCondition `json:"conditions,omitempty"`
 
+	// WaitingFor lists the unresolved dependencies that block reconcile of this VirtualService, empty once all dependencies are resolved.
+	// +optional
+	WaitingFor []DependencyReference `json:"waitingFor,omitempty"`
+
 	// The generation observed by the VirtualService controller.
 	// +optional
 	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
@@ -25,6 +25,36 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSCloudMapDNSConfig) DeepCopyInto(out *AWSCloudMapDNSConfig) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RoutingPolicy != nil {
+		in, out := &in.RoutingPolicy, &out.RoutingPolicy
+		*out = new(AWSCloudMapRoutingPolicy)
+		**out = **in
+	}
+	if in.RecordTypes != nil {
+		in, out := &in.RecordTypes, &out.RecordTypes
+		*out = make([]AWSCloudMapDNSRecordType, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSCloudMapDNSConfig.
+func (in *AWSCloudMapDNSConfig) DeepCopy() *AWSCloudMapDNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSCloudMapDNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSCloudMapInstanceAttribute) DeepCopyInto(out *AWSCloudMapInstanceAttribute) {
 	*out = *in
@@ -40,6 +70,46 @@ func (in *AWSCloudMapInstanceAttribute) DeepCopy() *AWSCloudMapInstanceAttribute
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSCloudMapInstanceAttributeTemplate) DeepCopyInto(out *AWSCloudMapInstanceAttributeTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSCloudMapInstanceAttributeTemplate.
+func (in *AWSCloudMapInstanceAttributeTemplate) DeepCopy() *AWSCloudMapInstanceAttributeTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSCloudMapInstanceAttributeTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSCloudMapPodLabelsSelection) DeepCopyInto(out *AWSCloudMapPodLabelsSelection) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSCloudMapPodLabelsSelection.
+func (in *AWSCloudMapPodLabelsSelection) DeepCopy() *AWSCloudMapPodLabelsSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSCloudMapPodLabelsSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSCloudMapServiceDiscovery) DeepCopyInto(out *AWSCloudMapServiceDiscovery) {
 	*out = *in
@@ -48,6 +118,26 @@ func (in *AWSCloudMapServiceDiscovery) DeepCopyInto(out *AWSCloudMapServiceDisco
 		*out = make([]AWSCloudMapInstanceAttribute, len(*in))
 		copy(*out, *in)
 	}
+	if in.InstanceAttributes != nil {
+		in, out := &in.InstanceAttributes, &out.InstanceAttributes
+		*out = make([]AWSCloudMapInstanceAttributeTemplate, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = new(AWSCloudMapPodLabelsSelection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(AWSCloudMapDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomHealthCheckFailureThreshold != nil {
+		in, out := &in.CustomHealthCheckFailureThreshold, &out.CustomHealthCheckFailureThreshold
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSCloudMapServiceDiscovery.
@@ -122,7 +212,7 @@ func (in *BackendGroup) DeepCopyInto(out *BackendGroup) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendGroup.
@@ -205,6 +295,16 @@ func (in *BackendGroupSpec) DeepCopyInto(out *BackendGroupSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VirtualServiceSelector != nil {
+		in, out := &in.VirtualServiceSelector, &out.VirtualServiceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MeshRef != nil {
 		in, out := &in.MeshRef, &out.MeshRef
 		*out = new(MeshReference)
@@ -225,6 +325,23 @@ func (in *BackendGroupSpec) DeepCopy() *BackendGroupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackendGroupStatus) DeepCopyInto(out *BackendGroupStatus) {
 	*out = *in
+	if in.VirtualServices != nil {
+		in, out := &in.VirtualServices, &out.VirtualServices
+		*out = make([]VirtualServiceReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VirtualServiceCount != nil {
+		in, out := &in.VirtualServiceCount, &out.VirtualServiceCount
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ObservedGeneration != nil {
+		in, out := &in.ObservedGeneration, &out.ObservedGeneration
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendGroupStatus.
@@ -313,6 +430,21 @@ func (in *ClientTLSCertificate) DeepCopy() *ClientTLSCertificate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudMapListenerService) DeepCopyInto(out *CloudMapListenerService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudMapListenerService.
+func (in *CloudMapListenerService) DeepCopy() *CloudMapListenerService {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudMapListenerService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DNSServiceDiscovery) DeepCopyInto(out *DNSServiceDiscovery) {
 	*out = *in
@@ -333,6 +465,21 @@ func (in *DNSServiceDiscovery) DeepCopy() *DNSServiceDiscovery {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependencyReference) DeepCopyInto(out *DependencyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyReference.
+func (in *DependencyReference) DeepCopy() *DependencyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Duration) DeepCopyInto(out *Duration) {
 	*out = *in
@@ -953,6 +1100,11 @@ func (in *GatewayRouteStatus) DeepCopyInto(out *GatewayRouteStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.WaitingFor != nil {
+		in, out := &in.WaitingFor, &out.WaitingFor
+		*out = make([]DependencyReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.ObservedGeneration != nil {
 		in, out := &in.ObservedGeneration, &out.ObservedGeneration
 		*out = new(int64)
@@ -1872,6 +2024,31 @@ func (in *MeshCondition) DeepCopy() *MeshCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshDefaults) DeepCopyInto(out *MeshDefaults) {
+	*out = *in
+	if in.VirtualNode != nil {
+		in, out := &in.VirtualNode, &out.VirtualNode
+		*out = new(VirtualNodeDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VirtualGateway != nil {
+		in, out := &in.VirtualGateway, &out.VirtualGateway
+		*out = new(VirtualGatewayDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshDefaults.
+func (in *MeshDefaults) DeepCopy() *MeshDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MeshList) DeepCopyInto(out *MeshList) {
 	*out = *in
@@ -1904,6 +2081,33 @@ func (in *MeshList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshMembersDeletion) DeepCopyInto(out *MeshMembersDeletion) {
+	*out = *in
+	if in.Deleting != nil {
+		in, out := &in.Deleting, &out.Deleting
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingMembers != nil {
+		in, out := &in.PendingMembers, &out.PendingMembers
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshMembersDeletion.
+func (in *MeshMembersDeletion) DeepCopy() *MeshMembersDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshMembersDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MeshReference) DeepCopyInto(out *MeshReference) {
 	*out = *in
@@ -1919,6 +2123,126 @@ func (in *MeshReference) DeepCopy() *MeshReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshReferenceGrant) DeepCopyInto(out *MeshReferenceGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshReferenceGrant.
+func (in *MeshReferenceGrant) DeepCopy() *MeshReferenceGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshReferenceGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MeshReferenceGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshReferenceGrantFrom) DeepCopyInto(out *MeshReferenceGrantFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshReferenceGrantFrom.
+func (in *MeshReferenceGrantFrom) DeepCopy() *MeshReferenceGrantFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshReferenceGrantFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshReferenceGrantList) DeepCopyInto(out *MeshReferenceGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MeshReferenceGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshReferenceGrantList.
+func (in *MeshReferenceGrantList) DeepCopy() *MeshReferenceGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshReferenceGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MeshReferenceGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshReferenceGrantSpec) DeepCopyInto(out *MeshReferenceGrantSpec) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]MeshReferenceGrantFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]MeshReferenceGrantTo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshReferenceGrantSpec.
+func (in *MeshReferenceGrantSpec) DeepCopy() *MeshReferenceGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshReferenceGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshReferenceGrantTo) DeepCopyInto(out *MeshReferenceGrantTo) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshReferenceGrantTo.
+func (in *MeshReferenceGrantTo) DeepCopy() *MeshReferenceGrantTo {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshReferenceGrantTo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MeshServiceDiscovery) DeepCopyInto(out *MeshServiceDiscovery) {
 	*out = *in
@@ -1967,6 +2291,16 @@ func (in *MeshSpec) DeepCopyInto(out *MeshSpec) {
 		*out = new(MeshServiceDiscovery)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(MeshDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MembersDeletionPolicy != nil {
+		in, out := &in.MembersDeletionPolicy, &out.MembersDeletionPolicy
+		*out = new(MeshMembersDeletionPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshSpec.
@@ -1994,6 +2328,11 @@ func (in *MeshStatus) DeepCopyInto(out *MeshStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MembersDeletion != nil {
+		in, out := &in.MembersDeletion, &out.MembersDeletion
+		*out = new(MeshMembersDeletion)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ObservedGeneration != nil {
 		in, out := &in.ObservedGeneration, &out.ObservedGeneration
 		*out = new(int64)
@@ -2011,6 +2350,46 @@ func (in *MeshStatus) DeepCopy() *MeshStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshVirtualGatewayBackendDefaults) DeepCopyInto(out *MeshVirtualGatewayBackendDefaults) {
+	*out = *in
+	if in.ClientPolicy != nil {
+		in, out := &in.ClientPolicy, &out.ClientPolicy
+		*out = new(VirtualGatewayClientPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshVirtualGatewayBackendDefaults.
+func (in *MeshVirtualGatewayBackendDefaults) DeepCopy() *MeshVirtualGatewayBackendDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshVirtualGatewayBackendDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshVirtualNodeBackendDefaults) DeepCopyInto(out *MeshVirtualNodeBackendDefaults) {
+	*out = *in
+	if in.ClientPolicy != nil {
+		in, out := &in.ClientPolicy, &out.ClientPolicy
+		*out = new(ClientPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshVirtualNodeBackendDefaults.
+func (in *MeshVirtualNodeBackendDefaults) DeepCopy() *MeshVirtualNodeBackendDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshVirtualNodeBackendDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OutlierDetection) DeepCopyInto(out *OutlierDetection) {
 	*out = *in
@@ -2585,6 +2964,31 @@ func (in *VirtualGatewayConnectionPool) DeepCopy() *VirtualGatewayConnectionPool
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualGatewayDefaults) DeepCopyInto(out *VirtualGatewayDefaults) {
+	*out = *in
+	if in.BackendDefaults != nil {
+		in, out := &in.BackendDefaults, &out.BackendDefaults
+		*out = new(MeshVirtualGatewayBackendDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(VirtualGatewayLogging)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualGatewayDefaults.
+func (in *VirtualGatewayDefaults) DeepCopy() *VirtualGatewayDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualGatewayDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualGatewayFileAccessLog) DeepCopyInto(out *VirtualGatewayFileAccessLog) {
 	*out = *in
@@ -2900,6 +3304,26 @@ func (in *VirtualGatewayReference) DeepCopy() *VirtualGatewayReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualGatewayServiceDiscovery) DeepCopyInto(out *VirtualGatewayServiceDiscovery) {
+	*out = *in
+	if in.AWSCloudMap != nil {
+		in, out := &in.AWSCloudMap, &out.AWSCloudMap
+		*out = new(AWSCloudMapServiceDiscovery)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualGatewayServiceDiscovery.
+func (in *VirtualGatewayServiceDiscovery) DeepCopy() *VirtualGatewayServiceDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualGatewayServiceDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualGatewaySpec) DeepCopyInto(out *VirtualGatewaySpec) {
 	*out = *in
@@ -2940,6 +3364,11 @@ func (in *VirtualGatewaySpec) DeepCopyInto(out *VirtualGatewaySpec) {
 		*out = new(VirtualGatewayBackendDefaults)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ServiceDiscovery != nil {
+		in, out := &in.ServiceDiscovery, &out.ServiceDiscovery
+		*out = new(VirtualGatewayServiceDiscovery)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MeshRef != nil {
 		in, out := &in.MeshRef, &out.MeshRef
 		*out = new(MeshReference)
@@ -2972,6 +3401,21 @@ func (in *VirtualGatewayStatus) DeepCopyInto(out *VirtualGatewayStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AppliedMeshDefaults != nil {
+		in, out := &in.AppliedMeshDefaults, &out.AppliedMeshDefaults
+		*out = new(VirtualGatewayDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WaitingFor != nil {
+		in, out := &in.WaitingFor, &out.WaitingFor
+		*out = make([]DependencyReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.CloudMapListenerServices != nil {
+		in, out := &in.CloudMapListenerServices, &out.CloudMapListenerServices
+		*out = make([]CloudMapListenerService, len(*in))
+		copy(*out, *in)
+	}
 	if in.ObservedGeneration != nil {
 		in, out := &in.ObservedGeneration, &out.ObservedGeneration
 		*out = new(int64)
@@ -3122,6 +3566,27 @@ func (in *VirtualNode) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNodeAWSNameMigration) DeepCopyInto(out *VirtualNodeAWSNameMigration) {
+	*out = *in
+	if in.PendingReferences != nil {
+		in, out := &in.PendingReferences, &out.PendingReferences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNodeAWSNameMigration.
+func (in *VirtualNodeAWSNameMigration) DeepCopy() *VirtualNodeAWSNameMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNodeAWSNameMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualNodeCondition) DeepCopyInto(out *VirtualNodeCondition) {
 	*out = *in
@@ -3186,6 +3651,36 @@ func (in *VirtualNodeConnectionPool) DeepCopy() *VirtualNodeConnectionPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualNodeDefaults) DeepCopyInto(out *VirtualNodeDefaults) {
+	*out = *in
+	if in.BackendDefaults != nil {
+		in, out := &in.BackendDefaults, &out.BackendDefaults
+		*out = new(MeshVirtualNodeBackendDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ListenerTimeout != nil {
+		in, out := &in.ListenerTimeout, &out.ListenerTimeout
+		*out = new(ListenerTimeout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(Logging)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualNodeDefaults.
+func (in *VirtualNodeDefaults) DeepCopy() *VirtualNodeDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualNodeDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualNodeList) DeepCopyInto(out *VirtualNodeList) {
 	*out = *in
@@ -3344,6 +3839,26 @@ func (in *VirtualNodeStatus) DeepCopyInto(out *VirtualNodeStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AppliedMeshDefaults != nil {
+		in, out := &in.AppliedMeshDefaults, &out.AppliedMeshDefaults
+		*out = new(VirtualNodeDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSNameMigration != nil {
+		in, out := &in.AWSNameMigration, &out.AWSNameMigration
+		*out = new(VirtualNodeAWSNameMigration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WaitingFor != nil {
+		in, out := &in.WaitingFor, &out.WaitingFor
+		*out = make([]DependencyReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.CloudMapListenerServices != nil {
+		in, out := &in.CloudMapListenerServices, &out.CloudMapListenerServices
+		*out = make([]CloudMapListenerService, len(*in))
+		copy(*out, *in)
+	}
 	if in.ObservedGeneration != nil {
 		in, out := &in.ObservedGeneration, &out.ObservedGeneration
 		*out = new(int64)
@@ -3569,6 +4084,11 @@ func (in *VirtualRouterStatus) DeepCopyInto(out *VirtualRouterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.WaitingFor != nil {
+		in, out := &in.WaitingFor, &out.WaitingFor
+		*out = make([]DependencyReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.ObservedGeneration != nil {
 		in, out := &in.ObservedGeneration, &out.ObservedGeneration
 		*out = new(int64)
@@ -3794,6 +4314,11 @@ func (in *VirtualServiceStatus) DeepCopyInto(out *VirtualServiceStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.WaitingFor != nil {
+		in, out := &in.WaitingFor, &out.WaitingFor
+		*out = make([]DependencyReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.ObservedGeneration != nil {
 		in, out := &in.ObservedGeneration, &out.ObservedGeneration
 		*out = new(int64)
@@ -0,0 +1,96 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/backendgroup"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+)
+
+// NewBackendGroupReconciler constructs new backendGroupReconciler
+func NewBackendGroupReconciler(
+	k8sClient client.Client,
+	bgResManager backendgroup.ResourceManager,
+	log logr.Logger,
+	recorder record.EventRecorder) *backendGroupReconciler {
+	return &backendGroupReconciler{
+		k8sClient:                                  k8sClient,
+		bgResManager:                               bgResManager,
+		enqueueRequestsForVirtualServiceEvents:     backendgroup.NewEnqueueRequestsForVirtualServiceEvents(k8sClient, log),
+		enqueueRequestsForNamespaceEvents:          backendgroup.NewEnqueueRequestsForNamespaceEvents(k8sClient, log),
+		enqueueRequestsForMeshReferenceGrantEvents: references.NewEnqueueRequestsForMeshReferenceGrantEvents(k8sClient, appmesh.MeshReferenceGrantFromKindBackendGroup, &appmesh.BackendGroupList{}, log),
+		log:      log,
+		recorder: recorder,
+	}
+}
+
+// backendGroupReconciler reconciles a BackendGroup object
+type backendGroupReconciler struct {
+	k8sClient    client.Client
+	bgResManager backendgroup.ResourceManager
+
+	enqueueRequestsForVirtualServiceEvents     handler.EventHandler
+	enqueueRequestsForNamespaceEvents          handler.EventHandler
+	enqueueRequestsForMeshReferenceGrantEvents handler.EventHandler
+	log                                        logr.Logger
+	recorder                                   record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=backendgroups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=backendgroups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *backendGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
+}
+
+func (r *backendGroupReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&appmesh.BackendGroup{}).
+		Watches(&appmesh.VirtualService{}, r.enqueueRequestsForVirtualServiceEvents).
+		Watches(&corev1.Namespace{}, r.enqueueRequestsForNamespaceEvents).
+		Watches(&appmesh.MeshReferenceGrant{}, r.enqueueRequestsForMeshReferenceGrantEvents).
+		WithOptions(optionsFactory.ControllerOptions("backendgroup", backendGroupMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("backendgroup", r))
+}
+
+func (r *backendGroupReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
+	bg := &appmesh.BackendGroup{}
+	if err := r.k8sClient.Get(ctx, req.NamespacedName, bg); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !bg.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	if err := r.bgResManager.Reconcile(ctx, bg); err != nil {
+		tracing.RecordEvent(ctx, r.recorder, bg, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		return err
+	}
+	return nil
+}
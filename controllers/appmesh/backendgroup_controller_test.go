@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	mock_backendgroup "github.com/aws/aws-app-mesh-controller-for-k8s/mocks/aws-app-mesh-controller-for-k8s/pkg/backendgroup"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"testing"
+)
+
+func Test_backendGroupReconciler_reconcile(t *testing.T) {
+	type fields struct {
+		Reconcile func(ctx context.Context, bg *appmesh.BackendGroup) error
+	}
+	type args struct {
+		bg *appmesh.BackendGroup
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "backendGroup with reconcile error",
+			fields: fields{
+				Reconcile: func(ctx context.Context, ref *appmesh.BackendGroup) error {
+					return errors.New("Test Exception")
+				},
+			},
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "bg-1",
+					},
+					Status: appmesh.BackendGroupStatus{},
+				},
+			},
+			want:    "",
+			wantErr: errors.New("Test Exception"),
+		},
+		{
+			name: "backendGroup reconciled",
+			fields: fields{
+				Reconcile: func(ctx context.Context, ref *appmesh.BackendGroup) error {
+					return nil
+				},
+			},
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "bg-1",
+					},
+				},
+			},
+			want:    "",
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			bgResManager := mock_backendgroup.NewMockResourceManager(ctrl)
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			err := k8sClient.Create(ctx, tt.args.bg.DeepCopy())
+			assert.NoError(t, err)
+
+			recorder := record.NewFakeRecorder(3)
+
+			r := &backendGroupReconciler{
+				k8sClient:    k8sClient,
+				bgResManager: bgResManager,
+				log:          logr.New(&log.NullLogSink{}),
+				recorder:     recorder,
+			}
+
+			if tt.fields.Reconcile != nil {
+				bgResManager.EXPECT().Reconcile(gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.Reconcile)
+			}
+
+			err = r.reconcile(ctx, reconcile.Request{
+				NamespacedName: k8s.NamespacedName(tt.args.bg),
+			})
+			if tt.wantErr != nil {
+				assert.Greater(t, len(recorder.Events), 0)
+				assert.Equal(t, "Warning ReconcileError "+tt.wantErr.Error(), <-recorder.Events)
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 0, len(recorder.Events))
+			}
+		})
+	}
+}
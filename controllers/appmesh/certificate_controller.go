@@ -0,0 +1,143 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/certmanager"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// virtualNodeCertificateReconciler reconciles cert-manager Certificate for a VirtualNode
+type virtualNodeCertificateReconciler struct {
+	k8sClient                              client.Client
+	certResManager                         certmanager.ResourceManager
+	enqueueRequestsForVirtualServiceEvents handler.EventHandler
+	enqueueRequestsForVirtualRouterEvents  handler.EventHandler
+	log                                    logr.Logger
+	recorder                               record.EventRecorder
+}
+
+func NewVirtualNodeCertificateReconciler(k8sClient client.Client, certResManager certmanager.ResourceManager, log logr.Logger, recorder record.EventRecorder) *virtualNodeCertificateReconciler {
+	return &virtualNodeCertificateReconciler{
+		k8sClient:                              k8sClient,
+		certResManager:                         certResManager,
+		enqueueRequestsForVirtualServiceEvents: certmanager.NewEnqueueRequestsForVirtualServiceEvents(k8sClient, log),
+		enqueueRequestsForVirtualRouterEvents:  certmanager.NewEnqueueRequestsForVirtualRouterEvents(log),
+		log:                                    log,
+		recorder:                               recorder,
+	}
+}
+
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualnodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualservices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualrouters,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *virtualNodeCertificateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
+}
+
+func (r *virtualNodeCertificateReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("virtualNodeCertificate").
+		For(&appmesh.VirtualNode{}).
+		Owns(certmanager.NewCertificate()).
+		Watches(&appmesh.VirtualService{}, r.enqueueRequestsForVirtualServiceEvents).
+		Watches(&appmesh.VirtualRouter{}, r.enqueueRequestsForVirtualRouterEvents).
+		WithOptions(optionsFactory.ControllerOptions("virtualNodeCertificate", virtualNodeMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("virtualNodeCertificate", r))
+}
+
+func (r *virtualNodeCertificateReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
+	vn := &appmesh.VirtualNode{}
+	if err := r.k8sClient.Get(ctx, req.NamespacedName, vn); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	// Certificate is garbage collected via ownerReference once virtualNode is deleted.
+	if !vn.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	if err := r.certResManager.ReconcileVirtualNode(ctx, vn); err != nil {
+		tracing.RecordEvent(ctx, r.recorder, vn, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		return err
+	}
+	return nil
+}
+
+// virtualGatewayCertificateReconciler reconciles cert-manager Certificate for a VirtualGateway
+type virtualGatewayCertificateReconciler struct {
+	k8sClient                            client.Client
+	certResManager                       certmanager.ResourceManager
+	enqueueRequestsForGatewayRouteEvents handler.EventHandler
+	log                                  logr.Logger
+	recorder                             record.EventRecorder
+}
+
+func NewVirtualGatewayCertificateReconciler(k8sClient client.Client, certResManager certmanager.ResourceManager, log logr.Logger, recorder record.EventRecorder) *virtualGatewayCertificateReconciler {
+	return &virtualGatewayCertificateReconciler{
+		k8sClient:                            k8sClient,
+		certResManager:                       certResManager,
+		enqueueRequestsForGatewayRouteEvents: certmanager.NewEnqueueRequestsForGatewayRouteEvents(log),
+		log:                                  log,
+		recorder:                             recorder,
+	}
+}
+
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualgateways,verbs=get;list;watch
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=gatewayroutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualservices,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *virtualGatewayCertificateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
+}
+
+func (r *virtualGatewayCertificateReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("virtualGatewayCertificate").
+		For(&appmesh.VirtualGateway{}).
+		Owns(certmanager.NewCertificate()).
+		Watches(&appmesh.GatewayRoute{}, r.enqueueRequestsForGatewayRouteEvents).
+		WithOptions(optionsFactory.ControllerOptions("virtualGatewayCertificate", virtualGatewayMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("virtualGatewayCertificate", r))
+}
+
+func (r *virtualGatewayCertificateReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
+	vg := &appmesh.VirtualGateway{}
+	if err := r.k8sClient.Get(ctx, req.NamespacedName, vg); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	// Certificate is garbage collected via ownerReference once virtualGateway is deleted.
+	if !vg.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	if err := r.certResManager.ReconcileVirtualGateway(ctx, vg); err != nil {
+		tracing.RecordEvent(ctx, r.recorder, vg, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		return err
+	}
+	return nil
+}
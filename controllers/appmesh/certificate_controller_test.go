@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	mock_certmanager "github.com/aws/aws-app-mesh-controller-for-k8s/mocks/aws-app-mesh-controller-for-k8s/pkg/certmanager"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_virtualNodeCertificateReconciler_reconcile(t *testing.T) {
+	tests := []struct {
+		name                 string
+		reconcileVirtualNode func(ctx context.Context, vn *appmesh.VirtualNode) error
+		wantErr              error
+	}{
+		{
+			name: "virtualNode certificate reconciled",
+			reconcileVirtualNode: func(ctx context.Context, vn *appmesh.VirtualNode) error {
+				return nil
+			},
+		},
+		{
+			name: "virtualNode certificate with reconcile error",
+			reconcileVirtualNode: func(ctx context.Context, vn *appmesh.VirtualNode) error {
+				return errors.New("Test Exception")
+			},
+			wantErr: errors.New("Test Exception"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			certResManager := mock_certmanager.NewMockResourceManager(ctrl)
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			vn := &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1"},
+			}
+			err := k8sClient.Create(ctx, vn.DeepCopy())
+			assert.NoError(t, err)
+
+			recorder := record.NewFakeRecorder(3)
+			r := &virtualNodeCertificateReconciler{
+				k8sClient:      k8sClient,
+				certResManager: certResManager,
+				log:            logr.New(&log.NullLogSink{}),
+				recorder:       recorder,
+			}
+			certResManager.EXPECT().ReconcileVirtualNode(gomock.Any(), gomock.Any()).DoAndReturn(tt.reconcileVirtualNode)
+
+			err = r.reconcile(ctx, reconcile.Request{NamespacedName: k8s.NamespacedName(vn)})
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				assert.Equal(t, "Warning ReconcileError "+tt.wantErr.Error(), <-recorder.Events)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 0, len(recorder.Events))
+			}
+		})
+	}
+}
+
+func Test_virtualGatewayCertificateReconciler_reconcile(t *testing.T) {
+	tests := []struct {
+		name                    string
+		reconcileVirtualGateway func(ctx context.Context, vg *appmesh.VirtualGateway) error
+		wantErr                 error
+	}{
+		{
+			name: "virtualGateway certificate reconciled",
+			reconcileVirtualGateway: func(ctx context.Context, vg *appmesh.VirtualGateway) error {
+				return nil
+			},
+		},
+		{
+			name: "virtualGateway certificate with reconcile error",
+			reconcileVirtualGateway: func(ctx context.Context, vg *appmesh.VirtualGateway) error {
+				return errors.New("Test Exception")
+			},
+			wantErr: errors.New("Test Exception"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			certResManager := mock_certmanager.NewMockResourceManager(ctrl)
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			vg := &appmesh.VirtualGateway{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vg-1"},
+			}
+			err := k8sClient.Create(ctx, vg.DeepCopy())
+			assert.NoError(t, err)
+
+			recorder := record.NewFakeRecorder(3)
+			r := &virtualGatewayCertificateReconciler{
+				k8sClient:      k8sClient,
+				certResManager: certResManager,
+				log:            logr.New(&log.NullLogSink{}),
+				recorder:       recorder,
+			}
+			certResManager.EXPECT().ReconcileVirtualGateway(gomock.Any(), gomock.Any()).DoAndReturn(tt.reconcileVirtualGateway)
+
+			err = r.reconcile(ctx, reconcile.Request{NamespacedName: k8s.NamespacedName(vg)})
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				assert.Equal(t, "Warning ReconcileError "+tt.wantErr.Error(), <-recorder.Events)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 0, len(recorder.Events))
+			}
+		})
+	}
+}
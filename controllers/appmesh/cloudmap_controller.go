@@ -21,13 +21,16 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/cloudmap"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // CloudMapReconciler reconciles a VirtualNode pod instance to CloudMap Service
@@ -37,16 +40,19 @@ type cloudMapReconciler struct {
 	finalizerManager            k8s.FinalizerManager
 	cloudMapResourceManager     cloudmap.ResourceManager
 	enqueueRequestsForPodEvents handler.EventHandler
+	healthSource                cloudmap.HealthSource
 	recorder                    record.EventRecorder
-	podEventNotificationChan    <-chan k8s.GenericEvent
+	endpointSource              string
 }
 
-// NewCloudMapReconciler that can respond to pod events (Create/Update/Delete) via notification channels
+// NewCloudMapReconciler that can respond to pod events (Create/Update/Delete) from the manager's cache,
+// and to EndpointSlice events when endpointSource is endpointslice.
 func NewCloudMapReconciler(
 	k8sClient client.Client,
 	finalizerManager k8s.FinalizerManager,
 	cloudMapResourceManager cloudmap.ResourceManager,
-	podEventNotificationChan <-chan k8s.GenericEvent,
+	healthSource cloudmap.HealthSource,
+	endpointSource string,
 	log logr.Logger,
 	recorder record.EventRecorder) *cloudMapReconciler {
 	return &cloudMapReconciler{
@@ -54,30 +60,42 @@ func NewCloudMapReconciler(
 		log:                         log,
 		finalizerManager:            finalizerManager,
 		cloudMapResourceManager:     cloudMapResourceManager,
-		enqueueRequestsForPodEvents: cloudmap.NewEnqueueRequestsForPodEvents(k8sClient, log),
+		enqueueRequestsForPodEvents: cloudmap.NewEnqueueRequestsForPodEvents(k8sClient, healthSource, log),
+		healthSource:                healthSource,
 		recorder:                    recorder,
-		podEventNotificationChan:    podEventNotificationChan,
+		endpointSource:              endpointSource,
 	}
 }
 
 // +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualnodes,verbs=get;list;watch
-// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualnodes/status,verbs=get
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualnodes/status,verbs=get;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=pods/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *cloudMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	return runtime.HandleReconcileError(r.reconcile(ctx, req), r.log)
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
 }
 
-func (r *cloudMapReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+func (r *cloudMapReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
+	builder := ctrl.NewControllerManagedBy(mgr).
 		Named("cloudMap").
-		For(&appmesh.VirtualNode{}).
-		WatchesRawSource(&k8s.NotificationChannel{Source: r.podEventNotificationChan, Handler: r.enqueueRequestsForPodEvents}).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 3}).
-		Complete(r)
+		For(&appmesh.VirtualNode{})
+	// pods are watched with either endpointSource, as the readiness of pods held back by the aws-cloudmap-healthy readiness gate
+	// changes without any change to their endpoints.
+	builder = builder.Watches(&corev1.Pod{}, r.enqueueRequestsForPodEvents)
+	if events := r.healthSource.HealthChangedEvents(cloudmap.MemberKindVirtualNode); events != nil {
+		builder = builder.WatchesRawSource(source.Channel(events, &handler.EnqueueRequestForObject{}))
+	}
+	if r.endpointSource == cloudmap.EndpointSourceEndpointSlice {
+		builder = builder.Watches(&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(cloudmap.VirtualNodeRequestsForEndpointSlice(r.k8sClient, r.log)))
+	}
+	return builder.
+		WithOptions(optionsFactory.ControllerOptions("cloudMap", virtualNodeMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("cloudMap", r))
 }
 
 func (r *cloudMapReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
@@ -90,7 +108,11 @@ func (r *cloudMapReconciler) reconcile(ctx context.Context, req ctrl.Request) er
 		return r.cleanupCloudMapResources(ctx, vNode)
 	}
 	if err := r.reconcileVirtualNodeWithCloudMap(ctx, vNode); err != nil {
-		r.recorder.Event(vNode, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		// requeue for health resync isn't an error worth an event.
+		var requeueAfterErr *runtime.RequeueAfterError
+		if !errors.As(err, &requeueAfterErr) {
+			tracing.RecordEvent(ctx, r.recorder, vNode, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		}
 		return err
 	}
 	return nil
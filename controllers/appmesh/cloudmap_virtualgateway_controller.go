@@ -0,0 +1,141 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/cloudmap"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// cloudMapVirtualGatewayReconciler reconciles a VirtualGateway pod instance to CloudMap Service
+type cloudMapVirtualGatewayReconciler struct {
+	k8sClient                   client.Client
+	log                         logr.Logger
+	finalizerManager            k8s.FinalizerManager
+	cloudMapResourceManager     cloudmap.ResourceManager
+	enqueueRequestsForPodEvents handler.EventHandler
+	healthSource                cloudmap.HealthSource
+	recorder                    record.EventRecorder
+	endpointSource              string
+}
+
+// NewCloudMapVirtualGatewayReconciler that can respond to pod events (Create/Update/Delete) from the manager's cache,
+// and to EndpointSlice events when endpointSource is endpointslice.
+func NewCloudMapVirtualGatewayReconciler(
+	k8sClient client.Client,
+	finalizerManager k8s.FinalizerManager,
+	cloudMapResourceManager cloudmap.ResourceManager,
+	healthSource cloudmap.HealthSource,
+	endpointSource string,
+	log logr.Logger,
+	recorder record.EventRecorder) *cloudMapVirtualGatewayReconciler {
+	return &cloudMapVirtualGatewayReconciler{
+		k8sClient:                   k8sClient,
+		log:                         log,
+		finalizerManager:            finalizerManager,
+		cloudMapResourceManager:     cloudMapResourceManager,
+		enqueueRequestsForPodEvents: cloudmap.NewEnqueueVirtualGatewayRequestsForPodEvents(k8sClient, healthSource, log),
+		healthSource:                healthSource,
+		recorder:                    recorder,
+		endpointSource:              endpointSource,
+	}
+}
+
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualgateways,verbs=get;list;watch
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualgateways/status,verbs=get;patch
+
+func (r *cloudMapVirtualGatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
+}
+
+func (r *cloudMapVirtualGatewayReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		Named("cloudMapVirtualGateway").
+		For(&appmesh.VirtualGateway{})
+	// pods are watched with either endpointSource, as the readiness of pods held back by the aws-cloudmap-healthy readiness gate
+	// changes without any change to their endpoints.
+	builder = builder.Watches(&corev1.Pod{}, r.enqueueRequestsForPodEvents)
+	if events := r.healthSource.HealthChangedEvents(cloudmap.MemberKindVirtualGateway); events != nil {
+		builder = builder.WatchesRawSource(source.Channel(events, &handler.EnqueueRequestForObject{}))
+	}
+	if r.endpointSource == cloudmap.EndpointSourceEndpointSlice {
+		builder = builder.Watches(&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(cloudmap.VirtualGatewayRequestsForEndpointSlice(r.k8sClient, r.log)))
+	}
+	return builder.
+		WithOptions(optionsFactory.ControllerOptions("cloudMapVirtualGateway", virtualGatewayMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("cloudMapVirtualGateway", r))
+}
+
+func (r *cloudMapVirtualGatewayReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
+	vg := &appmesh.VirtualGateway{}
+	if err := r.k8sClient.Get(ctx, req.NamespacedName, vg); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if !vg.DeletionTimestamp.IsZero() {
+		return r.cleanupCloudMapResources(ctx, vg)
+	}
+	if err := r.reconcileVirtualGatewayWithCloudMap(ctx, vg); err != nil {
+		// requeue for health resync isn't an error worth an event.
+		var requeueAfterErr *runtime.RequeueAfterError
+		if !errors.As(err, &requeueAfterErr) {
+			tracing.RecordEvent(ctx, r.recorder, vg, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *cloudMapVirtualGatewayReconciler) reconcileVirtualGatewayWithCloudMap(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	if vg.Spec.ServiceDiscovery == nil || vg.Spec.ServiceDiscovery.AWSCloudMap == nil {
+		return nil
+	}
+	if err := r.finalizerManager.AddFinalizers(ctx, vg, k8s.FinalizerAWSCloudMapResources); err != nil {
+		return err
+	}
+	if err := r.cloudMapResourceManager.ReconcileVirtualGateway(ctx, vg); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cloudMapVirtualGatewayReconciler) cleanupCloudMapResources(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	if k8s.HasFinalizer(vg, k8s.FinalizerAWSCloudMapResources) {
+		if vg.Spec.ServiceDiscovery != nil && vg.Spec.ServiceDiscovery.AWSCloudMap != nil {
+			if err := r.cloudMapResourceManager.CleanupVirtualGateway(ctx, vg); err != nil {
+				return err
+			}
+		}
+		if err := r.finalizerManager.RemoveFinalizers(ctx, vg, k8s.FinalizerAWSCloudMapResources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -21,13 +21,15 @@ import (
 
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/gatewayroute"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
@@ -37,30 +39,37 @@ import (
 func NewGatewayRouteReconciler(
 	k8sClient client.Client,
 	finalizerManager k8s.FinalizerManager,
+	referencesIndexer references.ObjectReferenceIndexer,
 	grResManager gatewayroute.ResourceManager,
 	log logr.Logger,
 	recorder record.EventRecorder) *gatewayRouteReconciler {
 	return &gatewayRouteReconciler{
-		k8sClient:                              k8sClient,
-		finalizerManager:                       finalizerManager,
-		grResManager:                           grResManager,
-		enqueueRequestsForMeshEvents:           gatewayroute.NewEnqueueRequestsForMeshEvents(k8sClient, log),
-		enqueueRequestsForVirtualGatewayEvents: gatewayroute.NewEnqueueRequestsForVirtualGatewayEvents(k8sClient, log),
-		log:                                    log,
-		recorder:                               recorder,
+		k8sClient:                                  k8sClient,
+		finalizerManager:                           finalizerManager,
+		referencesIndexer:                          referencesIndexer,
+		grResManager:                               grResManager,
+		enqueueRequestsForMeshEvents:               gatewayroute.NewEnqueueRequestsForMeshEvents(k8sClient, log),
+		enqueueRequestsForVirtualGatewayEvents:     gatewayroute.NewEnqueueRequestsForVirtualGatewayEvents(k8sClient, log),
+		enqueueRequestsForVirtualServiceEvents:     gatewayroute.NewEnqueueRequestsForVirtualServiceEvents(referencesIndexer, log),
+		enqueueRequestsForMeshReferenceGrantEvents: references.NewEnqueueRequestsForMeshReferenceGrantEvents(k8sClient, appmesh.MeshReferenceGrantFromKindGatewayRoute, &appmesh.GatewayRouteList{}, log),
+		log:      log,
+		recorder: recorder,
 	}
 }
 
 // gatewayRouteReconciler reconciles a GatewayRoute object
 type gatewayRouteReconciler struct {
-	k8sClient        client.Client
-	finalizerManager k8s.FinalizerManager
-	grResManager     gatewayroute.ResourceManager
-
-	enqueueRequestsForMeshEvents           handler.EventHandler
-	enqueueRequestsForVirtualGatewayEvents handler.EventHandler
-	log                                    logr.Logger
-	recorder                               record.EventRecorder
+	k8sClient         client.Client
+	finalizerManager  k8s.FinalizerManager
+	referencesIndexer references.ObjectReferenceIndexer
+	grResManager      gatewayroute.ResourceManager
+
+	enqueueRequestsForMeshEvents               handler.EventHandler
+	enqueueRequestsForVirtualGatewayEvents     handler.EventHandler
+	enqueueRequestsForVirtualServiceEvents     handler.EventHandler
+	enqueueRequestsForMeshReferenceGrantEvents handler.EventHandler
+	log                                        logr.Logger
+	recorder                                   record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=gatewayroutes,verbs=get;list;watch;create;update;patch;delete
@@ -68,16 +77,23 @@ type gatewayRouteReconciler struct {
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *gatewayRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	return runtime.HandleReconcileError(r.reconcile(ctx, req), r.log)
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
 }
 
-func (r *gatewayRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *gatewayRouteReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
+	if err := r.referencesIndexer.Setup(&appmesh.GatewayRoute{}, map[string]references.ObjectReferenceIndexFunc{
+		gatewayroute.ReferenceKindVirtualService: gatewayroute.VirtualServiceReferenceIndexFunc,
+	}); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appmesh.GatewayRoute{}).
 		Watches(&appmesh.Mesh{}, r.enqueueRequestsForMeshEvents).
 		Watches(&appmesh.VirtualGateway{}, r.enqueueRequestsForVirtualGatewayEvents).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 3}).
-		Complete(r)
+		Watches(&appmesh.VirtualService{}, r.enqueueRequestsForVirtualServiceEvents).
+		Watches(&appmesh.MeshReferenceGrant{}, r.enqueueRequestsForMeshReferenceGrantEvents).
+		WithOptions(optionsFactory.ControllerOptions("gatewayroute", gatewayRouteMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("gatewayroute", r))
 }
 
 func (r *gatewayRouteReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
@@ -89,7 +105,11 @@ func (r *gatewayRouteReconciler) reconcile(ctx context.Context, req ctrl.Request
 		return r.cleanupGatewayRoute(ctx, gr)
 	}
 	if err := r.reconcileGatewayRoute(ctx, gr); err != nil {
-		r.recorder.Event(gr, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		// requeue while waiting for dependencies isn't an error worth an event, they're reported in status.waitingFor instead.
+		var requeueAfterErr *runtime.RequeueAfterError
+		if !errors.As(err, &requeueAfterErr) {
+			tracing.RecordEvent(ctx, r.recorder, gr, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		}
 		return err
 	}
 	return nil
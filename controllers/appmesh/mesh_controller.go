@@ -22,6 +22,7 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -63,13 +64,14 @@ type meshReconciler struct {
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *meshReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	return runtime.HandleReconcileError(r.reconcile(ctx, req), r.log)
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
 }
 
-func (r *meshReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *meshReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appmesh.Mesh{}).
-		Complete(r)
+		WithOptions(optionsFactory.ControllerOptions("mesh", meshMeshResolver())).
+		Complete(optionsFactory.Reconciler("mesh", r))
 }
 
 func (r *meshReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
@@ -81,7 +83,7 @@ func (r *meshReconciler) reconcile(ctx context.Context, req ctrl.Request) error
 		return r.cleanupMesh(ctx, ms)
 	}
 	if err := r.reconcileMesh(ctx, ms); err != nil {
-		r.recorder.Event(ms, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		tracing.RecordEvent(ctx, r.recorder, ms, corev1.EventTypeWarning, "ReconcileError", err.Error())
 		return err
 	}
 	return nil
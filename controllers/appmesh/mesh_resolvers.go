@@ -0,0 +1,95 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// meshMeshResolver resolves the mesh of mesh requests, which is the mesh itself.
+func meshMeshResolver() runtime.MeshResolver {
+	return func(req ctrl.Request) (string, bool) {
+		return req.Name, true
+	}
+}
+
+// virtualNodeMeshResolver resolves the mesh of virtualNode requests by their meshRef.
+func virtualNodeMeshResolver(k8sClient client.Client) runtime.MeshResolver {
+	return func(req ctrl.Request) (string, bool) {
+		vn := &appmesh.VirtualNode{}
+		if err := k8sClient.Get(context.Background(), req.NamespacedName, vn); err != nil || vn.Spec.MeshRef == nil {
+			return "", false
+		}
+		return vn.Spec.MeshRef.Name, true
+	}
+}
+
+// virtualServiceMeshResolver resolves the mesh of virtualService requests by their meshRef.
+func virtualServiceMeshResolver(k8sClient client.Client) runtime.MeshResolver {
+	return func(req ctrl.Request) (string, bool) {
+		vs := &appmesh.VirtualService{}
+		if err := k8sClient.Get(context.Background(), req.NamespacedName, vs); err != nil || vs.Spec.MeshRef == nil {
+			return "", false
+		}
+		return vs.Spec.MeshRef.Name, true
+	}
+}
+
+// virtualRouterMeshResolver resolves the mesh of virtualRouter requests by their meshRef.
+func virtualRouterMeshResolver(k8sClient client.Client) runtime.MeshResolver {
+	return func(req ctrl.Request) (string, bool) {
+		vr := &appmesh.VirtualRouter{}
+		if err := k8sClient.Get(context.Background(), req.NamespacedName, vr); err != nil || vr.Spec.MeshRef == nil {
+			return "", false
+		}
+		return vr.Spec.MeshRef.Name, true
+	}
+}
+
+// virtualGatewayMeshResolver resolves the mesh of virtualGateway requests by their meshRef.
+func virtualGatewayMeshResolver(k8sClient client.Client) runtime.MeshResolver {
+	return func(req ctrl.Request) (string, bool) {
+		vg := &appmesh.VirtualGateway{}
+		if err := k8sClient.Get(context.Background(), req.NamespacedName, vg); err != nil || vg.Spec.MeshRef == nil {
+			return "", false
+		}
+		return vg.Spec.MeshRef.Name, true
+	}
+}
+
+// gatewayRouteMeshResolver resolves the mesh of gatewayRoute requests by their meshRef.
+func gatewayRouteMeshResolver(k8sClient client.Client) runtime.MeshResolver {
+	return func(req ctrl.Request) (string, bool) {
+		gr := &appmesh.GatewayRoute{}
+		if err := k8sClient.Get(context.Background(), req.NamespacedName, gr); err != nil || gr.Spec.MeshRef == nil {
+			return "", false
+		}
+		return gr.Spec.MeshRef.Name, true
+	}
+}
+
+// backendGroupMeshResolver resolves the mesh of backendGroup requests by their meshRef.
+func backendGroupMeshResolver(k8sClient client.Client) runtime.MeshResolver {
+	return func(req ctrl.Request) (string, bool) {
+		bg := &appmesh.BackendGroup{}
+		if err := k8sClient.Get(context.Background(), req.NamespacedName, bg); err != nil || bg.Spec.MeshRef == nil {
+			return "", false
+		}
+		return bg.Spec.MeshRef.Name, true
+	}
+}
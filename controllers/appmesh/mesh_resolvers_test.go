@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_meshMeshResolver(t *testing.T) {
+	mesh, ok := meshMeshResolver()(ctrl.Request{NamespacedName: types.NamespacedName{Name: "my-mesh"}})
+	assert.True(t, ok)
+	assert.Equal(t, "my-mesh", mesh)
+}
+
+func Test_virtualNodeMeshResolver(t *testing.T) {
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+	for _, vn := range []*appmesh.VirtualNode{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn-with-mesh"},
+			Spec: appmesh.VirtualNodeSpec{
+				MeshRef: &appmesh.MeshReference{Name: "my-mesh", UID: "uid-1"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn-without-mesh"},
+		},
+	} {
+		assert.NoError(t, k8sClient.Create(context.Background(), vn))
+	}
+
+	tests := []struct {
+		name     string
+		vnName   string
+		wantMesh string
+		wantOK   bool
+	}{
+		{
+			name:     "virtualNode with meshRef",
+			vnName:   "vn-with-mesh",
+			wantMesh: "my-mesh",
+			wantOK:   true,
+		},
+		{
+			name:   "virtualNode without meshRef",
+			vnName: "vn-without-mesh",
+		},
+		{
+			name:   "virtualNode not found",
+			vnName: "vn-not-found",
+		},
+	}
+	resolver := virtualNodeMeshResolver(k8sClient)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mesh, ok := resolver(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: tt.vnName}})
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantMesh, mesh)
+		})
+	}
+}
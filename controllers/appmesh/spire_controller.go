@@ -0,0 +1,187 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/spire"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// virtualNodeSPIREReconciler reconciles SPIRE registrations for a VirtualNode
+type virtualNodeSPIREReconciler struct {
+	k8sClient        client.Client
+	finalizerManager k8s.FinalizerManager
+	regManager       spire.RegistrationManager
+	log              logr.Logger
+	recorder         record.EventRecorder
+}
+
+func NewVirtualNodeSPIREReconciler(k8sClient client.Client, finalizerManager k8s.FinalizerManager, regManager spire.RegistrationManager, log logr.Logger, recorder record.EventRecorder) *virtualNodeSPIREReconciler {
+	return &virtualNodeSPIREReconciler{
+		k8sClient:        k8sClient,
+		finalizerManager: finalizerManager,
+		regManager:       regManager,
+		log:              log,
+		recorder:         recorder,
+	}
+}
+
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualnodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *virtualNodeSPIREReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
+}
+
+func (r *virtualNodeSPIREReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("virtualNodeSPIRE").
+		For(&appmesh.VirtualNode{}).
+		Watches(spire.NewClusterSPIFFEID(), handler.EnqueueRequestsFromMapFunc(spire.OwnerRequestsForClusterSPIFFEID(spire.OwnerKindVirtualNode))).
+		WithOptions(optionsFactory.ControllerOptions("virtualNodeSPIRE", virtualNodeMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("virtualNodeSPIRE", r))
+}
+
+func (r *virtualNodeSPIREReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
+	vn := &appmesh.VirtualNode{}
+	if err := r.k8sClient.Get(ctx, req.NamespacedName, vn); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !vn.DeletionTimestamp.IsZero() {
+		return r.cleanupVirtualNode(ctx, vn)
+	}
+	if err := r.reconcileVirtualNode(ctx, vn); err != nil {
+		tracing.RecordEvent(ctx, r.recorder, vn, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *virtualNodeSPIREReconciler) reconcileVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error {
+	requiresRegistration := len(spire.VirtualNodeSPIFFEIDs(vn)) != 0
+	if requiresRegistration {
+		if err := r.finalizerManager.AddFinalizers(ctx, vn, k8s.FinalizerSPIRERegistrations); err != nil {
+			return err
+		}
+	}
+	if err := r.regManager.ReconcileVirtualNode(ctx, vn); err != nil {
+		return err
+	}
+	if !requiresRegistration && k8s.HasFinalizer(vn, k8s.FinalizerSPIRERegistrations) {
+		return r.finalizerManager.RemoveFinalizers(ctx, vn, k8s.FinalizerSPIRERegistrations)
+	}
+	return nil
+}
+
+func (r *virtualNodeSPIREReconciler) cleanupVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error {
+	if k8s.HasFinalizer(vn, k8s.FinalizerSPIRERegistrations) {
+		if err := r.regManager.CleanupVirtualNode(ctx, vn); err != nil {
+			return err
+		}
+		if err := r.finalizerManager.RemoveFinalizers(ctx, vn, k8s.FinalizerSPIRERegistrations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// virtualGatewaySPIREReconciler reconciles SPIRE registrations for a VirtualGateway
+type virtualGatewaySPIREReconciler struct {
+	k8sClient        client.Client
+	finalizerManager k8s.FinalizerManager
+	regManager       spire.RegistrationManager
+	log              logr.Logger
+	recorder         record.EventRecorder
+}
+
+func NewVirtualGatewaySPIREReconciler(k8sClient client.Client, finalizerManager k8s.FinalizerManager, regManager spire.RegistrationManager, log logr.Logger, recorder record.EventRecorder) *virtualGatewaySPIREReconciler {
+	return &virtualGatewaySPIREReconciler{
+		k8sClient:        k8sClient,
+		finalizerManager: finalizerManager,
+		regManager:       regManager,
+		log:              log,
+		recorder:         recorder,
+	}
+}
+
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualgateways,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *virtualGatewaySPIREReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
+}
+
+func (r *virtualGatewaySPIREReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("virtualGatewaySPIRE").
+		For(&appmesh.VirtualGateway{}).
+		Watches(spire.NewClusterSPIFFEID(), handler.EnqueueRequestsFromMapFunc(spire.OwnerRequestsForClusterSPIFFEID(spire.OwnerKindVirtualGateway))).
+		WithOptions(optionsFactory.ControllerOptions("virtualGatewaySPIRE", virtualGatewayMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("virtualGatewaySPIRE", r))
+}
+
+func (r *virtualGatewaySPIREReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
+	vg := &appmesh.VirtualGateway{}
+	if err := r.k8sClient.Get(ctx, req.NamespacedName, vg); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !vg.DeletionTimestamp.IsZero() {
+		return r.cleanupVirtualGateway(ctx, vg)
+	}
+	if err := r.reconcileVirtualGateway(ctx, vg); err != nil {
+		tracing.RecordEvent(ctx, r.recorder, vg, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *virtualGatewaySPIREReconciler) reconcileVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	requiresRegistration := len(spire.VirtualGatewaySPIFFEIDs(vg)) != 0
+	if requiresRegistration {
+		if err := r.finalizerManager.AddFinalizers(ctx, vg, k8s.FinalizerSPIRERegistrations); err != nil {
+			return err
+		}
+	}
+	if err := r.regManager.ReconcileVirtualGateway(ctx, vg); err != nil {
+		return err
+	}
+	if !requiresRegistration && k8s.HasFinalizer(vg, k8s.FinalizerSPIRERegistrations) {
+		return r.finalizerManager.RemoveFinalizers(ctx, vg, k8s.FinalizerSPIRERegistrations)
+	}
+	return nil
+}
+
+func (r *virtualGatewaySPIREReconciler) cleanupVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	if k8s.HasFinalizer(vg, k8s.FinalizerSPIRERegistrations) {
+		if err := r.regManager.CleanupVirtualGateway(ctx, vg); err != nil {
+			return err
+		}
+		if err := r.finalizerManager.RemoveFinalizers(ctx, vg, k8s.FinalizerSPIRERegistrations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
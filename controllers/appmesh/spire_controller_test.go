@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	mock_spire "github.com/aws/aws-app-mesh-controller-for-k8s/mocks/aws-app-mesh-controller-for-k8s/pkg/spire"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_virtualNodeSPIREReconciler_reconcile(t *testing.T) {
+	sdsListener := appmesh.Listener{
+		PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"},
+		TLS: &appmesh.ListenerTLS{
+			Certificate: appmesh.ListenerTLSCertificate{
+				SDS: &appmesh.ListenerTLSSDSCertificate{SecretName: aws.String("spiffe://mesh.local/app")},
+			},
+			Mode: appmesh.ListenerTLSModeStrict,
+		},
+	}
+	tests := []struct {
+		name                 string
+		vn                   *appmesh.VirtualNode
+		deleteVN             bool
+		reconcileVirtualNode func(ctx context.Context, vn *appmesh.VirtualNode) error
+		cleanupVirtualNode   func(ctx context.Context, vn *appmesh.VirtualNode) error
+		wantFinalizers       []string
+		wantVNDeleted        bool
+		wantErr              error
+	}{
+		{
+			name: "virtualNode using SDS gets finalizer",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1"},
+				Spec:       appmesh.VirtualNodeSpec{Listeners: []appmesh.Listener{sdsListener}},
+			},
+			reconcileVirtualNode: func(ctx context.Context, vn *appmesh.VirtualNode) error {
+				return nil
+			},
+			wantFinalizers: []string{k8s.FinalizerSPIRERegistrations},
+		},
+		{
+			name: "virtualNode no longer using SDS loses finalizer",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1", Finalizers: []string{k8s.FinalizerSPIRERegistrations}},
+			},
+			reconcileVirtualNode: func(ctx context.Context, vn *appmesh.VirtualNode) error {
+				return nil
+			},
+			wantFinalizers: nil,
+		},
+		{
+			name: "virtualNode with registration error",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1"},
+				Spec:       appmesh.VirtualNodeSpec{Listeners: []appmesh.Listener{sdsListener}},
+			},
+			reconcileVirtualNode: func(ctx context.Context, vn *appmesh.VirtualNode) error {
+				return errors.New("Test Exception")
+			},
+			wantFinalizers: []string{k8s.FinalizerSPIRERegistrations},
+			wantErr:        errors.New("Test Exception"),
+		},
+		{
+			name: "deleted virtualNode gets deregistered",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1", Finalizers: []string{k8s.FinalizerSPIRERegistrations}},
+				Spec:       appmesh.VirtualNodeSpec{Listeners: []appmesh.Listener{sdsListener}},
+			},
+			deleteVN: true,
+			cleanupVirtualNode: func(ctx context.Context, vn *appmesh.VirtualNode) error {
+				return nil
+			},
+			wantVNDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			regManager := mock_spire.NewMockRegistrationManager(ctrl)
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			err := k8sClient.Create(ctx, tt.vn.DeepCopy())
+			assert.NoError(t, err)
+			if tt.deleteVN {
+				assert.NoError(t, k8sClient.Delete(ctx, tt.vn.DeepCopy()))
+			}
+
+			recorder := record.NewFakeRecorder(3)
+			r := &virtualNodeSPIREReconciler{
+				k8sClient:        k8sClient,
+				finalizerManager: k8s.NewDefaultFinalizerManager(k8sClient, logr.New(&log.NullLogSink{})),
+				regManager:       regManager,
+				log:              logr.New(&log.NullLogSink{}),
+				recorder:         recorder,
+			}
+			if tt.reconcileVirtualNode != nil {
+				regManager.EXPECT().ReconcileVirtualNode(gomock.Any(), gomock.Any()).DoAndReturn(tt.reconcileVirtualNode)
+			}
+			if tt.cleanupVirtualNode != nil {
+				regManager.EXPECT().CleanupVirtualNode(gomock.Any(), gomock.Any()).DoAndReturn(tt.cleanupVirtualNode)
+			}
+
+			err = r.reconcile(ctx, reconcile.Request{NamespacedName: k8s.NamespacedName(tt.vn)})
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				assert.Equal(t, "Warning ReconcileError "+tt.wantErr.Error(), <-recorder.Events)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			gotVN := &appmesh.VirtualNode{}
+			err = k8sClient.Get(ctx, k8s.NamespacedName(tt.vn), gotVN)
+			if tt.wantVNDeleted {
+				assert.True(t, apierrors.IsNotFound(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantFinalizers, gotVN.Finalizers)
+			}
+		})
+	}
+}
+
+func Test_virtualGatewaySPIREReconciler_reconcile(t *testing.T) {
+	sdsListener := appmesh.VirtualGatewayListener{
+		PortMapping: appmesh.VirtualGatewayPortMapping{Port: 8443, Protocol: "http"},
+		TLS: &appmesh.VirtualGatewayListenerTLS{
+			Certificate: appmesh.VirtualGatewayListenerTLSCertificate{
+				SDS: &appmesh.VirtualGatewayListenerTLSSDSCertificate{SecretName: aws.String("spiffe://mesh.local/gateway")},
+			},
+			Mode: appmesh.VirtualGatewayListenerTLSModeStrict,
+		},
+	}
+	tests := []struct {
+		name                    string
+		vg                      *appmesh.VirtualGateway
+		deleteVG                bool
+		reconcileVirtualGateway func(ctx context.Context, vg *appmesh.VirtualGateway) error
+		cleanupVirtualGateway   func(ctx context.Context, vg *appmesh.VirtualGateway) error
+		wantFinalizers          []string
+		wantVGDeleted           bool
+		wantErr                 error
+	}{
+		{
+			name: "virtualGateway using SDS gets finalizer",
+			vg: &appmesh.VirtualGateway{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vg-1"},
+				Spec:       appmesh.VirtualGatewaySpec{Listeners: []appmesh.VirtualGatewayListener{sdsListener}},
+			},
+			reconcileVirtualGateway: func(ctx context.Context, vg *appmesh.VirtualGateway) error {
+				return nil
+			},
+			wantFinalizers: []string{k8s.FinalizerSPIRERegistrations},
+		},
+		{
+			name: "virtualGateway with registration error",
+			vg: &appmesh.VirtualGateway{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vg-1"},
+				Spec:       appmesh.VirtualGatewaySpec{Listeners: []appmesh.VirtualGatewayListener{sdsListener}},
+			},
+			reconcileVirtualGateway: func(ctx context.Context, vg *appmesh.VirtualGateway) error {
+				return errors.New("Test Exception")
+			},
+			wantFinalizers: []string{k8s.FinalizerSPIRERegistrations},
+			wantErr:        errors.New("Test Exception"),
+		},
+		{
+			name: "deleted virtualGateway gets deregistered",
+			vg: &appmesh.VirtualGateway{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vg-1", Finalizers: []string{k8s.FinalizerSPIRERegistrations}},
+				Spec:       appmesh.VirtualGatewaySpec{Listeners: []appmesh.VirtualGatewayListener{sdsListener}},
+			},
+			deleteVG: true,
+			cleanupVirtualGateway: func(ctx context.Context, vg *appmesh.VirtualGateway) error {
+				return nil
+			},
+			wantVGDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			regManager := mock_spire.NewMockRegistrationManager(ctrl)
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+
+			err := k8sClient.Create(ctx, tt.vg.DeepCopy())
+			assert.NoError(t, err)
+			if tt.deleteVG {
+				assert.NoError(t, k8sClient.Delete(ctx, tt.vg.DeepCopy()))
+			}
+
+			recorder := record.NewFakeRecorder(3)
+			r := &virtualGatewaySPIREReconciler{
+				k8sClient:        k8sClient,
+				finalizerManager: k8s.NewDefaultFinalizerManager(k8sClient, logr.New(&log.NullLogSink{})),
+				regManager:       regManager,
+				log:              logr.New(&log.NullLogSink{}),
+				recorder:         recorder,
+			}
+			if tt.reconcileVirtualGateway != nil {
+				regManager.EXPECT().ReconcileVirtualGateway(gomock.Any(), gomock.Any()).DoAndReturn(tt.reconcileVirtualGateway)
+			}
+			if tt.cleanupVirtualGateway != nil {
+				regManager.EXPECT().CleanupVirtualGateway(gomock.Any(), gomock.Any()).DoAndReturn(tt.cleanupVirtualGateway)
+			}
+
+			err = r.reconcile(ctx, reconcile.Request{NamespacedName: k8s.NamespacedName(tt.vg)})
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+				assert.Equal(t, "Warning ReconcileError "+tt.wantErr.Error(), <-recorder.Events)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			gotVG := &appmesh.VirtualGateway{}
+			err = k8sClient.Get(ctx, k8s.NamespacedName(tt.vg), gotVG)
+			if tt.wantVGDeleted {
+				assert.True(t, apierrors.IsNotFound(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantFinalizers, gotVG.Finalizers)
+			}
+		})
+	}
+}
@@ -21,13 +21,14 @@ import (
 
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualgateway"
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
@@ -69,15 +70,15 @@ type virtualGatewayReconciler struct {
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *virtualGatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	return runtime.HandleReconcileError(r.reconcile(ctx, req), r.log)
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
 }
 
-func (r *virtualGatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *virtualGatewayReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appmesh.VirtualGateway{}).
 		Watches(&appmesh.Mesh{}, r.enqueueRequestsForMeshEvents).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 3}).
-		Complete(r)
+		WithOptions(optionsFactory.ControllerOptions("virtualgateway", virtualGatewayMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("virtualgateway", r))
 }
 
 func (r *virtualGatewayReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
@@ -89,7 +90,11 @@ func (r *virtualGatewayReconciler) reconcile(ctx context.Context, req ctrl.Reque
 		return r.cleanupVirtualGateway(ctx, vg)
 	}
 	if err := r.reconcileVirtualGateway(ctx, vg); err != nil {
-		r.recorder.Event(vg, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		// requeue while waiting for dependencies isn't an error worth an event, they're reported in status.waitingFor instead.
+		var requeueAfterErr *runtime.RequeueAfterError
+		if !errors.As(err, &requeueAfterErr) {
+			tracing.RecordEvent(ctx, r.recorder, vg, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		}
 		return err
 	}
 	return nil
@@ -14,16 +14,20 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
@@ -38,15 +42,16 @@ func NewVirtualNodeReconciler(
 	recorder record.EventRecorder,
 	enableBackendGroups bool) *virtualNodeReconciler {
 	return &virtualNodeReconciler{
-		k8sClient:                              k8sClient,
-		finalizerManager:                       finalizerManager,
-		vnResManager:                           vnResManager,
-		enqueueRequestsForMeshEvents:           virtualnode.NewEnqueueRequestsForMeshEvents(k8sClient, log),
-		enqueueRequestsForBackendGroupEvents:   virtualnode.NewEnqueueRequestsForBackendGroupEvents(k8sClient, log),
-		enqueueRequestsForVirtualServiceEvents: virtualnode.NewEnqueueRequestsForVirtualServiceEvents(k8sClient, log),
-		log:                                    log,
-		recorder:                               recorder,
-		enableBackendGroups:                    enableBackendGroups,
+		k8sClient:                                  k8sClient,
+		finalizerManager:                           finalizerManager,
+		vnResManager:                               vnResManager,
+		enqueueRequestsForMeshEvents:               virtualnode.NewEnqueueRequestsForMeshEvents(k8sClient, log),
+		enqueueRequestsForBackendGroupEvents:       virtualnode.NewEnqueueRequestsForBackendGroupEvents(k8sClient, log),
+		enqueueRequestsForVirtualServiceEvents:     virtualnode.NewEnqueueRequestsForVirtualServiceEvents(k8sClient, log),
+		enqueueRequestsForMeshReferenceGrantEvents: references.NewEnqueueRequestsForMeshReferenceGrantEvents(k8sClient, appmesh.MeshReferenceGrantFromKindVirtualNode, &appmesh.VirtualNodeList{}, log),
+		log:                 log,
+		recorder:            recorder,
+		enableBackendGroups: enableBackendGroups,
 	}
 }
 
@@ -56,11 +61,12 @@ type virtualNodeReconciler struct {
 	finalizerManager k8s.FinalizerManager
 	vnResManager     virtualnode.ResourceManager
 
-	enqueueRequestsForMeshEvents           handler.EventHandler
-	enqueueRequestsForBackendGroupEvents   handler.EventHandler
-	enqueueRequestsForVirtualServiceEvents handler.EventHandler
-	log                                    logr.Logger
-	recorder                               record.EventRecorder
+	enqueueRequestsForMeshEvents               handler.EventHandler
+	enqueueRequestsForBackendGroupEvents       handler.EventHandler
+	enqueueRequestsForVirtualServiceEvents     handler.EventHandler
+	enqueueRequestsForMeshReferenceGrantEvents handler.EventHandler
+	log                                        logr.Logger
+	recorder                                   record.EventRecorder
 
 	enableBackendGroups bool
 }
@@ -72,24 +78,26 @@ type virtualNodeReconciler struct {
 // +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=backendgroups/status,verbs=get;update;patch
 
 func (r *virtualNodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	return runtime.HandleReconcileError(r.reconcile(ctx, req), r.log)
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
 }
 
-func (r *virtualNodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *virtualNodeReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
 	if r.enableBackendGroups {
 		return ctrl.NewControllerManagedBy(mgr).
 			For(&appmesh.VirtualNode{}).
 			Watches(&appmesh.Mesh{}, r.enqueueRequestsForMeshEvents).
 			Watches(&appmesh.BackendGroup{}, r.enqueueRequestsForBackendGroupEvents).
 			Watches(&appmesh.VirtualService{}, r.enqueueRequestsForVirtualServiceEvents).
-			WithOptions(controller.Options{MaxConcurrentReconciles: 3}).
-			Complete(r)
+			Watches(&appmesh.MeshReferenceGrant{}, r.enqueueRequestsForMeshReferenceGrantEvents).
+			WithOptions(optionsFactory.ControllerOptions("virtualnode", virtualNodeMeshResolver(r.k8sClient))).
+			Complete(optionsFactory.Reconciler("virtualnode", r))
 	} else {
 		return ctrl.NewControllerManagedBy(mgr).
 			For(&appmesh.VirtualNode{}).
 			Watches(&appmesh.Mesh{}, r.enqueueRequestsForMeshEvents).
-			WithOptions(controller.Options{MaxConcurrentReconciles: 3}).
-			Complete(r)
+			Watches(&appmesh.MeshReferenceGrant{}, r.enqueueRequestsForMeshReferenceGrantEvents).
+			WithOptions(optionsFactory.ControllerOptions("virtualnode", virtualNodeMeshResolver(r.k8sClient))).
+			Complete(optionsFactory.Reconciler("virtualnode", r))
 	}
 }
 
@@ -102,7 +110,11 @@ func (r *virtualNodeReconciler) reconcile(ctx context.Context, req ctrl.Request)
 		return r.cleanupVirtualNode(ctx, vn)
 	}
 	if err := r.reconcileVirtualNode(ctx, vn); err != nil {
-		r.recorder.Event(vn, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		// requeue while waiting for dependencies, or while awsName migration waits for references or pods isn't an error worth an event.
+		var requeueAfterErr *runtime.RequeueAfterError
+		if !errors.As(err, &requeueAfterErr) {
+			tracing.RecordEvent(ctx, r.recorder, vn, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		}
 		return err
 	}
 	return nil
@@ -112,10 +124,23 @@ func (r *virtualNodeReconciler) reconcileVirtualNode(ctx context.Context, vn *ap
 	if err := r.finalizerManager.AddFinalizers(ctx, vn, k8s.FinalizerAWSAppMeshResources); err != nil {
 		return err
 	}
-	if err := r.vnResManager.Reconcile(ctx, vn); err != nil {
-		return err
+	previousMigration := vn.Status.AWSNameMigration.DeepCopy()
+	err := r.vnResManager.Reconcile(ctx, vn)
+	r.recordAWSNameMigrationEvents(ctx, vn, previousMigration)
+	return err
+}
+
+// recordAWSNameMigrationEvents records events when vn's awsName migration starts or completes during reconcile.
+func (r *virtualNodeReconciler) recordAWSNameMigrationEvents(ctx context.Context, vn *appmesh.VirtualNode, previousMigration *appmesh.VirtualNodeAWSNameMigration) {
+	migration := vn.Status.AWSNameMigration
+	if previousMigration == nil && migration != nil {
+		tracing.RecordEvent(ctx, r.recorder, vn, corev1.EventTypeNormal, "AWSNameMigrationStarted",
+			fmt.Sprintf("migrating from awsName %s to %s", migration.PreviousAWSName, aws.StringValue(vn.Spec.AWSName)))
+	}
+	if previousMigration != nil && migration == nil {
+		tracing.RecordEvent(ctx, r.recorder, vn, corev1.EventTypeNormal, "AWSNameMigrationCompleted",
+			fmt.Sprintf("migrated from awsName %s to %s", previousMigration.PreviousAWSName, aws.StringValue(vn.Spec.AWSName)))
 	}
-	return nil
 }
 
 func (r *virtualNodeReconciler) cleanupVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error {
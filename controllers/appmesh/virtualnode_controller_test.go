@@ -5,6 +5,8 @@ import (
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	mock_virtualnode "github.com/aws/aws-app-mesh-controller-for-k8s/mocks/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	appmeshruntime "github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -17,6 +19,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"testing"
+	"time"
 )
 
 func Test_virtualNodeReconciler_reconcile(t *testing.T) {
@@ -27,11 +30,12 @@ func Test_virtualNodeReconciler_reconcile(t *testing.T) {
 		vn *appmesh.VirtualNode
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    string
-		wantErr error
+		name       string
+		fields     fields
+		args       args
+		want       string
+		wantErr    error
+		wantEvents []string
 	}{
 		{
 			name: "virtualNode with reconcile error",
@@ -48,8 +52,59 @@ func Test_virtualNodeReconciler_reconcile(t *testing.T) {
 					Status: appmesh.VirtualNodeStatus{},
 				},
 			},
-			want:    "",
-			wantErr: errors.New("Test Exception"),
+			want:       "",
+			wantErr:    errors.New("Test Exception"),
+			wantEvents: []string{"Warning ReconcileError Test Exception"},
+		},
+		{
+			name: "virtualNode with awsName migration started",
+			fields: fields{
+				Reconcile: func(ctx context.Context, vn *appmesh.VirtualNode) error {
+					vn.Status.AWSNameMigration = &appmesh.VirtualNodeAWSNameMigration{
+						PreviousAWSName: "vn-1_old",
+						Phase:           appmesh.VirtualNodeAWSNameMigrationUpdatingReferences,
+					}
+					return appmeshruntime.NewRequeueAfterError(errors.New("waiting for VirtualService/ns/vs-1 to stop referencing virtualNode vn-1_old"), time.Second)
+				},
+			},
+			args: args{
+				vn: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "vn-1",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("vn-1_new"),
+					},
+				},
+			},
+			wantErr:    errors.New("waiting for VirtualService/ns/vs-1 to stop referencing virtualNode vn-1_old"),
+			wantEvents: []string{"Normal AWSNameMigrationStarted migrating from awsName vn-1_old to vn-1_new"},
+		},
+		{
+			name: "virtualNode with awsName migration completed",
+			fields: fields{
+				Reconcile: func(ctx context.Context, vn *appmesh.VirtualNode) error {
+					vn.Status.AWSNameMigration = nil
+					return nil
+				},
+			},
+			args: args{
+				vn: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "vn-1",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("vn-1_new"),
+					},
+					Status: appmesh.VirtualNodeStatus{
+						AWSNameMigration: &appmesh.VirtualNodeAWSNameMigration{
+							PreviousAWSName: "vn-1_old",
+							Phase:           appmesh.VirtualNodeAWSNameMigrationDeletingPrevious,
+						},
+					},
+				},
+			},
+			wantEvents: []string{"Normal AWSNameMigrationCompleted migrated from awsName vn-1_old to vn-1_new"},
 		},
 	}
 	for _, tt := range tests {
@@ -86,9 +141,13 @@ func Test_virtualNodeReconciler_reconcile(t *testing.T) {
 				NamespacedName: k8s.NamespacedName(tt.args.vn),
 			})
 			if tt.wantErr != nil {
-				assert.Greater(t, len(recorder.Events), 0)
-				assert.Equal(t, "Warning ReconcileError "+tt.wantErr.Error(), <-recorder.Events)
 				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, len(tt.wantEvents), len(recorder.Events))
+			for _, wantEvent := range tt.wantEvents {
+				assert.Equal(t, wantEvent, <-recorder.Events)
 			}
 		})
 	}
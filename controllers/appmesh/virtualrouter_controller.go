@@ -22,13 +22,14 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualrouter"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -44,14 +45,15 @@ func NewVirtualRouterReconciler(
 	log logr.Logger,
 	recorder record.EventRecorder) *virtualRouterReconciler {
 	return &virtualRouterReconciler{
-		k8sClient:                           k8sClient,
-		finalizerManager:                    finalizerManager,
-		referencesIndexer:                   referencesIndexer,
-		vrResManager:                        vrResManager,
-		enqueueRequestsForMeshEvents:        virtualrouter.NewEnqueueRequestsForMeshEvents(k8sClient, log),
-		enqueueRequestsForVirtualNodeEvents: virtualrouter.NewEnqueueRequestsForVirtualNodeEvents(referencesIndexer, log),
-		log:                                 log,
-		recorder:                            recorder,
+		k8sClient:                                  k8sClient,
+		finalizerManager:                           finalizerManager,
+		referencesIndexer:                          referencesIndexer,
+		vrResManager:                               vrResManager,
+		enqueueRequestsForMeshEvents:               virtualrouter.NewEnqueueRequestsForMeshEvents(k8sClient, log),
+		enqueueRequestsForVirtualNodeEvents:        virtualrouter.NewEnqueueRequestsForVirtualNodeEvents(referencesIndexer, log),
+		enqueueRequestsForMeshReferenceGrantEvents: references.NewEnqueueRequestsForMeshReferenceGrantEvents(k8sClient, appmesh.MeshReferenceGrantFromKindVirtualRouter, &appmesh.VirtualRouterList{}, log),
+		log:      log,
+		recorder: recorder,
 	}
 }
 
@@ -62,10 +64,11 @@ type virtualRouterReconciler struct {
 	referencesIndexer references.ObjectReferenceIndexer
 	vrResManager      virtualrouter.ResourceManager
 
-	enqueueRequestsForMeshEvents        handler.EventHandler
-	enqueueRequestsForVirtualNodeEvents handler.EventHandler
-	log                                 logr.Logger
-	recorder                            record.EventRecorder
+	enqueueRequestsForMeshEvents               handler.EventHandler
+	enqueueRequestsForVirtualNodeEvents        handler.EventHandler
+	enqueueRequestsForMeshReferenceGrantEvents handler.EventHandler
+	log                                        logr.Logger
+	recorder                                   record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualrouters,verbs=get;list;watch;create;update;patch;delete
@@ -73,10 +76,10 @@ type virtualRouterReconciler struct {
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *virtualRouterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	return runtime.HandleReconcileError(r.reconcile(ctx, req), r.log)
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
 }
 
-func (r *virtualRouterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *virtualRouterReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
 	if err := r.referencesIndexer.Setup(&appmesh.VirtualRouter{}, map[string]references.ObjectReferenceIndexFunc{
 		virtualrouter.ReferenceKindVirtualNode: virtualrouter.VirtualNodeReferenceIndexFunc,
 	}); err != nil {
@@ -86,8 +89,9 @@ func (r *virtualRouterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&appmesh.VirtualRouter{}).
 		Watches(&appmesh.Mesh{}, r.enqueueRequestsForMeshEvents).
 		Watches(&appmesh.VirtualNode{}, r.enqueueRequestsForVirtualNodeEvents).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 3}).
-		Complete(r)
+		Watches(&appmesh.MeshReferenceGrant{}, r.enqueueRequestsForMeshReferenceGrantEvents).
+		WithOptions(optionsFactory.ControllerOptions("virtualrouter", virtualRouterMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("virtualrouter", r))
 }
 
 func (r *virtualRouterReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
@@ -99,7 +103,11 @@ func (r *virtualRouterReconciler) reconcile(ctx context.Context, req ctrl.Reques
 		return r.cleanupVirtualRouter(ctx, vr)
 	}
 	if err := r.reconcileVirtualRouter(ctx, vr); err != nil {
-		r.recorder.Event(vr, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		// requeue while waiting for dependencies isn't an error worth an event, they're reported in status.waitingFor instead.
+		var requeueAfterErr *runtime.RequeueAfterError
+		if !errors.As(err, &requeueAfterErr) {
+			tracing.RecordEvent(ctx, r.recorder, vr, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		}
 		return err
 	}
 	return nil
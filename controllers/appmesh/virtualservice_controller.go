@@ -22,13 +22,14 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualservice"
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
@@ -43,15 +44,16 @@ func NewVirtualServiceReconciler(
 	log logr.Logger,
 	recorder record.EventRecorder) *virtualServiceReconciler {
 	return &virtualServiceReconciler{
-		k8sClient:                             k8sClient,
-		finalizerManager:                      finalizerManager,
-		referencesIndexer:                     referencesIndexer,
-		vsResManager:                          vsResManager,
-		enqueueRequestsForMeshEvents:          virtualservice.NewEnqueueRequestsForMeshEvents(k8sClient, log),
-		enqueueRequestsForVirtualNodeEvents:   virtualservice.NewEnqueueRequestsForVirtualNodeEvents(referencesIndexer, log),
-		enqueueRequestsForVirtualRouterEvents: virtualservice.NewEnqueueRequestsForVirtualRouterEvents(referencesIndexer, log),
-		log:                                   log,
-		recorder:                              recorder,
+		k8sClient:                                  k8sClient,
+		finalizerManager:                           finalizerManager,
+		referencesIndexer:                          referencesIndexer,
+		vsResManager:                               vsResManager,
+		enqueueRequestsForMeshEvents:               virtualservice.NewEnqueueRequestsForMeshEvents(k8sClient, log),
+		enqueueRequestsForVirtualNodeEvents:        virtualservice.NewEnqueueRequestsForVirtualNodeEvents(referencesIndexer, log),
+		enqueueRequestsForVirtualRouterEvents:      virtualservice.NewEnqueueRequestsForVirtualRouterEvents(referencesIndexer, log),
+		enqueueRequestsForMeshReferenceGrantEvents: references.NewEnqueueRequestsForMeshReferenceGrantEvents(k8sClient, appmesh.MeshReferenceGrantFromKindVirtualService, &appmesh.VirtualServiceList{}, log),
+		log:      log,
+		recorder: recorder,
 	}
 }
 
@@ -62,11 +64,12 @@ type virtualServiceReconciler struct {
 	referencesIndexer references.ObjectReferenceIndexer
 	vsResManager      virtualservice.ResourceManager
 
-	enqueueRequestsForMeshEvents          handler.EventHandler
-	enqueueRequestsForVirtualNodeEvents   handler.EventHandler
-	enqueueRequestsForVirtualRouterEvents handler.EventHandler
-	log                                   logr.Logger
-	recorder                              record.EventRecorder
+	enqueueRequestsForMeshEvents               handler.EventHandler
+	enqueueRequestsForVirtualNodeEvents        handler.EventHandler
+	enqueueRequestsForVirtualRouterEvents      handler.EventHandler
+	enqueueRequestsForMeshReferenceGrantEvents handler.EventHandler
+	log                                        logr.Logger
+	recorder                                   record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualservices,verbs=get;list;watch;create;update;patch;delete
@@ -74,10 +77,10 @@ type virtualServiceReconciler struct {
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *virtualServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	return runtime.HandleReconcileError(r.reconcile(ctx, req), r.log)
+	return runtime.HandleReconcileError(r.reconcile(ctx, req), tracing.LoggerFromContext(ctx, r.log))
 }
 
-func (r *virtualServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *virtualServiceReconciler) SetupWithManager(mgr ctrl.Manager, optionsFactory runtime.ControllerOptionsFactory) error {
 	if err := r.referencesIndexer.Setup(&appmesh.VirtualService{}, map[string]references.ObjectReferenceIndexFunc{
 		virtualservice.ReferenceKindVirtualNode:   virtualservice.VirtualNodeReferenceIndexFunc,
 		virtualservice.ReferenceKindVirtualRouter: virtualservice.VirtualRouterReferenceIndexFunc,
@@ -89,8 +92,9 @@ func (r *virtualServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&appmesh.Mesh{}, r.enqueueRequestsForMeshEvents).
 		Watches(&appmesh.VirtualNode{}, r.enqueueRequestsForVirtualNodeEvents).
 		Watches(&appmesh.VirtualRouter{}, r.enqueueRequestsForVirtualRouterEvents).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 3}).
-		Complete(r)
+		Watches(&appmesh.MeshReferenceGrant{}, r.enqueueRequestsForMeshReferenceGrantEvents).
+		WithOptions(optionsFactory.ControllerOptions("virtualservice", virtualServiceMeshResolver(r.k8sClient))).
+		Complete(optionsFactory.Reconciler("virtualservice", r))
 }
 
 func (r *virtualServiceReconciler) reconcile(ctx context.Context, req ctrl.Request) error {
@@ -102,7 +106,11 @@ func (r *virtualServiceReconciler) reconcile(ctx context.Context, req ctrl.Reque
 		return r.cleanupVirtualService(ctx, vs)
 	}
 	if err := r.reconcileVirtualService(ctx, vs); err != nil {
-		r.recorder.Event(vs, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		// requeue while waiting for dependencies isn't an error worth an event, they're reported in status.waitingFor instead.
+		var requeueAfterErr *runtime.RequeueAfterError
+		if !errors.As(err, &requeueAfterErr) {
+			tracing.RecordEvent(ctx, r.recorder, vs, corev1.EventTypeWarning, "ReconcileError", err.Error())
+		}
 		return err
 	}
 	return nil
@@ -17,26 +17,36 @@ limitations under the License.
 package main
 
 import (
-	"context"
 	"crypto/tls"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/throttle"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/backendgroup"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/certmanager"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/cloudmap"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/dependency"
+	appmeshmetrics "github.com/aws/aws-app-mesh-controller-for-k8s/pkg/metrics"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	appmeshruntime "github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/shard"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/spire"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/version"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualrouter"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualservice"
 	sdkgoaws "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws"
+	"go.opentelemetry.io/otel"
 	zapraw "go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -45,6 +55,7 @@ import (
 	k8sapiflag "k8s.io/component-base/cli/flag"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -58,7 +69,7 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualgateway"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	appmeshv1beta2 "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	appmeshcontroller "github.com/aws/aws-app-mesh-controller-for-k8s/controllers/appmesh"
@@ -101,6 +112,13 @@ func main() {
 	awsCloudConfig := aws.CloudConfig{ThrottleConfig: throttle.NewDefaultServiceOperationsThrottleConfig()}
 	injectConfig := inject.Config{}
 	cloudMapConfig := cloudmap.Config{}
+	referencesConfig := references.Config{}
+	certManagerConfig := certmanager.Config{}
+	spireConfig := spire.Config{}
+	controllerConfig := appmeshruntime.ControllerConfig{}
+	shardConfig := shard.Config{}
+	tracingConfig := tracing.Config{}
+	deletionConfig := deletion.Config{}
 	fs := pflag.NewFlagSet("", pflag.ExitOnError)
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Hour, "SyncPeriod determines the minimum frequency at which watched resources are reconciled.")
 	fs.StringVar(&metricsAddr, "metrics-addr", "0.0.0.0:8080", "The address the metric endpoint binds to.")
@@ -112,12 +130,20 @@ func main() {
 	fs.StringVar(&logLevel, "log-level", "info", "Set the controller log level - info(default), debug")
 	fs.Int64Var(&listPageLimit, "page-limit", 100,
 		"The page size limiting the number of response for list operation to API Server")
+	fs.MarkDeprecated("page-limit", "pods are listed by the manager's cache, this flag has no effect")
 	fs.IntVar(&healthProbePort, flagHealthProbePort, defaultHealthProbePort,
 		"The port the health probes binds to.")
 
 	awsCloudConfig.BindFlags(fs)
 	injectConfig.BindFlags(fs)
 	cloudMapConfig.BindFlags(fs)
+	referencesConfig.BindFlags(fs)
+	certManagerConfig.BindFlags(fs)
+	spireConfig.BindFlags(fs)
+	controllerConfig.BindFlags(fs)
+	shardConfig.BindFlags(fs)
+	tracingConfig.BindFlags(fs)
+	deletionConfig.BindFlags(fs)
 	if err := fs.Parse(os.Args); err != nil {
 		setupLog.Error(err, "invalid flags")
 		os.Exit(1)
@@ -127,6 +153,42 @@ func main() {
 		setupLog.Error(err, "invalid flags")
 		os.Exit(1)
 	}
+	if err := cloudMapConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid flags")
+		os.Exit(1)
+	}
+	if err := certManagerConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid flags")
+		os.Exit(1)
+	}
+	if err := spireConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid flags")
+		os.Exit(1)
+	}
+	if err := controllerConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid flags")
+		os.Exit(1)
+	}
+	if err := shardConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid flags")
+		os.Exit(1)
+	}
+	if err := deletionConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid flags")
+		os.Exit(1)
+	}
+	if err := tracingConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid flags")
+		os.Exit(1)
+	}
+	if enableLeaderElection && shardConfig.Enabled() {
+		setupLog.Error(errors.New("leader election cannot be enabled with sharding"), "invalid flags")
+		os.Exit(1)
+	}
+	if spireConfig.EnableRegistration && !injectConfig.EnableSDS {
+		setupLog.Error(errors.New("SPIRE registration requires SDS to be enabled"), "invalid flags")
+		os.Exit(1)
+	}
 
 	lvl := zapraw.NewAtomicLevelAt(0)
 	if logLevel == "debug" {
@@ -144,9 +206,10 @@ func main() {
 	healthProbeBindAddress := ":" + parsedPort
 	setupLog.Info("Health endpoint", "HealthProbeBindAddress", healthProbeBindAddress)
 
-	eventNotificationChan := make(chan k8s.GenericEvent)
-
 	kubeConfig := ctrl.GetConfigOrDie()
+	if tracingConfig.Enabled() {
+		kubeConfig.Wrap(tracing.WrapTransport)
+	}
 
 	clientSet, err := kubernetes.NewForConfig(kubeConfig)
 	if err != nil {
@@ -187,6 +250,10 @@ func main() {
 		Scheme: scheme,
 		Cache: cache.Options{
 			SyncPeriod: &syncPeriod,
+			ByObject: map[client.Object]cache.ByObject{
+				// pods are stripped down to save memory on clusters with many pods.
+				&corev1.Pod{}: {Transform: conversions.StripPod},
+			},
 		},
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
@@ -201,16 +268,6 @@ func main() {
 		HealthProbeBindAddress:     healthProbeBindAddress,
 	})
 
-	customController := k8s.NewCustomController(
-		clientSet,
-		listPageLimit,
-		metav1.NamespaceAll,
-		conversions.NewPodConverter(),
-		syncPeriod,
-		false,
-		eventNotificationChan,
-		setupLog.WithName("pod custom controller"))
-
 	if err != nil {
 		setupLog.Error(err, "unable to start app mesh controller")
 		os.Exit(1)
@@ -220,6 +277,14 @@ func main() {
 		setupLog.Error(err, "unable to initialize AWS cloud")
 		os.Exit(1)
 	}
+	if awsCloudConfig.ThrottleConfigFile != "" && cloud.Throttler() != nil {
+		throttleConfigFileWatcher := throttle.NewConfigFileWatcher(awsCloudConfig.ThrottleConfigFile, awsCloudConfig.ThrottleConfig,
+			cloud.Throttler(), ctrl.Log.WithName("aws-api-throttle"))
+		if err := mgr.Add(throttleConfigFileWatcher); err != nil {
+			setupLog.Error(err, "unable to watch AWS API throttle file")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("Cluster Name", "ClusterName", injectConfig.ClusterName)
 
@@ -238,87 +303,226 @@ func main() {
 		setupLog.Info("please provide a cluster-name using --set clusterName=name-of-your-cluster")
 	}
 
-	podsRepository := k8s.NewPodsRepository(customController)
+	podsRepository := k8s.NewPodsRepository(mgr.GetCache())
 
 	ctx := ctrl.SetupSignalHandler()
 	referencesIndexer := references.NewDefaultObjectReferenceIndexer(mgr.GetCache(), mgr.GetFieldIndexer())
 	finalizerManager := k8s.NewDefaultFinalizerManager(mgr.GetClient(), ctrl.Log)
-	meshMembersFinalizer := mesh.NewPendingMembersFinalizer(mgr.GetClient(), mgr.GetEventRecorderFor("mesh-members"), ctrl.Log)
-	vgMembersFinalizer := virtualgateway.NewPendingMembersFinalizer(mgr.GetClient(), mgr.GetEventRecorderFor("virtualgateway-members"), ctrl.Log)
-	referencesResolver := references.NewDefaultResolver(mgr.GetClient(), ctrl.Log)
-	virtualNodeEndpointResolver := cloudmap.NewDefaultVirtualNodeEndpointResolver(podsRepository, ctrl.Log)
-	cloudMapInstancesReconciler := cloudmap.NewDefaultInstancesReconciler(mgr.GetClient(), cloud.CloudMap(), ctrl.Log, ctx.Done(), ipFamily)
-	meshResManager := mesh.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), cloud.AccountID(), ctrl.Log)
-	vgResManager := virtualgateway.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), ctrl.Log)
-	grResManager := gatewayroute.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), ctrl.Log)
-	vnResManager := virtualnode.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), ctrl.Log, injectConfig.EnableBackendGroups)
-	vsResManager := virtualservice.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), ctrl.Log)
-	vrResManager := virtualrouter.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), ctrl.Log)
-	cloudMapResManager := cloudmap.NewDefaultResourceManager(mgr.GetClient(), cloud.CloudMap(), referencesResolver, virtualNodeEndpointResolver, cloudMapInstancesReconciler, enableCustomHealthCheck, ctrl.Log, cloudMapConfig, ipFamily)
+	pendingMembersMetric, err := appmeshmetrics.NewPendingMembersMetric(metrics.Registry)
+	if err != nil {
+		setupLog.Error(err, "unable to register pending members metric")
+		os.Exit(1)
+	}
+	meshMembersFinalizer := mesh.NewPendingMembersFinalizer(mgr.GetClient(), mgr.GetEventRecorderFor("mesh-members"), dependency.NewMembersDeletionOrderer(), pendingMembersMetric, ctrl.Log)
+	vgMembersFinalizer := virtualgateway.NewPendingMembersFinalizer(mgr.GetClient(), mgr.GetEventRecorderFor("virtualgateway-members"), pendingMembersMetric, ctrl.Log)
+	if err := metrics.Registry.Register(appmeshmetrics.NewResourcesCollector(mgr.GetCache(), injectConfig.EnableBackendGroups)); err != nil {
+		setupLog.Error(err, "unable to register App Mesh resources metrics")
+		os.Exit(1)
+	}
+	activeLagTracker, err := appmeshmetrics.NewActiveLagTracker(mgr.GetCache(), injectConfig.EnableBackendGroups, metrics.Registry)
+	if err != nil {
+		setupLog.Error(err, "unable to register App Mesh resources metrics")
+		os.Exit(1)
+	}
+	if err := mgr.Add(activeLagTracker); err != nil {
+		setupLog.Error(err, "unable to add App Mesh resources tracker")
+		os.Exit(1)
+	}
+	referenceGrantChecker := references.NewDefaultReferenceGrantChecker(mgr.GetClient(), referencesConfig)
+	referencesResolver := references.NewDefaultResolver(mgr.GetClient(), referenceGrantChecker, ctrl.Log)
+	bgMembersResolver := backendgroup.NewDefaultMembersResolver(mgr.GetClient(), referenceGrantChecker, ctrl.Log)
+	var cloudMapEndpointResolver cloudmap.EndpointResolver
+	if cloudMapConfig.EndpointSource == cloudmap.EndpointSourceEndpointSlice {
+		cloudMapEndpointResolver = cloudmap.NewEndpointSliceEndpointResolver(mgr.GetClient(), ctrl.Log)
+	} else {
+		cloudMapEndpointResolver = cloudmap.NewDefaultEndpointResolver(podsRepository, ctrl.Log)
+	}
+	cloudMapHealthSource, err := cloudmap.NewHealthSource(cloudMapConfig, ctrl.Log.WithName("cloudMapHealthSource"))
+	if err != nil {
+		setupLog.Error(err, "unable to initialize CloudMap health source")
+		os.Exit(1)
+	}
+	// health sources that evaluate health in background, such as http-probe, run with the manager.
+	if runnable, ok := cloudMapHealthSource.(manager.Runnable); ok {
+		if err := mgr.Add(runnable); err != nil {
+			setupLog.Error(err, "unable to add CloudMap health source")
+			os.Exit(1)
+		}
+	}
+	cloudMapInstancesReconciler, err := cloudmap.NewDefaultInstancesReconciler(mgr.GetClient(), cloud.CloudMap(), cloudMapConfig, metrics.Registry, ctrl.Log, ctx.Done(), ipFamily)
+	if err != nil {
+		setupLog.Error(err, "unable to initialize CloudMap instances reconciler")
+		os.Exit(1)
+	}
+	var meshOwnership appmeshruntime.MeshOwnership
+	if shardConfig.Enabled() {
+		identity, err := os.Hostname()
+		if err != nil {
+			setupLog.Error(err, "unable to get identity for sharding")
+			os.Exit(1)
+		}
+		shardManager := shard.NewDefaultManager(shardConfig, identity, mgr.GetClient(), mgr.GetAPIReader(), ctrl.Log.WithName("shard"))
+		if err := mgr.Add(shardManager); err != nil {
+			setupLog.Error(err, "unable to add shard manager")
+			os.Exit(1)
+		}
+		meshOwnership = shardManager
+		// state kept for Cloud Map reconciles of meshes dropped by this replica turns stale, forget it.
+		meshStateForgetters := []cloudmap.MeshStateForgetter{cloudMapInstancesReconciler}
+		if forgetter, ok := cloudMapHealthSource.(cloudmap.MeshStateForgetter); ok {
+			meshStateForgetters = append(meshStateForgetters, forgetter)
+		}
+		shardManager.AddOwnershipChangeHandler(func() {
+			for _, forgetter := range meshStateForgetters {
+				forgetter.ForgetMeshes(shardManager.OwnsMesh)
+			}
+		})
+	}
+	var tracer tracing.Tracer
+	if tracingConfig.Enabled() {
+		tracingLog := ctrl.Log.WithName("tracing")
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			tracingLog.Error(err, "failed to export spans")
+		}))
+		defaultTracer, err := tracing.NewDefaultTracer(tracingConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to initialize tracer")
+			os.Exit(1)
+		}
+		if err := mgr.Add(defaultTracer); err != nil {
+			setupLog.Error(err, "unable to add tracer")
+			os.Exit(1)
+		}
+		tracer = defaultTracer
+	}
+	var requestDependencies appmeshruntime.RequestDependencies
+	if controllerConfig.DependencyOrdering {
+		requestDependencies = dependency.NewRequestDependencies(mgr.GetClient())
+	}
+	controllerOptionsFactory, err := appmeshruntime.NewDefaultControllerOptionsFactory(controllerConfig, meshOwnership, requestDependencies, metrics.Registry, tracer, ctrl.Log.WithName("workqueue"))
+	if err != nil {
+		setupLog.Error(err, "unable to initialize controller options")
+		os.Exit(1)
+	}
+	meshResManager := mesh.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), cloud.AccountID(), deletionConfig.Default(), ctrl.Log)
+	vgResManager := virtualgateway.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), deletionConfig.Default(), ctrl.Log)
+	grResManager := gatewayroute.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), deletionConfig.Default(), ctrl.Log)
+	vnResManager := virtualnode.NewDefaultResourceManager(mgr.GetClient(), mgr.GetAPIReader(), cloud.AppMesh(), referencesResolver, bgMembersResolver, cloud.AccountID(), deletionConfig.Default(), ctrl.Log, injectConfig.EnableBackendGroups)
+	vsResManager := virtualservice.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), deletionConfig.Default(), ctrl.Log)
+	vrResManager := virtualrouter.NewDefaultResourceManager(mgr.GetClient(), cloud.AppMesh(), referencesResolver, cloud.AccountID(), deletionConfig.Default(), ctrl.Log)
+	bgResManager := backendgroup.NewDefaultResourceManager(mgr.GetClient(), bgMembersResolver, ctrl.Log)
+	cloudMapResManager := cloudmap.NewDefaultResourceManager(mgr.GetClient(), cloud.CloudMap(), referencesResolver, cloudMapEndpointResolver, cloudMapHealthSource, cloudMapInstancesReconciler, enableCustomHealthCheck, deletionConfig.Default(), ctrl.Log, cloudMapConfig, ipFamily)
 	msReconciler := appmeshcontroller.NewMeshReconciler(mgr.GetClient(), finalizerManager, meshMembersFinalizer, meshResManager, ctrl.Log.WithName("controllers").WithName("Mesh"), mgr.GetEventRecorderFor("Mesh"))
 	vgReconciler := appmeshcontroller.NewVirtualGatewayReconciler(mgr.GetClient(), finalizerManager, vgMembersFinalizer, vgResManager, ctrl.Log.WithName("controllers").WithName("VirtualGateway"), mgr.GetEventRecorderFor("VirtualGateway"))
-	grReconciler := appmeshcontroller.NewGatewayRouteReconciler(mgr.GetClient(), finalizerManager, grResManager, ctrl.Log.WithName("controllers").WithName("GatewayRoute"), mgr.GetEventRecorderFor("GatewayRoute"))
+	grReconciler := appmeshcontroller.NewGatewayRouteReconciler(mgr.GetClient(), finalizerManager, referencesIndexer, grResManager, ctrl.Log.WithName("controllers").WithName("GatewayRoute"), mgr.GetEventRecorderFor("GatewayRoute"))
 	vnReconciler := appmeshcontroller.NewVirtualNodeReconciler(mgr.GetClient(), finalizerManager, vnResManager, ctrl.Log.WithName("controllers").WithName("VirtualNode"), mgr.GetEventRecorderFor("VirtualNode"), injectConfig.EnableBackendGroups)
 
 	cloudMapReconciler := appmeshcontroller.NewCloudMapReconciler(
 		mgr.GetClient(),
 		finalizerManager,
 		cloudMapResManager,
-		eventNotificationChan,
+		cloudMapHealthSource,
+		cloudMapConfig.EndpointSource,
 		ctrl.Log.WithName("controllers").WithName("CloudMap"),
 		mgr.GetEventRecorderFor("CloudMap"))
+	cloudMapVGReconciler := appmeshcontroller.NewCloudMapVirtualGatewayReconciler(
+		mgr.GetClient(),
+		finalizerManager,
+		cloudMapResManager,
+		cloudMapHealthSource,
+		cloudMapConfig.EndpointSource,
+		ctrl.Log.WithName("controllers").WithName("CloudMapVirtualGateway"),
+		mgr.GetEventRecorderFor("CloudMapVirtualGateway"))
 
 	vsReconciler := appmeshcontroller.NewVirtualServiceReconciler(mgr.GetClient(), finalizerManager, referencesIndexer, vsResManager, ctrl.Log.WithName("controllers").WithName("VirtualService"), mgr.GetEventRecorderFor("VirtualService"))
 	vrReconciler := appmeshcontroller.NewVirtualRouterReconciler(mgr.GetClient(), finalizerManager, referencesIndexer, vrResManager, ctrl.Log.WithName("controllers").WithName("VirtualRouter"), mgr.GetEventRecorderFor("VirtualRouter"))
-	if err = msReconciler.SetupWithManager(mgr); err != nil {
+	if err = msReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Mesh")
 		os.Exit(1)
 	}
-	if err = vsReconciler.SetupWithManager(mgr); err != nil {
+	if err = vsReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VirtualService")
 		os.Exit(1)
 	}
 
-	if err = vgReconciler.SetupWithManager(mgr); err != nil {
+	if err = vgReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VirtualGateway")
 		os.Exit(1)
 	}
-	if err = grReconciler.SetupWithManager(mgr); err != nil {
+	if err = grReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GatewayRoute")
 		os.Exit(1)
 	}
 
-	if err = vnReconciler.SetupWithManager(mgr); err != nil {
+	if err = vnReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VirtualNode")
 		os.Exit(1)
 	}
-	if err = vrReconciler.SetupWithManager(mgr); err != nil {
+	if err = vrReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VirtualRouter")
 		os.Exit(1)
 	}
-	if err = cloudMapReconciler.SetupWithManager(mgr); err != nil {
+	if err = cloudMapReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CloudMap")
 		os.Exit(1)
 	}
+	if err = cloudMapVGReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CloudMapVirtualGateway")
+		os.Exit(1)
+	}
+	if certManagerConfig.EnableCertificates {
+		workloadRoller := certmanager.NewDefaultWorkloadRoller(mgr.GetClient(), mgr.GetAPIReader(), ctrl.Log.WithName("certmanager"))
+		certResManager := certmanager.NewDefaultResourceManager(mgr.GetClient(), mgr.GetScheme(), workloadRoller, cloudmap.NewDefaultNamespaceResolver(cloud.CloudMap()), certManagerConfig, ctrl.Log.WithName("certmanager"))
+		vnCertReconciler := appmeshcontroller.NewVirtualNodeCertificateReconciler(mgr.GetClient(), certResManager, ctrl.Log.WithName("controllers").WithName("VirtualNodeCertificate"), mgr.GetEventRecorderFor("VirtualNodeCertificate"))
+		if err = vnCertReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "VirtualNodeCertificate")
+			os.Exit(1)
+		}
+		vgCertReconciler := appmeshcontroller.NewVirtualGatewayCertificateReconciler(mgr.GetClient(), certResManager, ctrl.Log.WithName("controllers").WithName("VirtualGatewayCertificate"), mgr.GetEventRecorderFor("VirtualGatewayCertificate"))
+		if err = vgCertReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "VirtualGatewayCertificate")
+			os.Exit(1)
+		}
+	}
+	if spireConfig.EnableRegistration {
+		spireRegManager := spire.NewDefaultRegistrationManager(mgr.GetClient(), spireConfig, ctrl.Log.WithName("spire"))
+		vnSPIREReconciler := appmeshcontroller.NewVirtualNodeSPIREReconciler(mgr.GetClient(), finalizerManager, spireRegManager, ctrl.Log.WithName("controllers").WithName("VirtualNodeSPIRE"), mgr.GetEventRecorderFor("VirtualNodeSPIRE"))
+		if err = vnSPIREReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "VirtualNodeSPIRE")
+			os.Exit(1)
+		}
+		vgSPIREReconciler := appmeshcontroller.NewVirtualGatewaySPIREReconciler(mgr.GetClient(), finalizerManager, spireRegManager, ctrl.Log.WithName("controllers").WithName("VirtualGatewaySPIRE"), mgr.GetEventRecorderFor("VirtualGatewaySPIRE"))
+		if err = vgSPIREReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "VirtualGatewaySPIRE")
+			os.Exit(1)
+		}
+	}
+	if injectConfig.EnableBackendGroups {
+		bgReconciler := appmeshcontroller.NewBackendGroupReconciler(mgr.GetClient(), bgResManager, ctrl.Log.WithName("controllers").WithName("BackendGroup"), mgr.GetEventRecorderFor("BackendGroup"))
+		if err = bgReconciler.SetupWithManager(mgr, controllerOptionsFactory); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "BackendGroup")
+			os.Exit(1)
+		}
+	}
 
 	meshMembershipDesignator := mesh.NewMembershipDesignator(mgr.GetClient())
 	vgMembershipDesignator := virtualgateway.NewMembershipDesignator(mgr.GetClient())
 	vnMembershipDesignator := virtualnode.NewMembershipDesignator(mgr.GetClient())
-	sidecarInjector := inject.NewSidecarInjector(injectConfig, cloud.AccountID(), cloud.Region(), version.GitVersion, k8sVersion, mgr.GetClient(), referencesResolver, vnMembershipDesignator, vgMembershipDesignator)
+	sidecarInjector := inject.NewSidecarInjector(injectConfig, certManagerConfig, cloud.AccountID(), cloud.Region(), version.GitVersion, k8sVersion, mgr.GetClient(), referencesResolver, vnMembershipDesignator, vgMembershipDesignator)
 	appmeshwebhook.NewMeshMutator(ipFamily).SetupWithManager(mgr)
 	appmeshwebhook.NewMeshValidator(ipFamily).SetupWithManager(mgr)
 	appmeshwebhook.NewVirtualGatewayMutator(meshMembershipDesignator).SetupWithManager(mgr)
 	appmeshwebhook.NewVirtualGatewayValidator().SetupWithManager(mgr)
 	appmeshwebhook.NewGatewayRouteMutator(meshMembershipDesignator, vgMembershipDesignator).SetupWithManager(mgr)
-	appmeshwebhook.NewGatewayRouteValidator().SetupWithManager(mgr)
+	appmeshwebhook.NewGatewayRouteValidator(referenceGrantChecker).SetupWithManager(mgr)
 	appmeshwebhook.NewVirtualNodeMutator(meshMembershipDesignator).SetupWithManager(mgr)
-	appmeshwebhook.NewVirtualNodeValidator().SetupWithManager(mgr)
+	appmeshwebhook.NewVirtualNodeValidator(referenceGrantChecker).SetupWithManager(mgr)
 	appmeshwebhook.NewVirtualServiceMutator(meshMembershipDesignator).SetupWithManager(mgr)
-	appmeshwebhook.NewVirtualServiceValidator().SetupWithManager(mgr)
+	appmeshwebhook.NewVirtualServiceValidator(referenceGrantChecker).SetupWithManager(mgr)
 	appmeshwebhook.NewVirtualRouterMutator(meshMembershipDesignator).SetupWithManager(mgr)
-	appmeshwebhook.NewVirtualRouterValidator().SetupWithManager(mgr)
+	appmeshwebhook.NewVirtualRouterValidator(referenceGrantChecker).SetupWithManager(mgr)
 	appmeshwebhook.NewBackendGroupMutator(meshMembershipDesignator).SetupWithManager(mgr)
-	appmeshwebhook.NewBackendGroupValidator().SetupWithManager(mgr)
+	appmeshwebhook.NewBackendGroupValidator(referenceGrantChecker).SetupWithManager(mgr)
 	corewebhook.NewPodMutator(sidecarInjector).SetupWithManager(mgr)
 
 	// Add liveness probe
@@ -329,20 +533,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Only start the controller when the leader election is won
-	mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
-		setupLog.Info("starting custom controller")
-
-		// Start the custom controller
-		customController.StartController(ctx.Done())
-		// If the manager is stopped, signal the controller to stop as well.
-		<-ctx.Done()
-
-		setupLog.Info("stopping the controller")
-
-		return nil
-	}))
-
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting controller")
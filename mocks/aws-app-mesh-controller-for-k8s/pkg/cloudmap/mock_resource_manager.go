@@ -49,6 +49,20 @@ func (mr *MockResourceManagerMockRecorder) Cleanup(ctx, vn interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cleanup", reflect.TypeOf((*MockResourceManager)(nil).Cleanup), ctx, vn)
 }
 
+// CleanupVirtualGateway mocks base method.
+func (m *MockResourceManager) CleanupVirtualGateway(ctx context.Context, vg *v1beta2.VirtualGateway) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanupVirtualGateway", ctx, vg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CleanupVirtualGateway indicates an expected call of CleanupVirtualGateway.
+func (mr *MockResourceManagerMockRecorder) CleanupVirtualGateway(ctx, vg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupVirtualGateway", reflect.TypeOf((*MockResourceManager)(nil).CleanupVirtualGateway), ctx, vg)
+}
+
 // Reconcile mocks base method.
 func (m *MockResourceManager) Reconcile(ctx context.Context, vn *v1beta2.VirtualNode) error {
 	m.ctrl.T.Helper()
@@ -62,3 +76,17 @@ func (mr *MockResourceManagerMockRecorder) Reconcile(ctx, vn interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockResourceManager)(nil).Reconcile), ctx, vn)
 }
+
+// ReconcileVirtualGateway mocks base method.
+func (m *MockResourceManager) ReconcileVirtualGateway(ctx context.Context, vg *v1beta2.VirtualGateway) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileVirtualGateway", ctx, vg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReconcileVirtualGateway indicates an expected call of ReconcileVirtualGateway.
+func (mr *MockResourceManagerMockRecorder) ReconcileVirtualGateway(ctx, vg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileVirtualGateway", reflect.TypeOf((*MockResourceManager)(nil).ReconcileVirtualGateway), ctx, vg)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/metrics"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/throttle"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -25,6 +26,9 @@ type Cloud interface {
 
 	// Region for the kubernetes cluster
 	Region() string
+
+	// Throttler throttles requests to AWS APIs, nil if throttle isn't configured.
+	Throttler() throttle.Throttler
 }
 
 // NewCloud constructs new Cloud implementation.
@@ -33,9 +37,17 @@ func NewCloud(cfg CloudConfig, metricsRegisterer prometheus.Registerer) (Cloud,
 	// creating separate config for AppMesh because it has both DualStack and FIPS endpoint, But for other AWS APIs services EKS and CloudMap DualStack endpoints(DNS ending in api.aws) are unavailable.
 	sessAppMesh := session.Must(session.NewSession(aws.NewConfig()))
 	injectUserAgent(&sess.Handlers)
+	var throttler throttle.Throttler
 	if cfg.ThrottleConfig != nil {
-		throttler := throttle.NewThrottler(cfg.ThrottleConfig)
-		throttler.InjectHandlers(&sess.Handlers)
+		defaultThrottler, err := throttle.NewThrottler(cfg.ThrottleConfig, cfg.ThrottleAdaptive, metricsRegisterer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to initialize sdk throttler")
+		}
+		defaultThrottler.InjectHandlers(&sess.Handlers)
+		if cfg.ThrottleAppMesh {
+			defaultThrottler.InjectHandlers(&sessAppMesh.Handlers)
+		}
+		throttler = defaultThrottler
 	}
 	if metricsRegisterer != nil {
 		metricsCollector, err := metrics.NewCollector(metricsRegisterer)
@@ -43,7 +55,11 @@ func NewCloud(cfg CloudConfig, metricsRegisterer prometheus.Registerer) (Cloud,
 			return nil, errors.Wrapf(err, "failed to initialize sdk metrics collector")
 		}
 		metricsCollector.InjectHandlers(&sess.Handlers)
+		metricsCollector.InjectHandlers(&sessAppMesh.Handlers)
 	}
+	// API calls are only traced within traced reconciles, handlers are no-op otherwise.
+	tracing.InjectAWSHandlers(&sess.Handlers)
+	tracing.InjectAWSHandlers(&sessAppMesh.Handlers)
 
 	if len(cfg.Region) == 0 {
 		metadata := services.NewEC2Metadata(sess)
@@ -75,11 +91,16 @@ func NewCloud(cfg CloudConfig, metricsRegisterer prometheus.Registerer) (Cloud,
 		}
 		cfg.AccountID = accountID
 	}
+	appMesh := services.NewAppMesh(sessAppMesh)
+	if cfg.AppMeshCacheRefreshInterval > 0 {
+		appMesh = services.NewSnapshotCachedAppMesh(appMesh, cfg.AppMeshCacheRefreshInterval)
+	}
 	return &defaultCloud{
-		cfg:      cfg,
-		appMesh:  services.NewAppMesh(sessAppMesh),
-		cloudMap: services.NewCloudMap(sess),
-		eks:      services.NewEKS(sess),
+		cfg:       cfg,
+		appMesh:   appMesh,
+		cloudMap:  services.NewCloudMap(sess),
+		eks:       services.NewEKS(sess),
+		throttler: throttler,
 	}, nil
 }
 
@@ -91,6 +112,8 @@ type defaultCloud struct {
 	appMesh  services.AppMesh
 	cloudMap services.CloudMap
 	eks      services.EKS
+
+	throttler throttle.Throttler
 }
 
 func (c *defaultCloud) AppMesh() services.AppMesh {
@@ -112,3 +135,7 @@ func (c *defaultCloud) AccountID() string {
 func (c *defaultCloud) Region() string {
 	return c.cfg.Region
 }
+
+func (c *defaultCloud) Throttler() throttle.Throttler {
+	return c.throttler
+}
@@ -7,12 +7,21 @@ import (
 	"github.com/spf13/pflag"
 	"regexp"
 	"strings"
+	"time"
+)
+
+const (
+	defaultAppMeshCacheRefreshInterval = 0
 )
 
 const (
 	flagAWSRegion               = "aws-region"
 	flagAWSAccountID            = "aws-account-id"
 	flagAWSAPIThrottle          = "aws-api-throttle"
+	flagAWSAPIThrottleAdaptive  = "aws-api-throttle-adaptive"
+	flagAWSAPIThrottleFile      = "aws-api-throttle-file"
+	flagAWSAPIThrottleAppMesh   = "aws-api-throttle-appmesh"
+	flagAppMeshCacheRefresh     = "appmesh-cache-refresh-interval"
 	flagUseAwsFipsEndpoint      = "use-aws-fips-endpoint"
 	flagUseAwsDualStackEndpoint = "use-aws-dual-stack-endpoint"
 )
@@ -24,6 +33,14 @@ type CloudConfig struct {
 	AccountID string
 	// Throttle settings for aws APIs
 	ThrottleConfig *throttle.ServiceOperationsThrottleConfig
+	// Whether throttle of aws APIs adapts to throttling errors
+	ThrottleAdaptive bool
+	// File with throttle settings for aws APIs that are applied at runtime
+	ThrottleConfigFile string
+	// Whether throttle settings apply to AppMesh APIs as well, which are unthrottled by default
+	ThrottleAppMesh bool
+	// How frequently per-mesh snapshots of AppMesh resources are refreshed, zero disables the snapshots
+	AppMeshCacheRefreshInterval time.Duration
 	// DualStackEndpoint flag for aws APIs
 	UseAwsDualStackEndpoint bool
 	// FipsEndpoint flag for aws APIs
@@ -34,6 +51,11 @@ func (cfg *CloudConfig) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&cfg.Region, flagAWSRegion, "", "AWS Region for the kubernetes cluster")
 	fs.StringVar(&cfg.AccountID, flagAWSAccountID, "", "AWS AccountID for the kubernetes cluster")
 	fs.Var(cfg.ThrottleConfig, flagAWSAPIThrottle, "throttle settings for AWS APIs, format: serviceID1:operationRegex1=rate:burst,serviceID2:operationRegex2=rate:burst")
+	fs.BoolVar(&cfg.ThrottleAdaptive, flagAWSAPIThrottleAdaptive, true, "Lower the throttle rate of AWS APIs on throttling errors, and slowly recover it afterwards")
+	fs.StringVar(&cfg.ThrottleConfigFile, flagAWSAPIThrottleFile, "", "File with throttle settings for AWS APIs in the format of --"+flagAWSAPIThrottle+", which is reloaded at runtime")
+	fs.BoolVar(&cfg.ThrottleAppMesh, flagAWSAPIThrottleAppMesh, false, "Apply the appmesh settings of --"+flagAWSAPIThrottle+" to AppMesh APIs, which are unthrottled otherwise")
+	fs.DurationVar(&cfg.AppMeshCacheRefreshInterval, flagAppMeshCacheRefresh, defaultAppMeshCacheRefreshInterval,
+		"How frequently AppMesh resources are listed to refresh the per-mesh snapshots that serve describe calls, 0 to always describe AppMesh resources. Changes made outside of the controller may be observed with this delay")
 	fs.BoolVar(&cfg.UseAwsFIPSEndpoint, flagUseAwsFipsEndpoint, false, "To use FIPS Endpoint for AWS Services")
 	fs.BoolVar(&cfg.UseAwsDualStackEndpoint, flagUseAwsDualStackEndpoint, false, "To use Dual Stack Endpoint for AWS Services")
 }
@@ -0,0 +1,433 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/service/appmesh"
+)
+
+const (
+	// the max number of concurrent describe calls while loading a snapshot.
+	defaultSnapshotDescribeConcurrency = 8
+)
+
+type snapshotResourceKind string
+
+const (
+	snapshotKindVirtualNode    snapshotResourceKind = "VirtualNode"
+	snapshotKindVirtualService snapshotResourceKind = "VirtualService"
+	snapshotKindVirtualRouter  snapshotResourceKind = "VirtualRouter"
+	snapshotKindRoute          snapshotResourceKind = "Route"
+	snapshotKindVirtualGateway snapshotResourceKind = "VirtualGateway"
+	snapshotKindGatewayRoute   snapshotResourceKind = "GatewayRoute"
+)
+
+// snapshotKey identifies an AppMesh resource within a mesh.
+type snapshotKey struct {
+	kind snapshotResourceKind
+	// parent is the name of virtualRouter for routes, and the name of virtualGateway for gatewayRoutes.
+	parent string
+	name   string
+}
+
+// meshSnapshot is a read cache of AppMesh resources within a mesh.
+// It's loaded by listing resources of the mesh, and only describes resources whose version changed since last load.
+type meshSnapshot struct {
+	appMeshSDK AppMesh
+	meshName   *string
+	meshOwner  *string
+
+	// loadMutex serializes loads of the snapshot.
+	loadMutex sync.Mutex
+
+	mutex sync.RWMutex
+	// whether the snapshot have been successfully loaded at least once.
+	loaded bool
+	// last time a load have been attempted.
+	refreshedAt time.Time
+	// whether a load is in progress.
+	loading bool
+	// keys changed during an in-progress load, which takes precedence over the load result.
+	dirtyKeys map[snapshotKey]struct{}
+	// parents deleted during an in-progress load, whose children in the load result are dropped.
+	dirtyParents map[snapshotKey]struct{}
+	entries      map[snapshotKey]interface{}
+}
+
+func newMeshSnapshot(appMeshSDK AppMesh, meshName *string, meshOwner *string) *meshSnapshot {
+	return &meshSnapshot{
+		appMeshSDK: appMeshSDK,
+		meshName:   meshName,
+		meshOwner:  meshOwner,
+		entries:    make(map[snapshotKey]interface{}),
+	}
+}
+
+// refreshIfStale loads the snapshot if it haven't been refreshed within refreshInterval.
+// The first load blocks concurrent callers, while later refreshes are done by a single caller with others served from the stale snapshot.
+func (s *meshSnapshot) refreshIfStale(ctx context.Context, refreshInterval time.Duration, now time.Time) {
+	s.mutex.RLock()
+	loaded, refreshedAt := s.loaded, s.refreshedAt
+	s.mutex.RUnlock()
+	if now.Sub(refreshedAt) < refreshInterval {
+		return
+	}
+	if loaded {
+		if !s.loadMutex.TryLock() {
+			return
+		}
+	} else {
+		s.loadMutex.Lock()
+	}
+	defer s.loadMutex.Unlock()
+
+	s.mutex.Lock()
+	if now.Sub(s.refreshedAt) < refreshInterval {
+		s.mutex.Unlock()
+		return
+	}
+	s.loading = true
+	s.dirtyKeys = make(map[snapshotKey]struct{})
+	s.dirtyParents = make(map[snapshotKey]struct{})
+	cachedEntries := make(map[snapshotKey]interface{}, len(s.entries))
+	for key, data := range s.entries {
+		cachedEntries[key] = data
+	}
+	s.mutex.Unlock()
+
+	entries, err := s.load(ctx, cachedEntries)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.loading = false
+	s.refreshedAt = now
+	if err == nil {
+		for key := range s.dirtyKeys {
+			if data, ok := s.entries[key]; ok {
+				entries[key] = data
+			} else {
+				delete(entries, key)
+			}
+		}
+		for key := range entries {
+			if _, ok := s.dirtyParents[parentKeyOf(key)]; !ok {
+				continue
+			}
+			if _, ok := s.entries[key]; !ok {
+				delete(entries, key)
+			}
+		}
+		s.entries = entries
+		s.loaded = true
+	}
+	s.dirtyKeys = nil
+	s.dirtyParents = nil
+}
+
+// get returns a copy of resource data by key, the boolean is false if it's not in the snapshot.
+func (s *meshSnapshot) get(key snapshotKey) (interface{}, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if !s.loaded {
+		return nil, false
+	}
+	data, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return awsutil.CopyOf(data), true
+}
+
+// set stores a copy of resource data by key.
+func (s *meshSnapshot) set(key snapshotKey, data interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries[key] = awsutil.CopyOf(data)
+	s.markDirty(key)
+}
+
+// invalidate removes resource data by key, so that it will be described again.
+func (s *meshSnapshot) invalidate(key snapshotKey) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.entries, key)
+	s.markDirty(key)
+}
+
+// invalidateChildren removes resources nested under resource by key, e.g. routes of a deleted virtualRouter.
+func (s *meshSnapshot) invalidateChildren(key snapshotKey) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for entryKey := range s.entries {
+		if parentKeyOf(entryKey) == key {
+			delete(s.entries, entryKey)
+		}
+	}
+	if s.loading {
+		s.dirtyParents[key] = struct{}{}
+	}
+}
+
+// parentKeyOf returns the key of the resource that resource by key is nested under, the zero key if it isn't nested.
+func parentKeyOf(key snapshotKey) snapshotKey {
+	switch key.kind {
+	case snapshotKindRoute:
+		return snapshotKey{kind: snapshotKindVirtualRouter, name: key.parent}
+	case snapshotKindGatewayRoute:
+		return snapshotKey{kind: snapshotKindVirtualGateway, name: key.parent}
+	}
+	return snapshotKey{}
+}
+
+func (s *meshSnapshot) markDirty(key snapshotKey) {
+	if s.loading {
+		s.dirtyKeys[key] = struct{}{}
+	}
+}
+
+// snapshotDescribeTask describes a single resource while loading a snapshot.
+type snapshotDescribeTask struct {
+	key      snapshotKey
+	describe func(ctx context.Context) (interface{}, error)
+}
+
+// load lists resources of the mesh, and returns entries of them.
+// Resources in cachedEntries with unchanged version are reused, while others are described.
+func (s *meshSnapshot) load(ctx context.Context, cachedEntries map[snapshotKey]interface{}) (map[snapshotKey]interface{}, error) {
+	entries := make(map[snapshotKey]interface{})
+	var tasks []snapshotDescribeTask
+	reuseOrDescribe := func(key snapshotKey, version *int64, describe func(ctx context.Context) (interface{}, error)) {
+		if data, ok := cachedEntries[key]; ok && aws.Int64Value(snapshotDataVersion(data)) == aws.Int64Value(version) {
+			entries[key] = data
+			return
+		}
+		tasks = append(tasks, snapshotDescribeTask{key: key, describe: describe})
+	}
+
+	if err := s.appMeshSDK.ListVirtualNodesPagesWithContext(ctx, &appmesh.ListVirtualNodesInput{
+		MeshName:  s.meshName,
+		MeshOwner: s.meshOwner,
+	}, func(output *appmesh.ListVirtualNodesOutput, _ bool) bool {
+		for _, ref := range output.VirtualNodes {
+			name := ref.VirtualNodeName
+			reuseOrDescribe(snapshotKey{kind: snapshotKindVirtualNode, name: aws.StringValue(name)}, ref.Version, func(ctx context.Context) (interface{}, error) {
+				resp, err := s.appMeshSDK.DescribeVirtualNodeWithContext(ctx, &appmesh.DescribeVirtualNodeInput{
+					MeshName:        s.meshName,
+					MeshOwner:       s.meshOwner,
+					VirtualNodeName: name,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return resp.VirtualNode, nil
+			})
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.appMeshSDK.ListVirtualServicesPagesWithContext(ctx, &appmesh.ListVirtualServicesInput{
+		MeshName:  s.meshName,
+		MeshOwner: s.meshOwner,
+	}, func(output *appmesh.ListVirtualServicesOutput, _ bool) bool {
+		for _, ref := range output.VirtualServices {
+			name := ref.VirtualServiceName
+			reuseOrDescribe(snapshotKey{kind: snapshotKindVirtualService, name: aws.StringValue(name)}, ref.Version, func(ctx context.Context) (interface{}, error) {
+				resp, err := s.appMeshSDK.DescribeVirtualServiceWithContext(ctx, &appmesh.DescribeVirtualServiceInput{
+					MeshName:           s.meshName,
+					MeshOwner:          s.meshOwner,
+					VirtualServiceName: name,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return resp.VirtualService, nil
+			})
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	var virtualRouterNames []*string
+	if err := s.appMeshSDK.ListVirtualRoutersPagesWithContext(ctx, &appmesh.ListVirtualRoutersInput{
+		MeshName:  s.meshName,
+		MeshOwner: s.meshOwner,
+	}, func(output *appmesh.ListVirtualRoutersOutput, _ bool) bool {
+		for _, ref := range output.VirtualRouters {
+			name := ref.VirtualRouterName
+			virtualRouterNames = append(virtualRouterNames, name)
+			reuseOrDescribe(snapshotKey{kind: snapshotKindVirtualRouter, name: aws.StringValue(name)}, ref.Version, func(ctx context.Context) (interface{}, error) {
+				resp, err := s.appMeshSDK.DescribeVirtualRouterWithContext(ctx, &appmesh.DescribeVirtualRouterInput{
+					MeshName:          s.meshName,
+					MeshOwner:         s.meshOwner,
+					VirtualRouterName: name,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return resp.VirtualRouter, nil
+			})
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	for _, virtualRouterName := range virtualRouterNames {
+		vrName := virtualRouterName
+		if err := s.appMeshSDK.ListRoutesPagesWithContext(ctx, &appmesh.ListRoutesInput{
+			MeshName:          s.meshName,
+			MeshOwner:         s.meshOwner,
+			VirtualRouterName: vrName,
+		}, func(output *appmesh.ListRoutesOutput, _ bool) bool {
+			for _, ref := range output.Routes {
+				name := ref.RouteName
+				reuseOrDescribe(snapshotKey{kind: snapshotKindRoute, parent: aws.StringValue(vrName), name: aws.StringValue(name)}, ref.Version, func(ctx context.Context) (interface{}, error) {
+					resp, err := s.appMeshSDK.DescribeRouteWithContext(ctx, &appmesh.DescribeRouteInput{
+						MeshName:          s.meshName,
+						MeshOwner:         s.meshOwner,
+						VirtualRouterName: vrName,
+						RouteName:         name,
+					})
+					if err != nil {
+						return nil, err
+					}
+					return resp.Route, nil
+				})
+			}
+			return true
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	var virtualGatewayNames []*string
+	if err := s.appMeshSDK.ListVirtualGatewaysPagesWithContext(ctx, &appmesh.ListVirtualGatewaysInput{
+		MeshName:  s.meshName,
+		MeshOwner: s.meshOwner,
+	}, func(output *appmesh.ListVirtualGatewaysOutput, _ bool) bool {
+		for _, ref := range output.VirtualGateways {
+			name := ref.VirtualGatewayName
+			virtualGatewayNames = append(virtualGatewayNames, name)
+			reuseOrDescribe(snapshotKey{kind: snapshotKindVirtualGateway, name: aws.StringValue(name)}, ref.Version, func(ctx context.Context) (interface{}, error) {
+				resp, err := s.appMeshSDK.DescribeVirtualGatewayWithContext(ctx, &appmesh.DescribeVirtualGatewayInput{
+					MeshName:           s.meshName,
+					MeshOwner:          s.meshOwner,
+					VirtualGatewayName: name,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return resp.VirtualGateway, nil
+			})
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	for _, virtualGatewayName := range virtualGatewayNames {
+		vgName := virtualGatewayName
+		if err := s.appMeshSDK.ListGatewayRoutesPagesWithContext(ctx, &appmesh.ListGatewayRoutesInput{
+			MeshName:           s.meshName,
+			MeshOwner:          s.meshOwner,
+			VirtualGatewayName: vgName,
+		}, func(output *appmesh.ListGatewayRoutesOutput, _ bool) bool {
+			for _, ref := range output.GatewayRoutes {
+				name := ref.GatewayRouteName
+				reuseOrDescribe(snapshotKey{kind: snapshotKindGatewayRoute, parent: aws.StringValue(vgName), name: aws.StringValue(name)}, ref.Version, func(ctx context.Context) (interface{}, error) {
+					resp, err := s.appMeshSDK.DescribeGatewayRouteWithContext(ctx, &appmesh.DescribeGatewayRouteInput{
+						MeshName:           s.meshName,
+						MeshOwner:          s.meshOwner,
+						VirtualGatewayName: vgName,
+						GatewayRouteName:   name,
+					})
+					if err != nil {
+						return nil, err
+					}
+					return resp.GatewayRoute, nil
+				})
+			}
+			return true
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := runSnapshotDescribeTasks(ctx, tasks, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// runSnapshotDescribeTasks runs tasks concurrently and stores described resources into entries.
+// resources deleted since being listed are skipped.
+func runSnapshotDescribeTasks(ctx context.Context, tasks []snapshotDescribeTask, entries map[snapshotKey]interface{}) error {
+	type taskResult struct {
+		data interface{}
+		err  error
+	}
+	results := make([]taskResult, len(tasks))
+	taskIndexChan := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < defaultSnapshotDescribeConcurrency && i < len(tasks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range taskIndexChan {
+				results[index].data, results[index].err = tasks[index].describe(ctx)
+			}
+		}()
+	}
+	for index := range tasks {
+		taskIndexChan <- index
+	}
+	close(taskIndexChan)
+	wg.Wait()
+
+	for index, result := range results {
+		if result.err != nil {
+			if isAppMeshNotFoundError(result.err) {
+				continue
+			}
+			return result.err
+		}
+		entries[tasks[index].key] = result.data
+	}
+	return nil
+}
+
+// snapshotDataVersion returns the version of resource data stored in snapshot.
+func snapshotDataVersion(data interface{}) *int64 {
+	var metadata *appmesh.ResourceMetadata
+	switch v := data.(type) {
+	case *appmesh.VirtualNodeData:
+		metadata = v.Metadata
+	case *appmesh.VirtualServiceData:
+		metadata = v.Metadata
+	case *appmesh.VirtualRouterData:
+		metadata = v.Metadata
+	case *appmesh.RouteData:
+		metadata = v.Metadata
+	case *appmesh.VirtualGatewayData:
+		metadata = v.Metadata
+	case *appmesh.GatewayRouteData:
+		metadata = v.Metadata
+	}
+	if metadata == nil {
+		return nil
+	}
+	return metadata.Version
+}
+
+func isAppMeshNotFoundError(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == appmesh.ErrCodeNotFoundException {
+		return true
+	}
+	return false
+}
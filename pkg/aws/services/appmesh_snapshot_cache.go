@@ -0,0 +1,387 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/appmesh"
+)
+
+// NewSnapshotCachedAppMesh constructs AppMesh that serves describe calls from per-mesh snapshots.
+// Snapshots are refreshed every refreshInterval, and updated by create/update/delete calls made through the returned AppMesh.
+// Resources missing from snapshots are still described from AppMesh, so resources created elsewhere are never missed.
+func NewSnapshotCachedAppMesh(appMeshSDK AppMesh, refreshInterval time.Duration) AppMesh {
+	return &snapshotCachedAppMesh{
+		AppMesh:         appMeshSDK,
+		refreshInterval: refreshInterval,
+		snapshots:       make(map[snapshotMeshKey]*meshSnapshot),
+		now:             time.Now,
+	}
+}
+
+// snapshotMeshKey identifies a mesh.
+type snapshotMeshKey struct {
+	meshName  string
+	meshOwner string
+}
+
+var _ AppMesh = &snapshotCachedAppMesh{}
+
+// snapshotCachedAppMesh decorates AppMesh with per-mesh snapshots.
+type snapshotCachedAppMesh struct {
+	AppMesh
+	refreshInterval time.Duration
+
+	mutex     sync.Mutex
+	snapshots map[snapshotMeshKey]*meshSnapshot
+	now       func() time.Time
+}
+
+// snapshotOf returns the snapshot of mesh.
+func (c *snapshotCachedAppMesh) snapshotOf(meshName *string, meshOwner *string) *meshSnapshot {
+	key := snapshotMeshKey{meshName: aws.StringValue(meshName), meshOwner: aws.StringValue(meshOwner)}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	snapshot, ok := c.snapshots[key]
+	if !ok {
+		var snapshotMeshOwner *string
+		if meshOwner != nil {
+			snapshotMeshOwner = aws.String(key.meshOwner)
+		}
+		snapshot = newMeshSnapshot(c.AppMesh, aws.String(key.meshName), snapshotMeshOwner)
+		c.snapshots[key] = snapshot
+	}
+	return snapshot
+}
+
+// observeWrite updates snapshot after a write of resource.
+// the resource is invalidated if the write failed, since it may have been partially applied.
+func observeWrite(snapshot *meshSnapshot, key snapshotKey, data interface{}, err error) {
+	if err != nil {
+		snapshot.invalidate(key)
+		return
+	}
+	snapshot.set(key, data)
+}
+
+// observeDescribe updates snapshot after a describe of resource that's missing from snapshot.
+func observeDescribe(snapshot *meshSnapshot, key snapshotKey, data interface{}, err error) {
+	if err != nil {
+		if isAppMeshNotFoundError(err) {
+			snapshot.invalidate(key)
+		}
+		return
+	}
+	snapshot.set(key, data)
+}
+
+// observeDelete updates snapshot after a delete of resource.
+// resources nested under it are invalidated as well, since AppMesh only deletes resources without children,
+// and they may have been deleted out of band if the delete succeeded.
+func observeDelete(snapshot *meshSnapshot, key snapshotKey) {
+	snapshot.invalidate(key)
+	snapshot.invalidateChildren(key)
+}
+
+func (c *snapshotCachedAppMesh) DeleteMeshWithContext(ctx aws.Context, input *appmesh.DeleteMeshInput, opts ...request.Option) (*appmesh.DeleteMeshOutput, error) {
+	resp, err := c.AppMesh.DeleteMeshWithContext(ctx, input, opts...)
+	// DeleteMeshInput carries no meshOwner, the mesh may be snapshotted both without owner and with the caller's account as owner.
+	c.mutex.Lock()
+	for key := range c.snapshots {
+		if key.meshName == aws.StringValue(input.MeshName) {
+			delete(c.snapshots, key)
+		}
+	}
+	c.mutex.Unlock()
+	return resp, err
+}
+
+func (c *snapshotCachedAppMesh) DescribeVirtualNodeWithContext(ctx aws.Context, input *appmesh.DescribeVirtualNodeInput, opts ...request.Option) (*appmesh.DescribeVirtualNodeOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	snapshot.refreshIfStale(ctx, c.refreshInterval, c.now())
+	key := snapshotKey{kind: snapshotKindVirtualNode, name: aws.StringValue(input.VirtualNodeName)}
+	if data, ok := snapshot.get(key); ok {
+		return &appmesh.DescribeVirtualNodeOutput{VirtualNode: data.(*appmesh.VirtualNodeData)}, nil
+	}
+	resp, err := c.AppMesh.DescribeVirtualNodeWithContext(ctx, input, opts...)
+	if err != nil {
+		observeDescribe(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeDescribe(snapshot, key, resp.VirtualNode, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) CreateVirtualNodeWithContext(ctx aws.Context, input *appmesh.CreateVirtualNodeInput, opts ...request.Option) (*appmesh.CreateVirtualNodeOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualNode, name: aws.StringValue(input.VirtualNodeName)}
+	resp, err := c.AppMesh.CreateVirtualNodeWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.VirtualNode, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) UpdateVirtualNodeWithContext(ctx aws.Context, input *appmesh.UpdateVirtualNodeInput, opts ...request.Option) (*appmesh.UpdateVirtualNodeOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualNode, name: aws.StringValue(input.VirtualNodeName)}
+	resp, err := c.AppMesh.UpdateVirtualNodeWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.VirtualNode, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) DeleteVirtualNodeWithContext(ctx aws.Context, input *appmesh.DeleteVirtualNodeInput, opts ...request.Option) (*appmesh.DeleteVirtualNodeOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualNode, name: aws.StringValue(input.VirtualNodeName)}
+	resp, err := c.AppMesh.DeleteVirtualNodeWithContext(ctx, input, opts...)
+	observeDelete(snapshot, key)
+	return resp, err
+}
+
+func (c *snapshotCachedAppMesh) DescribeVirtualServiceWithContext(ctx aws.Context, input *appmesh.DescribeVirtualServiceInput, opts ...request.Option) (*appmesh.DescribeVirtualServiceOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	snapshot.refreshIfStale(ctx, c.refreshInterval, c.now())
+	key := snapshotKey{kind: snapshotKindVirtualService, name: aws.StringValue(input.VirtualServiceName)}
+	if data, ok := snapshot.get(key); ok {
+		return &appmesh.DescribeVirtualServiceOutput{VirtualService: data.(*appmesh.VirtualServiceData)}, nil
+	}
+	resp, err := c.AppMesh.DescribeVirtualServiceWithContext(ctx, input, opts...)
+	if err != nil {
+		observeDescribe(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeDescribe(snapshot, key, resp.VirtualService, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) CreateVirtualServiceWithContext(ctx aws.Context, input *appmesh.CreateVirtualServiceInput, opts ...request.Option) (*appmesh.CreateVirtualServiceOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualService, name: aws.StringValue(input.VirtualServiceName)}
+	resp, err := c.AppMesh.CreateVirtualServiceWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.VirtualService, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) UpdateVirtualServiceWithContext(ctx aws.Context, input *appmesh.UpdateVirtualServiceInput, opts ...request.Option) (*appmesh.UpdateVirtualServiceOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualService, name: aws.StringValue(input.VirtualServiceName)}
+	resp, err := c.AppMesh.UpdateVirtualServiceWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.VirtualService, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) DeleteVirtualServiceWithContext(ctx aws.Context, input *appmesh.DeleteVirtualServiceInput, opts ...request.Option) (*appmesh.DeleteVirtualServiceOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualService, name: aws.StringValue(input.VirtualServiceName)}
+	resp, err := c.AppMesh.DeleteVirtualServiceWithContext(ctx, input, opts...)
+	observeDelete(snapshot, key)
+	return resp, err
+}
+
+func (c *snapshotCachedAppMesh) DescribeVirtualRouterWithContext(ctx aws.Context, input *appmesh.DescribeVirtualRouterInput, opts ...request.Option) (*appmesh.DescribeVirtualRouterOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	snapshot.refreshIfStale(ctx, c.refreshInterval, c.now())
+	key := snapshotKey{kind: snapshotKindVirtualRouter, name: aws.StringValue(input.VirtualRouterName)}
+	if data, ok := snapshot.get(key); ok {
+		return &appmesh.DescribeVirtualRouterOutput{VirtualRouter: data.(*appmesh.VirtualRouterData)}, nil
+	}
+	resp, err := c.AppMesh.DescribeVirtualRouterWithContext(ctx, input, opts...)
+	if err != nil {
+		observeDescribe(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeDescribe(snapshot, key, resp.VirtualRouter, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) CreateVirtualRouterWithContext(ctx aws.Context, input *appmesh.CreateVirtualRouterInput, opts ...request.Option) (*appmesh.CreateVirtualRouterOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualRouter, name: aws.StringValue(input.VirtualRouterName)}
+	resp, err := c.AppMesh.CreateVirtualRouterWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.VirtualRouter, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) UpdateVirtualRouterWithContext(ctx aws.Context, input *appmesh.UpdateVirtualRouterInput, opts ...request.Option) (*appmesh.UpdateVirtualRouterOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualRouter, name: aws.StringValue(input.VirtualRouterName)}
+	resp, err := c.AppMesh.UpdateVirtualRouterWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.VirtualRouter, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) DeleteVirtualRouterWithContext(ctx aws.Context, input *appmesh.DeleteVirtualRouterInput, opts ...request.Option) (*appmesh.DeleteVirtualRouterOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualRouter, name: aws.StringValue(input.VirtualRouterName)}
+	resp, err := c.AppMesh.DeleteVirtualRouterWithContext(ctx, input, opts...)
+	observeDelete(snapshot, key)
+	return resp, err
+}
+
+func (c *snapshotCachedAppMesh) DescribeRouteWithContext(ctx aws.Context, input *appmesh.DescribeRouteInput, opts ...request.Option) (*appmesh.DescribeRouteOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	snapshot.refreshIfStale(ctx, c.refreshInterval, c.now())
+	key := snapshotKey{kind: snapshotKindRoute, parent: aws.StringValue(input.VirtualRouterName), name: aws.StringValue(input.RouteName)}
+	if data, ok := snapshot.get(key); ok {
+		return &appmesh.DescribeRouteOutput{Route: data.(*appmesh.RouteData)}, nil
+	}
+	resp, err := c.AppMesh.DescribeRouteWithContext(ctx, input, opts...)
+	if err != nil {
+		observeDescribe(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeDescribe(snapshot, key, resp.Route, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) CreateRouteWithContext(ctx aws.Context, input *appmesh.CreateRouteInput, opts ...request.Option) (*appmesh.CreateRouteOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindRoute, parent: aws.StringValue(input.VirtualRouterName), name: aws.StringValue(input.RouteName)}
+	resp, err := c.AppMesh.CreateRouteWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.Route, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) UpdateRouteWithContext(ctx aws.Context, input *appmesh.UpdateRouteInput, opts ...request.Option) (*appmesh.UpdateRouteOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindRoute, parent: aws.StringValue(input.VirtualRouterName), name: aws.StringValue(input.RouteName)}
+	resp, err := c.AppMesh.UpdateRouteWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.Route, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) DeleteRouteWithContext(ctx aws.Context, input *appmesh.DeleteRouteInput, opts ...request.Option) (*appmesh.DeleteRouteOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindRoute, parent: aws.StringValue(input.VirtualRouterName), name: aws.StringValue(input.RouteName)}
+	resp, err := c.AppMesh.DeleteRouteWithContext(ctx, input, opts...)
+	observeDelete(snapshot, key)
+	return resp, err
+}
+
+func (c *snapshotCachedAppMesh) DescribeVirtualGatewayWithContext(ctx aws.Context, input *appmesh.DescribeVirtualGatewayInput, opts ...request.Option) (*appmesh.DescribeVirtualGatewayOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	snapshot.refreshIfStale(ctx, c.refreshInterval, c.now())
+	key := snapshotKey{kind: snapshotKindVirtualGateway, name: aws.StringValue(input.VirtualGatewayName)}
+	if data, ok := snapshot.get(key); ok {
+		return &appmesh.DescribeVirtualGatewayOutput{VirtualGateway: data.(*appmesh.VirtualGatewayData)}, nil
+	}
+	resp, err := c.AppMesh.DescribeVirtualGatewayWithContext(ctx, input, opts...)
+	if err != nil {
+		observeDescribe(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeDescribe(snapshot, key, resp.VirtualGateway, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) CreateVirtualGatewayWithContext(ctx aws.Context, input *appmesh.CreateVirtualGatewayInput, opts ...request.Option) (*appmesh.CreateVirtualGatewayOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualGateway, name: aws.StringValue(input.VirtualGatewayName)}
+	resp, err := c.AppMesh.CreateVirtualGatewayWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.VirtualGateway, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) UpdateVirtualGatewayWithContext(ctx aws.Context, input *appmesh.UpdateVirtualGatewayInput, opts ...request.Option) (*appmesh.UpdateVirtualGatewayOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualGateway, name: aws.StringValue(input.VirtualGatewayName)}
+	resp, err := c.AppMesh.UpdateVirtualGatewayWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.VirtualGateway, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) DeleteVirtualGatewayWithContext(ctx aws.Context, input *appmesh.DeleteVirtualGatewayInput, opts ...request.Option) (*appmesh.DeleteVirtualGatewayOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindVirtualGateway, name: aws.StringValue(input.VirtualGatewayName)}
+	resp, err := c.AppMesh.DeleteVirtualGatewayWithContext(ctx, input, opts...)
+	observeDelete(snapshot, key)
+	return resp, err
+}
+
+func (c *snapshotCachedAppMesh) DescribeGatewayRouteWithContext(ctx aws.Context, input *appmesh.DescribeGatewayRouteInput, opts ...request.Option) (*appmesh.DescribeGatewayRouteOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	snapshot.refreshIfStale(ctx, c.refreshInterval, c.now())
+	key := snapshotKey{kind: snapshotKindGatewayRoute, parent: aws.StringValue(input.VirtualGatewayName), name: aws.StringValue(input.GatewayRouteName)}
+	if data, ok := snapshot.get(key); ok {
+		return &appmesh.DescribeGatewayRouteOutput{GatewayRoute: data.(*appmesh.GatewayRouteData)}, nil
+	}
+	resp, err := c.AppMesh.DescribeGatewayRouteWithContext(ctx, input, opts...)
+	if err != nil {
+		observeDescribe(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeDescribe(snapshot, key, resp.GatewayRoute, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) CreateGatewayRouteWithContext(ctx aws.Context, input *appmesh.CreateGatewayRouteInput, opts ...request.Option) (*appmesh.CreateGatewayRouteOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindGatewayRoute, parent: aws.StringValue(input.VirtualGatewayName), name: aws.StringValue(input.GatewayRouteName)}
+	resp, err := c.AppMesh.CreateGatewayRouteWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.GatewayRoute, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) UpdateGatewayRouteWithContext(ctx aws.Context, input *appmesh.UpdateGatewayRouteInput, opts ...request.Option) (*appmesh.UpdateGatewayRouteOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindGatewayRoute, parent: aws.StringValue(input.VirtualGatewayName), name: aws.StringValue(input.GatewayRouteName)}
+	resp, err := c.AppMesh.UpdateGatewayRouteWithContext(ctx, input, opts...)
+	if err != nil {
+		observeWrite(snapshot, key, nil, err)
+		return nil, err
+	}
+	observeWrite(snapshot, key, resp.GatewayRoute, nil)
+	return resp, nil
+}
+
+func (c *snapshotCachedAppMesh) DeleteGatewayRouteWithContext(ctx aws.Context, input *appmesh.DeleteGatewayRouteInput, opts ...request.Option) (*appmesh.DeleteGatewayRouteOutput, error) {
+	snapshot := c.snapshotOf(input.MeshName, input.MeshOwner)
+	key := snapshotKey{kind: snapshotKindGatewayRoute, parent: aws.StringValue(input.VirtualGatewayName), name: aws.StringValue(input.GatewayRouteName)}
+	resp, err := c.AppMesh.DeleteGatewayRouteWithContext(ctx, input, opts...)
+	observeDelete(snapshot, key)
+	return resp, err
+}
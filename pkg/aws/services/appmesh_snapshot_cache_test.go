@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/appmesh"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSnapshotAppMesh is a fake AppMesh with virtualNodes and virtualRouters with routes, that counts calls by operation.
+type fakeSnapshotAppMesh struct {
+	AppMesh
+
+	mutex sync.Mutex
+	// virtualNodes by name.
+	virtualNodes map[string]*appmesh.VirtualNodeData
+	// routes by name by virtualRouter name.
+	routes  map[string]map[string]*appmesh.RouteData
+	listErr error
+	calls   map[string]int
+	// onDescribeVirtualNode is invoked before virtualNodes are described.
+	onDescribeVirtualNode func()
+}
+
+func newFakeSnapshotAppMesh() *fakeSnapshotAppMesh {
+	return &fakeSnapshotAppMesh{
+		virtualNodes: make(map[string]*appmesh.VirtualNodeData),
+		routes:       make(map[string]map[string]*appmesh.RouteData),
+		calls:        make(map[string]int),
+	}
+}
+
+func (f *fakeSnapshotAppMesh) putVirtualNode(name string, version int64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.virtualNodes[name] = &appmesh.VirtualNodeData{
+		VirtualNodeName: aws.String(name),
+		Metadata:        &appmesh.ResourceMetadata{Version: aws.Int64(version)},
+	}
+}
+
+func (f *fakeSnapshotAppMesh) putRoute(vrName string, name string, version int64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.routes[vrName] == nil {
+		f.routes[vrName] = make(map[string]*appmesh.RouteData)
+	}
+	f.routes[vrName][name] = &appmesh.RouteData{
+		VirtualRouterName: aws.String(vrName),
+		RouteName:         aws.String(name),
+		Metadata:          &appmesh.ResourceMetadata{Version: aws.Int64(version)},
+	}
+}
+
+func (f *fakeSnapshotAppMesh) countCall(operation string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls[operation]++
+}
+
+func (f *fakeSnapshotAppMesh) callCount(operation string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.calls[operation]
+}
+
+func (f *fakeSnapshotAppMesh) ListVirtualNodesPagesWithContext(_ aws.Context, _ *appmesh.ListVirtualNodesInput, fn func(*appmesh.ListVirtualNodesOutput, bool) bool, _ ...request.Option) error {
+	f.countCall("ListVirtualNodes")
+	if f.listErr != nil {
+		return f.listErr
+	}
+	f.mutex.Lock()
+	var refs []*appmesh.VirtualNodeRef
+	for name, vn := range f.virtualNodes {
+		refs = append(refs, &appmesh.VirtualNodeRef{VirtualNodeName: aws.String(name), Version: vn.Metadata.Version})
+	}
+	f.mutex.Unlock()
+	sort.Slice(refs, func(i, j int) bool {
+		return aws.StringValue(refs[i].VirtualNodeName) < aws.StringValue(refs[j].VirtualNodeName)
+	})
+	// one ref per page to exercise pagination.
+	for i, ref := range refs {
+		if !fn(&appmesh.ListVirtualNodesOutput{VirtualNodes: []*appmesh.VirtualNodeRef{ref}}, i == len(refs)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeSnapshotAppMesh) ListVirtualServicesPagesWithContext(_ aws.Context, _ *appmesh.ListVirtualServicesInput, fn func(*appmesh.ListVirtualServicesOutput, bool) bool, _ ...request.Option) error {
+	fn(&appmesh.ListVirtualServicesOutput{}, true)
+	return nil
+}
+
+func (f *fakeSnapshotAppMesh) ListVirtualRoutersPagesWithContext(_ aws.Context, _ *appmesh.ListVirtualRoutersInput, fn func(*appmesh.ListVirtualRoutersOutput, bool) bool, _ ...request.Option) error {
+	f.mutex.Lock()
+	var refs []*appmesh.VirtualRouterRef
+	for name := range f.routes {
+		refs = append(refs, &appmesh.VirtualRouterRef{VirtualRouterName: aws.String(name), Version: aws.Int64(1)})
+	}
+	f.mutex.Unlock()
+	fn(&appmesh.ListVirtualRoutersOutput{VirtualRouters: refs}, true)
+	return nil
+}
+
+func (f *fakeSnapshotAppMesh) ListRoutesPagesWithContext(_ aws.Context, input *appmesh.ListRoutesInput, fn func(*appmesh.ListRoutesOutput, bool) bool, _ ...request.Option) error {
+	f.mutex.Lock()
+	var refs []*appmesh.RouteRef
+	for name, route := range f.routes[aws.StringValue(input.VirtualRouterName)] {
+		refs = append(refs, &appmesh.RouteRef{VirtualRouterName: input.VirtualRouterName, RouteName: aws.String(name), Version: route.Metadata.Version})
+	}
+	f.mutex.Unlock()
+	fn(&appmesh.ListRoutesOutput{Routes: refs}, true)
+	return nil
+}
+
+func (f *fakeSnapshotAppMesh) ListVirtualGatewaysPagesWithContext(_ aws.Context, _ *appmesh.ListVirtualGatewaysInput, fn func(*appmesh.ListVirtualGatewaysOutput, bool) bool, _ ...request.Option) error {
+	fn(&appmesh.ListVirtualGatewaysOutput{}, true)
+	return nil
+}
+
+func (f *fakeSnapshotAppMesh) DescribeVirtualNodeWithContext(_ aws.Context, input *appmesh.DescribeVirtualNodeInput, _ ...request.Option) (*appmesh.DescribeVirtualNodeOutput, error) {
+	f.countCall("DescribeVirtualNode")
+	if f.onDescribeVirtualNode != nil {
+		f.onDescribeVirtualNode()
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	vn, ok := f.virtualNodes[aws.StringValue(input.VirtualNodeName)]
+	if !ok {
+		return nil, awserr.New(appmesh.ErrCodeNotFoundException, "virtualNode not found", nil)
+	}
+	return &appmesh.DescribeVirtualNodeOutput{VirtualNode: vn}, nil
+}
+
+func (f *fakeSnapshotAppMesh) DescribeVirtualRouterWithContext(_ aws.Context, input *appmesh.DescribeVirtualRouterInput, _ ...request.Option) (*appmesh.DescribeVirtualRouterOutput, error) {
+	return &appmesh.DescribeVirtualRouterOutput{VirtualRouter: &appmesh.VirtualRouterData{
+		VirtualRouterName: input.VirtualRouterName,
+		Metadata:          &appmesh.ResourceMetadata{Version: aws.Int64(1)},
+	}}, nil
+}
+
+func (f *fakeSnapshotAppMesh) DescribeRouteWithContext(_ aws.Context, input *appmesh.DescribeRouteInput, _ ...request.Option) (*appmesh.DescribeRouteOutput, error) {
+	f.countCall("DescribeRoute")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	route, ok := f.routes[aws.StringValue(input.VirtualRouterName)][aws.StringValue(input.RouteName)]
+	if !ok {
+		return nil, awserr.New(appmesh.ErrCodeNotFoundException, "route not found", nil)
+	}
+	return &appmesh.DescribeRouteOutput{Route: route}, nil
+}
+
+func (f *fakeSnapshotAppMesh) UpdateVirtualNodeWithContext(_ aws.Context, input *appmesh.UpdateVirtualNodeInput, _ ...request.Option) (*appmesh.UpdateVirtualNodeOutput, error) {
+	f.countCall("UpdateVirtualNode")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	vn, ok := f.virtualNodes[aws.StringValue(input.VirtualNodeName)]
+	if !ok {
+		return nil, awserr.New(appmesh.ErrCodeNotFoundException, "virtualNode not found", nil)
+	}
+	vn = &appmesh.VirtualNodeData{
+		VirtualNodeName: vn.VirtualNodeName,
+		Spec:            input.Spec,
+		Metadata:        &appmesh.ResourceMetadata{Version: aws.Int64(aws.Int64Value(vn.Metadata.Version) + 1)},
+	}
+	f.virtualNodes[aws.StringValue(input.VirtualNodeName)] = vn
+	return &appmesh.UpdateVirtualNodeOutput{VirtualNode: vn}, nil
+}
+
+func (f *fakeSnapshotAppMesh) DeleteMeshWithContext(_ aws.Context, _ *appmesh.DeleteMeshInput, _ ...request.Option) (*appmesh.DeleteMeshOutput, error) {
+	f.countCall("DeleteMesh")
+	return &appmesh.DeleteMeshOutput{}, nil
+}
+
+func (f *fakeSnapshotAppMesh) DeleteVirtualNodeWithContext(_ aws.Context, input *appmesh.DeleteVirtualNodeInput, _ ...request.Option) (*appmesh.DeleteVirtualNodeOutput, error) {
+	f.countCall("DeleteVirtualNode")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.virtualNodes, aws.StringValue(input.VirtualNodeName))
+	return &appmesh.DeleteVirtualNodeOutput{}, nil
+}
+
+func (f *fakeSnapshotAppMesh) DeleteVirtualRouterWithContext(_ aws.Context, input *appmesh.DeleteVirtualRouterInput, _ ...request.Option) (*appmesh.DeleteVirtualRouterOutput, error) {
+	f.countCall("DeleteVirtualRouter")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.routes, aws.StringValue(input.VirtualRouterName))
+	return &appmesh.DeleteVirtualRouterOutput{}, nil
+}
+
+func describeVirtualNodeVersion(t *testing.T, appMeshSDK AppMesh, name string) *int64 {
+	resp, err := appMeshSDK.DescribeVirtualNodeWithContext(context.Background(), &appmesh.DescribeVirtualNodeInput{
+		MeshName:        aws.String("mesh"),
+		VirtualNodeName: aws.String(name),
+	})
+	assert.NoError(t, err)
+	return resp.VirtualNode.Metadata.Version
+}
+
+func Test_snapshotCachedAppMesh_describe(t *testing.T) {
+	fakeSDK := newFakeSnapshotAppMesh()
+	fakeSDK.putVirtualNode("vn-1", 1)
+	fakeSDK.putVirtualNode("vn-2", 1)
+	fakeSDK.putRoute("vr-1", "route", 1)
+	fakeSDK.putRoute("vr-2", "route", 2)
+	cachedSDK := NewSnapshotCachedAppMesh(fakeSDK, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-1"))
+		assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-2"))
+	}
+	assert.Equal(t, 1, fakeSDK.callCount("ListVirtualNodes"))
+	assert.Equal(t, 2, fakeSDK.callCount("DescribeVirtualNode"))
+
+	// routes with same name under different virtualRouters are distinguished.
+	resp, err := cachedSDK.DescribeRouteWithContext(context.Background(), &appmesh.DescribeRouteInput{
+		MeshName:          aws.String("mesh"),
+		VirtualRouterName: aws.String("vr-2"),
+		RouteName:         aws.String("route"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, aws.Int64(2), resp.Route.Metadata.Version)
+	assert.Equal(t, 2, fakeSDK.callCount("DescribeRoute"))
+
+	// resources created elsewhere after the snapshot is loaded are still described.
+	fakeSDK.putVirtualNode("vn-3", 1)
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-3"))
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-3"))
+	assert.Equal(t, 3, fakeSDK.callCount("DescribeVirtualNode"))
+
+	// missing resources are reported as not found.
+	_, err = cachedSDK.DescribeVirtualNodeWithContext(context.Background(), &appmesh.DescribeVirtualNodeInput{
+		MeshName:        aws.String("mesh"),
+		VirtualNodeName: aws.String("vn-4"),
+	})
+	assert.True(t, isAppMeshNotFoundError(err))
+}
+
+func Test_snapshotCachedAppMesh_describeReturnsCopy(t *testing.T) {
+	fakeSDK := newFakeSnapshotAppMesh()
+	fakeSDK.putVirtualNode("vn-1", 1)
+	cachedSDK := NewSnapshotCachedAppMesh(fakeSDK, time.Hour)
+
+	resp, err := cachedSDK.DescribeVirtualNodeWithContext(context.Background(), &appmesh.DescribeVirtualNodeInput{
+		MeshName:        aws.String("mesh"),
+		VirtualNodeName: aws.String("vn-1"),
+	})
+	assert.NoError(t, err)
+	resp.VirtualNode.Metadata.Version = aws.Int64(42)
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-1"))
+}
+
+func Test_snapshotCachedAppMesh_writes(t *testing.T) {
+	fakeSDK := newFakeSnapshotAppMesh()
+	fakeSDK.putVirtualNode("vn-1", 1)
+	cachedSDK := NewSnapshotCachedAppMesh(fakeSDK, time.Hour)
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-1"))
+
+	_, err := cachedSDK.UpdateVirtualNodeWithContext(context.Background(), &appmesh.UpdateVirtualNodeInput{
+		MeshName:        aws.String("mesh"),
+		VirtualNodeName: aws.String("vn-1"),
+		Spec:            &appmesh.VirtualNodeSpec{},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, aws.Int64(2), describeVirtualNodeVersion(t, cachedSDK, "vn-1"))
+	assert.Equal(t, 1, fakeSDK.callCount("DescribeVirtualNode"))
+
+	_, err = cachedSDK.UpdateVirtualNodeWithContext(context.Background(), &appmesh.UpdateVirtualNodeInput{
+		MeshName:        aws.String("mesh"),
+		VirtualNodeName: aws.String("vn-2"),
+		Spec:            &appmesh.VirtualNodeSpec{},
+	})
+	assert.Error(t, err)
+
+	_, err = cachedSDK.DeleteVirtualNodeWithContext(context.Background(), &appmesh.DeleteVirtualNodeInput{
+		MeshName:        aws.String("mesh"),
+		VirtualNodeName: aws.String("vn-1"),
+	})
+	assert.NoError(t, err)
+	_, err = cachedSDK.DescribeVirtualNodeWithContext(context.Background(), &appmesh.DescribeVirtualNodeInput{
+		MeshName:        aws.String("mesh"),
+		VirtualNodeName: aws.String("vn-1"),
+	})
+	assert.True(t, isAppMeshNotFoundError(err))
+	assert.Equal(t, 2, fakeSDK.callCount("DescribeVirtualNode"))
+}
+
+func Test_snapshotCachedAppMesh_deleteMesh(t *testing.T) {
+	fakeSDK := newFakeSnapshotAppMesh()
+	cachedSDK := NewSnapshotCachedAppMesh(fakeSDK, time.Hour).(*snapshotCachedAppMesh)
+	cachedSDK.snapshotOf(aws.String("mesh"), nil)
+	cachedSDK.snapshotOf(aws.String("mesh"), aws.String("222222222222"))
+	cachedSDK.snapshotOf(aws.String("other-mesh"), aws.String("222222222222"))
+
+	_, err := cachedSDK.DeleteMeshWithContext(context.Background(), &appmesh.DeleteMeshInput{MeshName: aws.String("mesh")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[snapshotMeshKey]bool{
+		{meshName: "other-mesh", meshOwner: "222222222222"}: true,
+	}, snapshotMeshKeys(cachedSDK))
+}
+
+func Test_snapshotCachedAppMesh_deleteVirtualRouter(t *testing.T) {
+	fakeSDK := newFakeSnapshotAppMesh()
+	fakeSDK.putRoute("vr-1", "route", 1)
+	fakeSDK.putRoute("vr-2", "route", 1)
+	cachedSDK := NewSnapshotCachedAppMesh(fakeSDK, time.Hour)
+	describeRoute := func(vrName string) error {
+		_, err := cachedSDK.DescribeRouteWithContext(context.Background(), &appmesh.DescribeRouteInput{
+			MeshName:          aws.String("mesh"),
+			VirtualRouterName: aws.String(vrName),
+			RouteName:         aws.String("route"),
+		})
+		return err
+	}
+	assert.NoError(t, describeRoute("vr-1"))
+	assert.NoError(t, describeRoute("vr-2"))
+	assert.Equal(t, 2, fakeSDK.callCount("DescribeRoute"))
+
+	// routes of a deleted virtualRouter are evicted, while routes of other virtualRouters are still served from snapshot.
+	_, err := cachedSDK.DeleteVirtualRouterWithContext(context.Background(), &appmesh.DeleteVirtualRouterInput{
+		MeshName:          aws.String("mesh"),
+		VirtualRouterName: aws.String("vr-1"),
+	})
+	assert.NoError(t, err)
+	assert.True(t, isAppMeshNotFoundError(describeRoute("vr-1")))
+	assert.NoError(t, describeRoute("vr-2"))
+	assert.Equal(t, 3, fakeSDK.callCount("DescribeRoute"))
+}
+
+func snapshotMeshKeys(c *snapshotCachedAppMesh) map[snapshotMeshKey]bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	keys := make(map[snapshotMeshKey]bool)
+	for key := range c.snapshots {
+		keys[key] = true
+	}
+	return keys
+}
+
+func Test_snapshotCachedAppMesh_refresh(t *testing.T) {
+	fakeSDK := newFakeSnapshotAppMesh()
+	for _, name := range []string{"vn-1", "vn-2", "vn-3"} {
+		fakeSDK.putVirtualNode(name, 1)
+	}
+	cachedSDK := NewSnapshotCachedAppMesh(fakeSDK, time.Minute).(*snapshotCachedAppMesh)
+	now := time.Now()
+	cachedSDK.now = func() time.Time { return now }
+
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-1"))
+	assert.Equal(t, 3, fakeSDK.callCount("DescribeVirtualNode"))
+
+	// changes made elsewhere are not observed until refresh.
+	fakeSDK.putVirtualNode("vn-2", 2)
+	fakeSDK.mutex.Lock()
+	delete(fakeSDK.virtualNodes, "vn-3")
+	fakeSDK.mutex.Unlock()
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-2"))
+
+	// only resources with changed version are described on refresh.
+	now = now.Add(time.Minute)
+	assert.Equal(t, aws.Int64(2), describeVirtualNodeVersion(t, cachedSDK, "vn-2"))
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-1"))
+	assert.Equal(t, 2, fakeSDK.callCount("ListVirtualNodes"))
+	assert.Equal(t, 4, fakeSDK.callCount("DescribeVirtualNode"))
+	_, err := cachedSDK.DescribeVirtualNodeWithContext(context.Background(), &appmesh.DescribeVirtualNodeInput{
+		MeshName:        aws.String("mesh"),
+		VirtualNodeName: aws.String("vn-3"),
+	})
+	assert.True(t, isAppMeshNotFoundError(err))
+}
+
+func Test_snapshotCachedAppMesh_loadFailure(t *testing.T) {
+	fakeSDK := newFakeSnapshotAppMesh()
+	fakeSDK.putVirtualNode("vn-1", 1)
+	fakeSDK.listErr = errors.New("list failed")
+	cachedSDK := NewSnapshotCachedAppMesh(fakeSDK, time.Hour)
+
+	// describe calls fall back to AppMesh, and the load isn't retried until next refresh.
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-1"))
+	assert.Equal(t, aws.Int64(1), describeVirtualNodeVersion(t, cachedSDK, "vn-1"))
+	assert.Equal(t, 1, fakeSDK.callCount("ListVirtualNodes"))
+	assert.Equal(t, 2, fakeSDK.callCount("DescribeVirtualNode"))
+}
+
+func Test_meshSnapshot_writeDuringLoad(t *testing.T) {
+	fakeSDK := newFakeSnapshotAppMesh()
+	fakeSDK.putVirtualNode("vn-1", 1)
+	fakeSDK.putVirtualNode("vn-2", 1)
+	snapshot := newMeshSnapshot(fakeSDK, aws.String("mesh"), nil)
+	vn1Key := snapshotKey{kind: snapshotKindVirtualNode, name: "vn-1"}
+	vn2Key := snapshotKey{kind: snapshotKindVirtualNode, name: "vn-2"}
+	var once sync.Once
+	fakeSDK.onDescribeVirtualNode = func() {
+		once.Do(func() {
+			snapshot.set(vn1Key, &appmesh.VirtualNodeData{
+				VirtualNodeName: aws.String("vn-1"),
+				Metadata:        &appmesh.ResourceMetadata{Version: aws.Int64(5)},
+			})
+			snapshot.invalidate(vn2Key)
+		})
+	}
+
+	snapshot.refreshIfStale(context.Background(), time.Hour, time.Now())
+	data, ok := snapshot.get(vn1Key)
+	assert.True(t, ok)
+	assert.Equal(t, aws.Int64(5), snapshotDataVersion(data))
+	_, ok = snapshot.get(vn2Key)
+	assert.False(t, ok)
+}
@@ -0,0 +1,101 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// the rate is multiplied by this factor on throttling errors.
+	defaultRateDecreaseFactor = 0.5
+	// the rate won't be decreased again within this duration, since concurrent requests are usually throttled together.
+	defaultRateDecreaseCooldown = 1 * time.Second
+	// the rate is increased by this fraction of maxRate after every increase interval without throttling errors.
+	defaultRateIncreaseStep     = 0.05
+	defaultRateIncreaseInterval = 5 * time.Second
+	// the rate won't be decreased below this fraction of maxRate.
+	defaultMinRateFactor = 0.05
+)
+
+// adaptiveLimiter is a rate limiter that adapts its rate to throttling errors from AWS APIs with AIMD.
+// The rate is decreased multiplicatively on throttling errors, and recovers additively up to maxRate afterwards.
+type adaptiveLimiter struct {
+	limiter *rate.Limiter
+	maxRate rate.Limit
+	minRate rate.Limit
+
+	mutex sync.Mutex
+	// last time the rate have been changed.
+	lastChangeTime time.Time
+	// onRateChange is invoked with the new rate whenever the rate is changed.
+	onRateChange func(r rate.Limit)
+	now          func() time.Time
+}
+
+// newAdaptiveLimiter constructs new adaptiveLimiter starting at maxRate.
+func newAdaptiveLimiter(maxRate rate.Limit, burst int, onRateChange func(r rate.Limit)) *adaptiveLimiter {
+	if onRateChange == nil {
+		onRateChange = func(r rate.Limit) {}
+	}
+	onRateChange(maxRate)
+	return &adaptiveLimiter{
+		limiter:      rate.NewLimiter(maxRate, burst),
+		maxRate:      maxRate,
+		minRate:      maxRate * defaultMinRateFactor,
+		onRateChange: onRateChange,
+		now:          time.Now,
+	}
+}
+
+// Wait blocks until a request is allowed by the current rate.
+func (l *adaptiveLimiter) Wait(ctx context.Context) error {
+	return l.limiter.Wait(ctx)
+}
+
+// OnThrottled decreases the rate after a request have been throttled.
+func (l *adaptiveLimiter) OnThrottled() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := l.now()
+	if now.Sub(l.lastChangeTime) < defaultRateDecreaseCooldown {
+		return
+	}
+	newRate := l.limiter.Limit() * defaultRateDecreaseFactor
+	if newRate < l.minRate {
+		newRate = l.minRate
+	}
+	l.setRate(newRate, now)
+}
+
+// OnSucceeded recovers the rate after a request succeeded.
+func (l *adaptiveLimiter) OnSucceeded() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	currentRate := l.limiter.Limit()
+	if currentRate >= l.maxRate {
+		return
+	}
+	now := l.now()
+	if now.Sub(l.lastChangeTime) < defaultRateIncreaseInterval {
+		return
+	}
+	newRate := currentRate + l.maxRate*defaultRateIncreaseStep
+	if newRate > l.maxRate {
+		newRate = l.maxRate
+	}
+	l.setRate(newRate, now)
+}
+
+// Limit returns the current rate.
+func (l *adaptiveLimiter) Limit() rate.Limit {
+	return l.limiter.Limit()
+}
+
+func (l *adaptiveLimiter) setRate(r rate.Limit, now time.Time) {
+	l.limiter.SetLimitAt(now, r)
+	l.lastChangeTime = now
+	l.onRateChange(r)
+}
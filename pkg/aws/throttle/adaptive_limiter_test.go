@@ -0,0 +1,63 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func Test_adaptiveLimiter(t *testing.T) {
+	now := time.Now()
+	var reportedRates []rate.Limit
+	l := newAdaptiveLimiter(10, 5, func(r rate.Limit) {
+		reportedRates = append(reportedRates, r)
+	})
+	l.now = func() time.Time { return now }
+	assert.Equal(t, rate.Limit(10), l.Limit())
+
+	// succeeded requests don't increase the rate beyond maxRate.
+	l.OnSucceeded()
+	assert.Equal(t, rate.Limit(10), l.Limit())
+
+	// throttled requests decrease the rate multiplicatively.
+	l.OnThrottled()
+	assert.Equal(t, rate.Limit(5), l.Limit())
+
+	// concurrent throttled requests only decrease the rate once.
+	now = now.Add(100 * time.Millisecond)
+	l.OnThrottled()
+	assert.Equal(t, rate.Limit(5), l.Limit())
+
+	now = now.Add(defaultRateDecreaseCooldown)
+	l.OnThrottled()
+	assert.Equal(t, rate.Limit(2.5), l.Limit())
+
+	// the rate doesn't go below minRate.
+	for i := 0; i < 10; i++ {
+		now = now.Add(defaultRateDecreaseCooldown)
+		l.OnThrottled()
+	}
+	assert.Equal(t, rate.Limit(0.5), l.Limit())
+
+	// succeeded requests recover the rate additively after every increase interval.
+	now = now.Add(time.Second)
+	l.OnSucceeded()
+	assert.Equal(t, rate.Limit(0.5), l.Limit())
+	now = now.Add(defaultRateIncreaseInterval)
+	l.OnSucceeded()
+	assert.InDelta(t, 1.0, float64(l.Limit()), 1e-9)
+	now = now.Add(defaultRateIncreaseInterval)
+	l.OnSucceeded()
+	assert.InDelta(t, 1.5, float64(l.Limit()), 1e-9)
+
+	// the rate recovers up to maxRate.
+	for i := 0; i < 100; i++ {
+		now = now.Add(defaultRateIncreaseInterval)
+		l.OnSucceeded()
+	}
+	assert.Equal(t, rate.Limit(10), l.Limit())
+	assert.Equal(t, rate.Limit(10), reportedRates[len(reportedRates)-1])
+	assert.Equal(t, rate.Limit(10), reportedRates[0])
+}
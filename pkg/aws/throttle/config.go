@@ -100,3 +100,12 @@ func (c *ServiceOperationsThrottleConfig) Set(val string) error {
 func (c *ServiceOperationsThrottleConfig) Type() string {
 	return "serviceOperationsThrottleConfig"
 }
+
+// DeepCopy returns a copy of the config, so that it can be overridden without affecting the original one.
+func (c *ServiceOperationsThrottleConfig) DeepCopy() *ServiceOperationsThrottleConfig {
+	value := make(map[string][]throttleConfig, len(c.value))
+	for serviceID, operationsThrottleConfigs := range c.value {
+		value[serviceID] = append([]throttleConfig(nil), operationsThrottleConfigs...)
+	}
+	return &ServiceOperationsThrottleConfig{value: value}
+}
@@ -0,0 +1,98 @@
+package throttle
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const defaultConfigFilePollPeriod = 30 * time.Second
+
+// ConfigFileWatcher reconfigures throttler at runtime with the throttle settings in a file,
+// such as a mounted ConfigMap. The file has the same format as --aws-api-throttle, with one or more
+// comma or newline separated settings, which override baseConfig.
+type ConfigFileWatcher struct {
+	path       string
+	baseConfig *ServiceOperationsThrottleConfig
+	throttler  Throttler
+	pollPeriod time.Duration
+	log        logr.Logger
+
+	// content of the file when throttler is last reconfigured.
+	lastContent *string
+}
+
+var _ manager.Runnable = &ConfigFileWatcher{}
+var _ manager.LeaderElectionRunnable = &ConfigFileWatcher{}
+
+// NewConfigFileWatcher constructs new ConfigFileWatcher.
+func NewConfigFileWatcher(path string, baseConfig *ServiceOperationsThrottleConfig, throttler Throttler, log logr.Logger) *ConfigFileWatcher {
+	return &ConfigFileWatcher{
+		path:       path,
+		baseConfig: baseConfig.DeepCopy(),
+		throttler:  throttler,
+		pollPeriod: defaultConfigFilePollPeriod,
+		log:        log,
+	}
+}
+
+// Start polls the file until ctx is done.
+func (w *ConfigFileWatcher) Start(ctx context.Context) error {
+	for {
+		if err := w.reconcile(); err != nil {
+			w.log.Error(err, "failed to reconfigure AWS API throttle", "path", w.path)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(w.pollPeriod):
+		}
+	}
+}
+
+// NeedLeaderElection returns false, since AWS APIs are called by every replica.
+func (w *ConfigFileWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// reconcile reconfigures throttler if content of the file changed.
+func (w *ConfigFileWatcher) reconcile() error {
+	raw, err := os.ReadFile(w.path)
+	if err != nil {
+		return err
+	}
+	content := string(raw)
+	if w.lastContent != nil && *w.lastContent == content {
+		return nil
+	}
+	config, err := w.buildConfig(content)
+	if err != nil {
+		return err
+	}
+	w.throttler.Reconfigure(config)
+	w.lastContent = &content
+	w.log.Info("reconfigured AWS API throttle", "throttle", config.String())
+	return nil
+}
+
+func (w *ConfigFileWatcher) buildConfig(content string) (*ServiceOperationsThrottleConfig, error) {
+	var settings []string
+	for _, setting := range strings.FieldsFunc(content, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if setting = strings.TrimSpace(setting); setting != "" {
+			settings = append(settings, setting)
+		}
+	}
+	config := w.baseConfig.DeepCopy()
+	if len(settings) == 0 {
+		return config, nil
+	}
+	if err := config.Set(strings.Join(settings, ",")); err != nil {
+		return nil, errors.Wrapf(err, "invalid throttle settings in %s", w.path)
+	}
+	return config, nil
+}
@@ -0,0 +1,58 @@
+package throttle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type fakeThrottler struct {
+	configs []*ServiceOperationsThrottleConfig
+}
+
+func (t *fakeThrottler) InjectHandlers(handlers *request.Handlers) {}
+
+func (t *fakeThrottler) Reconfigure(config *ServiceOperationsThrottleConfig) {
+	t.configs = append(t.configs, config)
+}
+
+func Test_ConfigFileWatcher_reconcile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "throttle")
+	baseConfig := &ServiceOperationsThrottleConfig{}
+	assert.NoError(t, baseConfig.Set("appmesh:^Describe=40:5,servicediscovery:^ListServices=1:8"))
+	throttler := &fakeThrottler{}
+	w := NewConfigFileWatcher(path, baseConfig, throttler, logr.New(&log.NullLogSink{}))
+
+	// missing file is an error.
+	assert.Error(t, w.reconcile())
+	assert.Len(t, throttler.configs, 0)
+
+	// settings in file override the base config of the same service.
+	assert.NoError(t, os.WriteFile(path, []byte("appmesh:^Describe=10:5\nappmesh:^Create=2:1\n"), 0644))
+	assert.NoError(t, w.reconcile())
+	assert.Len(t, throttler.configs, 1)
+	assert.Equal(t, "appmesh:^Describe=10:5,appmesh:^Create=2:1,servicediscovery:^ListServices=1:8", throttler.configs[0].String())
+
+	// unchanged file doesn't reconfigure throttler.
+	assert.NoError(t, w.reconcile())
+	assert.Len(t, throttler.configs, 1)
+
+	// invalid settings keep the previous settings.
+	assert.NoError(t, os.WriteFile(path, []byte("appmesh:^Describe=fast"), 0644))
+	assert.Error(t, w.reconcile())
+	assert.Len(t, throttler.configs, 1)
+
+	// empty file restores the base config.
+	assert.NoError(t, os.WriteFile(path, []byte(""), 0644))
+	assert.NoError(t, w.reconcile())
+	assert.Len(t, throttler.configs, 2)
+	assert.Equal(t, "appmesh:^Describe=40:5,servicediscovery:^ListServices=1:8", throttler.configs[1].String())
+
+	// base config isn't modified by overrides.
+	assert.Equal(t, "appmesh:^Describe=40:5,servicediscovery:^ListServices=1:8", baseConfig.String())
+}
@@ -0,0 +1,36 @@
+package throttle
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricSubsystemAWS = "aws"
+
+	metricAPIThrottleRateLimit = "api_throttle_rate_limit"
+)
+
+const (
+	labelService   = "service"
+	labelOperation = "operation"
+)
+
+type instruments struct {
+	rateLimit *prometheus.GaugeVec
+}
+
+// newInstruments allocates and register new metrics to registerer
+func newInstruments(registerer prometheus.Registerer) (*instruments, error) {
+	rateLimit := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metricSubsystemAWS,
+		Name:      metricAPIThrottleRateLimit,
+		Help:      "Current effective rate in requests per second that SDK API calls to AWS services are throttled to, by operation pattern",
+	}, []string{labelService, labelOperation})
+
+	if err := registerer.Register(rateLimit); err != nil {
+		return nil, err
+	}
+	return &instruments{
+		rateLimit: rateLimit,
+	}, nil
+}
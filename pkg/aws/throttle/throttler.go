@@ -1,56 +1,122 @@
 package throttle
 
 import (
+	"regexp"
+	"sync"
+
 	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/time/rate"
-	"regexp"
 )
 
-const sdkHandlerRequestThrottle = "requestThrottle"
+const (
+	sdkHandlerRequestThrottle  = "requestThrottle"
+	sdkHandlerThrottleFeedback = "throttleFeedback"
+)
+
+// Throttler throttles requests to AWS APIs.
+type Throttler interface {
+	// InjectHandlers injects handlers to throttle requests.
+	InjectHandlers(handlers *request.Handlers)
+
+	// Reconfigure replaces the throttle settings of requests.
+	Reconfigure(config *ServiceOperationsThrottleConfig)
+}
 
 type conditionLimiter struct {
 	condition Condition
-	limiter   *rate.Limiter
+	limiter   *adaptiveLimiter
 }
 
+var _ Throttler = &throttler{}
+
 type throttler struct {
+	// whether limiters adapt to throttling errors.
+	adaptive bool
+	// nil if metrics aren't collected.
+	instruments *instruments
+
+	mutex             sync.RWMutex
 	conditionLimiters []conditionLimiter
 }
 
 // NewThrottler constructs new request throttler instance.
-func NewThrottler(config *ServiceOperationsThrottleConfig) *throttler {
-	throttler := &throttler{}
+// When adaptive is set, the rate of requests is lowered on throttling errors and recovers slowly afterwards.
+// The effective rates are exported as metrics if metricsRegisterer isn't nil.
+func NewThrottler(config *ServiceOperationsThrottleConfig, adaptive bool, metricsRegisterer prometheus.Registerer) (*throttler, error) {
+	throttler := &throttler{adaptive: adaptive}
+	if metricsRegisterer != nil {
+		instruments, err := newInstruments(metricsRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		throttler.instruments = instruments
+	}
+	throttler.Reconfigure(config)
+	return throttler, nil
+}
+
+func (t *throttler) Reconfigure(config *ServiceOperationsThrottleConfig) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.conditionLimiters = nil
+	if t.instruments != nil {
+		t.instruments.rateLimit.Reset()
+	}
 	for serviceID, operationsThrottleConfigs := range config.value {
 		for _, operationsThrottleConfig := range operationsThrottleConfigs {
-			throttler = throttler.WithOperationPatternThrottle(
-				serviceID,
-				operationsThrottleConfig.operationPtn,
-				operationsThrottleConfig.r,
-				operationsThrottleConfig.burst)
+			t.addConditionLimiter(matchServiceOperationPattern(serviceID, operationsThrottleConfig.operationPtn),
+				serviceID, operationsThrottleConfig.operationPtn.String(),
+				operationsThrottleConfig.r, operationsThrottleConfig.burst)
 		}
 	}
-	return throttler
 }
 
 func (t *throttler) WithConditionThrottle(condition Condition, r rate.Limit, burst int) *throttler {
-	limiter := rate.NewLimiter(r, burst)
-	t.conditionLimiters = append(t.conditionLimiters, conditionLimiter{
-		condition: condition,
-		limiter:   limiter,
-	})
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.addConditionLimiter(condition, "", "", r, burst)
 	return t
 }
 
 func (t *throttler) WithServiceThrottle(serviceID string, r rate.Limit, burst int) *throttler {
-	return t.WithConditionThrottle(matchService(serviceID), r, burst)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.addConditionLimiter(matchService(serviceID), serviceID, "", r, burst)
+	return t
 }
 
 func (t *throttler) WithOperationThrottle(serviceID string, operation string, r rate.Limit, burst int) *throttler {
-	return t.WithConditionThrottle(matchServiceOperation(serviceID, operation), r, burst)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.addConditionLimiter(matchServiceOperation(serviceID, operation), serviceID, operation, r, burst)
+	return t
 }
 
 func (t *throttler) WithOperationPatternThrottle(serviceID string, operationPtn *regexp.Regexp, r rate.Limit, burst int) *throttler {
-	return t.WithConditionThrottle(matchServiceOperationPattern(serviceID, operationPtn), r, burst)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.addConditionLimiter(matchServiceOperationPattern(serviceID, operationPtn), serviceID, operationPtn.String(), r, burst)
+	return t
+}
+
+// addConditionLimiter adds limiter for requests matching condition, serviceID and operation are used as labels of metrics.
+// must be called with mutex held.
+func (t *throttler) addConditionLimiter(condition Condition, serviceID string, operation string, r rate.Limit, burst int) {
+	var onRateChange func(r rate.Limit)
+	if t.instruments != nil {
+		rateLimit := t.instruments.rateLimit.With(prometheus.Labels{
+			labelService:   serviceID,
+			labelOperation: operation,
+		})
+		onRateChange = func(r rate.Limit) {
+			rateLimit.Set(float64(r))
+		}
+	}
+	t.conditionLimiters = append(t.conditionLimiters, conditionLimiter{
+		condition: condition,
+		limiter:   newAdaptiveLimiter(r, burst, onRateChange),
+	})
 }
 
 func (t *throttler) InjectHandlers(handlers *request.Handlers) {
@@ -58,13 +124,44 @@ func (t *throttler) InjectHandlers(handlers *request.Handlers) {
 		Name: sdkHandlerRequestThrottle,
 		Fn:   t.beforeSign,
 	})
+	if t.adaptive {
+		handlers.CompleteAttempt.PushBackNamed(request.NamedHandler{
+			Name: sdkHandlerThrottleFeedback,
+			Fn:   t.afterAttempt,
+		})
+	}
 }
 
 // beforeSign is added to the Sign chain; called before each request
 func (t *throttler) beforeSign(r *request.Request) {
+	for _, conditionLimiter := range t.matchingConditionLimiters(r) {
+		conditionLimiter.limiter.Wait(r.Context())
+	}
+}
+
+// afterAttempt is added to the CompleteAttempt chain; called after each attempt of request
+func (t *throttler) afterAttempt(r *request.Request) {
+	throttled := r.Error != nil && request.IsErrorThrottle(r.Error)
+	if r.Error != nil && !throttled {
+		return
+	}
+	for _, conditionLimiter := range t.matchingConditionLimiters(r) {
+		if throttled {
+			conditionLimiter.limiter.OnThrottled()
+		} else {
+			conditionLimiter.limiter.OnSucceeded()
+		}
+	}
+}
+
+func (t *throttler) matchingConditionLimiters(r *request.Request) []conditionLimiter {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	var matched []conditionLimiter
 	for _, conditionLimiter := range t.conditionLimiters {
 		if conditionLimiter.condition(r) {
-			conditionLimiter.limiter.Wait(r.Context())
+			matched = append(matched, conditionLimiter)
 		}
 	}
+	return matched
 }
@@ -2,10 +2,14 @@ package throttle
 
 import (
 	"context"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
 	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/time/rate"
 	"net/http"
+	"regexp"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -17,6 +21,15 @@ func Test_throttler_InjectHandlers(t *testing.T) {
 	handlers := request.Handlers{}
 	throttler.InjectHandlers(&handlers)
 	assert.Equal(t, 1, handlers.Sign.Len())
+	assert.Equal(t, 0, handlers.CompleteAttempt.Len())
+}
+
+func Test_throttler_InjectHandlers_adaptive(t *testing.T) {
+	throttler := &throttler{adaptive: true}
+	handlers := request.Handlers{}
+	throttler.InjectHandlers(&handlers)
+	assert.Equal(t, 1, handlers.Sign.Len())
+	assert.Equal(t, 1, handlers.CompleteAttempt.Len())
 }
 
 // Test beforeSign to check whether throttle applies correctly.
@@ -46,7 +59,7 @@ func Test_throttler_beforeSign(t *testing.T) {
 						condition: func(r *request.Request) bool {
 							return true
 						},
-						limiter: rate.NewLimiter(10, 5),
+						limiter: newAdaptiveLimiter(10, 5, nil),
 					},
 				},
 			},
@@ -76,7 +89,7 @@ func Test_throttler_beforeSign(t *testing.T) {
 						condition: func(r *request.Request) bool {
 							return false
 						},
-						limiter: rate.NewLimiter(10, 5),
+						limiter: newAdaptiveLimiter(10, 5, nil),
 					},
 				},
 			},
@@ -106,13 +119,13 @@ func Test_throttler_beforeSign(t *testing.T) {
 						condition: func(r *request.Request) bool {
 							return true
 						},
-						limiter: rate.NewLimiter(10, 5),
+						limiter: newAdaptiveLimiter(10, 5, nil),
 					},
 					{
 						condition: func(r *request.Request) bool {
 							return false
 						},
-						limiter: rate.NewLimiter(1, 5),
+						limiter: newAdaptiveLimiter(1, 5, nil),
 					},
 				},
 			},
@@ -142,13 +155,13 @@ func Test_throttler_beforeSign(t *testing.T) {
 						condition: func(r *request.Request) bool {
 							return true
 						},
-						limiter: rate.NewLimiter(10, 5),
+						limiter: newAdaptiveLimiter(10, 5, nil),
 					},
 					{
 						condition: func(r *request.Request) bool {
 							return true
 						},
-						limiter: rate.NewLimiter(1, 5),
+						limiter: newAdaptiveLimiter(1, 5, nil),
 					},
 				},
 			},
@@ -201,3 +214,85 @@ func Test_throttler_beforeSign(t *testing.T) {
 		})
 	}
 }
+
+func Test_throttler_afterAttempt(t *testing.T) {
+	newRequest := func(operation string, err error) *request.Request {
+		return &request.Request{
+			ClientInfo: metadata.ClientInfo{ServiceID: "App Mesh"},
+			Operation:  &request.Operation{Name: operation},
+			Error:      err,
+		}
+	}
+	tests := []struct {
+		name           string
+		req            *request.Request
+		wantCreateRate rate.Limit
+		wantListRate   rate.Limit
+	}{
+		{
+			name:           "throttling error decreases the rate of matching operations",
+			req:            newRequest("CreateMesh", awserr.New("ThrottlingException", "Rate exceeded", nil)),
+			wantCreateRate: 5,
+			wantListRate:   40,
+		},
+		{
+			name:           "other error keeps the rate",
+			req:            newRequest("CreateMesh", awserr.New("ConflictException", "conflict", nil)),
+			wantCreateRate: 10,
+			wantListRate:   40,
+		},
+		{
+			name:           "success keeps the max rate",
+			req:            newRequest("ListMeshes", nil),
+			wantCreateRate: 10,
+			wantListRate:   40,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttler := (&throttler{adaptive: true}).
+				WithOperationPatternThrottle("App Mesh", regexp.MustCompile("^Create"), 10, 5).
+				WithOperationPatternThrottle("App Mesh", regexp.MustCompile("^List"), 40, 5)
+			throttler.afterAttempt(tt.req)
+			assert.Equal(t, tt.wantCreateRate, throttler.conditionLimiters[0].limiter.Limit())
+			assert.Equal(t, tt.wantListRate, throttler.conditionLimiters[1].limiter.Limit())
+		})
+	}
+}
+
+func Test_throttler_Reconfigure(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	config := &ServiceOperationsThrottleConfig{}
+	assert.NoError(t, config.Set("App Mesh:^Create=10:5,App Mesh:^List=40:5"))
+	throttler, err := NewThrottler(config, true, registry)
+	assert.NoError(t, err)
+	assert.Len(t, throttler.conditionLimiters, 2)
+	assert.Equal(t, map[string]float64{"App Mesh:^Create": 10, "App Mesh:^List": 40}, gatherRateLimits(t, registry))
+
+	newConfig := &ServiceOperationsThrottleConfig{}
+	assert.NoError(t, newConfig.Set("App Mesh:^Describe=20:5"))
+	throttler.Reconfigure(newConfig)
+	assert.Len(t, throttler.conditionLimiters, 1)
+	assert.Equal(t, rate.Limit(20), throttler.conditionLimiters[0].limiter.Limit())
+	assert.Equal(t, map[string]float64{"App Mesh:^Describe": 20}, gatherRateLimits(t, registry))
+}
+
+// gatherRateLimits returns the value of rate limit gauges by service:operation.
+func gatherRateLimits(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+	rateLimits := make(map[string]float64)
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != "aws_api_throttle_rate_limit" {
+			continue
+		}
+		for _, metric := range metricFamily.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			rateLimits[labels[labelService]+":"+labels[labelOperation]] = metric.GetGauge().GetValue()
+		}
+	}
+	return rateLimits
+}
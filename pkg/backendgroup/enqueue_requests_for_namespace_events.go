@@ -0,0 +1,70 @@
+package backendgroup
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func NewEnqueueRequestsForNamespaceEvents(k8sClient client.Client, log logr.Logger) *enqueueRequestsForNamespaceEvents {
+	return &enqueueRequestsForNamespaceEvents{
+		k8sClient: k8sClient,
+		log:       log,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForNamespaceEvents)(nil)
+
+type enqueueRequestsForNamespaceEvents struct {
+	k8sClient client.Client
+	log       logr.Logger
+}
+
+// Create is called in response to a create event
+func (h *enqueueRequestsForNamespaceEvents) Create(ctx context.Context, e event.CreateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	// no-op, a new namespace doesn't contain virtualServices yet.
+}
+
+// Update is called in response to an update event
+func (h *enqueueRequestsForNamespaceEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	nsOld := e.ObjectOld.(*corev1.Namespace)
+	nsNew := e.ObjectNew.(*corev1.Namespace)
+	if !equality.Semantic.DeepEqual(nsOld.Labels, nsNew.Labels) {
+		h.enqueueBackendGroupsWithNamespaceSelector(ctx, queue)
+	}
+}
+
+// Delete is called in response to a delete event
+func (h *enqueueRequestsForNamespaceEvents) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	// no-op, virtualServices in deleted namespace will trigger their own delete events.
+}
+
+// Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
+// external trigger request
+func (h *enqueueRequestsForNamespaceEvents) Generic(ctx context.Context, e event.GenericEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	// no-op
+}
+
+// enqueueBackendGroupsWithNamespaceSelector enqueues all backendGroups that select virtualServices across namespaces.
+func (h *enqueueRequestsForNamespaceEvents) enqueueBackendGroupsWithNamespaceSelector(ctx context.Context, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	bgList := &appmesh.BackendGroupList{}
+	if err := h.k8sClient.List(ctx, bgList); err != nil {
+		h.log.Error(err, "failed to enqueue backendGroups for namespace events")
+		return
+	}
+	for i := range bgList.Items {
+		bg := &bgList.Items[i]
+		if bg.Spec.VirtualServiceSelector != nil && bg.Spec.NamespaceSelector != nil {
+			queue.Add(ctrl.Request{NamespacedName: k8s.NamespacedName(bg)})
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package backendgroup
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func NewEnqueueRequestsForVirtualServiceEvents(k8sClient client.Client, log logr.Logger) *enqueueRequestsForVirtualServiceEvents {
+	return &enqueueRequestsForVirtualServiceEvents{
+		k8sClient: k8sClient,
+		log:       log,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForVirtualServiceEvents)(nil)
+
+type enqueueRequestsForVirtualServiceEvents struct {
+	k8sClient client.Client
+	log       logr.Logger
+}
+
+// Create is called in response to a create event
+func (h *enqueueRequestsForVirtualServiceEvents) Create(ctx context.Context, e event.CreateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	vs := e.Object.(*appmesh.VirtualService)
+	h.enqueueBackendGroupsForVirtualService(ctx, queue, vs, vs.Labels)
+}
+
+// Update is called in response to an update event
+func (h *enqueueRequestsForVirtualServiceEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	vsOld := e.ObjectOld.(*appmesh.VirtualService)
+	vsNew := e.ObjectNew.(*appmesh.VirtualService)
+	if equality.Semantic.DeepEqual(vsOld.Labels, vsNew.Labels) {
+		return
+	}
+	h.enqueueBackendGroupsForVirtualService(ctx, queue, vsNew, vsOld.Labels)
+	h.enqueueBackendGroupsForVirtualService(ctx, queue, vsNew, vsNew.Labels)
+}
+
+// Delete is called in response to a delete event
+func (h *enqueueRequestsForVirtualServiceEvents) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	vs := e.Object.(*appmesh.VirtualService)
+	h.enqueueBackendGroupsForVirtualService(ctx, queue, vs, vs.Labels)
+}
+
+// Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
+// external trigger request
+func (h *enqueueRequestsForVirtualServiceEvents) Generic(ctx context.Context, e event.GenericEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	// no-op
+}
+
+// enqueueBackendGroupsForVirtualService enqueues backendGroups whose selectors may select vs with vsLabels.
+func (h *enqueueRequestsForVirtualServiceEvents) enqueueBackendGroupsForVirtualService(ctx context.Context, queue workqueue.TypedRateLimitingInterface[ctrl.Request], vs *appmesh.VirtualService, vsLabels map[string]string) {
+	bgList := &appmesh.BackendGroupList{}
+	if err := h.k8sClient.List(ctx, bgList); err != nil {
+		h.log.Error(err, "failed to enqueue backendGroups for virtualService events",
+			"virtualService", k8s.NamespacedName(vs))
+		return
+	}
+	for i := range bgList.Items {
+		bg := &bgList.Items[i]
+		if !IsVirtualServiceInSameMesh(bg, vs) {
+			continue
+		}
+		if MatchesVirtualServiceSelector(bg, vs.Namespace, vsLabels) {
+			queue.Add(ctrl.Request{NamespacedName: k8s.NamespacedName(bg)})
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package backendgroup
+
+import (
+	"context"
+	"sort"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MembersResolver resolves the virtualServices in a backendGroup.
+type MembersResolver interface {
+	// Resolve returns the virtualServices in backendGroup, including both listed and selected virtualServices.
+	// the returned references are sorted by namespace and name, and always have namespace populated.
+	Resolve(ctx context.Context, bg *appmesh.BackendGroup) ([]appmesh.VirtualServiceReference, error)
+}
+
+// NewDefaultMembersResolver constructs new defaultMembersResolver
+func NewDefaultMembersResolver(k8sClient client.Client, referenceGrantChecker references.ReferenceGrantChecker, log logr.Logger) *defaultMembersResolver {
+	return &defaultMembersResolver{
+		k8sClient:             k8sClient,
+		referenceGrantChecker: referenceGrantChecker,
+		log:                   log,
+	}
+}
+
+var _ MembersResolver = &defaultMembersResolver{}
+
+// defaultMembersResolver implements MembersResolver
+type defaultMembersResolver struct {
+	k8sClient             client.Client
+	referenceGrantChecker references.ReferenceGrantChecker
+	log                   logr.Logger
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+func (r *defaultMembersResolver) Resolve(ctx context.Context, bg *appmesh.BackendGroup) ([]appmesh.VirtualServiceReference, error) {
+	vsKeys := make(map[types.NamespacedName]struct{})
+	for _, vsRef := range bg.Spec.VirtualServices {
+		vsKey := references.ObjectKeyForVirtualServiceReference(bg, vsRef)
+		// listed virtualServices are rejected rather than skipped, same as the webhook does.
+		if err := r.referenceGrantChecker.CheckReference(ctx, bg, appmesh.MeshReferenceGrantToKindVirtualService, vsKey); err != nil {
+			return nil, err
+		}
+		vsKeys[vsKey] = struct{}{}
+	}
+	selectedVSKeys, err := r.resolveSelectedVirtualServices(ctx, bg)
+	if err != nil {
+		return nil, err
+	}
+	for _, vsKey := range selectedVSKeys {
+		vsKeys[vsKey] = struct{}{}
+	}
+
+	vsRefs := make([]appmesh.VirtualServiceReference, 0, len(vsKeys))
+	for vsKey := range vsKeys {
+		vsRefs = append(vsRefs, appmesh.VirtualServiceReference{
+			Namespace: aws.String(vsKey.Namespace),
+			Name:      vsKey.Name,
+		})
+	}
+	sort.Slice(vsRefs, func(i, j int) bool {
+		if aws.StringValue(vsRefs[i].Namespace) != aws.StringValue(vsRefs[j].Namespace) {
+			return aws.StringValue(vsRefs[i].Namespace) < aws.StringValue(vsRefs[j].Namespace)
+		}
+		return vsRefs[i].Name < vsRefs[j].Name
+	})
+	return vsRefs, nil
+}
+
+// resolveSelectedVirtualServices returns the keys of virtualServices selected by backendGroup's selectors.
+func (r *defaultMembersResolver) resolveSelectedVirtualServices(ctx context.Context, bg *appmesh.BackendGroup) ([]types.NamespacedName, error) {
+	if bg.Spec.VirtualServiceSelector == nil {
+		return nil, nil
+	}
+	vsSelector, err := metav1.LabelSelectorAsSelector(bg.Spec.VirtualServiceSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid virtualServiceSelector")
+	}
+	namespaces, err := r.resolveSelectedNamespaces(ctx, bg)
+	if err != nil {
+		return nil, err
+	}
+
+	var vsKeys []types.NamespacedName
+	for _, namespace := range namespaces {
+		vsList := &appmesh.VirtualServiceList{}
+		if err := r.k8sClient.List(ctx, vsList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: vsSelector}); err != nil {
+			return nil, errors.Wrapf(err, "failed to list virtualServices in namespace: %s", namespace)
+		}
+		for _, vs := range vsList.Items {
+			if !IsVirtualServiceInSameMesh(bg, &vs) {
+				continue
+			}
+			vsKey := types.NamespacedName{Namespace: vs.Namespace, Name: vs.Name}
+			if err := r.referenceGrantChecker.CheckReference(ctx, bg, appmesh.MeshReferenceGrantToKindVirtualService, vsKey); err != nil {
+				tracing.LoggerFromContext(ctx, r.log).V(1).Info("skipping selected virtualService",
+					"backendGroup", types.NamespacedName{Namespace: bg.Namespace, Name: bg.Name},
+					"virtualService", vsKey,
+					"reason", err.Error())
+				continue
+			}
+			vsKeys = append(vsKeys, vsKey)
+		}
+	}
+	return vsKeys, nil
+}
+
+// resolveSelectedNamespaces returns the namespaces that backendGroup's virtualServiceSelector applies to.
+func (r *defaultMembersResolver) resolveSelectedNamespaces(ctx context.Context, bg *appmesh.BackendGroup) ([]string, error) {
+	if bg.Spec.NamespaceSelector == nil {
+		return []string{bg.Namespace}, nil
+	}
+	nsSelector, err := metav1.LabelSelectorAsSelector(bg.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid namespaceSelector")
+	}
+	nsList := &corev1.NamespaceList{}
+	if err := r.k8sClient.List(ctx, nsList, client.MatchingLabelsSelector{Selector: nsSelector}); err != nil {
+		return nil, errors.Wrap(err, "failed to list namespaces")
+	}
+	namespaces := make([]string, 0, len(nsList.Items))
+	for _, ns := range nsList.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,309 @@
+package backendgroup
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_defaultMembersResolver_Resolve(t *testing.T) {
+	ns1 := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "ns-1",
+			Labels: map[string]string{"team": "a"},
+		},
+	}
+	ns2 := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "ns-2",
+			Labels: map[string]string{"team": "a"},
+		},
+	}
+	ns3 := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "ns-3",
+			Labels: map[string]string{"team": "b"},
+		},
+	}
+	vsInNS1 := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "vs-1",
+			Labels:    map[string]string{"app": "catalog"},
+		},
+		Spec: appmesh.VirtualServiceSpec{
+			MeshRef: &appmesh.MeshReference{Name: "mesh", UID: "uid-1"},
+		},
+	}
+	vsInNS1WithoutLabel := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "vs-2",
+		},
+		Spec: appmesh.VirtualServiceSpec{
+			MeshRef: &appmesh.MeshReference{Name: "mesh", UID: "uid-1"},
+		},
+	}
+	vsInNS1OtherMesh := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "vs-3",
+			Labels:    map[string]string{"app": "catalog"},
+		},
+		Spec: appmesh.VirtualServiceSpec{
+			MeshRef: &appmesh.MeshReference{Name: "other-mesh", UID: "uid-2"},
+		},
+	}
+	vsInNS2 := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-2",
+			Name:      "vs-1",
+			Labels:    map[string]string{"app": "catalog"},
+		},
+		Spec: appmesh.VirtualServiceSpec{
+			MeshRef: &appmesh.MeshReference{Name: "mesh", UID: "uid-1"},
+		},
+	}
+	vsInNS3 := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-3",
+			Name:      "vs-1",
+			Labels:    map[string]string{"app": "catalog"},
+		},
+		Spec: appmesh.VirtualServiceSpec{
+			MeshRef: &appmesh.MeshReference{Name: "mesh", UID: "uid-1"},
+		},
+	}
+
+	type env struct {
+		namespaces      []*corev1.Namespace
+		virtualServices []*appmesh.VirtualService
+	}
+	type args struct {
+		bg *appmesh.BackendGroup
+	}
+	tests := []struct {
+		name    string
+		env     env
+		args    args
+		want    []appmesh.VirtualServiceReference
+		wantErr error
+	}{
+		{
+			name: "backendGroup with listed virtualServices only",
+			env: env{
+				namespaces:      []*corev1.Namespace{ns1, ns2, ns3},
+				virtualServices: []*appmesh.VirtualService{vsInNS1, vsInNS2},
+			},
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "bg",
+					},
+					Spec: appmesh.BackendGroupSpec{
+						VirtualServices: []appmesh.VirtualServiceReference{
+							{Namespace: aws.String("ns-2"), Name: "vs-1"},
+							{Name: "vs-1"},
+						},
+						MeshRef: &appmesh.MeshReference{Name: "mesh", UID: "uid-1"},
+					},
+				},
+			},
+			want: []appmesh.VirtualServiceReference{
+				{Namespace: aws.String("ns-1"), Name: "vs-1"},
+				{Namespace: aws.String("ns-2"), Name: "vs-1"},
+			},
+		},
+		{
+			name: "backendGroup with virtualServiceSelector only",
+			env: env{
+				namespaces:      []*corev1.Namespace{ns1, ns2, ns3},
+				virtualServices: []*appmesh.VirtualService{vsInNS1, vsInNS1WithoutLabel, vsInNS1OtherMesh, vsInNS2, vsInNS3},
+			},
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "bg",
+					},
+					Spec: appmesh.BackendGroupSpec{
+						VirtualServiceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "catalog"},
+						},
+						MeshRef: &appmesh.MeshReference{Name: "mesh", UID: "uid-1"},
+					},
+				},
+			},
+			want: []appmesh.VirtualServiceReference{
+				{Namespace: aws.String("ns-1"), Name: "vs-1"},
+			},
+		},
+		{
+			name: "backendGroup with virtualServiceSelector and namespaceSelector",
+			env: env{
+				namespaces:      []*corev1.Namespace{ns1, ns2, ns3},
+				virtualServices: []*appmesh.VirtualService{vsInNS1, vsInNS1WithoutLabel, vsInNS1OtherMesh, vsInNS2, vsInNS3},
+			},
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "bg",
+					},
+					Spec: appmesh.BackendGroupSpec{
+						VirtualServices: []appmesh.VirtualServiceReference{
+							{Name: "vs-2"},
+						},
+						VirtualServiceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "catalog"},
+						},
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"team": "a"},
+						},
+						MeshRef: &appmesh.MeshReference{Name: "mesh", UID: "uid-1"},
+					},
+				},
+			},
+			want: []appmesh.VirtualServiceReference{
+				{Namespace: aws.String("ns-1"), Name: "vs-1"},
+				{Namespace: aws.String("ns-1"), Name: "vs-2"},
+				{Namespace: aws.String("ns-2"), Name: "vs-1"},
+			},
+		},
+		{
+			name: "backendGroup with invalid virtualServiceSelector",
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "bg",
+					},
+					Spec: appmesh.BackendGroupSpec{
+						VirtualServiceSelector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: "app", Operator: "bad-operator"},
+							},
+						},
+					},
+				},
+			},
+			wantErr: errors.New("invalid virtualServiceSelector: \"bad-operator\" is not a valid label selector operator"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+			r := NewDefaultMembersResolver(k8sClient, references.NewDefaultReferenceGrantChecker(k8sClient, references.Config{}), logr.New(&log.NullLogSink{}))
+
+			for _, ns := range tt.env.namespaces {
+				err := k8sClient.Create(ctx, ns.DeepCopy())
+				assert.NoError(t, err)
+			}
+			for _, vs := range tt.env.virtualServices {
+				err := k8sClient.Create(ctx, vs.DeepCopy())
+				assert.NoError(t, err)
+			}
+
+			got, err := r.Resolve(ctx, tt.args.bg)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_defaultMembersResolver_Resolve_withReferenceGrants(t *testing.T) {
+	ctx := context.Background()
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+	checker := references.NewDefaultReferenceGrantChecker(k8sClient, references.Config{EnableReferenceGrants: true})
+	r := NewDefaultMembersResolver(k8sClient, checker, logr.New(&log.NullLogSink{}))
+
+	for _, nsName := range []string{"ns-1", "ns-2", "ns-3"} {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   nsName,
+				Labels: map[string]string{"team": "a"},
+			},
+		}
+		assert.NoError(t, k8sClient.Create(ctx, ns))
+		vs := &appmesh.VirtualService{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: nsName,
+				Name:      "vs",
+				Labels:    map[string]string{"app": "catalog"},
+			},
+		}
+		assert.NoError(t, k8sClient.Create(ctx, vs))
+	}
+	grant := &appmesh.MeshReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-2",
+			Name:      "grant",
+		},
+		Spec: appmesh.MeshReferenceGrantSpec{
+			From: []appmesh.MeshReferenceGrantFrom{
+				{Kind: appmesh.MeshReferenceGrantFromKindBackendGroup, Namespace: "ns-1"},
+			},
+			To: []appmesh.MeshReferenceGrantTo{
+				{Kind: appmesh.MeshReferenceGrantToKindVirtualService},
+			},
+		},
+	}
+	assert.NoError(t, k8sClient.Create(ctx, grant))
+
+	bg := &appmesh.BackendGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "bg",
+		},
+		Spec: appmesh.BackendGroupSpec{
+			VirtualServiceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "catalog"},
+			},
+			NamespaceSelector: &metav1.LabelSelector{},
+		},
+	}
+	got, err := r.Resolve(ctx, bg)
+	assert.NoError(t, err)
+	assert.Equal(t, []appmesh.VirtualServiceReference{
+		{Namespace: aws.String("ns-1"), Name: "vs"},
+		{Namespace: aws.String("ns-2"), Name: "vs"},
+	}, got)
+
+	bgWithListedVirtualService := &appmesh.BackendGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "bg",
+		},
+		Spec: appmesh.BackendGroupSpec{
+			VirtualServices: []appmesh.VirtualServiceReference{
+				{Namespace: aws.String("ns-3"), Name: "vs"},
+			},
+		},
+	}
+	_, err = r.Resolve(ctx, bgWithListedVirtualService)
+	assert.EqualError(t, err, "BackendGroup ns-1/bg is not permitted to reference VirtualService ns-3/vs, no meshReferenceGrant in namespace ns-3 allows it")
+}
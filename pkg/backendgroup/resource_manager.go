@@ -0,0 +1,62 @@
+package backendgroup
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResourceManager is dedicated to manage BackendGroup status.
+type ResourceManager interface {
+	// Reconcile will resolve the virtualServices in bg and publish them in bg's status.
+	Reconcile(ctx context.Context, bg *appmesh.BackendGroup) error
+}
+
+// NewDefaultResourceManager constructs new defaultResourceManager
+func NewDefaultResourceManager(k8sClient client.Client, membersResolver MembersResolver, log logr.Logger) ResourceManager {
+	return &defaultResourceManager{
+		k8sClient:       k8sClient,
+		membersResolver: membersResolver,
+		log:             log,
+	}
+}
+
+// defaultResourceManager implements ResourceManager
+type defaultResourceManager struct {
+	k8sClient       client.Client
+	membersResolver MembersResolver
+	log             logr.Logger
+}
+
+func (m *defaultResourceManager) Reconcile(ctx context.Context, bg *appmesh.BackendGroup) error {
+	vsRefs, err := m.membersResolver.Resolve(ctx, bg)
+	if err != nil {
+		return err
+	}
+	return m.updateCRDBackendGroup(ctx, bg, vsRefs)
+}
+
+func (m *defaultResourceManager) updateCRDBackendGroup(ctx context.Context, bg *appmesh.BackendGroup, vsRefs []appmesh.VirtualServiceReference) error {
+	oldBG := bg.DeepCopy()
+	needsUpdate := false
+	if !equality.Semantic.DeepEqual(bg.Status.VirtualServices, vsRefs) {
+		bg.Status.VirtualServices = vsRefs
+		needsUpdate = true
+	}
+	if bg.Status.VirtualServiceCount == nil || aws.Int64Value(bg.Status.VirtualServiceCount) != int64(len(vsRefs)) {
+		bg.Status.VirtualServiceCount = aws.Int64(int64(len(vsRefs)))
+		needsUpdate = true
+	}
+	if aws.Int64Value(bg.Status.ObservedGeneration) != bg.Generation {
+		bg.Status.ObservedGeneration = aws.Int64(bg.Generation)
+		needsUpdate = true
+	}
+	if !needsUpdate {
+		return nil
+	}
+	return m.k8sClient.Status().Patch(ctx, bg, client.MergeFrom(oldBG))
+}
@@ -0,0 +1,113 @@
+package backendgroup
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/equality"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_defaultResourceManager_updateCRDBackendGroup(t *testing.T) {
+	type args struct {
+		bg     *appmesh.BackendGroup
+		vsRefs []appmesh.VirtualServiceReference
+	}
+	tests := []struct {
+		name   string
+		args   args
+		wantBG *appmesh.BackendGroup
+	}{
+		{
+			name: "backendGroup needs patch virtualServices and count",
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "bg",
+					},
+				},
+				vsRefs: []appmesh.VirtualServiceReference{
+					{Namespace: aws.String("ns-1"), Name: "vs-1"},
+					{Namespace: aws.String("ns-2"), Name: "vs-1"},
+				},
+			},
+			wantBG: &appmesh.BackendGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns-1",
+					Name:      "bg",
+				},
+				Status: appmesh.BackendGroupStatus{
+					VirtualServices: []appmesh.VirtualServiceReference{
+						{Namespace: aws.String("ns-1"), Name: "vs-1"},
+						{Namespace: aws.String("ns-2"), Name: "vs-1"},
+					},
+					VirtualServiceCount: aws.Int64(2),
+				},
+			},
+		},
+		{
+			name: "backendGroup needs patch when selection becomes empty",
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "bg",
+					},
+					Status: appmesh.BackendGroupStatus{
+						VirtualServices: []appmesh.VirtualServiceReference{
+							{Namespace: aws.String("ns-1"), Name: "vs-1"},
+						},
+						VirtualServiceCount: aws.Int64(1),
+						ObservedGeneration:  aws.Int64(0),
+					},
+				},
+				vsRefs: []appmesh.VirtualServiceReference{},
+			},
+			wantBG: &appmesh.BackendGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns-1",
+					Name:      "bg",
+				},
+				Status: appmesh.BackendGroupStatus{
+					VirtualServiceCount: aws.Int64(0),
+					ObservedGeneration:  aws.Int64(0),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithStatusSubresource(&appmesh.BackendGroup{}).Build()
+			m := &defaultResourceManager{
+				k8sClient: k8sClient,
+				log:       logr.New(&log.NullLogSink{}),
+			}
+
+			err := k8sClient.Create(ctx, tt.args.bg.DeepCopy())
+			assert.NoError(t, err)
+			err = m.updateCRDBackendGroup(ctx, tt.args.bg, tt.args.vsRefs)
+			assert.NoError(t, err)
+
+			gotBG := &appmesh.BackendGroup{}
+			err = k8sClient.Get(ctx, k8s.NamespacedName(tt.args.bg), gotBG)
+			assert.NoError(t, err)
+			opts := equality.IgnoreFakeClientPopulatedFields()
+			assert.True(t, cmp.Equal(tt.wantBG, gotBG, opts), "diff", cmp.Diff(tt.wantBG, gotBG, opts))
+		})
+	}
+}
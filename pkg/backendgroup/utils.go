@@ -0,0 +1,51 @@
+package backendgroup
+
+import (
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// WildcardName is the name of backendGroupRef that stands for every virtualService in its namespace and mesh,
+// rather than a BackendGroup object.
+const WildcardName = "*"
+
+// NewWildcardBackendGroup returns the backendGroup that backendGroupRef named WildcardName in namespace stands for,
+// which selects every virtualService in namespace that belongs to the mesh of meshRef.
+func NewWildcardBackendGroup(namespace string, meshRef *appmesh.MeshReference) *appmesh.BackendGroup {
+	return &appmesh.BackendGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      WildcardName,
+		},
+		Spec: appmesh.BackendGroupSpec{
+			VirtualServiceSelector: &metav1.LabelSelector{},
+			MeshRef:                meshRef,
+		},
+	}
+}
+
+// IsVirtualServiceInSameMesh checks whether virtualService belongs to the same mesh as backendGroup.
+// resources without meshRef populated are considered in same mesh.
+func IsVirtualServiceInSameMesh(bg *appmesh.BackendGroup, vs *appmesh.VirtualService) bool {
+	if bg.Spec.MeshRef == nil || vs.Spec.MeshRef == nil {
+		return true
+	}
+	return *bg.Spec.MeshRef == *vs.Spec.MeshRef
+}
+
+// MatchesVirtualServiceSelector checks whether virtualService with vsLabels in vsNamespace may be selected by backendGroup.
+// namespaceSelector isn't evaluated here, virtualServices in other namespaces are considered as possible matches when it's specified.
+func MatchesVirtualServiceSelector(bg *appmesh.BackendGroup, vsNamespace string, vsLabels map[string]string) bool {
+	if bg.Spec.VirtualServiceSelector == nil {
+		return false
+	}
+	if bg.Spec.NamespaceSelector == nil && vsNamespace != bg.Namespace {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(bg.Spec.VirtualServiceSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(vsLabels))
+}
@@ -0,0 +1,123 @@
+package backendgroup
+
+import (
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchesVirtualServiceSelector(t *testing.T) {
+	type args struct {
+		bg          *appmesh.BackendGroup
+		vsNamespace string
+		vsLabels    map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "backendGroup without virtualServiceSelector",
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "bg"},
+				},
+				vsNamespace: "ns-1",
+				vsLabels:    map[string]string{"app": "catalog"},
+			},
+			want: false,
+		},
+		{
+			name: "labels match in same namespace",
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "bg"},
+					Spec: appmesh.BackendGroupSpec{
+						VirtualServiceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "catalog"},
+						},
+					},
+				},
+				vsNamespace: "ns-1",
+				vsLabels:    map[string]string{"app": "catalog"},
+			},
+			want: true,
+		},
+		{
+			name: "labels match in other namespace without namespaceSelector",
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "bg"},
+					Spec: appmesh.BackendGroupSpec{
+						VirtualServiceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "catalog"},
+						},
+					},
+				},
+				vsNamespace: "ns-2",
+				vsLabels:    map[string]string{"app": "catalog"},
+			},
+			want: false,
+		},
+		{
+			name: "labels match in other namespace with namespaceSelector",
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "bg"},
+					Spec: appmesh.BackendGroupSpec{
+						VirtualServiceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "catalog"},
+						},
+						NamespaceSelector: &metav1.LabelSelector{},
+					},
+				},
+				vsNamespace: "ns-2",
+				vsLabels:    map[string]string{"app": "catalog"},
+			},
+			want: true,
+		},
+		{
+			name: "labels mismatch",
+			args: args{
+				bg: &appmesh.BackendGroup{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "bg"},
+					Spec: appmesh.BackendGroupSpec{
+						VirtualServiceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "catalog"},
+						},
+					},
+				},
+				vsNamespace: "ns-1",
+				vsLabels:    map[string]string{"app": "checkout"},
+			},
+			want: false,
+		},
+		{
+			name: "wildcard backendGroup matches any labels in its namespace",
+			args: args{
+				bg:          NewWildcardBackendGroup("ns-1", nil),
+				vsNamespace: "ns-1",
+				vsLabels:    map[string]string{"app": "checkout"},
+			},
+			want: true,
+		},
+		{
+			name: "wildcard backendGroup doesn't match other namespaces",
+			args: args{
+				bg:          NewWildcardBackendGroup("ns-1", nil),
+				vsNamespace: "ns-2",
+				vsLabels:    map[string]string{"app": "checkout"},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchesVirtualServiceSelector(tt.args.bg, tt.args.vsNamespace, tt.args.vsLabels)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
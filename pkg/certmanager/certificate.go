@@ -0,0 +1,109 @@
+package certmanager
+
+import (
+	"strconv"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// AnnotationCertificateRevision records the revision of the cert-manager Certificate that was mounted into a pod.
+	// It's set on pods by the sidecar injector, and on workload pod templates to roll pods when the certificate rotates.
+	AnnotationCertificateRevision = "appmesh.k8s.aws/certificateRevision"
+
+	// certificateNameSuffix is appended to VirtualNode/VirtualGateway name and kind to form the Certificate and Secret name.
+	certificateNameSuffix = "-appmesh-tls"
+)
+
+// CertificateGVK is the GroupVersionKind of cert-manager Certificate.
+var CertificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+// NewCertificate returns an empty cert-manager Certificate object.
+func NewCertificate() *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(CertificateGVK)
+	return cert
+}
+
+// CertificateName returns the name of the Certificate and its Secret for VirtualNode or VirtualGateway obj.
+// The kind is part of the name, so that a VirtualNode and a VirtualGateway with the same name don't share a Certificate.
+func CertificateName(obj metav1.Object) string {
+	switch obj.(type) {
+	case *appmesh.VirtualGateway:
+		return obj.GetName() + "-virtualgateway" + certificateNameSuffix
+	default:
+		return obj.GetName() + "-virtualnode" + certificateNameSuffix
+	}
+}
+
+// CertificateRevision returns the revision of the issued certificate.
+// returns empty string if certificate haven't been issued yet.
+func CertificateRevision(cert *unstructured.Unstructured) string {
+	revision, found, err := unstructured.NestedInt64(cert.Object, "status", "revision")
+	if err != nil || !found {
+		return ""
+	}
+	return strconv.FormatInt(revision, 10)
+}
+
+// VirtualNodeRequiresCertificate tests whether vn uses file based certificate for listener TLS or client TLS,
+// including client TLS applied from mesh defaults as reported in vn's status.
+func VirtualNodeRequiresCertificate(vn *appmesh.VirtualNode) bool {
+	for _, listener := range vn.Spec.Listeners {
+		if listener.TLS != nil && listener.TLS.Certificate.File != nil {
+			return true
+		}
+	}
+	if vn.Spec.BackendDefaults != nil && clientPolicyRequiresCertificate(vn.Spec.BackendDefaults.ClientPolicy) {
+		return true
+	}
+	for _, backend := range vn.Spec.Backends {
+		if clientPolicyRequiresCertificate(backend.VirtualService.ClientPolicy) {
+			return true
+		}
+	}
+	if meshDefaults := vn.Status.AppliedMeshDefaults; meshDefaults != nil && meshDefaults.BackendDefaults != nil &&
+		clientPolicyRequiresCertificate(meshDefaults.BackendDefaults.ClientPolicy) {
+		return true
+	}
+	return false
+}
+
+// VirtualGatewayRequiresCertificate tests whether vg uses file based certificate for listener TLS or client TLS,
+// including client TLS applied from mesh defaults as reported in vg's status.
+func VirtualGatewayRequiresCertificate(vg *appmesh.VirtualGateway) bool {
+	for _, listener := range vg.Spec.Listeners {
+		if listener.TLS != nil && listener.TLS.Certificate.File != nil {
+			return true
+		}
+	}
+	if vg.Spec.BackendDefaults != nil && virtualGatewayClientPolicyRequiresCertificate(vg.Spec.BackendDefaults.ClientPolicy) {
+		return true
+	}
+	if meshDefaults := vg.Status.AppliedMeshDefaults; meshDefaults != nil && meshDefaults.BackendDefaults != nil &&
+		virtualGatewayClientPolicyRequiresCertificate(meshDefaults.BackendDefaults.ClientPolicy) {
+		return true
+	}
+	return false
+}
+
+func clientPolicyRequiresCertificate(clientPolicy *appmesh.ClientPolicy) bool {
+	if clientPolicy == nil || clientPolicy.TLS == nil || clientPolicy.TLS.Certificate == nil {
+		return false
+	}
+	return clientPolicy.TLS.Certificate.File != nil
+}
+
+func virtualGatewayClientPolicyRequiresCertificate(clientPolicy *appmesh.VirtualGatewayClientPolicy) bool {
+	if clientPolicy == nil || clientPolicy.TLS == nil || clientPolicy.TLS.Certificate == nil {
+		return false
+	}
+	return clientPolicy.TLS.Certificate.File != nil
+}
@@ -0,0 +1,296 @@
+package certmanager
+
+import (
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCertificateRevision(t *testing.T) {
+	tests := []struct {
+		name string
+		cert *unstructured.Unstructured
+		want string
+	}{
+		{
+			name: "certificate haven't been issued",
+			cert: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{},
+				},
+			},
+			want: "",
+		},
+		{
+			name: "certificate have been issued",
+			cert: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"status": map[string]interface{}{
+						"revision": int64(3),
+					},
+				},
+			},
+			want: "3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CertificateRevision(tt.cert)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVirtualNodeRequiresCertificate(t *testing.T) {
+	tests := []struct {
+		name string
+		vn   *appmesh.VirtualNode
+		want bool
+	}{
+		{
+			name: "virtualNode without TLS",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: []appmesh.Listener{
+						{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolHTTP}},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "virtualNode with file based listener certificate",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: []appmesh.Listener{
+						{
+							PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolHTTP},
+							TLS: &appmesh.ListenerTLS{
+								Certificate: appmesh.ListenerTLSCertificate{
+									File: &appmesh.ListenerTLSFileCertificate{
+										CertificateChain: "/certs/tls.crt",
+										PrivateKey:       "/certs/tls.key",
+									},
+								},
+								Mode: appmesh.ListenerTLSModeStrict,
+							},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "virtualNode with SDS listener certificate",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: []appmesh.Listener{
+						{
+							PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolHTTP},
+							TLS: &appmesh.ListenerTLS{
+								Certificate: appmesh.ListenerTLSCertificate{
+									SDS: &appmesh.ListenerTLSSDSCertificate{
+										SecretName: aws.String("spiffe://mesh/vn"),
+									},
+								},
+								Mode: appmesh.ListenerTLSModeStrict,
+							},
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "virtualNode with file based client certificate in backendDefaults",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					BackendDefaults: &appmesh.BackendDefaults{
+						ClientPolicy: &appmesh.ClientPolicy{
+							TLS: &appmesh.ClientPolicyTLS{
+								Certificate: &appmesh.ClientTLSCertificate{
+									File: &appmesh.ListenerTLSFileCertificate{
+										CertificateChain: "/certs/tls.crt",
+										PrivateKey:       "/certs/tls.key",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "virtualNode with file based client certificate in backend",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Backends: []appmesh.Backend{
+						{
+							VirtualService: appmesh.VirtualServiceBackend{
+								VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs"},
+								ClientPolicy: &appmesh.ClientPolicy{
+									TLS: &appmesh.ClientPolicyTLS{
+										Certificate: &appmesh.ClientTLSCertificate{
+											File: &appmesh.ListenerTLSFileCertificate{
+												CertificateChain: "/certs/tls.crt",
+												PrivateKey:       "/certs/tls.key",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "virtualNode with file based client certificate from mesh defaults",
+			vn: &appmesh.VirtualNode{
+				Status: appmesh.VirtualNodeStatus{
+					AppliedMeshDefaults: &appmesh.VirtualNodeDefaults{
+						BackendDefaults: &appmesh.MeshVirtualNodeBackendDefaults{
+							ClientPolicy: &appmesh.ClientPolicy{
+								TLS: &appmesh.ClientPolicyTLS{
+									Certificate: &appmesh.ClientTLSCertificate{
+										File: &appmesh.ListenerTLSFileCertificate{
+											CertificateChain: "/certs/tls.crt",
+											PrivateKey:       "/certs/tls.key",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VirtualNodeRequiresCertificate(tt.vn)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVirtualGatewayRequiresCertificate(t *testing.T) {
+	tests := []struct {
+		name string
+		vg   *appmesh.VirtualGateway
+		want bool
+	}{
+		{
+			name: "virtualGateway without TLS",
+			vg: &appmesh.VirtualGateway{
+				Spec: appmesh.VirtualGatewaySpec{
+					Listeners: []appmesh.VirtualGatewayListener{
+						{PortMapping: appmesh.VirtualGatewayPortMapping{Port: 8080, Protocol: appmesh.VirtualGatewayPortProtocolHTTP}},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "virtualGateway with file based listener certificate",
+			vg: &appmesh.VirtualGateway{
+				Spec: appmesh.VirtualGatewaySpec{
+					Listeners: []appmesh.VirtualGatewayListener{
+						{
+							PortMapping: appmesh.VirtualGatewayPortMapping{Port: 8080, Protocol: appmesh.VirtualGatewayPortProtocolHTTP},
+							TLS: &appmesh.VirtualGatewayListenerTLS{
+								Certificate: appmesh.VirtualGatewayListenerTLSCertificate{
+									File: &appmesh.VirtualGatewayListenerTLSFileCertificate{
+										CertificateChain: "/certs/tls.crt",
+										PrivateKey:       "/certs/tls.key",
+									},
+								},
+								Mode: appmesh.VirtualGatewayListenerTLSModeStrict,
+							},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "virtualGateway with file based client certificate",
+			vg: &appmesh.VirtualGateway{
+				Spec: appmesh.VirtualGatewaySpec{
+					BackendDefaults: &appmesh.VirtualGatewayBackendDefaults{
+						ClientPolicy: &appmesh.VirtualGatewayClientPolicy{
+							TLS: &appmesh.VirtualGatewayClientPolicyTLS{
+								Certificate: &appmesh.VirtualGatewayClientTLSCertificate{
+									File: &appmesh.VirtualGatewayListenerTLSFileCertificate{
+										CertificateChain: "/certs/tls.crt",
+										PrivateKey:       "/certs/tls.key",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "virtualGateway with file based client certificate from mesh defaults",
+			vg: &appmesh.VirtualGateway{
+				Status: appmesh.VirtualGatewayStatus{
+					AppliedMeshDefaults: &appmesh.VirtualGatewayDefaults{
+						BackendDefaults: &appmesh.MeshVirtualGatewayBackendDefaults{
+							ClientPolicy: &appmesh.VirtualGatewayClientPolicy{
+								TLS: &appmesh.VirtualGatewayClientPolicyTLS{
+									Certificate: &appmesh.VirtualGatewayClientTLSCertificate{
+										File: &appmesh.VirtualGatewayListenerTLSFileCertificate{
+											CertificateChain: "/certs/tls.crt",
+											PrivateKey:       "/certs/tls.key",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VirtualGatewayRequiresCertificate(tt.vg)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCertificateName(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  metav1.Object
+		want string
+	}{
+		{
+			name: "virtualNode",
+			obj:  &appmesh.VirtualNode{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-app"}},
+			want: "my-app-virtualnode-appmesh-tls",
+		},
+		{
+			name: "virtualGateway with the same name",
+			obj:  &appmesh.VirtualGateway{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-app"}},
+			want: "my-app-virtualgateway-appmesh-tls",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CertificateName(tt.obj))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package certmanager
+
+import (
+	"errors"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEnableCertManagerCertificates = "enable-cert-manager-certificates"
+	flagCertManagerIssuerName         = "cert-manager-issuer-name"
+	flagCertManagerIssuerKind         = "cert-manager-issuer-kind"
+	flagCertManagerIssuerGroup        = "cert-manager-issuer-group"
+	flagCertManagerMountPath          = "cert-manager-certificate-mount-path"
+
+	defaultIssuerKind  = "ClusterIssuer"
+	defaultIssuerGroup = "cert-manager.io"
+	defaultMountPath   = "/certs"
+)
+
+type Config struct {
+	// If enabled, a cert-manager Certificate will be requested for VirtualNodes and VirtualGateways using file based TLS certificates.
+	EnableCertificates bool
+	// Name of the cert-manager issuer that signs the requested certificates.
+	IssuerName string
+	// Kind of the cert-manager issuer that signs the requested certificates.
+	IssuerKind string
+	// API group of the cert-manager issuer that signs the requested certificates.
+	IssuerGroup string
+	// Path the certificate Secret is mounted at within the envoy container.
+	MountPath string
+}
+
+func (cfg *Config) BindFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&cfg.EnableCertificates, flagEnableCertManagerCertificates, false,
+		"If enabled, a cert-manager Certificate will be requested for VirtualNodes and VirtualGateways using file based TLS certificates")
+	fs.StringVar(&cfg.IssuerName, flagCertManagerIssuerName, "",
+		"Name of the cert-manager issuer that signs VirtualNode and VirtualGateway certificates")
+	fs.StringVar(&cfg.IssuerKind, flagCertManagerIssuerKind, defaultIssuerKind,
+		"Kind of the cert-manager issuer that signs VirtualNode and VirtualGateway certificates")
+	fs.StringVar(&cfg.IssuerGroup, flagCertManagerIssuerGroup, defaultIssuerGroup,
+		"API group of the cert-manager issuer that signs VirtualNode and VirtualGateway certificates")
+	fs.StringVar(&cfg.MountPath, flagCertManagerMountPath, defaultMountPath,
+		"Path the certificate Secret is mounted at within the envoy container")
+}
+
+func (cfg *Config) BindEnv() error {
+	return nil
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.EnableCertificates && cfg.IssuerName == "" {
+		return errors.New("cert-manager-issuer-name must be specified when cert-manager certificates are enabled")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package certmanager
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func NewEnqueueRequestsForGatewayRouteEvents(log logr.Logger) *enqueueRequestsForGatewayRouteEvents {
+	return &enqueueRequestsForGatewayRouteEvents{
+		log: log,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForGatewayRouteEvents)(nil)
+
+// enqueueRequestsForGatewayRouteEvents enqueues the virtualGateway of a gatewayRoute,
+// since names of virtualServices targeted by gatewayRoutes are part of the certificate DNS names of virtualGateways.
+type enqueueRequestsForGatewayRouteEvents struct {
+	log logr.Logger
+}
+
+// Create is called in response to a create event
+func (h *enqueueRequestsForGatewayRouteEvents) Create(ctx context.Context, e event.CreateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	h.enqueueVirtualGatewayForGatewayRoute(queue, e.Object.(*appmesh.GatewayRoute))
+}
+
+// Update is called in response to an update event
+func (h *enqueueRequestsForGatewayRouteEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	grOld := e.ObjectOld.(*appmesh.GatewayRoute)
+	grNew := e.ObjectNew.(*appmesh.GatewayRoute)
+	if equality.Semantic.DeepEqual(grOld.Spec, grNew.Spec) {
+		return
+	}
+	h.enqueueVirtualGatewayForGatewayRoute(queue, grOld)
+	h.enqueueVirtualGatewayForGatewayRoute(queue, grNew)
+}
+
+// Delete is called in response to a delete event
+func (h *enqueueRequestsForGatewayRouteEvents) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	h.enqueueVirtualGatewayForGatewayRoute(queue, e.Object.(*appmesh.GatewayRoute))
+}
+
+// Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
+// external trigger request
+func (h *enqueueRequestsForGatewayRouteEvents) Generic(ctx context.Context, e event.GenericEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	// no-op
+}
+
+func (h *enqueueRequestsForGatewayRouteEvents) enqueueVirtualGatewayForGatewayRoute(queue workqueue.TypedRateLimitingInterface[ctrl.Request], gr *appmesh.GatewayRoute) {
+	if gr.Spec.VirtualGatewayRef == nil {
+		return
+	}
+	vgKey := references.ObjectKeyForVirtualGatewayReference(gr, *gr.Spec.VirtualGatewayRef)
+	queue.Add(ctrl.Request{NamespacedName: vgKey})
+}
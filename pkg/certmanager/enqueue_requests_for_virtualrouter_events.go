@@ -0,0 +1,54 @@
+package certmanager
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func NewEnqueueRequestsForVirtualRouterEvents(log logr.Logger) *enqueueRequestsForVirtualRouterEvents {
+	return &enqueueRequestsForVirtualRouterEvents{
+		log: log,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForVirtualRouterEvents)(nil)
+
+// enqueueRequestsForVirtualRouterEvents enqueues virtualNodes targeted by virtualRouter routes,
+// since names of virtualServices provided by the virtualRouter are part of the certificate DNS names of these virtualNodes.
+type enqueueRequestsForVirtualRouterEvents struct {
+	log logr.Logger
+}
+
+// Create is called in response to a create event
+func (h *enqueueRequestsForVirtualRouterEvents) Create(ctx context.Context, e event.CreateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	enqueueVirtualNodesForVirtualRouter(queue, e.Object.(*appmesh.VirtualRouter))
+}
+
+// Update is called in response to an update event
+func (h *enqueueRequestsForVirtualRouterEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	vrOld := e.ObjectOld.(*appmesh.VirtualRouter)
+	vrNew := e.ObjectNew.(*appmesh.VirtualRouter)
+	if equality.Semantic.DeepEqual(vrOld.Spec.Routes, vrNew.Spec.Routes) {
+		return
+	}
+	enqueueVirtualNodesForVirtualRouter(queue, vrOld)
+	enqueueVirtualNodesForVirtualRouter(queue, vrNew)
+}
+
+// Delete is called in response to a delete event
+func (h *enqueueRequestsForVirtualRouterEvents) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	enqueueVirtualNodesForVirtualRouter(queue, e.Object.(*appmesh.VirtualRouter))
+}
+
+// Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
+// external trigger request
+func (h *enqueueRequestsForVirtualRouterEvents) Generic(ctx context.Context, e event.GenericEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	// no-op
+}
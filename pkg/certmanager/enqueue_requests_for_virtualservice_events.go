@@ -0,0 +1,91 @@
+package certmanager
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualrouter"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func NewEnqueueRequestsForVirtualServiceEvents(k8sClient client.Client, log logr.Logger) *enqueueRequestsForVirtualServiceEvents {
+	return &enqueueRequestsForVirtualServiceEvents{
+		k8sClient: k8sClient,
+		log:       log,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForVirtualServiceEvents)(nil)
+
+// enqueueRequestsForVirtualServiceEvents enqueues virtualNodes providing a virtualService,
+// since virtualService names are part of the certificate DNS names of virtualNodes.
+type enqueueRequestsForVirtualServiceEvents struct {
+	k8sClient client.Client
+	log       logr.Logger
+}
+
+// Create is called in response to a create event
+func (h *enqueueRequestsForVirtualServiceEvents) Create(ctx context.Context, e event.CreateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	h.enqueueVirtualNodesForVirtualService(ctx, queue, e.Object.(*appmesh.VirtualService))
+}
+
+// Update is called in response to an update event
+func (h *enqueueRequestsForVirtualServiceEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	vsOld := e.ObjectOld.(*appmesh.VirtualService)
+	vsNew := e.ObjectNew.(*appmesh.VirtualService)
+	if equality.Semantic.DeepEqual(vsOld.Spec.AWSName, vsNew.Spec.AWSName) &&
+		equality.Semantic.DeepEqual(vsOld.Spec.Provider, vsNew.Spec.Provider) {
+		return
+	}
+	h.enqueueVirtualNodesForVirtualService(ctx, queue, vsOld)
+	h.enqueueVirtualNodesForVirtualService(ctx, queue, vsNew)
+}
+
+// Delete is called in response to a delete event
+func (h *enqueueRequestsForVirtualServiceEvents) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	h.enqueueVirtualNodesForVirtualService(ctx, queue, e.Object.(*appmesh.VirtualService))
+}
+
+// Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
+// external trigger request
+func (h *enqueueRequestsForVirtualServiceEvents) Generic(ctx context.Context, e event.GenericEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	// no-op
+}
+
+func (h *enqueueRequestsForVirtualServiceEvents) enqueueVirtualNodesForVirtualService(ctx context.Context, queue workqueue.TypedRateLimitingInterface[ctrl.Request], vs *appmesh.VirtualService) {
+	if vs.Spec.Provider == nil {
+		return
+	}
+	if vs.Spec.Provider.VirtualNode != nil && vs.Spec.Provider.VirtualNode.VirtualNodeRef != nil {
+		vnKey := references.ObjectKeyForVirtualNodeReference(vs, *vs.Spec.Provider.VirtualNode.VirtualNodeRef)
+		queue.Add(ctrl.Request{NamespacedName: vnKey})
+	}
+	if vs.Spec.Provider.VirtualRouter != nil && vs.Spec.Provider.VirtualRouter.VirtualRouterRef != nil {
+		vrKey := references.ObjectKeyForVirtualRouterReference(vs, *vs.Spec.Provider.VirtualRouter.VirtualRouterRef)
+		vr := &appmesh.VirtualRouter{}
+		if err := h.k8sClient.Get(ctx, vrKey, vr); err != nil {
+			if !apierrors.IsNotFound(err) {
+				h.log.Error(err, "failed to enqueue virtualNodes for virtualService events",
+					"virtualService", k8s.NamespacedName(vs))
+			}
+			return
+		}
+		enqueueVirtualNodesForVirtualRouter(queue, vr)
+	}
+}
+
+// enqueueVirtualNodesForVirtualRouter enqueues virtualNodes that are route targets of vr.
+func enqueueVirtualNodesForVirtualRouter(queue workqueue.TypedRateLimitingInterface[ctrl.Request], vr *appmesh.VirtualRouter) {
+	for _, vnRef := range virtualrouter.ExtractVirtualNodeReferences(vr) {
+		queue.Add(ctrl.Request{NamespacedName: references.ObjectKeyForVirtualNodeReference(vr, vnRef)})
+	}
+}
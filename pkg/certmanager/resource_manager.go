@@ -0,0 +1,292 @@
+package certmanager
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/cloudmap"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/gatewayroute"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualrouter"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ResourceManager is dedicated to manage cert-manager Certificates for VirtualNodes and VirtualGateways.
+type ResourceManager interface {
+	// ReconcileVirtualNode will request a Certificate for vn if it uses file based TLS certificates,
+	// and roll the pods of vn once the Certificate is rotated.
+	ReconcileVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error
+
+	// ReconcileVirtualGateway will request a Certificate for vg if it uses file based TLS certificates,
+	// and roll the pods of vg once the Certificate is rotated.
+	ReconcileVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error
+}
+
+func NewDefaultResourceManager(k8sClient client.Client, scheme *runtime.Scheme, workloadRoller WorkloadRoller,
+	cloudMapNamespaceResolver cloudmap.NamespaceResolver, cfg Config, log logr.Logger) ResourceManager {
+	return &defaultResourceManager{
+		k8sClient:                 k8sClient,
+		scheme:                    scheme,
+		workloadRoller:            workloadRoller,
+		cloudMapNamespaceResolver: cloudMapNamespaceResolver,
+		cfg:                       cfg,
+		log:                       log,
+	}
+}
+
+type defaultResourceManager struct {
+	k8sClient                 client.Client
+	scheme                    *runtime.Scheme
+	workloadRoller            WorkloadRoller
+	cloudMapNamespaceResolver cloudmap.NamespaceResolver
+	cfg                       Config
+	log                       logr.Logger
+}
+
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+
+func (m *defaultResourceManager) ReconcileVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error {
+	if !VirtualNodeRequiresCertificate(vn) {
+		return m.deleteCertificate(ctx, vn)
+	}
+	dnsNames, err := m.findVirtualNodeDNSNames(ctx, vn)
+	if err != nil {
+		return err
+	}
+	cert, err := m.reconcileCertificate(ctx, vn, dnsNames)
+	if err != nil {
+		return err
+	}
+	return m.rollPodsIfRotated(ctx, vn.Namespace, vn.Spec.PodSelector, cert)
+}
+
+func (m *defaultResourceManager) ReconcileVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	if !VirtualGatewayRequiresCertificate(vg) {
+		return m.deleteCertificate(ctx, vg)
+	}
+	dnsNames, err := m.findVirtualGatewayDNSNames(ctx, vg)
+	if err != nil {
+		return err
+	}
+	cert, err := m.reconcileCertificate(ctx, vg, dnsNames)
+	if err != nil {
+		return err
+	}
+	return m.rollPodsIfRotated(ctx, vg.Namespace, vg.Spec.PodSelector, cert)
+}
+
+// reconcileCertificate creates or updates the Certificate for owner with dnsNames as SANs.
+func (m *defaultResourceManager) reconcileCertificate(ctx context.Context, owner client.Object, dnsNames []string) (*unstructured.Unstructured, error) {
+	if len(dnsNames) == 0 {
+		return nil, errors.Errorf("unable to derive DNS names for certificate of %v, "+
+			"either serviceDiscovery or a virtualService referencing it is required", k8s.NamespacedName(owner))
+	}
+	desiredCert, err := m.buildCertificate(owner, dnsNames)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := NewCertificate()
+	if err := m.k8sClient.Get(ctx, k8s.NamespacedName(desiredCert), cert); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err := m.k8sClient.Create(ctx, desiredCert); err != nil {
+			return nil, err
+		}
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("created certificate",
+			"owner", k8s.NamespacedName(owner),
+			"certificate", k8s.NamespacedName(desiredCert))
+		return desiredCert, nil
+	}
+	if !metav1.IsControlledBy(cert, owner) {
+		return nil, errors.Errorf("certificate %v already exists and isn't managed by %v",
+			k8s.NamespacedName(cert), k8s.NamespacedName(owner))
+	}
+	if equality.Semantic.DeepEqual(cert.Object["spec"], desiredCert.Object["spec"]) {
+		return cert, nil
+	}
+	cert.Object["spec"] = desiredCert.Object["spec"]
+	if err := m.k8sClient.Update(ctx, cert); err != nil {
+		return nil, err
+	}
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("updated certificate",
+		"owner", k8s.NamespacedName(owner),
+		"certificate", k8s.NamespacedName(cert))
+	return cert, nil
+}
+
+// deleteCertificate deletes the Certificate managed for owner if there is one.
+func (m *defaultResourceManager) deleteCertificate(ctx context.Context, owner client.Object) error {
+	cert := NewCertificate()
+	certKey := types.NamespacedName{Namespace: owner.GetNamespace(), Name: CertificateName(owner)}
+	if err := m.k8sClient.Get(ctx, certKey, cert); err != nil {
+		// cert-manager CRDs may not be installed when no object requires a certificate.
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(cert, owner) {
+		return nil
+	}
+	if err := m.k8sClient.Delete(ctx, cert); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("deleted certificate",
+		"owner", k8s.NamespacedName(owner),
+		"certificate", certKey)
+	return nil
+}
+
+// rollPodsIfRotated rolls pods selected by podSelector that mounted a different revision of cert.
+func (m *defaultResourceManager) rollPodsIfRotated(ctx context.Context, namespace string, podSelector *metav1.LabelSelector, cert *unstructured.Unstructured) error {
+	revision := CertificateRevision(cert)
+	if revision == "" {
+		return nil
+	}
+	return m.workloadRoller.Roll(ctx, namespace, podSelector, revision)
+}
+
+func (m *defaultResourceManager) buildCertificate(owner client.Object, dnsNames []string) (*unstructured.Unstructured, error) {
+	certName := CertificateName(owner)
+	sdkDNSNames := make([]interface{}, 0, len(dnsNames))
+	for _, dnsName := range dnsNames {
+		sdkDNSNames = append(sdkDNSNames, dnsName)
+	}
+
+	cert := NewCertificate()
+	cert.SetNamespace(owner.GetNamespace())
+	cert.SetName(certName)
+	cert.Object["spec"] = map[string]interface{}{
+		"secretName": certName,
+		"dnsNames":   sdkDNSNames,
+		"issuerRef": map[string]interface{}{
+			"name":  m.cfg.IssuerName,
+			"kind":  m.cfg.IssuerKind,
+			"group": m.cfg.IssuerGroup,
+		},
+		"usages": []interface{}{"server auth", "client auth"},
+	}
+	if err := controllerutil.SetControllerReference(owner, cert, m.scheme); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// findVirtualNodeDNSNames returns the DNS names vn can be addressed by.
+// It includes the service discovery hostname of vn, and names of virtualServices provided by vn either directly or via a virtualRouter.
+func (m *defaultResourceManager) findVirtualNodeDNSNames(ctx context.Context, vn *appmesh.VirtualNode) ([]string, error) {
+	dnsNames := sets.NewString()
+	if sd := vn.Spec.ServiceDiscovery; sd != nil {
+		if sd.DNS != nil && sd.DNS.Hostname != "" {
+			dnsNames.Insert(sd.DNS.Hostname)
+		}
+		if sd.AWSCloudMap != nil {
+			// instances in HTTP namespaces are only discoverable by API calls, so there is no DNS name for them.
+			nsSummary, err := m.cloudMapNamespaceResolver.Resolve(ctx, sd.AWSCloudMap.NamespaceName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to resolve CloudMap namespace %v", sd.AWSCloudMap.NamespaceName)
+			}
+			if nsSummary != nil && cloudmap.IsDNSNamespace(nsSummary) {
+				dnsNames.Insert(sd.AWSCloudMap.ServiceName + "." + sd.AWSCloudMap.NamespaceName)
+			}
+		}
+	}
+
+	vnKey := k8s.NamespacedName(vn)
+	vsList := &appmesh.VirtualServiceList{}
+	if err := m.k8sClient.List(ctx, vsList); err != nil {
+		return nil, errors.Wrap(err, "failed to list virtualServices")
+	}
+	vrByKey := make(map[types.NamespacedName]*appmesh.VirtualRouter)
+	for i := range vsList.Items {
+		vs := &vsList.Items[i]
+		if vs.Spec.MeshRef == nil || vn.Spec.MeshRef == nil || vs.Spec.MeshRef.UID != vn.Spec.MeshRef.UID {
+			continue
+		}
+		providedByVN, err := m.isVirtualServiceProvidedByVirtualNode(ctx, vs, vnKey, vrByKey)
+		if err != nil {
+			return nil, err
+		}
+		if providedByVN && aws.StringValue(vs.Spec.AWSName) != "" {
+			dnsNames.Insert(aws.StringValue(vs.Spec.AWSName))
+		}
+	}
+	return dnsNames.List(), nil
+}
+
+func (m *defaultResourceManager) isVirtualServiceProvidedByVirtualNode(ctx context.Context, vs *appmesh.VirtualService, vnKey types.NamespacedName,
+	vrByKey map[types.NamespacedName]*appmesh.VirtualRouter) (bool, error) {
+	if vs.Spec.Provider == nil {
+		return false, nil
+	}
+	if vs.Spec.Provider.VirtualNode != nil && vs.Spec.Provider.VirtualNode.VirtualNodeRef != nil {
+		return references.ObjectKeyForVirtualNodeReference(vs, *vs.Spec.Provider.VirtualNode.VirtualNodeRef) == vnKey, nil
+	}
+	if vs.Spec.Provider.VirtualRouter != nil && vs.Spec.Provider.VirtualRouter.VirtualRouterRef != nil {
+		vrKey := references.ObjectKeyForVirtualRouterReference(vs, *vs.Spec.Provider.VirtualRouter.VirtualRouterRef)
+		vr, ok := vrByKey[vrKey]
+		if !ok {
+			vr = &appmesh.VirtualRouter{}
+			if err := m.k8sClient.Get(ctx, vrKey, vr); err != nil {
+				if apierrors.IsNotFound(err) {
+					return false, nil
+				}
+				return false, errors.Wrapf(err, "failed to get virtualRouter: %v", vrKey)
+			}
+			vrByKey[vrKey] = vr
+		}
+		for _, vnRef := range virtualrouter.ExtractVirtualNodeReferences(vr) {
+			if references.ObjectKeyForVirtualNodeReference(vr, vnRef) == vnKey {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// findVirtualGatewayDNSNames returns the DNS names vg can be addressed by.
+// It includes names of virtualServices targeted by gatewayRoutes of vg.
+func (m *defaultResourceManager) findVirtualGatewayDNSNames(ctx context.Context, vg *appmesh.VirtualGateway) ([]string, error) {
+	dnsNames := sets.NewString()
+	vgKey := k8s.NamespacedName(vg)
+	grList := &appmesh.GatewayRouteList{}
+	if err := m.k8sClient.List(ctx, grList); err != nil {
+		return nil, errors.Wrap(err, "failed to list gatewayRoutes")
+	}
+	for i := range grList.Items {
+		gr := &grList.Items[i]
+		if gr.Spec.VirtualGatewayRef == nil || references.ObjectKeyForVirtualGatewayReference(gr, *gr.Spec.VirtualGatewayRef) != vgKey {
+			continue
+		}
+		for _, vsRef := range gatewayroute.ExtractVirtualServiceReferences(gr) {
+			vsKey := references.ObjectKeyForVirtualServiceReference(gr, vsRef)
+			vs := &appmesh.VirtualService{}
+			if err := m.k8sClient.Get(ctx, vsKey, vs); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, errors.Wrapf(err, "failed to get virtualService: %v", vsKey)
+			}
+			if aws.StringValue(vs.Spec.AWSName) != "" {
+				dnsNames.Insert(aws.StringValue(vs.Spec.AWSName))
+			}
+		}
+	}
+	return dnsNames.List(), nil
+}
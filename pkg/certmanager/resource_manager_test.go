@@ -0,0 +1,313 @@
+package certmanager
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestScheme() *runtime.Scheme {
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sSchema.AddKnownTypeWithName(CertificateGVK, &unstructured.Unstructured{})
+	k8sSchema.AddKnownTypeWithName(CertificateGVK.GroupVersion().WithKind("CertificateList"), &unstructured.UnstructuredList{})
+	return k8sSchema
+}
+
+func Test_defaultResourceManager_findVirtualNodeDNSNames(t *testing.T) {
+	meshRef := &appmesh.MeshReference{Name: "mesh", UID: "uid-1"}
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn"},
+		Spec: appmesh.VirtualNodeSpec{
+			ServiceDiscovery: &appmesh.ServiceDiscovery{
+				DNS: &appmesh.DNSServiceDiscovery{Hostname: "vn.ns-1.svc.cluster.local"},
+			},
+			MeshRef: meshRef,
+		},
+	}
+	tests := []struct {
+		name         string
+		existingObjs []client.Object
+		want         []string
+	}{
+		{
+			name: "only service discovery hostname",
+			want: []string{"vn.ns-1.svc.cluster.local"},
+		},
+		{
+			name: "virtualServices provided by virtualNode directly or via virtualRouter",
+			existingObjs: []client.Object{
+				&appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vs-1"},
+					Spec: appmesh.VirtualServiceSpec{
+						AWSName: aws.String("vs-1.ns-1.svc.cluster.local"),
+						Provider: &appmesh.VirtualServiceProvider{
+							VirtualNode: &appmesh.VirtualNodeServiceProvider{
+								VirtualNodeRef: &appmesh.VirtualNodeReference{Name: "vn"},
+							},
+						},
+						MeshRef: meshRef,
+					},
+				},
+				&appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "vs-2"},
+					Spec: appmesh.VirtualServiceSpec{
+						AWSName: aws.String("vs-2.ns-2.svc.cluster.local"),
+						Provider: &appmesh.VirtualServiceProvider{
+							VirtualRouter: &appmesh.VirtualRouterServiceProvider{
+								VirtualRouterRef: &appmesh.VirtualRouterReference{Name: "vr"},
+							},
+						},
+						MeshRef: meshRef,
+					},
+				},
+				&appmesh.VirtualRouter{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "vr"},
+					Spec: appmesh.VirtualRouterSpec{
+						Routes: []appmesh.Route{
+							{
+								Name: "route",
+								HTTPRoute: &appmesh.HTTPRoute{
+									Action: appmesh.HTTPRouteAction{
+										WeightedTargets: []appmesh.WeightedTarget{
+											{
+												VirtualNodeRef: &appmesh.VirtualNodeReference{Namespace: aws.String("ns-1"), Name: "vn"},
+												Weight:         100,
+											},
+										},
+									},
+								},
+							},
+						},
+						MeshRef: meshRef,
+					},
+				},
+				&appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vs-3"},
+					Spec: appmesh.VirtualServiceSpec{
+						AWSName: aws.String("vs-3.ns-1.svc.cluster.local"),
+						Provider: &appmesh.VirtualServiceProvider{
+							VirtualNode: &appmesh.VirtualNodeServiceProvider{
+								VirtualNodeRef: &appmesh.VirtualNodeReference{Name: "other-vn"},
+							},
+						},
+						MeshRef: meshRef,
+					},
+				},
+			},
+			want: []string{"vn.ns-1.svc.cluster.local", "vs-1.ns-1.svc.cluster.local", "vs-2.ns-2.svc.cluster.local"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sClient := testclient.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(tt.existingObjs...).Build()
+			m := &defaultResourceManager{
+				k8sClient: k8sClient,
+				log:       logr.New(&log.NullLogSink{}),
+			}
+			got, err := m.findVirtualNodeDNSNames(ctx, vn)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fakeNamespaceResolver resolves CloudMap namespaces from nsSummaryByName.
+type fakeNamespaceResolver struct {
+	nsSummaryByName map[string]*servicediscovery.NamespaceSummary
+}
+
+func (r *fakeNamespaceResolver) Resolve(_ context.Context, namespaceName string) (*servicediscovery.NamespaceSummary, error) {
+	return r.nsSummaryByName[namespaceName], nil
+}
+
+func Test_defaultResourceManager_findVirtualNodeDNSNames_cloudMap(t *testing.T) {
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn"},
+		Spec: appmesh.VirtualNodeSpec{
+			ServiceDiscovery: &appmesh.ServiceDiscovery{
+				AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{NamespaceName: "my-ns", ServiceName: "vn"},
+			},
+			MeshRef: &appmesh.MeshReference{Name: "mesh", UID: "uid-1"},
+		},
+	}
+	tests := []struct {
+		name          string
+		namespaceType string
+		want          []string
+	}{
+		{
+			name:          "DNS namespace",
+			namespaceType: servicediscovery.NamespaceTypeDnsPrivate,
+			want:          []string{"vn.my-ns"},
+		},
+		{
+			name:          "HTTP namespace",
+			namespaceType: servicediscovery.NamespaceTypeHttp,
+			want:          []string{},
+		},
+		{
+			name: "namespace not found",
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sClient := testclient.NewClientBuilder().WithScheme(newTestScheme()).Build()
+			namespaceResolver := &fakeNamespaceResolver{nsSummaryByName: map[string]*servicediscovery.NamespaceSummary{}}
+			if tt.namespaceType != "" {
+				namespaceResolver.nsSummaryByName["my-ns"] = &servicediscovery.NamespaceSummary{
+					Name: aws.String("my-ns"),
+					Type: aws.String(tt.namespaceType),
+				}
+			}
+			m := &defaultResourceManager{
+				k8sClient:                 k8sClient,
+				cloudMapNamespaceResolver: namespaceResolver,
+				log:                       logr.New(&log.NullLogSink{}),
+			}
+			got, err := m.findVirtualNodeDNSNames(ctx, vn)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_defaultResourceManager_findVirtualGatewayDNSNames(t *testing.T) {
+	vg := &appmesh.VirtualGateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vg"},
+	}
+	existingObjs := []client.Object{
+		&appmesh.GatewayRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "gr-1"},
+			Spec: appmesh.GatewayRouteSpec{
+				HTTPRoute: &appmesh.HTTPGatewayRoute{
+					Action: appmesh.HTTPGatewayRouteAction{
+						Target: appmesh.GatewayRouteTarget{
+							VirtualService: appmesh.GatewayRouteVirtualService{
+								VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs-1"},
+							},
+						},
+					},
+				},
+				VirtualGatewayRef: &appmesh.VirtualGatewayReference{Namespace: aws.String("ns-1"), Name: "vg"},
+			},
+		},
+		&appmesh.GatewayRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "gr-2"},
+			Spec: appmesh.GatewayRouteSpec{
+				HTTPRoute: &appmesh.HTTPGatewayRoute{
+					Action: appmesh.HTTPGatewayRouteAction{
+						Target: appmesh.GatewayRouteTarget{
+							VirtualService: appmesh.GatewayRouteVirtualService{
+								VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs-2"},
+							},
+						},
+					},
+				},
+				VirtualGatewayRef: &appmesh.VirtualGatewayReference{Namespace: aws.String("ns-1"), Name: "other-vg"},
+			},
+		},
+		&appmesh.VirtualService{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "vs-1"},
+			Spec:       appmesh.VirtualServiceSpec{AWSName: aws.String("vs-1.ns-2.svc.cluster.local")},
+		},
+		&appmesh.VirtualService{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "vs-2"},
+			Spec:       appmesh.VirtualServiceSpec{AWSName: aws.String("vs-2.ns-2.svc.cluster.local")},
+		},
+	}
+
+	ctx := context.Background()
+	k8sClient := testclient.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(existingObjs...).Build()
+	m := &defaultResourceManager{
+		k8sClient: k8sClient,
+		log:       logr.New(&log.NullLogSink{}),
+	}
+	got, err := m.findVirtualGatewayDNSNames(ctx, vg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vs-1.ns-2.svc.cluster.local"}, got)
+}
+
+func Test_defaultResourceManager_reconcileCertificate(t *testing.T) {
+	vn := &appmesh.VirtualNode{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "appmesh.k8s.aws/v1beta2", Kind: "VirtualNode"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn", UID: "vn-uid"},
+	}
+	cfg := Config{
+		IssuerName:  "mesh-ca",
+		IssuerKind:  "ClusterIssuer",
+		IssuerGroup: "cert-manager.io",
+	}
+	tests := []struct {
+		name         string
+		dnsNames     []string
+		wantDNSNames []interface{}
+		wantErr      string
+	}{
+		{
+			name:     "no DNS names",
+			dnsNames: nil,
+			wantErr:  "unable to derive DNS names for certificate of ns-1/vn, either serviceDiscovery or a virtualService referencing it is required",
+		},
+		{
+			name:         "certificate is created then updated",
+			dnsNames:     []string{"vn.ns-1.svc.cluster.local"},
+			wantDNSNames: []interface{}{"vn.ns-1.svc.cluster.local"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sSchema := newTestScheme()
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+			m := &defaultResourceManager{
+				k8sClient: k8sClient,
+				scheme:    k8sSchema,
+				cfg:       cfg,
+				log:       logr.New(&log.NullLogSink{}),
+			}
+			_, err := m.reconcileCertificate(ctx, vn, tt.dnsNames)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			gotCert := NewCertificate()
+			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: "ns-1", Name: "vn-virtualnode-appmesh-tls"}, gotCert)
+			assert.NoError(t, err)
+			assert.True(t, metav1.IsControlledBy(gotCert, vn))
+			gotSecretName, _, _ := unstructured.NestedString(gotCert.Object, "spec", "secretName")
+			assert.Equal(t, "vn-virtualnode-appmesh-tls", gotSecretName)
+			gotIssuerName, _, _ := unstructured.NestedString(gotCert.Object, "spec", "issuerRef", "name")
+			assert.Equal(t, "mesh-ca", gotIssuerName)
+			gotDNSNames, _, _ := unstructured.NestedSlice(gotCert.Object, "spec", "dnsNames")
+			assert.Equal(t, tt.wantDNSNames, gotDNSNames)
+
+			// reconcile again with an additional DNS name updates the existing certificate.
+			_, err = m.reconcileCertificate(ctx, vn, append(tt.dnsNames, "vs.ns-1.svc.cluster.local"))
+			assert.NoError(t, err)
+			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: "ns-1", Name: "vn-virtualnode-appmesh-tls"}, gotCert)
+			assert.NoError(t, err)
+			gotDNSNames, _, _ = unstructured.NestedSlice(gotCert.Object, "spec", "dnsNames")
+			assert.Equal(t, append(tt.wantDNSNames, "vs.ns-1.svc.cluster.local"), gotDNSNames)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package certmanager
+
+import (
+	"context"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkloadRoller rolls pods that mounted a stale certificate.
+type WorkloadRoller interface {
+	// Roll restarts the workloads owning pods selected by podSelector within namespace,
+	// if these pods mounted a certificate revision other than revision.
+	Roll(ctx context.Context, namespace string, podSelector *metav1.LabelSelector, revision string) error
+}
+
+func NewDefaultWorkloadRoller(k8sClient client.Client, apiReader client.Reader, log logr.Logger) WorkloadRoller {
+	return &defaultWorkloadRoller{
+		k8sClient: k8sClient,
+		apiReader: apiReader,
+		log:       log,
+	}
+}
+
+type defaultWorkloadRoller struct {
+	k8sClient client.Client
+	// pods and their owners are read from API server directly, so they aren't cached cluster-wide by informers.
+	apiReader client.Reader
+	log       logr.Logger
+}
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch;patch
+
+func (r *defaultWorkloadRoller) Roll(ctx context.Context, namespace string, podSelector *metav1.LabelSelector, revision string) error {
+	if podSelector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(podSelector)
+	if err != nil {
+		return err
+	}
+	podList := &corev1.PodList{}
+	if err := r.apiReader.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+
+	workloadByKey := make(map[string]client.Object)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		// pods without the annotation aren't injected with a certificate.
+		podRevision, ok := pod.Annotations[AnnotationCertificateRevision]
+		if !ok || podRevision == revision {
+			continue
+		}
+		workload, err := r.findWorkloadForPod(ctx, pod)
+		if err != nil {
+			return err
+		}
+		if workload == nil {
+			tracing.LoggerFromContext(ctx, r.log).V(1).Info("skip rolling pod without supported workload owner",
+				"pod", k8s.NamespacedName(pod))
+			continue
+		}
+		workloadKey := workload.GetObjectKind().GroupVersionKind().Kind + "/" + workload.GetName()
+		workloadByKey[workloadKey] = workload
+	}
+
+	for _, workload := range workloadByKey {
+		if err := r.rollWorkload(ctx, workload, revision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findWorkloadForPod finds the Deployment, StatefulSet or DaemonSet that owns pod.
+// returns nil if pod isn't owned by one of them.
+func (r *defaultWorkloadRoller) findWorkloadForPod(ctx context.Context, pod *corev1.Pod) (client.Object, error) {
+	podOwner := metav1.GetControllerOf(pod)
+	if podOwner == nil {
+		return nil, nil
+	}
+	switch podOwner.Kind {
+	case "ReplicaSet":
+		rs := &appsv1.ReplicaSet{}
+		if !r.getOwner(ctx, pod.Namespace, podOwner.Name, rs) {
+			return nil, nil
+		}
+		rsOwner := metav1.GetControllerOf(rs)
+		if rsOwner == nil || rsOwner.Kind != "Deployment" {
+			return nil, nil
+		}
+		deployment := &appsv1.Deployment{}
+		if !r.getOwner(ctx, pod.Namespace, rsOwner.Name, deployment) {
+			return nil, nil
+		}
+		deployment.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		return deployment, nil
+	case "StatefulSet":
+		sts := &appsv1.StatefulSet{}
+		if !r.getOwner(ctx, pod.Namespace, podOwner.Name, sts) {
+			return nil, nil
+		}
+		sts.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("StatefulSet"))
+		return sts, nil
+	case "DaemonSet":
+		ds := &appsv1.DaemonSet{}
+		if !r.getOwner(ctx, pod.Namespace, podOwner.Name, ds) {
+			return nil, nil
+		}
+		ds.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("DaemonSet"))
+		return ds, nil
+	}
+	return nil, nil
+}
+
+// getOwner fetches the owner object by namespace and name, returns false if it cannot be fetched.
+func (r *defaultWorkloadRoller) getOwner(ctx context.Context, namespace string, name string, owner client.Object) bool {
+	if err := r.apiReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, owner); err != nil {
+		if !apierrors.IsNotFound(err) {
+			tracing.LoggerFromContext(ctx, r.log).Error(err, "failed to get pod owner", "namespace", namespace, "name", name)
+		}
+		return false
+	}
+	return true
+}
+
+// rollWorkload annotates the pod template of workload with revision, which triggers a rollout of its pods.
+func (r *defaultWorkloadRoller) rollWorkload(ctx context.Context, workload client.Object, revision string) error {
+	oldWorkload := workload.DeepCopyObject().(client.Object)
+	var podTemplate *corev1.PodTemplateSpec
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		podTemplate = &w.Spec.Template
+	case *appsv1.StatefulSet:
+		podTemplate = &w.Spec.Template
+	case *appsv1.DaemonSet:
+		podTemplate = &w.Spec.Template
+	default:
+		return nil
+	}
+	if podTemplate.Annotations[AnnotationCertificateRevision] == revision {
+		return nil
+	}
+	if podTemplate.Annotations == nil {
+		podTemplate.Annotations = make(map[string]string)
+	}
+	podTemplate.Annotations[AnnotationCertificateRevision] = revision
+	if err := r.k8sClient.Patch(ctx, workload, client.MergeFrom(oldWorkload)); err != nil {
+		return errors.Wrapf(err, "failed to roll %s %v", workload.GetObjectKind().GroupVersionKind().Kind, k8s.NamespacedName(workload))
+	}
+	tracing.LoggerFromContext(ctx, r.log).Info("rolled pods for rotated certificate",
+		"kind", workload.GetObjectKind().GroupVersionKind().Kind,
+		"workload", k8s.NamespacedName(workload),
+		"revision", revision)
+	return nil
+}
@@ -0,0 +1,111 @@
+package certmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_defaultWorkloadRoller_Roll(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "app", UID: "deployment-uid"},
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "app-5d8f7",
+			UID:       "rs-uid",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", UID: "deployment-uid", Controller: aws.Bool(true)},
+			},
+		},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "db", UID: "sts-uid"},
+	}
+	podOwnedBy := func(name string, ownerKind string, ownerName string, ownerUID types.UID, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns-1",
+				Name:        name,
+				Labels:      map[string]string{"app": "mesh-app"},
+				Annotations: annotations,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: ownerKind, Name: ownerName, UID: ownerUID, Controller: aws.Bool(true)},
+				},
+			},
+		}
+	}
+	podSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "mesh-app"}}
+
+	tests := []struct {
+		name                      string
+		existingObjs              []client.Object
+		podSelector               *metav1.LabelSelector
+		revision                  string
+		wantDeploymentAnnotation  map[string]string
+		wantStatefulSetAnnotation map[string]string
+	}{
+		{
+			name: "roll workloads with pods mounting stale certificate",
+			existingObjs: []client.Object{
+				deployment.DeepCopy(), replicaSet.DeepCopy(), statefulSet.DeepCopy(),
+				podOwnedBy("app-5d8f7-1", "ReplicaSet", "app-5d8f7", "rs-uid", map[string]string{AnnotationCertificateRevision: "1"}),
+				podOwnedBy("db-0", "StatefulSet", "db", "sts-uid", map[string]string{AnnotationCertificateRevision: ""}),
+			},
+			podSelector:               podSelector,
+			revision:                  "2",
+			wantDeploymentAnnotation:  map[string]string{AnnotationCertificateRevision: "2"},
+			wantStatefulSetAnnotation: map[string]string{AnnotationCertificateRevision: "2"},
+		},
+		{
+			name: "don't roll workloads with pods mounting current certificate",
+			existingObjs: []client.Object{
+				deployment.DeepCopy(), replicaSet.DeepCopy(), statefulSet.DeepCopy(),
+				podOwnedBy("app-5d8f7-1", "ReplicaSet", "app-5d8f7", "rs-uid", map[string]string{AnnotationCertificateRevision: "2"}),
+				podOwnedBy("db-0", "StatefulSet", "db", "sts-uid", nil),
+			},
+			podSelector: podSelector,
+			revision:    "2",
+		},
+		{
+			name: "don't roll workloads without podSelector",
+			existingObjs: []client.Object{
+				deployment.DeepCopy(), replicaSet.DeepCopy(), statefulSet.DeepCopy(),
+				podOwnedBy("app-5d8f7-1", "ReplicaSet", "app-5d8f7", "rs-uid", map[string]string{AnnotationCertificateRevision: "1"}),
+			},
+			podSelector: nil,
+			revision:    "2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sClient := testclient.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(tt.existingObjs...).Build()
+			r := &defaultWorkloadRoller{
+				k8sClient: k8sClient,
+				apiReader: k8sClient,
+				log:       logr.New(&log.NullLogSink{}),
+			}
+			err := r.Roll(ctx, "ns-1", tt.podSelector, tt.revision)
+			assert.NoError(t, err)
+
+			gotDeployment := &appsv1.Deployment{}
+			assert.NoError(t, k8sClient.Get(ctx, types.NamespacedName{Namespace: "ns-1", Name: "app"}, gotDeployment))
+			assert.Equal(t, tt.wantDeploymentAnnotation, gotDeployment.Spec.Template.Annotations)
+			gotStatefulSet := &appsv1.StatefulSet{}
+			assert.NoError(t, k8sClient.Get(ctx, types.NamespacedName{Namespace: "ns-1", Name: "db"}, gotStatefulSet))
+			assert.Equal(t, tt.wantStatefulSetAnnotation, gotStatefulSet.Spec.Template.Annotations)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cloudmap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualgateway"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonDNSConfigSynced   = "DNSConfigSynced"
+	reasonDNSConfigMismatch = "DNSConfigMismatch"
+)
+
+// updateDNSConfigSyncedCondition records whether the DNS configuration of VirtualNode or VirtualGateway's cloudMap services matches its spec.
+// mismatchedServiceNames are the services whose routing policy or DNS record types differ from spec.
+// The condition is only added once there is a mismatch, and is kept afterwards to report the recovery.
+func (m *defaultResourceManager) updateDNSConfigSyncedCondition(ctx context.Context, member *meshMember, mismatchedServiceNames []string) error {
+	status := corev1.ConditionTrue
+	reason := reasonDNSConfigSynced
+	var message *string
+	if len(mismatchedServiceNames) != 0 {
+		status = corev1.ConditionFalse
+		reason = reasonDNSConfigMismatch
+		message = awssdk.String(fmt.Sprintf("routing policy or DNS record types of cloudMap services %s differ from dnsConfig, "+
+			"and can't be changed on existing services", strings.Join(mismatchedServiceNames, ", ")))
+	}
+
+	switch obj := member.obj.(type) {
+	case *appmesh.VirtualNode:
+		if status == corev1.ConditionTrue && !hasVirtualNodeCondition(obj, appmesh.VirtualNodeCloudMapDNSConfigSynced) {
+			return nil
+		}
+		oldVN := obj.DeepCopy()
+		if !virtualnode.UpdateCondition(obj, appmesh.VirtualNodeCloudMapDNSConfigSynced, status, awssdk.String(reason), message) {
+			return nil
+		}
+		return m.k8sClient.Status().Patch(ctx, obj, client.MergeFromWithOptions(oldVN, client.MergeFromWithOptimisticLock{}))
+	case *appmesh.VirtualGateway:
+		if status == corev1.ConditionTrue && !hasVirtualGatewayCondition(obj, appmesh.VirtualGatewayCloudMapDNSConfigSynced) {
+			return nil
+		}
+		oldVG := obj.DeepCopy()
+		if !virtualgateway.UpdateCondition(obj, appmesh.VirtualGatewayCloudMapDNSConfigSynced, status, awssdk.String(reason), message) {
+			return nil
+		}
+		return m.k8sClient.Status().Patch(ctx, obj, client.MergeFromWithOptions(oldVG, client.MergeFromWithOptimisticLock{}))
+	}
+	return nil
+}
+
+func hasVirtualNodeCondition(vn *appmesh.VirtualNode, conditionType appmesh.VirtualNodeConditionType) bool {
+	for _, condition := range vn.Status.Conditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVirtualGatewayCondition(vg *appmesh.VirtualGateway, conditionType appmesh.VirtualGatewayConditionType) bool {
+	for _, condition := range vg.Status.Conditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package cloudmap
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_defaultResourceManager_updateDNSConfigSyncedCondition(t *testing.T) {
+	ctx := context.Background()
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1"},
+	}
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithObjects(vn.DeepCopy()).WithStatusSubresource(&appmesh.VirtualNode{}).Build()
+	m := &defaultResourceManager{
+		k8sClient: k8sClient,
+		log:       logr.New(&log.NullLogSink{}),
+	}
+	getCondition := func() *appmesh.VirtualNodeCondition {
+		gotVN := &appmesh.VirtualNode{}
+		assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(vn), gotVN))
+		for i := range gotVN.Status.Conditions {
+			if gotVN.Status.Conditions[i].Type == appmesh.VirtualNodeCloudMapDNSConfigSynced {
+				return &gotVN.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+	update := func(mismatchedServiceNames []string) {
+		gotVN := &appmesh.VirtualNode{}
+		assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(vn), gotVN))
+		assert.NoError(t, m.updateDNSConfigSyncedCondition(ctx, newVirtualNodeMeshMember(gotVN), mismatchedServiceNames))
+	}
+
+	// condition isn't added while DNS configuration matches.
+	update(nil)
+	assert.Nil(t, getCondition())
+
+	update([]string{"cmservice", "cmservice-9090"})
+	condition := getCondition()
+	assert.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonDNSConfigMismatch, awssdk.StringValue(condition.Reason))
+	assert.Equal(t, "routing policy or DNS record types of cloudMap services cmservice, cmservice-9090 differ from dnsConfig, "+
+		"and can't be changed on existing services", awssdk.StringValue(condition.Message))
+
+	// recovery is reported once the mismatched services are recreated.
+	update(nil)
+	condition = getCondition()
+	assert.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, reasonDNSConfigSynced, awssdk.StringValue(condition.Reason))
+	assert.Nil(t, condition.Message)
+}
@@ -1,21 +1,81 @@
 package cloudmap
 
 import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 )
 
 const (
-	flagSetCloudMapTTL = "cloudmap-dns-ttl"
+	flagSetCloudMapTTL                           = "cloudmap-dns-ttl"
+	flagSetCloudMapHealthStatusUpdateConcurrency = "cloudmap-health-status-update-concurrency"
+	flagSetCloudMapEndpointSource                = "cloudmap-endpoint-source"
+	flagSetCloudMapClusterID                     = "cloudmap-cluster-id"
+	flagSetCloudMapHealthSource                  = "cloudmap-health-source"
+	flagSetCloudMapHealthReadinessGate           = "cloudmap-health-readiness-gate"
+	flagSetCloudMapHealthProbePath               = "cloudmap-health-probe-path"
+	flagSetCloudMapHealthProbeTimeout            = "cloudmap-health-probe-timeout"
+	flagSetCloudMapHealthProbePeriod             = "cloudmap-health-probe-period"
+
+	// EndpointSourcePod resolves pods of VirtualNodes by watching pods.
+	EndpointSourcePod = "pod"
+	// EndpointSourceEndpointSlice resolves pods of VirtualNodes from EndpointSlices of their Service.
+	EndpointSourceEndpointSlice = "endpointslice"
+
+	defaultHealthStatusUpdateConcurrency = 8
+	// clusterID is registered as instance attribute value.
+	maxClusterIDLength = maxInstanceAttributeValueLength
 )
 
 type Config struct {
 	//Specifies the DNS TTL value to be used while creating CloudMap services.
 	CloudMapServiceTTL int64
+	// Specifies the max number of concurrent custom health status updates of CloudMap instances.
+	HealthStatusUpdateConcurrency int
+	// Specifies how pods of VirtualNodes are resolved, either pod or endpointslice.
+	EndpointSource string
+	// Specifies the ID of this cluster when CloudMap services are shared by multiple clusters.
+	// multi-cluster mode is disabled if empty.
+	ClusterID string
+	// Specifies what decides health of CloudMap instances, one of endpoint, envoy, readiness-gate or http-probe.
+	HealthSource string
+	// Specifies the pod condition type followed by readiness-gate health source.
+	HealthReadinessGate string
+	// Specifies the HTTP path probed by http-probe health source.
+	HealthProbePath string
+	// Specifies the timeout of probes of http-probe health source.
+	HealthProbeTimeout time.Duration
+	// Specifies how frequently pods are probed by http-probe health source.
+	HealthProbePeriod time.Duration
 }
 
 func (cfg *Config) BindFlags(fs *pflag.FlagSet) {
 	fs.Int64Var(&cfg.CloudMapServiceTTL, flagSetCloudMapTTL, defaultServiceDNSConfigTTL,
 		`CloudMap Service DNS TTL value`)
+	fs.IntVar(&cfg.HealthStatusUpdateConcurrency, flagSetCloudMapHealthStatusUpdateConcurrency, defaultHealthStatusUpdateConcurrency,
+		`The max number of concurrent custom health status updates of CloudMap instances`)
+	fs.StringVar(&cfg.EndpointSource, flagSetCloudMapEndpointSource, EndpointSourcePod,
+		`How pods of VirtualNodes are resolved for CloudMap registration, either pod or endpointslice`)
+	fs.StringVar(&cfg.ClusterID, flagSetCloudMapClusterID, "",
+		`The ID of this cluster, enables sharing CloudMap services with controllers in other clusters if specified`)
+	fs.StringVar(&cfg.HealthSource, flagSetCloudMapHealthSource, HealthSourceEndpoint,
+		`What decides health of CloudMap instances, one of endpoint, envoy, readiness-gate or http-probe`)
+	fs.StringVar(&cfg.HealthReadinessGate, flagSetCloudMapHealthReadinessGate, "",
+		`The pod condition type that decides health of CloudMap instances with readiness-gate health source`)
+	fs.StringVar(&cfg.HealthProbePath, flagSetCloudMapHealthProbePath, defaultHealthProbePath,
+		`The HTTP path probed through Envoy with http-probe health source`)
+	fs.DurationVar(&cfg.HealthProbeTimeout, flagSetCloudMapHealthProbeTimeout, defaultHealthProbeTimeout,
+		`The timeout of HTTP probes with http-probe health source`)
+	fs.DurationVar(&cfg.HealthProbePeriod, flagSetCloudMapHealthProbePeriod, defaultHealthProbeResync,
+		`How frequently pods are probed with http-probe health source`)
+}
+
+// MultiClusterEnabled returns whether CloudMap services are shared by multiple clusters.
+func (cfg *Config) MultiClusterEnabled() bool {
+	return cfg.ClusterID != ""
 }
 
 func (cfg *Config) BindEnv() error {
@@ -23,5 +83,45 @@ func (cfg *Config) BindEnv() error {
 }
 
 func (cfg *Config) Validate() error {
+	if cfg.HealthStatusUpdateConcurrency < 1 {
+		return errors.Errorf("%s must be positive", flagSetCloudMapHealthStatusUpdateConcurrency)
+	}
+	if cfg.EndpointSource != EndpointSourcePod && cfg.EndpointSource != EndpointSourceEndpointSlice {
+		return errors.Errorf("%s must be either %s or %s", flagSetCloudMapEndpointSource, EndpointSourcePod, EndpointSourceEndpointSlice)
+	}
+	if len(cfg.ClusterID) > maxClusterIDLength {
+		return errors.Errorf("%s must be no more than %d characters", flagSetCloudMapClusterID, maxClusterIDLength)
+	}
+	if err := cfg.validateHealthSource(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cfg *Config) validateHealthSource() error {
+	switch cfg.HealthSource {
+	case HealthSourceEndpoint, HealthSourceEnvoy:
+	case HealthSourceReadinessGate:
+		if cfg.HealthReadinessGate == "" {
+			return errors.Errorf("%s must be specified with %s health source", flagSetCloudMapHealthReadinessGate, HealthSourceReadinessGate)
+		}
+		// the aws-cloudmap-healthy readiness gate is set from the health of instances, it can't decide the health itself.
+		if cfg.HealthReadinessGate == k8s.ConditionAWSCloudMapHealthy {
+			return errors.Errorf("%s must not be %s", flagSetCloudMapHealthReadinessGate, k8s.ConditionAWSCloudMapHealthy)
+		}
+	case HealthSourceHTTPProbe:
+		if !strings.HasPrefix(cfg.HealthProbePath, "/") {
+			return errors.Errorf("%s must start with /", flagSetCloudMapHealthProbePath)
+		}
+		if cfg.HealthProbeTimeout <= 0 {
+			return errors.Errorf("%s must be positive", flagSetCloudMapHealthProbeTimeout)
+		}
+		if cfg.HealthProbePeriod <= 0 {
+			return errors.Errorf("%s must be positive", flagSetCloudMapHealthProbePeriod)
+		}
+	default:
+		return errors.Errorf("%s must be one of %s, %s, %s or %s", flagSetCloudMapHealthSource,
+			HealthSourceEndpoint, HealthSourceEnvoy, HealthSourceReadinessGate, HealthSourceHTTPProbe)
+	}
 	return nil
 }
@@ -3,7 +3,6 @@ package cloudmap
 import (
 	"context"
 
-	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -11,30 +10,32 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type VirtualNodeEndpointResolver interface {
-	Resolve(ctx context.Context, vn *appmesh.VirtualNode) ([]*corev1.Pod, []*corev1.Pod, []*corev1.Pod, error)
+// EndpointResolver resolves pods of VirtualNode or VirtualGateway.
+type EndpointResolver interface {
+	// Resolve returns the ready, notReady and ignored pods selected by podSelector in namespace of obj.
+	Resolve(ctx context.Context, obj client.Object, podSelector *metav1.LabelSelector) ([]*corev1.Pod, []*corev1.Pod, []*corev1.Pod, error)
 }
 
-func NewDefaultVirtualNodeEndpointResolver(podsRepository k8s.PodsRepository, log logr.Logger) *defaultVirtualNodeEndpointResolver {
-	return &defaultVirtualNodeEndpointResolver{
+func NewDefaultEndpointResolver(podsRepository k8s.PodsRepository, log logr.Logger) *defaultEndpointResolver {
+	return &defaultEndpointResolver{
 		podsRepository: podsRepository,
 		log:            log,
 	}
 }
 
-var _ VirtualNodeEndpointResolver = &defaultVirtualNodeEndpointResolver{}
+var _ EndpointResolver = &defaultEndpointResolver{}
 
-type defaultVirtualNodeEndpointResolver struct {
+type defaultEndpointResolver struct {
 	podsRepository k8s.PodsRepository
 	log            logr.Logger
 }
 
-func (e *defaultVirtualNodeEndpointResolver) Resolve(ctx context.Context, vNode *appmesh.VirtualNode) ([]*corev1.Pod, []*corev1.Pod, []*corev1.Pod, error) {
+func (e *defaultEndpointResolver) Resolve(ctx context.Context, obj client.Object, podSelector *metav1.LabelSelector) ([]*corev1.Pod, []*corev1.Pod, []*corev1.Pod, error) {
 	var podsList *corev1.PodList
 	var err error
 	var listOptions client.ListOptions
-	listOptions.LabelSelector, _ = metav1.LabelSelectorAsSelector(vNode.Spec.PodSelector)
-	listOptions.Namespace = vNode.Namespace
+	listOptions.LabelSelector, _ = metav1.LabelSelectorAsSelector(podSelector)
+	listOptions.Namespace = obj.GetNamespace()
 
 	if podsList, err = e.podsRepository.ListPodsWithMatchingLabels(listOptions); err != nil {
 		return nil, nil, nil, err
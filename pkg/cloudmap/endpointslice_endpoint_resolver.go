@@ -0,0 +1,216 @@
+package cloudmap
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// AnnotationEndpointService is the VirtualNode or VirtualGateway annotation specifying the Service whose EndpointSlices are used to resolve its pods.
+	// it defaults to the Service with the VirtualNode or VirtualGateway's name.
+	AnnotationEndpointService = "appmesh.k8s.aws/cloudMapEndpointService"
+
+	// annotationTopologyZoneHints is set on pods resolved from EndpointSlices, with the zones from topology hints of their endpoint.
+	annotationTopologyZoneHints = "appmesh.k8s.aws/topologyZoneHints"
+)
+
+// NewEndpointSliceEndpointResolver constructs new EndpointResolver that resolves pods from EndpointSlices.
+func NewEndpointSliceEndpointResolver(k8sClient client.Client, log logr.Logger) *endpointSliceEndpointResolver {
+	return &endpointSliceEndpointResolver{
+		k8sClient: k8sClient,
+		log:       log,
+	}
+}
+
+var _ EndpointResolver = &endpointSliceEndpointResolver{}
+
+// endpointSliceEndpointResolver resolves pods of VirtualNode or VirtualGateway from EndpointSlices of its Service,
+// so that only pods kube-proxy routes to are registered, and terminating pods are handled as kube-proxy does.
+type endpointSliceEndpointResolver struct {
+	k8sClient client.Client
+	log       logr.Logger
+}
+
+// endpointState is the aggregated state of a pod's endpoints across EndpointSlices.
+type endpointState struct {
+	ready       bool
+	serving     bool
+	terminating bool
+	zoneHints   []string
+}
+
+func (e *endpointSliceEndpointResolver) Resolve(ctx context.Context, obj client.Object, podSelector *metav1.LabelSelector) ([]*corev1.Pod, []*corev1.Pod, []*corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(podSelector)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	epsList := &discoveryv1.EndpointSliceList{}
+	if err := e.k8sClient.List(ctx, epsList, client.InNamespace(obj.GetNamespace()),
+		client.MatchingLabels{discoveryv1.LabelServiceName: endpointServiceName(obj)}); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "failed to list endpointSlices")
+	}
+	endpointStateByPod := aggregateEndpointStateByPod(epsList.Items)
+
+	podKeys := make([]types.NamespacedName, 0, len(endpointStateByPod))
+	for podKey := range endpointStateByPod {
+		podKeys = append(podKeys, podKey)
+	}
+	sort.Slice(podKeys, func(i, j int) bool {
+		return podKeys[i].String() < podKeys[j].String()
+	})
+
+	var readyPods []*corev1.Pod
+	var notReadyPods []*corev1.Pod
+	var ignoredPods []*corev1.Pod
+	for _, podKey := range podKeys {
+		pod := &corev1.Pod{}
+		if err := e.k8sClient.Get(ctx, podKey, pod); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, nil, nil, err
+		}
+		// the Service can select pods of other VirtualNodes, such as other versions of an application.
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		state := endpointStateByPod[podKey]
+		if len(state.zoneHints) != 0 {
+			if pod.Annotations == nil {
+				pod.Annotations = make(map[string]string)
+			}
+			pod.Annotations[annotationTopologyZoneHints] = strings.Join(state.zoneHints, ",")
+		}
+
+		// the ready and serving conditions of endpoints include the aws-cloudmap-healthy readiness gate, which is only
+		// satisfied once pod is registered, so the readiness of pods is evaluated without it instead.
+		serving := isPodReadyIgnoringCloudMapHealthyGate(pod)
+		switch {
+		case pod.Status.PodIP == "":
+			ignoredPods = append(ignoredPods, pod)
+		case serving && !state.terminating:
+			readyPods = append(readyPods, pod)
+		case state.terminating && !serving:
+			ignoredPods = append(ignoredPods, pod)
+		default:
+			notReadyPods = append(notReadyPods, pod)
+		}
+	}
+	return readyPods, notReadyPods, ignoredPods, nil
+}
+
+// isPodReadyIgnoringCloudMapHealthyGate checks whether pod is ready, as its Ready condition without the aws-cloudmap-healthy readiness gate.
+// i.e. its containers are ready, and its other readiness gates are satisfied.
+func isPodReadyIgnoringCloudMapHealthyGate(pod *corev1.Pod) bool {
+	if !ArePodContainersReady(pod) {
+		return false
+	}
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == k8s.ConditionAWSCloudMapHealthy {
+			continue
+		}
+		condition := k8s.GetPodCondition(pod, gate.ConditionType)
+		if condition == nil || condition.Status != corev1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// aggregateEndpointStateByPod computes the endpointState of pods from endpoints of EndpointSlices.
+// a pod can have endpoints in multiple EndpointSlices, such as for each address type.
+func aggregateEndpointStateByPod(endpointSlices []discoveryv1.EndpointSlice) map[types.NamespacedName]endpointState {
+	endpointStateByPod := make(map[types.NamespacedName]endpointState)
+	for _, eps := range endpointSlices {
+		for _, endpoint := range eps.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+				continue
+			}
+			podKey := types.NamespacedName{Namespace: eps.Namespace, Name: endpoint.TargetRef.Name}
+			if endpoint.TargetRef.Namespace != "" {
+				podKey.Namespace = endpoint.TargetRef.Namespace
+			}
+			state := endpointStateByPod[podKey]
+			// nil conditions must be interpreted as ready and serving, and not terminating.
+			ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+			serving := ready
+			if endpoint.Conditions.Serving != nil {
+				serving = *endpoint.Conditions.Serving
+			}
+			terminating := endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating
+			state.ready = state.ready || ready
+			state.serving = state.serving || serving
+			state.terminating = state.terminating || terminating
+			if endpoint.Hints != nil {
+				for _, zone := range endpoint.Hints.ForZones {
+					state.zoneHints = appendIfMissing(state.zoneHints, zone.Name)
+				}
+			}
+			endpointStateByPod[podKey] = state
+		}
+	}
+	return endpointStateByPod
+}
+
+func appendIfMissing(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// endpointServiceName returns the name of Service whose EndpointSlices are used to resolve pods of VirtualNode or VirtualGateway.
+func endpointServiceName(obj client.Object) string {
+	if serviceName, ok := obj.GetAnnotations()[AnnotationEndpointService]; ok && serviceName != "" {
+		return serviceName
+	}
+	return obj.GetName()
+}
+
+// VirtualNodeRequestsForEndpointSlice returns a MapFunc that maps EndpointSlices to the VirtualNodes resolving pods from them.
+func VirtualNodeRequestsForEndpointSlice(k8sClient client.Client, log logr.Logger) handler.MapFunc {
+	return meshMemberRequestsForEndpointSlice(k8sClient, listVirtualNodeMeshMembers, log)
+}
+
+// VirtualGatewayRequestsForEndpointSlice returns a MapFunc that maps EndpointSlices to the VirtualGateways resolving pods from them.
+func VirtualGatewayRequestsForEndpointSlice(k8sClient client.Client, log logr.Logger) handler.MapFunc {
+	return meshMemberRequestsForEndpointSlice(k8sClient, listVirtualGatewayMeshMembers, log)
+}
+
+func meshMemberRequestsForEndpointSlice(k8sClient client.Client, listMeshMembers meshMembersLister, log logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		serviceName, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+		if !ok {
+			return nil
+		}
+		members, err := listMeshMembers(ctx, k8sClient, obj.GetNamespace())
+		if err != nil {
+			log.Error(err, "failed to enqueue mesh members for endpointSlice events",
+				"endpointSlice", k8s.NamespacedName(obj))
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, member := range members {
+			if endpointServiceName(member.obj) == serviceName {
+				requests = append(requests, reconcile.Request{NamespacedName: k8s.NamespacedName(member.obj)})
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,221 @@
+package cloudmap
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_endpointSliceEndpointResolver_Resolve(t *testing.T) {
+	newPod := func(name string, labels map[string]string, podIP string, containersReady corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: labels},
+			Status: corev1.PodStatus{
+				PodIP:      podIP,
+				Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: containersReady}},
+			},
+		}
+	}
+	// pod whose only unmet condition is the aws-cloudmap-healthy readiness gate, i.e. it isn't registered yet.
+	gatedPod := newPod("pod-gated", map[string]string{"app": "my-app", "version": "v1"}, "192.168.1.8", corev1.ConditionTrue)
+	gatedPod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: k8s.ConditionAWSCloudMapHealthy}}
+	gatedPod.Status.Conditions = append(gatedPod.Status.Conditions,
+		corev1.PodCondition{Type: k8s.ConditionAWSCloudMapHealthy, Status: corev1.ConditionFalse})
+	// pod with other unmet readiness gate besides aws-cloudmap-healthy.
+	otherGatedPod := newPod("pod-other-gate", map[string]string{"app": "my-app", "version": "v1"}, "192.168.1.9", corev1.ConditionTrue)
+	otherGatedPod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: k8s.ConditionAWSCloudMapHealthy}, {ConditionType: "example.com/gate"}}
+	newEndpoint := func(podName string, ready *bool, serving *bool, terminating *bool) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{
+			Addresses:  []string{"192.168.1.1"},
+			Conditions: discoveryv1.EndpointConditions{Ready: ready, Serving: serving, Terminating: terminating},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: "ns", Name: podName},
+		}
+	}
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-vn"},
+		Spec: appmesh.VirtualNodeSpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "my-app", "version": "v1"}},
+		},
+	}
+	v1Labels := map[string]string{"app": "my-app", "version": "v1"}
+	v2Labels := map[string]string{"app": "my-app", "version": "v2"}
+	hintedEndpoint := newEndpoint("pod-hinted", nil, nil, nil)
+	hintedEndpoint.Hints = &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: "us-west-2a"}, {Name: "us-west-2b"}}}
+	eps := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "my-vn-abcde",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "my-vn"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			newEndpoint("pod-ready", aws.Bool(true), aws.Bool(true), aws.Bool(false)),
+			newEndpoint("pod-not-ready", aws.Bool(false), aws.Bool(false), aws.Bool(false)),
+			newEndpoint("pod-terminating-serving", aws.Bool(false), aws.Bool(true), aws.Bool(true)),
+			newEndpoint("pod-terminating", aws.Bool(false), aws.Bool(false), aws.Bool(true)),
+			newEndpoint("pod-other-version", aws.Bool(true), aws.Bool(true), aws.Bool(false)),
+			newEndpoint("pod-deleted", aws.Bool(true), aws.Bool(true), aws.Bool(false)),
+			newEndpoint("pod-gated", aws.Bool(false), aws.Bool(false), aws.Bool(false)),
+			newEndpoint("pod-other-gate", aws.Bool(false), aws.Bool(false), aws.Bool(false)),
+			hintedEndpoint,
+		},
+	}
+	otherEPS := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "other-svc-abcde",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "other-svc"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   []discoveryv1.Endpoint{newEndpoint("pod-other-svc", aws.Bool(true), nil, nil)},
+	}
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithObjects(
+		eps, otherEPS,
+		newPod("pod-ready", v1Labels, "192.168.1.1", corev1.ConditionTrue),
+		newPod("pod-not-ready", v1Labels, "192.168.1.2", corev1.ConditionFalse),
+		newPod("pod-terminating-serving", v1Labels, "192.168.1.3", corev1.ConditionTrue),
+		newPod("pod-terminating", v1Labels, "192.168.1.4", corev1.ConditionFalse),
+		newPod("pod-other-version", v2Labels, "192.168.1.5", corev1.ConditionTrue),
+		newPod("pod-hinted", v1Labels, "192.168.1.6", corev1.ConditionTrue),
+		newPod("pod-other-svc", v1Labels, "192.168.1.7", corev1.ConditionTrue),
+		gatedPod, otherGatedPod,
+	).Build()
+	r := NewEndpointSliceEndpointResolver(k8sClient, logr.New(&log.NullLogSink{}))
+
+	readyPods, notReadyPods, ignoredPods, err := r.Resolve(context.Background(), vn, vn.Spec.PodSelector)
+	assert.NoError(t, err)
+	podNames := func(pods []*corev1.Pod) []string {
+		var names []string
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"pod-gated", "pod-hinted", "pod-ready"}, podNames(readyPods))
+	assert.Equal(t, []string{"pod-not-ready", "pod-other-gate", "pod-terminating-serving"}, podNames(notReadyPods))
+	assert.Equal(t, []string{"pod-terminating"}, podNames(ignoredPods))
+	assert.Equal(t, "us-west-2a,us-west-2b", readyPods[1].Annotations[annotationTopologyZoneHints])
+}
+
+func Test_aggregateEndpointStateByPod(t *testing.T) {
+	podRef := &corev1.ObjectReference{Kind: "Pod", Name: "pod-1"}
+	endpointSlices := []discoveryv1.EndpointSlice{
+		{
+			ObjectMeta:  metav1.ObjectMeta{Namespace: "ns", Name: "svc-ipv4"},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					Conditions: discoveryv1.EndpointConditions{Ready: aws.Bool(false)},
+					TargetRef:  podRef,
+					Hints:      &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: "us-west-2a"}}},
+				},
+				{
+					Conditions: discoveryv1.EndpointConditions{Ready: aws.Bool(true)},
+				},
+			},
+		},
+		{
+			ObjectMeta:  metav1.ObjectMeta{Namespace: "ns", Name: "svc-ipv6"},
+			AddressType: discoveryv1.AddressTypeIPv6,
+			Endpoints: []discoveryv1.Endpoint{
+				{
+					Conditions: discoveryv1.EndpointConditions{},
+					TargetRef:  podRef,
+					Hints:      &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: "us-west-2a"}}},
+				},
+			},
+		},
+	}
+	got := aggregateEndpointStateByPod(endpointSlices)
+	assert.Equal(t, map[types.NamespacedName]endpointState{
+		{Namespace: "ns", Name: "pod-1"}: {
+			ready:     true,
+			serving:   true,
+			zoneHints: []string{"us-west-2a"},
+		},
+	}, got)
+}
+
+func Test_VirtualNodeRequestsForEndpointSlice(t *testing.T) {
+	cloudMapSD := &appmesh.ServiceDiscovery{AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{}}
+	vnDefault := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-svc"},
+		Spec:       appmesh.VirtualNodeSpec{ServiceDiscovery: cloudMapSD},
+	}
+	vnAnnotated := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "my-vn-v2",
+			Annotations: map[string]string{AnnotationEndpointService: "my-svc"},
+		},
+		Spec: appmesh.VirtualNodeSpec{ServiceDiscovery: cloudMapSD},
+	}
+	vnWithoutCloudMap := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "my-vn-dns",
+			Annotations: map[string]string{AnnotationEndpointService: "my-svc"},
+		},
+	}
+	vnOtherNamespace := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other-ns", Name: "my-svc"},
+		Spec:       appmesh.VirtualNodeSpec{ServiceDiscovery: cloudMapSD},
+	}
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).
+		WithObjects(vnDefault, vnAnnotated, vnWithoutCloudMap, vnOtherNamespace).Build()
+	mapFunc := VirtualNodeRequestsForEndpointSlice(k8sClient, logr.New(&log.NullLogSink{}))
+
+	tests := []struct {
+		name string
+		eps  client.Object
+		want []reconcile.Request
+	}{
+		{
+			name: "endpointSlice of service",
+			eps: &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "my-svc-abcde",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "my-svc"},
+			}},
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "my-svc"}},
+				{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "my-vn-v2"}},
+			},
+		},
+		{
+			name: "endpointSlice without service",
+			eps: &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "custom",
+			}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapFunc(context.Background(), tt.eps)
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
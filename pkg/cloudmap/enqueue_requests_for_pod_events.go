@@ -2,7 +2,6 @@ package cloudmap
 
 import (
 	"context"
-	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -16,23 +15,37 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
-func NewEnqueueRequestsForPodEvents(k8sClient client.Client, log logr.Logger) *enqueueRequestsForPodEvents {
+func NewEnqueueRequestsForPodEvents(k8sClient client.Client, healthSource HealthSource, log logr.Logger) *enqueueRequestsForPodEvents {
 	return &enqueueRequestsForPodEvents{
-		k8sClient: k8sClient,
-		log:       log,
+		k8sClient:       k8sClient,
+		healthSource:    healthSource,
+		listMeshMembers: listVirtualNodeMeshMembers,
+		log:             log,
+	}
+}
+
+// NewEnqueueVirtualGatewayRequestsForPodEvents constructs handler that enqueues VirtualGateways selecting pods.
+func NewEnqueueVirtualGatewayRequestsForPodEvents(k8sClient client.Client, healthSource HealthSource, log logr.Logger) *enqueueRequestsForPodEvents {
+	return &enqueueRequestsForPodEvents{
+		k8sClient:       k8sClient,
+		healthSource:    healthSource,
+		listMeshMembers: listVirtualGatewayMeshMembers,
+		log:             log,
 	}
 }
 
 var _ handler.EventHandler = (*enqueueRequestsForPodEvents)(nil)
 
 type enqueueRequestsForPodEvents struct {
-	k8sClient client.Client
-	log       logr.Logger
+	k8sClient       client.Client
+	healthSource    HealthSource
+	listMeshMembers meshMembersLister
+	log             logr.Logger
 }
 
 // Create is called in response to an create event
 func (h *enqueueRequestsForPodEvents) Create(ctx context.Context, e event.CreateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
-	h.enqueueVirtualNodesForPods(ctx, queue, e.Object.(*corev1.Pod))
+	h.enqueueMeshMembersForPods(ctx, queue, e.Object.(*corev1.Pod))
 }
 
 // Update is called in response to an update event
@@ -44,22 +57,24 @@ func (h *enqueueRequestsForPodEvents) Update(ctx context.Context, e event.Update
 
 	labelsChanged := !reflect.DeepEqual(oldPod.Labels, newPod.Labels)
 
-	if newPod.DeletionTimestamp != nil || ReadyStatusChanged(oldPod, newPod) || labelsChanged {
+	if newPod.DeletionTimestamp != nil || ReadyStatusChanged(oldPod, newPod) || h.healthSource.HealthChanged(oldPod, newPod) || labelsChanged {
 		if labelsChanged {
-			h.enqueueVirtualNodesForPods(ctx, queue, oldPod)
+			h.enqueueMeshMembersForPods(ctx, queue, oldPod)
 		}
 
-		h.enqueueVirtualNodesForPods(ctx, queue, newPod)
+		h.enqueueMeshMembersForPods(ctx, queue, newPod)
 	}
 }
 
+// ReadyStatusChanged checks whether the readiness of pod changed, either of its containers or of its readiness gates other than aws-cloudmap-healthy.
 func ReadyStatusChanged(pod1 *corev1.Pod, pod2 *corev1.Pod) bool {
-	return ArePodContainersReady(pod1) != ArePodContainersReady(pod2)
+	return ArePodContainersReady(pod1) != ArePodContainersReady(pod2) ||
+		isPodReadyIgnoringCloudMapHealthyGate(pod1) != isPodReadyIgnoringCloudMapHealthyGate(pod2)
 }
 
 // Delete is called in response to a delete event
 func (h *enqueueRequestsForPodEvents) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
-	h.enqueueVirtualNodesForPods(ctx, queue, e.Object.(*corev1.Pod))
+	h.enqueueMeshMembersForPods(ctx, queue, e.Object.(*corev1.Pod))
 }
 
 // Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
@@ -68,27 +83,22 @@ func (h *enqueueRequestsForPodEvents) Generic(ctx context.Context, e event.Gener
 	// no-op
 }
 
-func (h *enqueueRequestsForPodEvents) enqueueVirtualNodesForPods(ctx context.Context, queue workqueue.TypedRateLimitingInterface[ctrl.Request],
+func (h *enqueueRequestsForPodEvents) enqueueMeshMembersForPods(ctx context.Context, queue workqueue.TypedRateLimitingInterface[ctrl.Request],
 	pod *corev1.Pod) {
-	var listOptions client.ListOptions
-	listOptions.Namespace = pod.Namespace
-	vnList := &appmesh.VirtualNodeList{}
-	if err := h.k8sClient.List(ctx, vnList, &listOptions); err != nil {
-		h.log.Error(err, "failed to enqueue virtualNodes for pod events",
+	members, err := h.listMeshMembers(ctx, h.k8sClient, pod.Namespace)
+	if err != nil {
+		h.log.Error(err, "failed to enqueue mesh members for pod events",
 			"Pod", k8s.NamespacedName(pod))
 		return
 	}
 
-	for _, vn := range vnList.Items {
-		if vn.Spec.ServiceDiscovery == nil || vn.Spec.ServiceDiscovery.AWSCloudMap == nil {
-			continue
-		}
-		selector, err := metav1.LabelSelectorAsSelector(vn.Spec.PodSelector)
+	for _, member := range members {
+		selector, err := metav1.LabelSelectorAsSelector(member.podSelector)
 		if err != nil {
 			continue
 		}
 		if selector.Matches(labels.Set(pod.Labels)) {
-			queue.Add(ctrl.Request{NamespacedName: k8s.NamespacedName(&vn)})
+			queue.Add(ctrl.Request{NamespacedName: k8s.NamespacedName(member.obj)})
 		}
 	}
 }
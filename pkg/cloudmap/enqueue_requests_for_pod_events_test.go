@@ -97,10 +97,7 @@ func Test_enqueueRequestsForPodEvents_Create(t *testing.T) {
 			appmesh.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
 			queue := workqueue.NewTypedRateLimitingQueue[ctrl.Request](workqueue.DefaultTypedControllerRateLimiter[ctrl.Request]())
-			h := &enqueueRequestsForPodEvents{
-				k8sClient: k8sClient,
-				log:       logr.New(&log.NullLogSink{}),
-			}
+			h := NewEnqueueRequestsForPodEvents(k8sClient, &endpointHealthSource{}, logr.New(&log.NullLogSink{}))
 
 			for _, vn := range tt.env.virtualNodes {
 				err := k8sClient.Create(ctx, vn.DeepCopy())
@@ -255,6 +252,67 @@ func Test_enqueueRequestsForPodEvents_Update(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Pod readiness gate other than aws-cloudmap-healthy is satisfied",
+			env: env{
+				virtualNodes: []*appmesh.VirtualNode{vn1, vn2},
+			},
+			args: args{
+				e: event.UpdateEvent{
+					ObjectOld: &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test_pod1",
+							UID:  "b387048d-aba8-6235-9a11-5343764c8ab",
+							Labels: map[string]string{
+								"app": "testapp",
+							},
+						},
+						Spec: corev1.PodSpec{
+							ReadinessGates: []corev1.PodReadinessGate{{ConditionType: "example.com/gate"}},
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							Conditions: []corev1.PodCondition{
+								{
+									Type:   corev1.ContainersReady,
+									Status: corev1.ConditionTrue,
+								},
+							},
+						},
+					},
+					ObjectNew: &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "test_pod1",
+							UID:  "b387048d-aba8-6235-9a11-5343764c8ab",
+							Labels: map[string]string{
+								"app": "testapp",
+							},
+						},
+						Spec: corev1.PodSpec{
+							ReadinessGates: []corev1.PodReadinessGate{{ConditionType: "example.com/gate"}},
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodRunning,
+							Conditions: []corev1.PodCondition{
+								{
+									Type:   corev1.ContainersReady,
+									Status: corev1.ConditionTrue,
+								},
+								{
+									Type:   "example.com/gate",
+									Status: corev1.ConditionTrue,
+								},
+							},
+						},
+					},
+				},
+			},
+			wantRequests: []reconcile.Request{
+				{
+					NamespacedName: k8s.NamespacedName(vn1),
+				},
+			},
+		},
 		{
 			name: "Pod labels changed",
 			env: env{
@@ -309,10 +367,7 @@ func Test_enqueueRequestsForPodEvents_Update(t *testing.T) {
 			appmesh.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
 			queue := workqueue.NewTypedRateLimitingQueue[ctrl.Request](workqueue.DefaultTypedControllerRateLimiter[ctrl.Request]())
-			h := &enqueueRequestsForPodEvents{
-				k8sClient: k8sClient,
-				log:       logr.New(&log.NullLogSink{}),
-			}
+			h := NewEnqueueRequestsForPodEvents(k8sClient, &endpointHealthSource{}, logr.New(&log.NullLogSink{}))
 
 			for _, vn := range tt.env.virtualNodes {
 				err := k8sClient.Create(ctx, vn.DeepCopy())
@@ -412,10 +467,7 @@ func Test_enqueueRequestsForPodEvents_Delete(t *testing.T) {
 			appmesh.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
 			queue := workqueue.NewTypedRateLimitingQueue[ctrl.Request](workqueue.DefaultTypedControllerRateLimiter[ctrl.Request]())
-			h := &enqueueRequestsForPodEvents{
-				k8sClient: k8sClient,
-				log:       logr.New(&log.NullLogSink{}),
-			}
+			h := NewEnqueueRequestsForPodEvents(k8sClient, &endpointHealthSource{}, logr.New(&log.NullLogSink{}))
 
 			for _, vn := range tt.env.virtualNodes {
 				err := k8sClient.Create(ctx, vn.DeepCopy())
@@ -0,0 +1,163 @@
+package cloudmap
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// HealthSourceEndpoint follows the readiness of pods decided by the endpoint source.
+	HealthSourceEndpoint = "endpoint"
+	// HealthSourceEnvoy follows the readiness of the Envoy container of pods.
+	HealthSourceEnvoy = "envoy"
+	// HealthSourceReadinessGate follows a dedicated readiness gate of pods.
+	HealthSourceReadinessGate = "readiness-gate"
+	// HealthSourceHTTPProbe probes the app through Envoy over HTTP.
+	HealthSourceHTTPProbe = "http-probe"
+
+	envoyContainerName = "envoy"
+
+	defaultHealthProbePath    = "/"
+	defaultHealthProbeTimeout = 1 * time.Second
+	defaultHealthProbeResync  = 10 * time.Second
+	// the max number of concurrent probes of http-probe health source.
+	defaultHealthProbeConcurrency = 8
+)
+
+// HealthSource decides whether pods resolved as endpoints are registered as healthy cloudMap instances.
+type HealthSource interface {
+	// IsHealthy returns whether pod is healthy.
+	// port is the primary listener port of pod, and endpointReady is the readiness decided by EndpointResolver.
+	IsHealthy(ctx context.Context, pod *corev1.Pod, port int64, endpointReady bool) (bool, error)
+
+	// HealthChanged returns whether health of pod may have changed from oldPod to newPod.
+	HealthChanged(oldPod *corev1.Pod, newPod *corev1.Pod) bool
+
+	// TrackPods records pods of mesh member whose health is evaluated, with port as their primary listener port.
+	// health sources that evaluate health in background use it to know what to evaluate, nil pods stops tracking member.
+	TrackPods(member client.Object, memberKind string, port int64, pods []*corev1.Pod)
+
+	// HealthChangedEvents returns the events of mesh members of memberKind whose pods' health changed without pod changes.
+	// it's nil if health only changes with pods.
+	HealthChangedEvents(memberKind string) <-chan event.GenericEvent
+}
+
+// NewHealthSource constructs HealthSource by cfg.
+func NewHealthSource(cfg Config, log logr.Logger) (HealthSource, error) {
+	switch cfg.HealthSource {
+	case HealthSourceEndpoint:
+		return &endpointHealthSource{}, nil
+	case HealthSourceEnvoy:
+		return &envoyHealthSource{}, nil
+	case HealthSourceReadinessGate:
+		return &readinessGateHealthSource{conditionType: corev1.PodConditionType(cfg.HealthReadinessGate)}, nil
+	case HealthSourceHTTPProbe:
+		return newHTTPProbeHealthSource(cfg, log), nil
+	default:
+		return nil, errors.Errorf("unknown health source: %v", cfg.HealthSource)
+	}
+}
+
+var _ HealthSource = &endpointHealthSource{}
+
+// endpointHealthSource considers pods ready by EndpointResolver as healthy.
+type endpointHealthSource struct{}
+
+func (s *endpointHealthSource) IsHealthy(_ context.Context, _ *corev1.Pod, _ int64, endpointReady bool) (bool, error) {
+	return endpointReady, nil
+}
+
+func (s *endpointHealthSource) HealthChanged(oldPod *corev1.Pod, newPod *corev1.Pod) bool {
+	return ArePodContainersReady(oldPod) != ArePodContainersReady(newPod)
+}
+
+func (s *endpointHealthSource) TrackPods(_ client.Object, _ string, _ int64, _ []*corev1.Pod) {}
+
+func (s *endpointHealthSource) HealthChangedEvents(_ string) <-chan event.GenericEvent {
+	return nil
+}
+
+var _ HealthSource = &envoyHealthSource{}
+
+// envoyHealthSource considers pods with ready Envoy container as healthy.
+type envoyHealthSource struct{}
+
+func (s *envoyHealthSource) IsHealthy(_ context.Context, pod *corev1.Pod, _ int64, _ bool) (bool, error) {
+	return isEnvoyContainerReady(pod), nil
+}
+
+func (s *envoyHealthSource) HealthChanged(oldPod *corev1.Pod, newPod *corev1.Pod) bool {
+	return isEnvoyContainerReady(oldPod) != isEnvoyContainerReady(newPod)
+}
+
+func (s *envoyHealthSource) TrackPods(_ client.Object, _ string, _ int64, _ []*corev1.Pod) {}
+
+func (s *envoyHealthSource) HealthChangedEvents(_ string) <-chan event.GenericEvent {
+	return nil
+}
+
+var _ HealthSource = &readinessGateHealthSource{}
+
+// readinessGateHealthSource considers pods with a true conditionType condition as healthy.
+type readinessGateHealthSource struct {
+	conditionType corev1.PodConditionType
+}
+
+func (s *readinessGateHealthSource) IsHealthy(_ context.Context, pod *corev1.Pod, _ int64, _ bool) (bool, error) {
+	return s.isConditionTrue(pod), nil
+}
+
+func (s *readinessGateHealthSource) HealthChanged(oldPod *corev1.Pod, newPod *corev1.Pod) bool {
+	return s.isConditionTrue(oldPod) != s.isConditionTrue(newPod)
+}
+
+func (s *readinessGateHealthSource) TrackPods(_ client.Object, _ string, _ int64, _ []*corev1.Pod) {}
+
+func (s *readinessGateHealthSource) HealthChangedEvents(_ string) <-chan event.GenericEvent {
+	return nil
+}
+
+func (s *readinessGateHealthSource) isConditionTrue(pod *corev1.Pod) bool {
+	condition := k8s.GetPodCondition(pod, s.conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// evaluatePodsHealth splits readyPods and notReadyPods decided by EndpointResolver into healthy and unhealthy pods by healthSource.
+func evaluatePodsHealth(ctx context.Context, healthSource HealthSource, port int64,
+	readyPods []*corev1.Pod, notReadyPods []*corev1.Pod) ([]*corev1.Pod, []*corev1.Pod, error) {
+	var healthyPods []*corev1.Pod
+	var unhealthyPods []*corev1.Pod
+	for _, podsByReadiness := range []struct {
+		pods          []*corev1.Pod
+		endpointReady bool
+	}{{readyPods, true}, {notReadyPods, false}} {
+		for _, pod := range podsByReadiness.pods {
+			healthy, err := healthSource.IsHealthy(ctx, pod, port, podsByReadiness.endpointReady)
+			if err != nil {
+				return nil, nil, err
+			}
+			if healthy {
+				healthyPods = append(healthyPods, pod)
+			} else {
+				unhealthyPods = append(unhealthyPods, pod)
+			}
+		}
+	}
+	return healthyPods, unhealthyPods, nil
+}
+
+func isEnvoyContainerReady(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == envoyContainerName {
+			return status.Ready
+		}
+	}
+	return false
+}
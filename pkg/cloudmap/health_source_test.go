@@ -0,0 +1,151 @@
+package cloudmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_NewHealthSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    HealthSource
+		wantErr string
+	}{
+		{
+			name: "endpoint",
+			cfg:  Config{HealthSource: HealthSourceEndpoint},
+			want: &endpointHealthSource{},
+		},
+		{
+			name: "envoy",
+			cfg:  Config{HealthSource: HealthSourceEnvoy},
+			want: &envoyHealthSource{},
+		},
+		{
+			name: "readiness-gate",
+			cfg:  Config{HealthSource: HealthSourceReadinessGate, HealthReadinessGate: "example.com/ready"},
+			want: &readinessGateHealthSource{conditionType: "example.com/ready"},
+		},
+		{
+			name:    "unknown",
+			cfg:     Config{HealthSource: "unknown"},
+			wantErr: "unknown health source: unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewHealthSource(tt.cfg, logr.New(&log.NullLogSink{}))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_envoyHealthSource(t *testing.T) {
+	podWithEnvoy := func(envoyReady bool, appReady bool) *corev1.Pod {
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", Ready: appReady},
+					{Name: "envoy", Ready: envoyReady},
+				},
+			},
+		}
+	}
+	s := &envoyHealthSource{}
+
+	healthy, err := s.IsHealthy(context.Background(), podWithEnvoy(true, false), 8080, false)
+	assert.NoError(t, err)
+	assert.True(t, healthy)
+	healthy, err = s.IsHealthy(context.Background(), podWithEnvoy(false, true), 8080, true)
+	assert.NoError(t, err)
+	assert.False(t, healthy)
+	healthy, err = s.IsHealthy(context.Background(), &corev1.Pod{}, 8080, true)
+	assert.NoError(t, err)
+	assert.False(t, healthy)
+
+	assert.True(t, s.HealthChanged(podWithEnvoy(false, true), podWithEnvoy(true, true)))
+	assert.False(t, s.HealthChanged(podWithEnvoy(true, false), podWithEnvoy(true, true)))
+}
+
+func Test_readinessGateHealthSource(t *testing.T) {
+	podWithCondition := func(status corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+					{Type: "example.com/ready", Status: status},
+				},
+			},
+		}
+	}
+	s := &readinessGateHealthSource{conditionType: "example.com/ready"}
+
+	healthy, err := s.IsHealthy(context.Background(), podWithCondition(corev1.ConditionTrue), 8080, false)
+	assert.NoError(t, err)
+	assert.True(t, healthy)
+	healthy, err = s.IsHealthy(context.Background(), podWithCondition(corev1.ConditionFalse), 8080, true)
+	assert.NoError(t, err)
+	assert.False(t, healthy)
+	healthy, err = s.IsHealthy(context.Background(), &corev1.Pod{}, 8080, true)
+	assert.NoError(t, err)
+	assert.False(t, healthy)
+
+	assert.True(t, s.HealthChanged(podWithCondition(corev1.ConditionFalse), podWithCondition(corev1.ConditionTrue)))
+	assert.False(t, s.HealthChanged(podWithCondition(corev1.ConditionTrue), podWithCondition(corev1.ConditionTrue)))
+}
+
+func Test_evaluatePodsHealth(t *testing.T) {
+	podWithEnvoy := func(name string, envoyReady bool) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "envoy", Ready: envoyReady}},
+			},
+		}
+	}
+	podA := podWithEnvoy("pod-a", true)
+	podB := podWithEnvoy("pod-b", false)
+	podC := podWithEnvoy("pod-c", true)
+	podD := podWithEnvoy("pod-d", false)
+
+	tests := []struct {
+		name          string
+		healthSource  HealthSource
+		wantHealthy   []*corev1.Pod
+		wantUnhealthy []*corev1.Pod
+	}{
+		{
+			name:          "endpoint keeps endpoint readiness",
+			healthSource:  &endpointHealthSource{},
+			wantHealthy:   []*corev1.Pod{podA, podB},
+			wantUnhealthy: []*corev1.Pod{podC, podD},
+		},
+		{
+			name:          "envoy follows envoy readiness",
+			healthSource:  &envoyHealthSource{},
+			wantHealthy:   []*corev1.Pod{podA, podC},
+			wantUnhealthy: []*corev1.Pod{podB, podD},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy, unhealthy, err := evaluatePodsHealth(context.Background(), tt.healthSource, 8080,
+				[]*corev1.Pod{podA, podB}, []*corev1.Pod{podC, podD})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHealthy, healthy)
+			assert.Equal(t, tt.wantUnhealthy, unhealthy)
+		})
+	}
+}
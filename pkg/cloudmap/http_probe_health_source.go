@@ -0,0 +1,246 @@
+package cloudmap
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// newHTTPProbeHealthSource constructs new httpProbeHealthSource.
+func newHTTPProbeHealthSource(cfg Config, log logr.Logger) *httpProbeHealthSource {
+	return &httpProbeHealthSource{
+		httpClient: &http.Client{
+			Timeout: cfg.HealthProbeTimeout,
+			// redirects are considered healthy without following them, same as kubelet.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		path:            cfg.HealthProbePath,
+		period:          cfg.HealthProbePeriod,
+		concurrency:     defaultHealthProbeConcurrency,
+		targetsByMember: make(map[probeMemberKey]probeTarget),
+		healthByPod:     make(map[types.UID]bool),
+		eventsByKind: map[string]chan event.GenericEvent{
+			MemberKindVirtualNode:    make(chan event.GenericEvent),
+			MemberKindVirtualGateway: make(chan event.GenericEvent),
+		},
+		log: log,
+	}
+}
+
+var _ HealthSource = &httpProbeHealthSource{}
+var _ MeshStateForgetter = &httpProbeHealthSource{}
+var _ manager.LeaderElectionRunnable = &httpProbeHealthSource{}
+
+// httpProbeHealthSource considers pods responding to HTTP GET on their primary listener port as healthy.
+// requests to the listener port are intercepted by Envoy, so this probes the app through Envoy.
+// pods are probed in background, and mesh members are enqueued when the health of their pods changes,
+// so that reconciles never wait for probes.
+type httpProbeHealthSource struct {
+	httpClient  *http.Client
+	path        string
+	period      time.Duration
+	concurrency int
+
+	mutex sync.Mutex
+	// targetsByMember tracks the pods to probe of mesh members.
+	targetsByMember map[probeMemberKey]probeTarget
+	// healthByPod tracks the last known health of probed pods, indexed by pod UID.
+	healthByPod map[types.UID]bool
+
+	// eventsByKind is the channel of health changed events by mesh member kind.
+	eventsByKind map[string]chan event.GenericEvent
+	log          logr.Logger
+}
+
+type probeMemberKey struct {
+	kind string
+	key  types.NamespacedName
+}
+
+type probeTarget struct {
+	member   client.Object
+	meshName string
+	port     int64
+	pods     []*corev1.Pod
+}
+
+// IsHealthy returns the last probed health of pod, or endpointReady until pod is probed.
+func (s *httpProbeHealthSource) IsHealthy(_ context.Context, pod *corev1.Pod, port int64, endpointReady bool) (bool, error) {
+	if port == 0 {
+		return false, errors.Errorf("pod %v has no listener port to probe", k8s.NamespacedName(pod))
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if healthy, ok := s.healthByPod[pod.UID]; ok {
+		return healthy, nil
+	}
+	return endpointReady, nil
+}
+
+func (s *httpProbeHealthSource) HealthChanged(oldPod *corev1.Pod, newPod *corev1.Pod) bool {
+	return ArePodContainersReady(oldPod) != ArePodContainersReady(newPod)
+}
+
+func (s *httpProbeHealthSource) TrackPods(member client.Object, memberKind string, port int64, pods []*corev1.Pod) {
+	memberKey := probeMemberKey{kind: memberKind, key: k8s.NamespacedName(member)}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(pods) == 0 || port == 0 {
+		delete(s.targetsByMember, memberKey)
+		return
+	}
+	s.targetsByMember[memberKey] = probeTarget{member: member, meshName: meshNameOf(member), port: port, pods: pods}
+}
+
+// ForgetMeshes stops probing pods of mesh members in meshes that are not owned, and forgets their health.
+func (s *httpProbeHealthSource) ForgetMeshes(owned func(meshName string) bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for memberKey, target := range s.targetsByMember {
+		if owned(target.meshName) {
+			continue
+		}
+		delete(s.targetsByMember, memberKey)
+		for _, pod := range target.pods {
+			delete(s.healthByPod, pod.UID)
+		}
+	}
+}
+
+func (s *httpProbeHealthSource) HealthChangedEvents(memberKind string) <-chan event.GenericEvent {
+	return s.eventsByKind[memberKind]
+}
+
+// NeedLeaderElection returns false, since pods are probed for mesh members reconciled by every replica.
+func (s *httpProbeHealthSource) NeedLeaderElection() bool {
+	return false
+}
+
+// Start probes tracked pods every period until ctx is done.
+func (s *httpProbeHealthSource) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		s.probeAll(ctx)
+	}
+}
+
+// probeAll probes the pods of tracked mesh members, and sends events for mesh members whose pods' health changed.
+func (s *httpProbeHealthSource) probeAll(ctx context.Context) {
+	s.mutex.Lock()
+	targets := make([]probeTarget, 0, len(s.targetsByMember))
+	kinds := make([]string, 0, len(s.targetsByMember))
+	for memberKey, target := range s.targetsByMember {
+		targets = append(targets, target)
+		kinds = append(kinds, memberKey.kind)
+	}
+	s.mutex.Unlock()
+
+	type probeEntry struct {
+		pod     *corev1.Pod
+		port    int64
+		healthy bool
+		// known is false if probe is interrupted, such as on shutdown.
+		known bool
+	}
+	var entries []*probeEntry
+	for _, target := range targets {
+		for _, pod := range target.pods {
+			entries = append(entries, &probeEntry{pod: pod, port: target.port})
+		}
+	}
+	entryChan := make(chan *probeEntry)
+	var wg sync.WaitGroup
+	for i := 0; i < s.concurrency && i < len(entries); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range entryChan {
+				entry.healthy, entry.known = s.probe(ctx, entry.pod, entry.port)
+			}
+		}()
+	}
+	for _, entry := range entries {
+		entryChan <- entry
+	}
+	close(entryChan)
+	wg.Wait()
+
+	changedPods := sets.New[types.UID]()
+	trackedPods := sets.New[types.UID]()
+	s.mutex.Lock()
+	for _, entry := range entries {
+		trackedPods.Insert(entry.pod.UID)
+		if !entry.known {
+			continue
+		}
+		if healthy, ok := s.healthByPod[entry.pod.UID]; !ok || healthy != entry.healthy {
+			changedPods.Insert(entry.pod.UID)
+		}
+		s.healthByPod[entry.pod.UID] = entry.healthy
+	}
+	// pods that are no longer tracked are forgotten.
+	for podUID := range s.healthByPod {
+		if !trackedPods.Has(podUID) {
+			delete(s.healthByPod, podUID)
+		}
+	}
+	s.mutex.Unlock()
+
+	for i, target := range targets {
+		for _, pod := range target.pods {
+			if !changedPods.Has(pod.UID) {
+				continue
+			}
+			s.log.V(1).Info("health of pods changed",
+				"kind", kinds[i],
+				"member", k8s.NamespacedName(target.member))
+			select {
+			case s.eventsByKind[kinds[i]] <- event.GenericEvent{Object: target.member}:
+			case <-ctx.Done():
+				return
+			}
+			break
+		}
+	}
+}
+
+// probe sends HTTP GET to pod's port, returns whether pod is healthy, and whether the health is known.
+// probes interrupted by ctx leave the health unknown, instead of considering pod unhealthy.
+func (s *httpProbeHealthSource) probe(ctx context.Context, pod *corev1.Pod, port int64) (bool, bool) {
+	url := "http://" + net.JoinHostPort(pod.Status.PodIP, strconv.FormatInt(port, 10)) + s.path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, false
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, false
+		}
+		// failing to connect is an unhealthy pod rather than an error.
+		return false, true
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusBadRequest, true
+}
@@ -0,0 +1,195 @@
+package cloudmap
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestHTTPProbeServer(t *testing.T) (*httptest.Server, string, int64) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthy":
+			w.WriteHeader(http.StatusOK)
+		case "/redirect":
+			http.Redirect(w, r, "/unhealthy", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	assert.NoError(t, err)
+	port, err := strconv.ParseInt(portStr, 10, 64)
+	assert.NoError(t, err)
+	return server, host, port
+}
+
+func newTestHTTPProbeHealthSource(path string) *httpProbeHealthSource {
+	return newHTTPProbeHealthSource(Config{
+		HealthSource:       HealthSourceHTTPProbe,
+		HealthProbePath:    path,
+		HealthProbeTimeout: time.Second,
+		HealthProbePeriod:  10 * time.Second,
+	}, logr.New(&log.NullLogSink{}))
+}
+
+func Test_httpProbeHealthSource_probe(t *testing.T) {
+	server, host, port := newTestHTTPProbeServer(t)
+	defer server.Close()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod"},
+		Status:     corev1.PodStatus{PodIP: host},
+	}
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		path        string
+		port        int64
+		wantHealthy bool
+		wantKnown   bool
+	}{
+		{
+			name:        "2xx response is healthy",
+			ctx:         context.Background(),
+			path:        "/healthy",
+			port:        port,
+			wantHealthy: true,
+			wantKnown:   true,
+		},
+		{
+			name:        "redirect is healthy",
+			ctx:         context.Background(),
+			path:        "/redirect",
+			port:        port,
+			wantHealthy: true,
+			wantKnown:   true,
+		},
+		{
+			name:        "5xx response is unhealthy",
+			ctx:         context.Background(),
+			path:        "/unhealthy",
+			port:        port,
+			wantHealthy: false,
+			wantKnown:   true,
+		},
+		{
+			name:        "cancelled probe is unknown",
+			ctx:         cancelledCtx,
+			path:        "/healthy",
+			port:        port,
+			wantHealthy: false,
+			wantKnown:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestHTTPProbeHealthSource(tt.path)
+			healthy, known := s.probe(tt.ctx, pod, tt.port)
+			assert.Equal(t, tt.wantHealthy, healthy)
+			assert.Equal(t, tt.wantKnown, known)
+		})
+	}
+}
+
+func Test_httpProbeHealthSource_IsHealthy(t *testing.T) {
+	s := newTestHTTPProbeHealthSource("/healthy")
+	s.healthByPod["uid-probed"] = false
+	probedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "probed", UID: "uid-probed"}}
+	newPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "new", UID: "uid-new"}}
+
+	healthy, err := s.IsHealthy(context.Background(), probedPod, 8080, true)
+	assert.NoError(t, err)
+	assert.False(t, healthy)
+	// pods not probed yet follow endpoint readiness.
+	healthy, err = s.IsHealthy(context.Background(), newPod, 8080, true)
+	assert.NoError(t, err)
+	assert.True(t, healthy)
+	_, err = s.IsHealthy(context.Background(), newPod, 0, true)
+	assert.EqualError(t, err, "pod ns/new has no listener port to probe")
+}
+
+func Test_httpProbeHealthSource_probeAll(t *testing.T) {
+	server, host, port := newTestHTTPProbeServer(t)
+	defer server.Close()
+	vn := &appmesh.VirtualNode{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod", UID: "uid-1"},
+		Status:     corev1.PodStatus{PodIP: host},
+	}
+	s := newTestHTTPProbeHealthSource("/healthy")
+	// probeAll returns the mesh members it sent events for.
+	probeAll := func() []client.Object {
+		done := make(chan struct{})
+		go func() {
+			s.probeAll(context.Background())
+			close(done)
+		}()
+		var members []client.Object
+		for {
+			select {
+			case e := <-s.HealthChangedEvents(MemberKindVirtualNode):
+				members = append(members, e.Object)
+			case <-done:
+				return members
+			}
+		}
+	}
+
+	s.TrackPods(vn, MemberKindVirtualNode, port, []*corev1.Pod{pod})
+	assert.Equal(t, []client.Object{vn}, probeAll())
+	assert.Equal(t, map[types.UID]bool{"uid-1": true}, s.healthByPod)
+
+	// unchanged health doesn't enqueue the member again.
+	assert.Nil(t, probeAll())
+
+	s.path = "/unhealthy"
+	assert.Equal(t, []client.Object{vn}, probeAll())
+	assert.Equal(t, map[types.UID]bool{"uid-1": false}, s.healthByPod)
+
+	// pods of members no longer tracked are forgotten.
+	s.TrackPods(vn, MemberKindVirtualNode, port, nil)
+	assert.Nil(t, probeAll())
+	assert.Empty(t, s.healthByPod)
+	assert.Nil(t, s.HealthChangedEvents("unknown"))
+}
+
+func Test_httpProbeHealthSource_ForgetMeshes(t *testing.T) {
+	vnA := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn-a"},
+		Spec:       appmesh.VirtualNodeSpec{MeshRef: &appmesh.MeshReference{Name: "mesh-a"}},
+	}
+	vgB := &appmesh.VirtualGateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vg-b"},
+		Spec:       appmesh.VirtualGatewaySpec{MeshRef: &appmesh.MeshReference{Name: "mesh-b"}},
+	}
+	podA := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-a", UID: "uid-a"}}
+	podB := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-b", UID: "uid-b"}}
+	s := newTestHTTPProbeHealthSource("/healthy")
+	s.TrackPods(vnA, MemberKindVirtualNode, 8080, []*corev1.Pod{podA})
+	s.TrackPods(vgB, MemberKindVirtualGateway, 8080, []*corev1.Pod{podB})
+	s.healthByPod = map[types.UID]bool{"uid-a": true, "uid-b": true}
+
+	s.ForgetMeshes(func(meshName string) bool { return meshName == "mesh-a" })
+	assert.Equal(t, map[probeMemberKey]probeTarget{
+		{kind: MemberKindVirtualNode, key: types.NamespacedName{Namespace: "ns", Name: "vn-a"}}: {
+			member: vnA, meshName: "mesh-a", port: 8080, pods: []*corev1.Pod{podA},
+		},
+	}, s.targetsByMember)
+	assert.Equal(t, map[types.UID]bool{"uid-a": true}, s.healthByPod)
+}
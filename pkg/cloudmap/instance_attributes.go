@@ -0,0 +1,179 @@
+package cloudmap
+
+import (
+	"path"
+	"strings"
+	"text/template"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	nodeInstanceTypeLabelKey1 = "node.kubernetes.io/instance-type"
+	nodeInstanceTypeLabelKey2 = "beta.kubernetes.io/instance-type"
+
+	// limits of instance attributes enforced by cloudMap RegisterInstance.
+	// See https://docs.aws.amazon.com/cloud-map/latest/api/API_RegisterInstance.html
+	maxInstanceAttributeKeyLength    = 255
+	maxInstanceAttributeValueLength  = 1024
+	maxCustomInstanceAttributes      = 30
+	maxInstanceAttributesTotalLength = 5000
+	// reservedInstanceAttributePrefix is the prefix of attributes reserved by cloudMap, they don't count as custom attributes.
+	reservedInstanceAttributePrefix = "AWS_"
+)
+
+// instanceAttributeTemplateData is the data templated instance attribute values are rendered with.
+type instanceAttributeTemplateData struct {
+	Name               string
+	Namespace          string
+	Labels             map[string]string
+	Annotations        map[string]string
+	PodIP              string
+	NodeName           string
+	ServiceAccountName string
+	Node               nodeTemplateData
+}
+
+// nodeTemplateData is the data of pod's node templated instance attribute values are rendered with.
+type nodeTemplateData struct {
+	Labels           map[string]string
+	Region           string
+	AvailabilityZone string
+	InstanceType     string
+}
+
+func buildInstanceAttributeTemplateData(pod *corev1.Pod, nodeInfoByName map[string]nodeAttributes) instanceAttributeTemplateData {
+	data := instanceAttributeTemplateData{
+		Name:               pod.Name,
+		Namespace:          pod.Namespace,
+		Labels:             pod.Labels,
+		Annotations:        pod.Annotations,
+		PodIP:              pod.Status.PodIP,
+		NodeName:           pod.Spec.NodeName,
+		ServiceAccountName: pod.Spec.ServiceAccountName,
+	}
+	if nodeInfo, ok := nodeInfoByName[pod.Spec.NodeName]; ok {
+		data.Node = nodeTemplateData{
+			Labels:           nodeInfo.labels,
+			Region:           nodeInfo.region,
+			AvailabilityZone: nodeInfo.availabilityZone,
+		}
+		if instanceType, ok := nodeInfo.labels[nodeInstanceTypeLabelKey1]; ok {
+			data.Node.InstanceType = instanceType
+		} else {
+			data.Node.InstanceType = nodeInfo.labels[nodeInstanceTypeLabelKey2]
+		}
+	}
+	return data
+}
+
+// parseInstanceAttributeTemplate parses the Go template of an instance attribute value.
+// missing map keys are rendered as empty string, so that attributes derived from missing labels or annotations are omitted.
+func parseInstanceAttributeTemplate(attr appmesh.AWSCloudMapInstanceAttributeTemplate) (*template.Template, error) {
+	return template.New(attr.Key).Option("missingkey=zero").Parse(attr.Value)
+}
+
+// parseInstanceAttributeTemplates parses the Go templates of all instance attributes, each template is named by attribute key.
+func parseInstanceAttributeTemplates(cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) ([]*template.Template, error) {
+	tmpls := make([]*template.Template, 0, len(cloudMapConfig.InstanceAttributes))
+	for _, attr := range cloudMapConfig.InstanceAttributes {
+		tmpl, err := parseInstanceAttributeTemplate(attr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid template for instance attribute %v", attr.Key)
+		}
+		tmpls = append(tmpls, tmpl)
+	}
+	return tmpls, nil
+}
+
+// renderInstanceAttribute renders the value of a templated instance attribute with parsed template.
+func renderInstanceAttribute(tmpl *template.Template, data instanceAttributeTemplateData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	value := sb.String()
+	if len(value) > maxInstanceAttributeValueLength {
+		return "", errors.Errorf("rendered value exceeds %d characters", maxInstanceAttributeValueLength)
+	}
+	return value, nil
+}
+
+// validateInstanceAttributeLimits checks instance attributes against the limits of cloudMap RegisterInstance.
+func validateInstanceAttributeLimits(attrs instanceAttributes) error {
+	customAttrsCount := 0
+	totalLength := 0
+	for key, value := range attrs {
+		if len(key) > maxInstanceAttributeKeyLength {
+			return errors.Errorf("instance attribute key %v exceeds %d characters", key, maxInstanceAttributeKeyLength)
+		}
+		if len(value) > maxInstanceAttributeValueLength {
+			return errors.Errorf("value of instance attribute %v exceeds %d characters", key, maxInstanceAttributeValueLength)
+		}
+		if !strings.HasPrefix(key, reservedInstanceAttributePrefix) {
+			customAttrsCount++
+		}
+		totalLength += len(key) + len(value)
+	}
+	if customAttrsCount > maxCustomInstanceAttributes {
+		return errors.Errorf("%d custom instance attributes exceed the limit of %d", customAttrsCount, maxCustomInstanceAttributes)
+	}
+	if totalLength > maxInstanceAttributesTotalLength {
+		return errors.Errorf("total length of instance attributes %d exceeds %d characters", totalLength, maxInstanceAttributesTotalLength)
+	}
+	return nil
+}
+
+// shouldExportPodLabel checks whether pod label with key should be exported as instance attribute.
+func shouldExportPodLabel(selection *appmesh.AWSCloudMapPodLabelsSelection, key string) bool {
+	if selection == nil {
+		return true
+	}
+	if len(selection.Include) != 0 && !matchesAnyLabelKeyPattern(selection.Include, key) {
+		return false
+	}
+	return !matchesAnyLabelKeyPattern(selection.Exclude, key)
+}
+
+func matchesAnyLabelKeyPattern(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateInstanceAttributes checks the templated instance attributes and pod label selection of AWSCloudMapServiceDiscovery.
+func ValidateInstanceAttributes(cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) error {
+	if _, err := parseInstanceAttributeTemplates(cloudMapConfig); err != nil {
+		return err
+	}
+	for _, attr := range cloudMapConfig.InstanceAttributes {
+		if len(attr.Key) > maxInstanceAttributeKeyLength {
+			return errors.Errorf("instance attribute key %v exceeds %d characters", attr.Key, maxInstanceAttributeKeyLength)
+		}
+	}
+	for _, attr := range cloudMapConfig.Attributes {
+		if len(attr.Key) > maxInstanceAttributeKeyLength {
+			return errors.Errorf("instance attribute key %v exceeds %d characters", attr.Key, maxInstanceAttributeKeyLength)
+		}
+		if len(attr.Value) > maxInstanceAttributeValueLength {
+			return errors.Errorf("value of instance attribute %v exceeds %d characters", attr.Key, maxInstanceAttributeValueLength)
+		}
+	}
+	if attrsCount := len(cloudMapConfig.Attributes) + len(cloudMapConfig.InstanceAttributes); attrsCount > maxCustomInstanceAttributes {
+		return errors.Errorf("%d instance attributes exceed the limit of %d custom attributes", attrsCount, maxCustomInstanceAttributes)
+	}
+	if cloudMapConfig.PodLabels != nil {
+		patterns := append(append([]string{}, cloudMapConfig.PodLabels.Include...), cloudMapConfig.PodLabels.Exclude...)
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return errors.Wrapf(err, "invalid pod label key pattern %v", pattern)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package cloudmap
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_renderInstanceAttribute(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "pod-ns",
+			Name:        "pod-name",
+			Labels:      map[string]string{"version": "v2"},
+			Annotations: map[string]string{"team": "payments"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:           "node-1",
+			ServiceAccountName: "sa",
+		},
+		Status: corev1.PodStatus{PodIP: "192.168.1.42"},
+	}
+	nodeInfoByName := map[string]nodeAttributes{
+		"node-1": {
+			region:           "us-west-2",
+			availabilityZone: "us-west-2a",
+			labels:           map[string]string{"node.kubernetes.io/instance-type": "m5.large"},
+		},
+	}
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "pod label",
+			value: "{{ .Labels.version }}",
+			want:  "v2",
+		},
+		{
+			name:  "missing pod label",
+			value: "{{ .Labels.track }}",
+			want:  "",
+		},
+		{
+			name:  "pod label with fallback",
+			value: `{{ or .Labels.track "stable" }}`,
+			want:  "stable",
+		},
+		{
+			name:  "pod annotation",
+			value: "{{ .Annotations.team }}",
+			want:  "payments",
+		},
+		{
+			name:  "pod fields",
+			value: "{{ .Namespace }}/{{ .Name }}@{{ .PodIP }} on {{ .NodeName }} as {{ .ServiceAccountName }}",
+			want:  "pod-ns/pod-name@192.168.1.42 on node-1 as sa",
+		},
+		{
+			name:  "node fields",
+			value: "{{ .Node.Region }}/{{ .Node.AvailabilityZone }}/{{ .Node.InstanceType }}",
+			want:  "us-west-2/us-west-2a/m5.large",
+		},
+		{
+			name:  "node label",
+			value: `{{ index .Node.Labels "node.kubernetes.io/instance-type" }}`,
+			want:  "m5.large",
+		},
+		{
+			name:    "invalid template",
+			value:   "{{ .Labels.version",
+			wantErr: true,
+		},
+		{
+			name:    "rendered value too long",
+			value:   `{{ printf "%1025s" .Name }}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attr := appmesh.AWSCloudMapInstanceAttributeTemplate{Key: "attr", Value: tt.value}
+			tmpls, err := parseInstanceAttributeTemplates(&appmesh.AWSCloudMapServiceDiscovery{
+				InstanceAttributes: []appmesh.AWSCloudMapInstanceAttributeTemplate{attr},
+			})
+			var got string
+			if err == nil {
+				got, err = renderInstanceAttribute(tmpls[0], buildInstanceAttributeTemplateData(pod, nodeInfoByName))
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_validateInstanceAttributeLimits(t *testing.T) {
+	manyCustomAttrs := instanceAttributes{"AWS_INSTANCE_IPV4": "192.168.1.42", "AWS_INSTANCE_PORT": "8080"}
+	for i := 0; i < 30; i++ {
+		manyCustomAttrs[strings.Repeat("k", i+1)] = "v"
+	}
+	tooManyCustomAttrs := instanceAttributes{"k8s.io/pod": "pod-name"}
+	for key, value := range manyCustomAttrs {
+		tooManyCustomAttrs[key] = value
+	}
+	tests := []struct {
+		name    string
+		attrs   instanceAttributes
+		wantErr string
+	}{
+		{
+			name:  "30 custom attributes besides reserved ones",
+			attrs: manyCustomAttrs,
+		},
+		{
+			name:    "more than 30 custom attributes",
+			attrs:   tooManyCustomAttrs,
+			wantErr: "31 custom instance attributes exceed the limit of 30",
+		},
+		{
+			name:    "key too long",
+			attrs:   instanceAttributes{strings.Repeat("k", 256): "v"},
+			wantErr: "instance attribute key " + strings.Repeat("k", 256) + " exceeds 255 characters",
+		},
+		{
+			name:    "value too long",
+			attrs:   instanceAttributes{"version": strings.Repeat("v", 1025)},
+			wantErr: "value of instance attribute version exceeds 1024 characters",
+		},
+		{
+			name: "total length too long",
+			attrs: instanceAttributes{
+				"a": strings.Repeat("v", 1024),
+				"b": strings.Repeat("v", 1024),
+				"c": strings.Repeat("v", 1024),
+				"d": strings.Repeat("v", 1024),
+				"e": strings.Repeat("v", 1024),
+			},
+			wantErr: "total length of instance attributes 5125 exceeds 5000 characters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInstanceAttributeLimits(tt.attrs)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_ValidateInstanceAttributes(t *testing.T) {
+	tooManyAttrs := make([]appmesh.AWSCloudMapInstanceAttribute, 0, 31)
+	for i := 0; i < 31; i++ {
+		tooManyAttrs = append(tooManyAttrs, appmesh.AWSCloudMapInstanceAttribute{Key: strings.Repeat("k", i+1), Value: "v"})
+	}
+	tests := []struct {
+		name           string
+		cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery
+		wantErr        string
+	}{
+		{
+			name: "valid attributes",
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				Attributes:         []appmesh.AWSCloudMapInstanceAttribute{{Key: "app", Value: "my-app"}},
+				InstanceAttributes: []appmesh.AWSCloudMapInstanceAttributeTemplate{{Key: "version", Value: "{{ .Labels.version }}"}},
+			},
+		},
+		{
+			name: "invalid template",
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				InstanceAttributes: []appmesh.AWSCloudMapInstanceAttributeTemplate{{Key: "version", Value: "{{ .Labels.version"}},
+			},
+			wantErr: "invalid template for instance attribute version: template: version:1: unclosed action",
+		},
+		{
+			name: "templated key too long",
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				InstanceAttributes: []appmesh.AWSCloudMapInstanceAttributeTemplate{{Key: strings.Repeat("k", 256), Value: "v"}},
+			},
+			wantErr: "instance attribute key " + strings.Repeat("k", 256) + " exceeds 255 characters",
+		},
+		{
+			name: "value too long",
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				Attributes: []appmesh.AWSCloudMapInstanceAttribute{{Key: "app", Value: strings.Repeat("v", 1025)}},
+			},
+			wantErr: "value of instance attribute app exceeds 1024 characters",
+		},
+		{
+			name: "too many attributes",
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				Attributes: tooManyAttrs,
+			},
+			wantErr: "31 instance attributes exceed the limit of 30 custom attributes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateInstanceAttributes(tt.cloudMapConfig)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_shouldExportPodLabel(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection *appmesh.AWSCloudMapPodLabelsSelection
+		key       string
+		want      bool
+	}{
+		{
+			name:      "all labels exported without selection",
+			selection: nil,
+			key:       "pod-template-hash",
+			want:      true,
+		},
+		{
+			name:      "label included",
+			selection: &appmesh.AWSCloudMapPodLabelsSelection{Include: []string{"app.kubernetes.io/*", "version"}},
+			key:       "app.kubernetes.io/name",
+			want:      true,
+		},
+		{
+			name:      "label not included",
+			selection: &appmesh.AWSCloudMapPodLabelsSelection{Include: []string{"app.kubernetes.io/*", "version"}},
+			key:       "pod-template-hash",
+			want:      false,
+		},
+		{
+			name:      "label excluded",
+			selection: &appmesh.AWSCloudMapPodLabelsSelection{Exclude: []string{"pod-template-hash"}},
+			key:       "pod-template-hash",
+			want:      false,
+		},
+		{
+			name: "exclude takes precedence over include",
+			selection: &appmesh.AWSCloudMapPodLabelsSelection{
+				Include: []string{"app.kubernetes.io/*"},
+				Exclude: []string{"app.kubernetes.io/instance"},
+			},
+			key:  "app.kubernetes.io/instance",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shouldExportPodLabel(tt.selection, tt.key)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_defaultInstancesReconciler_buildInstanceAttributes_templated(t *testing.T) {
+	ms := &appmesh.Mesh{Spec: appmesh.MeshSpec{AWSName: aws.String("my-mesh")}}
+	vn := &appmesh.VirtualNode{
+		Spec: appmesh.VirtualNodeSpec{
+			AWSName: aws.String("my-vn"),
+			ServiceDiscovery: &appmesh.ServiceDiscovery{
+				AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+					Attributes: []appmesh.AWSCloudMapInstanceAttribute{{Key: "app", Value: "my-app"}},
+					InstanceAttributes: []appmesh.AWSCloudMapInstanceAttributeTemplate{
+						{Key: "version", Value: "{{ .Labels.version }}"},
+						{Key: "track", Value: "{{ .Labels.track }}"},
+						{Key: "k8s.io/pod", Value: "overridden"},
+					},
+					PodLabels: &appmesh.AWSCloudMapPodLabelsSelection{Include: []string{"app"}},
+				},
+			},
+			Listeners: []appmesh.Listener{{PortMapping: appmesh.PortMapping{Port: 8080}}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "pod-ns",
+			Name:      "pod-name",
+			Labels:    map[string]string{"app": "my-app", "version": "v2", "pod-template-hash": "5d8f7"},
+		},
+		Status: corev1.PodStatus{PodIP: "192.168.1.42"},
+	}
+	r := &defaultInstancesReconciler{}
+	got := r.buildInstanceAttributes(context.Background(), ms, newVirtualNodeMeshMember(vn), 8080, pod, nil)
+	assert.Equal(t, instanceAttributes{
+		"app":                         "my-app",
+		"version":                     "v2",
+		"AWS_INSTANCE_IPV4":           "192.168.1.42",
+		"AWS_INSTANCE_PORT":           "8080",
+		"k8s.io/pod":                  "pod-name",
+		"k8s.io/namespace":            "pod-ns",
+		"appmesh.k8s.aws/mesh":        "my-mesh",
+		"appmesh.k8s.aws/virtualNode": "my-vn",
+	}, got)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/retry"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/servicediscovery"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/cache"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sync"
@@ -33,6 +36,8 @@ type instancesCache interface {
 	// DeregisterInstance deregister an instance from cloudMap service.
 	// it blocks until deregisterInstance operation succeeds or fails.
 	DeregisterInstance(ctx context.Context, serviceID string, instanceID string) error
+	// Evict evicts the cached instances of cloudMap service, so that they're listed again from cloudMap.
+	Evict(serviceID string)
 }
 
 // newDefaultInstancesCache constructs defaultInstancesCache
@@ -96,7 +101,18 @@ func (c *defaultInstancesCache) ListInstances(ctx context.Context, serviceID str
 	return instanceAttrsByIDClone, nil
 }
 
-func (c *defaultInstancesCache) RegisterInstance(ctx context.Context, serviceID string, instanceID string, attrs instanceAttributes) error {
+func (c *defaultInstancesCache) Evict(serviceID string) {
+	c.instancesAttrsCacheMutex.Lock()
+	defer c.instancesAttrsCacheMutex.Unlock()
+	c.instancesAttrsCache.Remove(serviceID)
+}
+
+func (c *defaultInstancesCache) RegisterInstance(ctx context.Context, serviceID string, instanceID string, attrs instanceAttributes) (err error) {
+	ctx, span := startInstanceOperationSpan(ctx, "CloudMap register instance", serviceID, instanceID)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 	optResp, err := c.cloudMapSDK.RegisterInstanceWithContext(ctx, &servicediscovery.RegisterInstanceInput{
 		ServiceId:  aws.String(serviceID),
 		InstanceId: aws.String(instanceID),
@@ -124,7 +140,12 @@ func (c *defaultInstancesCache) RegisterInstance(ctx context.Context, serviceID
 	}, ctx.Done())
 }
 
-func (c *defaultInstancesCache) DeregisterInstance(ctx context.Context, serviceID string, instanceID string) error {
+func (c *defaultInstancesCache) DeregisterInstance(ctx context.Context, serviceID string, instanceID string) (err error) {
+	ctx, span := startInstanceOperationSpan(ctx, "CloudMap deregister instance", serviceID, instanceID)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 	optResp, err := c.cloudMapSDK.DeregisterInstanceWithContext(ctx, &servicediscovery.DeregisterInstanceInput{
 		ServiceId:  aws.String(serviceID),
 		InstanceId: aws.String(instanceID),
@@ -152,6 +173,14 @@ func (c *defaultInstancesCache) DeregisterInstance(ctx context.Context, serviceI
 	}, ctx.Done())
 }
 
+// startInstanceOperationSpan starts span of instance operation named name, which includes polling until the operation completes.
+func startInstanceOperationSpan(ctx context.Context, name string, serviceID string, instanceID string) (context.Context, trace.Span) {
+	return tracing.StartSpan(ctx, name, trace.SpanKindInternal,
+		attribute.String("cloudmap.service_id", serviceID),
+		attribute.String("cloudmap.instance_id", instanceID),
+	)
+}
+
 func (c *defaultInstancesCache) listInstancesFromAWS(ctx context.Context, serviceID string) (map[string]instanceAttributes, error) {
 	input := &servicediscovery.ListInstancesInput{
 		ServiceId: aws.String(serviceID),
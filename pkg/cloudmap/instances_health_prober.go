@@ -24,6 +24,8 @@ const (
 type instancesHealthProber interface {
 	// Submit will submit probe task for serviceID and instances.
 	Submit(ctx context.Context, service serviceSummary, subset serviceSubset, instanceInfoByID map[string]instanceInfo, timeout time.Duration) error
+	// Forget stops probing instances of service subsets.
+	Forget(serviceSubsetIDs []serviceSubsetID)
 }
 
 // newDefaultInstancesHealthProber constructs new instancesHealthProber
@@ -34,6 +36,7 @@ func newDefaultInstancesHealthProber(ctx context.Context, k8sClient client.Clien
 		probeRequestChan:   make(chan probeRequest),
 		probePeriod:        defaultHealthProbePeriod,
 		transitionDuration: defaultHealthTransitionDuration,
+		stopChan:           ctx.Done(),
 		log:                log,
 	}
 	go prober.probeLoop(ctx)
@@ -51,6 +54,8 @@ type defaultInstancesHealthProber struct {
 	probePeriod time.Duration
 	// how long an instance should stay in specific healthyStatus before we update pod's condition.
 	transitionDuration time.Duration
+	// closed once probeLoop quits.
+	stopChan <-chan struct{}
 
 	log logr.Logger
 }
@@ -106,6 +111,19 @@ func (p *defaultInstancesHealthProber) Submit(ctx context.Context, service servi
 	}
 }
 
+func (p *defaultInstancesHealthProber) Forget(serviceSubsetIDs []serviceSubsetID) {
+	// probeLoop may be busy probing, so forget in background instead of blocking the caller.
+	go func() {
+		for _, serviceSubsetID := range serviceSubsetIDs {
+			select {
+			case <-p.stopChan:
+				return
+			case p.probeRequestChan <- probeRequest{serviceSubsetID: serviceSubsetID}:
+			}
+		}
+	}()
+}
+
 func (p *defaultInstancesHealthProber) probeLoop(ctx context.Context) {
 	probeConfigByServiceSubset := make(map[serviceSubsetID]probeConfig)
 	for {
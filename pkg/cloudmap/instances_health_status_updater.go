@@ -0,0 +1,216 @@
+package cloudmap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// how long a reported health status is trusted before it's reported again.
+	defaultHealthStatusResyncPeriod = 10 * time.Minute
+
+	metricSubsystemCloudMap                         = "cloudmap"
+	metricInstanceHealthStatusUpdateDurationSeconds = "instance_health_status_update_duration_seconds"
+	metricInstanceHealthStatusUpdatesPending        = "instance_health_status_updates_pending"
+
+	labelHealthStatus = "status"
+)
+
+// instancesHealthStatusUpdater updates the custom health status of instances.
+type instancesHealthStatusUpdater interface {
+	// Update reports custom health status of instances of serviceSubset.
+	// only health status that changed since last report are sent to cloudMap.
+	Update(ctx context.Context, service serviceSummary, subset serviceSubset, healthyInstanceIDs sets.String, unhealthyInstanceIDs sets.String) error
+	// Forget forgets reported health status of instances of service subsets.
+	Forget(serviceSubsetIDs []serviceSubsetID)
+}
+
+// newDefaultInstancesHealthStatusUpdater constructs new instancesHealthStatusUpdater
+func newDefaultInstancesHealthStatusUpdater(cloudMapSDK services.CloudMap, concurrency int, registerer prometheus.Registerer, log logr.Logger) (*defaultInstancesHealthStatusUpdater, error) {
+	updateDurationSeconds := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metricSubsystemCloudMap,
+		Name:      metricInstanceHealthStatusUpdateDurationSeconds,
+		Help:      "Latency of updating custom health status of cloudMap instances",
+	}, []string{labelHealthStatus})
+	updatesPending := prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: metricSubsystemCloudMap,
+		Name:      metricInstanceHealthStatusUpdatesPending,
+		Help:      "Number of custom health status updates of cloudMap instances waiting to be sent",
+	})
+	if err := registerer.Register(updateDurationSeconds); err != nil {
+		return nil, err
+	}
+	if err := registerer.Register(updatesPending); err != nil {
+		return nil, err
+	}
+	return &defaultInstancesHealthStatusUpdater{
+		cloudMapSDK:            cloudMapSDK,
+		concurrency:            concurrency,
+		resyncPeriod:           defaultHealthStatusResyncPeriod,
+		reportedStatusBySubset: make(map[serviceSubsetID]map[string]reportedHealthStatus),
+		updateDurationSeconds:  updateDurationSeconds,
+		updatesPending:         updatesPending,
+		log:                    log,
+	}, nil
+}
+
+var _ instancesHealthStatusUpdater = &defaultInstancesHealthStatusUpdater{}
+
+type defaultInstancesHealthStatusUpdater struct {
+	cloudMapSDK services.CloudMap
+	// max number of concurrent health status updates, requests are further throttled by the cloudMap SDK.
+	concurrency int
+	// how long a reported health status is trusted before it's reported again.
+	resyncPeriod time.Duration
+
+	// reportedStatusBySubset tracks last reported health status of instances, indexed by serviceSubset and instanceID.
+	reportedStatusBySubset map[serviceSubsetID]map[string]reportedHealthStatus
+	mutex                  sync.Mutex
+
+	updateDurationSeconds *prometheus.HistogramVec
+	updatesPending        prometheus.Gauge
+
+	log logr.Logger
+}
+
+type reportedHealthStatus struct {
+	status     string
+	reportedAt time.Time
+}
+
+type healthStatusUpdate struct {
+	instanceID string
+	status     string
+}
+
+func (u *defaultInstancesHealthStatusUpdater) Update(ctx context.Context, service serviceSummary, subset serviceSubset, healthyInstanceIDs sets.String, unhealthyInstanceIDs sets.String) error {
+	subsetID := serviceSubsetID{serviceID: service.serviceID, subsetID: subset.SubsetID()}
+	updates := u.computeHealthStatusUpdates(subsetID, healthyInstanceIDs, unhealthyInstanceIDs, time.Now())
+	if len(updates) == 0 {
+		return nil
+	}
+	u.log.V(1).Info("CloudMap: Update Instances Health Status",
+		"serviceID", service.serviceID,
+		"updates", len(updates))
+
+	u.updatesPending.Add(float64(len(updates)))
+	updateChan := make(chan healthStatusUpdate)
+	errChan := make(chan error, len(updates))
+	var wg sync.WaitGroup
+	for i := 0; i < u.concurrency && i < len(updates); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for update := range updateChan {
+				err := u.updateHealthStatus(ctx, service.serviceID, update)
+				u.updatesPending.Dec()
+				if err != nil {
+					u.forgetHealthStatus(subsetID, update.instanceID)
+					errChan <- err
+					continue
+				}
+				u.recordHealthStatus(subsetID, update, time.Now())
+			}
+		}()
+	}
+	for _, update := range updates {
+		updateChan <- update
+	}
+	close(updateChan)
+	wg.Wait()
+	close(errChan)
+	// only first error is returned, failed updates will be retried by next reconcile.
+	for err := range errChan {
+		return err
+	}
+	return nil
+}
+
+// computeHealthStatusUpdates computes the health status updates needed for instances of serviceSubset,
+// and forgets instances that are no longer part of the serviceSubset.
+func (u *defaultInstancesHealthStatusUpdater) computeHealthStatusUpdates(subsetID serviceSubsetID, healthyInstanceIDs sets.String, unhealthyInstanceIDs sets.String, now time.Time) []healthStatusUpdate {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	reportedStatusByID := u.reportedStatusBySubset[subsetID]
+	var updates []healthStatusUpdate
+	for _, instanceID := range healthyInstanceIDs.List() {
+		if u.shouldUpdateHealthStatus(reportedStatusByID, instanceID, servicediscovery.CustomHealthStatusHealthy, now) {
+			updates = append(updates, healthStatusUpdate{instanceID: instanceID, status: servicediscovery.CustomHealthStatusHealthy})
+		}
+	}
+	for _, instanceID := range unhealthyInstanceIDs.List() {
+		if u.shouldUpdateHealthStatus(reportedStatusByID, instanceID, servicediscovery.CustomHealthStatusUnhealthy, now) {
+			updates = append(updates, healthStatusUpdate{instanceID: instanceID, status: servicediscovery.CustomHealthStatusUnhealthy})
+		}
+	}
+
+	instanceIDs := healthyInstanceIDs.Union(unhealthyInstanceIDs)
+	if instanceIDs.Len() == 0 {
+		delete(u.reportedStatusBySubset, subsetID)
+		return updates
+	}
+	for instanceID := range reportedStatusByID {
+		if !instanceIDs.Has(instanceID) {
+			delete(reportedStatusByID, instanceID)
+		}
+	}
+	return updates
+}
+
+func (u *defaultInstancesHealthStatusUpdater) shouldUpdateHealthStatus(reportedStatusByID map[string]reportedHealthStatus, instanceID string, status string, now time.Time) bool {
+	reported, ok := reportedStatusByID[instanceID]
+	if !ok || reported.status != status {
+		return true
+	}
+	return now.Sub(reported.reportedAt) >= u.resyncPeriod
+}
+
+func (u *defaultInstancesHealthStatusUpdater) updateHealthStatus(ctx context.Context, serviceID string, update healthStatusUpdate) error {
+	startTime := time.Now()
+	defer func() {
+		u.updateDurationSeconds.WithLabelValues(update.status).Observe(time.Since(startTime).Seconds())
+	}()
+	_, err := u.cloudMapSDK.UpdateInstanceCustomHealthStatusWithContext(ctx, &servicediscovery.UpdateInstanceCustomHealthStatusInput{
+		ServiceId:  aws.String(serviceID),
+		InstanceId: aws.String(update.instanceID),
+		Status:     aws.String(update.status),
+	})
+	return err
+}
+
+func (u *defaultInstancesHealthStatusUpdater) recordHealthStatus(subsetID serviceSubsetID, update healthStatusUpdate, reportedAt time.Time) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	reportedStatusByID, ok := u.reportedStatusBySubset[subsetID]
+	if !ok {
+		reportedStatusByID = make(map[string]reportedHealthStatus)
+		u.reportedStatusBySubset[subsetID] = reportedStatusByID
+	}
+	reportedStatusByID[update.instanceID] = reportedHealthStatus{status: update.status, reportedAt: reportedAt}
+}
+
+func (u *defaultInstancesHealthStatusUpdater) Forget(serviceSubsetIDs []serviceSubsetID) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	for _, subsetID := range serviceSubsetIDs {
+		delete(u.reportedStatusBySubset, subsetID)
+	}
+}
+
+func (u *defaultInstancesHealthStatusUpdater) forgetHealthStatus(subsetID serviceSubsetID, instanceID string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	delete(u.reportedStatusBySubset[subsetID], instanceID)
+}
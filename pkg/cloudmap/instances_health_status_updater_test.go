@@ -0,0 +1,130 @@
+package cloudmap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_defaultInstancesHealthStatusUpdater_computeHealthStatusUpdates(t *testing.T) {
+	now := time.Now()
+	subsetID := serviceSubsetID{serviceID: "srv-1", subsetID: "mesh/vn"}
+	tests := []struct {
+		name                   string
+		reportedStatusBySubset map[serviceSubsetID]map[string]reportedHealthStatus
+		healthyInstanceIDs     sets.String
+		unhealthyInstanceIDs   sets.String
+		wantUpdates            []healthStatusUpdate
+		wantReportedStatusByID map[string]reportedHealthStatus
+	}{
+		{
+			name:                   "instances never reported",
+			reportedStatusBySubset: map[serviceSubsetID]map[string]reportedHealthStatus{},
+			healthyInstanceIDs:     sets.NewString("uid-1", "uid-2"),
+			unhealthyInstanceIDs:   sets.NewString("uid-3"),
+			wantUpdates: []healthStatusUpdate{
+				{instanceID: "uid-1", status: servicediscovery.CustomHealthStatusHealthy},
+				{instanceID: "uid-2", status: servicediscovery.CustomHealthStatusHealthy},
+				{instanceID: "uid-3", status: servicediscovery.CustomHealthStatusUnhealthy},
+			},
+			wantReportedStatusByID: nil,
+		},
+		{
+			name: "only changed or stale health status are updated, and removed instances are forgotten",
+			reportedStatusBySubset: map[serviceSubsetID]map[string]reportedHealthStatus{
+				subsetID: {
+					"uid-1": {status: servicediscovery.CustomHealthStatusHealthy, reportedAt: now.Add(-time.Minute)},
+					"uid-2": {status: servicediscovery.CustomHealthStatusHealthy, reportedAt: now.Add(-time.Minute)},
+					"uid-3": {status: servicediscovery.CustomHealthStatusUnhealthy, reportedAt: now.Add(-time.Hour)},
+					"uid-4": {status: servicediscovery.CustomHealthStatusHealthy, reportedAt: now.Add(-time.Minute)},
+				},
+			},
+			healthyInstanceIDs:   sets.NewString("uid-1"),
+			unhealthyInstanceIDs: sets.NewString("uid-2", "uid-3"),
+			wantUpdates: []healthStatusUpdate{
+				{instanceID: "uid-2", status: servicediscovery.CustomHealthStatusUnhealthy},
+				{instanceID: "uid-3", status: servicediscovery.CustomHealthStatusUnhealthy},
+			},
+			wantReportedStatusByID: map[string]reportedHealthStatus{
+				"uid-1": {status: servicediscovery.CustomHealthStatusHealthy, reportedAt: now.Add(-time.Minute)},
+				"uid-2": {status: servicediscovery.CustomHealthStatusHealthy, reportedAt: now.Add(-time.Minute)},
+				"uid-3": {status: servicediscovery.CustomHealthStatusUnhealthy, reportedAt: now.Add(-time.Hour)},
+			},
+		},
+		{
+			name: "serviceSubset without instances is forgotten",
+			reportedStatusBySubset: map[serviceSubsetID]map[string]reportedHealthStatus{
+				subsetID: {
+					"uid-1": {status: servicediscovery.CustomHealthStatusHealthy, reportedAt: now},
+				},
+			},
+			healthyInstanceIDs:     sets.NewString(),
+			unhealthyInstanceIDs:   sets.NewString(),
+			wantUpdates:            nil,
+			wantReportedStatusByID: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &defaultInstancesHealthStatusUpdater{
+				resyncPeriod:           defaultHealthStatusResyncPeriod,
+				reportedStatusBySubset: tt.reportedStatusBySubset,
+			}
+			gotUpdates := u.computeHealthStatusUpdates(subsetID, tt.healthyInstanceIDs, tt.unhealthyInstanceIDs, now)
+			assert.Equal(t, tt.wantUpdates, gotUpdates)
+			assert.Equal(t, tt.wantReportedStatusByID, u.reportedStatusBySubset[subsetID])
+		})
+	}
+}
+
+func Test_defaultInstancesHealthStatusUpdater_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	cloudMapSDK := services.NewMockCloudMap(ctrl)
+	u, err := newDefaultInstancesHealthStatusUpdater(cloudMapSDK, 2, prometheus.NewRegistry(), logr.New(&log.NullLogSink{}))
+	assert.NoError(t, err)
+
+	service := serviceSummary{serviceID: "srv-1"}
+	subset := &meshMemberServiceSubset{
+		ms:     &appmesh.Mesh{Spec: appmesh.MeshSpec{AWSName: aws.String("mesh")}},
+		member: newVirtualNodeMeshMember(&appmesh.VirtualNode{Spec: appmesh.VirtualNodeSpec{AWSName: aws.String("vn")}}),
+	}
+	expectUpdate := func(instanceID string, status string, err error) {
+		cloudMapSDK.EXPECT().UpdateInstanceCustomHealthStatusWithContext(gomock.Any(), &servicediscovery.UpdateInstanceCustomHealthStatusInput{
+			ServiceId:  aws.String("srv-1"),
+			InstanceId: aws.String(instanceID),
+			Status:     aws.String(status),
+		}).Return(&servicediscovery.UpdateInstanceCustomHealthStatusOutput{}, err)
+	}
+
+	// first update reports every instance
+	expectUpdate("uid-1", servicediscovery.CustomHealthStatusHealthy, nil)
+	expectUpdate("uid-2", servicediscovery.CustomHealthStatusHealthy, nil)
+	expectUpdate("uid-3", servicediscovery.CustomHealthStatusUnhealthy, errors.New("oops"))
+	err = u.Update(ctx, service, subset, sets.NewString("uid-1", "uid-2"), sets.NewString("uid-3"))
+	assert.EqualError(t, err, "oops")
+
+	// second update only reports changed and failed instances
+	expectUpdate("uid-2", servicediscovery.CustomHealthStatusUnhealthy, nil)
+	expectUpdate("uid-3", servicediscovery.CustomHealthStatusUnhealthy, nil)
+	err = u.Update(ctx, service, subset, sets.NewString("uid-1"), sets.NewString("uid-2", "uid-3"))
+	assert.NoError(t, err)
+
+	// third update reports nothing
+	err = u.Update(ctx, service, subset, sets.NewString("uid-1"), sets.NewString("uid-2", "uid-3"))
+	assert.NoError(t, err)
+}
@@ -4,26 +4,48 @@ import (
 	"context"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sync"
 )
 
 const (
 	defaultInstancesReconcileReactorRequestChanBuffer = 10
+
+	metricInstances = "instances"
+
+	labelService       = "service"
+	labelSubset        = "subset"
+	labelInstanceState = "state"
+
+	instanceStateDesired    = "desired"
+	instanceStateRegistered = "registered"
 )
 
 // instancesReconcileReactor manages the asynchronous execution for instances reconcile.
 type instancesReconcileReactor interface {
 	// Submit submits a instances reconcile request, it will asynchronously drive cloudMap service's subset to match desiredState.
 	Submit(ctx context.Context, service serviceSummary, subset serviceSubset, readyInstanceInfoByID map[string]instanceInfo, unreadyInstanceInfoByID map[string]instanceInfo) <-chan error
+	// Forget evicts cached instances and metrics of service subsets.
+	Forget(serviceSubsetIDs []serviceSubsetID)
 }
 
 // newDefaultInstancesReconcileReactor constructs new defaultInstancesReconcileReactor
-func newDefaultInstancesReconcileReactor(ctx context.Context, k8sClient client.Client, cloudMapSDK services.CloudMap, log logr.Logger) *defaultInstancesReconcileReactor {
+func newDefaultInstancesReconcileReactor(ctx context.Context, k8sClient client.Client, cloudMapSDK services.CloudMap, registerer prometheus.Registerer, log logr.Logger) (*defaultInstancesReconcileReactor, error) {
+	instances := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metricSubsystemCloudMap,
+		Name:      metricInstances,
+		Help:      "Number of desired and registered instances of cloudMap service subsets",
+	}, []string{labelService, labelSubset, labelInstanceState})
+	if err := registerer.Register(instances); err != nil {
+		return nil, err
+	}
 	instancesCache := newDefaultInstancesCache(cloudMapSDK)
 	reactor := &defaultInstancesReconcileReactor{
 		cloudMapSDK:                       cloudMapSDK,
 		instancesCache:                    instancesCache,
+		instances:                         instances,
 		reconcileRequestChan:              make(chan instancesReconcileRequest, defaultInstancesReconcileReactorRequestChanBuffer),
 		reconcileTaskByServiceSubset:      make(map[serviceSubsetID]*instancesReconcileTask),
 		reconcileTaskByServiceSubsetMutex: sync.RWMutex{},
@@ -31,7 +53,7 @@ func newDefaultInstancesReconcileReactor(ctx context.Context, k8sClient client.C
 	}
 
 	go reactor.reactorLoop(ctx)
-	return reactor
+	return reactor, nil
 }
 
 var _ instancesReconcileReactor = &defaultInstancesReconcileReactor{}
@@ -39,6 +61,8 @@ var _ instancesReconcileReactor = &defaultInstancesReconcileReactor{}
 type defaultInstancesReconcileReactor struct {
 	cloudMapSDK    services.CloudMap
 	instancesCache instancesCache
+	// number of desired and registered instances by service subset.
+	instances *prometheus.GaugeVec
 
 	// channel to receive reconcile requests
 	reconcileRequestChan chan instancesReconcileRequest
@@ -57,6 +81,8 @@ type instancesReconcileRequest struct {
 	readyInstanceInfoByID   map[string]instanceInfo
 	unreadyInstanceInfoByID map[string]instanceInfo
 	resultChan              chan<- error
+	// span of the submitter, it isn't recording if the submitter isn't traced.
+	span trace.Span
 }
 
 func (r *defaultInstancesReconcileReactor) Submit(ctx context.Context, service serviceSummary, subset serviceSubset, readyInstanceInfoByID map[string]instanceInfo, unreadyInstanceInfoByID map[string]instanceInfo) <-chan error {
@@ -67,6 +93,7 @@ func (r *defaultInstancesReconcileReactor) Submit(ctx context.Context, service s
 		readyInstanceInfoByID:   readyInstanceInfoByID,
 		unreadyInstanceInfoByID: unreadyInstanceInfoByID,
 		resultChan:              resultChan,
+		span:                    trace.SpanFromContext(ctx),
 	}
 
 	select {
@@ -79,6 +106,16 @@ func (r *defaultInstancesReconcileReactor) Submit(ctx context.Context, service s
 	return resultChan
 }
 
+func (r *defaultInstancesReconcileReactor) Forget(serviceSubsetIDs []serviceSubsetID) {
+	for _, serviceSubsetID := range serviceSubsetIDs {
+		r.instancesCache.Evict(serviceSubsetID.serviceID)
+		r.instances.DeletePartialMatch(prometheus.Labels{
+			labelService: serviceSubsetID.serviceID,
+			labelSubset:  serviceSubsetID.subsetID,
+		})
+	}
+}
+
 func (r *defaultInstancesReconcileReactor) reactorLoop(ctx context.Context) {
 	for {
 		select {
@@ -111,7 +148,7 @@ func (r *defaultInstancesReconcileReactor) dispatch(ctx context.Context, request
 
 // dispatch new cloudMap service subset reconcile task to run on a new task.
 func (r *defaultInstancesReconcileReactor) dispatchToNewTask(ctx context.Context, serviceSubsetID serviceSubsetID, request instancesReconcileRequest) {
-	reconcileTask := newInstancesReconcileTask(r.cloudMapSDK, r.instancesCache, r.log, make(chan struct{}))
+	reconcileTask := newInstancesReconcileTask(r.cloudMapSDK, r.instancesCache, r.instances, r.log, make(chan struct{}))
 	r.reconcileTaskByServiceSubsetMutex.Lock()
 	r.reconcileTaskByServiceSubset[serviceSubsetID] = reconcileTask
 	r.reconcileTaskByServiceSubsetMutex.Unlock()
@@ -3,11 +3,14 @@ package cloudmap
 import (
 	"context"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-sdk-go/service/servicediscovery"
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -16,10 +19,11 @@ const (
 )
 
 // newInstancesReconcileTask constructs new instancesReconcileTask for specific subset of cloudMap service.
-func newInstancesReconcileTask(cloudMapSDK services.CloudMap, instancesCache instancesCache, log logr.Logger, done chan struct{}) *instancesReconcileTask {
+func newInstancesReconcileTask(cloudMapSDK services.CloudMap, instancesCache instancesCache, instances *prometheus.GaugeVec, log logr.Logger, done chan struct{}) *instancesReconcileTask {
 	return &instancesReconcileTask{
 		cloudMapSDK:    cloudMapSDK,
 		instancesCache: instancesCache,
+		instances:      instances,
 		done:           done,
 
 		instancesReconcileRequestChan:   make(chan instancesReconcileRequest),
@@ -34,7 +38,9 @@ func newInstancesReconcileTask(cloudMapSDK services.CloudMap, instancesCache ins
 type instancesReconcileTask struct {
 	cloudMapSDK    services.CloudMap
 	instancesCache instancesCache
-	done           chan struct{}
+	// number of desired and registered instances by service subset, nil if not reported.
+	instances *prometheus.GaugeVec
+	done      chan struct{}
 
 	instancesReconcileRequestChan chan instancesReconcileRequest
 	// instances that have on-going operation, we'll skip these instances.
@@ -56,7 +62,8 @@ type instanceOperationResult struct {
 func (t *instancesReconcileTask) Run(ctx context.Context) {
 	request := <-t.instancesReconcileRequestChan
 	for {
-		err := t.reconcile(ctx, request.service, request.subset, request.readyInstanceInfoByID, request.unreadyInstanceInfoByID)
+		// instances are reconciled within the trace of the submitter, while tasks outlive submitters' contexts.
+		err := t.reconcile(trace.ContextWithSpan(ctx, request.span), request.service, request.subset, request.readyInstanceInfoByID, request.unreadyInstanceInfoByID)
 		if err != nil {
 			request.resultChan <- err
 			close(request.resultChan)
@@ -95,10 +102,11 @@ func (t *instancesReconcileTask) reconcile(ctx context.Context, service serviceS
 	if err != nil {
 		return err
 	}
+	t.reportInstances(service, subset, len(desiredReadyInstanceInfoByID)+len(desiredNotReadyInstanceInfoByID), len(existingInstanceAttrsByID))
 
 	instancesToCreateOrUpdate, instancesToDelete := t.matchDesiredInstancesAgainstExistingInstances(desiredReadyInstanceInfoByID, desiredNotReadyInstanceInfoByID, existingInstanceAttrsByID)
 
-	t.log.V(1).Info("CloudMap: Register Instances", "InstanceToCreateOrUpdate", instancesToCreateOrUpdate)
+	tracing.LoggerFromContext(ctx, t.log).V(1).Info("CloudMap: Register Instances", "InstanceToCreateOrUpdate", instancesToCreateOrUpdate)
 
 	for instanceID, info := range instancesToCreateOrUpdate {
 		if t.instancesWithOngoingOperation.Has(instanceID) {
@@ -114,7 +122,7 @@ func (t *instancesReconcileTask) reconcile(ctx context.Context, service serviceS
 		}(instanceID, info)
 	}
 
-	t.log.V(1).Info("CloudMap: Deregister Instances", "instancesToDelete", instancesToDelete)
+	tracing.LoggerFromContext(ctx, t.log).V(1).Info("CloudMap: Deregister Instances", "instancesToDelete", instancesToDelete)
 
 	for _, instanceID := range instancesToDelete {
 		if t.instancesWithOngoingOperation.Has(instanceID) {
@@ -132,6 +140,24 @@ func (t *instancesReconcileTask) reconcile(ctx context.Context, service serviceS
 	return nil
 }
 
+// reportInstances reports the number of desired and registered instances of subset of cloudMap service.
+// subsets without any instance are no longer reported.
+func (t *instancesReconcileTask) reportInstances(service serviceSummary, subset serviceSubset, desired int, registered int) {
+	if t.instances == nil {
+		return
+	}
+	if desired == 0 && registered == 0 {
+		t.instances.DeleteLabelValues(service.serviceID, subset.SubsetID(), instanceStateDesired)
+		t.instances.DeleteLabelValues(service.serviceID, subset.SubsetID(), instanceStateRegistered)
+		return
+	}
+	t.instances.WithLabelValues(service.serviceID, subset.SubsetID(), instanceStateDesired).Set(float64(desired))
+	t.instances.WithLabelValues(service.serviceID, subset.SubsetID(), instanceStateRegistered).Set(float64(registered))
+}
+
+// matchDesiredInstancesAgainstExistingInstances returns instances to create or update, and instances to delete.
+// instances whose IP changed are updated in place, as RegisterInstance overwrites the attributes of existing instance,
+// so that pods remain discoverable while their instance is updated.
 func (t *instancesReconcileTask) matchDesiredInstancesAgainstExistingInstances(
 	desiredReadyInstanceInfoByID map[string]instanceInfo,
 	desiredNotReadyInstanceInfoByID map[string]instanceInfo,
@@ -170,10 +196,37 @@ func (t *instancesReconcileTask) matchDesiredInstancesAgainstExistingInstances(
 
 	desiredInstanceIDs := sets.StringKeySet(desiredReadyInstanceInfoByID).Union(sets.StringKeySet(desiredNotReadyInstanceInfoByID))
 	existingInstanceIDs := sets.StringKeySet(existingInstanceAttrsByID)
-	instancesToDelete := existingInstanceIDs.Difference(desiredInstanceIDs).List()
+	desiredInstanceIDByPod := make(map[string]string, desiredInstanceIDs.Len())
+	for _, instanceInfoByID := range []map[string]instanceInfo{desiredReadyInstanceInfoByID, desiredNotReadyInstanceInfoByID} {
+		for instanceID, info := range instanceInfoByID {
+			desiredInstanceIDByPod[instancePodKey(info.attrs)] = instanceID
+		}
+	}
+	instancesToDelete := []string{}
+	for _, instanceID := range existingInstanceIDs.Difference(desiredInstanceIDs).List() {
+		existingAttrs := existingInstanceAttrsByID[instanceID]
+		// instances registered with pod IP as ID are migrated to pod UID as ID.
+		// the legacy instance is kept until the instance for the same pod is registered, so that the pod stays discoverable.
+		if isLegacyInstanceID(instanceID, existingAttrs) {
+			if podInstanceID, ok := desiredInstanceIDByPod[instancePodKey(existingAttrs)]; ok && !existingInstanceIDs.Has(podInstanceID) {
+				continue
+			}
+		}
+		instancesToDelete = append(instancesToDelete, instanceID)
+	}
 	return instancesToCreateOrUpdate, instancesToDelete
 }
 
+// isLegacyInstanceID checks whether instance is registered with pod IP as ID, as in older versions of controller.
+func isLegacyInstanceID(instanceID string, attrs instanceAttributes) bool {
+	return instanceID == attrs[AttrAWSInstanceIPV4] || instanceID == attrs[AttrAWSInstanceIPV6]
+}
+
+// instancePodKey returns the key of pod an instance is registered for.
+func instancePodKey(attrs instanceAttributes) string {
+	return attrs[AttrK8sNamespace] + "/" + attrs[AttrK8sPod]
+}
+
 // listServiceSubsetInstances returns instances that should belong to subset of cloudMap service.
 func (t *instancesReconcileTask) listServiceSubsetInstances(ctx context.Context, service serviceSummary, subset serviceSubset) (map[string]instanceAttributes, error) {
 	instanceAttrsByID, err := t.instancesCache.ListInstances(ctx, service.serviceID)
@@ -1,7 +1,10 @@
 package cloudmap
 
 import (
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -511,6 +514,121 @@ func Test_instancesReconcileTask_matchDesiredInstancesAgainstExistingInstances(t
 			wantInstancesToCreateOrUpdate: map[string]instanceInfo{},
 			wantInstancesToDelete:         []string{},
 		},
+		{
+			name: "when pod IP changed, instance needs to be updated in place - shouldn't change AWS_INIT_HEALTH_STATUS",
+			args: args{
+				desiredReadyInstanceInfoByID: map[string]instanceInfo{
+					"pod-uid-1": {
+						attrs: instanceAttributes{
+							"AWS_INSTANCE_IPV4": "192.168.1.2",
+							"k8s.io/pod":        "pod1",
+							"k8s.io/namespace":  "pod-ns",
+						},
+					},
+				},
+				desiredNotReadyInstanceInfoByID: map[string]instanceInfo{
+					"pod-uid-2": {
+						attrs: instanceAttributes{
+							"AWS_INSTANCE_IPV4": "192.168.1.4",
+							"k8s.io/pod":        "pod2",
+							"k8s.io/namespace":  "pod-ns",
+						},
+					},
+				},
+				existingInstanceAttrsByID: map[string]instanceAttributes{
+					"pod-uid-1": {
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.1",
+						"k8s.io/pod":             "pod1",
+						"k8s.io/namespace":       "pod-ns",
+					},
+					"pod-uid-2": {
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.3",
+						"k8s.io/pod":             "pod2",
+						"k8s.io/namespace":       "pod-ns",
+					},
+				},
+			},
+			wantInstancesToCreateOrUpdate: map[string]instanceInfo{
+				"pod-uid-1": {
+					attrs: instanceAttributes{
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.2",
+						"k8s.io/pod":             "pod1",
+						"k8s.io/namespace":       "pod-ns",
+					},
+				},
+				"pod-uid-2": {
+					attrs: instanceAttributes{
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.4",
+						"k8s.io/pod":             "pod2",
+						"k8s.io/namespace":       "pod-ns",
+					},
+				},
+			},
+			wantInstancesToDelete: []string{},
+		},
+		{
+			name: "when instance registered with pod IP as ID, it's kept until instance with pod UID as ID is registered",
+			args: args{
+				desiredReadyInstanceInfoByID: map[string]instanceInfo{
+					"pod-uid-1": {
+						attrs: instanceAttributes{
+							"AWS_INSTANCE_IPV4": "192.168.1.1",
+							"k8s.io/pod":        "pod1",
+							"k8s.io/namespace":  "pod-ns",
+						},
+					},
+					"pod-uid-2": {
+						attrs: instanceAttributes{
+							"AWS_INSTANCE_IPV4": "192.168.1.2",
+							"k8s.io/pod":        "pod2",
+							"k8s.io/namespace":  "pod-ns",
+						},
+					},
+				},
+				desiredNotReadyInstanceInfoByID: nil,
+				existingInstanceAttrsByID: map[string]instanceAttributes{
+					"192.168.1.1": {
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.1",
+						"k8s.io/pod":             "pod1",
+						"k8s.io/namespace":       "pod-ns",
+					},
+					"192.168.1.2": {
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.2",
+						"k8s.io/pod":             "pod2",
+						"k8s.io/namespace":       "pod-ns",
+					},
+					"pod-uid-2": {
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.2",
+						"k8s.io/pod":             "pod2",
+						"k8s.io/namespace":       "pod-ns",
+					},
+					"192.168.1.3": {
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.3",
+						"k8s.io/pod":             "pod3",
+						"k8s.io/namespace":       "pod-ns",
+					},
+				},
+			},
+			wantInstancesToCreateOrUpdate: map[string]instanceInfo{
+				"pod-uid-1": {
+					attrs: instanceAttributes{
+						"AWS_INIT_HEALTH_STATUS": "HEALTHY",
+						"AWS_INSTANCE_IPV4":      "192.168.1.1",
+						"k8s.io/pod":             "pod1",
+						"k8s.io/namespace":       "pod-ns",
+					},
+				},
+			},
+			wantInstancesToDelete: []string{"192.168.1.2", "192.168.1.3"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -521,3 +639,39 @@ func Test_instancesReconcileTask_matchDesiredInstancesAgainstExistingInstances(t
 		})
 	}
 }
+
+func Test_instancesReconcileTask_reportInstances(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	instances := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "instances"}, []string{labelService, labelSubset, labelInstanceState})
+	registry.MustRegister(instances)
+	task := &instancesReconcileTask{instances: instances}
+	service := serviceSummary{serviceID: "srv-1"}
+	subset := &meshMemberServiceSubset{
+		ms:     &appmesh.Mesh{Spec: appmesh.MeshSpec{AWSName: aws.String("my-mesh")}},
+		member: &meshMember{awsName: "vn_ns"},
+	}
+	gatherInstances := func() map[string]float64 {
+		metricFamilies, err := registry.Gather()
+		assert.NoError(t, err)
+		got := make(map[string]float64)
+		for _, metricFamily := range metricFamilies {
+			for _, metric := range metricFamily.GetMetric() {
+				labels := make(map[string]string)
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				got[labels[labelService]+"/"+labels[labelSubset]+":"+labels[labelInstanceState]] = metric.GetGauge().GetValue()
+			}
+		}
+		return got
+	}
+
+	task.reportInstances(service, subset, 3, 1)
+	assert.Equal(t, map[string]float64{
+		"srv-1/my-mesh/vn_ns:desired":    3,
+		"srv-1/my-mesh/vn_ns:registered": 1,
+	}, gatherInstances())
+
+	task.reportInstances(service, subset, 0, 0)
+	assert.Empty(t, gatherInstances())
+}
@@ -2,16 +2,21 @@ package cloudmap
 
 import (
 	"context"
+	"net"
 	"strconv"
+	"sync"
 	"time"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/servicediscovery"
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -36,9 +41,15 @@ const (
 	AttrK8sPodRegion = "REGION"
 	// AttrK8sPodAZ is a custom attribute injected by app-mesh controller
 	AttrK8sPodAZ = "AVAILABILITY_ZONE"
+	// AttrK8sTopologyZoneHints is a custom attribute injected by app-mesh controller, with the zones from topology hints of pod's endpoint.
+	AttrK8sTopologyZoneHints = "TOPOLOGY_ZONE_HINTS"
 
 	AttrAppMeshMesh        = "appmesh.k8s.aws/mesh"
 	AttrAppMeshVirtualNode = "appmesh.k8s.aws/virtualNode"
+	// AttrAppMeshVirtualGateway is a custom attribute injected by app-mesh controller for pods of VirtualGateway.
+	AttrAppMeshVirtualGateway = "appmesh.k8s.aws/virtualGateway"
+	// AttrAppMeshCluster is a custom attribute injected by app-mesh controller in multi-cluster mode, with the ID of registering cluster.
+	AttrAppMeshCluster = "appmesh.k8s.aws/cluster"
 
 	// how long to synchronously wait for instances reconcile operation
 	defaultInstancesReconcileWaitTimeout = 5 * time.Second
@@ -51,11 +62,24 @@ const (
 )
 
 type InstancesReconciler interface {
-	Reconcile(ctx context.Context, ms *appmesh.Mesh, vn *appmesh.VirtualNode, service serviceSummary,
+	// Reconcile registers pods of VirtualNode or VirtualGateway as instances of cloudMap service, with port as instance port.
+	// port of 0 means instances have no port.
+	Reconcile(ctx context.Context, ms *appmesh.Mesh, member *meshMember, service serviceSummary, port int64,
 		readyPods []*corev1.Pod, notReadyPods []*corev1.Pod, nodeInfoByName map[string]nodeAttributes) error
 }
 
-func NewDefaultInstancesReconciler(k8sClient client.Client, cloudMapSDK services.CloudMap, log logr.Logger, stopChan <-chan struct{}, ipFamily string) *defaultInstancesReconciler {
+// MeshStateForgetter forgets state kept in memory for meshes, which turns stale once they're reconciled by other replicas.
+type MeshStateForgetter interface {
+	// ForgetMeshes forgets state of meshes that are not owned by this replica.
+	ForgetMeshes(owned func(meshName string) bool)
+}
+
+func NewDefaultInstancesReconciler(k8sClient client.Client, cloudMapSDK services.CloudMap, cfg Config, metricsRegisterer prometheus.Registerer,
+	log logr.Logger, stopChan <-chan struct{}, ipFamily string) (*defaultInstancesReconciler, error) {
+	instancesHealthStatusUpdater, err := newDefaultInstancesHealthStatusUpdater(cloudMapSDK, cfg.HealthStatusUpdateConcurrency, metricsRegisterer, log)
+	if err != nil {
+		return nil, err
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		select {
@@ -64,39 +88,55 @@ func NewDefaultInstancesReconciler(k8sClient client.Client, cloudMapSDK services
 		}
 	}()
 
-	instancesReconcileReactor := newDefaultInstancesReconcileReactor(ctx, k8sClient, cloudMapSDK, log)
+	instancesReconcileReactor, err := newDefaultInstancesReconcileReactor(ctx, k8sClient, cloudMapSDK, metricsRegisterer, log)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 	instancesHealthProber := newDefaultInstancesHealthProber(ctx, k8sClient, cloudMapSDK, log)
 	return &defaultInstancesReconciler{
-		cloudMapSDK:               cloudMapSDK,
-		instancesReconcileReactor: instancesReconcileReactor,
-		instancesHealthProber:     instancesHealthProber,
-		log:                       log,
-		ipFamily:                  ipFamily,
-	}
+		cloudMapSDK:                  cloudMapSDK,
+		instancesReconcileReactor:    instancesReconcileReactor,
+		instancesHealthProber:        instancesHealthProber,
+		instancesHealthStatusUpdater: instancesHealthStatusUpdater,
+		log:                          log,
+		ipFamily:                     ipFamily,
+		clusterID:                    cfg.ClusterID,
+	}, nil
 }
 
 var _ InstancesReconciler = &defaultInstancesReconciler{}
+var _ MeshStateForgetter = &defaultInstancesReconciler{}
 
 type defaultInstancesReconciler struct {
-	cloudMapSDK               services.CloudMap
-	instancesReconcileReactor instancesReconcileReactor
-	instancesHealthProber     instancesHealthProber
-	log                       logr.Logger
-	ipFamily                  string
+	cloudMapSDK                  services.CloudMap
+	instancesReconcileReactor    instancesReconcileReactor
+	instancesHealthProber        instancesHealthProber
+	instancesHealthStatusUpdater instancesHealthStatusUpdater
+	log                          logr.Logger
+	ipFamily                     string
+	// clusterID is the ID of this cluster, empty if multi-cluster mode is disabled.
+	clusterID string
+
+	// subsetsByMesh tracks service subsets with instances, indexed by mesh name.
+	subsetsByMesh      map[string]map[serviceSubsetID]struct{}
+	subsetsByMeshMutex sync.Mutex
 }
 
-func (r *defaultInstancesReconciler) Reconcile(ctx context.Context, ms *appmesh.Mesh, vn *appmesh.VirtualNode, service serviceSummary,
+func (r *defaultInstancesReconciler) Reconcile(ctx context.Context, ms *appmesh.Mesh, member *meshMember, service serviceSummary, port int64,
 	readyPods []*corev1.Pod, notReadyPods []*corev1.Pod, nodeInfoByName map[string]nodeAttributes) error {
 
 	customHealthCheckEnabled := service.healthCheckCustomConfig != nil
-	subset := &virtualNodeServiceSubset{
-		ms: ms,
-		vn: vn,
+	subset := &meshMemberServiceSubset{
+		ms:        ms,
+		member:    member,
+		clusterID: r.clusterID,
 	}
-	readyInstanceInfoByID := r.buildInstanceInfoByID(ms, vn, readyPods, nodeInfoByName)
+	r.trackMeshSubset(ms.Name, serviceSubsetID{serviceID: service.serviceID, subsetID: subset.SubsetID()}, len(readyPods)+len(notReadyPods) != 0)
+	readyInstanceInfoByID := r.buildInstanceInfoByID(ctx, ms, member, port, readyPods, nodeInfoByName)
 	var notReadyInstanceInfoByID map[string]instanceInfo
 	if customHealthCheckEnabled {
-		notReadyInstanceInfoByID = r.buildInstanceInfoByID(ms, vn, notReadyPods, nodeInfoByName)
+		notReadyInstanceInfoByID = r.buildInstanceInfoByID(ctx, ms, member, port, notReadyPods, nodeInfoByName)
 	}
 	resultChan := r.instancesReconcileReactor.Submit(ctx, service, subset, readyInstanceInfoByID, notReadyInstanceInfoByID)
 	select {
@@ -108,45 +148,76 @@ func (r *defaultInstancesReconciler) Reconcile(ctx context.Context, ms *appmesh.
 		}
 	}
 	if customHealthCheckEnabled {
-		if err := r.reconcileCustomHealthCheck(ctx, service, readyInstanceInfoByID, notReadyInstanceInfoByID); err != nil {
+		if err := r.instancesHealthStatusUpdater.Update(ctx, service, subset,
+			sets.StringKeySet(readyInstanceInfoByID), sets.StringKeySet(notReadyInstanceInfoByID)); err != nil {
 			return err
 		}
 	}
+	// the CloudMapHealthy readinessGate of pods only reflects member's own cloudMap service, not its additional listeners' services.
+	if port != primaryListenerPort(member) {
+		return nil
+	}
 	if err := r.instancesHealthProber.Submit(ctx, service, subset, readyInstanceInfoByID, defaultInstancesHealthProbeTimeout); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *defaultInstancesReconciler) reconcileCustomHealthCheck(ctx context.Context, service serviceSummary, readyInstanceInfoByID map[string]instanceInfo, notReadyInstanceInfoByID map[string]instanceInfo) error {
-	for instanceID := range readyInstanceInfoByID {
-		if _, err := r.cloudMapSDK.UpdateInstanceCustomHealthStatusWithContext(ctx, &servicediscovery.UpdateInstanceCustomHealthStatusInput{
-			ServiceId:  aws.String(service.serviceID),
-			InstanceId: aws.String(instanceID),
-			Status:     aws.String(servicediscovery.CustomHealthStatusHealthy),
-		}); err != nil {
-			return err
+func (r *defaultInstancesReconciler) ForgetMeshes(owned func(meshName string) bool) {
+	r.subsetsByMeshMutex.Lock()
+	var forgotten []serviceSubsetID
+	for meshName, subsetIDs := range r.subsetsByMesh {
+		if owned(meshName) {
+			continue
 		}
+		for subsetID := range subsetIDs {
+			forgotten = append(forgotten, subsetID)
+		}
+		delete(r.subsetsByMesh, meshName)
 	}
-	for instanceID := range notReadyInstanceInfoByID {
-		if _, err := r.cloudMapSDK.UpdateInstanceCustomHealthStatusWithContext(ctx, &servicediscovery.UpdateInstanceCustomHealthStatusInput{
-			ServiceId:  aws.String(service.serviceID),
-			InstanceId: aws.String(instanceID),
-			Status:     aws.String(servicediscovery.CustomHealthStatusUnhealthy),
-		}); err != nil {
-			return err
+	r.subsetsByMeshMutex.Unlock()
+	if len(forgotten) == 0 {
+		return
+	}
+	r.log.V(1).Info("forgetting cloudMap service subsets of meshes not owned", "subsets", len(forgotten))
+	r.instancesReconcileReactor.Forget(forgotten)
+	r.instancesHealthProber.Forget(forgotten)
+	r.instancesHealthStatusUpdater.Forget(forgotten)
+}
+
+// trackMeshSubset tracks subset of mesh while it has instances, so that its state can be forgotten with the mesh.
+func (r *defaultInstancesReconciler) trackMeshSubset(meshName string, subsetID serviceSubsetID, hasInstances bool) {
+	r.subsetsByMeshMutex.Lock()
+	defer r.subsetsByMeshMutex.Unlock()
+	if !hasInstances {
+		delete(r.subsetsByMesh[meshName], subsetID)
+		if len(r.subsetsByMesh[meshName]) == 0 {
+			delete(r.subsetsByMesh, meshName)
 		}
+		return
 	}
-	return nil
+	if r.subsetsByMesh == nil {
+		r.subsetsByMesh = make(map[string]map[serviceSubsetID]struct{})
+	}
+	if r.subsetsByMesh[meshName] == nil {
+		r.subsetsByMesh[meshName] = make(map[serviceSubsetID]struct{})
+	}
+	r.subsetsByMesh[meshName][subsetID] = struct{}{}
 }
 
 // buildInstanceInfoByID build instances info indexed by instanceID
-func (r *defaultInstancesReconciler) buildInstanceInfoByID(ms *appmesh.Mesh, vn *appmesh.VirtualNode,
+func (r *defaultInstancesReconciler) buildInstanceInfoByID(ctx context.Context, ms *appmesh.Mesh, member *meshMember, port int64,
 	pods []*corev1.Pod, nodeInfoByName map[string]nodeAttributes) map[string]instanceInfo {
 	instanceInfoByID := make(map[string]instanceInfo, len(pods))
 	for _, pod := range pods {
 		instanceID := r.buildInstanceID(pod)
-		instanceAttrs := r.buildInstanceAttributes(ms, vn, pod, nodeInfoByName)
+		instanceAttrs := r.buildInstanceAttributes(ctx, ms, member, port, pod, nodeInfoByName)
+		// cloudMap would reject the instance anyway, skip it instead of failing registration of other instances.
+		if err := validateInstanceAttributeLimits(instanceAttrs); err != nil {
+			tracing.LoggerFromContext(ctx, r.log).Error(err, "instance attributes exceed cloudMap limits, skipping instance",
+				"pod", k8s.NamespacedName(pod))
+			continue
+		}
 		instanceInfoByID[instanceID] = instanceInfo{
 			attrs: instanceAttrs,
 			pod:   pod,
@@ -155,30 +226,62 @@ func (r *defaultInstancesReconciler) buildInstanceInfoByID(ms *appmesh.Mesh, vn
 	return instanceInfoByID
 }
 
-func (r *defaultInstancesReconciler) buildInstanceAttributes(ms *appmesh.Mesh, vn *appmesh.VirtualNode,
+func (r *defaultInstancesReconciler) buildInstanceAttributes(ctx context.Context, ms *appmesh.Mesh, member *meshMember, port int64,
 	pod *corev1.Pod, nodeInfoByName map[string]nodeAttributes) instanceAttributes {
+	cloudMapConfig := member.cloudMapConfig
 	attr := make(map[string]string)
 	for label, v := range pod.Labels {
-		attr[label] = v
+		if shouldExportPodLabel(cloudMapConfig.PodLabels, label) {
+			attr[label] = v
+		}
 	}
-	for _, cmAttr := range vn.Spec.ServiceDiscovery.AWSCloudMap.Attributes {
+	for _, cmAttr := range cloudMapConfig.Attributes {
 		attr[cmAttr.Key] = cmAttr.Value
 	}
+	if len(cloudMapConfig.InstanceAttributes) != 0 {
+		attrTemplates, err := member.instanceAttributeTemplates()
+		if err != nil {
+			tracing.LoggerFromContext(ctx, r.log).Error(err, "failed to parse instance attributes",
+				"pod", k8s.NamespacedName(pod))
+		}
+		templateData := buildInstanceAttributeTemplateData(pod, nodeInfoByName)
+		for _, attrTemplate := range attrTemplates {
+			value, err := renderInstanceAttribute(attrTemplate, templateData)
+			if err != nil {
+				tracing.LoggerFromContext(ctx, r.log).Error(err, "failed to render instance attribute",
+					"pod", k8s.NamespacedName(pod),
+					"attribute", attrTemplate.Name())
+				continue
+			}
+			if value != "" {
+				attr[attrTemplate.Name()] = value
+			}
+		}
+	}
 	podsNodeName := pod.Spec.NodeName
-	if r.ipFamily == IPv6 {
+	if wantIPv4, wantIPv6, ok := addressRecordTypes(cloudMapConfig); ok {
+		ipv4, ipv6 := podIPsByFamily(pod)
+		if wantIPv4 && ipv4 != "" {
+			attr[AttrAWSInstanceIPV4] = ipv4
+		}
+		if wantIPv6 && ipv6 != "" {
+			attr[AttrAWSInstanceIPV6] = ipv6
+		}
+	} else if r.ipFamily == IPv6 {
 		attr[AttrAWSInstanceIPV6] = pod.Status.PodIP
 	} else {
 		attr[AttrAWSInstanceIPV4] = pod.Status.PodIP
 	}
-
-	/* VirtualNode currently supports only one listener. In future even if support for multiple listeners is introduced,
-	we will always derive the port value from the first listener config. */
-
-	attr[AttrAWSInstancePort] = strconv.Itoa(int(vn.Spec.Listeners[0].PortMapping.Port))
+	if port != 0 {
+		attr[AttrAWSInstancePort] = strconv.FormatInt(port, 10)
+	}
 	attr[AttrK8sPod] = pod.Name
 	attr[AttrK8sNamespace] = pod.Namespace
 	attr[AttrAppMeshMesh] = aws.StringValue(ms.Spec.AWSName)
-	attr[AttrAppMeshVirtualNode] = aws.StringValue(vn.Spec.AWSName)
+	attr[member.awsNameAttr] = member.awsName
+	if r.clusterID != "" {
+		attr[AttrAppMeshCluster] = r.clusterID
+	}
 	if nodeInfo, ok := nodeInfoByName[podsNodeName]; ok {
 		if nodeInfo.region != "" {
 			attr[AttrK8sPodRegion] = nodeInfo.region
@@ -187,9 +290,53 @@ func (r *defaultInstancesReconciler) buildInstanceAttributes(ms *appmesh.Mesh, v
 			attr[AttrK8sPodAZ] = nodeInfo.availabilityZone
 		}
 	}
+	if zoneHints, ok := pod.Annotations[annotationTopologyZoneHints]; ok {
+		attr[AttrK8sTopologyZoneHints] = zoneHints
+	}
 	return attr
 }
 
+// addressRecordTypes returns whether A and AAAA records are explicitly specified for cloudMap service.
+// ok is false if neither is specified, in which case the record type is determined by cluster's IP family.
+func addressRecordTypes(cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) (wantIPv4 bool, wantIPv6 bool, ok bool) {
+	if cloudMapConfig.DNSConfig == nil {
+		return false, false, false
+	}
+	for _, recordType := range cloudMapConfig.DNSConfig.RecordTypes {
+		switch recordType {
+		case appmesh.AWSCloudMapDNSRecordTypeA:
+			wantIPv4 = true
+		case appmesh.AWSCloudMapDNSRecordTypeAAAA:
+			wantIPv6 = true
+		}
+	}
+	return wantIPv4, wantIPv6, wantIPv4 || wantIPv6
+}
+
+// podIPsByFamily returns the IPv4 and IPv6 address of pod, which has both for dual-stack pods.
+func podIPsByFamily(pod *corev1.Pod) (ipv4 string, ipv6 string) {
+	podIPs := []string{pod.Status.PodIP}
+	for _, podIP := range pod.Status.PodIPs {
+		podIPs = append(podIPs, podIP.IP)
+	}
+	for _, podIP := range podIPs {
+		ip := net.ParseIP(podIP)
+		if ip == nil {
+			continue
+		}
+		if ip.To4() != nil {
+			if ipv4 == "" {
+				ipv4 = podIP
+			}
+		} else if ipv6 == "" {
+			ipv6 = podIP
+		}
+	}
+	return ipv4, ipv6
+}
+
+// buildInstanceID returns the cloudMap instance ID for pod.
+// pod UID is used so that instances are never shared by pods reusing an IP, and can be traced back to pods.
 func (r *defaultInstancesReconciler) buildInstanceID(pod *corev1.Pod) string {
-	return pod.Status.PodIP
+	return string(pod.UID)
 }
@@ -1,8 +1,10 @@
 package cloudmap
 
 import (
+	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -244,11 +246,45 @@ func Test_defaultInstancesReconciler_buildInstanceAttributes(t *testing.T) {
 				"appmesh.k8s.aws/virtualNode": "my-vn",
 			},
 		},
+		{
+			name: "attributes shouldn't have port if VirtualNode has no listeners",
+			args: args{
+				ms: &appmesh.Mesh{
+					Spec: appmesh.MeshSpec{
+						AWSName: aws.String("my-mesh"),
+					},
+				},
+				vn: &appmesh.VirtualNode{
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("my-vn"),
+						ServiceDiscovery: &appmesh.ServiceDiscovery{
+							AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{},
+						},
+					},
+				},
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "pod-ns",
+						Name:      "pod-name",
+					},
+					Status: corev1.PodStatus{
+						PodIP: "192.168.1.42",
+					},
+				},
+			},
+			want: instanceAttributes{
+				"AWS_INSTANCE_IPV4":           "192.168.1.42",
+				"k8s.io/pod":                  "pod-name",
+				"k8s.io/namespace":            "pod-ns",
+				"appmesh.k8s.aws/mesh":        "my-mesh",
+				"appmesh.k8s.aws/virtualNode": "my-vn",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &defaultInstancesReconciler{}
-			got := r.buildInstanceAttributes(tt.args.ms, tt.args.vn, tt.args.pod, nil)
+			got := r.buildInstanceAttributes(context.Background(), tt.args.ms, newVirtualNodeMeshMember(tt.args.vn), primaryListenerPort(newVirtualNodeMeshMember(tt.args.vn)), tt.args.pod, nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -267,14 +303,16 @@ func Test_defaultInstancesReconciler_buildInstanceID(t *testing.T) {
 			name: "normal case",
 			args: args{
 				pod: &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{},
-					Spec:       corev1.PodSpec{},
+					ObjectMeta: metav1.ObjectMeta{
+						UID: "4cb8cfa4-0e1d-4fd4-8bb5-7c2ec0f7d39b",
+					},
+					Spec: corev1.PodSpec{},
 					Status: corev1.PodStatus{
 						PodIP: "192.168.1.42",
 					},
 				},
 			},
-			want: "192.168.1.42",
+			want: "4cb8cfa4-0e1d-4fd4-8bb5-7c2ec0f7d39b",
 		},
 	}
 	for _, tt := range tests {
@@ -323,6 +361,7 @@ func Test_defaultInstancesReconciler_buildInstanceInfoByID(t *testing.T) {
 						ObjectMeta: metav1.ObjectMeta{
 							Namespace: "pod-ns",
 							Name:      "pod-name-1",
+							UID:       "pod-uid-1",
 							Labels: map[string]string{
 								"podLabelA": "valueA",
 								"podLabelB": "valueB",
@@ -337,6 +376,7 @@ func Test_defaultInstancesReconciler_buildInstanceInfoByID(t *testing.T) {
 						ObjectMeta: metav1.ObjectMeta{
 							Namespace: "pod-ns",
 							Name:      "pod-name-2",
+							UID:       "pod-uid-2",
 							Labels: map[string]string{
 								"podLabelA": "valueA",
 								"podLabelB": "valueB",
@@ -350,7 +390,7 @@ func Test_defaultInstancesReconciler_buildInstanceInfoByID(t *testing.T) {
 				},
 			},
 			want: map[string]instanceInfo{
-				"192.168.1.42": {
+				"pod-uid-1": {
 					attrs: instanceAttributes{
 						"podLabelA":                   "valueA",
 						"podLabelB":                   "valueB",
@@ -365,6 +405,7 @@ func Test_defaultInstancesReconciler_buildInstanceInfoByID(t *testing.T) {
 						ObjectMeta: metav1.ObjectMeta{
 							Namespace: "pod-ns",
 							Name:      "pod-name-1",
+							UID:       "pod-uid-1",
 							Labels: map[string]string{
 								"podLabelA": "valueA",
 								"podLabelB": "valueB",
@@ -376,7 +417,7 @@ func Test_defaultInstancesReconciler_buildInstanceInfoByID(t *testing.T) {
 						},
 					},
 				},
-				"192.168.2.42": {
+				"pod-uid-2": {
 					attrs: instanceAttributes{
 						"podLabelA":                   "valueA",
 						"podLabelB":                   "valueB",
@@ -391,6 +432,7 @@ func Test_defaultInstancesReconciler_buildInstanceInfoByID(t *testing.T) {
 						ObjectMeta: metav1.ObjectMeta{
 							Namespace: "pod-ns",
 							Name:      "pod-name-2",
+							UID:       "pod-uid-2",
 							Labels: map[string]string{
 								"podLabelA": "valueA",
 								"podLabelB": "valueB",
@@ -458,7 +500,7 @@ func Test_defaultInstancesReconciler_buildInstanceInfoByID(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &defaultInstancesReconciler{}
-			got := r.buildInstanceInfoByID(tt.args.ms, tt.args.vn, tt.args.pods, nil)
+			got := r.buildInstanceInfoByID(context.Background(), tt.args.ms, newVirtualNodeMeshMember(tt.args.vn), primaryListenerPort(newVirtualNodeMeshMember(tt.args.vn)), tt.args.pods, nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -528,7 +570,7 @@ func Test_defaultInstancesReconciler_buildInstanceAttributes_IPv6(t *testing.T)
 			r := &defaultInstancesReconciler{
 				ipFamily: IPv6,
 			}
-			got := r.buildInstanceAttributes(tt.args.ms, tt.args.vn, tt.args.pod, nil)
+			got := r.buildInstanceAttributes(context.Background(), tt.args.ms, newVirtualNodeMeshMember(tt.args.vn), primaryListenerPort(newVirtualNodeMeshMember(tt.args.vn)), tt.args.pod, nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -592,14 +634,229 @@ func Test_defaultInstancesReconciler_buildInstanceAttributes_IPv4(t *testing.T)
 				"appmesh.k8s.aws/virtualNode": "my-vn",
 			},
 		},
+		{
+			name: "should have TOPOLOGY_ZONE_HINTS set to pod's topology zone hints",
+			args: args{
+				ms: &appmesh.Mesh{
+					Spec: appmesh.MeshSpec{
+						AWSName: aws.String("my-mesh"),
+					},
+				},
+				vn: &appmesh.VirtualNode{
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("my-vn"),
+						ServiceDiscovery: &appmesh.ServiceDiscovery{
+							AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{},
+						},
+					},
+				},
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "pod-ns",
+						Name:      "pod-name",
+						Annotations: map[string]string{
+							"appmesh.k8s.aws/topologyZoneHints": "us-west-2a,us-west-2b",
+						},
+					},
+					Status: corev1.PodStatus{
+						PodIP: "192.168.1.42",
+					},
+				},
+			},
+			want: instanceAttributes{
+				"AWS_INSTANCE_IPV4":           "192.168.1.42",
+				"TOPOLOGY_ZONE_HINTS":         "us-west-2a,us-west-2b",
+				"k8s.io/pod":                  "pod-name",
+				"k8s.io/namespace":            "pod-ns",
+				"appmesh.k8s.aws/mesh":        "my-mesh",
+				"appmesh.k8s.aws/virtualNode": "my-vn",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &defaultInstancesReconciler{
 				ipFamily: IPv4,
 			}
-			got := r.buildInstanceAttributes(tt.args.ms, tt.args.vn, tt.args.pod, nil)
+			got := r.buildInstanceAttributes(context.Background(), tt.args.ms, newVirtualNodeMeshMember(tt.args.vn), primaryListenerPort(newVirtualNodeMeshMember(tt.args.vn)), tt.args.pod, nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func Test_podIPsByFamily(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		wantIPv4 string
+		wantIPv6 string
+	}{
+		{
+			name: "dual-stack pod",
+			pod: &corev1.Pod{Status: corev1.PodStatus{
+				PodIP:  "192.168.1.42",
+				PodIPs: []corev1.PodIP{{IP: "192.168.1.42"}, {IP: "2001:db8::42"}},
+			}},
+			wantIPv4: "192.168.1.42",
+			wantIPv6: "2001:db8::42",
+		},
+		{
+			name:     "single-stack IPv6 pod",
+			pod:      &corev1.Pod{Status: corev1.PodStatus{PodIP: "2001:db8::42"}},
+			wantIPv4: "",
+			wantIPv6: "2001:db8::42",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotIPv4, gotIPv6 := podIPsByFamily(tt.pod)
+			assert.Equal(t, tt.wantIPv4, gotIPv4)
+			assert.Equal(t, tt.wantIPv6, gotIPv6)
+		})
+	}
+}
+
+func Test_defaultInstancesReconciler_buildInstanceAttributes_dualStack(t *testing.T) {
+	ms := &appmesh.Mesh{Spec: appmesh.MeshSpec{AWSName: aws.String("my-mesh")}}
+	vn := &appmesh.VirtualNode{
+		Spec: appmesh.VirtualNodeSpec{
+			AWSName: aws.String("my-vn"),
+			ServiceDiscovery: &appmesh.ServiceDiscovery{
+				AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+					DNSConfig: &appmesh.AWSCloudMapDNSConfig{
+						RecordTypes: []appmesh.AWSCloudMapDNSRecordType{appmesh.AWSCloudMapDNSRecordTypeA, appmesh.AWSCloudMapDNSRecordTypeAAAA},
+					},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "pod-ns", Name: "pod-name"},
+		Status: corev1.PodStatus{
+			PodIP:  "192.168.1.42",
+			PodIPs: []corev1.PodIP{{IP: "192.168.1.42"}, {IP: "2001:db8::42"}},
+		},
+	}
+	r := &defaultInstancesReconciler{ipFamily: IPv4}
+	got := r.buildInstanceAttributes(context.Background(), ms, newVirtualNodeMeshMember(vn), 0, pod, nil)
+	assert.Equal(t, instanceAttributes{
+		"AWS_INSTANCE_IPV4":           "192.168.1.42",
+		"AWS_INSTANCE_IPV6":           "2001:db8::42",
+		"k8s.io/pod":                  "pod-name",
+		"k8s.io/namespace":            "pod-ns",
+		"appmesh.k8s.aws/mesh":        "my-mesh",
+		"appmesh.k8s.aws/virtualNode": "my-vn",
+	}, got)
+}
+
+func Test_defaultInstancesReconciler_buildInstanceAttributes_multiCluster(t *testing.T) {
+	ms := &appmesh.Mesh{Spec: appmesh.MeshSpec{AWSName: aws.String("my-mesh")}}
+	vn := &appmesh.VirtualNode{
+		Spec: appmesh.VirtualNodeSpec{
+			AWSName: aws.String("my-vn"),
+			ServiceDiscovery: &appmesh.ServiceDiscovery{
+				AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "pod-ns", Name: "pod-name"},
+		Status:     corev1.PodStatus{PodIP: "192.168.1.42"},
+	}
+	r := &defaultInstancesReconciler{ipFamily: IPv4, clusterID: "cluster-a"}
+	got := r.buildInstanceAttributes(context.Background(), ms, newVirtualNodeMeshMember(vn), 0, pod, nil)
+	assert.Equal(t, instanceAttributes{
+		"AWS_INSTANCE_IPV4":           "192.168.1.42",
+		"k8s.io/pod":                  "pod-name",
+		"k8s.io/namespace":            "pod-ns",
+		"appmesh.k8s.aws/mesh":        "my-mesh",
+		"appmesh.k8s.aws/virtualNode": "my-vn",
+		"appmesh.k8s.aws/cluster":     "cluster-a",
+	}, got)
+}
+
+func Test_defaultInstancesReconciler_buildInstanceAttributes_virtualGateway(t *testing.T) {
+	ms := &appmesh.Mesh{Spec: appmesh.MeshSpec{AWSName: aws.String("my-mesh")}}
+	vg := &appmesh.VirtualGateway{
+		Spec: appmesh.VirtualGatewaySpec{
+			AWSName: aws.String("my-vg"),
+			ServiceDiscovery: &appmesh.VirtualGatewayServiceDiscovery{
+				AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "pod-ns", Name: "pod-name"},
+		Status:     corev1.PodStatus{PodIP: "192.168.1.42"},
+	}
+	r := &defaultInstancesReconciler{ipFamily: IPv4}
+	got := r.buildInstanceAttributes(context.Background(), ms, newVirtualGatewayMeshMember(vg), 8088, pod, nil)
+	assert.Equal(t, instanceAttributes{
+		"AWS_INSTANCE_IPV4":              "192.168.1.42",
+		"AWS_INSTANCE_PORT":              "8088",
+		"k8s.io/pod":                     "pod-name",
+		"k8s.io/namespace":               "pod-ns",
+		"appmesh.k8s.aws/mesh":           "my-mesh",
+		"appmesh.k8s.aws/virtualGateway": "my-vg",
+	}, got)
+}
+
+// fakeForgettingReactor, fakeForgettingProber and fakeForgettingUpdater record forgotten service subsets.
+type fakeForgettingReactor struct {
+	instancesReconcileReactor
+	forgotten []serviceSubsetID
+}
+
+func (f *fakeForgettingReactor) Forget(serviceSubsetIDs []serviceSubsetID) {
+	f.forgotten = append(f.forgotten, serviceSubsetIDs...)
+}
+
+type fakeForgettingProber struct {
+	instancesHealthProber
+	forgotten []serviceSubsetID
+}
+
+func (f *fakeForgettingProber) Forget(serviceSubsetIDs []serviceSubsetID) {
+	f.forgotten = append(f.forgotten, serviceSubsetIDs...)
+}
+
+type fakeForgettingUpdater struct {
+	instancesHealthStatusUpdater
+	forgotten []serviceSubsetID
+}
+
+func (f *fakeForgettingUpdater) Forget(serviceSubsetIDs []serviceSubsetID) {
+	f.forgotten = append(f.forgotten, serviceSubsetIDs...)
+}
+
+func Test_defaultInstancesReconciler_ForgetMeshes(t *testing.T) {
+	reactor := &fakeForgettingReactor{}
+	prober := &fakeForgettingProber{}
+	updater := &fakeForgettingUpdater{}
+	r := &defaultInstancesReconciler{
+		instancesReconcileReactor:    reactor,
+		instancesHealthProber:        prober,
+		instancesHealthStatusUpdater: updater,
+		log:                          logr.Discard(),
+	}
+	subsetA := serviceSubsetID{serviceID: "srv-1", subsetID: "mesh-a/vn-a"}
+	subsetB := serviceSubsetID{serviceID: "srv-2", subsetID: "mesh-b/vn-b"}
+	subsetBEmpty := serviceSubsetID{serviceID: "srv-3", subsetID: "mesh-b/vn-c"}
+	r.trackMeshSubset("mesh-a", subsetA, true)
+	r.trackMeshSubset("mesh-b", subsetB, true)
+	r.trackMeshSubset("mesh-b", subsetBEmpty, true)
+	// subsets are no longer tracked once their instances are gone.
+	r.trackMeshSubset("mesh-b", subsetBEmpty, false)
+
+	r.ForgetMeshes(func(meshName string) bool { return meshName == "mesh-a" })
+	assert.Equal(t, []serviceSubsetID{subsetB}, reactor.forgotten)
+	assert.Equal(t, []serviceSubsetID{subsetB}, prober.forgotten)
+	assert.Equal(t, []serviceSubsetID{subsetB}, updater.forgotten)
+	assert.Equal(t, map[string]map[serviceSubsetID]struct{}{
+		"mesh-a": {subsetA: {}},
+	}, r.subsetsByMesh)
+
+	// meshes already forgotten are not forgotten again.
+	r.ForgetMeshes(func(meshName string) bool { return meshName == "mesh-a" })
+	assert.Equal(t, []serviceSubsetID{subsetB}, reactor.forgotten)
+}
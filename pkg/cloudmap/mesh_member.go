@@ -0,0 +1,138 @@
+package cloudmap
+
+import (
+	"context"
+	"text/template"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// kinds of mesh members.
+const (
+	MemberKindVirtualNode    = "VirtualNode"
+	MemberKindVirtualGateway = "VirtualGateway"
+)
+
+// meshMember is a VirtualNode or VirtualGateway whose pods are registered as instances of cloudMap services.
+type meshMember struct {
+	// obj is the VirtualNode or VirtualGateway object, cloudMap annotations are recorded on it.
+	obj client.Object
+	// kind is the kind of obj, used in logs.
+	kind string
+	// awsName is the AppMesh name of VirtualNode or VirtualGateway.
+	awsName string
+	// awsNameAttr is the instance attribute that records awsName on registered instances.
+	awsNameAttr string
+	meshRef     *appmesh.MeshReference
+	podSelector *metav1.LabelSelector
+	// cloudMapConfig is the cloudMap serviceDiscovery, nil if pods shouldn't be registered into cloudMap.
+	cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery
+	// listenerPorts is the ports of listeners, in their order in spec.
+	listenerPorts []int64
+
+	// attrTemplates is the parsed templates of cloudMap instanceAttributes, see instanceAttributeTemplates.
+	attrTemplates      []*template.Template
+	attrTemplatesErr   error
+	attrTemplatesReady bool
+}
+
+// instanceAttributeTemplates returns the parsed templates of cloudMap instanceAttributes.
+// They're parsed once per meshMember, instead of for every pod and listener.
+func (m *meshMember) instanceAttributeTemplates() ([]*template.Template, error) {
+	if !m.attrTemplatesReady {
+		m.attrTemplates, m.attrTemplatesErr = parseInstanceAttributeTemplates(m.cloudMapConfig)
+		m.attrTemplatesReady = true
+	}
+	return m.attrTemplates, m.attrTemplatesErr
+}
+
+// meshNameOf returns the name of Mesh that VirtualNode or VirtualGateway belongs to, empty if it has no meshRef.
+func meshNameOf(obj client.Object) string {
+	var meshRef *appmesh.MeshReference
+	switch member := obj.(type) {
+	case *appmesh.VirtualNode:
+		meshRef = member.Spec.MeshRef
+	case *appmesh.VirtualGateway:
+		meshRef = member.Spec.MeshRef
+	}
+	if meshRef == nil {
+		return ""
+	}
+	return meshRef.Name
+}
+
+// newVirtualNodeMeshMember constructs meshMember for VirtualNode.
+func newVirtualNodeMeshMember(vn *appmesh.VirtualNode) *meshMember {
+	member := &meshMember{
+		obj:         vn,
+		kind:        MemberKindVirtualNode,
+		awsName:     aws.StringValue(vn.Spec.AWSName),
+		awsNameAttr: AttrAppMeshVirtualNode,
+		meshRef:     vn.Spec.MeshRef,
+		podSelector: vn.Spec.PodSelector,
+	}
+	if vn.Spec.ServiceDiscovery != nil {
+		member.cloudMapConfig = vn.Spec.ServiceDiscovery.AWSCloudMap
+	}
+	for _, listener := range vn.Spec.Listeners {
+		member.listenerPorts = append(member.listenerPorts, int64(listener.PortMapping.Port))
+	}
+	return member
+}
+
+// newVirtualGatewayMeshMember constructs meshMember for VirtualGateway.
+func newVirtualGatewayMeshMember(vg *appmesh.VirtualGateway) *meshMember {
+	member := &meshMember{
+		obj:         vg,
+		kind:        MemberKindVirtualGateway,
+		awsName:     aws.StringValue(vg.Spec.AWSName),
+		awsNameAttr: AttrAppMeshVirtualGateway,
+		meshRef:     vg.Spec.MeshRef,
+		podSelector: vg.Spec.PodSelector,
+	}
+	if vg.Spec.ServiceDiscovery != nil {
+		member.cloudMapConfig = vg.Spec.ServiceDiscovery.AWSCloudMap
+	}
+	for _, listener := range vg.Spec.Listeners {
+		member.listenerPorts = append(member.listenerPorts, int64(listener.PortMapping.Port))
+	}
+	return member
+}
+
+// meshMembersLister lists VirtualNodes or VirtualGateways with cloudMap serviceDiscovery in namespace.
+type meshMembersLister func(ctx context.Context, k8sClient client.Client, namespace string) ([]*meshMember, error)
+
+// listVirtualNodeMeshMembers lists VirtualNodes with cloudMap serviceDiscovery in namespace.
+func listVirtualNodeMeshMembers(ctx context.Context, k8sClient client.Client, namespace string) ([]*meshMember, error) {
+	vnList := &appmesh.VirtualNodeList{}
+	if err := k8sClient.List(ctx, vnList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var members []*meshMember
+	for i := range vnList.Items {
+		member := newVirtualNodeMeshMember(&vnList.Items[i])
+		if member.cloudMapConfig != nil {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// listVirtualGatewayMeshMembers lists VirtualGateways with cloudMap serviceDiscovery in namespace.
+func listVirtualGatewayMeshMembers(ctx context.Context, k8sClient client.Client, namespace string) ([]*meshMember, error) {
+	vgList := &appmesh.VirtualGatewayList{}
+	if err := k8sClient.List(ctx, vgList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var members []*meshMember
+	for i := range vgList.Items {
+		member := newVirtualGatewayMeshMember(&vgList.Items[i])
+		if member.cloudMapConfig != nil {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,97 @@
+package cloudmap
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_newVirtualNodeMeshMember(t *testing.T) {
+	cloudMapConfig := &appmesh.AWSCloudMapServiceDiscovery{NamespaceName: "my-ns", ServiceName: "my-svc"}
+	meshRef := &appmesh.MeshReference{Name: "my-mesh"}
+	podSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "my-app"}}
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-vn"},
+		Spec: appmesh.VirtualNodeSpec{
+			AWSName:     aws.String("my-vn_ns"),
+			PodSelector: podSelector,
+			Listeners: []appmesh.Listener{
+				{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"}},
+				{PortMapping: appmesh.PortMapping{Port: 9090, Protocol: "http"}},
+			},
+			ServiceDiscovery: &appmesh.ServiceDiscovery{AWSCloudMap: cloudMapConfig},
+			MeshRef:          meshRef,
+		},
+	}
+	assert.Equal(t, &meshMember{
+		obj:            vn,
+		kind:           "VirtualNode",
+		awsName:        "my-vn_ns",
+		awsNameAttr:    AttrAppMeshVirtualNode,
+		meshRef:        meshRef,
+		podSelector:    podSelector,
+		cloudMapConfig: cloudMapConfig,
+		listenerPorts:  []int64{8080, 9090},
+	}, newVirtualNodeMeshMember(vn))
+}
+
+func Test_newVirtualGatewayMeshMember(t *testing.T) {
+	cloudMapConfig := &appmesh.AWSCloudMapServiceDiscovery{NamespaceName: "my-ns", ServiceName: "my-gw"}
+	meshRef := &appmesh.MeshReference{Name: "my-mesh"}
+	podSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "my-gw"}}
+	vg := &appmesh.VirtualGateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-vg"},
+		Spec: appmesh.VirtualGatewaySpec{
+			AWSName:     aws.String("my-vg_ns"),
+			PodSelector: podSelector,
+			Listeners: []appmesh.VirtualGatewayListener{
+				{PortMapping: appmesh.VirtualGatewayPortMapping{Port: 8088, Protocol: "http"}},
+			},
+			ServiceDiscovery: &appmesh.VirtualGatewayServiceDiscovery{AWSCloudMap: cloudMapConfig},
+			MeshRef:          meshRef,
+		},
+	}
+	assert.Equal(t, &meshMember{
+		obj:            vg,
+		kind:           "VirtualGateway",
+		awsName:        "my-vg_ns",
+		awsNameAttr:    AttrAppMeshVirtualGateway,
+		meshRef:        meshRef,
+		podSelector:    podSelector,
+		cloudMapConfig: cloudMapConfig,
+		listenerPorts:  []int64{8088},
+	}, newVirtualGatewayMeshMember(vg))
+}
+
+func Test_listVirtualGatewayMeshMembers(t *testing.T) {
+	cloudMapSD := &appmesh.VirtualGatewayServiceDiscovery{AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{ServiceName: "my-gw"}}
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithObjects(
+		&appmesh.VirtualGateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vg-cloudmap"},
+			Spec:       appmesh.VirtualGatewaySpec{ServiceDiscovery: cloudMapSD},
+		},
+		&appmesh.VirtualGateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vg-without-cloudmap"},
+		},
+		&appmesh.VirtualGateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other-ns", Name: "vg-cloudmap"},
+			Spec:       appmesh.VirtualGatewaySpec{ServiceDiscovery: cloudMapSD},
+		},
+	).Build()
+
+	members, err := listVirtualGatewayMeshMembers(context.Background(), k8sClient, "ns")
+	assert.NoError(t, err)
+	assert.Len(t, members, 1)
+	assert.Equal(t, "vg-cloudmap", members[0].obj.GetName())
+	assert.Equal(t, "ns", members[0].obj.GetNamespace())
+}
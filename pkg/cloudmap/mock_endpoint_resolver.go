@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: pkg/cloudmap/virtualnode_endpoint_resolver.go
+// Source: pkg/cloudmap/endpoint_resolver.go
 
 // Package cloudmap is a generated GoMock package.
 package cloudmap
@@ -8,38 +8,39 @@ import (
 	context "context"
 	reflect "reflect"
 
-	v1beta2 "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	gomock "github.com/golang/mock/gomock"
 	v1 "k8s.io/api/core/v1"
+	v10 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// MockVirtualNodeEndpointResolver is a mock of VirtualNodeEndpointResolver interface.
-type MockVirtualNodeEndpointResolver struct {
+// MockEndpointResolver is a mock of EndpointResolver interface.
+type MockEndpointResolver struct {
 	ctrl     *gomock.Controller
-	recorder *MockVirtualNodeEndpointResolverMockRecorder
+	recorder *MockEndpointResolverMockRecorder
 }
 
-// MockVirtualNodeEndpointResolverMockRecorder is the mock recorder for MockVirtualNodeEndpointResolver.
-type MockVirtualNodeEndpointResolverMockRecorder struct {
-	mock *MockVirtualNodeEndpointResolver
+// MockEndpointResolverMockRecorder is the mock recorder for MockEndpointResolver.
+type MockEndpointResolverMockRecorder struct {
+	mock *MockEndpointResolver
 }
 
-// NewMockVirtualNodeEndpointResolver creates a new mock instance.
-func NewMockVirtualNodeEndpointResolver(ctrl *gomock.Controller) *MockVirtualNodeEndpointResolver {
-	mock := &MockVirtualNodeEndpointResolver{ctrl: ctrl}
-	mock.recorder = &MockVirtualNodeEndpointResolverMockRecorder{mock}
+// NewMockEndpointResolver creates a new mock instance.
+func NewMockEndpointResolver(ctrl *gomock.Controller) *MockEndpointResolver {
+	mock := &MockEndpointResolver{ctrl: ctrl}
+	mock.recorder = &MockEndpointResolverMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockVirtualNodeEndpointResolver) EXPECT() *MockVirtualNodeEndpointResolverMockRecorder {
+func (m *MockEndpointResolver) EXPECT() *MockEndpointResolverMockRecorder {
 	return m.recorder
 }
 
 // Resolve mocks base method.
-func (m *MockVirtualNodeEndpointResolver) Resolve(ctx context.Context, vn *v1beta2.VirtualNode) ([]*v1.Pod, []*v1.Pod, []*v1.Pod, error) {
+func (m *MockEndpointResolver) Resolve(ctx context.Context, obj client.Object, podSelector *v10.LabelSelector) ([]*v1.Pod, []*v1.Pod, []*v1.Pod, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Resolve", ctx, vn)
+	ret := m.ctrl.Call(m, "Resolve", ctx, obj, podSelector)
 	ret0, _ := ret[0].([]*v1.Pod)
 	ret1, _ := ret[1].([]*v1.Pod)
 	ret2, _ := ret[2].([]*v1.Pod)
@@ -48,7 +49,7 @@ func (m *MockVirtualNodeEndpointResolver) Resolve(ctx context.Context, vn *v1bet
 }
 
 // Resolve indicates an expected call of Resolve.
-func (mr *MockVirtualNodeEndpointResolverMockRecorder) Resolve(ctx, vn interface{}) *gomock.Call {
+func (mr *MockEndpointResolverMockRecorder) Resolve(ctx, obj, podSelector interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockVirtualNodeEndpointResolver)(nil).Resolve), ctx, vn)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockEndpointResolver)(nil).Resolve), ctx, obj, podSelector)
 }
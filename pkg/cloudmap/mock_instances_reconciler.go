@@ -37,15 +37,15 @@ func (m *MockInstancesReconciler) EXPECT() *MockInstancesReconcilerMockRecorder
 }
 
 // Reconcile mocks base method.
-func (m *MockInstancesReconciler) Reconcile(ctx context.Context, ms *v1beta2.Mesh, vn *v1beta2.VirtualNode, service serviceSummary, readyPods, notReadyPods []*v1.Pod, nodeInfoByName map[string]nodeAttributes) error {
+func (m *MockInstancesReconciler) Reconcile(ctx context.Context, ms *v1beta2.Mesh, member *meshMember, service serviceSummary, port int64, readyPods, notReadyPods []*v1.Pod, nodeInfoByName map[string]nodeAttributes) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Reconcile", ctx, ms, vn, service, readyPods, notReadyPods, nodeInfoByName)
+	ret := m.ctrl.Call(m, "Reconcile", ctx, ms, member, service, port, readyPods, notReadyPods, nodeInfoByName)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Reconcile indicates an expected call of Reconcile.
-func (mr *MockInstancesReconcilerMockRecorder) Reconcile(ctx, ms, vn, service, readyPods, notReadyPods, nodeInfoByName interface{}) *gomock.Call {
+func (mr *MockInstancesReconcilerMockRecorder) Reconcile(ctx, ms, member, service, port, readyPods, notReadyPods, nodeInfoByName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockInstancesReconciler)(nil).Reconcile), ctx, ms, vn, service, readyPods, notReadyPods, nodeInfoByName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockInstancesReconciler)(nil).Reconcile), ctx, ms, member, service, port, readyPods, notReadyPods, nodeInfoByName)
 }
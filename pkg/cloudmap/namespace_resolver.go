@@ -0,0 +1,84 @@
+package cloudmap
+
+import (
+	"context"
+	"time"
+
+	services "github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"k8s.io/apimachinery/pkg/util/cache"
+)
+
+const (
+	defaultNamespaceCacheMaxSize = 100
+	defaultNamespaceCacheTTL     = 2 * time.Minute
+)
+
+// NamespaceResolver resolves CloudMap namespaces by name.
+type NamespaceResolver interface {
+	// Resolve returns the CloudMap namespace with namespaceName, returns nil if not found.
+	Resolve(ctx context.Context, namespaceName string) (*servicediscovery.NamespaceSummary, error)
+}
+
+func NewDefaultNamespaceResolver(cloudMapSDK services.CloudMap) NamespaceResolver {
+	return &defaultNamespaceResolver{
+		cloudMapSDK:           cloudMapSDK,
+		namespaceSummaryCache: cache.NewLRUExpireCache(defaultNamespaceCacheMaxSize),
+	}
+}
+
+var _ NamespaceResolver = &defaultNamespaceResolver{}
+
+// defaultNamespaceResolver implements NamespaceResolver
+type defaultNamespaceResolver struct {
+	cloudMapSDK           services.CloudMap
+	namespaceSummaryCache *cache.LRUExpireCache
+}
+
+// Resolve will try to find CloudMapNamespace from cache and AWS(if cache miss). returns nil if not found
+func (r *defaultNamespaceResolver) Resolve(ctx context.Context, namespaceName string) (*servicediscovery.NamespaceSummary, error) {
+	if cachedValue, exists := r.namespaceSummaryCache.Get(namespaceName); exists {
+		cacheItem := cachedValue.(*servicediscovery.NamespaceSummary)
+		return cacheItem, nil
+	}
+
+	nsSummary, err := r.resolveFromAWS(ctx, namespaceName)
+	if err != nil {
+		return nil, err
+	}
+	if nsSummary != nil {
+		r.namespaceSummaryCache.Add(namespaceName, nsSummary, defaultNamespaceCacheTTL)
+	}
+	return nsSummary, nil
+}
+
+// resolveFromAWS will try to find CloudMapNamespace directly from AWS. returns nil if not found
+func (r *defaultNamespaceResolver) resolveFromAWS(ctx context.Context, namespaceName string) (*servicediscovery.NamespaceSummary, error) {
+	listNamespacesInput := &servicediscovery.ListNamespacesInput{}
+	var nsSummary *servicediscovery.NamespaceSummary
+	if err := r.cloudMapSDK.ListNamespacesPagesWithContext(ctx, listNamespacesInput,
+		func(listNamespacesOutput *servicediscovery.ListNamespacesOutput, lastPage bool) bool {
+			for _, ns := range listNamespacesOutput.Namespaces {
+				if awssdk.StringValue(ns.Name) == namespaceName {
+					nsSummary = ns
+					return false
+				}
+			}
+			return true
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return nsSummary, nil
+}
+
+// IsDNSNamespace checks whether instances in CloudMap namespace are discoverable by DNS queries.
+func IsDNSNamespace(nsSummary *servicediscovery.NamespaceSummary) bool {
+	switch awssdk.StringValue(nsSummary.Type) {
+	case servicediscovery.NamespaceTypeDnsPrivate, servicediscovery.NamespaceTypeDnsPublic:
+		return true
+	}
+	return false
+}
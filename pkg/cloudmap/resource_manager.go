@@ -3,14 +3,19 @@ package cloudmap
 import (
 	"context"
 	"fmt"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/servicediscovery"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,8 +28,6 @@ import (
 const (
 	defaultServiceDNSConfigTTL             = 300
 	defaultServiceCustomHCFailureThreshold = 1
-	defaultNamespaceCacheMaxSize           = 100
-	defaultNamespaceCacheTTL               = 2 * time.Minute
 	defaultServiceCacheMaxSize             = 1024
 	defaultServiceCacheTTL                 = 2 * time.Minute
 
@@ -34,6 +37,10 @@ const (
 	nodeAvailabilityZoneLabelKey2 = "topology.kubernetes.io/zone"
 
 	cloudMapServiceAnnotation = "cloudMapServiceARN"
+
+	// multiClusterServiceDescription is the description of cloudMap services created in multi-cluster mode.
+	// such services are shared by controllers in all clusters, and can be deleted by any of them once no cluster uses it.
+	multiClusterServiceDescription = "Shared by appmesh-controller across multiple clusters"
 )
 
 type ResourceManager interface {
@@ -42,57 +49,86 @@ type ResourceManager interface {
 
 	// Cleanup will delete AppMesh CloudMap resources created for VirtualNode.
 	Cleanup(ctx context.Context, vn *appmesh.VirtualNode) error
+
+	// ReconcileVirtualGateway will create/update AppMesh CloudMap Resources for VirtualGateway.
+	ReconcileVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error
+
+	// CleanupVirtualGateway will delete AppMesh CloudMap resources created for VirtualGateway.
+	CleanupVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error
 }
 
 func NewDefaultResourceManager(
 	k8sClient client.Client,
 	cloudMapSDK services.CloudMap,
 	referencesResolver references.Resolver,
-	virtualNodeEndpointResolver VirtualNodeEndpointResolver,
+	endpointResolver EndpointResolver,
+	healthSource HealthSource,
 	instancesReconciler InstancesReconciler,
 	enableCustomHealthCheck bool,
+	defaultDeletionPolicy deletion.Policy,
 	log logr.Logger,
 	cfg Config,
 	ipFamily string) ResourceManager {
 
 	return &defaultResourceManager{
-		config:                      cfg,
-		k8sClient:                   k8sClient,
-		cloudMapSDK:                 cloudMapSDK,
-		referencesResolver:          referencesResolver,
-		virtualNodeEndpointResolver: virtualNodeEndpointResolver,
-		instancesReconciler:         instancesReconciler,
-		enableCustomHealthCheck:     enableCustomHealthCheck,
-		namespaceSummaryCache:       cache.NewLRUExpireCache(defaultNamespaceCacheMaxSize),
-		serviceSummaryCache:         cache.NewLRUExpireCache(defaultServiceCacheMaxSize),
-		log:                         log,
-		ipFamily:                    ipFamily,
+		config:                  cfg,
+		k8sClient:               k8sClient,
+		cloudMapSDK:             cloudMapSDK,
+		referencesResolver:      referencesResolver,
+		endpointResolver:        endpointResolver,
+		healthSource:            healthSource,
+		instancesReconciler:     instancesReconciler,
+		enableCustomHealthCheck: enableCustomHealthCheck,
+		defaultDeletionPolicy:   defaultDeletionPolicy,
+		namespaceResolver:       NewDefaultNamespaceResolver(cloudMapSDK),
+		serviceSummaryCache:     cache.NewLRUExpireCache(defaultServiceCacheMaxSize),
+		log:                     log,
+		ipFamily:                ipFamily,
 	}
 }
 
 // defaultResourceManager implements ResourceManager
 type defaultResourceManager struct {
-	config                      Config
-	k8sClient                   client.Client
-	cloudMapSDK                 services.CloudMap
-	referencesResolver          references.Resolver
-	virtualNodeEndpointResolver VirtualNodeEndpointResolver
-	instancesReconciler         InstancesReconciler
-	enableCustomHealthCheck     bool
-
-	namespaceSummaryCache *cache.LRUExpireCache
-	serviceSummaryCache   *cache.LRUExpireCache
-	log                   logr.Logger
-	ipFamily              string
+	config                  Config
+	k8sClient               client.Client
+	cloudMapSDK             services.CloudMap
+	referencesResolver      references.Resolver
+	endpointResolver        EndpointResolver
+	healthSource            HealthSource
+	instancesReconciler     InstancesReconciler
+	enableCustomHealthCheck bool
+	defaultDeletionPolicy   deletion.Policy
+
+	namespaceResolver   NamespaceResolver
+	serviceSummaryCache *cache.LRUExpireCache
+	log                 logr.Logger
+	ipFamily            string
 }
 
 func (m *defaultResourceManager) Reconcile(ctx context.Context, vn *appmesh.VirtualNode) error {
-	ms, err := m.findMeshDependency(ctx, vn)
+	return m.reconcile(ctx, newVirtualNodeMeshMember(vn))
+}
+
+func (m *defaultResourceManager) Cleanup(ctx context.Context, vn *appmesh.VirtualNode) error {
+	return m.cleanup(ctx, newVirtualNodeMeshMember(vn))
+}
+
+func (m *defaultResourceManager) ReconcileVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	return m.reconcile(ctx, newVirtualGatewayMeshMember(vg))
+}
+
+func (m *defaultResourceManager) CleanupVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	return m.cleanup(ctx, newVirtualGatewayMeshMember(vg))
+}
+
+// reconcile registers pods of VirtualNode or VirtualGateway into its cloudMap services.
+func (m *defaultResourceManager) reconcile(ctx context.Context, member *meshMember) error {
+	ms, err := m.findMeshDependency(ctx, member)
 	if err != nil {
 		return err
 	}
-	cloudMapConfig := vn.Spec.ServiceDiscovery.AWSCloudMap
-	nsSummary, err := m.findCloudMapNamespace(ctx, cloudMapConfig.NamespaceName)
+	cloudMapConfig := member.cloudMapConfig
+	nsSummary, err := m.namespaceResolver.Resolve(ctx, cloudMapConfig.NamespaceName)
 	if err != nil {
 		return err
 	}
@@ -103,49 +139,157 @@ func (m *defaultResourceManager) Reconcile(ctx context.Context, vn *appmesh.Virt
 	if err != nil {
 		return err
 	}
+	var dnsConfigMismatchedServiceNames []string
 	if svcSummary == nil {
-		svcSummary, err = m.createCloudMapService(ctx, vn, nsSummary, cloudMapConfig.ServiceName, m.config.CloudMapServiceTTL)
+		svcSummary, err = m.createCloudMapService(ctx, string(member.obj.GetUID()), nsSummary, cloudMapConfig.ServiceName, cloudMapConfig)
 		if err != nil {
 			return err
 		}
+	} else {
+		if m.isCloudMapServiceDNSConfigMismatched(svcSummary, cloudMapConfig) {
+			dnsConfigMismatchedServiceNames = append(dnsConfigMismatchedServiceNames, cloudMapConfig.ServiceName)
+		}
+		if svcSummary, err = m.reconcileCloudMapServiceDNSConfig(ctx, string(member.obj.GetUID()), nsSummary, cloudMapConfig.ServiceName, svcSummary, cloudMapConfig); err != nil {
+			return err
+		}
 	}
 
-	if err := m.updateCRDVirtualNode(ctx, vn, svcSummary); err != nil {
+	if err := m.updateServiceARNAnnotation(ctx, member, svcSummary); err != nil {
 		return err
 	}
 
 	var readyPods []*corev1.Pod
 	var notReadyPods []*corev1.Pod
-	if vn.Spec.PodSelector != nil {
-		readyPods, notReadyPods, _, err = m.virtualNodeEndpointResolver.Resolve(ctx, vn)
+	if member.podSelector != nil {
+		readyPods, notReadyPods, _, err = m.endpointResolver.Resolve(ctx, member.obj, member.podSelector)
 		if err != nil {
 			return err
 		}
-		m.log.V(1).Info("resolved VirtualNode endpoints",
+		m.healthSource.TrackPods(member.obj, member.kind, primaryListenerPort(member), append(append([]*corev1.Pod{}, readyPods...), notReadyPods...))
+		readyPods, notReadyPods, err = evaluatePodsHealth(ctx, m.healthSource, primaryListenerPort(member), readyPods, notReadyPods)
+		if err != nil {
+			return err
+		}
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("resolved "+member.kind+" endpoints",
 			"readyPods", len(readyPods),
 			"notReadyPods", len(notReadyPods),
 		)
 	} else {
-		m.log.V(1).Info("VirtualNode does not have a pod selector, no endpoints")
+		m.healthSource.TrackPods(member.obj, member.kind, primaryListenerPort(member), nil)
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info(member.kind + " does not have a pod selector, no endpoints")
 	}
 
 	nodeInfoByName := m.getClusterNodeInfo(ctx)
-	if err := m.instancesReconciler.Reconcile(ctx, ms, vn, *svcSummary, readyPods, notReadyPods, nodeInfoByName); err != nil {
+	if err := m.instancesReconciler.Reconcile(ctx, ms, member, *svcSummary, primaryListenerPort(member), readyPods, notReadyPods, nodeInfoByName); err != nil {
+		return err
+	}
+	listenerDNSConfigMismatchedServiceNames, err := m.reconcileListenerServices(ctx, ms, member, nsSummary, readyPods, notReadyPods, nodeInfoByName)
+	if err != nil {
 		return err
 	}
+	dnsConfigMismatchedServiceNames = append(dnsConfigMismatchedServiceNames, listenerDNSConfigMismatchedServiceNames...)
+	return m.updateDNSConfigSyncedCondition(ctx, member, dnsConfigMismatchedServiceNames)
+}
 
-	return nil
+// reconcileListenerServices registers pods of VirtualNode or VirtualGateway into a cloudMap service per additional listener,
+// and deletes the cloudMap services of listeners that no longer exist.
+// returns the names of listener services whose DNS configuration mismatches spec, see isCloudMapServiceDNSConfigMismatched.
+func (m *defaultResourceManager) reconcileListenerServices(ctx context.Context, ms *appmesh.Mesh, member *meshMember, nsSummary *servicediscovery.NamespaceSummary,
+	readyPods []*corev1.Pod, notReadyPods []*corev1.Pod, nodeInfoByName map[string]nodeAttributes) ([]string, error) {
+	desiredPorts := additionalListenerPorts(member)
+	existingPorts := listenerServicePorts(member)
+	// record ports before creating services, so that services are always cleaned up even if we fail halfway.
+	if err := m.updateListenerServicePorts(ctx, member, existingPorts.Union(desiredPorts)); err != nil {
+		return nil, err
+	}
+
+	cloudMapConfig := member.cloudMapConfig
+	serviceName := cloudMapConfig.ServiceName
+	var dnsConfigMismatchedServiceNames []string
+	for _, port := range desiredPorts.List() {
+		listenerServiceName := buildListenerServiceName(serviceName, port)
+		svcSummary, err := m.findCloudMapService(ctx, nsSummary, listenerServiceName)
+		if err != nil {
+			return nil, err
+		}
+		if svcSummary == nil {
+			svcSummary, err = m.createCloudMapService(ctx, buildListenerServiceCreatorRequestID(member, port), nsSummary, listenerServiceName, cloudMapConfig)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			if m.isCloudMapServiceDNSConfigMismatched(svcSummary, cloudMapConfig) {
+				dnsConfigMismatchedServiceNames = append(dnsConfigMismatchedServiceNames, listenerServiceName)
+			}
+			if svcSummary, err = m.reconcileCloudMapServiceDNSConfig(ctx, buildListenerServiceCreatorRequestID(member, port), nsSummary, listenerServiceName, svcSummary, cloudMapConfig); err != nil {
+				return nil, err
+			}
+		}
+		if err := m.instancesReconciler.Reconcile(ctx, ms, member, *svcSummary, port, readyPods, notReadyPods, nodeInfoByName); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, port := range existingPorts.Difference(desiredPorts).List() {
+		if err := m.cleanupListenerService(ctx, ms, member, nsSummary, port); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.updateListenerServicePorts(ctx, member, desiredPorts); err != nil {
+		return nil, err
+	}
+	return dnsConfigMismatchedServiceNames, nil
 }
 
-func (m *defaultResourceManager) Cleanup(ctx context.Context, vn *appmesh.VirtualNode) error {
-	ms, err := m.findMeshDependency(ctx, vn)
+// cleanupListenerService deregisters pods of VirtualNode or VirtualGateway from the cloudMap service of listener with port, and deletes that service.
+func (m *defaultResourceManager) cleanupListenerService(ctx context.Context, ms *appmesh.Mesh, member *meshMember, nsSummary *servicediscovery.NamespaceSummary, port int64) error {
+	listenerServiceName := buildListenerServiceName(member.cloudMapConfig.ServiceName, port)
+	svcSummary, err := m.findCloudMapService(ctx, nsSummary, listenerServiceName)
+	if err != nil {
+		return err
+	}
+	if svcSummary == nil {
+		return nil
+	}
+	if err := m.instancesReconciler.Reconcile(ctx, ms, member, *svcSummary, port, nil, nil, nil); err != nil {
+		return err
+	}
+	return m.deleteCloudMapService(ctx, buildListenerServiceCreatorRequestID(member, port), nsSummary, svcSummary)
+}
+
+// cleanup deregisters pods of VirtualNode or VirtualGateway from its cloudMap services, and deletes the services it owns.
+// Services and their instances are left in place if VirtualNode or VirtualGateway is retained by its deletion policy.
+func (m *defaultResourceManager) cleanup(ctx context.Context, member *meshMember) error {
+	m.healthSource.TrackPods(member.obj, member.kind, primaryListenerPort(member), nil)
+	deletionPolicy, err := deletion.PolicyOf(member.obj, m.defaultDeletionPolicy)
 	if err != nil {
 		return err
 	}
-	cloudMapConfig := vn.Spec.ServiceDiscovery.AWSCloudMap
-	nsSummary, err := m.findCloudMapNamespace(ctx, cloudMapConfig.NamespaceName)
+	if deletionPolicy == deletion.PolicyRetain {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain cloudMap services by deletion policy",
+			"kind", member.kind,
+			"object", k8s.NamespacedName(member.obj),
+			"serviceName", member.cloudMapConfig.ServiceName,
+		)
+		return nil
+	}
+	ms, err := m.findMeshDependency(ctx, member)
 	if err != nil {
-		if !m.isCloudMapServiceCreated(ctx, vn) {
+		// mesh is deleted with Orphan membersDeletionPolicy, cloudMap services are orphaned along with the AppMesh mesh.
+		if apierrors.IsNotFound(err) {
+			tracing.LoggerFromContext(ctx, m.log).Info("skip cloudMap services cleanup since mesh is deleted",
+				"kind", member.kind,
+				"object", k8s.NamespacedName(member.obj),
+				"serviceName", member.cloudMapConfig.ServiceName,
+			)
+			return nil
+		}
+		return err
+	}
+	cloudMapConfig := member.cloudMapConfig
+	nsSummary, err := m.namespaceResolver.Resolve(ctx, cloudMapConfig.NamespaceName)
+	if err != nil {
+		if !m.isCloudMapServiceCreated(ctx, member) {
 			return nil
 		}
 		return err
@@ -157,70 +301,38 @@ func (m *defaultResourceManager) Cleanup(ctx context.Context, vn *appmesh.Virtua
 	if err != nil {
 		return err
 	}
+	listenerPorts := listenerServicePorts(member).Union(additionalListenerPorts(member))
+	for _, port := range listenerPorts.List() {
+		if err := m.cleanupListenerService(ctx, ms, member, nsSummary, port); err != nil {
+			return err
+		}
+	}
 	if svcSummary == nil {
 		return nil
 	}
 
-	if err := m.instancesReconciler.Reconcile(ctx, ms, vn, *svcSummary, nil, nil, nil); err != nil {
+	if err := m.instancesReconciler.Reconcile(ctx, ms, member, *svcSummary, primaryListenerPort(member), nil, nil, nil); err != nil {
 		return err
 	}
 
-	if err := m.deleteCloudMapService(ctx, vn, nsSummary, svcSummary); err != nil {
+	if err := m.deleteCloudMapService(ctx, string(member.obj.GetUID()), nsSummary, svcSummary); err != nil {
 		return err
 	}
 	return nil
 }
 
-// findMeshDependency find the Mesh dependency for this virtualNode.
-func (m *defaultResourceManager) findMeshDependency(ctx context.Context, vn *appmesh.VirtualNode) (*appmesh.Mesh, error) {
-	if vn.Spec.MeshRef == nil {
+// findMeshDependency find the Mesh dependency for this virtualNode or virtualGateway.
+func (m *defaultResourceManager) findMeshDependency(ctx context.Context, member *meshMember) (*appmesh.Mesh, error) {
+	if member.meshRef == nil {
 		return nil, errors.Errorf("meshRef shouldn't be nil, please check webhook setup")
 	}
-	ms, err := m.referencesResolver.ResolveMeshReference(ctx, *vn.Spec.MeshRef)
+	ms, err := m.referencesResolver.ResolveMeshReference(ctx, *member.meshRef)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to resolve meshRef")
 	}
 	return ms, nil
 }
 
-// findCloudMapNamespaceFromAWS will try to find CloudMapNamespace from cache and AWS(if cache miss). returns nil if not found
-func (m *defaultResourceManager) findCloudMapNamespace(ctx context.Context, namespaceName string) (*servicediscovery.NamespaceSummary, error) {
-	if cachedValue, exists := m.namespaceSummaryCache.Get(namespaceName); exists {
-		cacheItem := cachedValue.(*servicediscovery.NamespaceSummary)
-		return cacheItem, nil
-	}
-
-	nsSummary, err := m.findCloudMapNamespaceFromAWS(ctx, namespaceName)
-	if err != nil {
-		return nil, err
-	}
-	if nsSummary != nil {
-		m.namespaceSummaryCache.Add(namespaceName, nsSummary, defaultNamespaceCacheTTL)
-	}
-	return nsSummary, nil
-}
-
-// findCloudMapNamespaceFromAWS will try to find CloudMapNamespace directly from AWS. returns nil if not found
-func (m *defaultResourceManager) findCloudMapNamespaceFromAWS(ctx context.Context, namespaceName string) (*servicediscovery.NamespaceSummary, error) {
-	listNamespacesInput := &servicediscovery.ListNamespacesInput{}
-	var nsSummary *servicediscovery.NamespaceSummary
-	if err := m.cloudMapSDK.ListNamespacesPagesWithContext(ctx, listNamespacesInput,
-		func(listNamespacesOutput *servicediscovery.ListNamespacesOutput, lastPage bool) bool {
-			for _, ns := range listNamespacesOutput.Namespaces {
-				if awssdk.StringValue(ns.Name) == namespaceName {
-					nsSummary = ns
-					return false
-				}
-			}
-			return true
-		},
-	); err != nil {
-		return nil, err
-	}
-
-	return nsSummary, nil
-}
-
 func (m *defaultResourceManager) findCloudMapService(ctx context.Context, nsSummary *servicediscovery.NamespaceSummary, serviceName string) (*serviceSummary, error) {
 	cacheKey := m.buildCloudMapServiceSummaryCacheKey(nsSummary, serviceName)
 	if cachedValue, exists := m.serviceSummaryCache.Get(cacheKey); exists {
@@ -237,6 +349,7 @@ func (m *defaultResourceManager) findCloudMapService(ctx context.Context, nsSumm
 			serviceID:               awssdk.StringValue(sdkSVCSummary.Id),
 			serviceARN:              sdkSVCSummary.Arn,
 			healthCheckCustomConfig: sdkSVCSummary.HealthCheckCustomConfig,
+			dnsConfig:               sdkSVCSummary.DnsConfig,
 		}
 		m.serviceSummaryCache.Add(cacheKey, svcSummary, defaultServiceCacheTTL)
 		return svcSummary, nil
@@ -272,17 +385,18 @@ func (m *defaultResourceManager) findCloudMapServiceFromAWS(ctx context.Context,
 	return sdkSVCSummary, nil
 }
 
-func (m *defaultResourceManager) createCloudMapService(ctx context.Context, vn *appmesh.VirtualNode, nsSummary *servicediscovery.NamespaceSummary, serviceName string,
-	cloudMapTTL int64) (*serviceSummary, error) {
+// createCloudMapService creates a cloudMap service owned by creatorRequestID.
+func (m *defaultResourceManager) createCloudMapService(ctx context.Context, creatorRequestID string, nsSummary *servicediscovery.NamespaceSummary, serviceName string,
+	cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) (*serviceSummary, error) {
 	switch awssdk.StringValue(nsSummary.Type) {
 	case servicediscovery.NamespaceTypeDnsPrivate:
-		sdkService, err := m.createCloudMapServiceUnderPrivateDNSNamespace(ctx, vn, nsSummary, serviceName, cloudMapTTL)
+		sdkService, err := m.createCloudMapServiceUnderPrivateDNSNamespace(ctx, creatorRequestID, nsSummary, serviceName, cloudMapConfig)
 		if err != nil {
 			return nil, err
 		}
 		return m.addCloudMapServiceToServiceSummaryCache(nsSummary, sdkService), nil
 	case servicediscovery.NamespaceTypeHttp:
-		sdkService, err := m.createCloudMapServiceUnderHTTPNamespace(ctx, vn, nsSummary, serviceName)
+		sdkService, err := m.createCloudMapServiceUnderHTTPNamespace(ctx, creatorRequestID, nsSummary, serviceName, cloudMapConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -295,14 +409,15 @@ func (m *defaultResourceManager) createCloudMapService(ctx context.Context, vn *
 	}
 }
 
-func (m *defaultResourceManager) deleteCloudMapService(ctx context.Context, vn *appmesh.VirtualNode, nsSummary *servicediscovery.NamespaceSummary, svcSummary *serviceSummary) error {
+// deleteCloudMapService deletes the cloudMap service if it's owned by creatorRequestID.
+func (m *defaultResourceManager) deleteCloudMapService(ctx context.Context, creatorRequestID string, nsSummary *servicediscovery.NamespaceSummary, svcSummary *serviceSummary) error {
 	getServiceInput := &servicediscovery.GetServiceInput{Id: awssdk.String(svcSummary.serviceID)}
 	getServiceOutput, err := m.cloudMapSDK.GetServiceWithContext(ctx, getServiceInput)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get cloudMap service")
 	}
-	if !m.isCloudMapServiceOwnedByVirtualNode(ctx, getServiceOutput.Service, vn) {
-		m.log.V(1).Info("skip cloudMap service deletion since it's not owned",
+	if !m.isCloudMapServiceOwnedBy(ctx, getServiceOutput.Service, creatorRequestID) {
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip cloudMap service deletion since it's not owned",
 			"namespaceName", awssdk.StringValue(nsSummary.Name),
 			"namespaceID", awssdk.StringValue(nsSummary.Id),
 			"serviceName", awssdk.StringValue(getServiceOutput.Service.Name),
@@ -310,6 +425,21 @@ func (m *defaultResourceManager) deleteCloudMapService(ctx context.Context, vn *
 		)
 		return nil
 	}
+	if m.config.MultiClusterEnabled() {
+		usedByOtherClusters, err := m.isCloudMapServiceUsedByOtherClusters(ctx, svcSummary)
+		if err != nil {
+			return err
+		}
+		if usedByOtherClusters {
+			tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip cloudMap service deletion since it's used by other clusters",
+				"namespaceName", awssdk.StringValue(nsSummary.Name),
+				"namespaceID", awssdk.StringValue(nsSummary.Id),
+				"serviceName", awssdk.StringValue(getServiceOutput.Service.Name),
+				"serviceID", awssdk.StringValue(getServiceOutput.Service.Id),
+			)
+			return nil
+		}
+	}
 
 	deleteServiceInput := &servicediscovery.DeleteServiceInput{
 		Id: awssdk.String(svcSummary.serviceID),
@@ -339,33 +469,15 @@ func (m *defaultResourceManager) deleteCloudMapService(ctx context.Context, vn *
 	return nil
 }
 
-func (m *defaultResourceManager) createCloudMapServiceUnderPrivateDNSNamespace(ctx context.Context, vn *appmesh.VirtualNode,
-	nsSummary *servicediscovery.NamespaceSummary, serviceName string, cloudMapTTL int64) (*servicediscovery.Service, error) {
-	creatorRequestID := string(vn.UID)
-	var dnsRecordType string
-	if m.ipFamily == IPv6 {
-		dnsRecordType = servicediscovery.RecordTypeAaaa
-	} else {
-		dnsRecordType = servicediscovery.RecordTypeA
-	}
+func (m *defaultResourceManager) createCloudMapServiceUnderPrivateDNSNamespace(ctx context.Context, creatorRequestID string,
+	nsSummary *servicediscovery.NamespaceSummary, serviceName string, cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) (*servicediscovery.Service, error) {
 	createServiceInput := &servicediscovery.CreateServiceInput{
-		CreatorRequestId: awssdk.String(creatorRequestID),
-		NamespaceId:      nsSummary.Id,
-		Name:             awssdk.String(serviceName),
-		DnsConfig: &servicediscovery.DnsConfig{
-			RoutingPolicy: awssdk.String(servicediscovery.RoutingPolicyMultivalue),
-			DnsRecords: []*servicediscovery.DnsRecord{
-				{
-					Type: awssdk.String(dnsRecordType),
-					TTL:  awssdk.Int64(cloudMapTTL),
-				},
-			},
-		},
-	}
-	if m.enableCustomHealthCheck {
-		createServiceInput.HealthCheckCustomConfig = &servicediscovery.HealthCheckCustomConfig{
-			FailureThreshold: awssdk.Int64(defaultServiceCustomHCFailureThreshold),
-		}
+		CreatorRequestId:        awssdk.String(creatorRequestID),
+		Description:             m.buildServiceDescription(),
+		NamespaceId:             nsSummary.Id,
+		Name:                    awssdk.String(serviceName),
+		DnsConfig:               m.buildServiceDNSConfig(cloudMapConfig),
+		HealthCheckCustomConfig: m.buildServiceHealthCheckCustomConfig(cloudMapConfig),
 	}
 
 	resp, err := m.cloudMapSDK.CreateServiceWithContext(ctx, createServiceInput)
@@ -375,18 +487,14 @@ func (m *defaultResourceManager) createCloudMapServiceUnderPrivateDNSNamespace(c
 	return resp.Service, nil
 }
 
-func (m *defaultResourceManager) createCloudMapServiceUnderHTTPNamespace(ctx context.Context, vn *appmesh.VirtualNode,
-	nsSummary *servicediscovery.NamespaceSummary, serviceName string) (*servicediscovery.Service, error) {
-	creatorRequestID := string(vn.UID)
+func (m *defaultResourceManager) createCloudMapServiceUnderHTTPNamespace(ctx context.Context, creatorRequestID string,
+	nsSummary *servicediscovery.NamespaceSummary, serviceName string, cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) (*servicediscovery.Service, error) {
 	createServiceInput := &servicediscovery.CreateServiceInput{
-		CreatorRequestId: awssdk.String(creatorRequestID),
-		NamespaceId:      nsSummary.Id,
-		Name:             awssdk.String(serviceName),
-	}
-	if m.enableCustomHealthCheck {
-		createServiceInput.HealthCheckCustomConfig = &servicediscovery.HealthCheckCustomConfig{
-			FailureThreshold: awssdk.Int64(defaultServiceCustomHCFailureThreshold),
-		}
+		CreatorRequestId:        awssdk.String(creatorRequestID),
+		Description:             m.buildServiceDescription(),
+		NamespaceId:             nsSummary.Id,
+		Name:                    awssdk.String(serviceName),
+		HealthCheckCustomConfig: m.buildServiceHealthCheckCustomConfig(cloudMapConfig),
 	}
 	resp, err := m.cloudMapSDK.CreateServiceWithContext(ctx, createServiceInput)
 	if err != nil {
@@ -395,12 +503,177 @@ func (m *defaultResourceManager) createCloudMapServiceUnderHTTPNamespace(ctx con
 	return resp.Service, nil
 }
 
+// buildServiceDescription builds the description of cloudMap service, which marks services shared in multi-cluster mode.
+func (m *defaultResourceManager) buildServiceDescription() *string {
+	if !m.config.MultiClusterEnabled() {
+		return nil
+	}
+	return awssdk.String(multiClusterServiceDescription)
+}
+
+// buildServiceDNSConfig builds the DNS configuration of cloudMap service in DNS namespace.
+func (m *defaultResourceManager) buildServiceDNSConfig(cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) *servicediscovery.DnsConfig {
+	ttl := m.config.CloudMapServiceTTL
+	routingPolicy := servicediscovery.RoutingPolicyMultivalue
+	recordTypes := []string{servicediscovery.RecordTypeA}
+	if m.ipFamily == IPv6 {
+		recordTypes = []string{servicediscovery.RecordTypeAaaa}
+	}
+	if dnsConfig := cloudMapConfig.DNSConfig; dnsConfig != nil {
+		if dnsConfig.TTL != nil {
+			ttl = *dnsConfig.TTL
+		}
+		if dnsConfig.RoutingPolicy != nil {
+			routingPolicy = string(*dnsConfig.RoutingPolicy)
+		}
+		if len(dnsConfig.RecordTypes) != 0 {
+			recordTypes = nil
+			for _, recordType := range dnsConfig.RecordTypes {
+				recordTypes = append(recordTypes, string(recordType))
+			}
+		}
+	}
+
+	dnsRecords := make([]*servicediscovery.DnsRecord, 0, len(recordTypes))
+	for _, recordType := range recordTypes {
+		dnsRecords = append(dnsRecords, &servicediscovery.DnsRecord{
+			Type: awssdk.String(recordType),
+			TTL:  awssdk.Int64(ttl),
+		})
+	}
+	return &servicediscovery.DnsConfig{
+		RoutingPolicy: awssdk.String(routingPolicy),
+		DnsRecords:    dnsRecords,
+	}
+}
+
+// buildServiceHealthCheckCustomConfig builds the custom health check configuration of cloudMap service.
+// returns nil if custom health check is disabled.
+func (m *defaultResourceManager) buildServiceHealthCheckCustomConfig(cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) *servicediscovery.HealthCheckCustomConfig {
+	if !m.enableCustomHealthCheck {
+		return nil
+	}
+	failureThreshold := int64(defaultServiceCustomHCFailureThreshold)
+	if cloudMapConfig.CustomHealthCheckFailureThreshold != nil {
+		failureThreshold = *cloudMapConfig.CustomHealthCheckFailureThreshold
+	}
+	return &servicediscovery.HealthCheckCustomConfig{
+		FailureThreshold: awssdk.Int64(failureThreshold),
+	}
+}
+
+// isCloudMapServiceDNSConfigMismatched checks whether existing cloudMap service has a different routing policy or DNS record types than specified by
+// VirtualNode or VirtualGateway, which can't be changed once the service is created.
+func (m *defaultResourceManager) isCloudMapServiceDNSConfigMismatched(svcSummary *serviceSummary, cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) bool {
+	dnsConfig := cloudMapConfig.DNSConfig
+	if svcSummary.dnsConfig == nil || dnsConfig == nil {
+		return false
+	}
+	desiredDNSConfig := m.buildServiceDNSConfig(cloudMapConfig)
+	if dnsConfig.RoutingPolicy != nil && awssdk.StringValue(svcSummary.dnsConfig.RoutingPolicy) != awssdk.StringValue(desiredDNSConfig.RoutingPolicy) {
+		return true
+	}
+	return len(dnsConfig.RecordTypes) != 0 && !dnsRecordTypes(svcSummary.dnsConfig).Equal(dnsRecordTypes(desiredDNSConfig))
+}
+
+// reconcileCloudMapServiceDNSConfig updates the TTL of existing cloudMap service's DNS records to the TTL specified by VirtualNode,
+// or to the default TTL if it's not specified, which is only restored on services owned by creatorRequestID.
+// other DNS settings can't be changed once the service is created, see isCloudMapServiceDNSConfigMismatched.
+func (m *defaultResourceManager) reconcileCloudMapServiceDNSConfig(ctx context.Context, creatorRequestID string, nsSummary *servicediscovery.NamespaceSummary,
+	serviceName string, svcSummary *serviceSummary, cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery) (*serviceSummary, error) {
+	if svcSummary.dnsConfig == nil {
+		return svcSummary, nil
+	}
+	desiredTTL := m.config.CloudMapServiceTTL
+	ttlSpecified := cloudMapConfig.DNSConfig != nil && cloudMapConfig.DNSConfig.TTL != nil
+	if ttlSpecified {
+		desiredTTL = *cloudMapConfig.DNSConfig.TTL
+	}
+	ttlChanged := false
+	dnsRecords := make([]*servicediscovery.DnsRecord, 0, len(svcSummary.dnsConfig.DnsRecords))
+	for _, record := range svcSummary.dnsConfig.DnsRecords {
+		if awssdk.Int64Value(record.TTL) != desiredTTL {
+			ttlChanged = true
+		}
+		dnsRecords = append(dnsRecords, &servicediscovery.DnsRecord{
+			Type: record.Type,
+			TTL:  awssdk.Int64(desiredTTL),
+		})
+	}
+	if !ttlChanged {
+		return svcSummary, nil
+	}
+	if !ttlSpecified {
+		var err error
+		if svcSummary, err = m.describeCloudMapServiceOwner(ctx, nsSummary, serviceName, svcSummary); err != nil {
+			return nil, err
+		}
+		owner := &servicediscovery.Service{CreatorRequestId: svcSummary.creatorRequestID, Description: svcSummary.description}
+		if !m.isCloudMapServiceOwnedBy(ctx, owner, creatorRequestID) {
+			return svcSummary, nil
+		}
+	}
+	if _, err := m.cloudMapSDK.UpdateServiceWithContext(ctx, &servicediscovery.UpdateServiceInput{
+		Id: awssdk.String(svcSummary.serviceID),
+		Service: &servicediscovery.ServiceChange{
+			DnsConfig: &servicediscovery.DnsConfigChange{
+				DnsRecords: dnsRecords,
+			},
+		},
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to update cloudMap service")
+	}
+	updatedSVCSummary := &serviceSummary{
+		serviceID:               svcSummary.serviceID,
+		serviceARN:              svcSummary.serviceARN,
+		healthCheckCustomConfig: svcSummary.healthCheckCustomConfig,
+		dnsConfig: &servicediscovery.DnsConfig{
+			NamespaceId:   svcSummary.dnsConfig.NamespaceId,
+			RoutingPolicy: svcSummary.dnsConfig.RoutingPolicy,
+			DnsRecords:    dnsRecords,
+		},
+		creatorRequestID: svcSummary.creatorRequestID,
+		description:      svcSummary.description,
+	}
+	m.serviceSummaryCache.Add(m.buildCloudMapServiceSummaryCacheKey(nsSummary, serviceName), updatedSVCSummary, defaultServiceCacheTTL)
+	return updatedSVCSummary, nil
+}
+
+// describeCloudMapServiceOwner returns svcSummary with the creatorRequestID and description of cloudMap service,
+// which are fetched with GetService unless already known.
+func (m *defaultResourceManager) describeCloudMapServiceOwner(ctx context.Context, nsSummary *servicediscovery.NamespaceSummary, serviceName string,
+	svcSummary *serviceSummary) (*serviceSummary, error) {
+	if svcSummary.creatorRequestID != nil {
+		return svcSummary, nil
+	}
+	getServiceOutput, err := m.cloudMapSDK.GetServiceWithContext(ctx, &servicediscovery.GetServiceInput{Id: awssdk.String(svcSummary.serviceID)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get cloudMap service")
+	}
+	describedSVCSummary := *svcSummary
+	describedSVCSummary.creatorRequestID = awssdk.String(awssdk.StringValue(getServiceOutput.Service.CreatorRequestId))
+	describedSVCSummary.description = getServiceOutput.Service.Description
+	m.serviceSummaryCache.Add(m.buildCloudMapServiceSummaryCacheKey(nsSummary, serviceName), &describedSVCSummary, defaultServiceCacheTTL)
+	return &describedSVCSummary, nil
+}
+
+func dnsRecordTypes(dnsConfig *servicediscovery.DnsConfig) sets.String {
+	recordTypes := sets.NewString()
+	for _, record := range dnsConfig.DnsRecords {
+		recordTypes.Insert(awssdk.StringValue(record.Type))
+	}
+	return recordTypes
+}
+
 func (m *defaultResourceManager) addCloudMapServiceToServiceSummaryCache(nsSummary *servicediscovery.NamespaceSummary, service *servicediscovery.Service) *serviceSummary {
 	cacheKey := m.buildCloudMapServiceSummaryCacheKey(nsSummary, awssdk.StringValue(service.Name))
 	svcSummary := &serviceSummary{
 		serviceID:               awssdk.StringValue(service.Id),
 		serviceARN:              service.Arn,
 		healthCheckCustomConfig: service.HealthCheckCustomConfig,
+		dnsConfig:               service.DnsConfig,
+		creatorRequestID:        service.CreatorRequestId,
+		description:             service.Description,
 	}
 	m.serviceSummaryCache.Add(cacheKey, svcSummary, defaultServiceCacheTTL)
 	return svcSummary
@@ -411,10 +684,36 @@ func (m *defaultResourceManager) removeCloudMapServiceFromServiceSummaryCache(ns
 	m.serviceSummaryCache.Remove(cacheKey)
 }
 
-// isCloudMapServiceOwnedByVirtualNode checks whether an CloudMap service is owned by VirtualNode.
-// if it's owned, VirtualNode deletion is responsible for deleting the CloudMap Service
-func (m *defaultResourceManager) isCloudMapServiceOwnedByVirtualNode(ctx context.Context, svc *servicediscovery.Service, vn *appmesh.VirtualNode) bool {
-	return awssdk.StringValue(svc.CreatorRequestId) == string(vn.UID)
+// isCloudMapServiceOwnedBy checks whether an CloudMap service is created with creatorRequestID.
+// VirtualNode or VirtualGateway's CloudMap service is created with its UID, and its additional listeners' CloudMap services are created with UID and port.
+// In multi-cluster mode, services created by controller in any cluster are shared and considered owned.
+// if it's owned, VirtualNode or VirtualGateway deletion is responsible for deleting the CloudMap Service
+func (m *defaultResourceManager) isCloudMapServiceOwnedBy(ctx context.Context, svc *servicediscovery.Service, creatorRequestID string) bool {
+	if awssdk.StringValue(svc.CreatorRequestId) == creatorRequestID {
+		return true
+	}
+	return m.config.MultiClusterEnabled() && awssdk.StringValue(svc.Description) == multiClusterServiceDescription
+}
+
+// isCloudMapServiceUsedByOtherClusters checks whether an CloudMap service still have instances registered by other clusters.
+// instances without cluster attribute are registered by controllers not in multi-cluster mode, and are considered as other clusters'.
+func (m *defaultResourceManager) isCloudMapServiceUsedByOtherClusters(ctx context.Context, svcSummary *serviceSummary) (bool, error) {
+	usedByOtherClusters := false
+	listInstancesInput := &servicediscovery.ListInstancesInput{
+		ServiceId: awssdk.String(svcSummary.serviceID),
+	}
+	if err := m.cloudMapSDK.ListInstancesPagesWithContext(ctx, listInstancesInput, func(output *servicediscovery.ListInstancesOutput, lastPage bool) bool {
+		for _, instance := range output.Instances {
+			if awssdk.StringValue(instance.Attributes[AttrAppMeshCluster]) != m.config.ClusterID {
+				usedByOtherClusters = true
+				return false
+			}
+		}
+		return true
+	}); err != nil {
+		return false, errors.Wrapf(err, "failed to list cloudMap service instances")
+	}
+	return usedByOtherClusters, nil
 }
 
 func (m *defaultResourceManager) buildCloudMapServiceSummaryCacheKey(nsSummary *servicediscovery.NamespaceSummary, serviceName string) string {
@@ -427,7 +726,7 @@ func (m *defaultResourceManager) getClusterNodeInfo(ctx context.Context) map[str
 		return nil
 	}
 
-	m.log.V(1).Info("Listed Nodes", "count", len(nodeList.Items))
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("Listed Nodes", "count", len(nodeList.Items))
 	nodeInfoByName := make(map[string]nodeAttributes, len(nodeList.Items))
 	for i := range nodeList.Items {
 		var nodeRegion string
@@ -443,39 +742,96 @@ func (m *defaultResourceManager) getClusterNodeInfo(ctx context.Context) map[str
 		nodeAttrs := nodeAttributes{
 			region:           nodeRegion,
 			availabilityZone: nodeAvailabilityZone,
+			labels:           node.Labels,
 		}
 		nodeInfoByName[node.Name] = nodeAttrs
 	}
 	return nodeInfoByName
 }
 
-func (m *defaultResourceManager) isCloudMapServiceCreated(ctx context.Context, vn *appmesh.VirtualNode) bool {
-	oldVN := vn.DeepCopy()
-	vnAnnotations := oldVN.Annotations
+func (m *defaultResourceManager) isCloudMapServiceCreated(ctx context.Context, member *meshMember) bool {
+	_, ok := member.obj.GetAnnotations()[cloudMapServiceAnnotation]
+	return ok
+}
 
-	for key, _ := range vnAnnotations {
-		if key == cloudMapServiceAnnotation {
-			return true
-		}
+// updateServiceARNAnnotation records the ARN of cloudMap service on VirtualNode or VirtualGateway.
+func (m *defaultResourceManager) updateServiceARNAnnotation(ctx context.Context, member *meshMember, svcSummary *serviceSummary) error {
+	if svcSummary.serviceARN == nil {
+		return nil
+	}
+	if _, ok := member.obj.GetAnnotations()[cloudMapServiceAnnotation]; ok {
+		return nil
 	}
-	return false
+	oldObj := member.obj.DeepCopyObject().(client.Object)
+	annotations := member.obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[cloudMapServiceAnnotation] = *svcSummary.serviceARN
+	member.obj.SetAnnotations(annotations)
+	return m.k8sClient.Patch(ctx, member.obj, client.MergeFrom(oldObj))
 }
 
-func (m *defaultResourceManager) updateCRDVirtualNode(ctx context.Context, vn *appmesh.VirtualNode, svcSummary *serviceSummary) error {
-	if svcSummary.serviceARN == nil {
+// updateListenerServicePorts records ports of additional listeners that have a cloudMap service in status of VirtualNode or VirtualGateway.
+func (m *defaultResourceManager) updateListenerServicePorts(ctx context.Context, member *meshMember, ports sets.Int64) error {
+	if listenerServicePorts(member).Equal(ports) {
 		return nil
 	}
-	oldVN := vn.DeepCopy()
-	vnAnnotations := oldVN.Annotations
+	var listenerServices []appmesh.CloudMapListenerService
+	for _, port := range ports.List() {
+		listenerServices = append(listenerServices, appmesh.CloudMapListenerService{
+			Port:        appmesh.PortNumber(port),
+			ServiceName: buildListenerServiceName(member.cloudMapConfig.ServiceName, port),
+		})
+	}
+	oldObj := member.obj.DeepCopyObject().(client.Object)
+	switch obj := member.obj.(type) {
+	case *appmesh.VirtualNode:
+		obj.Status.CloudMapListenerServices = listenerServices
+	case *appmesh.VirtualGateway:
+		obj.Status.CloudMapListenerServices = listenerServices
+	}
+	return m.k8sClient.Status().Patch(ctx, member.obj, client.MergeFromWithOptions(oldObj, client.MergeFromWithOptimisticLock{}))
+}
 
-	if vn.Annotations == nil {
-		vn.Annotations = make(map[string]string)
+// listenerServicePorts returns ports of additional listeners that have a cloudMap service recorded in status of VirtualNode or VirtualGateway.
+func listenerServicePorts(member *meshMember) sets.Int64 {
+	var listenerServices []appmesh.CloudMapListenerService
+	switch obj := member.obj.(type) {
+	case *appmesh.VirtualNode:
+		listenerServices = obj.Status.CloudMapListenerServices
+	case *appmesh.VirtualGateway:
+		listenerServices = obj.Status.CloudMapListenerServices
 	}
-	for key, _ := range vnAnnotations {
-		if key == cloudMapServiceAnnotation {
-			return nil
-		}
+	ports := sets.NewInt64()
+	for _, listenerService := range listenerServices {
+		ports.Insert(int64(listenerService.Port))
+	}
+	return ports
+}
+
+// primaryListenerPort returns the port of first listener, which is registered into the member's own cloudMap service.
+// returns 0 if there are no listeners.
+func primaryListenerPort(member *meshMember) int64 {
+	if len(member.listenerPorts) == 0 {
+		return 0
 	}
-	vn.Annotations[cloudMapServiceAnnotation] = *svcSummary.serviceARN
-	return m.k8sClient.Patch(ctx, vn, client.MergeFrom(oldVN))
+	return member.listenerPorts[0]
+}
+
+// additionalListenerPorts returns ports of listeners other than the first one, which are registered into a cloudMap service per listener.
+func additionalListenerPorts(member *meshMember) sets.Int64 {
+	ports := sets.NewInt64(member.listenerPorts...)
+	ports.Delete(primaryListenerPort(member))
+	return ports
+}
+
+// buildListenerServiceName returns the name of cloudMap service for listener with port.
+func buildListenerServiceName(serviceName string, port int64) string {
+	return fmt.Sprintf("%s-%d", serviceName, port)
+}
+
+// buildListenerServiceCreatorRequestID returns the creatorRequestID of cloudMap service for listener with port.
+func buildListenerServiceCreatorRequestID(member *meshMember, port int64) string {
+	return fmt.Sprintf("%s-%d", member.obj.GetUID(), port)
 }
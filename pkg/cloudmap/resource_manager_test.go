@@ -19,13 +19,14 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/cache"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"testing"
 	"time"
 )
 
-func Test_defaultResourceManager_updateCRDVirtualNode(t *testing.T) {
+func Test_defaultResourceManager_updateServiceARNAnnotation(t *testing.T) {
 	type args struct {
 		vn         *appmesh.VirtualNode
 		svcSummary *serviceSummary
@@ -96,7 +97,7 @@ func Test_defaultResourceManager_updateCRDVirtualNode(t *testing.T) {
 
 			err := k8sClient.Create(ctx, tt.args.vn.DeepCopy())
 			assert.NoError(t, err)
-			err = m.updateCRDVirtualNode(ctx, tt.args.vn, tt.args.svcSummary)
+			err = m.updateServiceARNAnnotation(ctx, newVirtualNodeMeshMember(tt.args.vn), tt.args.svcSummary)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
@@ -165,7 +166,7 @@ func Test_defaultResourceManager_isCloudMapServiceCreated(t *testing.T) {
 
 			err := k8sClient.Create(ctx, tt.args.vn.DeepCopy())
 			assert.NoError(t, err)
-			response := m.isCloudMapServiceCreated(ctx, tt.args.vn)
+			response := m.isCloudMapServiceCreated(ctx, newVirtualNodeMeshMember(tt.args.vn))
 			assert.True(t, cmp.Equal(tt.want, response), "diff", cmp.Diff(tt.want, response))
 		})
 	}
@@ -243,7 +244,7 @@ func Test_defaultResourceManager_reconcile_arePodsReconciled(t *testing.T) {
 
 			referencesResolver := mock_references.NewMockResolver(ctrl)
 			cloudMapSDK := services.NewMockCloudMap(ctrl)
-			virtualNodeEndpointResolver := NewMockVirtualNodeEndpointResolver(ctrl)
+			endpointResolver := NewMockEndpointResolver(ctrl)
 			instancesReconciler := NewMockInstancesReconciler(ctrl)
 
 			mesh := &appmesh.Mesh{}
@@ -255,18 +256,23 @@ func Test_defaultResourceManager_reconcile_arePodsReconciled(t *testing.T) {
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
 			cloudMapNamespace := servicediscovery.NamespaceSummary{Id: awssdk.String("namespace")}
 
+			namespaceResolver := &defaultNamespaceResolver{
+				cloudMapSDK:           cloudMapSDK,
+				namespaceSummaryCache: cache.NewLRUExpireCache(1),
+			}
 			m := &defaultResourceManager{
-				k8sClient:                   k8sClient,
-				log:                         logr.New(&log.NullLogSink{}),
-				referencesResolver:          referencesResolver,
-				namespaceSummaryCache:       cache.NewLRUExpireCache(1),
-				serviceSummaryCache:         cache.NewLRUExpireCache(1),
-				cloudMapSDK:                 cloudMapSDK,
-				virtualNodeEndpointResolver: virtualNodeEndpointResolver,
-				instancesReconciler:         instancesReconciler,
+				k8sClient:           k8sClient,
+				namespaceResolver:   namespaceResolver,
+				log:                 logr.New(&log.NullLogSink{}),
+				referencesResolver:  referencesResolver,
+				serviceSummaryCache: cache.NewLRUExpireCache(1),
+				cloudMapSDK:         cloudMapSDK,
+				endpointResolver:    endpointResolver,
+				healthSource:        &endpointHealthSource{},
+				instancesReconciler: instancesReconciler,
 			}
 
-			m.namespaceSummaryCache.Add(tt.args.vn.Spec.ServiceDiscovery.AWSCloudMap.NamespaceName, &cloudMapNamespace, 1*time.Minute)
+			namespaceResolver.namespaceSummaryCache.Add(tt.args.vn.Spec.ServiceDiscovery.AWSCloudMap.NamespaceName, &cloudMapNamespace, 1*time.Minute)
 			m.serviceSummaryCache.Add("namespace/"+tt.args.vn.Spec.ServiceDiscovery.AWSCloudMap.ServiceName, &svcSummary, 1*time.Minute)
 
 			referencesResolver.EXPECT().
@@ -278,14 +284,14 @@ func Test_defaultResourceManager_reconcile_arePodsReconciled(t *testing.T) {
 			if tt.shouldResolvePods {
 				expectedReadyPods = []*corev1.Pod{{}, {}}
 				expectedNotReadyPods = []*corev1.Pod{{}}
-				virtualNodeEndpointResolver.EXPECT().
-					Resolve(ctx, tt.args.vn).
+				endpointResolver.EXPECT().
+					Resolve(ctx, tt.args.vn, tt.args.vn.Spec.PodSelector).
 					Return(expectedReadyPods, expectedNotReadyPods, nil, nil)
 			}
 
 			//ensure we pass the correct pods to the reconciler
 			instancesReconciler.EXPECT().
-				Reconcile(ctx, mesh, tt.args.vn, svcSummary, expectedReadyPods, expectedNotReadyPods, map[string]nodeAttributes{}).
+				Reconcile(ctx, mesh, newVirtualNodeMeshMember(tt.args.vn), svcSummary, primaryListenerPort(newVirtualNodeMeshMember(tt.args.vn)), expectedReadyPods, expectedNotReadyPods, map[string]nodeAttributes{}).
 				Return(nil)
 
 			err := m.Reconcile(context.TODO(), tt.args.vn)
@@ -293,3 +299,466 @@ func Test_defaultResourceManager_reconcile_arePodsReconciled(t *testing.T) {
 		})
 	}
 }
+
+func Test_defaultResourceManager_reconcileListenerServices(t *testing.T) {
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "vn-1",
+			UID:       "uid-1",
+		},
+		Spec: appmesh.VirtualNodeSpec{
+			Listeners: []appmesh.Listener{
+				{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"}},
+				{PortMapping: appmesh.PortMapping{Port: 9090, Protocol: "grpc"}},
+				{PortMapping: appmesh.PortMapping{Port: 9091, Protocol: "grpc"}},
+			},
+			ServiceDiscovery: &appmesh.ServiceDiscovery{
+				AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+					NamespaceName: "cmnamespace",
+					ServiceName:   "cmservice",
+				},
+			},
+		},
+	}
+
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cloudMapSDK := services.NewMockCloudMap(ctrl)
+	instancesReconciler := NewMockInstancesReconciler(ctrl)
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithObjects(vn.DeepCopy()).WithStatusSubresource(&appmesh.VirtualNode{}).Build()
+	m := &defaultResourceManager{
+		k8sClient:           k8sClient,
+		log:                 logr.New(&log.NullLogSink{}),
+		serviceSummaryCache: cache.NewLRUExpireCache(10),
+		cloudMapSDK:         cloudMapSDK,
+		instancesReconciler: instancesReconciler,
+		config:              Config{CloudMapServiceTTL: 300},
+	}
+	nsSummary := &servicediscovery.NamespaceSummary{Id: awssdk.String("namespace"), Type: awssdk.String(servicediscovery.NamespaceTypeHttp)}
+	svc7070 := serviceSummary{serviceID: "svc-7070"}
+	svc9090 := serviceSummary{serviceID: "svc-9090"}
+	m.serviceSummaryCache.Add("namespace/cmservice-7070", &svc7070, 1*time.Minute)
+	m.serviceSummaryCache.Add("namespace/cmservice-9090", &svc9090, 1*time.Minute)
+
+	mesh := &appmesh.Mesh{}
+	readyPods := []*corev1.Pod{{}}
+
+	// cloudMap service for new listener is created.
+	cloudMapSDK.EXPECT().ListServicesPagesWithContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	cloudMapSDK.EXPECT().CreateServiceWithContext(gomock.Any(), &servicediscovery.CreateServiceInput{
+		CreatorRequestId: awssdk.String("uid-1-9091"),
+		NamespaceId:      awssdk.String("namespace"),
+		Name:             awssdk.String("cmservice-9091"),
+	}).Return(&servicediscovery.CreateServiceOutput{
+		Service: &servicediscovery.Service{Id: awssdk.String("svc-9091"), Name: awssdk.String("cmservice-9091"), CreatorRequestId: awssdk.String("uid-1-9091")},
+	}, nil)
+	instancesReconciler.EXPECT().Reconcile(gomock.Any(), mesh, gomock.Any(), svc9090, int64(9090), readyPods, nil, nil).Return(nil)
+	instancesReconciler.EXPECT().Reconcile(gomock.Any(), mesh, gomock.Any(), serviceSummary{serviceID: "svc-9091", creatorRequestID: awssdk.String("uid-1-9091")},
+		int64(9091), readyPods, nil, nil).Return(nil)
+
+	// cloudMap service for removed listener is deleted.
+	instancesReconciler.EXPECT().Reconcile(gomock.Any(), mesh, gomock.Any(), svc7070, int64(7070), nil, nil, nil).Return(nil)
+	cloudMapSDK.EXPECT().GetServiceWithContext(gomock.Any(), &servicediscovery.GetServiceInput{Id: awssdk.String("svc-7070")}).Return(&servicediscovery.GetServiceOutput{
+		Service: &servicediscovery.Service{Id: awssdk.String("svc-7070"), Name: awssdk.String("cmservice-7070"), CreatorRequestId: awssdk.String("uid-1-7070")},
+	}, nil)
+	cloudMapSDK.EXPECT().DeleteServiceWithContext(gomock.Any(), &servicediscovery.DeleteServiceInput{Id: awssdk.String("svc-7070")}).Return(&servicediscovery.DeleteServiceOutput{}, nil)
+
+	gotVN := &appmesh.VirtualNode{}
+	assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(vn), gotVN))
+	oldVN := gotVN.DeepCopy()
+	gotVN.Status.CloudMapListenerServices = []appmesh.CloudMapListenerService{
+		{Port: 7070, ServiceName: "cmservice-7070"},
+		{Port: 9090, ServiceName: "cmservice-9090"},
+	}
+	assert.NoError(t, k8sClient.Status().Patch(ctx, gotVN, client.MergeFrom(oldVN)))
+	dnsConfigMismatchedServiceNames, err := m.reconcileListenerServices(ctx, mesh, newVirtualNodeMeshMember(gotVN), nsSummary, readyPods, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, dnsConfigMismatchedServiceNames)
+
+	assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(vn), gotVN))
+	assert.Equal(t, []appmesh.CloudMapListenerService{
+		{Port: 9090, ServiceName: "cmservice-9090"},
+		{Port: 9091, ServiceName: "cmservice-9091"},
+	}, gotVN.Status.CloudMapListenerServices)
+}
+
+func Test_additionalListenerPorts(t *testing.T) {
+	tests := []struct {
+		name string
+		vn   *appmesh.VirtualNode
+		want []int64
+	}{
+		{
+			name: "virtualNode without listeners",
+			vn:   &appmesh.VirtualNode{},
+			want: []int64{},
+		},
+		{
+			name: "virtualNode with single listener",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: []appmesh.Listener{{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"}}},
+				},
+			},
+			want: []int64{},
+		},
+		{
+			name: "virtualNode with multiple listeners",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: []appmesh.Listener{
+						{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"}},
+						{PortMapping: appmesh.PortMapping{Port: 9090, Protocol: "grpc"}},
+					},
+				},
+			},
+			want: []int64{9090},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := additionalListenerPorts(newVirtualNodeMeshMember(tt.vn))
+			assert.Equal(t, tt.want, got.List())
+		})
+	}
+}
+
+func Test_defaultResourceManager_buildServiceDNSConfig(t *testing.T) {
+	weighted := appmesh.AWSCloudMapRoutingPolicyWeighted
+	tests := []struct {
+		name           string
+		ipFamily       string
+		cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery
+		want           *servicediscovery.DnsConfig
+	}{
+		{
+			name:           "defaults for IPv4 cluster",
+			ipFamily:       IPv4,
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{},
+			want: &servicediscovery.DnsConfig{
+				RoutingPolicy: awssdk.String(servicediscovery.RoutingPolicyMultivalue),
+				DnsRecords: []*servicediscovery.DnsRecord{
+					{Type: awssdk.String(servicediscovery.RecordTypeA), TTL: awssdk.Int64(300)},
+				},
+			},
+		},
+		{
+			name:           "defaults for IPv6 cluster",
+			ipFamily:       IPv6,
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{},
+			want: &servicediscovery.DnsConfig{
+				RoutingPolicy: awssdk.String(servicediscovery.RoutingPolicyMultivalue),
+				DnsRecords: []*servicediscovery.DnsRecord{
+					{Type: awssdk.String(servicediscovery.RecordTypeAaaa), TTL: awssdk.Int64(300)},
+				},
+			},
+		},
+		{
+			name:     "specified by virtualNode",
+			ipFamily: IPv4,
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				DNSConfig: &appmesh.AWSCloudMapDNSConfig{
+					TTL:           awssdk.Int64(30),
+					RoutingPolicy: &weighted,
+					RecordTypes: []appmesh.AWSCloudMapDNSRecordType{
+						appmesh.AWSCloudMapDNSRecordTypeA,
+						appmesh.AWSCloudMapDNSRecordTypeAAAA,
+						appmesh.AWSCloudMapDNSRecordTypeSRV,
+					},
+				},
+			},
+			want: &servicediscovery.DnsConfig{
+				RoutingPolicy: awssdk.String(servicediscovery.RoutingPolicyWeighted),
+				DnsRecords: []*servicediscovery.DnsRecord{
+					{Type: awssdk.String(servicediscovery.RecordTypeA), TTL: awssdk.Int64(30)},
+					{Type: awssdk.String(servicediscovery.RecordTypeAaaa), TTL: awssdk.Int64(30)},
+					{Type: awssdk.String(servicediscovery.RecordTypeSrv), TTL: awssdk.Int64(30)},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &defaultResourceManager{
+				config:   Config{CloudMapServiceTTL: 300},
+				ipFamily: tt.ipFamily,
+			}
+			got := m.buildServiceDNSConfig(tt.cloudMapConfig)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_defaultResourceManager_reconcileCloudMapServiceDNSConfig(t *testing.T) {
+	nsSummary := &servicediscovery.NamespaceSummary{Id: awssdk.String("namespace"), Type: awssdk.String(servicediscovery.NamespaceTypeDnsPrivate)}
+	newSVCSummary := func(ttl int64, creatorRequestID *string) *serviceSummary {
+		return &serviceSummary{
+			serviceID: "svc-1",
+			dnsConfig: &servicediscovery.DnsConfig{
+				RoutingPolicy: awssdk.String(servicediscovery.RoutingPolicyMultivalue),
+				DnsRecords: []*servicediscovery.DnsRecord{
+					{Type: awssdk.String(servicediscovery.RecordTypeA), TTL: awssdk.Int64(ttl)},
+					{Type: awssdk.String(servicediscovery.RecordTypeSrv), TTL: awssdk.Int64(ttl)},
+				},
+			},
+			creatorRequestID: creatorRequestID,
+		}
+	}
+	newUpdate := func(ttl int64) *servicediscovery.UpdateServiceInput {
+		return &servicediscovery.UpdateServiceInput{
+			Id: awssdk.String("svc-1"),
+			Service: &servicediscovery.ServiceChange{
+				DnsConfig: &servicediscovery.DnsConfigChange{
+					DnsRecords: []*servicediscovery.DnsRecord{
+						{Type: awssdk.String(servicediscovery.RecordTypeA), TTL: awssdk.Int64(ttl)},
+						{Type: awssdk.String(servicediscovery.RecordTypeSrv), TTL: awssdk.Int64(ttl)},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name           string
+		svcSummary     *serviceSummary
+		cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery
+		getService     *servicediscovery.Service
+		wantUpdate     *servicediscovery.UpdateServiceInput
+	}{
+		{
+			name:           "TTL not specified",
+			svcSummary:     newSVCSummary(300, nil),
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{ServiceName: "cmservice"},
+		},
+		{
+			name:       "TTL unchanged",
+			svcSummary: newSVCSummary(300, nil),
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				ServiceName: "cmservice",
+				DNSConfig:   &appmesh.AWSCloudMapDNSConfig{TTL: awssdk.Int64(300)},
+			},
+		},
+		{
+			name:       "TTL changed",
+			svcSummary: newSVCSummary(300, nil),
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				ServiceName: "cmservice",
+				DNSConfig:   &appmesh.AWSCloudMapDNSConfig{TTL: awssdk.Int64(60)},
+			},
+			wantUpdate: newUpdate(60),
+		},
+		{
+			name:           "TTL removed, default TTL restored on owned service",
+			svcSummary:     newSVCSummary(60, nil),
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{ServiceName: "cmservice"},
+			getService:     &servicediscovery.Service{Id: awssdk.String("svc-1"), CreatorRequestId: awssdk.String("vn-uid")},
+			wantUpdate:     newUpdate(300),
+		},
+		{
+			name:           "TTL removed, default TTL restored on service known to be owned",
+			svcSummary:     newSVCSummary(60, awssdk.String("vn-uid")),
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{ServiceName: "cmservice", DNSConfig: &appmesh.AWSCloudMapDNSConfig{}},
+			wantUpdate:     newUpdate(300),
+		},
+		{
+			name:           "TTL removed, TTL of service not owned is kept",
+			svcSummary:     newSVCSummary(60, nil),
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{ServiceName: "cmservice"},
+			getService:     &servicediscovery.Service{Id: awssdk.String("svc-1"), CreatorRequestId: awssdk.String("other-uid")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cloudMapSDK := services.NewMockCloudMap(ctrl)
+			m := &defaultResourceManager{
+				log:                 logr.New(&log.NullLogSink{}),
+				serviceSummaryCache: cache.NewLRUExpireCache(10),
+				cloudMapSDK:         cloudMapSDK,
+				config:              Config{CloudMapServiceTTL: 300},
+			}
+			if tt.getService != nil {
+				cloudMapSDK.EXPECT().GetServiceWithContext(gomock.Any(), &servicediscovery.GetServiceInput{Id: awssdk.String("svc-1")}).
+					Return(&servicediscovery.GetServiceOutput{Service: tt.getService}, nil)
+			}
+			if tt.wantUpdate != nil {
+				cloudMapSDK.EXPECT().UpdateServiceWithContext(gomock.Any(), tt.wantUpdate).Return(&servicediscovery.UpdateServiceOutput{}, nil)
+			}
+			got, err := m.reconcileCloudMapServiceDNSConfig(context.Background(), "vn-uid", nsSummary, "cmservice", tt.svcSummary, tt.cloudMapConfig)
+			assert.NoError(t, err)
+			if tt.wantUpdate == nil {
+				assert.Equal(t, tt.svcSummary.dnsConfig, got.dnsConfig)
+				return
+			}
+			assert.Equal(t, tt.wantUpdate.Service.DnsConfig.DnsRecords, got.dnsConfig.DnsRecords)
+			cached, exists := m.serviceSummaryCache.Get("namespace/cmservice")
+			assert.True(t, exists)
+			assert.Equal(t, got, cached)
+		})
+	}
+}
+
+func Test_defaultResourceManager_isCloudMapServiceDNSConfigMismatched(t *testing.T) {
+	svcSummary := &serviceSummary{
+		serviceID: "svc-1",
+		dnsConfig: &servicediscovery.DnsConfig{
+			RoutingPolicy: awssdk.String(servicediscovery.RoutingPolicyMultivalue),
+			DnsRecords: []*servicediscovery.DnsRecord{
+				{Type: awssdk.String(servicediscovery.RecordTypeA), TTL: awssdk.Int64(300)},
+			},
+		},
+	}
+	weighted := appmesh.AWSCloudMapRoutingPolicyWeighted
+	multivalue := appmesh.AWSCloudMapRoutingPolicyMultivalue
+	tests := []struct {
+		name       string
+		svcSummary *serviceSummary
+		dnsConfig  *appmesh.AWSCloudMapDNSConfig
+		want       bool
+	}{
+		{
+			name:       "dnsConfig not specified",
+			svcSummary: svcSummary,
+			want:       false,
+		},
+		{
+			name:       "service in HTTP namespace",
+			svcSummary: &serviceSummary{serviceID: "svc-1"},
+			dnsConfig:  &appmesh.AWSCloudMapDNSConfig{RoutingPolicy: &weighted},
+			want:       false,
+		},
+		{
+			name:       "only TTL differs",
+			svcSummary: svcSummary,
+			dnsConfig:  &appmesh.AWSCloudMapDNSConfig{TTL: awssdk.Int64(60)},
+			want:       false,
+		},
+		{
+			name:       "same routing policy and record types",
+			svcSummary: svcSummary,
+			dnsConfig: &appmesh.AWSCloudMapDNSConfig{
+				RoutingPolicy: &multivalue,
+				RecordTypes:   []appmesh.AWSCloudMapDNSRecordType{appmesh.AWSCloudMapDNSRecordTypeA},
+			},
+			want: false,
+		},
+		{
+			name:       "routing policy differs",
+			svcSummary: svcSummary,
+			dnsConfig:  &appmesh.AWSCloudMapDNSConfig{RoutingPolicy: &weighted},
+			want:       true,
+		},
+		{
+			name:       "record types differ",
+			svcSummary: svcSummary,
+			dnsConfig: &appmesh.AWSCloudMapDNSConfig{
+				RecordTypes: []appmesh.AWSCloudMapDNSRecordType{appmesh.AWSCloudMapDNSRecordTypeA, appmesh.AWSCloudMapDNSRecordTypeSRV},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &defaultResourceManager{
+				config: Config{CloudMapServiceTTL: 300},
+			}
+			cloudMapConfig := &appmesh.AWSCloudMapServiceDiscovery{ServiceName: "cmservice", DNSConfig: tt.dnsConfig}
+			assert.Equal(t, tt.want, m.isCloudMapServiceDNSConfigMismatched(tt.svcSummary, cloudMapConfig))
+		})
+	}
+}
+
+func Test_defaultResourceManager_deleteCloudMapService(t *testing.T) {
+	nsSummary := &servicediscovery.NamespaceSummary{Id: awssdk.String("namespace"), Name: awssdk.String("my-ns")}
+	svcSummary := &serviceSummary{serviceID: "svc-1"}
+	instance := func(id string, clusterID string) *servicediscovery.InstanceSummary {
+		attrs := map[string]*string{AttrAppMeshMesh: awssdk.String("my-mesh")}
+		if clusterID != "" {
+			attrs[AttrAppMeshCluster] = awssdk.String(clusterID)
+		}
+		return &servicediscovery.InstanceSummary{Id: awssdk.String(id), Attributes: attrs}
+	}
+	tests := []struct {
+		name       string
+		config     Config
+		service    *servicediscovery.Service
+		instances  []*servicediscovery.InstanceSummary
+		wantDelete bool
+	}{
+		{
+			name:       "service owned by virtualNode",
+			service:    &servicediscovery.Service{Id: awssdk.String("svc-1"), CreatorRequestId: awssdk.String("vn-uid")},
+			wantDelete: true,
+		},
+		{
+			name:       "service not owned by virtualNode",
+			service:    &servicediscovery.Service{Id: awssdk.String("svc-1"), CreatorRequestId: awssdk.String("other-uid")},
+			wantDelete: false,
+		},
+		{
+			name: "shared service not owned by virtualNode when multi-cluster mode disabled",
+			service: &servicediscovery.Service{Id: awssdk.String("svc-1"), CreatorRequestId: awssdk.String("other-uid"),
+				Description: awssdk.String(multiClusterServiceDescription)},
+			wantDelete: false,
+		},
+		{
+			name:   "shared service without instances of other clusters",
+			config: Config{ClusterID: "cluster-a"},
+			service: &servicediscovery.Service{Id: awssdk.String("svc-1"), CreatorRequestId: awssdk.String("other-uid"),
+				Description: awssdk.String(multiClusterServiceDescription)},
+			instances:  []*servicediscovery.InstanceSummary{instance("uid-1", "cluster-a")},
+			wantDelete: true,
+		},
+		{
+			name:   "shared service with instances of other clusters",
+			config: Config{ClusterID: "cluster-a"},
+			service: &servicediscovery.Service{Id: awssdk.String("svc-1"), CreatorRequestId: awssdk.String("vn-uid"),
+				Description: awssdk.String(multiClusterServiceDescription)},
+			instances:  []*servicediscovery.InstanceSummary{instance("uid-1", "cluster-a"), instance("uid-2", "cluster-b")},
+			wantDelete: false,
+		},
+		{
+			name:       "service with instances registered without cluster",
+			config:     Config{ClusterID: "cluster-a"},
+			service:    &servicediscovery.Service{Id: awssdk.String("svc-1"), CreatorRequestId: awssdk.String("vn-uid")},
+			instances:  []*servicediscovery.InstanceSummary{instance("uid-2", "")},
+			wantDelete: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cloudMapSDK := services.NewMockCloudMap(ctrl)
+			m := &defaultResourceManager{
+				log:                 logr.New(&log.NullLogSink{}),
+				serviceSummaryCache: cache.NewLRUExpireCache(10),
+				cloudMapSDK:         cloudMapSDK,
+				config:              tt.config,
+			}
+			cloudMapSDK.EXPECT().GetServiceWithContext(gomock.Any(), &servicediscovery.GetServiceInput{Id: awssdk.String("svc-1")}).
+				Return(&servicediscovery.GetServiceOutput{Service: tt.service}, nil)
+			if tt.config.MultiClusterEnabled() && m.isCloudMapServiceOwnedBy(context.Background(), tt.service, "vn-uid") {
+				cloudMapSDK.EXPECT().ListInstancesPagesWithContext(gomock.Any(), &servicediscovery.ListInstancesInput{ServiceId: awssdk.String("svc-1")}, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, input *servicediscovery.ListInstancesInput, fn func(*servicediscovery.ListInstancesOutput, bool) bool, opts ...interface{}) error {
+						fn(&servicediscovery.ListInstancesOutput{Instances: tt.instances}, true)
+						return nil
+					})
+			}
+			if tt.wantDelete {
+				cloudMapSDK.EXPECT().DeleteServiceWithContext(gomock.Any(), &servicediscovery.DeleteServiceInput{Id: awssdk.String("svc-1")}).
+					Return(&servicediscovery.DeleteServiceOutput{}, nil)
+			}
+			err := m.deleteCloudMapService(context.Background(), "vn-uid", nsSummary, svcSummary)
+			assert.NoError(t, err)
+		})
+	}
+}
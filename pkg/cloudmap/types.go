@@ -13,6 +13,11 @@ type serviceSummary struct {
 	serviceID               string
 	serviceARN              *string
 	healthCheckCustomConfig *servicediscovery.HealthCheckCustomConfig
+	dnsConfig               *servicediscovery.DnsConfig
+	// creatorRequestID and description identify the owner of service, see isCloudMapServiceOwnedBy.
+	// they're nil until known, as services found by ListServices don't include them.
+	creatorRequestID *string
+	description      *string
 }
 
 // serviceSubset represents a subset of cloudMap service
@@ -44,20 +49,30 @@ type instanceInfo struct {
 type nodeAttributes struct {
 	region           string
 	availabilityZone string
+	labels           map[string]string
 }
 
-var _ serviceSubset = &virtualNodeServiceSubset{}
+var _ serviceSubset = &meshMemberServiceSubset{}
 
-// virtualNodeServiceSubset presents a subset of cloudMap service that should be managed by specific virtualNode.
-type virtualNodeServiceSubset struct {
-	ms *appmesh.Mesh
-	vn *appmesh.VirtualNode
+// meshMemberServiceSubset presents a subset of cloudMap service that should be managed by specific virtualNode or virtualGateway.
+// in multi-cluster mode, it only contains instances registered by this cluster.
+type meshMemberServiceSubset struct {
+	ms     *appmesh.Mesh
+	member *meshMember
+	// clusterID is the ID of this cluster, empty if multi-cluster mode is disabled.
+	clusterID string
 }
 
-func (s *virtualNodeServiceSubset) SubsetID() string {
-	return fmt.Sprintf("%s/%s", aws.StringValue(s.ms.Spec.AWSName), aws.StringValue(s.vn.Spec.AWSName))
+func (s *meshMemberServiceSubset) SubsetID() string {
+	if s.clusterID != "" {
+		return fmt.Sprintf("%s/%s/%s", aws.StringValue(s.ms.Spec.AWSName), s.member.awsName, s.clusterID)
+	}
+	return fmt.Sprintf("%s/%s", aws.StringValue(s.ms.Spec.AWSName), s.member.awsName)
 }
 
-func (s *virtualNodeServiceSubset) Contains(instanceID string, attrs instanceAttributes) bool {
-	return attrs[AttrAppMeshMesh] == aws.StringValue(s.ms.Spec.AWSName) && attrs[AttrAppMeshVirtualNode] == aws.StringValue(s.vn.Spec.AWSName)
+func (s *meshMemberServiceSubset) Contains(instanceID string, attrs instanceAttributes) bool {
+	if s.clusterID != "" && attrs[AttrAppMeshCluster] != s.clusterID {
+		return false
+	}
+	return attrs[AttrAppMeshMesh] == aws.StringValue(s.ms.Spec.AWSName) && attrs[s.member.awsNameAttr] == s.member.awsName
 }
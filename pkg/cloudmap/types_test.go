@@ -0,0 +1,78 @@
+package cloudmap
+
+import (
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_meshMemberServiceSubset(t *testing.T) {
+	ms := &appmesh.Mesh{Spec: appmesh.MeshSpec{AWSName: aws.String("my-mesh")}}
+	vn := &appmesh.VirtualNode{Spec: appmesh.VirtualNodeSpec{AWSName: aws.String("my-vn")}}
+	tests := []struct {
+		name         string
+		clusterID    string
+		attrs        instanceAttributes
+		wantSubsetID string
+		wantContains bool
+	}{
+		{
+			name:         "instance of virtualNode",
+			attrs:        instanceAttributes{AttrAppMeshMesh: "my-mesh", AttrAppMeshVirtualNode: "my-vn"},
+			wantSubsetID: "my-mesh/my-vn",
+			wantContains: true,
+		},
+		{
+			name:         "instance of other virtualNode",
+			attrs:        instanceAttributes{AttrAppMeshMesh: "my-mesh", AttrAppMeshVirtualNode: "other-vn"},
+			wantSubsetID: "my-mesh/my-vn",
+			wantContains: false,
+		},
+		{
+			name:         "instance of virtualNode registered by any cluster when multi-cluster mode disabled",
+			attrs:        instanceAttributes{AttrAppMeshMesh: "my-mesh", AttrAppMeshVirtualNode: "my-vn", AttrAppMeshCluster: "cluster-b"},
+			wantSubsetID: "my-mesh/my-vn",
+			wantContains: true,
+		},
+		{
+			name:         "instance of virtualNode registered by this cluster",
+			clusterID:    "cluster-a",
+			attrs:        instanceAttributes{AttrAppMeshMesh: "my-mesh", AttrAppMeshVirtualNode: "my-vn", AttrAppMeshCluster: "cluster-a"},
+			wantSubsetID: "my-mesh/my-vn/cluster-a",
+			wantContains: true,
+		},
+		{
+			name:         "instance of virtualNode registered by other cluster",
+			clusterID:    "cluster-a",
+			attrs:        instanceAttributes{AttrAppMeshMesh: "my-mesh", AttrAppMeshVirtualNode: "my-vn", AttrAppMeshCluster: "cluster-b"},
+			wantSubsetID: "my-mesh/my-vn/cluster-a",
+			wantContains: false,
+		},
+		{
+			name:         "instance of virtualNode registered without cluster",
+			clusterID:    "cluster-a",
+			attrs:        instanceAttributes{AttrAppMeshMesh: "my-mesh", AttrAppMeshVirtualNode: "my-vn"},
+			wantSubsetID: "my-mesh/my-vn/cluster-a",
+			wantContains: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &meshMemberServiceSubset{ms: ms, member: newVirtualNodeMeshMember(vn), clusterID: tt.clusterID}
+			assert.Equal(t, tt.wantSubsetID, s.SubsetID())
+			assert.Equal(t, tt.wantContains, s.Contains("uid-1", tt.attrs))
+		})
+	}
+}
+
+func Test_meshMemberServiceSubset_virtualGateway(t *testing.T) {
+	ms := &appmesh.Mesh{Spec: appmesh.MeshSpec{AWSName: aws.String("my-mesh")}}
+	vg := &appmesh.VirtualGateway{Spec: appmesh.VirtualGatewaySpec{AWSName: aws.String("my-vg")}}
+	s := &meshMemberServiceSubset{ms: ms, member: newVirtualGatewayMeshMember(vg)}
+	assert.Equal(t, "my-mesh/my-vg", s.SubsetID())
+	assert.True(t, s.Contains("uid-1", instanceAttributes{AttrAppMeshMesh: "my-mesh", AttrAppMeshVirtualGateway: "my-vg"}))
+	// instances of virtualNode with same name are not contained.
+	assert.False(t, s.Contains("uid-2", instanceAttributes{AttrAppMeshMesh: "my-mesh", AttrAppMeshVirtualNode: "my-vg"}))
+}
@@ -14,95 +14,58 @@
 package conversions
 
 import (
-	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/cache"
 )
 
-// PodConverter implements the interface to convert k8s pod object to a stripped down
-// version of pod to save on memory utilized
-type podConverter struct {
-	podResource     string
-	podResourceType runtime.Object
-}
-
-// NewPodConverter returns podConverter object
-func NewPodConverter() *podConverter {
-	return &podConverter{
-		podResource:     "pods",
-		podResourceType: &corev1.Pod{},
-	}
-}
-
-// ConvertObject converts original pod object to stripped down pod object
-func (c *podConverter) ConvertObject(originalObj interface{}) (convertedObj interface{}, err error) {
-	pod, ok := originalObj.(*corev1.Pod)
-	if ok {
-		return c.stripDownPod(pod), nil
-	}
-
-	if deleteTombstone, ok := originalObj.(cache.DeletedFinalStateUnknown); ok {
-		pod, ok = deleteTombstone.Obj.(*corev1.Pod)
-		if ok {
-			//the object on a DeletedFinalStateUnknown will be from the cache we don't need to strip it again
-			return pod, nil
-		}
-	}
-
-	return nil, fmt.Errorf("failed to convert object to pod")
-}
+var _ cache.TransformFunc = StripPod
 
-// ConvertList converts the original pod list to stripped down list of pod objects
-func (c *podConverter) ConvertList(originalList interface{}) (convertedList interface{}, err error) {
-	podList, ok := originalList.(*corev1.PodList)
+// StripPod is a cache transform function that converts pod objects to a stripped down
+// version of pod to save on memory utilized by the pod informer.
+// Objects other than pods are returned as is.
+func StripPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*corev1.Pod)
 	if !ok {
-		return nil, fmt.Errorf("failed to convert object to pod list")
-	}
-	// We need to set continue in order to allow the pagination to work on converted
-	// pod list object
-	strippedPodList := corev1.PodList{
-		ListMeta: metaV1.ListMeta{
-			Continue:        podList.Continue,
-			ResourceVersion: podList.ResourceVersion,
-		},
+		return obj, nil
 	}
-	for _, pod := range podList.Items {
-		strippedPod := c.stripDownPod(&pod)
-		strippedPodList.Items = append(strippedPodList.Items, *strippedPod)
-	}
-	return &strippedPodList, nil
+	return stripDownPod(pod), nil
 }
 
-// Resource to watch and list
-func (c *podConverter) Resource() string {
-	return c.podResource
-}
-
-// ResourceType to watch and list
-func (c *podConverter) ResourceType() runtime.Object {
-	return c.podResourceType
-}
-
-// StripDownPod removes all the extra details from pod that are not
+// stripDownPod removes all the extra details from pod that are not
 // required by the controller.
-func (c *podConverter) stripDownPod(pod *corev1.Pod) *corev1.Pod {
+func stripDownPod(pod *corev1.Pod) *corev1.Pod {
+	var containerStatuses []corev1.ContainerStatus
+	for _, status := range pod.Status.ContainerStatuses {
+		containerStatuses = append(containerStatuses, corev1.ContainerStatus{
+			Name:  status.Name,
+			Ready: status.Ready,
+		})
+	}
 	return &corev1.Pod{
+		TypeMeta: pod.TypeMeta,
 		ObjectMeta: metaV1.ObjectMeta{
 			Name:              pod.Name,
 			Namespace:         pod.Namespace,
+			UID:               pod.UID,
+			ResourceVersion:   pod.ResourceVersion,
 			Labels:            pod.Labels,
+			Annotations:       pod.Annotations,
+			OwnerReferences:   pod.OwnerReferences,
 			DeletionTimestamp: pod.DeletionTimestamp,
 		},
 		Spec: corev1.PodSpec{
-			NodeName:      pod.Spec.NodeName,
-			RestartPolicy: pod.Spec.RestartPolicy,
+			NodeName:           pod.Spec.NodeName,
+			RestartPolicy:      pod.Spec.RestartPolicy,
+			ServiceAccountName: pod.Spec.ServiceAccountName,
+			ReadinessGates:     pod.Spec.ReadinessGates,
 		},
 		Status: corev1.PodStatus{
-			Conditions: pod.Status.Conditions,
-			Phase:      pod.Status.Phase,
-			PodIP:      pod.Status.PodIP,
+			Conditions:        pod.Status.Conditions,
+			Phase:             pod.Status.Phase,
+			PodIP:             pod.Status.PodIP,
+			PodIPs:            pod.Status.PodIPs,
+			ContainerStatuses: containerStatuses,
 		},
 	}
 }
@@ -0,0 +1,30 @@
+package deletion
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagDefaultDeletionPolicy = "default-deletion-policy"
+)
+
+type Config struct {
+	// Specifies the deletion policy of custom resources without the deletion policy annotation.
+	DefaultPolicy string
+}
+
+func (cfg *Config) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&cfg.DefaultPolicy, flagDefaultDeletionPolicy, string(PolicyDelete),
+		`The deletion policy of App Mesh custom resources without the `+AnnotationDeletionPolicy+` annotation, either Delete or Retain. Retain leaves App Mesh and Cloud Map resources in place when custom resources are deleted`)
+}
+
+func (cfg *Config) Validate() error {
+	_, err := ParsePolicy(cfg.DefaultPolicy)
+	return errors.Wrapf(err, "invalid %v", flagDefaultDeletionPolicy)
+}
+
+// Default returns the default deletion policy, cfg must be valid.
+func (cfg *Config) Default() Policy {
+	return Policy(cfg.DefaultPolicy)
+}
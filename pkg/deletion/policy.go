@@ -0,0 +1,54 @@
+package deletion
+
+import (
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AnnotationDeletionPolicy specifies the deletion policy of an App Mesh custom resource,
+	// which overrides the controller-wide default.
+	AnnotationDeletionPolicy = "appmesh.k8s.aws/deletion-policy"
+)
+
+// Policy decides whether AWS resources are deleted when the custom resource managing them is deleted.
+type Policy string
+
+const (
+	// PolicyDelete deletes AWS resources along with the custom resource.
+	PolicyDelete Policy = "Delete"
+	// PolicyRetain leaves AWS resources in place when the custom resource is deleted.
+	PolicyRetain Policy = "Retain"
+)
+
+// ParsePolicy parses deletion policy from value.
+func ParsePolicy(value string) (Policy, error) {
+	switch Policy(value) {
+	case PolicyDelete, PolicyRetain:
+		return Policy(value), nil
+	default:
+		return "", errors.Errorf("deletion policy must be either %v or %v, got %q", PolicyDelete, PolicyRetain, value)
+	}
+}
+
+// PolicyOf returns the deletion policy of obj.
+// It's specified by the annotation of obj if present, or defaultPolicy otherwise. Empty defaultPolicy means PolicyDelete.
+func PolicyOf(obj metav1.Object, defaultPolicy Policy) (Policy, error) {
+	if value, ok := obj.GetAnnotations()[AnnotationDeletionPolicy]; ok {
+		policy, err := ParsePolicy(value)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid %v annotation", AnnotationDeletionPolicy)
+		}
+		return policy, nil
+	}
+	if defaultPolicy == "" {
+		return PolicyDelete, nil
+	}
+	return defaultPolicy, nil
+}
+
+// ValidatePolicyAnnotation validates the deletion policy annotation of obj, if present.
+func ValidatePolicyAnnotation(obj metav1.Object) error {
+	_, err := PolicyOf(obj, PolicyDelete)
+	return err
+}
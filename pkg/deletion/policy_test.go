@@ -0,0 +1,93 @@
+package deletion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyOf(t *testing.T) {
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		defaultPolicy Policy
+		want          Policy
+		wantErr       string
+	}{
+		{
+			name: "without annotation nor default",
+			want: PolicyDelete,
+		},
+		{
+			name:          "without annotation",
+			defaultPolicy: PolicyRetain,
+			want:          PolicyRetain,
+		},
+		{
+			name:          "annotation overrides default",
+			annotations:   map[string]string{AnnotationDeletionPolicy: "Delete"},
+			defaultPolicy: PolicyRetain,
+			want:          PolicyDelete,
+		},
+		{
+			name:          "retain by annotation",
+			annotations:   map[string]string{AnnotationDeletionPolicy: "Retain"},
+			defaultPolicy: PolicyDelete,
+			want:          PolicyRetain,
+		},
+		{
+			name:          "invalid annotation",
+			annotations:   map[string]string{AnnotationDeletionPolicy: "retain"},
+			defaultPolicy: PolicyDelete,
+			wantErr:       `invalid appmesh.k8s.aws/deletion-policy annotation: deletion policy must be either Delete or Retain, got "retain"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tt.annotations}
+			got, err := PolicyOf(obj, tt.defaultPolicy)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.EqualError(t, ValidatePolicyAnnotation(obj), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.NoError(t, ValidatePolicyAnnotation(obj))
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{
+			name: "delete",
+			cfg:  Config{DefaultPolicy: "Delete"},
+		},
+		{
+			name: "retain",
+			cfg:  Config{DefaultPolicy: "Retain"},
+		},
+		{
+			name:    "invalid",
+			cfg:     Config{DefaultPolicy: "Orphan"},
+			wantErr: `invalid default-deletion-policy: deletion policy must be either Delete or Retain, got "Orphan"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, Policy(tt.cfg.DefaultPolicy), tt.cfg.Default())
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package dependency
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sort"
+)
+
+// Key identifies an object in the dependency graph.
+type Key struct {
+	Kind string
+	types.NamespacedName
+}
+
+// Graph is the dependency graph between objects of a mesh.
+// A hard edge means an object can only be reconciled after its dependency is active,
+// e.g. a VirtualService and its provider VirtualNode.
+// A soft edge means an object only refers to its dependency by name,
+// e.g. a VirtualNode and its backend VirtualServices.
+// Soft edges order objects as long as they don't form a cycle, which is allowed between VirtualNodes and VirtualServices.
+type Graph struct {
+	nodes     map[Key]struct{}
+	hardEdges map[Key]map[Key]struct{}
+	softEdges map[Key]map[Key]struct{}
+}
+
+// NewGraph constructs new empty Graph.
+func NewGraph() *Graph {
+	return &Graph{
+		nodes:     make(map[Key]struct{}),
+		hardEdges: make(map[Key]map[Key]struct{}),
+		softEdges: make(map[Key]map[Key]struct{}),
+	}
+}
+
+// AddNode adds object to the graph.
+func (g *Graph) AddNode(key Key) {
+	g.nodes[key] = struct{}{}
+}
+
+// AddHardEdge adds a hard edge from object to its dependency.
+func (g *Graph) AddHardEdge(from Key, to Key) {
+	addEdge(g.hardEdges, from, to)
+}
+
+// AddSoftEdge adds a soft edge from object to its dependency.
+func (g *Graph) AddSoftEdge(from Key, to Key) {
+	addEdge(g.softEdges, from, to)
+}
+
+// ReverseLayers returns objects in reverse dependency order, grouped into layers where objects are only depended on by objects of previous layers.
+// Edges to objects that are not in the graph are ignored, and so are soft edges within a cycle.
+// An error is returned if hard edges form a cycle.
+func (g *Graph) ReverseLayers() ([][]Key, error) {
+	dependents := make(map[Key]map[Key]struct{})
+	for from, tos := range g.dependencies() {
+		for to := range tos {
+			addEdge(dependents, to, from)
+		}
+	}
+	return g.layers(dependents)
+}
+
+// Dependencies returns the objects that key depends on directly or indirectly, except the objects in a cycle with key,
+// i.e. the objects to reconcile before key. Objects in a cycle are reconciled together, since neither can go first.
+func (g *Graph) Dependencies(key Key) []Key {
+	var cycle []Key
+	for _, component := range g.stronglyConnectedComponents() {
+		for _, member := range component {
+			if member == key {
+				cycle = component
+			}
+		}
+	}
+	inCycle := make(map[Key]struct{}, len(cycle))
+	for _, member := range cycle {
+		inCycle[member] = struct{}{}
+	}
+	visited := map[Key]struct{}{key: {}}
+	queue := []Key{key}
+	var dependencies []Key
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependency := range g.dependenciesOf(current) {
+			if _, ok := visited[dependency]; ok {
+				continue
+			}
+			visited[dependency] = struct{}{}
+			queue = append(queue, dependency)
+			if _, ok := inCycle[dependency]; !ok {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+	sortKeys(dependencies)
+	return dependencies
+}
+
+// dependencies returns the edges that order objects, i.e. hard edges and soft edges that are not within a cycle.
+func (g *Graph) dependencies() map[Key]map[Key]struct{} {
+	componentByKey := make(map[Key]int)
+	for i, component := range g.stronglyConnectedComponents() {
+		for _, key := range component {
+			componentByKey[key] = i
+		}
+	}
+	dependencies := make(map[Key]map[Key]struct{})
+	for from, tos := range g.hardEdges {
+		for to := range tos {
+			if g.hasNodes(from, to) {
+				addEdge(dependencies, from, to)
+			}
+		}
+	}
+	for from, tos := range g.softEdges {
+		for to := range tos {
+			if g.hasNodes(from, to) && componentByKey[from] != componentByKey[to] {
+				addEdge(dependencies, from, to)
+			}
+		}
+	}
+	return dependencies
+}
+
+// layers groups objects into layers where objects only depend on objects of previous layers by dependencies.
+func (g *Graph) layers(dependencies map[Key]map[Key]struct{}) ([][]Key, error) {
+	var layers [][]Key
+	ordered := make(map[Key]struct{})
+	for len(ordered) != len(g.nodes) {
+		var layer []Key
+		for key := range g.nodes {
+			if _, ok := ordered[key]; ok {
+				continue
+			}
+			if allOrdered(dependencies[key], ordered) {
+				layer = append(layer, key)
+			}
+		}
+		if len(layer) == 0 {
+			return nil, errors.Errorf("dependency cycle between %v", g.unorderedKeys(ordered))
+		}
+		sortKeys(layer)
+		for _, key := range layer {
+			ordered[key] = struct{}{}
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// stronglyConnectedComponents computes the strongly connected components through hard and soft edges with Tarjan's algorithm.
+func (g *Graph) stronglyConnectedComponents() [][]Key {
+	keys := make([]Key, 0, len(g.nodes))
+	for key := range g.nodes {
+		keys = append(keys, key)
+	}
+	sortKeys(keys)
+
+	index := 0
+	indexByKey := make(map[Key]int)
+	lowLinkByKey := make(map[Key]int)
+	onStack := make(map[Key]bool)
+	var stack []Key
+	var components [][]Key
+
+	var visit func(key Key)
+	visit = func(key Key) {
+		indexByKey[key] = index
+		lowLinkByKey[key] = index
+		index++
+		stack = append(stack, key)
+		onStack[key] = true
+		for _, dependency := range g.dependenciesOf(key) {
+			if _, visited := indexByKey[dependency]; !visited {
+				visit(dependency)
+				lowLinkByKey[key] = min(lowLinkByKey[key], lowLinkByKey[dependency])
+			} else if onStack[dependency] {
+				lowLinkByKey[key] = min(lowLinkByKey[key], indexByKey[dependency])
+			}
+		}
+		if lowLinkByKey[key] != indexByKey[key] {
+			return
+		}
+		var component []Key
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == key {
+				break
+			}
+		}
+		sortKeys(component)
+		components = append(components, component)
+	}
+	for _, key := range keys {
+		if _, visited := indexByKey[key]; !visited {
+			visit(key)
+		}
+	}
+	return components
+}
+
+// dependenciesOf returns the dependencies of object in the graph through hard or soft edges.
+func (g *Graph) dependenciesOf(key Key) []Key {
+	var dependencies []Key
+	for _, edges := range []map[Key]map[Key]struct{}{g.hardEdges, g.softEdges} {
+		for to := range edges[key] {
+			if _, ok := g.nodes[to]; ok {
+				dependencies = append(dependencies, to)
+			}
+		}
+	}
+	sortKeys(dependencies)
+	return dependencies
+}
+
+func (g *Graph) hasNodes(keys ...Key) bool {
+	for _, key := range keys {
+		if _, ok := g.nodes[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *Graph) unorderedKeys(ordered map[Key]struct{}) []Key {
+	var keys []Key
+	for key := range g.nodes {
+		if _, ok := ordered[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sortKeys(keys)
+	return keys
+}
+
+func addEdge(edges map[Key]map[Key]struct{}, from Key, to Key) {
+	if edges[from] == nil {
+		edges[from] = make(map[Key]struct{})
+	}
+	edges[from][to] = struct{}{}
+}
+
+func allOrdered(dependencies map[Key]struct{}, ordered map[Key]struct{}) bool {
+	for dependency := range dependencies {
+		if _, ok := ordered[dependency]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sortKeys(keys []Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+}
+
+func lessKey(a Key, b Key) bool {
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// String returns the human-readable form of key, e.g. "VirtualNode my-ns/my-vn".
+func (k Key) String() string {
+	if len(k.Namespace) == 0 {
+		return k.Kind + " " + k.Name
+	}
+	return k.Kind + " " + k.NamespacedName.String()
+}
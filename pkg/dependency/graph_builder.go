@@ -0,0 +1,74 @@
+package dependency
+
+import (
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/gatewayroute"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualrouter"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualservice"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BuildGraph builds the dependency graph between members of a mesh.
+// VirtualServices depend on their provider, VirtualRouters on their route targets,
+// and GatewayRoutes on their VirtualGateway and route targets through hard edges.
+// VirtualNodes depend on their backends through soft edges, since backends may refer back to the VirtualNode.
+func BuildGraph(members []client.Object) *Graph {
+	graph := NewGraph()
+	for _, member := range members {
+		key, ok := KeyForObject(member)
+		if !ok {
+			continue
+		}
+		graph.AddNode(key)
+		hardDependencies, softDependencies := dependenciesOfObject(member)
+		for _, dependency := range hardDependencies {
+			graph.AddHardEdge(key, dependency)
+		}
+		for _, dependency := range softDependencies {
+			graph.AddSoftEdge(key, dependency)
+		}
+	}
+	return graph
+}
+
+// dependenciesOfObject returns the objects that a mesh member depends on through hard and soft edges.
+func dependenciesOfObject(member client.Object) ([]Key, []Key) {
+	var hardDependencies, softDependencies []Key
+	switch obj := member.(type) {
+	case *appmesh.VirtualService:
+		for _, vnRef := range virtualservice.ExtractVirtualNodeReferences(obj) {
+			hardDependencies = append(hardDependencies, Key{Kind: references.DependencyKindVirtualNode, NamespacedName: references.ObjectKeyForVirtualNodeReference(obj, vnRef)})
+		}
+		for _, vrRef := range virtualservice.ExtractVirtualRouterReferences(obj) {
+			hardDependencies = append(hardDependencies, Key{Kind: references.DependencyKindVirtualRouter, NamespacedName: references.ObjectKeyForVirtualRouterReference(obj, vrRef)})
+		}
+	case *appmesh.VirtualRouter:
+		for _, vnRef := range virtualrouter.ExtractVirtualNodeReferences(obj) {
+			hardDependencies = append(hardDependencies, Key{Kind: references.DependencyKindVirtualNode, NamespacedName: references.ObjectKeyForVirtualNodeReference(obj, vnRef)})
+		}
+	case *appmesh.VirtualNode:
+		for _, vsRef := range virtualnode.ExtractVirtualServiceReferences(obj) {
+			softDependencies = append(softDependencies, Key{Kind: references.DependencyKindVirtualService, NamespacedName: references.ObjectKeyForVirtualServiceReference(obj, vsRef)})
+		}
+	case *appmesh.GatewayRoute:
+		if obj.Spec.VirtualGatewayRef != nil {
+			hardDependencies = append(hardDependencies, Key{Kind: references.DependencyKindVirtualGateway, NamespacedName: references.ObjectKeyForVirtualGatewayReference(obj, *obj.Spec.VirtualGatewayRef)})
+		}
+		for _, vsRef := range gatewayroute.ExtractVirtualServiceReferences(obj) {
+			hardDependencies = append(hardDependencies, Key{Kind: references.DependencyKindVirtualService, NamespacedName: references.ObjectKeyForVirtualServiceReference(obj, vsRef)})
+		}
+	}
+	return hardDependencies, softDependencies
+}
+
+// KeyForObject returns the key of a mesh member in the dependency graph, false if obj isn't a mesh member.
+func KeyForObject(obj client.Object) (Key, bool) {
+	kind, ok := references.DependencyKindOf(obj)
+	if !ok {
+		return Key{}, false
+	}
+	return Key{Kind: kind, NamespacedName: k8s.NamespacedName(obj)}, true
+}
@@ -0,0 +1,179 @@
+package dependency
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGraph_ReverseLayers(t *testing.T) {
+	vn := Key{Kind: "VirtualNode", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vn"}}
+	vnBackend := Key{Kind: "VirtualNode", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vn-backend"}}
+	vr := Key{Kind: "VirtualRouter", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vr"}}
+	vs := Key{Kind: "VirtualService", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vs"}}
+	vsBackend := Key{Kind: "VirtualService", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vs-backend"}}
+	type edge struct {
+		from Key
+		to   Key
+	}
+	tests := []struct {
+		name              string
+		nodes             []Key
+		hardEdges         []edge
+		softEdges         []edge
+		wantReverseLayers [][]Key
+		wantErr           error
+	}{
+		{
+			name:              "empty graph",
+			wantReverseLayers: nil,
+		},
+		{
+			name:              "hard edges order objects",
+			nodes:             []Key{vs, vr, vn},
+			hardEdges:         []edge{{from: vs, to: vr}, {from: vr, to: vn}},
+			wantReverseLayers: [][]Key{{vs}, {vr}, {vn}},
+		},
+		{
+			name:              "soft edges order objects outside cycles",
+			nodes:             []Key{vn, vsBackend, vnBackend},
+			hardEdges:         []edge{{from: vsBackend, to: vnBackend}},
+			softEdges:         []edge{{from: vn, to: vsBackend}},
+			wantReverseLayers: [][]Key{{vn}, {vsBackend}, {vnBackend}},
+		},
+		{
+			name:              "soft edges within cycles are ignored",
+			nodes:             []Key{vn, vs},
+			hardEdges:         []edge{{from: vs, to: vn}},
+			softEdges:         []edge{{from: vn, to: vs}},
+			wantReverseLayers: [][]Key{{vs}, {vn}},
+		},
+		{
+			name:              "edges to objects not in graph are ignored",
+			nodes:             []Key{vs},
+			hardEdges:         []edge{{from: vs, to: vn}},
+			wantReverseLayers: [][]Key{{vs}},
+		},
+		{
+			name:      "hard edges within cycles are rejected",
+			nodes:     []Key{vr, vs},
+			hardEdges: []edge{{from: vs, to: vr}, {from: vr, to: vs}},
+			wantErr:   errors.New("dependency cycle between [VirtualRouter ns/vr VirtualService ns/vs]"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			for _, node := range tt.nodes {
+				g.AddNode(node)
+			}
+			for _, e := range tt.hardEdges {
+				g.AddHardEdge(e.from, e.to)
+			}
+			for _, e := range tt.softEdges {
+				g.AddSoftEdge(e.from, e.to)
+			}
+			gotReverseLayers, err := g.ReverseLayers()
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantReverseLayers, gotReverseLayers)
+			}
+		})
+	}
+}
+
+func TestGraph_Dependencies(t *testing.T) {
+	vg := Key{Kind: "VirtualGateway", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vg"}}
+	gr := Key{Kind: "GatewayRoute", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "gr"}}
+	vn := Key{Kind: "VirtualNode", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vn"}}
+	vnBackend := Key{Kind: "VirtualNode", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vn-backend"}}
+	vr := Key{Kind: "VirtualRouter", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vr"}}
+	vs := Key{Kind: "VirtualService", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vs"}}
+	vsBackend := Key{Kind: "VirtualService", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vs-backend"}}
+	type edge struct {
+		from Key
+		to   Key
+	}
+	tests := []struct {
+		name      string
+		nodes     []Key
+		hardEdges []edge
+		softEdges []edge
+		key       Key
+		want      []Key
+	}{
+		{
+			name:      "direct and indirect dependencies",
+			nodes:     []Key{gr, vg, vs, vr, vn},
+			hardEdges: []edge{{from: gr, to: vg}, {from: gr, to: vs}, {from: vs, to: vr}, {from: vr, to: vn}},
+			key:       gr,
+			want:      []Key{vg, vn, vr, vs},
+		},
+		{
+			name:      "dependencies through soft edges outside cycles",
+			nodes:     []Key{vn, vsBackend, vnBackend},
+			hardEdges: []edge{{from: vsBackend, to: vnBackend}},
+			softEdges: []edge{{from: vn, to: vsBackend}},
+			key:       vn,
+			want:      []Key{vnBackend, vsBackend},
+		},
+		{
+			name:      "objects in a cycle aren't dependencies of each other",
+			nodes:     []Key{vn, vs, vsBackend, vnBackend},
+			hardEdges: []edge{{from: vs, to: vn}, {from: vsBackend, to: vnBackend}},
+			softEdges: []edge{{from: vn, to: vs}, {from: vn, to: vsBackend}},
+			key:       vs,
+			want:      []Key{vnBackend, vsBackend},
+		},
+		{
+			name:      "edges to objects not in graph are ignored",
+			nodes:     []Key{vs},
+			hardEdges: []edge{{from: vs, to: vn}},
+			key:       vs,
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			for _, node := range tt.nodes {
+				g.AddNode(node)
+			}
+			for _, e := range tt.hardEdges {
+				g.AddHardEdge(e.from, e.to)
+			}
+			for _, e := range tt.softEdges {
+				g.AddSoftEdge(e.from, e.to)
+			}
+			assert.Equal(t, tt.want, g.Dependencies(tt.key))
+		})
+	}
+}
+
+func TestKey_String(t *testing.T) {
+	tests := []struct {
+		name string
+		key  Key
+		want string
+	}{
+		{
+			name: "namespaced object",
+			key:  Key{Kind: "VirtualNode", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vn"}},
+			want: "VirtualNode ns/vn",
+		},
+		{
+			name: "cluster scoped object",
+			key:  Key{Kind: "Mesh", NamespacedName: types.NamespacedName{Name: "mesh"}},
+			want: "Mesh mesh",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.key.String())
+		})
+	}
+}
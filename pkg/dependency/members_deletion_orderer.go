@@ -0,0 +1,39 @@
+package dependency
+
+import (
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewMembersDeletionOrderer constructs new mesh.MembersDeletionOrderer that orders members by the dependency graph between them.
+func NewMembersDeletionOrderer() mesh.MembersDeletionOrderer {
+	return &membersDeletionOrderer{}
+}
+
+var _ mesh.MembersDeletionOrderer = &membersDeletionOrderer{}
+
+// membersDeletionOrderer deletes members before the members they depend on,
+// so that AppMesh never rejects deletion of a resource that is still referenced.
+type membersDeletionOrderer struct{}
+
+func (o *membersDeletionOrderer) Order(members []client.Object) ([][]client.Object, error) {
+	memberByKey := make(map[Key]client.Object, len(members))
+	for _, member := range members {
+		if key, ok := KeyForObject(member); ok {
+			memberByKey[key] = member
+		}
+	}
+	layers, err := BuildGraph(members).ReverseLayers()
+	if err != nil {
+		return nil, err
+	}
+	batches := make([][]client.Object, 0, len(layers))
+	for _, layer := range layers {
+		batch := make([]client.Object, 0, len(layer))
+		for _, key := range layer {
+			batch = append(batch, memberByKey[key])
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
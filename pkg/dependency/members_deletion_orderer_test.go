@@ -0,0 +1,161 @@
+package dependency
+
+import (
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_membersDeletionOrderer_Order(t *testing.T) {
+	vg := &appmesh.VirtualGateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vg"},
+	}
+	gr := &appmesh.GatewayRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "gr"},
+		Spec: appmesh.GatewayRouteSpec{
+			HTTPRoute: &appmesh.HTTPGatewayRoute{
+				Action: appmesh.HTTPGatewayRouteAction{
+					Target: appmesh.GatewayRouteTarget{
+						VirtualService: appmesh.GatewayRouteVirtualService{
+							VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs-front"},
+						},
+					},
+				},
+			},
+			VirtualGatewayRef: &appmesh.VirtualGatewayReference{Name: "vg"},
+		},
+	}
+	vsFront := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vs-front"},
+		Spec: appmesh.VirtualServiceSpec{
+			Provider: &appmesh.VirtualServiceProvider{
+				VirtualRouter: &appmesh.VirtualRouterServiceProvider{
+					VirtualRouterRef: &appmesh.VirtualRouterReference{Name: "vr-front"},
+				},
+			},
+		},
+	}
+	vrFront := &appmesh.VirtualRouter{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vr-front"},
+		Spec: appmesh.VirtualRouterSpec{
+			Routes: []appmesh.Route{
+				{
+					Name: "route",
+					HTTPRoute: &appmesh.HTTPRoute{
+						Action: appmesh.HTTPRouteAction{
+							WeightedTargets: []appmesh.WeightedTarget{
+								{VirtualNodeRef: &appmesh.VirtualNodeReference{Name: "vn-front"}, Weight: 100},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	vnFront := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn-front"},
+		Spec: appmesh.VirtualNodeSpec{
+			Backends: []appmesh.Backend{
+				{VirtualService: appmesh.VirtualServiceBackend{VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs-back"}}},
+			},
+		},
+	}
+	vsBack := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vs-back"},
+		Spec: appmesh.VirtualServiceSpec{
+			Provider: &appmesh.VirtualServiceProvider{
+				VirtualNode: &appmesh.VirtualNodeServiceProvider{
+					VirtualNodeRef: &appmesh.VirtualNodeReference{Name: "vn-back"},
+				},
+			},
+		},
+	}
+	vnBack := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn-back"},
+		Spec: appmesh.VirtualNodeSpec{
+			Backends: []appmesh.Backend{
+				{VirtualService: appmesh.VirtualServiceBackend{VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs-back"}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		members []client.Object
+		want    [][]client.Object
+	}{
+		{
+			name:    "no members",
+			members: nil,
+			want:    [][]client.Object{},
+		},
+		{
+			name:    "members are deleted before their dependencies",
+			members: []client.Object{vnFront, vrFront, vsFront, vg, gr},
+			want: [][]client.Object{
+				{gr},
+				{vg, vsFront},
+				{vrFront},
+				{vnFront},
+			},
+		},
+		{
+			name:    "virtualNode backends are deleted after the virtualNode unless they refer back to it",
+			members: []client.Object{vnBack, vsBack, vnFront, vsFront, vrFront},
+			want: [][]client.Object{
+				{vsFront},
+				{vrFront},
+				{vnFront},
+				{vsBack},
+				{vnBack},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewMembersDeletionOrderer()
+			got, err := o.Order(tt.members)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKeyForObject(t *testing.T) {
+	tests := []struct {
+		name   string
+		obj    client.Object
+		want   Key
+		wantOK bool
+	}{
+		{
+			name:   "gatewayRoute",
+			obj:    &appmesh.GatewayRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "gr"}},
+			want:   Key{Kind: "GatewayRoute", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "gr"}},
+			wantOK: true,
+		},
+		{
+			name:   "virtualNode",
+			obj:    &appmesh.VirtualNode{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn"}},
+			want:   Key{Kind: "VirtualNode", NamespacedName: types.NamespacedName{Namespace: "ns", Name: "vn"}},
+			wantOK: true,
+		},
+		{
+			name:   "mesh isn't a mesh member",
+			obj:    &appmesh.Mesh{ObjectMeta: metav1.ObjectMeta{Name: "mesh"}},
+			want:   Key{},
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOK := KeyForObject(tt.obj)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, gotOK)
+		})
+	}
+}
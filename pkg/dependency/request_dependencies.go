@@ -0,0 +1,138 @@
+package dependency
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// kindByController is the kind of mesh members reconciled by each controller, by controller name.
+var kindByController = map[string]string{
+	"gatewayroute":   references.DependencyKindGatewayRoute,
+	"virtualgateway": references.DependencyKindVirtualGateway,
+	"virtualnode":    references.DependencyKindVirtualNode,
+	"virtualrouter":  references.DependencyKindVirtualRouter,
+	"virtualservice": references.DependencyKindVirtualService,
+}
+
+// NewRequestDependencies constructs new runtime.RequestDependencies that orders requests of mesh members by the dependency graph of their mesh.
+func NewRequestDependencies(k8sClient client.Client) runtime.RequestDependencies {
+	return &requestDependencies{
+		k8sClient: k8sClient,
+	}
+}
+
+var _ runtime.RequestDependencies = &requestDependencies{}
+
+// requestDependencies resolves the members that a member depends on directly or indirectly within its mesh,
+// and orders its request after theirs unless they're in a cycle with it.
+type requestDependencies struct {
+	k8sClient client.Client
+}
+
+func (d *requestDependencies) Orders(controllerName string) bool {
+	_, ok := kindByController[controllerName]
+	return ok
+}
+
+func (d *requestDependencies) PendingDependencies(req runtime.ControllerRequest, pending []runtime.ControllerRequest) []runtime.ControllerRequest {
+	kind, ok := kindByController[req.Controller]
+	if !ok {
+		return nil
+	}
+	key := Key{Kind: kind, NamespacedName: req.NamespacedName}
+	graph := d.buildGraphFrom(context.Background(), key)
+	pendingByKey := make(map[Key]runtime.ControllerRequest, len(pending))
+	for _, pendingReq := range pending {
+		if pendingKind, ok := kindByController[pendingReq.Controller]; ok {
+			pendingByKey[Key{Kind: pendingKind, NamespacedName: pendingReq.NamespacedName}] = pendingReq
+		}
+	}
+	var pendingDependencies []runtime.ControllerRequest
+	for _, dependency := range graph.Dependencies(key) {
+		if pendingReq, ok := pendingByKey[dependency]; ok {
+			pendingDependencies = append(pendingDependencies, pendingReq)
+		}
+	}
+	return pendingDependencies
+}
+
+// buildGraphFrom builds the dependency graph between the member of key and the members it depends on directly or indirectly within its mesh.
+// members are read from the cache, and members that cannot be read are left out of the graph.
+func (d *requestDependencies) buildGraphFrom(ctx context.Context, key Key) *Graph {
+	obj, ok := d.getMember(ctx, key)
+	if !ok {
+		return NewGraph()
+	}
+	meshName, ok := meshNameOfMember(obj)
+	if !ok {
+		return NewGraph()
+	}
+	members := []client.Object{obj}
+	visited := map[Key]struct{}{key: {}}
+	for i := 0; i < len(members); i++ {
+		hardDependencies, softDependencies := dependenciesOfObject(members[i])
+		for _, dependency := range append(hardDependencies, softDependencies...) {
+			if _, ok := visited[dependency]; ok {
+				continue
+			}
+			visited[dependency] = struct{}{}
+			member, ok := d.getMember(ctx, dependency)
+			if !ok {
+				continue
+			}
+			if memberMeshName, ok := meshNameOfMember(member); !ok || memberMeshName != meshName {
+				continue
+			}
+			members = append(members, member)
+		}
+	}
+	return BuildGraph(members)
+}
+
+// getMember returns the mesh member of key, false if it's not found.
+func (d *requestDependencies) getMember(ctx context.Context, key Key) (client.Object, bool) {
+	var obj client.Object
+	switch key.Kind {
+	case references.DependencyKindGatewayRoute:
+		obj = &appmesh.GatewayRoute{}
+	case references.DependencyKindVirtualGateway:
+		obj = &appmesh.VirtualGateway{}
+	case references.DependencyKindVirtualNode:
+		obj = &appmesh.VirtualNode{}
+	case references.DependencyKindVirtualRouter:
+		obj = &appmesh.VirtualRouter{}
+	case references.DependencyKindVirtualService:
+		obj = &appmesh.VirtualService{}
+	default:
+		return nil, false
+	}
+	if err := d.k8sClient.Get(ctx, key.NamespacedName, obj); err != nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+// meshNameOfMember returns the name of mesh that member belongs to, false if its mesh isn't set yet.
+func meshNameOfMember(member client.Object) (string, bool) {
+	var meshRef *appmesh.MeshReference
+	switch obj := member.(type) {
+	case *appmesh.GatewayRoute:
+		meshRef = obj.Spec.MeshRef
+	case *appmesh.VirtualGateway:
+		meshRef = obj.Spec.MeshRef
+	case *appmesh.VirtualNode:
+		meshRef = obj.Spec.MeshRef
+	case *appmesh.VirtualRouter:
+		meshRef = obj.Spec.MeshRef
+	case *appmesh.VirtualService:
+		meshRef = obj.Spec.MeshRef
+	}
+	if meshRef == nil {
+		return "", false
+	}
+	return meshRef.Name, true
+}
@@ -0,0 +1,144 @@
+package dependency
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_requestDependencies_Orders(t *testing.T) {
+	d := &requestDependencies{}
+	assert.True(t, d.Orders("virtualnode"))
+	assert.True(t, d.Orders("gatewayroute"))
+	assert.False(t, d.Orders("cloudMap"))
+	assert.False(t, d.Orders("mesh"))
+}
+
+func Test_requestDependencies_PendingDependencies(t *testing.T) {
+	meshRef := &appmesh.MeshReference{Name: "mesh", UID: "uid"}
+	otherMeshRef := &appmesh.MeshReference{Name: "other-mesh", UID: "other-uid"}
+	gr := &appmesh.GatewayRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "gr"},
+		Spec: appmesh.GatewayRouteSpec{
+			HTTPRoute: &appmesh.HTTPGatewayRoute{
+				Action: appmesh.HTTPGatewayRouteAction{
+					Target: appmesh.GatewayRouteTarget{
+						VirtualService: appmesh.GatewayRouteVirtualService{
+							VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs"},
+						},
+					},
+				},
+			},
+			VirtualGatewayRef: &appmesh.VirtualGatewayReference{Name: "vg"},
+			MeshRef:           meshRef,
+		},
+	}
+	vg := &appmesh.VirtualGateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vg"},
+		Spec:       appmesh.VirtualGatewaySpec{MeshRef: meshRef},
+	}
+	vs := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vs"},
+		Spec: appmesh.VirtualServiceSpec{
+			Provider: &appmesh.VirtualServiceProvider{
+				VirtualNode: &appmesh.VirtualNodeServiceProvider{
+					VirtualNodeRef: &appmesh.VirtualNodeReference{Name: "vn"},
+				},
+			},
+			MeshRef: meshRef,
+		},
+	}
+	// vn uses vs as backend, so vn and vs are in a cycle.
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn"},
+		Spec: appmesh.VirtualNodeSpec{
+			Backends: []appmesh.Backend{
+				{VirtualService: appmesh.VirtualServiceBackend{VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs"}}},
+				{VirtualService: appmesh.VirtualServiceBackend{VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs-other-mesh"}}},
+			},
+			MeshRef: meshRef,
+		},
+	}
+	vsOtherMesh := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vs-other-mesh"},
+		Spec:       appmesh.VirtualServiceSpec{MeshRef: otherMeshRef},
+	}
+	newRequest := func(controller string, name string) runtime.ControllerRequest {
+		return runtime.ControllerRequest{
+			Controller: controller,
+			Request:    reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: name}},
+		}
+	}
+	pending := []runtime.ControllerRequest{
+		newRequest("gatewayroute", "gr"),
+		newRequest("virtualgateway", "vg"),
+		newRequest("virtualservice", "vs"),
+		newRequest("virtualnode", "vn"),
+		newRequest("virtualservice", "vs-other-mesh"),
+		newRequest("cloudMap", "vn"),
+	}
+	tests := []struct {
+		name string
+		req  runtime.ControllerRequest
+		want []runtime.ControllerRequest
+	}{
+		{
+			name: "gatewayRoute is reconciled after its virtualGateway and route targets",
+			req:  newRequest("gatewayroute", "gr"),
+			want: []runtime.ControllerRequest{
+				newRequest("virtualgateway", "vg"),
+				newRequest("virtualnode", "vn"),
+				newRequest("virtualservice", "vs"),
+			},
+		},
+		{
+			name: "virtualService is reconciled together with virtualNode in a cycle",
+			req:  newRequest("virtualservice", "vs"),
+			want: nil,
+		},
+		{
+			name: "virtualNode is reconciled together with virtualService in a cycle, and ignores members of other meshes",
+			req:  newRequest("virtualnode", "vn"),
+			want: nil,
+		},
+		{
+			name: "virtualGateway has no dependencies",
+			req:  newRequest("virtualgateway", "vg"),
+			want: nil,
+		},
+		{
+			name: "deleted members have no dependencies",
+			req:  newRequest("virtualrouter", "vr"),
+			want: nil,
+		},
+		{
+			name: "requests of other controllers have no dependencies",
+			req:  newRequest("cloudMap", "vn"),
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sSchema := k8sruntime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+			for _, obj := range []client.Object{gr, vg, vs, vn, vsOtherMesh} {
+				assert.NoError(t, k8sClient.Create(ctx, obj.DeepCopyObject().(client.Object)))
+			}
+			d := NewRequestDependencies(k8sClient)
+			assert.Equal(t, tt.want, d.PendingDependencies(tt.req, pending))
+		})
+	}
+}
@@ -3,8 +3,8 @@ package gatewayroute
 import (
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -28,7 +28,7 @@ func ExtractVirtualServiceReferences(gr *appmesh.GatewayRoute) []appmesh.Virtual
 	return vsRefs
 }
 
-func VirtualServiceReferenceIndexFunc(obj runtime.Object) []types.NamespacedName {
+func VirtualServiceReferenceIndexFunc(obj client.Object) []types.NamespacedName {
 	gr := obj.(*appmesh.GatewayRoute)
 	vsRefs := ExtractVirtualServiceReferences(gr)
 	var vsKeys []types.NamespacedName
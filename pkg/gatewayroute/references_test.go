@@ -5,8 +5,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"testing"
 )
 
@@ -154,7 +154,7 @@ func Test_ExtractVirtualServiceReferences(t *testing.T) {
 
 func TestVirtualServiceReferenceIndexFunc(t *testing.T) {
 	type args struct {
-		obj runtime.Object
+		obj client.Object
 	}
 	tests := []struct {
 		name string
@@ -6,10 +6,11 @@ import (
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
-	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualgateway"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualservice"
 	"github.com/aws/aws-sdk-go/aws"
@@ -20,8 +21,10 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/types"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -39,47 +42,66 @@ func NewDefaultResourceManager(
 	appMeshSDK services.AppMesh,
 	referencesResolver references.Resolver,
 	accountID string,
+	defaultDeletionPolicy deletion.Policy,
 	log logr.Logger) ResourceManager {
 
 	return &defaultResourceManager{
-		k8sClient:          k8sClient,
-		appMeshSDK:         appMeshSDK,
-		referencesResolver: referencesResolver,
-		accountID:          accountID,
-		log:                log,
+		k8sClient:             k8sClient,
+		appMeshSDK:            appMeshSDK,
+		referencesResolver:    referencesResolver,
+		accountID:             accountID,
+		defaultDeletionPolicy: defaultDeletionPolicy,
+		log:                   log,
 	}
 }
 
 // defaultResourceManager implements ResourceManager
 type defaultResourceManager struct {
-	k8sClient          client.Client
-	appMeshSDK         services.AppMesh
-	referencesResolver references.Resolver
-	accountID          string
-	log                logr.Logger
+	k8sClient             client.Client
+	appMeshSDK            services.AppMesh
+	referencesResolver    references.Resolver
+	accountID             string
+	defaultDeletionPolicy deletion.Policy
+	log                   logr.Logger
 }
 
 func (m *defaultResourceManager) Reconcile(ctx context.Context, gr *appmesh.GatewayRoute) error {
 	ms, err := m.findMeshDependency(ctx, gr)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return m.waitForDependencies(ctx, gr, []appmesh.DependencyReference{
+				references.NewDependencyReference(references.DependencyKindMesh, types.NamespacedName{Name: gr.Spec.MeshRef.Name}, appmesh.DependencyReasonNotFound),
+			})
+		}
 		return err
 	}
-	if err := m.validateMeshDependency(ctx, ms); err != nil {
-		return err
-	}
+	waitingFor := m.validateMeshDependency(ctx, ms)
 	vg, err := m.findVirtualGatewayDependency(ctx, gr)
 	if err != nil {
-		return err
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		vgKey := references.ObjectKeyForVirtualGatewayReference(gr, *gr.Spec.VirtualGatewayRef)
+		waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualGateway, vgKey, appmesh.DependencyReasonNotFound))
+	} else {
+		vgWaitingFor, err := m.validateVirtualGatewayDependency(ctx, ms, vg)
+		if err != nil {
+			return err
+		}
+		waitingFor = append(waitingFor, vgWaitingFor...)
 	}
-	if err := m.validateVirtualGatewayDependency(ctx, ms, vg); err != nil {
+	vsByKey, vsWaitingFor, err := m.findVirtualServiceDependencies(ctx, gr)
+	if err != nil {
 		return err
 	}
-	vsByKey, err := m.findVirtualServiceDependencies(ctx, gr)
+	waitingFor = append(waitingFor, vsWaitingFor...)
+	vsWaitingFor, err = m.validateVirtualServiceDependencies(ctx, ms, vsByKey)
 	if err != nil {
 		return err
 	}
-	if err := m.validateVirtualServiceDependencies(ctx, ms, vsByKey); err != nil {
-		return err
+	waitingFor = append(waitingFor, vsWaitingFor...)
+	if len(waitingFor) != 0 {
+		return m.waitForDependencies(ctx, gr, waitingFor)
 	}
 
 	sdkGR, err := m.findSDKGatewayRoute(ctx, ms, vg, gr)
@@ -102,8 +124,26 @@ func (m *defaultResourceManager) Reconcile(ctx context.Context, gr *appmesh.Gate
 }
 
 func (m *defaultResourceManager) Cleanup(ctx context.Context, gr *appmesh.GatewayRoute) error {
+	deletionPolicy, err := deletion.PolicyOf(gr, m.defaultDeletionPolicy)
+	if err != nil {
+		return err
+	}
+	if deletionPolicy == deletion.PolicyRetain {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain mesh gatewayRoute by deletion policy",
+			"gatewayRoute", k8s.NamespacedName(gr),
+			"gatewayRouteARN", aws.StringValue(gr.Status.GatewayRouteARN),
+		)
+		return nil
+	}
 	ms, err := m.findMeshDependency(ctx, gr)
 	if err != nil {
+		// mesh is deleted with Orphan membersDeletionPolicy, the AppMesh gatewayRoute is orphaned along with the AppMesh mesh.
+		if apierrors.IsNotFound(err) {
+			tracing.LoggerFromContext(ctx, m.log).Info("skip mesh gatewayRoute cleanup since mesh is deleted",
+				"gatewayRoute", k8s.NamespacedName(gr),
+			)
+			return nil
+		}
 		return err
 	}
 	vg, err := m.findVirtualGatewayDependency(ctx, gr)
@@ -136,10 +176,12 @@ func (m *defaultResourceManager) findMeshDependency(ctx context.Context, gr *app
 	return ms, nil
 }
 
-// validateMeshDependency validate the Mesh dependency for this gatewayRoute.
-func (m *defaultResourceManager) validateMeshDependency(ctx context.Context, ms *appmesh.Mesh) error {
+// validateMeshDependency validate the Mesh dependency for this gatewayRoute, and returns it if it's unresolved.
+func (m *defaultResourceManager) validateMeshDependency(ctx context.Context, ms *appmesh.Mesh) []appmesh.DependencyReference {
 	if !mesh.IsMeshActive(ms) {
-		return runtime.NewRequeueError(errors.New("mesh is not active yet"))
+		return []appmesh.DependencyReference{
+			references.NewDependencyReference(references.DependencyKindMesh, k8s.NamespacedName(ms), appmesh.DependencyReasonNotActive),
+		}
 	}
 	return nil
 }
@@ -156,21 +198,24 @@ func (m *defaultResourceManager) findVirtualGatewayDependency(ctx context.Contex
 	return vg, nil
 }
 
-// validateVirtualGatewayDependency validates the VirtualGateway dependencies for this gatewayRoute.
-func (m *defaultResourceManager) validateVirtualGatewayDependency(ctx context.Context, ms *appmesh.Mesh, vg *appmesh.VirtualGateway) error {
+// validateVirtualGatewayDependency validates the VirtualGateway dependencies for this gatewayRoute, and returns it if it's unresolved.
+func (m *defaultResourceManager) validateVirtualGatewayDependency(ctx context.Context, ms *appmesh.Mesh, vg *appmesh.VirtualGateway) ([]appmesh.DependencyReference, error) {
 	if vg.Spec.MeshRef == nil || !mesh.IsMeshReferenced(ms, *vg.Spec.MeshRef) {
-		return errors.Errorf("virtualGateway %v didn't belong to mesh %v", k8s.NamespacedName(vg), k8s.NamespacedName(ms))
+		return nil, errors.Errorf("virtualGateway %v didn't belong to mesh %v", k8s.NamespacedName(vg), k8s.NamespacedName(ms))
 	}
 	if !virtualgateway.IsVirtualGatewayActive(vg) {
-		return runtime.NewRequeueError(errors.New("virtualGateway is not active yet"))
+		return []appmesh.DependencyReference{
+			references.NewDependencyReference(references.DependencyKindVirtualGateway, k8s.NamespacedName(vg), appmesh.DependencyReasonNotActive),
+		}, nil
 	}
-	return nil
+	return nil, nil
 }
 
-// findVirtualServiceDependencies find the VirtualService dependency for this gatewayRoute.
-func (m *defaultResourceManager) findVirtualServiceDependencies(ctx context.Context, gr *appmesh.GatewayRoute) (map[types.NamespacedName]*appmesh.VirtualService, error) {
+// findVirtualServiceDependencies find the VirtualService dependency for this gatewayRoute, and the ones not found.
+func (m *defaultResourceManager) findVirtualServiceDependencies(ctx context.Context, gr *appmesh.GatewayRoute) (map[types.NamespacedName]*appmesh.VirtualService, []appmesh.DependencyReference, error) {
 	vsRefs := ExtractVirtualServiceReferences(gr)
 	vsByKey := make(map[types.NamespacedName]*appmesh.VirtualService)
+	var waitingFor []appmesh.DependencyReference
 	for _, vsRef := range vsRefs {
 		vsKey := references.ObjectKeyForVirtualServiceReference(gr, vsRef)
 		if _, ok := vsByKey[vsKey]; ok {
@@ -178,24 +223,43 @@ func (m *defaultResourceManager) findVirtualServiceDependencies(ctx context.Cont
 		}
 		vs, err := m.referencesResolver.ResolveVirtualServiceReference(ctx, gr, vsRef)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to resolve virtualServiceRef")
+			if apierrors.IsNotFound(err) {
+				waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualService, vsKey, appmesh.DependencyReasonNotFound))
+				continue
+			}
+			return nil, nil, errors.Wrapf(err, "failed to resolve virtualServiceRef")
 		}
 		vsByKey[vsKey] = vs
 	}
-	return vsByKey, nil
+	return vsByKey, waitingFor, nil
 }
 
-// validateVirtualServiceDependencies validates the VirtualService dependencies for this gatewayRoute.
-func (m *defaultResourceManager) validateVirtualServiceDependencies(ctx context.Context, ms *appmesh.Mesh, vsByKey map[types.NamespacedName]*appmesh.VirtualService) error {
-	for _, vs := range vsByKey {
+// validateVirtualServiceDependencies validates the VirtualService dependencies for this gatewayRoute, and returns the unresolved ones.
+func (m *defaultResourceManager) validateVirtualServiceDependencies(ctx context.Context, ms *appmesh.Mesh, vsByKey map[types.NamespacedName]*appmesh.VirtualService) ([]appmesh.DependencyReference, error) {
+	var waitingFor []appmesh.DependencyReference
+	for vsKey, vs := range vsByKey {
 		if vs.Spec.MeshRef == nil || !mesh.IsMeshReferenced(ms, *vs.Spec.MeshRef) {
-			return errors.Errorf("virtualService %v didn't belong to mesh %v", k8s.NamespacedName(vs), k8s.NamespacedName(ms))
+			return nil, errors.Errorf("virtualService %v didn't belong to mesh %v", k8s.NamespacedName(vs), k8s.NamespacedName(ms))
 		}
 		if !virtualservice.IsVirtualServiceActive(vs) {
-			return runtime.NewRequeueError(errors.New("virtualService is not active yet"))
+			waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualService, vsKey, appmesh.DependencyReasonNotActive))
 		}
 	}
-	return nil
+	return waitingFor, nil
+}
+
+// waitForDependencies records the unresolved dependencies in gatewayRoute status, and requeues it until they're resolved.
+// the gatewayRoute is enqueued by watches once its dependencies become ready.
+func (m *defaultResourceManager) waitForDependencies(ctx context.Context, gr *appmesh.GatewayRoute, waitingFor []appmesh.DependencyReference) error {
+	waitingFor = references.NormalizeDependencyReferences(waitingFor)
+	if !reflect.DeepEqual(gr.Status.WaitingFor, waitingFor) {
+		oldGR := gr.DeepCopy()
+		gr.Status.WaitingFor = waitingFor
+		if err := m.k8sClient.Status().Patch(ctx, gr, client.MergeFrom(oldGR)); err != nil {
+			return err
+		}
+	}
+	return references.NewWaitingForError(waitingFor)
 }
 
 func (m *defaultResourceManager) findSDKGatewayRoute(ctx context.Context, ms *appmesh.Mesh, vg *appmesh.VirtualGateway, gr *appmesh.GatewayRoute) (*appmeshsdk.GatewayRouteData, error) {
@@ -245,7 +309,7 @@ func (m *defaultResourceManager) updateSDKGatewayRoute(ctx context.Context, sdkG
 		return sdkGR, nil
 	}
 	if !m.isSDKGatewayRouteControlledByCRDGatewayRoute(ctx, sdkGR, gr) {
-		m.log.V(2).Info("skip gatewayRoute update since it's not controlled",
+		tracing.LoggerFromContext(ctx, m.log).V(2).Info("skip gatewayRoute update since it's not controlled",
 			"gatewayRoute", k8s.NamespacedName(gr),
 			"gatewayRouteARN", aws.StringValue(sdkGR.Metadata.Arn),
 		)
@@ -253,7 +317,7 @@ func (m *defaultResourceManager) updateSDKGatewayRoute(ctx context.Context, sdkG
 	}
 
 	diff := cmp.Diff(desiredSDKGRSpec, actualSDKGRSpec, opts)
-	m.log.V(2).Info("gatewayRouteSpec changed",
+	tracing.LoggerFromContext(ctx, m.log).V(2).Info("gatewayRouteSpec changed",
 		"gatewayRoute", k8s.NamespacedName(gr),
 		"actualSDKGRSpec", actualSDKGRSpec,
 		"desiredSDKGRSpec", desiredSDKGRSpec,
@@ -274,7 +338,7 @@ func (m *defaultResourceManager) updateSDKGatewayRoute(ctx context.Context, sdkG
 
 func (m *defaultResourceManager) deleteSDKGatewayRoute(ctx context.Context, sdkGR *appmeshsdk.GatewayRouteData, ms *appmesh.Mesh, vg *appmesh.VirtualGateway, gr *appmesh.GatewayRoute) error {
 	if !m.isSDKGatewayRouteOwnedByCRDGatewayRoute(ctx, sdkGR, gr) {
-		m.log.V(2).Info("skip mesh gatewayRoute since its not owned",
+		tracing.LoggerFromContext(ctx, m.log).V(2).Info("skip mesh gatewayRoute since its not owned",
 			"gatewayRoute", k8s.NamespacedName(gr),
 			"gatewayRouteARN", aws.StringValue(sdkGR.Metadata.Arn),
 		)
@@ -305,6 +369,10 @@ func (m *defaultResourceManager) updateCRDGatewayRoute(ctx context.Context, gr *
 		gr.Status.ObservedGeneration = aws.Int64(gr.Generation)
 		needsUpdate = true
 	}
+	if len(gr.Status.WaitingFor) != 0 {
+		gr.Status.WaitingFor = nil
+		needsUpdate = true
+	}
 
 	grActiveConditionStatus := corev1.ConditionFalse
 	if sdkGR.Status != nil && aws.StringValue(sdkGR.Status.Status) == appmeshsdk.GatewayRouteStatusCodeActive {
@@ -15,8 +15,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -387,9 +389,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 		mesh *appmesh.Mesh
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr error
+		name string
+		args args
+		want []appmesh.DependencyReference
 	}{
 		{
 			name: "valid mesh",
@@ -410,7 +412,7 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: nil,
+			want: nil,
 		},
 		{
 			name: "inactive mesh",
@@ -431,7 +433,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: errors.New("mesh is not active yet"),
+			want: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -441,12 +445,8 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				log: logr.New(&log.NullLogSink{}),
 			}
 
-			err := m.validateMeshDependency(ctx, tt.args.mesh)
-			if tt.wantErr != nil {
-				assert.EqualError(t, err, tt.wantErr.Error())
-			} else {
-				assert.NoError(t, err)
-			}
+			got := m.validateMeshDependency(ctx, tt.args.mesh)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -459,11 +459,12 @@ func Test_defaultResourceManager_findVirtualServiceDependencies(t *testing.T) {
 		gr *appmesh.GatewayRoute
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    map[types.NamespacedName]*appmesh.VirtualService
-		wantErr error
+		name           string
+		fields         fields
+		args           args
+		want           map[types.NamespacedName]*appmesh.VirtualService
+		wantWaitingFor []appmesh.DependencyReference
+		wantErr        error
 	}{
 		{
 			name: "gatewayRoute with a virtualservice backend",
@@ -528,6 +529,43 @@ func Test_defaultResourceManager_findVirtualServiceDependencies(t *testing.T) {
 				}}},
 			wantErr: nil,
 		},
+		{
+			name: "gatewayRoute with virtualservice not found",
+			args: args{
+				gr: &appmesh.GatewayRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "gr-1",
+					},
+					Spec: appmesh.GatewayRouteSpec{
+						HTTPRoute: &appmesh.HTTPGatewayRoute{
+							Match: appmesh.HTTPGatewayRouteMatch{
+								Prefix: aws.String("prefix"),
+							},
+							Action: appmesh.HTTPGatewayRouteAction{
+								Target: appmesh.GatewayRouteTarget{
+									VirtualService: appmesh.GatewayRouteVirtualService{
+										VirtualServiceRef: &appmesh.VirtualServiceReference{
+											Namespace: aws.String("ns-1"),
+											Name:      "vs-1",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			fields: fields{
+				ResolveVirtualServiceReference: func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualServiceReference) (*appmesh.VirtualService, error) {
+					return nil, errors.Wrapf(apierrors.NewNotFound(schema.GroupResource{Group: "appmesh.k8s.aws", Resource: "virtualservices"}, "vs-1"), "unable to fetch virtualService")
+				},
+			},
+			want: map[types.NamespacedName]*appmesh.VirtualService{},
+			wantWaitingFor: []appmesh.DependencyReference{
+				{Kind: "VirtualService", Namespace: "ns-1", Name: "vs-1", Reason: appmesh.DependencyReasonNotFound},
+			},
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -545,13 +583,155 @@ func Test_defaultResourceManager_findVirtualServiceDependencies(t *testing.T) {
 				resolver.EXPECT().ResolveVirtualServiceReference(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveVirtualServiceReference)
 			}
 
-			vsmap, err := m.findVirtualServiceDependencies(ctx, tt.args.gr)
+			vsmap, waitingFor, err := m.findVirtualServiceDependencies(ctx, tt.args.gr)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, vsmap)
+				assert.Equal(t, tt.wantWaitingFor, waitingFor)
+			}
+		})
+	}
+}
+
+func Test_defaultResourceManager_Reconcile_waitingForDependencies(t *testing.T) {
+	activeMesh := &appmesh.Mesh{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-mesh",
+			UID:  "uid-1",
+		},
+		Spec: appmesh.MeshSpec{
+			AWSName: aws.String("my-mesh"),
+		},
+		Status: appmesh.MeshStatus{
+			Conditions: []appmesh.MeshCondition{
+				{
+					Type:   appmesh.MeshActive,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}
+	gr := &appmesh.GatewayRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "gr-1",
+		},
+		Spec: appmesh.GatewayRouteSpec{
+			AWSName: aws.String("gr-1_ns-1"),
+			MeshRef: &appmesh.MeshReference{
+				Name: "my-mesh",
+				UID:  "uid-1",
+			},
+			VirtualGatewayRef: &appmesh.VirtualGatewayReference{
+				Namespace: aws.String("ns-1"),
+				Name:      "vg-1",
+			},
+			HTTPRoute: &appmesh.HTTPGatewayRoute{
+				Match: appmesh.HTTPGatewayRouteMatch{
+					Prefix: aws.String("/"),
+				},
+				Action: appmesh.HTTPGatewayRouteAction{
+					Target: appmesh.GatewayRouteTarget{
+						VirtualService: appmesh.GatewayRouteVirtualService{
+							VirtualServiceRef: &appmesh.VirtualServiceReference{
+								Namespace: aws.String("ns-1"),
+								Name:      "vs-1",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	type fields struct {
+		ResolveMeshReference           func(ctx context.Context, ref appmesh.MeshReference) (*appmesh.Mesh, error)
+		ResolveVirtualGatewayReference func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualGatewayReference) (*appmesh.VirtualGateway, error)
+		ResolveVirtualServiceReference func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualServiceReference) (*appmesh.VirtualService, error)
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		wantWaitingFor []appmesh.DependencyReference
+		wantErr        error
+	}{
+		{
+			name: "mesh not found",
+			fields: fields{
+				ResolveMeshReference: func(ctx context.Context, ref appmesh.MeshReference) (*appmesh.Mesh, error) {
+					return nil, errors.Wrapf(apierrors.NewNotFound(schema.GroupResource{Group: "appmesh.k8s.aws", Resource: "meshes"}, "my-mesh"), "unable to fetch mesh: my-mesh")
+				},
+			},
+			wantWaitingFor: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotFound},
+			},
+			wantErr: errors.New("waiting for Mesh my-mesh (NotFound)"),
+		},
+		{
+			name: "virtualGateway not found and virtualService not active",
+			fields: fields{
+				ResolveMeshReference: func(ctx context.Context, ref appmesh.MeshReference) (*appmesh.Mesh, error) {
+					return activeMesh, nil
+				},
+				ResolveVirtualGatewayReference: func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualGatewayReference) (*appmesh.VirtualGateway, error) {
+					return nil, errors.Wrapf(apierrors.NewNotFound(schema.GroupResource{Group: "appmesh.k8s.aws", Resource: "virtualgateways"}, "vg-1"), "unable to fetch virtualGateway")
+				},
+				ResolveVirtualServiceReference: func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualServiceReference) (*appmesh.VirtualService, error) {
+					return &appmesh.VirtualService{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns-1",
+							Name:      "vs-1",
+						},
+						Spec: appmesh.VirtualServiceSpec{
+							MeshRef: &appmesh.MeshReference{
+								Name: "my-mesh",
+								UID:  "uid-1",
+							},
+						},
+					}, nil
+				},
+			},
+			wantWaitingFor: []appmesh.DependencyReference{
+				{Kind: "VirtualGateway", Namespace: "ns-1", Name: "vg-1", Reason: appmesh.DependencyReasonNotFound},
+				{Kind: "VirtualService", Namespace: "ns-1", Name: "vs-1", Reason: appmesh.DependencyReasonNotActive},
+			},
+			wantErr: errors.New("waiting for VirtualGateway ns-1/vg-1 (NotFound), VirtualService ns-1/vs-1 (NotActive)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			resolver := mock_resolver.NewMockResolver(ctrl)
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithStatusSubresource(&appmesh.GatewayRoute{}).Build()
+			m := &defaultResourceManager{
+				k8sClient:          k8sClient,
+				referencesResolver: resolver,
+				log:                logr.New(&log.NullLogSink{}),
 			}
+			if tt.fields.ResolveMeshReference != nil {
+				resolver.EXPECT().ResolveMeshReference(gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveMeshReference)
+			}
+			if tt.fields.ResolveVirtualGatewayReference != nil {
+				resolver.EXPECT().ResolveVirtualGatewayReference(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveVirtualGatewayReference)
+			}
+			if tt.fields.ResolveVirtualServiceReference != nil {
+				resolver.EXPECT().ResolveVirtualServiceReference(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveVirtualServiceReference)
+			}
+
+			assert.NoError(t, k8sClient.Create(ctx, gr.DeepCopy()))
+			reconcileGR := &appmesh.GatewayRoute{}
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(gr), reconcileGR))
+			err := m.Reconcile(ctx, reconcileGR)
+			assert.EqualError(t, err, tt.wantErr.Error())
+			gotGR := &appmesh.GatewayRoute{}
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(gr), gotGR))
+			assert.Equal(t, tt.wantWaitingFor, gotGR.Status.WaitingFor)
 		})
 	}
 }
@@ -0,0 +1,72 @@
+package inject
+
+import (
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/certmanager"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	certificateVolumeName = "appmesh-certificate-volume"
+)
+
+type certificateMutatorConfig struct {
+	// whether a cert-manager certificate is requested for the virtualNode/virtualGateway of pod.
+	enabled bool
+	// name of the Secret that contains the certificate.
+	secretName string
+	// path within envoy container to mount the Secret at.
+	mountPath string
+	// revision of the certificate at the time of injection.
+	revision string
+}
+
+func newCertificateMutator(mutatorConfig certificateMutatorConfig) *certificateMutator {
+	return &certificateMutator{
+		mutatorConfig: mutatorConfig,
+	}
+}
+
+var _ PodMutator = &certificateMutator{}
+
+// certificateMutator mounts the cert-manager certificate Secret into envoy container,
+// and records the certificate revision so that pods can be rolled once certificate rotates.
+type certificateMutator struct {
+	mutatorConfig certificateMutatorConfig
+}
+
+func (m *certificateMutator) mutate(pod *corev1.Pod) error {
+	if !m.mutatorConfig.enabled {
+		return nil
+	}
+	ok, envoyIdx := containsEnvoyContainer(pod)
+	if !ok {
+		return nil
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == certificateVolumeName {
+			return nil
+		}
+	}
+
+	volume := corev1.Volume{
+		Name: certificateVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: m.mutatorConfig.secretName,
+			},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      certificateVolumeName,
+		MountPath: m.mutatorConfig.mountPath,
+		ReadOnly:  true,
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, volume)
+	pod.Spec.Containers[envoyIdx].VolumeMounts = append(pod.Spec.Containers[envoyIdx].VolumeMounts, volumeMount)
+
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[certmanager.AnnotationCertificateRevision] = m.mutatorConfig.revision
+	return nil
+}
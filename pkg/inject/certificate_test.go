@@ -0,0 +1,215 @@
+package inject
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_certificateMutator_mutate(t *testing.T) {
+	type fields struct {
+		mutatorConfig certificateMutatorConfig
+	}
+	type args struct {
+		pod *corev1.Pod
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantPod *corev1.Pod
+	}{
+		{
+			name: "no-op when disabled",
+			fields: fields{
+				mutatorConfig: certificateMutatorConfig{
+					enabled:    false,
+					secretName: "my-vn-virtualnode-appmesh-tls",
+					mountPath:  "/certs",
+					revision:   "1",
+				},
+			},
+			args: args{
+				pod: &corev1.Pod{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "envoy"}},
+					},
+				},
+			},
+			wantPod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "envoy"}},
+				},
+			},
+		},
+		{
+			name: "no-op when envoy container is absent",
+			fields: fields{
+				mutatorConfig: certificateMutatorConfig{
+					enabled:    true,
+					secretName: "my-vn-virtualnode-appmesh-tls",
+					mountPath:  "/certs",
+					revision:   "1",
+				},
+			},
+			args: args{
+				pod: &corev1.Pod{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app"}},
+					},
+				},
+			},
+			wantPod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app"}},
+				},
+			},
+		},
+		{
+			name: "mount certificate secret into envoy container",
+			fields: fields{
+				mutatorConfig: certificateMutatorConfig{
+					enabled:    true,
+					secretName: "my-vn-virtualnode-appmesh-tls",
+					mountPath:  "/certs",
+					revision:   "2",
+				},
+			},
+			args: args{
+				pod: &corev1.Pod{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app"}, {Name: "envoy"}},
+					},
+				},
+			},
+			wantPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"appmesh.k8s.aws/certificateRevision": "2",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app"},
+						{
+							Name: "envoy",
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "appmesh-certificate-volume",
+									MountPath: "/certs",
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "appmesh-certificate-volume",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "my-vn-virtualnode-appmesh-tls",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "record empty revision when certificate haven't been issued",
+			fields: fields{
+				mutatorConfig: certificateMutatorConfig{
+					enabled:    true,
+					secretName: "my-vn-virtualnode-appmesh-tls",
+					mountPath:  "/etc/tls",
+					revision:   "",
+				},
+			},
+			args: args{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							"appmesh.k8s.aws/sidecarInjectorWebhook": "enabled",
+						},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "envoy"}},
+					},
+				},
+			},
+			wantPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"appmesh.k8s.aws/sidecarInjectorWebhook": "enabled",
+						"appmesh.k8s.aws/certificateRevision":    "",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "envoy",
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "appmesh-certificate-volume",
+									MountPath: "/etc/tls",
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "appmesh-certificate-volume",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "my-vn-virtualnode-appmesh-tls",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "no-op when certificate volume already present",
+			fields: fields{
+				mutatorConfig: certificateMutatorConfig{
+					enabled:    true,
+					secretName: "my-vn-virtualnode-appmesh-tls",
+					mountPath:  "/certs",
+					revision:   "1",
+				},
+			},
+			args: args{
+				pod: &corev1.Pod{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "envoy"}},
+						Volumes: []corev1.Volume{
+							{Name: "appmesh-certificate-volume"},
+						},
+					},
+				},
+			},
+			wantPod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "envoy"}},
+					Volumes: []corev1.Volume{
+						{Name: "appmesh-certificate-volume"},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newCertificateMutator(tt.fields.mutatorConfig)
+			pod := tt.args.pod.DeepCopy()
+			err := m.mutate(pod)
+			assert.NoError(t, err)
+			assert.True(t, cmp.Equal(tt.wantPod, pod), "diff", cmp.Diff(tt.wantPod, pod))
+		})
+	}
+}
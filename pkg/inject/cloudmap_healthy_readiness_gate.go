@@ -8,20 +8,31 @@ import (
 
 // newCloudMapHealthyReadinessGate constructs new cloudMapHealthyReadinessGate
 func newCloudMapHealthyReadinessGate(vn *appmesh.VirtualNode) *cloudMapHealthyReadinessGate {
-	return &cloudMapHealthyReadinessGate{
-		vn: vn,
+	m := &cloudMapHealthyReadinessGate{}
+	if vn.Spec.ServiceDiscovery != nil {
+		m.cloudMapConfig = vn.Spec.ServiceDiscovery.AWSCloudMap
 	}
+	return m
+}
+
+// newVirtualGatewayCloudMapHealthyReadinessGate constructs new cloudMapHealthyReadinessGate for pods of VirtualGateway
+func newVirtualGatewayCloudMapHealthyReadinessGate(vg *appmesh.VirtualGateway) *cloudMapHealthyReadinessGate {
+	m := &cloudMapHealthyReadinessGate{}
+	if vg.Spec.ServiceDiscovery != nil {
+		m.cloudMapConfig = vg.Spec.ServiceDiscovery.AWSCloudMap
+	}
+	return m
 }
 
 var _ PodMutator = &cloudMapHealthyReadinessGate{}
 
-// mutator adding a healthy readiness gate for pods selected by VirtualNode with cloudMap serviceDiscovery.
+// mutator adding a healthy readiness gate for pods selected by VirtualNode or VirtualGateway with cloudMap serviceDiscovery.
 type cloudMapHealthyReadinessGate struct {
-	vn *appmesh.VirtualNode
+	cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery
 }
 
 func (m *cloudMapHealthyReadinessGate) mutate(pod *corev1.Pod) error {
-	if m.vn.Spec.ServiceDiscovery == nil || m.vn.Spec.ServiceDiscovery.AWSCloudMap == nil {
+	if m.cloudMapConfig == nil {
 		return nil
 	}
 	containsAWSCloudMapHealthyReadinessGate := false
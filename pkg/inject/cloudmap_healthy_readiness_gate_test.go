@@ -170,9 +170,7 @@ func Test_cloudMapHealthyReadinessGate_mutate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &cloudMapHealthyReadinessGate{
-				vn: tt.fields.vn,
-			}
+			m := newCloudMapHealthyReadinessGate(tt.fields.vn)
 			pod := tt.args.pod.DeepCopy()
 			err := m.mutate(pod)
 			if tt.wantErr != nil {
@@ -184,3 +182,48 @@ func Test_cloudMapHealthyReadinessGate_mutate(t *testing.T) {
 		})
 	}
 }
+
+func Test_virtualGatewayCloudMapHealthyReadinessGate_mutate(t *testing.T) {
+	tests := []struct {
+		name    string
+		vg      *appmesh.VirtualGateway
+		wantPod *corev1.Pod
+	}{
+		{
+			name: "should add readinessGate if virtualGateway has cloudMap serviceDiscovery",
+			vg: &appmesh.VirtualGateway{
+				Spec: appmesh.VirtualGatewaySpec{
+					ServiceDiscovery: &appmesh.VirtualGatewayServiceDiscovery{
+						AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+							NamespaceName: "cm-ns",
+							ServiceName:   "cm-svc",
+						},
+					},
+				},
+			},
+			wantPod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					ReadinessGates: []corev1.PodReadinessGate{
+						{
+							ConditionType: "conditions.appmesh.k8s.aws/aws-cloudmap-healthy",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "shouldn't add readinessGate if virtualGateway has no serviceDiscovery",
+			vg:      &appmesh.VirtualGateway{},
+			wantPod: &corev1.Pod{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newVirtualGatewayCloudMapHealthyReadinessGate(tt.vg)
+			pod := &corev1.Pod{}
+			err := m.mutate(pod)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPod, pod)
+		})
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"strings"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/certmanager"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualgateway"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,6 +26,7 @@ type PodMutator interface {
 
 type SidecarInjector struct {
 	config                 Config
+	certConfig             certmanager.Config
 	accountID              string
 	awsRegion              string
 	controllerVersion      string
@@ -34,13 +37,14 @@ type SidecarInjector struct {
 	vnMembershipDesignator virtualnode.MembershipDesignator
 }
 
-func NewSidecarInjector(cfg Config, accountID string, awsRegion string, controllerVersion string, k8sVersion string,
+func NewSidecarInjector(cfg Config, certConfig certmanager.Config, accountID string, awsRegion string, controllerVersion string, k8sVersion string,
 	k8sClient client.Client,
 	referenceResolver references.Resolver,
 	vnMembershipDesignator virtualnode.MembershipDesignator,
 	vgMembershipDesignator virtualgateway.MembershipDesignator) *SidecarInjector {
 	return &SidecarInjector{
 		config:                 cfg,
+		certConfig:             certConfig,
 		accountID:              accountID,
 		awsRegion:              awsRegion,
 		controllerVersion:      controllerVersion,
@@ -94,10 +98,38 @@ func (m *SidecarInjector) Inject(ctx context.Context, pod *corev1.Pod) error {
 	if err != nil {
 		return err
 	}
-	return m.injectAppMeshPatches(ms, vn, vg, pod)
+	certRevision, err := m.getCertificateRevision(ctx, vn, vg)
+	if err != nil {
+		return err
+	}
+	return m.injectAppMeshPatches(ms, vn, vg, pod, certRevision)
+}
+
+// getCertificateRevision returns the revision of the cert-manager certificate requested for vn or vg.
+// returns empty string if no certificate is requested or it haven't been issued yet.
+func (m *SidecarInjector) getCertificateRevision(ctx context.Context, vn *appmesh.VirtualNode, vg *appmesh.VirtualGateway) (string, error) {
+	if !m.certConfig.EnableCertificates {
+		return "", nil
+	}
+	var certKey types.NamespacedName
+	if vn != nil && certmanager.VirtualNodeRequiresCertificate(vn) {
+		certKey = types.NamespacedName{Namespace: vn.Namespace, Name: certmanager.CertificateName(vn)}
+	} else if vg != nil && certmanager.VirtualGatewayRequiresCertificate(vg) {
+		certKey = types.NamespacedName{Namespace: vg.Namespace, Name: certmanager.CertificateName(vg)}
+	} else {
+		return "", nil
+	}
+	cert := certmanager.NewCertificate()
+	if err := m.k8sClient.Get(ctx, certKey, cert); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to get certificate %v", certKey)
+	}
+	return certmanager.CertificateRevision(cert), nil
 }
 
-func (m *SidecarInjector) injectAppMeshPatches(ms *appmesh.Mesh, vn *appmesh.VirtualNode, vg *appmesh.VirtualGateway, pod *corev1.Pod) error {
+func (m *SidecarInjector) injectAppMeshPatches(ms *appmesh.Mesh, vn *appmesh.VirtualNode, vg *appmesh.VirtualGateway, pod *corev1.Pod, certRevision string) error {
 	// List out all the mutators in sequence
 	var mutators []PodMutator
 
@@ -157,6 +189,12 @@ func (m *SidecarInjector) injectAppMeshPatches(ms *appmesh.Mesh, vn *appmesh.Vir
 				awsSecretAccessKey:         m.config.EnvoyAwsSecretAccessKey,
 				awsSessionToken:            m.config.EnvoyAwsSessionToken,
 			}, ms, vn),
+			newCertificateMutator(certificateMutatorConfig{
+				enabled:    m.certConfig.EnableCertificates && certmanager.VirtualNodeRequiresCertificate(vn),
+				secretName: certmanager.CertificateName(vn),
+				mountPath:  m.certConfig.MountPath,
+				revision:   certRevision,
+			}),
 			newXrayMutator(xrayMutatorConfig{
 				awsRegion:             m.awsRegion,
 				sidecarCPURequests:    m.config.SidecarCpuRequests,
@@ -209,6 +247,12 @@ func (m *SidecarInjector) injectAppMeshPatches(ms *appmesh.Mesh, vn *appmesh.Vir
 			awsSecretAccessKey:         m.config.EnvoyAwsSecretAccessKey,
 			awsSessionToken:            m.config.EnvoyAwsSessionToken,
 		}, ms, vg),
+			newCertificateMutator(certificateMutatorConfig{
+				enabled:    m.certConfig.EnableCertificates && certmanager.VirtualGatewayRequiresCertificate(vg),
+				secretName: certmanager.CertificateName(vg),
+				mountPath:  m.certConfig.MountPath,
+				revision:   certRevision,
+			}),
 			newXrayMutator(xrayMutatorConfig{
 				awsRegion:             m.awsRegion,
 				sidecarCPURequests:    m.config.SidecarCpuRequests,
@@ -220,6 +264,7 @@ func (m *SidecarInjector) injectAppMeshPatches(ms *appmesh.Mesh, vn *appmesh.Vir
 				xRayLogLevel:          m.config.XrayLogLevel,
 				xRayConfigRoleArn:     m.config.XrayConfigRoleArn,
 			}, m.config.EnableXrayTracing),
+			newVirtualGatewayCloudMapHealthyReadinessGate(vg),
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/certmanager"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/pkg/errors"
@@ -255,9 +256,9 @@ func Test_InjectEnvoyContainerVN(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			inj := NewSidecarInjector(tt.conf, "000000000000", "us-west-2", "v1.4.1", "v1.4.1", nil, nil, nil, nil)
+			inj := NewSidecarInjector(tt.conf, certmanager.Config{}, "000000000000", "us-west-2", "v1.4.1", "v1.4.1", nil, nil, nil, nil)
 			pod := tt.args.pod
-			inj.injectAppMeshPatches(tt.args.ms, tt.args.vn, nil, pod)
+			inj.injectAppMeshPatches(tt.args.ms, tt.args.vn, nil, pod, "")
 			assert.Equal(t, tt.want.init, len(pod.Spec.InitContainers), "Numbers of init containers mismatch")
 			assert.Equal(t, tt.want.containers, len(pod.Spec.Containers), "Numbers of containers mismatch")
 			if tt.want.xray {
@@ -359,9 +360,9 @@ func Test_InjectEnvoyContainerVG(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			inj := NewSidecarInjector(tt.conf, "000000000000", "us-west-2", "v1.4.1", "v1.4.1", nil, nil, nil, nil)
+			inj := NewSidecarInjector(tt.conf, certmanager.Config{}, "000000000000", "us-west-2", "v1.4.1", "v1.4.1", nil, nil, nil, nil)
 			pod := tt.args.pod
-			err := inj.injectAppMeshPatches(tt.args.ms, nil, tt.args.vg, pod)
+			err := inj.injectAppMeshPatches(tt.args.ms, nil, tt.args.vg, pod, "")
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
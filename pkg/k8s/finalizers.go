@@ -15,6 +15,7 @@ const (
 	FinalizerVirtualGatewayMembers = "finalizers.appmesh.k8s.aws/virtualgateway-members"
 	FinalizerAWSAppMeshResources   = "finalizers.appmesh.k8s.aws/aws-appmesh-resources"
 	FinalizerAWSCloudMapResources  = "finalizers.appmesh.k8s.aws/aws-cloudmap-resources"
+	FinalizerSPIRERegistrations    = "finalizers.appmesh.k8s.aws/spire-registrations"
 )
 
 type FinalizerManager interface {
@@ -0,0 +1,66 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_podsRepository(t *testing.T) {
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithObjects(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "pod-1", Labels: map[string]string{"app": "app-1"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "pod-2", Labels: map[string]string{"app": "app-2"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "pod-3", Labels: map[string]string{"app": "app-1"}}},
+	).Build()
+	repo := NewPodsRepository(k8sClient)
+
+	pod, err := repo.GetPod("ns-1", "pod-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-1", pod.Name)
+
+	_, err = repo.GetPod("ns-1", "pod-3")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	tests := []struct {
+		name     string
+		opts     client.ListOptions
+		wantPods []string
+	}{
+		{
+			name:     "pods in namespace matching labels",
+			opts:     client.ListOptions{Namespace: "ns-1", LabelSelector: labels.SelectorFromSet(labels.Set{"app": "app-1"})},
+			wantPods: []string{"ns-1/pod-1"},
+		},
+		{
+			name:     "pods in all namespaces matching labels",
+			opts:     client.ListOptions{LabelSelector: labels.SelectorFromSet(labels.Set{"app": "app-1"})},
+			wantPods: []string{"ns-1/pod-1", "ns-2/pod-3"},
+		},
+		{
+			name:     "pods in namespace",
+			opts:     client.ListOptions{Namespace: "ns-1"},
+			wantPods: []string{"ns-1/pod-1", "ns-1/pod-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podList, err := repo.ListPodsWithMatchingLabels(tt.opts)
+			assert.NoError(t, err)
+			var gotPods []string
+			for i := range podList.Items {
+				gotPods = append(gotPods, NamespacedName(&podList.Items[i]).String())
+			}
+			assert.ElementsMatch(t, tt.wantPods, gotPods)
+		})
+	}
+}
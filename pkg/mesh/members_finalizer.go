@@ -4,42 +4,65 @@ import (
 	"context"
 	"fmt"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/record"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
 	"strings"
 	"time"
 )
 
 const (
 	pendingMembersFinalizerEvaluateInterval = 60 * time.Second
+	cascadeMembersFinalizerEvaluateInterval = 10 * time.Second
 )
 
 type MembersFinalizer interface {
 	Finalize(ctx context.Context, ms *appmesh.Mesh) error
 }
 
+// MembersDeletionOrderer orders mesh members for deletion by Cascade membersDeletionPolicy.
+type MembersDeletionOrderer interface {
+	// Order groups members into batches, where members of a batch are only depended on by members of previous batches.
+	Order(members []client.Object) ([][]client.Object, error)
+}
+
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-func NewPendingMembersFinalizer(k8sClient client.Client, eventRecorder record.EventRecorder, log logr.Logger) MembersFinalizer {
+// pendingMembers is the metric of members that block deletion, nil if metrics are not reported.
+func NewPendingMembersFinalizer(k8sClient client.Client, eventRecorder record.EventRecorder, deletionOrderer MembersDeletionOrderer,
+	pendingMembers *prometheus.GaugeVec, log logr.Logger) MembersFinalizer {
 	return &pendingMembersFinalizer{
-		k8sClient:        k8sClient,
-		eventRecorder:    eventRecorder,
-		log:              log,
-		evaluateInterval: pendingMembersFinalizerEvaluateInterval,
+		k8sClient:               k8sClient,
+		eventRecorder:           eventRecorder,
+		deletionOrderer:         deletionOrderer,
+		pendingMembers:          pendingMembers,
+		log:                     log,
+		evaluateInterval:        pendingMembersFinalizerEvaluateInterval,
+		cascadeEvaluateInterval: cascadeMembersFinalizerEvaluateInterval,
 	}
 }
 
 // pendingMembersFinalizer is a MembersFinalizer that will pend mesh deletion until all mesh members are deleted.
+// members are deleted by the finalizer with Cascade membersDeletionPolicy, and left in place with Orphan membersDeletionPolicy.
 type pendingMembersFinalizer struct {
-	k8sClient     client.Client
-	eventRecorder record.EventRecorder
-	log           logr.Logger
+	k8sClient       client.Client
+	eventRecorder   record.EventRecorder
+	deletionOrderer MembersDeletionOrderer
+	pendingMembers  *prometheus.GaugeVec
+	log             logr.Logger
 
-	evaluateInterval time.Duration
+	evaluateInterval        time.Duration
+	cascadeEvaluateInterval time.Duration
 }
 
 func (m *pendingMembersFinalizer) Finalize(ctx context.Context, ms *appmesh.Mesh) error {
@@ -63,15 +86,130 @@ func (m *pendingMembersFinalizer) Finalize(ctx context.Context, ms *appmesh.Mesh
 	if err != nil {
 		return err
 	}
-	if len(vsMembers) == 0 && len(vrMembers) == 0 && len(vnMembers) == 0 && len(vgMembers) == 0 && len(grMembers) == 0 {
+	pendingMembersCount := len(vsMembers) + len(vrMembers) + len(vnMembers) + len(vgMembers) + len(grMembers)
+	policy := MembersDeletionPolicy(ms)
+	if pendingMembersCount == 0 || policy == appmesh.MeshMembersDeletionPolicyOrphan {
+		m.reportPendingMembers(ms, 0)
+		if pendingMembersCount != 0 {
+			message := "objects belong to this mesh are orphaned. " + m.buildMembersCountMessage(vsMembers, vrMembers, vnMembers, vgMembers, grMembers)
+			tracing.RecordEvent(ctx, m.eventRecorder, ms, corev1.EventTypeNormal, "OrphanMembers", message)
+		}
 		return nil
 	}
+	m.reportPendingMembers(ms, pendingMembersCount)
+
+	membersByKind := map[string][]client.Object{
+		references.DependencyKindGatewayRoute:   memberObjects(grMembers),
+		references.DependencyKindVirtualGateway: memberObjects(vgMembers),
+		references.DependencyKindVirtualService: memberObjects(vsMembers),
+		references.DependencyKindVirtualRouter:  memberObjects(vrMembers),
+		references.DependencyKindVirtualNode:    memberObjects(vnMembers),
+	}
+	if policy == appmesh.MeshMembersDeletionPolicyCascade {
+		deletingKinds, err := m.cascadeMembersDeletion(ctx, ms, membersByKind)
+		if err != nil {
+			return err
+		}
+		if err := m.updateMembersDeletionStatus(ctx, ms, policy, deletingKinds, membersByKind); err != nil {
+			return err
+		}
+		return runtime.NewRequeueAfterError(errors.New("cascading members deletion"), m.cascadeEvaluateInterval)
+	}
 
+	if err := m.updateMembersDeletionStatus(ctx, ms, policy, nil, membersByKind); err != nil {
+		return err
+	}
 	message := m.buildPendingMembersEventMessage(ctx, vsMembers, vrMembers, vnMembers, vgMembers, grMembers)
-	m.eventRecorder.Eventf(ms, corev1.EventTypeWarning, "PendingMembersDeletion", message)
+	tracing.RecordEvent(ctx, m.eventRecorder, ms, corev1.EventTypeWarning, "PendingMembersDeletion", message)
 	return runtime.NewRequeueAfterError(errors.New("pending members deletion"), m.evaluateInterval)
 }
 
+// cascadeMembersDeletion deletes the first batch of members in dependency order, i.e. members no other member depends on,
+// and returns the kinds of members being deleted.
+func (m *pendingMembersFinalizer) cascadeMembersDeletion(ctx context.Context, ms *appmesh.Mesh, membersByKind map[string][]client.Object) ([]string, error) {
+	var members []client.Object
+	for _, kind := range []string{references.DependencyKindGatewayRoute, references.DependencyKindVirtualGateway,
+		references.DependencyKindVirtualService, references.DependencyKindVirtualRouter, references.DependencyKindVirtualNode} {
+		members = append(members, membersByKind[kind]...)
+	}
+	batches, err := m.deletionOrderer.Order(members)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to order members deletion")
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+	deletingKindSet := make(map[string]struct{})
+	for _, member := range batches[0] {
+		kind, _ := references.DependencyKindOf(member)
+		deletingKindSet[kind] = struct{}{}
+		if !member.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if err := m.k8sClient.Delete(ctx, member); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to delete %s %s", kind, k8s.NamespacedName(member))
+		}
+		tracing.LoggerFromContext(ctx, m.log).Info("deleted mesh member by cascade membersDeletionPolicy",
+			"mesh", k8s.NamespacedName(ms),
+			"kind", kind,
+			"object", k8s.NamespacedName(member),
+		)
+	}
+	deletingKinds := make([]string, 0, len(deletingKindSet))
+	for kind := range deletingKindSet {
+		deletingKinds = append(deletingKinds, kind)
+	}
+	sort.Strings(deletingKinds)
+	tracing.RecordEvent(ctx, m.eventRecorder, ms, corev1.EventTypeNormal, "CascadeMembersDeletion",
+		fmt.Sprintf("deleting objects belong to this mesh: %s", strings.Join(deletingKinds, ", ")))
+	return deletingKinds, nil
+}
+
+// updateMembersDeletionStatus reports the progress of members deletion in mesh status.
+func (m *pendingMembersFinalizer) updateMembersDeletionStatus(ctx context.Context, ms *appmesh.Mesh, policy appmesh.MeshMembersDeletionPolicy,
+	deletingKinds []string, membersByKind map[string][]client.Object) error {
+	membersDeletion := &appmesh.MeshMembersDeletion{
+		Policy:         policy,
+		Deleting:       deletingKinds,
+		PendingMembers: make(map[string]int64),
+	}
+	for kind, members := range membersByKind {
+		if len(members) != 0 {
+			membersDeletion.PendingMembers[kind] = int64(len(members))
+		}
+	}
+	if reflect.DeepEqual(ms.Status.MembersDeletion, membersDeletion) {
+		return nil
+	}
+	oldMS := ms.DeepCopy()
+	ms.Status.MembersDeletion = membersDeletion
+	return m.k8sClient.Status().Patch(ctx, ms, client.MergeFrom(oldMS))
+}
+
+// memberObjects converts mesh members of a kind into client.Objects.
+func memberObjects[T client.Object](members []T) []client.Object {
+	objects := make([]client.Object, 0, len(members))
+	for _, member := range members {
+		objects = append(objects, member)
+	}
+	return objects
+}
+
+// reportPendingMembers reports the number of members that block deletion of mesh.
+func (m *pendingMembersFinalizer) reportPendingMembers(ms *appmesh.Mesh, count int) {
+	if m.pendingMembers == nil {
+		return
+	}
+	if count == 0 {
+		m.pendingMembers.DeleteLabelValues("Mesh", "", ms.Name)
+		return
+	}
+	m.pendingMembers.WithLabelValues("Mesh", "", ms.Name).Set(float64(count))
+}
+
 // findVirtualServiceMembers find the VirtualService members for this mesh.
 func (m *pendingMembersFinalizer) findVirtualServiceMembers(ctx context.Context, ms *appmesh.Mesh) ([]*appmesh.VirtualService, error) {
 	vsList := &appmesh.VirtualServiceList{}
@@ -158,6 +296,14 @@ func (m *pendingMembersFinalizer) findGatewayRouteMembers(ctx context.Context, m
 }
 
 func (m *pendingMembersFinalizer) buildPendingMembersEventMessage(ctx context.Context,
+	vsMembers []*appmesh.VirtualService, vrMembers []*appmesh.VirtualRouter,
+	vnMembers []*appmesh.VirtualNode, vgMembers []*appmesh.VirtualGateway,
+	grMembers []*appmesh.GatewayRoute) string {
+	return "objects belong to this mesh exists, please delete them to proceed. " + m.buildMembersCountMessage(vsMembers, vrMembers, vnMembers, vgMembers, grMembers)
+}
+
+// buildMembersCountMessage builds the message of the number of mesh members by kind.
+func (m *pendingMembersFinalizer) buildMembersCountMessage(
 	vsMembers []*appmesh.VirtualService, vrMembers []*appmesh.VirtualRouter,
 	vnMembers []*appmesh.VirtualNode, vgMembers []*appmesh.VirtualGateway,
 	grMembers []*appmesh.GatewayRoute) string {
@@ -183,5 +329,5 @@ func (m *pendingMembersFinalizer) buildPendingMembersEventMessage(ctx context.Co
 		messagePerObjectTypes = append(messagePerObjectTypes, message)
 	}
 
-	return "objects belong to this mesh exists, please delete them to proceed. " + strings.Join(messagePerObjectTypes, ", ")
+	return strings.Join(messagePerObjectTypes, ", ")
 }
@@ -6,14 +6,18 @@ import (
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/equality"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"testing"
@@ -873,8 +877,10 @@ func Test_pendingMembersFinalizer_Finalize(t *testing.T) {
 			},
 		},
 	}
-	_ = vn
-	_ = vr
+	cascadeMS := ms.DeepCopy()
+	cascadeMS.Spec.MembersDeletionPolicy = meshMembersDeletionPolicyPtr(appmesh.MeshMembersDeletionPolicyCascade)
+	orphanMS := ms.DeepCopy()
+	orphanMS.Spec.MembersDeletionPolicy = meshMembersDeletionPolicyPtr(appmesh.MeshMembersDeletionPolicyOrphan)
 
 	type env struct {
 		virtualServices []*appmesh.VirtualService
@@ -886,48 +892,124 @@ func Test_pendingMembersFinalizer_Finalize(t *testing.T) {
 		ms *appmesh.Mesh
 	}
 	tests := []struct {
-		name    string
-		env     env
-		args    args
-		wantErr error
+		name                string
+		env                 env
+		args                args
+		wantErr             error
+		wantPendingMembers  map[string]float64
+		wantMembersDeletion *appmesh.MeshMembersDeletion
+		wantDeletedMembers  []string
 	}{
 		{
 			name: "when pending virtualService deletion",
 			env: env{
 				virtualServices: []*appmesh.VirtualService{vs},
 			},
-			args:    args{ms: ms},
-			wantErr: errors.New("pending members deletion"),
+			args:               args{ms: ms},
+			wantErr:            errors.New("pending members deletion"),
+			wantPendingMembers: map[string]float64{"my-mesh": 1},
+			wantMembersDeletion: &appmesh.MeshMembersDeletion{
+				Policy:         appmesh.MeshMembersDeletionPolicyBlock,
+				PendingMembers: map[string]int64{"VirtualService": 1},
+			},
 		},
 		{
 			name: "when pending virtualRouter deletion",
 			env: env{
 				virtualRouters: []*appmesh.VirtualRouter{vr},
 			},
-			args:    args{ms: ms},
-			wantErr: errors.New("pending members deletion"),
+			args:               args{ms: ms},
+			wantErr:            errors.New("pending members deletion"),
+			wantPendingMembers: map[string]float64{"my-mesh": 1},
+			wantMembersDeletion: &appmesh.MeshMembersDeletion{
+				Policy:         appmesh.MeshMembersDeletionPolicyBlock,
+				PendingMembers: map[string]int64{"VirtualRouter": 1},
+			},
 		},
 		{
 			name: "when pending virtualService deletion",
 			env: env{
 				virtualNodes: []*appmesh.VirtualNode{vn},
 			},
-			args:    args{ms: ms},
-			wantErr: errors.New("pending members deletion"),
+			args:               args{ms: ms},
+			wantErr:            errors.New("pending members deletion"),
+			wantPendingMembers: map[string]float64{"my-mesh": 1},
+			wantMembersDeletion: &appmesh.MeshMembersDeletion{
+				Policy:         appmesh.MeshMembersDeletionPolicyBlock,
+				PendingMembers: map[string]int64{"VirtualNode": 1},
+			},
 		},
 		{
 			name: "when pending virtualGateway deletion",
 			env: env{
 				virtualGateways: []*appmesh.VirtualGateway{vg},
 			},
-			args:    args{ms: ms},
-			wantErr: errors.New("pending members deletion"),
+			args:               args{ms: ms},
+			wantErr:            errors.New("pending members deletion"),
+			wantPendingMembers: map[string]float64{"my-mesh": 1},
+			wantMembersDeletion: &appmesh.MeshMembersDeletion{
+				Policy:         appmesh.MeshMembersDeletionPolicyBlock,
+				PendingMembers: map[string]int64{"VirtualGateway": 1},
+			},
 		},
 		{
-			name:    "when pending no member deletion",
-			env:     env{},
-			args:    args{ms: ms},
-			wantErr: nil,
+			name: "when cascading virtualGateway and virtualService deletion",
+			env: env{
+				virtualServices: []*appmesh.VirtualService{vs},
+				virtualRouters:  []*appmesh.VirtualRouter{vr},
+				virtualNodes:    []*appmesh.VirtualNode{vn},
+				virtualGateways: []*appmesh.VirtualGateway{vg},
+			},
+			args:               args{ms: cascadeMS},
+			wantErr:            errors.New("cascading members deletion"),
+			wantPendingMembers: map[string]float64{"my-mesh": 4},
+			wantMembersDeletion: &appmesh.MeshMembersDeletion{
+				Policy:   appmesh.MeshMembersDeletionPolicyCascade,
+				Deleting: []string{"VirtualGateway", "VirtualService"},
+				PendingMembers: map[string]int64{
+					"VirtualService": 1,
+					"VirtualRouter":  1,
+					"VirtualNode":    1,
+					"VirtualGateway": 1,
+				},
+			},
+			wantDeletedMembers: []string{"my-ns/vg-1", "my-ns/vs-1"},
+		},
+		{
+			name: "when cascading virtualRouter deletion",
+			env: env{
+				virtualRouters: []*appmesh.VirtualRouter{vr},
+				virtualNodes:   []*appmesh.VirtualNode{vn},
+			},
+			args:               args{ms: cascadeMS},
+			wantErr:            errors.New("cascading members deletion"),
+			wantPendingMembers: map[string]float64{"my-mesh": 2},
+			wantMembersDeletion: &appmesh.MeshMembersDeletion{
+				Policy:   appmesh.MeshMembersDeletionPolicyCascade,
+				Deleting: []string{"VirtualRouter"},
+				PendingMembers: map[string]int64{
+					"VirtualRouter": 1,
+					"VirtualNode":   1,
+				},
+			},
+			wantDeletedMembers: []string{"my-ns/vr-1"},
+		},
+		{
+			name: "when orphaning members",
+			env: env{
+				virtualServices: []*appmesh.VirtualService{vs},
+				virtualNodes:    []*appmesh.VirtualNode{vn},
+			},
+			args:               args{ms: orphanMS},
+			wantErr:            nil,
+			wantPendingMembers: map[string]float64{},
+		},
+		{
+			name:               "when pending no member deletion",
+			env:                env{},
+			args:               args{ms: ms},
+			wantErr:            nil,
+			wantPendingMembers: map[string]float64{},
 		},
 	}
 	for _, tt := range tests {
@@ -936,37 +1018,82 @@ func Test_pendingMembersFinalizer_Finalize(t *testing.T) {
 			k8sSchema := runtime.NewScheme()
 			clientgoscheme.AddToScheme(k8sSchema)
 			appmesh.AddToScheme(k8sSchema)
-			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithStatusSubresource(&appmesh.Mesh{}).Build()
 			eventRecorder := record.NewFakeRecorder(1)
+			registry := prometheus.NewRegistry()
+			pendingMembers := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "pending_members"}, []string{"kind", "namespace", "name"})
+			registry.MustRegister(pendingMembers)
 			m := &pendingMembersFinalizer{
-				k8sClient:        k8sClient,
-				eventRecorder:    eventRecorder,
-				log:              logr.New(&log.NullLogSink{}),
-				evaluateInterval: pendingMembersFinalizerEvaluateInterval,
+				k8sClient:               k8sClient,
+				eventRecorder:           eventRecorder,
+				deletionOrderer:         &kindStagesDeletionOrderer{},
+				pendingMembers:          pendingMembers,
+				log:                     logr.New(&log.NullLogSink{}),
+				evaluateInterval:        pendingMembersFinalizerEvaluateInterval,
+				cascadeEvaluateInterval: cascadeMembersFinalizerEvaluateInterval,
 			}
+			ms := tt.args.ms.DeepCopy()
+			assert.NoError(t, k8sClient.Create(ctx, ms))
 			for _, vs := range tt.env.virtualServices {
-				err := k8sClient.Create(ctx, vs)
+				err := k8sClient.Create(ctx, vs.DeepCopy())
 				assert.NoError(t, err)
 			}
 			for _, vr := range tt.env.virtualRouters {
-				err := k8sClient.Create(ctx, vr)
+				err := k8sClient.Create(ctx, vr.DeepCopy())
 				assert.NoError(t, err)
 			}
 			for _, vn := range tt.env.virtualNodes {
-				err := k8sClient.Create(ctx, vn)
+				err := k8sClient.Create(ctx, vn.DeepCopy())
 				assert.NoError(t, err)
 			}
 			for _, vg := range tt.env.virtualGateways {
-				err := k8sClient.Create(ctx, vg)
+				err := k8sClient.Create(ctx, vg.DeepCopy())
 				assert.NoError(t, err)
 			}
 
-			err := m.Finalize(ctx, tt.args.ms)
+			err := m.Finalize(ctx, ms)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
 				assert.NoError(t, err)
 			}
+			metricFamilies, err := registry.Gather()
+			assert.NoError(t, err)
+			gotPendingMembers := make(map[string]float64)
+			for _, metricFamily := range metricFamilies {
+				for _, metric := range metricFamily.GetMetric() {
+					for _, label := range metric.GetLabel() {
+						if label.GetName() == "name" {
+							gotPendingMembers[label.GetValue()] = metric.GetGauge().GetValue()
+						}
+					}
+				}
+			}
+			assert.Equal(t, tt.wantPendingMembers, gotPendingMembers)
+
+			gotMS := &appmesh.Mesh{}
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(ms), gotMS))
+			assert.Equal(t, tt.wantMembersDeletion, gotMS.Status.MembersDeletion)
+			var members []client.Object
+			for _, vs := range tt.env.virtualServices {
+				members = append(members, vs)
+			}
+			for _, vr := range tt.env.virtualRouters {
+				members = append(members, vr)
+			}
+			for _, vn := range tt.env.virtualNodes {
+				members = append(members, vn)
+			}
+			for _, vg := range tt.env.virtualGateways {
+				members = append(members, vg)
+			}
+			var gotDeletedMembers []string
+			for _, obj := range members {
+				if err := k8sClient.Get(ctx, k8s.NamespacedName(obj), obj.DeepCopyObject().(client.Object)); apierrors.IsNotFound(err) {
+					gotDeletedMembers = append(gotDeletedMembers, k8s.NamespacedName(obj).String())
+				}
+			}
+			assert.ElementsMatch(t, tt.wantDeletedMembers, gotDeletedMembers)
 		})
 	}
 }
@@ -990,3 +1117,28 @@ func compareVirtualGateway(a *appmesh.VirtualGateway, b *appmesh.VirtualGateway)
 func compareGatewayRoute(a *appmesh.GatewayRoute, b *appmesh.GatewayRoute) bool {
 	return k8s.NamespacedName(a).String() < k8s.NamespacedName(b).String()
 }
+
+func meshMembersDeletionPolicyPtr(policy appmesh.MeshMembersDeletionPolicy) *appmesh.MeshMembersDeletionPolicy {
+	return &policy
+}
+
+// kindStagesDeletionOrderer orders members for deletion by kind, as members of the test mesh don't refer to each other.
+type kindStagesDeletionOrderer struct{}
+
+func (o *kindStagesDeletionOrderer) Order(members []client.Object) ([][]client.Object, error) {
+	var batches [][]client.Object
+	for _, stage := range [][]string{{"GatewayRoute"}, {"VirtualGateway", "VirtualService"}, {"VirtualRouter"}, {"VirtualNode"}} {
+		var batch []client.Object
+		for _, member := range members {
+			for _, kind := range stage {
+				if memberKind, _ := references.DependencyKindOf(member); memberKind == kind {
+					batch = append(batch, member)
+				}
+			}
+		}
+		if len(batch) != 0 {
+			batches = append(batches, batch)
+		}
+	}
+	return batches, nil
+}
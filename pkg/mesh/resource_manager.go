@@ -6,7 +6,9 @@ import (
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	appmeshsdk "github.com/aws/aws-sdk-go/service/appmesh"
@@ -31,13 +33,15 @@ func NewDefaultResourceManager(
 	k8sClient client.Client,
 	appMeshSDK services.AppMesh,
 	accountID string,
+	defaultDeletionPolicy deletion.Policy,
 	log logr.Logger) ResourceManager {
 
 	return &defaultResourceManager{
-		k8sClient:  k8sClient,
-		appMeshSDK: appMeshSDK,
-		accountID:  accountID,
-		log:        log,
+		k8sClient:             k8sClient,
+		appMeshSDK:            appMeshSDK,
+		accountID:             accountID,
+		defaultDeletionPolicy: defaultDeletionPolicy,
+		log:                   log,
 	}
 }
 
@@ -46,8 +50,9 @@ type defaultResourceManager struct {
 	k8sClient  client.Client
 	appMeshSDK services.AppMesh
 	// current iam identity's aws accountID, used to differentiate mesh ownership.
-	accountID string
-	log       logr.Logger
+	accountID             string
+	defaultDeletionPolicy deletion.Policy
+	log                   logr.Logger
 }
 
 func (m *defaultResourceManager) Reconcile(ctx context.Context, ms *appmesh.Mesh) error {
@@ -70,6 +75,24 @@ func (m *defaultResourceManager) Reconcile(ctx context.Context, ms *appmesh.Mesh
 }
 
 func (m *defaultResourceManager) Cleanup(ctx context.Context, ms *appmesh.Mesh) error {
+	deletionPolicy, err := deletion.PolicyOf(ms, m.defaultDeletionPolicy)
+	if err != nil {
+		return err
+	}
+	if deletionPolicy == deletion.PolicyRetain {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain mesh mesh by deletion policy",
+			"mesh", k8s.NamespacedName(ms),
+			"meshARN", aws.StringValue(ms.Status.MeshARN),
+		)
+		return nil
+	}
+	if MembersDeletionPolicy(ms) == appmesh.MeshMembersDeletionPolicyOrphan {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain mesh mesh since its members are orphaned",
+			"mesh", k8s.NamespacedName(ms),
+			"meshARN", aws.StringValue(ms.Status.MeshARN),
+		)
+		return nil
+	}
 	sdkMS, err := m.findSDKMesh(ctx, ms)
 	if err != nil {
 		if ms.Status.MeshARN == nil {
@@ -125,7 +148,7 @@ func (m *defaultResourceManager) updateSDKMesh(ctx context.Context, sdkMS *appme
 		return sdkMS, nil
 	}
 	if !m.isSDKMeshControlledByCRDMesh(ctx, sdkMS, ms) {
-		m.log.V(1).Info("skip mesh update since it's not controlled",
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip mesh update since it's not controlled",
 			"mesh", k8s.NamespacedName(ms),
 			"meshARN", aws.StringValue(sdkMS.Metadata.Arn),
 		)
@@ -133,7 +156,7 @@ func (m *defaultResourceManager) updateSDKMesh(ctx context.Context, sdkMS *appme
 	}
 
 	diff := cmp.Diff(desiredSDKMSSpec, actualSDKMSSpec, opts)
-	m.log.V(1).Info("meshSpec changed",
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("meshSpec changed",
 		"mesh", k8s.NamespacedName(ms),
 		"actualSDKMSSpec", actualSDKMSSpec,
 		"desiredSDKMSSpec", desiredSDKMSSpec,
@@ -151,7 +174,7 @@ func (m *defaultResourceManager) updateSDKMesh(ctx context.Context, sdkMS *appme
 
 func (m *defaultResourceManager) deleteSDKMesh(ctx context.Context, sdkMS *appmeshsdk.MeshData, ms *appmesh.Mesh) error {
 	if !m.isSDKMeshOwnedByCRDMesh(ctx, sdkMS, ms) {
-		m.log.V(1).Info("skip mesh deletion since its not owned",
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip mesh deletion since its not owned",
 			"mesh", k8s.NamespacedName(ms),
 			"meshARN", aws.StringValue(sdkMS.Metadata.Arn),
 		)
@@ -20,3 +20,27 @@ func IsMeshActive(ms *appmesh.Mesh) bool {
 func IsMeshReferenced(ms *appmesh.Mesh, reference appmesh.MeshReference) bool {
 	return ms.Name == reference.Name && ms.UID == reference.UID
 }
+
+// VirtualNodeDefaults returns the virtualNode defaults of given mesh, or nil if not specified.
+func VirtualNodeDefaults(ms *appmesh.Mesh) *appmesh.VirtualNodeDefaults {
+	if ms.Spec.Defaults == nil {
+		return nil
+	}
+	return ms.Spec.Defaults.VirtualNode
+}
+
+// VirtualGatewayDefaults returns the virtualGateway defaults of given mesh, or nil if not specified.
+func VirtualGatewayDefaults(ms *appmesh.Mesh) *appmesh.VirtualGatewayDefaults {
+	if ms.Spec.Defaults == nil {
+		return nil
+	}
+	return ms.Spec.Defaults.VirtualGateway
+}
+
+// MembersDeletionPolicy returns the membersDeletionPolicy of given mesh, Block if not specified.
+func MembersDeletionPolicy(ms *appmesh.Mesh) appmesh.MeshMembersDeletionPolicy {
+	if ms.Spec.MembersDeletionPolicy == nil {
+		return appmesh.MeshMembersDeletionPolicyBlock
+	}
+	return *ms.Spec.MembersDeletionPolicy
+}
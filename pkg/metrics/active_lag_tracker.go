@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// NewActiveLagTracker constructs new activeLagTracker, which watches App Mesh resources in k8sCache.
+// BackendGroups are only tracked if enableBackendGroups is true.
+func NewActiveLagTracker(k8sCache cache.Informers, enableBackendGroups bool, registerer prometheus.Registerer) (*activeLagTracker, error) {
+	activeLagSeconds := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metricSubsystemAppMesh,
+		Name:      metricResourceActiveLagSeconds,
+		Help:      "Latency from spec changes of App Mesh resources until they're observed and active",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800},
+	}, []string{labelKind})
+	if err := registerer.Register(activeLagSeconds); err != nil {
+		return nil, err
+	}
+	return &activeLagTracker{
+		k8sCache:          k8sCache,
+		kinds:             kindsToReport(enableBackendGroups),
+		activeLagSeconds:  activeLagSeconds,
+		now:               time.Now,
+		pendingSinceByUID: make(map[types.UID]time.Time),
+	}, nil
+}
+
+var _ manager.Runnable = &activeLagTracker{}
+
+// activeLagTracker measures how long spec changes of App Mesh resources take to become active,
+// i.e. until status.observedGeneration catches up with generation and the active condition is true.
+type activeLagTracker struct {
+	k8sCache         cache.Informers
+	kinds            []resourceKind
+	activeLagSeconds *prometheus.HistogramVec
+	now              func() time.Time
+
+	// when resources became out of date, by UID of resources whose spec changes haven't become active.
+	pendingSinceByUID map[types.UID]time.Time
+	mutex             sync.Mutex
+}
+
+func (t *activeLagTracker) Start(ctx context.Context) error {
+	for _, kind := range t.kinds {
+		informer, err := t.k8sCache.GetInformer(ctx, kind.newObject())
+		if err != nil {
+			return err
+		}
+		kind := kind
+		if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				t.onUpdate(kind, obj)
+			},
+			UpdateFunc: func(_, newObj interface{}) {
+				t.onUpdate(kind, newObj)
+			},
+			DeleteFunc: t.onDelete,
+		}); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// onUpdate tracks resource obj of kind, and observes the lag once its pending spec change becomes active.
+func (t *activeLagTracker) onUpdate(kind resourceKind, obj interface{}) {
+	resource, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	pendingSince, hasPending := t.pendingSinceByUID[resource.GetUID()]
+	if kind.isActive(resource) {
+		if hasPending {
+			delete(t.pendingSinceByUID, resource.GetUID())
+			t.activeLagSeconds.WithLabelValues(kind.kind).Observe(t.now().Sub(pendingSince).Seconds())
+		}
+		return
+	}
+	// further spec changes before becoming active are measured from the first one.
+	if hasPending {
+		return
+	}
+	since := t.now()
+	// the first spec of resource is changed on its creation.
+	creationTimestamp := resource.GetCreationTimestamp()
+	if resource.GetGeneration() <= 1 && !creationTimestamp.IsZero() {
+		since = creationTimestamp.Time
+	}
+	t.pendingSinceByUID[resource.GetUID()] = since
+}
+
+func (t *activeLagTracker) onDelete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	resource, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.pendingSinceByUID, resource.GetUID())
+}
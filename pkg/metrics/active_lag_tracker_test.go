@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestVirtualNode(generation int64, observedGeneration *int64, active corev1.ConditionStatus, creationTime time.Time) *appmesh.VirtualNode {
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              "vn",
+			UID:               "vn-uid",
+			Generation:        generation,
+			CreationTimestamp: metav1.NewTime(creationTime),
+		},
+		Status: appmesh.VirtualNodeStatus{ObservedGeneration: observedGeneration},
+	}
+	if active != "" {
+		vn.Status.Conditions = []appmesh.VirtualNodeCondition{{Type: appmesh.VirtualNodeActive, Status: active}}
+	}
+	return vn
+}
+
+func Test_activeLagTracker(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	tracker, err := NewActiveLagTracker(nil, false, registry)
+	assert.NoError(t, err)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	vnKind := resourceKinds[1]
+	created := now.Add(-2 * time.Second)
+
+	// a new resource is measured from its creation.
+	tracker.onUpdate(vnKind, newTestVirtualNode(1, nil, "", created))
+	now = now.Add(time.Second)
+	tracker.onUpdate(vnKind, newTestVirtualNode(1, aws.Int64(1), corev1.ConditionFalse, created))
+	assert.Len(t, tracker.pendingSinceByUID, 1)
+	now = now.Add(time.Second)
+	tracker.onUpdate(vnKind, newTestVirtualNode(1, aws.Int64(1), corev1.ConditionTrue, created))
+	assert.Empty(t, tracker.pendingSinceByUID)
+
+	// a spec change is measured from when it's seen, until the new generation becomes active.
+	tracker.onUpdate(vnKind, newTestVirtualNode(2, aws.Int64(1), corev1.ConditionTrue, created))
+	now = now.Add(3 * time.Second)
+	tracker.onUpdate(vnKind, newTestVirtualNode(3, aws.Int64(1), corev1.ConditionTrue, created))
+	now = now.Add(5 * time.Second)
+	tracker.onUpdate(vnKind, newTestVirtualNode(3, aws.Int64(3), corev1.ConditionTrue, created))
+	assert.Empty(t, tracker.pendingSinceByUID)
+
+	// deleted resources are no longer tracked.
+	tracker.onUpdate(vnKind, newTestVirtualNode(4, aws.Int64(3), corev1.ConditionTrue, created))
+	tracker.onDelete(toolscache.DeletedFinalStateUnknown{Obj: newTestVirtualNode(4, aws.Int64(3), corev1.ConditionTrue, created)})
+	assert.Empty(t, tracker.pendingSinceByUID)
+
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, metricFamilies, 1)
+	histogram := metricFamilies[0].GetMetric()[0].GetHistogram()
+	assert.Equal(t, uint64(2), histogram.GetSampleCount())
+	assert.InDelta(t, 4+8, histogram.GetSampleSum(), 0.001)
+}
+
+func Test_resourceKind_isActive(t *testing.T) {
+	vnKind := resourceKinds[1]
+	bgKind := resourceKinds[len(resourceKinds)-1]
+	tests := []struct {
+		name string
+		kind resourceKind
+		obj  client.Object
+		want bool
+	}{
+		{
+			name: "active virtualNode",
+			kind: vnKind,
+			obj:  newTestVirtualNode(2, aws.Int64(2), corev1.ConditionTrue, time.Now()),
+			want: true,
+		},
+		{
+			name: "virtualNode with unobserved generation",
+			kind: vnKind,
+			obj:  newTestVirtualNode(3, aws.Int64(2), corev1.ConditionTrue, time.Now()),
+		},
+		{
+			name: "inactive virtualNode",
+			kind: vnKind,
+			obj:  newTestVirtualNode(2, aws.Int64(2), corev1.ConditionFalse, time.Now()),
+		},
+		{
+			name: "virtualNode without conditions",
+			kind: vnKind,
+			obj:  newTestVirtualNode(2, aws.Int64(2), "", time.Now()),
+		},
+		{
+			name: "backendGroup with observed generation",
+			kind: bgKind,
+			obj: &appmesh.BackendGroup{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Status:     appmesh.BackendGroupStatus{ObservedGeneration: aws.Int64(1)},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.kind.isActive(tt.obj))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resourceCondition is a condition of App Mesh resource, regardless of its kind.
+type resourceCondition struct {
+	conditionType string
+	status        corev1.ConditionStatus
+}
+
+// resourceStatus is the status of App Mesh resource, regardless of its kind.
+type resourceStatus struct {
+	observedGeneration *int64
+	conditions         []resourceCondition
+}
+
+// resourceKind describes how to list App Mesh resources of a kind and read their status.
+type resourceKind struct {
+	kind string
+	// activeCondition is the condition type of active resources, empty if resources are active once their spec is observed.
+	activeCondition string
+	newObject       func() client.Object
+	newList         func() client.ObjectList
+	statusOf        func(obj client.Object) resourceStatus
+}
+
+// isActive returns whether the current spec of obj has been observed and made active.
+func (k resourceKind) isActive(obj client.Object) bool {
+	status := k.statusOf(obj)
+	if status.observedGeneration == nil || *status.observedGeneration < obj.GetGeneration() {
+		return false
+	}
+	if k.activeCondition == "" {
+		return true
+	}
+	for _, condition := range status.conditions {
+		if condition.conditionType == k.activeCondition {
+			return condition.status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isGenerationObserved returns whether the current spec of obj has been observed.
+func (k resourceKind) isGenerationObserved(obj client.Object) bool {
+	observedGeneration := k.statusOf(obj).observedGeneration
+	return observedGeneration != nil && *observedGeneration >= obj.GetGeneration()
+}
+
+// resourceKinds are kinds of App Mesh resources to report metrics for.
+var resourceKinds = []resourceKind{
+	{
+		kind:            "Mesh",
+		activeCondition: string(appmesh.MeshActive),
+		newObject:       func() client.Object { return &appmesh.Mesh{} },
+		newList:         func() client.ObjectList { return &appmesh.MeshList{} },
+		statusOf: func(obj client.Object) resourceStatus {
+			status := obj.(*appmesh.Mesh).Status
+			conditions := make([]resourceCondition, 0, len(status.Conditions))
+			for _, condition := range status.Conditions {
+				conditions = append(conditions, resourceCondition{conditionType: string(condition.Type), status: condition.Status})
+			}
+			return resourceStatus{observedGeneration: status.ObservedGeneration, conditions: conditions}
+		},
+	},
+	{
+		kind:            "VirtualNode",
+		activeCondition: string(appmesh.VirtualNodeActive),
+		newObject:       func() client.Object { return &appmesh.VirtualNode{} },
+		newList:         func() client.ObjectList { return &appmesh.VirtualNodeList{} },
+		statusOf: func(obj client.Object) resourceStatus {
+			status := obj.(*appmesh.VirtualNode).Status
+			conditions := make([]resourceCondition, 0, len(status.Conditions))
+			for _, condition := range status.Conditions {
+				conditions = append(conditions, resourceCondition{conditionType: string(condition.Type), status: condition.Status})
+			}
+			return resourceStatus{observedGeneration: status.ObservedGeneration, conditions: conditions}
+		},
+	},
+	{
+		kind:            "VirtualService",
+		activeCondition: string(appmesh.VirtualServiceActive),
+		newObject:       func() client.Object { return &appmesh.VirtualService{} },
+		newList:         func() client.ObjectList { return &appmesh.VirtualServiceList{} },
+		statusOf: func(obj client.Object) resourceStatus {
+			status := obj.(*appmesh.VirtualService).Status
+			conditions := make([]resourceCondition, 0, len(status.Conditions))
+			for _, condition := range status.Conditions {
+				conditions = append(conditions, resourceCondition{conditionType: string(condition.Type), status: condition.Status})
+			}
+			return resourceStatus{observedGeneration: status.ObservedGeneration, conditions: conditions}
+		},
+	},
+	{
+		kind:            "VirtualRouter",
+		activeCondition: string(appmesh.VirtualRouterActive),
+		newObject:       func() client.Object { return &appmesh.VirtualRouter{} },
+		newList:         func() client.ObjectList { return &appmesh.VirtualRouterList{} },
+		statusOf: func(obj client.Object) resourceStatus {
+			status := obj.(*appmesh.VirtualRouter).Status
+			conditions := make([]resourceCondition, 0, len(status.Conditions))
+			for _, condition := range status.Conditions {
+				conditions = append(conditions, resourceCondition{conditionType: string(condition.Type), status: condition.Status})
+			}
+			return resourceStatus{observedGeneration: status.ObservedGeneration, conditions: conditions}
+		},
+	},
+	{
+		kind:            "VirtualGateway",
+		activeCondition: string(appmesh.VirtualGatewayActive),
+		newObject:       func() client.Object { return &appmesh.VirtualGateway{} },
+		newList:         func() client.ObjectList { return &appmesh.VirtualGatewayList{} },
+		statusOf: func(obj client.Object) resourceStatus {
+			status := obj.(*appmesh.VirtualGateway).Status
+			conditions := make([]resourceCondition, 0, len(status.Conditions))
+			for _, condition := range status.Conditions {
+				conditions = append(conditions, resourceCondition{conditionType: string(condition.Type), status: condition.Status})
+			}
+			return resourceStatus{observedGeneration: status.ObservedGeneration, conditions: conditions}
+		},
+	},
+	{
+		kind:            "GatewayRoute",
+		activeCondition: string(appmesh.GatewayRouteActive),
+		newObject:       func() client.Object { return &appmesh.GatewayRoute{} },
+		newList:         func() client.ObjectList { return &appmesh.GatewayRouteList{} },
+		statusOf: func(obj client.Object) resourceStatus {
+			status := obj.(*appmesh.GatewayRoute).Status
+			conditions := make([]resourceCondition, 0, len(status.Conditions))
+			for _, condition := range status.Conditions {
+				conditions = append(conditions, resourceCondition{conditionType: string(condition.Type), status: condition.Status})
+			}
+			return resourceStatus{observedGeneration: status.ObservedGeneration, conditions: conditions}
+		},
+	},
+	{
+		kind:      "BackendGroup",
+		newObject: func() client.Object { return &appmesh.BackendGroup{} },
+		newList:   func() client.ObjectList { return &appmesh.BackendGroupList{} },
+		statusOf: func(obj client.Object) resourceStatus {
+			return resourceStatus{observedGeneration: obj.(*appmesh.BackendGroup).Status.ObservedGeneration}
+		},
+	},
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricSubsystemAppMesh         = "appmesh"
+	metricResources                = "resources"
+	metricResourceConditions       = "resource_conditions"
+	metricResourcesUnobserved      = "resources_generation_unobserved"
+	metricResourceActiveLagSeconds = "resource_active_lag_seconds"
+	metricPendingMembers           = "pending_members"
+)
+
+const (
+	labelKind      = "kind"
+	labelCondition = "condition"
+	labelStatus    = "status"
+	labelNamespace = "namespace"
+	labelName      = "name"
+)
+
+// NewPendingMembersMetric allocates and registers the metric of members that block deletion of Meshes and VirtualGateways,
+// by kind, namespace and name of the resource being deleted.
+func NewPendingMembersMetric(registerer prometheus.Registerer) (*prometheus.GaugeVec, error) {
+	pendingMembers := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metricSubsystemAppMesh,
+		Name:      metricPendingMembers,
+		Help:      "Number of members that block deletion of App Mesh resources",
+	}, []string{labelKind, labelNamespace, labelName})
+	if err := registerer.Register(pendingMembers); err != nil {
+		return nil, err
+	}
+	return pendingMembers, nil
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultResourcesListTimeout = 10 * time.Second
+)
+
+// NewResourcesCollector constructs new resourcesCollector, which reports App Mesh resources in k8sCache on every scrape.
+// BackendGroups are only reported if enableBackendGroups is true.
+func NewResourcesCollector(k8sCache client.Reader, enableBackendGroups bool) *resourcesCollector {
+	return &resourcesCollector{
+		k8sCache: k8sCache,
+		kinds:    kindsToReport(enableBackendGroups),
+		resourcesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("", metricSubsystemAppMesh, metricResources),
+			"Number of App Mesh resources by kind",
+			[]string{labelKind}, nil),
+		resourceConditionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("", metricSubsystemAppMesh, metricResourceConditions),
+			"Number of App Mesh resources by kind, condition and status of the condition",
+			[]string{labelKind, labelCondition, labelStatus}, nil),
+		resourcesUnobservedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("", metricSubsystemAppMesh, metricResourcesUnobserved),
+			"Number of App Mesh resources whose latest spec hasn't been observed by the controller",
+			[]string{labelKind}, nil),
+		listTimeout: defaultResourcesListTimeout,
+	}
+}
+
+var _ prometheus.Collector = &resourcesCollector{}
+
+// resourcesCollector reports counts of App Mesh resources by their status.
+type resourcesCollector struct {
+	k8sCache client.Reader
+	kinds    []resourceKind
+
+	resourcesDesc           *prometheus.Desc
+	resourceConditionsDesc  *prometheus.Desc
+	resourcesUnobservedDesc *prometheus.Desc
+	listTimeout             time.Duration
+}
+
+func (c *resourcesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.resourcesDesc
+	ch <- c.resourceConditionsDesc
+	ch <- c.resourcesUnobservedDesc
+}
+
+func (c *resourcesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.listTimeout)
+	defer cancel()
+	for _, kind := range c.kinds {
+		objs, err := c.listResources(ctx, kind)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(c.resourcesDesc, err)
+			continue
+		}
+		type conditionKey struct {
+			conditionType string
+			status        string
+		}
+		countByCondition := make(map[conditionKey]int)
+		unobserved := 0
+		for _, obj := range objs {
+			for _, condition := range kind.statusOf(obj).conditions {
+				countByCondition[conditionKey{conditionType: condition.conditionType, status: string(condition.status)}]++
+			}
+			if !kind.isGenerationObserved(obj) {
+				unobserved++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(c.resourcesDesc, prometheus.GaugeValue, float64(len(objs)), kind.kind)
+		ch <- prometheus.MustNewConstMetric(c.resourcesUnobservedDesc, prometheus.GaugeValue, float64(unobserved), kind.kind)
+		for key, count := range countByCondition {
+			ch <- prometheus.MustNewConstMetric(c.resourceConditionsDesc, prometheus.GaugeValue, float64(count), kind.kind, key.conditionType, key.status)
+		}
+	}
+}
+
+// listResources lists resources of kind from cache.
+func (c *resourcesCollector) listResources(ctx context.Context, kind resourceKind) ([]client.Object, error) {
+	list := kind.newList()
+	if err := c.k8sCache.List(ctx, list); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]client.Object, 0, len(items))
+	for _, item := range items {
+		objs = append(objs, item.(client.Object))
+	}
+	return objs, nil
+}
+
+// kindsToReport returns resourceKinds to report metrics for.
+func kindsToReport(enableBackendGroups bool) []resourceKind {
+	kinds := make([]resourceKind, 0, len(resourceKinds))
+	for _, kind := range resourceKinds {
+		if kind.kind == "BackendGroup" && !enableBackendGroups {
+			continue
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_resourcesCollector_Collect(t *testing.T) {
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+	ctx := context.Background()
+	for _, vn := range []*appmesh.VirtualNode{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn-active"},
+			Status: appmesh.VirtualNodeStatus{
+				Conditions:         []appmesh.VirtualNodeCondition{{Type: appmesh.VirtualNodeActive, Status: corev1.ConditionTrue}},
+				ObservedGeneration: aws.Int64(0),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn-inactive"},
+			Status: appmesh.VirtualNodeStatus{
+				Conditions: []appmesh.VirtualNodeCondition{
+					{Type: appmesh.VirtualNodeActive, Status: corev1.ConditionFalse},
+					{Type: appmesh.VirtualNodeSPIRERegistered, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vn-new"},
+		},
+	} {
+		assert.NoError(t, k8sClient.Create(ctx, vn))
+	}
+	assert.NoError(t, k8sClient.Create(ctx, &appmesh.Mesh{ObjectMeta: metav1.ObjectMeta{Name: "my-mesh"}}))
+
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(NewResourcesCollector(k8sClient, false)))
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+	got := make(map[string]map[string]float64)
+	for _, metricFamily := range metricFamilies {
+		values := make(map[string]float64)
+		for _, metric := range metricFamily.GetMetric() {
+			key := ""
+			for _, label := range metric.GetLabel() {
+				key += label.GetName() + "=" + label.GetValue() + ","
+			}
+			values[key] = metric.GetGauge().GetValue()
+		}
+		got[metricFamily.GetName()] = values
+	}
+	assert.Equal(t, map[string]map[string]float64{
+		"appmesh_resources": {
+			"kind=Mesh,":           1,
+			"kind=VirtualNode,":    3,
+			"kind=VirtualService,": 0,
+			"kind=VirtualRouter,":  0,
+			"kind=VirtualGateway,": 0,
+			"kind=GatewayRoute,":   0,
+		},
+		"appmesh_resource_conditions": {
+			"condition=VirtualNodeActive,kind=VirtualNode,status=True,":  1,
+			"condition=VirtualNodeActive,kind=VirtualNode,status=False,": 1,
+			"condition=SPIRERegistered,kind=VirtualNode,status=True,":    1,
+		},
+		"appmesh_resources_generation_unobserved": {
+			"kind=Mesh,":           1,
+			"kind=VirtualNode,":    2,
+			"kind=VirtualService,": 0,
+			"kind=VirtualRouter,":  0,
+			"kind=VirtualGateway,": 0,
+			"kind=GatewayRoute,":   0,
+		},
+	}, got)
+}
@@ -0,0 +1,19 @@
+package references
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEnableReferenceGrants = "enable-reference-grants"
+)
+
+type Config struct {
+	// If enabled, references to resources in another namespace must be permitted by a MeshReferenceGrant in that namespace.
+	EnableReferenceGrants bool
+}
+
+func (cfg *Config) BindFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&cfg.EnableReferenceGrants, flagEnableReferenceGrants, false,
+		"If enabled, cross-namespace references must be permitted by a MeshReferenceGrant in the referenced namespace")
+}
@@ -0,0 +1,91 @@
+package references
+
+import (
+	"context"
+	"reflect"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// NewEnqueueRequestsForMeshReferenceGrantEvents constructs an event handler that enqueues referrers of referrerKind
+// whose cross-namespace references may be granted or revoked by a meshReferenceGrant.
+// referrerListPrototype is an empty list of referrerKind objects.
+func NewEnqueueRequestsForMeshReferenceGrantEvents(k8sClient client.Client, referrerKind appmesh.MeshReferenceGrantFromKind,
+	referrerListPrototype client.ObjectList, log logr.Logger) *enqueueRequestsForMeshReferenceGrantEvents {
+	return &enqueueRequestsForMeshReferenceGrantEvents{
+		k8sClient:             k8sClient,
+		referrerKind:          referrerKind,
+		referrerListPrototype: referrerListPrototype,
+		log:                   log,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForMeshReferenceGrantEvents)(nil)
+
+type enqueueRequestsForMeshReferenceGrantEvents struct {
+	k8sClient             client.Client
+	referrerKind          appmesh.MeshReferenceGrantFromKind
+	referrerListPrototype client.ObjectList
+	log                   logr.Logger
+}
+
+// Create is called in response to a create event
+func (h *enqueueRequestsForMeshReferenceGrantEvents) Create(ctx context.Context, e event.CreateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	grant := e.Object.(*appmesh.MeshReferenceGrant)
+	h.enqueueReferrers(ctx, queue, grant)
+}
+
+// Update is called in response to an update event
+func (h *enqueueRequestsForMeshReferenceGrantEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	grantOld := e.ObjectOld.(*appmesh.MeshReferenceGrant)
+	grantNew := e.ObjectNew.(*appmesh.MeshReferenceGrant)
+	if reflect.DeepEqual(grantOld.Spec, grantNew.Spec) {
+		return
+	}
+	// referrers of the old grant may have lost their permission, while referrers of the new grant may have gained it.
+	h.enqueueReferrers(ctx, queue, grantOld)
+	h.enqueueReferrers(ctx, queue, grantNew)
+}
+
+// Delete is called in response to a delete event
+func (h *enqueueRequestsForMeshReferenceGrantEvents) Delete(ctx context.Context, e event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	grant := e.Object.(*appmesh.MeshReferenceGrant)
+	h.enqueueReferrers(ctx, queue, grant)
+}
+
+// Generic is called in response to an event of an unknown type or a synthetic event triggered as a cron or
+// external trigger request
+func (h *enqueueRequestsForMeshReferenceGrantEvents) Generic(ctx context.Context, e event.GenericEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
+	// no-op
+}
+
+// enqueueReferrers enqueues all referrers of referrerKind in namespaces the grant applies to.
+func (h *enqueueRequestsForMeshReferenceGrantEvents) enqueueReferrers(ctx context.Context, queue workqueue.TypedRateLimitingInterface[ctrl.Request], grant *appmesh.MeshReferenceGrant) {
+	for _, from := range grant.Spec.From {
+		if from.Kind != h.referrerKind {
+			continue
+		}
+		referrerList := h.referrerListPrototype.DeepCopyObject().(client.ObjectList)
+		if err := h.k8sClient.List(ctx, referrerList, client.InNamespace(from.Namespace)); err != nil {
+			h.log.Error(err, "failed to enqueue referrers for meshReferenceGrant events",
+				"meshReferenceGrant", types.NamespacedName{Namespace: grant.Namespace, Name: grant.Name},
+				"kind", h.referrerKind,
+				"namespace", from.Namespace)
+			continue
+		}
+		_ = meta.EachListItem(referrerList, func(obj runtime.Object) error {
+			referrer := obj.(client.Object)
+			queue.Add(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: referrer.GetNamespace(), Name: referrer.GetName()}})
+			return nil
+		})
+	}
+}
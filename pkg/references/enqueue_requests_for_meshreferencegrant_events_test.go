@@ -0,0 +1,101 @@
+package references
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_enqueueRequestsForMeshReferenceGrantEvents_Update(t *testing.T) {
+	vnInFrontend := &appmesh.VirtualNode{ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "vn-1"}}
+	vnInCheckout := &appmesh.VirtualNode{ObjectMeta: metav1.ObjectMeta{Namespace: "checkout", Name: "vn-2"}}
+	vnInOther := &appmesh.VirtualNode{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "vn-3"}}
+	grantWithFrom := func(from ...appmesh.MeshReferenceGrantFrom) *appmesh.MeshReferenceGrant {
+		return &appmesh.MeshReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "backend", Name: "grant"},
+			Spec: appmesh.MeshReferenceGrantSpec{
+				From: from,
+				To:   []appmesh.MeshReferenceGrantTo{{Kind: appmesh.MeshReferenceGrantToKindVirtualService}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		e            event.UpdateEvent
+		wantRequests []reconcile.Request
+	}{
+		{
+			name: "grant spec un-changed",
+			e: event.UpdateEvent{
+				ObjectOld: grantWithFrom(appmesh.MeshReferenceGrantFrom{Kind: appmesh.MeshReferenceGrantFromKindVirtualNode, Namespace: "frontend"}),
+				ObjectNew: grantWithFrom(appmesh.MeshReferenceGrantFrom{Kind: appmesh.MeshReferenceGrantFromKindVirtualNode, Namespace: "frontend"}),
+			},
+			wantRequests: nil,
+		},
+		{
+			name: "grant moved to another namespace enqueues referrers of both",
+			e: event.UpdateEvent{
+				ObjectOld: grantWithFrom(appmesh.MeshReferenceGrantFrom{Kind: appmesh.MeshReferenceGrantFromKindVirtualNode, Namespace: "frontend"}),
+				ObjectNew: grantWithFrom(appmesh.MeshReferenceGrantFrom{Kind: appmesh.MeshReferenceGrantFromKindVirtualNode, Namespace: "checkout"}),
+			},
+			wantRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "frontend", Name: "vn-1"}},
+				{NamespacedName: types.NamespacedName{Namespace: "checkout", Name: "vn-2"}},
+			},
+		},
+		{
+			name: "referrers of other kinds are not enqueued",
+			e: event.UpdateEvent{
+				ObjectOld: grantWithFrom(appmesh.MeshReferenceGrantFrom{Kind: appmesh.MeshReferenceGrantFromKindVirtualRouter, Namespace: "frontend"}),
+				ObjectNew: grantWithFrom(appmesh.MeshReferenceGrantFrom{Kind: appmesh.MeshReferenceGrantFromKindVirtualRouter, Namespace: "checkout"}),
+			},
+			wantRequests: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+			queue := workqueue.NewTypedRateLimitingQueue[ctrl.Request](workqueue.DefaultTypedControllerRateLimiter[ctrl.Request]())
+			h := NewEnqueueRequestsForMeshReferenceGrantEvents(k8sClient, appmesh.MeshReferenceGrantFromKindVirtualNode,
+				&appmesh.VirtualNodeList{}, logr.New(&log.NullLogSink{}))
+
+			for _, vn := range []*appmesh.VirtualNode{vnInFrontend, vnInCheckout, vnInOther} {
+				err := k8sClient.Create(ctx, vn.DeepCopy())
+				assert.NoError(t, err)
+			}
+
+			h.Update(ctx, tt.e, queue)
+			var gotRequests []reconcile.Request
+			queueLen := queue.Len()
+			for i := 0; i < queueLen; i++ {
+				item, _ := queue.Get()
+				gotRequests = append(gotRequests, item)
+			}
+
+			opt := cmpopts.SortSlices(func(a reconcile.Request, b reconcile.Request) bool {
+				return a.String() < b.String()
+			})
+			assert.True(t, cmp.Equal(tt.wantRequests, gotRequests, opt), "diff: %v", cmp.Diff(tt.wantRequests, gotRequests, opt))
+		})
+	}
+}
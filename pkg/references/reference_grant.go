@@ -0,0 +1,103 @@
+package references
+
+import (
+	"context"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReferenceGrantChecker checks whether a cross-namespace reference is permitted by MeshReferenceGrants.
+type ReferenceGrantChecker interface {
+	// CheckReference returns an error if obj is not permitted to reference the referent of referentKind identified by referentKey.
+	// references within the same namespace are always permitted.
+	CheckReference(ctx context.Context, obj metav1.Object, referentKind appmesh.MeshReferenceGrantToKind, referentKey types.NamespacedName) error
+}
+
+// NewDefaultReferenceGrantChecker constructs new defaultReferenceGrantChecker
+func NewDefaultReferenceGrantChecker(k8sClient client.Client, cfg Config) *defaultReferenceGrantChecker {
+	return &defaultReferenceGrantChecker{
+		k8sClient: k8sClient,
+		enabled:   cfg.EnableReferenceGrants,
+	}
+}
+
+var _ ReferenceGrantChecker = &defaultReferenceGrantChecker{}
+
+// defaultReferenceGrantChecker implements ReferenceGrantChecker
+type defaultReferenceGrantChecker struct {
+	k8sClient client.Client
+	// when disabled, all cross-namespace references are permitted.
+	enabled bool
+}
+
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=meshreferencegrants,verbs=get;list;watch
+
+func (c *defaultReferenceGrantChecker) CheckReference(ctx context.Context, obj metav1.Object, referentKind appmesh.MeshReferenceGrantToKind, referentKey types.NamespacedName) error {
+	if !c.enabled || referentKey.Namespace == obj.GetNamespace() {
+		return nil
+	}
+	referrerKind, ok := referrerKindForObject(obj)
+	if !ok {
+		return errors.Errorf("unsupported referrer type %T for cross-namespace reference", obj)
+	}
+
+	grantList := &appmesh.MeshReferenceGrantList{}
+	if err := c.k8sClient.List(ctx, grantList, client.InNamespace(referentKey.Namespace)); err != nil {
+		return errors.Wrapf(err, "failed to list meshReferenceGrants in namespace: %s", referentKey.Namespace)
+	}
+	for i := range grantList.Items {
+		if IsReferenceGranted(&grantList.Items[i], referrerKind, obj.GetNamespace(), referentKind, referentKey.Name) {
+			return nil
+		}
+	}
+	return errors.Errorf("%s %s/%s is not permitted to reference %s %v, no meshReferenceGrant in namespace %s allows it",
+		referrerKind, obj.GetNamespace(), obj.GetName(), referentKind, referentKey, referentKey.Namespace)
+}
+
+// IsReferenceGranted checks whether grant permits a referrer of referrerKind in referrerNamespace
+// to reference the referent of referentKind named referentName in grant's namespace.
+func IsReferenceGranted(grant *appmesh.MeshReferenceGrant, referrerKind appmesh.MeshReferenceGrantFromKind, referrerNamespace string,
+	referentKind appmesh.MeshReferenceGrantToKind, referentName string) bool {
+	fromMatches := false
+	for _, from := range grant.Spec.From {
+		if from.Kind == referrerKind && from.Namespace == referrerNamespace {
+			fromMatches = true
+			break
+		}
+	}
+	if !fromMatches {
+		return false
+	}
+	for _, to := range grant.Spec.To {
+		if to.Kind != referentKind {
+			continue
+		}
+		if to.Name == nil || aws.StringValue(to.Name) == referentName {
+			return true
+		}
+	}
+	return false
+}
+
+// referrerKindForObject returns the kind of obj when it's allowed to hold cross-namespace references.
+func referrerKindForObject(obj metav1.Object) (appmesh.MeshReferenceGrantFromKind, bool) {
+	switch obj.(type) {
+	case *appmesh.VirtualNode:
+		return appmesh.MeshReferenceGrantFromKindVirtualNode, true
+	case *appmesh.VirtualService:
+		return appmesh.MeshReferenceGrantFromKindVirtualService, true
+	case *appmesh.VirtualRouter:
+		return appmesh.MeshReferenceGrantFromKindVirtualRouter, true
+	case *appmesh.GatewayRoute:
+		return appmesh.MeshReferenceGrantFromKindGatewayRoute, true
+	case *appmesh.BackendGroup:
+		return appmesh.MeshReferenceGrantFromKindBackendGroup, true
+	default:
+		return "", false
+	}
+}
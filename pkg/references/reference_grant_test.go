@@ -0,0 +1,259 @@
+package references
+
+import (
+	"context"
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func Test_defaultReferenceGrantChecker_CheckReference(t *testing.T) {
+	grantForVNInNS1 := &appmesh.MeshReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-2",
+			Name:      "grant",
+		},
+		Spec: appmesh.MeshReferenceGrantSpec{
+			From: []appmesh.MeshReferenceGrantFrom{
+				{
+					Kind:      appmesh.MeshReferenceGrantFromKindVirtualNode,
+					Namespace: "ns-1",
+				},
+			},
+			To: []appmesh.MeshReferenceGrantTo{
+				{
+					Kind: appmesh.MeshReferenceGrantToKindVirtualService,
+					Name: aws.String("vs"),
+				},
+			},
+		},
+	}
+	vnInNS1 := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-1",
+			Name:      "vn",
+		},
+	}
+
+	type env struct {
+		grants []*appmesh.MeshReferenceGrant
+	}
+	type args struct {
+		obj          metav1.Object
+		referentKind appmesh.MeshReferenceGrantToKind
+		referentKey  types.NamespacedName
+	}
+	tests := []struct {
+		name    string
+		cfg     Config
+		env     env
+		args    args
+		wantErr error
+	}{
+		{
+			name: "when reference grants are disabled",
+			cfg:  Config{EnableReferenceGrants: false},
+			args: args{
+				obj:          vnInNS1,
+				referentKind: appmesh.MeshReferenceGrantToKindVirtualService,
+				referentKey:  types.NamespacedName{Namespace: "ns-2", Name: "vs"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "when referent is in same namespace",
+			cfg:  Config{EnableReferenceGrants: true},
+			args: args{
+				obj:          vnInNS1,
+				referentKind: appmesh.MeshReferenceGrantToKindVirtualService,
+				referentKey:  types.NamespacedName{Namespace: "ns-1", Name: "vs"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "when cross-namespace reference is granted",
+			cfg:  Config{EnableReferenceGrants: true},
+			env: env{
+				grants: []*appmesh.MeshReferenceGrant{grantForVNInNS1},
+			},
+			args: args{
+				obj:          vnInNS1,
+				referentKind: appmesh.MeshReferenceGrantToKindVirtualService,
+				referentKey:  types.NamespacedName{Namespace: "ns-2", Name: "vs"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "when cross-namespace reference is not granted for referent name",
+			cfg:  Config{EnableReferenceGrants: true},
+			env: env{
+				grants: []*appmesh.MeshReferenceGrant{grantForVNInNS1},
+			},
+			args: args{
+				obj:          vnInNS1,
+				referentKind: appmesh.MeshReferenceGrantToKindVirtualService,
+				referentKey:  types.NamespacedName{Namespace: "ns-2", Name: "other-vs"},
+			},
+			wantErr: errors.New("VirtualNode ns-1/vn is not permitted to reference VirtualService ns-2/other-vs, no meshReferenceGrant in namespace ns-2 allows it"),
+		},
+		{
+			name: "when cross-namespace reference is not granted for referrer kind",
+			cfg:  Config{EnableReferenceGrants: true},
+			env: env{
+				grants: []*appmesh.MeshReferenceGrant{grantForVNInNS1},
+			},
+			args: args{
+				obj: &appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "vs",
+					},
+				},
+				referentKind: appmesh.MeshReferenceGrantToKindVirtualService,
+				referentKey:  types.NamespacedName{Namespace: "ns-2", Name: "vs"},
+			},
+			wantErr: errors.New("VirtualService ns-1/vs is not permitted to reference VirtualService ns-2/vs, no meshReferenceGrant in namespace ns-2 allows it"),
+		},
+		{
+			name: "when no grants exist in referent namespace",
+			cfg:  Config{EnableReferenceGrants: true},
+			args: args{
+				obj:          vnInNS1,
+				referentKind: appmesh.MeshReferenceGrantToKindVirtualService,
+				referentKey:  types.NamespacedName{Namespace: "ns-2", Name: "vs"},
+			},
+			wantErr: errors.New("VirtualNode ns-1/vn is not permitted to reference VirtualService ns-2/vs, no meshReferenceGrant in namespace ns-2 allows it"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+			c := NewDefaultReferenceGrantChecker(k8sClient, tt.cfg)
+
+			for _, grant := range tt.env.grants {
+				err := k8sClient.Create(ctx, grant.DeepCopy())
+				assert.NoError(t, err)
+			}
+
+			err := c.CheckReference(ctx, tt.args.obj, tt.args.referentKind, tt.args.referentKey)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIsReferenceGranted(t *testing.T) {
+	type args struct {
+		grant             *appmesh.MeshReferenceGrant
+		referrerKind      appmesh.MeshReferenceGrantFromKind
+		referrerNamespace string
+		referentKind      appmesh.MeshReferenceGrantToKind
+		referentName      string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "when grant matches referrer and referent name",
+			args: args{
+				grant: &appmesh.MeshReferenceGrant{
+					Spec: appmesh.MeshReferenceGrantSpec{
+						From: []appmesh.MeshReferenceGrantFrom{
+							{Kind: appmesh.MeshReferenceGrantFromKindVirtualRouter, Namespace: "ns-1"},
+						},
+						To: []appmesh.MeshReferenceGrantTo{
+							{Kind: appmesh.MeshReferenceGrantToKindVirtualNode, Name: aws.String("vn")},
+						},
+					},
+				},
+				referrerKind:      appmesh.MeshReferenceGrantFromKindVirtualRouter,
+				referrerNamespace: "ns-1",
+				referentKind:      appmesh.MeshReferenceGrantToKindVirtualNode,
+				referentName:      "vn",
+			},
+			want: true,
+		},
+		{
+			name: "when grant doesn't specify referent name",
+			args: args{
+				grant: &appmesh.MeshReferenceGrant{
+					Spec: appmesh.MeshReferenceGrantSpec{
+						From: []appmesh.MeshReferenceGrantFrom{
+							{Kind: appmesh.MeshReferenceGrantFromKindVirtualRouter, Namespace: "ns-1"},
+						},
+						To: []appmesh.MeshReferenceGrantTo{
+							{Kind: appmesh.MeshReferenceGrantToKindVirtualNode},
+						},
+					},
+				},
+				referrerKind:      appmesh.MeshReferenceGrantFromKindVirtualRouter,
+				referrerNamespace: "ns-1",
+				referentKind:      appmesh.MeshReferenceGrantToKindVirtualNode,
+				referentName:      "vn",
+			},
+			want: true,
+		},
+		{
+			name: "when grant doesn't match referrer namespace",
+			args: args{
+				grant: &appmesh.MeshReferenceGrant{
+					Spec: appmesh.MeshReferenceGrantSpec{
+						From: []appmesh.MeshReferenceGrantFrom{
+							{Kind: appmesh.MeshReferenceGrantFromKindVirtualRouter, Namespace: "ns-3"},
+						},
+						To: []appmesh.MeshReferenceGrantTo{
+							{Kind: appmesh.MeshReferenceGrantToKindVirtualNode},
+						},
+					},
+				},
+				referrerKind:      appmesh.MeshReferenceGrantFromKindVirtualRouter,
+				referrerNamespace: "ns-1",
+				referentKind:      appmesh.MeshReferenceGrantToKindVirtualNode,
+				referentName:      "vn",
+			},
+			want: false,
+		},
+		{
+			name: "when grant doesn't match referent kind",
+			args: args{
+				grant: &appmesh.MeshReferenceGrant{
+					Spec: appmesh.MeshReferenceGrantSpec{
+						From: []appmesh.MeshReferenceGrantFrom{
+							{Kind: appmesh.MeshReferenceGrantFromKindVirtualRouter, Namespace: "ns-1"},
+						},
+						To: []appmesh.MeshReferenceGrantTo{
+							{Kind: appmesh.MeshReferenceGrantToKindVirtualService},
+						},
+					},
+				},
+				referrerKind:      appmesh.MeshReferenceGrantFromKindVirtualRouter,
+				referrerNamespace: "ns-1",
+				referentKind:      appmesh.MeshReferenceGrantToKindVirtualNode,
+				referentName:      "vn",
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IsReferenceGranted(tt.args.grant, tt.args.referrerKind, tt.args.referrerNamespace, tt.args.referentKind, tt.args.referentName)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
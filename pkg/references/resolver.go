@@ -3,6 +3,7 @@ package references
 import (
 	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,17 +27,19 @@ type Resolver interface {
 }
 
 // NewDefaultResolver constructs new defaultResolver
-func NewDefaultResolver(k8sClient client.Client, log logr.Logger) Resolver {
+func NewDefaultResolver(k8sClient client.Client, referenceGrantChecker ReferenceGrantChecker, log logr.Logger) Resolver {
 	return &defaultResolver{
-		k8sClient: k8sClient,
-		log:       log,
+		k8sClient:             k8sClient,
+		referenceGrantChecker: referenceGrantChecker,
+		log:                   log,
 	}
 }
 
 // defaultResolver implements Resolver
 type defaultResolver struct {
-	k8sClient client.Client
-	log       logr.Logger
+	k8sClient             client.Client
+	referenceGrantChecker ReferenceGrantChecker
+	log                   logr.Logger
 }
 
 func (r *defaultResolver) ResolveMeshReference(ctx context.Context, ref appmesh.MeshReference) (*appmesh.Mesh, error) {
@@ -46,7 +49,7 @@ func (r *defaultResolver) ResolveMeshReference(ctx context.Context, ref appmesh.
 	}
 
 	if mesh.UID != ref.UID {
-		r.log.Error(nil, "mesh UID mismatch",
+		tracing.LoggerFromContext(ctx, r.log).Error(nil, "mesh UID mismatch",
 			"mesh", ref.Name,
 			"expected UID", ref.UID,
 			"actual UID", mesh.UID,
@@ -64,7 +67,7 @@ func (r *defaultResolver) ResolveVirtualGatewayReference(ctx context.Context, ob
 	}
 
 	if vg.UID != ref.UID {
-		r.log.Error(nil, "virtualGateway UID mismatch",
+		tracing.LoggerFromContext(ctx, r.log).Error(nil, "virtualGateway UID mismatch",
 			"virtualGateway", ref.Name,
 			"expected UID", ref.UID,
 			"actual UID", vg.UID,
@@ -76,6 +79,9 @@ func (r *defaultResolver) ResolveVirtualGatewayReference(ctx context.Context, ob
 
 func (r *defaultResolver) ResolveVirtualNodeReference(ctx context.Context, obj metav1.Object, ref appmesh.VirtualNodeReference) (*appmesh.VirtualNode, error) {
 	vnKey := ObjectKeyForVirtualNodeReference(obj, ref)
+	if err := r.referenceGrantChecker.CheckReference(ctx, obj, appmesh.MeshReferenceGrantToKindVirtualNode, vnKey); err != nil {
+		return nil, err
+	}
 	vn := &appmesh.VirtualNode{}
 	if err := r.k8sClient.Get(ctx, vnKey, vn); err != nil {
 		return nil, errors.Wrapf(err, "unable to fetch virtualNode: %v", vnKey)
@@ -85,6 +91,9 @@ func (r *defaultResolver) ResolveVirtualNodeReference(ctx context.Context, obj m
 
 func (r *defaultResolver) ResolveVirtualServiceReference(ctx context.Context, obj metav1.Object, ref appmesh.VirtualServiceReference) (*appmesh.VirtualService, error) {
 	vsKey := ObjectKeyForVirtualServiceReference(obj, ref)
+	if err := r.referenceGrantChecker.CheckReference(ctx, obj, appmesh.MeshReferenceGrantToKindVirtualService, vsKey); err != nil {
+		return nil, err
+	}
 	vs := &appmesh.VirtualService{}
 	if err := r.k8sClient.Get(ctx, vsKey, vs); err != nil {
 		return nil, errors.Wrapf(err, "unable to fetch virtualService: %v", vsKey)
@@ -94,6 +103,9 @@ func (r *defaultResolver) ResolveVirtualServiceReference(ctx context.Context, ob
 
 func (r *defaultResolver) ResolveVirtualRouterReference(ctx context.Context, obj metav1.Object, ref appmesh.VirtualRouterReference) (*appmesh.VirtualRouter, error) {
 	vrKey := ObjectKeyForVirtualRouterReference(obj, ref)
+	if err := r.referenceGrantChecker.CheckReference(ctx, obj, appmesh.MeshReferenceGrantToKindVirtualRouter, vrKey); err != nil {
+		return nil, err
+	}
 	vr := &appmesh.VirtualRouter{}
 	if err := r.k8sClient.Get(ctx, vrKey, vr); err != nil {
 		return nil, errors.Wrapf(err, "unable to fetch virtualRouter: %v", vrKey)
@@ -103,6 +115,9 @@ func (r *defaultResolver) ResolveVirtualRouterReference(ctx context.Context, obj
 
 func (r *defaultResolver) ResolveBackendGroupReference(ctx context.Context, obj metav1.Object, ref appmesh.BackendGroupReference) (*appmesh.BackendGroup, error) {
 	bgKey := ObjectKeyForBackendGroupReference(obj, ref)
+	if err := r.referenceGrantChecker.CheckReference(ctx, obj, appmesh.MeshReferenceGrantToKindBackendGroup, bgKey); err != nil {
+		return nil, err
+	}
 	bg := &appmesh.BackendGroup{}
 	if err := r.k8sClient.Get(ctx, bgKey, bg); err != nil {
 		return nil, errors.Wrapf(err, "unable to fetch backendGroup: %v", bgKey)
@@ -91,7 +91,7 @@ func Test_defaultResolver_ResolveMeshReference(t *testing.T) {
 			clientgoscheme.AddToScheme(k8sSchema)
 			appmesh.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
-			r := NewDefaultResolver(k8sClient, logr.New(&log.NullLogSink{}))
+			r := NewDefaultResolver(k8sClient, NewDefaultReferenceGrantChecker(k8sClient, Config{}), logr.New(&log.NullLogSink{}))
 
 			for _, ms := range tt.env.meshes {
 				err := k8sClient.Create(ctx, ms.DeepCopy())
@@ -235,7 +235,7 @@ func Test_defaultResolver_ResolveVirtualGatewayReference(t *testing.T) {
 			clientgoscheme.AddToScheme(k8sSchema)
 			appmesh.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
-			r := NewDefaultResolver(k8sClient, logr.New(&log.NullLogSink{}))
+			r := NewDefaultResolver(k8sClient, NewDefaultReferenceGrantChecker(k8sClient, Config{}), logr.New(&log.NullLogSink{}))
 
 			for _, ms := range tt.env.virtualGateways {
 				err := k8sClient.Create(ctx, ms.DeepCopy())
@@ -348,7 +348,7 @@ func Test_defaultResolver_ResolveVirtualNodeReference(t *testing.T) {
 			clientgoscheme.AddToScheme(k8sSchema)
 			appmesh.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
-			r := NewDefaultResolver(k8sClient, logr.New(&log.NullLogSink{}))
+			r := NewDefaultResolver(k8sClient, NewDefaultReferenceGrantChecker(k8sClient, Config{}), logr.New(&log.NullLogSink{}))
 
 			for _, vn := range tt.env.virtualNodes {
 				err := k8sClient.Create(ctx, vn.DeepCopy())
@@ -461,7 +461,7 @@ func Test_defaultResolver_ResolveVirtualServiceReference(t *testing.T) {
 			clientgoscheme.AddToScheme(k8sSchema)
 			appmesh.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
-			r := NewDefaultResolver(k8sClient, logr.New(&log.NullLogSink{}))
+			r := NewDefaultResolver(k8sClient, NewDefaultReferenceGrantChecker(k8sClient, Config{}), logr.New(&log.NullLogSink{}))
 
 			for _, vs := range tt.env.virtualServices {
 				err := k8sClient.Create(ctx, vs.DeepCopy())
@@ -574,7 +574,7 @@ func Test_defaultResolver_ResolveVirtualRouterReference(t *testing.T) {
 			clientgoscheme.AddToScheme(k8sSchema)
 			appmesh.AddToScheme(k8sSchema)
 			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
-			r := NewDefaultResolver(k8sClient, logr.New(&log.NullLogSink{}))
+			r := NewDefaultResolver(k8sClient, NewDefaultReferenceGrantChecker(k8sClient, Config{}), logr.New(&log.NullLogSink{}))
 
 			for _, vr := range tt.env.virtualRouters {
 				err := k8sClient.Create(ctx, vr.DeepCopy())
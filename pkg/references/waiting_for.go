@@ -0,0 +1,92 @@
+package references
+
+import (
+	"fmt"
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DependencyKindGatewayRoute   = "GatewayRoute"
+	DependencyKindMesh           = "Mesh"
+	DependencyKindVirtualGateway = "VirtualGateway"
+	DependencyKindVirtualNode    = "VirtualNode"
+	DependencyKindVirtualRouter  = "VirtualRouter"
+	DependencyKindVirtualService = "VirtualService"
+)
+
+// DependencyKindOf returns the kind of a mesh member, false if obj isn't a mesh member.
+func DependencyKindOf(obj client.Object) (string, bool) {
+	switch obj.(type) {
+	case *appmesh.GatewayRoute:
+		return DependencyKindGatewayRoute, true
+	case *appmesh.VirtualGateway:
+		return DependencyKindVirtualGateway, true
+	case *appmesh.VirtualService:
+		return DependencyKindVirtualService, true
+	case *appmesh.VirtualRouter:
+		return DependencyKindVirtualRouter, true
+	case *appmesh.VirtualNode:
+		return DependencyKindVirtualNode, true
+	}
+	return "", false
+}
+
+// waitingForRecheckInterval is the interval to recheck the dependencies an object is waiting for.
+// objects are enqueued by watches once their dependencies become ready, the recheck only guards against missed events.
+const waitingForRecheckInterval = 60 * time.Second
+
+// NewDependencyReference constructs DependencyReference for an unresolved dependency.
+func NewDependencyReference(kind string, key types.NamespacedName, reason appmesh.DependencyReason) appmesh.DependencyReference {
+	return appmesh.DependencyReference{
+		Kind:      kind,
+		Namespace: key.Namespace,
+		Name:      key.Name,
+		Reason:    reason,
+	}
+}
+
+// NormalizeDependencyReferences sorts dependencies by kind, namespace and name and removes duplicates,
+// so that status is stable across reconciles.
+func NormalizeDependencyReferences(refs []appmesh.DependencyReference) []appmesh.DependencyReference {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		if refs[i].Namespace != refs[j].Namespace {
+			return refs[i].Namespace < refs[j].Namespace
+		}
+		return refs[i].Name < refs[j].Name
+	})
+	var normalizedRefs []appmesh.DependencyReference
+	for i, ref := range refs {
+		if i > 0 && ref == refs[i-1] {
+			continue
+		}
+		normalizedRefs = append(normalizedRefs, ref)
+	}
+	return normalizedRefs
+}
+
+// FormatDependencyReference formats dependency in a human-readable form, e.g. "VirtualNode my-ns/my-vn (NotActive)".
+func FormatDependencyReference(ref appmesh.DependencyReference) string {
+	if len(ref.Namespace) == 0 {
+		return fmt.Sprintf("%s %s (%s)", ref.Kind, ref.Name, ref.Reason)
+	}
+	return fmt.Sprintf("%s %s/%s (%s)", ref.Kind, ref.Namespace, ref.Name, ref.Reason)
+}
+
+// NewWaitingForError constructs an error to requeue an object until its unresolved dependencies are resolved.
+func NewWaitingForError(waitingFor []appmesh.DependencyReference) error {
+	descriptions := make([]string, 0, len(waitingFor))
+	for _, ref := range waitingFor {
+		descriptions = append(descriptions, FormatDependencyReference(ref))
+	}
+	return runtime.NewRequeueAfterError(errors.Errorf("waiting for %s", strings.Join(descriptions, ", ")), waitingForRecheckInterval)
+}
@@ -0,0 +1,115 @@
+package references
+
+import (
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+func TestNormalizeDependencyReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		refs []appmesh.DependencyReference
+		want []appmesh.DependencyReference
+	}{
+		{
+			name: "sort by kind, namespace and name and remove duplicates",
+			refs: []appmesh.DependencyReference{
+				{Kind: "VirtualRouter", Namespace: "ns-1", Name: "vr-1", Reason: appmesh.DependencyReasonNotActive},
+				{Kind: "VirtualNode", Namespace: "ns-2", Name: "vn-1", Reason: appmesh.DependencyReasonNotFound},
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-2", Reason: appmesh.DependencyReasonNotActive},
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonAWSNameNotApplied},
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+				{Kind: "VirtualNode", Namespace: "ns-2", Name: "vn-1", Reason: appmesh.DependencyReasonNotFound},
+			},
+			want: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonAWSNameNotApplied},
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-2", Reason: appmesh.DependencyReasonNotActive},
+				{Kind: "VirtualNode", Namespace: "ns-2", Name: "vn-1", Reason: appmesh.DependencyReasonNotFound},
+				{Kind: "VirtualRouter", Namespace: "ns-1", Name: "vr-1", Reason: appmesh.DependencyReasonNotActive},
+			},
+		},
+		{
+			name: "no dependencies",
+			refs: nil,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeDependencyReferences(tt.refs)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewWaitingForError(t *testing.T) {
+	tests := []struct {
+		name       string
+		waitingFor []appmesh.DependencyReference
+		wantErr    error
+	}{
+		{
+			name: "waiting for cluster scoped dependency",
+			waitingFor: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+			},
+			wantErr: errors.New("waiting for Mesh my-mesh (NotActive)"),
+		},
+		{
+			name: "waiting for multiple dependencies",
+			waitingFor: []appmesh.DependencyReference{
+				{Kind: "VirtualNode", Namespace: "my-ns", Name: "vn-1", Reason: appmesh.DependencyReasonNotFound},
+				{Kind: "VirtualRouter", Namespace: "my-ns", Name: "vr-1", Reason: appmesh.DependencyReasonNotActive},
+			},
+			wantErr: errors.New("waiting for VirtualNode my-ns/vn-1 (NotFound), VirtualRouter my-ns/vr-1 (NotActive)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewWaitingForError(tt.waitingFor)
+			assert.EqualError(t, err, tt.wantErr.Error())
+			var requeueAfterErr *runtime.RequeueAfterError
+			assert.True(t, errors.As(err, &requeueAfterErr))
+			assert.Equal(t, waitingForRecheckInterval, requeueAfterErr.Duration())
+		})
+	}
+}
+
+func TestDependencyKindOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      client.Object
+		wantKind string
+		wantOK   bool
+	}{
+		{
+			name:     "gatewayRoute",
+			obj:      &appmesh.GatewayRoute{},
+			wantKind: "GatewayRoute",
+			wantOK:   true,
+		},
+		{
+			name:     "virtualNode",
+			obj:      &appmesh.VirtualNode{},
+			wantKind: "VirtualNode",
+			wantOK:   true,
+		},
+		{
+			name:   "mesh isn't a mesh member",
+			obj:    &appmesh.Mesh{},
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKind, gotOK := DependencyKindOf(tt.obj)
+			assert.Equal(t, tt.wantKind, gotKind)
+			assert.Equal(t, tt.wantOK, gotOK)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package runtime
+
+import (
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	flagQueueFairness                       = "queue-fairness"
+	flagMaxConcurrentReconciles             = "max-concurrent-reconciles"
+	flagMaxConcurrentReconcilesByController = "max-concurrent-reconciles-by-controller"
+	flagDependencyOrdering                  = "dependency-ordering"
+
+	// QueueFairnessNone dequeues requests in FIFO order.
+	QueueFairnessNone = "none"
+	// QueueFairnessNamespace dequeues requests from different namespaces fairly.
+	QueueFairnessNamespace = "namespace"
+	// QueueFairnessMesh dequeues requests for objects of different meshes fairly.
+	QueueFairnessMesh = "mesh"
+
+	defaultMaxConcurrentReconciles = 3
+)
+
+// ControllerConfig contains the workqueue and concurrency settings of controllers.
+type ControllerConfig struct {
+	// Specifies the tenant requests are dequeued fairly by, one of none, namespace or mesh.
+	QueueFairness string
+	// Specifies the max number of concurrent reconciles of controllers.
+	MaxConcurrentReconciles int
+	// Specifies the max number of concurrent reconciles by controller name, which overrides MaxConcurrentReconciles.
+	MaxConcurrentReconcilesByController map[string]int
+	// Specifies whether requests of mesh members are dispatched in dependency order.
+	DependencyOrdering bool
+}
+
+func (cfg *ControllerConfig) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&cfg.QueueFairness, flagQueueFairness, QueueFairnessNamespace,
+		`The tenant that reconcile requests are dequeued fairly by, one of none, namespace or mesh`)
+	fs.IntVar(&cfg.MaxConcurrentReconciles, flagMaxConcurrentReconciles, defaultMaxConcurrentReconciles,
+		`The max number of concurrent reconciles of each controller`)
+	fs.StringToIntVar(&cfg.MaxConcurrentReconcilesByController, flagMaxConcurrentReconcilesByController, nil,
+		`The max number of concurrent reconciles by controller name, e.g. virtualnode=10,cloudMap=5`)
+	fs.BoolVar(&cfg.DependencyOrdering, flagDependencyOrdering, true,
+		`Reconcile mesh members after the queued members they depend on`)
+}
+
+func (cfg *ControllerConfig) Validate() error {
+	switch cfg.QueueFairness {
+	case QueueFairnessNone, QueueFairnessNamespace, QueueFairnessMesh:
+	default:
+		return errors.Errorf("unknown queue fairness: %v", cfg.QueueFairness)
+	}
+	if cfg.MaxConcurrentReconciles < 1 {
+		return errors.Errorf("%v must be positive, got %v", flagMaxConcurrentReconciles, cfg.MaxConcurrentReconciles)
+	}
+	for name, concurrency := range cfg.MaxConcurrentReconcilesByController {
+		if concurrency < 1 {
+			return errors.Errorf("%v of %v must be positive, got %v", flagMaxConcurrentReconcilesByController, name, concurrency)
+		}
+	}
+	return nil
+}
+
+// MeshResolver resolves the mesh of object in request, the boolean is false if it cannot be resolved.
+type MeshResolver func(req reconcile.Request) (string, bool)
+
+// ControllerOptionsFactory constructs options of controllers.
+type ControllerOptionsFactory interface {
+	// ControllerOptions returns options of the controller named controllerName.
+	// meshResolver resolves meshes of requests for mesh fairness, nil if objects of the controller don't belong to meshes.
+	ControllerOptions(controllerName string, meshResolver MeshResolver) controller.Options
+	// Reconciler returns reconciler of the controller named controllerName, instrumented with reconcile metrics and traces.
+	Reconciler(controllerName string, reconciler reconcile.Reconciler) reconcile.Reconciler
+}
+
+// NewDefaultControllerOptionsFactory constructs new defaultControllerOptionsFactory
+// meshOwnership filters requests by meshes owned by this replica, nil if meshes are not sharded among replicas.
+// requestDependencies orders requests of controllers by their dependencies, nil if requests aren't ordered.
+// tracer traces reconciles, nil if tracing is disabled.
+// log logs requests dropped by sharded queues.
+func NewDefaultControllerOptionsFactory(cfg ControllerConfig, meshOwnership MeshOwnership, requestDependencies RequestDependencies,
+	registerer prometheus.Registerer, tracer tracing.Tracer, log logr.Logger) (*defaultControllerOptionsFactory, error) {
+	var tenantDepth *prometheus.GaugeVec
+	var shardDroppedRequests *prometheus.CounterVec
+	var reconcileMetrics *reconcileMetrics
+	if registerer != nil {
+		var err error
+		if cfg.QueueFairness != QueueFairnessNone {
+			tenantDepth, err = newTenantDepthMetric(registerer)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if meshOwnership != nil {
+			shardDroppedRequests, err = newShardedQueueDroppedRequestsMetric(registerer)
+			if err != nil {
+				return nil, err
+			}
+		}
+		reconcileMetrics, err = newReconcileMetrics(registerer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var requestOrderer *RequestOrderer
+	if requestDependencies != nil {
+		requestOrderer = NewRequestOrderer(requestDependencies, log)
+	}
+	return &defaultControllerOptionsFactory{
+		cfg:                  cfg,
+		meshOwnership:        meshOwnership,
+		requestOrderer:       requestOrderer,
+		tenantDepth:          tenantDepth,
+		shardDroppedRequests: shardDroppedRequests,
+		reconcileMetrics:     reconcileMetrics,
+		tracer:               tracer,
+		log:                  log,
+	}, nil
+}
+
+var _ ControllerOptionsFactory = &defaultControllerOptionsFactory{}
+
+// defaultControllerOptionsFactory implements ControllerOptionsFactory
+type defaultControllerOptionsFactory struct {
+	cfg                  ControllerConfig
+	meshOwnership        MeshOwnership
+	requestOrderer       *RequestOrderer
+	tenantDepth          *prometheus.GaugeVec
+	shardDroppedRequests *prometheus.CounterVec
+	reconcileMetrics     *reconcileMetrics
+	tracer               tracing.Tracer
+	log                  logr.Logger
+}
+
+func (f *defaultControllerOptionsFactory) ControllerOptions(controllerName string, meshResolver MeshResolver) controller.Options {
+	maxConcurrentReconciles := f.cfg.MaxConcurrentReconciles
+	if concurrency, ok := f.cfg.MaxConcurrentReconcilesByController[controllerName]; ok {
+		maxConcurrentReconciles = concurrency
+	}
+	options := controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}
+	tenantFunc := f.tenantFunc(meshResolver)
+	ordered := f.requestOrderer != nil && f.requestOrderer.Orders(controllerName)
+	if tenantFunc == nil && f.meshOwnership == nil && !ordered {
+		return options
+	}
+	options.NewQueue = func(name string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		var queue workqueue.TypedRateLimitingInterface[reconcile.Request]
+		if tenantFunc != nil {
+			queue = NewFairQueue(name, rateLimiter, tenantFunc, f.tenantDepth)
+		} else {
+			queue = workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{Name: name})
+		}
+		if ordered {
+			// ordering sits below sharding, so that requests of meshes not owned are never held.
+			queue = f.requestOrderer.Wrap(controllerName, queue)
+		}
+		if f.meshOwnership == nil {
+			return queue
+		}
+		if meshResolver == nil {
+			// objects that don't belong to meshes are reconciled by the owner of the mesh with empty name.
+			meshResolver = func(_ reconcile.Request) (string, bool) { return "", true }
+		}
+		return NewShardedQueue(name, queue, meshResolver, f.meshOwnership, f.shardDroppedRequests, f.log.WithValues("controller", name))
+	}
+	return options
+}
+
+func (f *defaultControllerOptionsFactory) Reconciler(controllerName string, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	if f.reconcileMetrics == nil && f.tracer == nil {
+		return reconciler
+	}
+	return &instrumentedReconciler{
+		controllerName: controllerName,
+		reconciler:     reconciler,
+		metrics:        f.reconcileMetrics,
+		tracer:         f.tracer,
+	}
+}
+
+// tenantFunc returns the TenantFunc by queue fairness, nil if requests are dequeued in FIFO order.
+func (f *defaultControllerOptionsFactory) tenantFunc(meshResolver MeshResolver) TenantFunc {
+	switch f.cfg.QueueFairness {
+	case QueueFairnessNamespace:
+		return namespaceTenant
+	case QueueFairnessMesh:
+		if meshResolver == nil {
+			return namespaceTenant
+		}
+		return func(req reconcile.Request) string {
+			if mesh, ok := meshResolver(req); ok {
+				return "mesh/" + mesh
+			}
+			return namespaceTenant(req)
+		}
+	default:
+		return nil
+	}
+}
+
+// namespaceTenant uses namespace of request as tenant, and each cluster scoped object as its own tenant.
+func namespaceTenant(req reconcile.Request) string {
+	if req.Namespace == "" {
+		return "cluster/" + req.Name
+	}
+	return "namespace/" + req.Namespace
+}
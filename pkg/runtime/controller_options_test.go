@@ -0,0 +1,166 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestControllerConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ControllerConfig
+		wantErr error
+	}{
+		{
+			name: "valid config",
+			cfg: ControllerConfig{
+				QueueFairness:                       QueueFairnessMesh,
+				MaxConcurrentReconciles:             3,
+				MaxConcurrentReconcilesByController: map[string]int{"virtualnode": 10},
+			},
+		},
+		{
+			name: "unknown queue fairness",
+			cfg: ControllerConfig{
+				QueueFairness:           "team",
+				MaxConcurrentReconciles: 3,
+			},
+			wantErr: errors.New("unknown queue fairness: team"),
+		},
+		{
+			name: "non-positive max concurrent reconciles",
+			cfg: ControllerConfig{
+				QueueFairness:           QueueFairnessNone,
+				MaxConcurrentReconciles: 0,
+			},
+			wantErr: errors.New("max-concurrent-reconciles must be positive, got 0"),
+		},
+		{
+			name: "non-positive max concurrent reconciles of controller",
+			cfg: ControllerConfig{
+				QueueFairness:                       QueueFairnessNamespace,
+				MaxConcurrentReconciles:             3,
+				MaxConcurrentReconcilesByController: map[string]int{"cloudMap": -1},
+			},
+			wantErr: errors.New("max-concurrent-reconciles-by-controller of cloudMap must be positive, got -1"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_defaultControllerOptionsFactory_ControllerOptions(t *testing.T) {
+	cfg := ControllerConfig{
+		QueueFairness:                       QueueFairnessNone,
+		MaxConcurrentReconciles:             3,
+		MaxConcurrentReconcilesByController: map[string]int{"virtualnode": 10},
+	}
+	f, err := NewDefaultControllerOptionsFactory(cfg, nil, nil, nil, nil, logr.Discard())
+	assert.NoError(t, err)
+
+	options := f.ControllerOptions("virtualnode", nil)
+	assert.Equal(t, 10, options.MaxConcurrentReconciles)
+	assert.Nil(t, options.NewQueue)
+	options = f.ControllerOptions("virtualservice", nil)
+	assert.Equal(t, 3, options.MaxConcurrentReconciles)
+	assert.Nil(t, options.NewQueue)
+
+	f.cfg.QueueFairness = QueueFairnessNamespace
+	options = f.ControllerOptions("virtualservice", nil)
+	assert.NotNil(t, options.NewQueue)
+
+	// requests are filtered by mesh ownership even if they're dequeued in FIFO order.
+	f.cfg.QueueFairness = QueueFairnessNone
+	f.meshOwnership = &fakeMeshOwnership{meshes: sets.NewString("")}
+	options = f.ControllerOptions("virtualservice", nil)
+	assert.NotNil(t, options.NewQueue)
+	queue := options.NewQueue("virtualservice", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	queue.Add(newTestRequest("ns", "vs"))
+	assert.Equal(t, 1, queue.Len())
+
+	// requests of ordered controllers are dispatched in dependency order even if they're dequeued in FIFO order.
+	f.meshOwnership = nil
+	f.requestOrderer = NewRequestOrderer(&fakeRequestDependencies{orderedControllers: []string{"virtualrouter"}}, logr.Discard())
+	options = f.ControllerOptions("virtualservice", nil)
+	assert.Nil(t, options.NewQueue)
+	options = f.ControllerOptions("virtualrouter", nil)
+	assert.NotNil(t, options.NewQueue)
+	queue = options.NewQueue("virtualrouter", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	assert.IsType(t, &orderedQueue{}, queue)
+}
+
+func Test_defaultControllerOptionsFactory_tenantFunc(t *testing.T) {
+	meshResolver := func(req reconcile.Request) (string, bool) {
+		if req.Name == "unknown" {
+			return "", false
+		}
+		return "mesh-" + req.Namespace, true
+	}
+	tests := []struct {
+		name          string
+		queueFairness string
+		meshResolver  MeshResolver
+		req           reconcile.Request
+		wantTenant    string
+	}{
+		{
+			name:          "namespace fairness",
+			queueFairness: QueueFairnessNamespace,
+			meshResolver:  meshResolver,
+			req:           newTestRequest("ns", "vn"),
+			wantTenant:    "namespace/ns",
+		},
+		{
+			name:          "namespace fairness with cluster scoped object",
+			queueFairness: QueueFairnessNamespace,
+			req:           newTestRequest("", "mesh"),
+			wantTenant:    "cluster/mesh",
+		},
+		{
+			name:          "mesh fairness",
+			queueFairness: QueueFairnessMesh,
+			meshResolver:  meshResolver,
+			req:           newTestRequest("ns", "vn"),
+			wantTenant:    "mesh/mesh-ns",
+		},
+		{
+			name:          "mesh fairness falls back to namespace when mesh cannot be resolved",
+			queueFairness: QueueFairnessMesh,
+			meshResolver:  meshResolver,
+			req:           newTestRequest("ns", "unknown"),
+			wantTenant:    "namespace/ns",
+		},
+		{
+			name:          "mesh fairness falls back to namespace without meshResolver",
+			queueFairness: QueueFairnessMesh,
+			req:           newTestRequest("ns", "vn"),
+			wantTenant:    "namespace/ns",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &defaultControllerOptionsFactory{cfg: ControllerConfig{QueueFairness: tt.queueFairness}}
+			tenantFunc := f.tenantFunc(tt.meshResolver)
+			assert.Equal(t, tt.wantTenant, tenantFunc(tt.req))
+		})
+	}
+
+	f := &defaultControllerOptionsFactory{cfg: ControllerConfig{QueueFairness: QueueFairnessNone}}
+	assert.Nil(t, f.tenantFunc(meshResolver))
+}
@@ -0,0 +1,120 @@
+package runtime
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	metricSubsystemWorkqueue = "workqueue"
+	metricTenantDepth        = "tenant_depth"
+
+	labelName   = "name"
+	labelTenant = "tenant"
+)
+
+// TenantFunc returns the tenant of request, requests from different tenants are dequeued fairly.
+type TenantFunc func(req reconcile.Request) string
+
+// NewFairQueue constructs a rate limiting workqueue that dequeues requests from different tenants in a round-robin manner,
+// so that a tenant with many requests won't starve the others.
+// depth of each tenant is reported to tenantDepth if it's not nil.
+func NewFairQueue(name string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request], tenantFunc TenantFunc,
+	tenantDepth *prometheus.GaugeVec) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	storage := newFairQueueStorage(tenantFunc, func(tenant string, depth int) {
+		if tenantDepth == nil {
+			return
+		}
+		if depth == 0 {
+			tenantDepth.DeleteLabelValues(name, tenant)
+			return
+		}
+		tenantDepth.WithLabelValues(name, tenant).Set(float64(depth))
+	})
+	queue := workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[reconcile.Request]{
+		Name:  name,
+		Queue: storage,
+	})
+	delayingQueue := workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[reconcile.Request]{
+		Name:  name,
+		Queue: queue,
+	})
+	return workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+		Name:          name,
+		DelayingQueue: delayingQueue,
+	})
+}
+
+// newTenantDepthMetric allocates and registers the metric of queue depth by tenant.
+func newTenantDepthMetric(registerer prometheus.Registerer) (*prometheus.GaugeVec, error) {
+	tenantDepth := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metricSubsystemWorkqueue,
+		Name:      metricTenantDepth,
+		Help:      "Current depth of fair workqueues by tenant",
+	}, []string{labelName, labelTenant})
+	if err := registerer.Register(tenantDepth); err != nil {
+		return nil, err
+	}
+	return tenantDepth, nil
+}
+
+var _ workqueue.Queue[reconcile.Request] = &fairQueueStorage{}
+
+// fairQueueStorage stores requests of each tenant in a FIFO sub-queue, and pops from sub-queues in a round-robin manner.
+// it's always accessed under the lock of workqueue, which also deduplicates requests.
+type fairQueueStorage struct {
+	tenantFunc TenantFunc
+	// sub-queues by tenant, only non-empty sub-queues are kept.
+	queues map[string][]reconcile.Request
+	// tenants with non-empty sub-queues, the first one is popped next.
+	tenants []string
+	length  int
+	// onDepthChange is invoked with the new depth of tenant whenever it's changed.
+	onDepthChange func(tenant string, depth int)
+}
+
+func newFairQueueStorage(tenantFunc TenantFunc, onDepthChange func(tenant string, depth int)) *fairQueueStorage {
+	return &fairQueueStorage{
+		tenantFunc:    tenantFunc,
+		queues:        make(map[string][]reconcile.Request),
+		onDepthChange: onDepthChange,
+	}
+}
+
+// Touch keeps the position of requests that are already queued.
+func (s *fairQueueStorage) Touch(_ reconcile.Request) {}
+
+func (s *fairQueueStorage) Push(req reconcile.Request) {
+	tenant := s.tenantFunc(req)
+	queue, ok := s.queues[tenant]
+	if !ok {
+		s.tenants = append(s.tenants, tenant)
+	}
+	s.queues[tenant] = append(queue, req)
+	s.length++
+	s.onDepthChange(tenant, len(s.queues[tenant]))
+}
+
+func (s *fairQueueStorage) Len() int {
+	return s.length
+}
+
+func (s *fairQueueStorage) Pop() reconcile.Request {
+	tenant := s.tenants[0]
+	s.tenants[0] = ""
+	s.tenants = s.tenants[1:]
+	queue := s.queues[tenant]
+	req := queue[0]
+	queue[0] = reconcile.Request{}
+	queue = queue[1:]
+	if len(queue) == 0 {
+		delete(s.queues, tenant)
+	} else {
+		s.queues[tenant] = queue
+		s.tenants = append(s.tenants, tenant)
+	}
+	s.length--
+	s.onDepthChange(tenant, len(queue))
+	return req
+}
@@ -0,0 +1,106 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestRequest(namespace string, name string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}
+}
+
+func Test_fairQueueStorage(t *testing.T) {
+	depthByTenant := make(map[string]int)
+	storage := newFairQueueStorage(namespaceTenant, func(tenant string, depth int) {
+		depthByTenant[tenant] = depth
+	})
+	for _, req := range []reconcile.Request{
+		newTestRequest("ns-a", "a-1"),
+		newTestRequest("ns-a", "a-2"),
+		newTestRequest("ns-a", "a-3"),
+		newTestRequest("ns-b", "b-1"),
+		newTestRequest("ns-c", "c-1"),
+		newTestRequest("ns-b", "b-2"),
+	} {
+		storage.Push(req)
+	}
+	assert.Equal(t, 6, storage.Len())
+	assert.Equal(t, map[string]int{"namespace/ns-a": 3, "namespace/ns-b": 2, "namespace/ns-c": 1}, depthByTenant)
+
+	var popped []reconcile.Request
+	popped = append(popped, storage.Pop(), storage.Pop())
+	// a new tenant joins the end of the round-robin order.
+	storage.Push(newTestRequest("ns-d", "d-1"))
+	for storage.Len() > 0 {
+		popped = append(popped, storage.Pop())
+	}
+	assert.Equal(t, []reconcile.Request{
+		newTestRequest("ns-a", "a-1"),
+		newTestRequest("ns-b", "b-1"),
+		newTestRequest("ns-c", "c-1"),
+		newTestRequest("ns-a", "a-2"),
+		newTestRequest("ns-b", "b-2"),
+		newTestRequest("ns-d", "d-1"),
+		newTestRequest("ns-a", "a-3"),
+	}, popped)
+	assert.Equal(t, map[string]int{"namespace/ns-a": 0, "namespace/ns-b": 0, "namespace/ns-c": 0, "namespace/ns-d": 0}, depthByTenant)
+	assert.Empty(t, storage.queues)
+	assert.Empty(t, storage.tenants)
+}
+
+func TestNewFairQueue(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	tenantDepth, err := newTenantDepthMetric(registry)
+	assert.NoError(t, err)
+	queue := NewFairQueue("test-fair-queue", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request](), namespaceTenant, tenantDepth)
+	defer queue.ShutDown()
+
+	for i := 0; i < 3; i++ {
+		queue.Add(newTestRequest("ns-a", "a-1"))
+		queue.Add(newTestRequest("ns-a", "a-2"))
+	}
+	queue.Add(newTestRequest("ns-b", "b-1"))
+	assert.Equal(t, 3, queue.Len())
+	assert.Equal(t, map[string]float64{"namespace/ns-a": 2, "namespace/ns-b": 1}, gatherTenantDepth(t, registry, "test-fair-queue"))
+
+	var got []reconcile.Request
+	for queue.Len() > 0 {
+		req, shutdown := queue.Get()
+		assert.False(t, shutdown)
+		got = append(got, req)
+		queue.Done(req)
+	}
+	assert.Equal(t, []reconcile.Request{
+		newTestRequest("ns-a", "a-1"),
+		newTestRequest("ns-b", "b-1"),
+		newTestRequest("ns-a", "a-2"),
+	}, got)
+	assert.Empty(t, gatherTenantDepth(t, registry, "test-fair-queue"))
+}
+
+// gatherTenantDepth returns depth by tenant of the queue named name from registry.
+func gatherTenantDepth(t *testing.T, registry *prometheus.Registry, name string) map[string]float64 {
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+	depthByTenant := make(map[string]float64)
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != metricSubsystemWorkqueue+"_"+metricTenantDepth {
+			continue
+		}
+		for _, metric := range metricFamily.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels[labelName] == name {
+				depthByTenant[labels[labelTenant]] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return depthByTenant
+}
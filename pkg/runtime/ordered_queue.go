@@ -0,0 +1,212 @@
+package runtime
+
+import (
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ControllerRequest is a reconcile request of the controller named Controller.
+type ControllerRequest struct {
+	Controller string
+	reconcile.Request
+}
+
+// RequestDependencies resolves dependencies between requests of controllers that are reconciled in dependency order.
+type RequestDependencies interface {
+	// Orders returns whether requests of the controller named controllerName are reconciled in dependency order.
+	Orders(controllerName string) bool
+	// PendingDependencies returns the requests among pending that req must be reconciled after.
+	PendingDependencies(req ControllerRequest, pending []ControllerRequest) []ControllerRequest
+}
+
+// NewRequestOrderer constructs new RequestOrderer that orders requests of controllers by dependencies.
+func NewRequestOrderer(dependencies RequestDependencies, log logr.Logger) *RequestOrderer {
+	return &RequestOrderer{
+		dependencies: dependencies,
+		log:          log,
+		queues:       make(map[string]workqueue.TypedRateLimitingInterface[reconcile.Request]),
+		states:       make(map[ControllerRequest]*requestState),
+		held:         make(map[ControllerRequest][]ControllerRequest),
+	}
+}
+
+// RequestOrderer dispatches requests queued across the queues of ordered controllers in dependency order.
+// A request is pending while it's queued or being reconciled, and a request is held once dequeued
+// until none of the pending requests it depends on remain pending, then it's queued again.
+// Held requests aren't pending, so requests waiting for each other never hold each other forever.
+// Requests delayed by AddAfter or AddRateLimited are only pending once they're dequeued,
+// so that retries of a failing request don't hold its dependents.
+type RequestOrderer struct {
+	dependencies RequestDependencies
+	log          logr.Logger
+
+	mutex sync.Mutex
+	// underlying queues by controller, which held requests are queued to again.
+	queues map[string]workqueue.TypedRateLimitingInterface[reconcile.Request]
+	// states of requests that are queued, being reconciled or held.
+	states map[ControllerRequest]*requestState
+	// held requests with the pending requests they wait for.
+	held map[ControllerRequest][]ControllerRequest
+}
+
+type requestState struct {
+	// queued is true once request is added, until it's dequeued.
+	queued bool
+	// inFlight is true once request is dequeued, until it's done.
+	inFlight bool
+}
+
+// Wrap wraps queue of the controller named controllerName, so that its requests are dispatched in dependency order.
+func (o *RequestOrderer) Wrap(controllerName string, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.queues[controllerName] = queue
+	return &orderedQueue{
+		TypedRateLimitingInterface: queue,
+		controllerName:             controllerName,
+		orderer:                    o,
+	}
+}
+
+// Orders returns whether requests of the controller named controllerName are dispatched in dependency order.
+func (o *RequestOrderer) Orders(controllerName string) bool {
+	return o.dependencies.Orders(controllerName)
+}
+
+// add tracks req as queued, it must be called before req is added to the underlying queue,
+// so that req is never tracked as queued after it's dequeued.
+func (o *RequestOrderer) add(req ControllerRequest) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.stateLocked(req).queued = true
+}
+
+// dispatch tracks req as being reconciled and returns true if it has no pending dependencies, holds req otherwise.
+func (o *RequestOrderer) dispatch(req ControllerRequest) bool {
+	o.mutex.Lock()
+	state := o.stateLocked(req)
+	state.queued = false
+	state.inFlight = true
+	var pending []ControllerRequest
+	for other := range o.states {
+		if other != req && o.isPendingLocked(other) {
+			pending = append(pending, other)
+		}
+	}
+	o.mutex.Unlock()
+	if len(pending) == 0 {
+		return true
+	}
+
+	// dependencies are resolved without the lock held, so requests done meanwhile are filtered below.
+	dependencies := o.dependencies.PendingDependencies(req, pending)
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	var blockers []ControllerRequest
+	for _, dependency := range dependencies {
+		if o.isPendingLocked(dependency) {
+			blockers = append(blockers, dependency)
+		}
+	}
+	if len(blockers) == 0 {
+		return true
+	}
+	o.log.V(1).Info("holding request until its dependencies are reconciled", "controller", req.Controller, "request", req.Request, "dependencies", blockers)
+	o.held[req] = blockers
+	// req is no longer pending once held, which may release requests waiting for it.
+	o.releaseLocked()
+	return false
+}
+
+// done tracks req as no longer being reconciled, and releases requests that waited for it.
+func (o *RequestOrderer) done(req ControllerRequest) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if state, ok := o.states[req]; ok {
+		state.inFlight = false
+		if !state.queued {
+			delete(o.states, req)
+		}
+	}
+	o.releaseLocked()
+}
+
+// releaseLocked queues held requests again once none of the requests they wait for are pending.
+// must be called with mutex held.
+func (o *RequestOrderer) releaseLocked() {
+	for req, blockers := range o.held {
+		if o.anyPendingLocked(blockers) {
+			continue
+		}
+		delete(o.held, req)
+		state := o.stateLocked(req)
+		state.inFlight = false
+		state.queued = true
+		queue := o.queues[req.Controller]
+		queue.Done(req.Request)
+		queue.Add(req.Request)
+	}
+}
+
+// isPendingLocked returns whether req is queued or being reconciled.
+// must be called with mutex held.
+func (o *RequestOrderer) isPendingLocked(req ControllerRequest) bool {
+	if _, held := o.held[req]; held {
+		return false
+	}
+	state, ok := o.states[req]
+	return ok && (state.queued || state.inFlight)
+}
+
+func (o *RequestOrderer) anyPendingLocked(reqs []ControllerRequest) bool {
+	for _, req := range reqs {
+		if o.isPendingLocked(req) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *RequestOrderer) stateLocked(req ControllerRequest) *requestState {
+	state, ok := o.states[req]
+	if !ok {
+		state = &requestState{}
+		o.states[req] = state
+	}
+	return state
+}
+
+var _ workqueue.TypedRateLimitingInterface[reconcile.Request] = &orderedQueue{}
+
+// orderedQueue dispatches requests of an underlying queue in dependency order by RequestOrderer.
+type orderedQueue struct {
+	workqueue.TypedRateLimitingInterface[reconcile.Request]
+	controllerName string
+	orderer        *RequestOrderer
+}
+
+func (q *orderedQueue) Add(req reconcile.Request) {
+	q.orderer.add(ControllerRequest{Controller: q.controllerName, Request: req})
+	q.TypedRateLimitingInterface.Add(req)
+}
+
+// Get returns the next request without pending dependencies, requests with pending dependencies are held.
+func (q *orderedQueue) Get() (reconcile.Request, bool) {
+	for {
+		req, shutdown := q.TypedRateLimitingInterface.Get()
+		if shutdown {
+			return req, shutdown
+		}
+		if q.orderer.dispatch(ControllerRequest{Controller: q.controllerName, Request: req}) {
+			return req, false
+		}
+	}
+}
+
+func (q *orderedQueue) Done(req reconcile.Request) {
+	q.orderer.done(ControllerRequest{Controller: q.controllerName, Request: req})
+	q.TypedRateLimitingInterface.Done(req)
+}
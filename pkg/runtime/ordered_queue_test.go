@@ -0,0 +1,159 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeRequestDependencies orders requests of controllers in orderedControllers by dependencies.
+type fakeRequestDependencies struct {
+	orderedControllers []string
+	dependencies       map[ControllerRequest][]ControllerRequest
+}
+
+func (d *fakeRequestDependencies) Orders(controllerName string) bool {
+	for _, orderedController := range d.orderedControllers {
+		if orderedController == controllerName {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *fakeRequestDependencies) PendingDependencies(req ControllerRequest, pending []ControllerRequest) []ControllerRequest {
+	var pendingDependencies []ControllerRequest
+	for _, dependency := range d.dependencies[req] {
+		for _, pendingReq := range pending {
+			if pendingReq == dependency {
+				pendingDependencies = append(pendingDependencies, dependency)
+			}
+		}
+	}
+	return pendingDependencies
+}
+
+func newTestOrderedQueues(dependencies map[ControllerRequest][]ControllerRequest) (workqueue.TypedRateLimitingInterface[reconcile.Request], workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	orderer := NewRequestOrderer(&fakeRequestDependencies{
+		orderedControllers: []string{"virtualnode", "virtualrouter"},
+		dependencies:       dependencies,
+	}, logr.Discard())
+	vnQueue := orderer.Wrap("virtualnode", workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]()))
+	vrQueue := orderer.Wrap("virtualrouter", workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]()))
+	return vnQueue, vrQueue
+}
+
+func Test_orderedQueue_holdsRequestsUntilDependenciesAreDone(t *testing.T) {
+	vnX := newTestRequest("ns", "x")
+	vrX := newTestRequest("ns", "x")
+	vrY := newTestRequest("ns", "y")
+	vnQueue, vrQueue := newTestOrderedQueues(map[ControllerRequest][]ControllerRequest{
+		{Controller: "virtualrouter", Request: vrX}: {{Controller: "virtualnode", Request: vnX}},
+	})
+	defer vnQueue.ShutDown()
+	defer vrQueue.ShutDown()
+
+	vrQueue.Add(vrX)
+	vrQueue.Add(vrY)
+	vnQueue.Add(vnX)
+
+	// vrX is held while vnX is queued, so vrY is dispatched first.
+	req, shutdown := vrQueue.Get()
+	assert.False(t, shutdown)
+	assert.Equal(t, vrY, req)
+	vrQueue.Done(req)
+	assert.Equal(t, 0, vrQueue.Len())
+
+	req, _ = vnQueue.Get()
+	assert.Equal(t, vnX, req)
+	// vrX is still held while vnX is being reconciled.
+	assert.Equal(t, 0, vrQueue.Len())
+	vnQueue.Done(req)
+
+	assert.Equal(t, 1, vrQueue.Len())
+	req, _ = vrQueue.Get()
+	assert.Equal(t, vrX, req)
+	vrQueue.Done(req)
+}
+
+func Test_orderedQueue_heldRequestsAreNotPending(t *testing.T) {
+	vnX := newTestRequest("ns", "x")
+	vrX := newTestRequest("ns", "x")
+	vrY := newTestRequest("ns", "y")
+	// requests that depend on each other are never held by each other.
+	vnQueue, vrQueue := newTestOrderedQueues(map[ControllerRequest][]ControllerRequest{
+		{Controller: "virtualrouter", Request: vrX}: {{Controller: "virtualnode", Request: vnX}},
+		{Controller: "virtualnode", Request: vnX}:   {{Controller: "virtualrouter", Request: vrX}},
+	})
+	defer vnQueue.ShutDown()
+	defer vrQueue.ShutDown()
+
+	vrQueue.Add(vrX)
+	vrQueue.Add(vrY)
+	vnQueue.Add(vnX)
+
+	req, _ := vrQueue.Get()
+	assert.Equal(t, vrY, req)
+	vrQueue.Done(req)
+
+	req, _ = vnQueue.Get()
+	assert.Equal(t, vnX, req)
+	vnQueue.Done(req)
+
+	req, _ = vrQueue.Get()
+	assert.Equal(t, vrX, req)
+	vrQueue.Done(req)
+}
+
+func Test_orderedQueue_delayedRequestsAreNotPending(t *testing.T) {
+	vnX := newTestRequest("ns", "x")
+	vrX := newTestRequest("ns", "x")
+	vnQueue, vrQueue := newTestOrderedQueues(map[ControllerRequest][]ControllerRequest{
+		{Controller: "virtualrouter", Request: vrX}: {{Controller: "virtualnode", Request: vnX}},
+	})
+	defer vnQueue.ShutDown()
+	defer vrQueue.ShutDown()
+
+	vnQueue.AddAfter(vnX, time.Hour)
+	vrQueue.Add(vrX)
+
+	req, _ := vrQueue.Get()
+	assert.Equal(t, vrX, req)
+	vrQueue.Done(req)
+}
+
+func Test_orderedQueue_requeuesRequestsAddedWhileInFlight(t *testing.T) {
+	vnX := newTestRequest("ns", "x")
+	vrX := newTestRequest("ns", "x")
+	vrY := newTestRequest("ns", "y")
+	vnQueue, vrQueue := newTestOrderedQueues(map[ControllerRequest][]ControllerRequest{
+		{Controller: "virtualrouter", Request: vrX}: {{Controller: "virtualnode", Request: vnX}},
+	})
+	defer vnQueue.ShutDown()
+	defer vrQueue.ShutDown()
+
+	vnQueue.Add(vnX)
+	req, _ := vnQueue.Get()
+	assert.Equal(t, vnX, req)
+	// vnX is queued again once done, so vrX is held until vnX is done again.
+	vnQueue.Add(vnX)
+	vnQueue.Done(req)
+
+	vrQueue.Add(vrX)
+	vrQueue.Add(vrY)
+	req, _ = vrQueue.Get()
+	assert.Equal(t, vrY, req)
+	vrQueue.Done(req)
+
+	req, _ = vnQueue.Get()
+	assert.Equal(t, vnX, req)
+	vnQueue.Done(req)
+
+	req, _ = vrQueue.Get()
+	assert.Equal(t, vrX, req)
+	vrQueue.Done(req)
+}
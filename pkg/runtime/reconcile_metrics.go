@@ -0,0 +1,111 @@
+package runtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	metricSubsystemAppMesh         = "appmesh"
+	metricReconcileTotal           = "reconcile_total"
+	metricReconcileDurationSeconds = "reconcile_duration_seconds"
+
+	labelController = "controller"
+	labelResult     = "result"
+
+	reconcileResultSuccess = "success"
+	reconcileResultRequeue = "requeue"
+	reconcileResultError   = "error"
+)
+
+// reconcileMetrics contains metrics of reconcile outcomes and latency by controller.
+type reconcileMetrics struct {
+	reconcileTotal           *prometheus.CounterVec
+	reconcileDurationSeconds *prometheus.HistogramVec
+}
+
+// newReconcileMetrics allocates and registers metrics of reconciles.
+func newReconcileMetrics(registerer prometheus.Registerer) (*reconcileMetrics, error) {
+	reconcileTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metricSubsystemAppMesh,
+		Name:      metricReconcileTotal,
+		Help:      "Total number of reconciles by controller and result, one of success, requeue or error",
+	}, []string{labelController, labelResult})
+	reconcileDurationSeconds := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metricSubsystemAppMesh,
+		Name:      metricReconcileDurationSeconds,
+		Help:      "Latency of reconciles by controller",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{labelController})
+	if err := registerer.Register(reconcileTotal); err != nil {
+		return nil, err
+	}
+	if err := registerer.Register(reconcileDurationSeconds); err != nil {
+		return nil, err
+	}
+	return &reconcileMetrics{
+		reconcileTotal:           reconcileTotal,
+		reconcileDurationSeconds: reconcileDurationSeconds,
+	}, nil
+}
+
+var _ reconcile.Reconciler = &instrumentedReconciler{}
+
+// instrumentedReconciler records outcome and latency of reconciles of the controller named controllerName,
+// and traces them if tracer is set.
+type instrumentedReconciler struct {
+	controllerName string
+	reconciler     reconcile.Reconciler
+	// metrics of reconciles, nil if metrics are disabled.
+	metrics *reconcileMetrics
+	// tracer of reconciles, nil if tracing is disabled.
+	tracer tracing.Tracer
+}
+
+func (r *instrumentedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	startTime := time.Now()
+	var span trace.Span
+	if r.tracer != nil {
+		ctx, span = r.tracer.StartTrace(ctx, "reconcile "+r.controllerName,
+			attribute.String(labelController, r.controllerName),
+			attribute.String("k8s.namespace.name", req.Namespace),
+			attribute.String("k8s.object.name", req.Name),
+		)
+		ctx = log.IntoContext(ctx, tracing.LoggerFromContext(ctx, log.FromContext(ctx)))
+	}
+	result, err := r.reconciler.Reconcile(ctx, req)
+	resultLabel := reconcileResultOf(result, err)
+	if span != nil {
+		span.SetAttributes(attribute.String(labelResult, resultLabel))
+		tracing.RecordError(span, err)
+		if err != nil && span.IsRecording() {
+			// reconcile errors are logged by controller-runtime without trace, so they're correlated by reconcileID here.
+			log.FromContext(ctx).Info("traced reconcile failed", "error", err.Error())
+		}
+		span.End()
+	}
+	if r.metrics != nil {
+		r.metrics.reconcileDurationSeconds.WithLabelValues(r.controllerName).Observe(time.Since(startTime).Seconds())
+		r.metrics.reconcileTotal.WithLabelValues(r.controllerName, resultLabel).Inc()
+	}
+	return result, err
+}
+
+// reconcileResultOf returns the result label of reconcile, requeues by RequeueError or RequeueAfterError are not errors.
+func reconcileResultOf(result reconcile.Result, err error) string {
+	switch {
+	case err != nil:
+		return reconcileResultError
+	case result.Requeue || result.RequeueAfter > 0:
+		return reconcileResultRequeue
+	default:
+		return reconcileResultSuccess
+	}
+}
@@ -0,0 +1,107 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_reconcileResultOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		result reconcile.Result
+		err    error
+		want   string
+	}{
+		{
+			name: "success",
+			want: reconcileResultSuccess,
+		},
+		{
+			name:   "requeue",
+			result: reconcile.Result{Requeue: true},
+			want:   reconcileResultRequeue,
+		},
+		{
+			name:   "requeue after",
+			result: reconcile.Result{RequeueAfter: time.Minute},
+			want:   reconcileResultRequeue,
+		},
+		{
+			name: "error",
+			err:  errors.New("oops"),
+			want: reconcileResultError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reconcileResultOf(tt.result, tt.err))
+		})
+	}
+}
+
+func Test_defaultControllerOptionsFactory_Reconciler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	f, err := NewDefaultControllerOptionsFactory(ControllerConfig{QueueFairness: QueueFairnessNone, MaxConcurrentReconciles: 1}, nil, nil, registry, nil, logr.Discard())
+	assert.NoError(t, err)
+
+	errs := []error{nil, NewRequeueAfterError(errors.New("pending"), time.Minute), errors.New("oops"), nil}
+	reconciler := f.Reconciler("virtualnode", reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		err := errs[0]
+		errs = errs[1:]
+		return HandleReconcileError(err, logr.Discard())
+	}))
+	for i := 0; i < 4; i++ {
+		_, _ = reconciler.Reconcile(context.Background(), newTestRequest("ns", "vn"))
+	}
+
+	metricFamilies, err := registry.Gather()
+	assert.NoError(t, err)
+	reconcileTotal := make(map[string]float64)
+	var reconcileCount uint64
+	for _, metricFamily := range metricFamilies {
+		for _, metric := range metricFamily.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			assert.Equal(t, "virtualnode", labels[labelController])
+			switch metricFamily.GetName() {
+			case metricSubsystemAppMesh + "_" + metricReconcileTotal:
+				reconcileTotal[labels[labelResult]] = metric.GetCounter().GetValue()
+			case metricSubsystemAppMesh + "_" + metricReconcileDurationSeconds:
+				reconcileCount = metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{reconcileResultSuccess: 2, reconcileResultRequeue: 1, reconcileResultError: 1}, reconcileTotal)
+	assert.Equal(t, uint64(4), reconcileCount)
+}
+
+func Test_defaultControllerOptionsFactory_Reconciler_tracing(t *testing.T) {
+	tracer, err := tracing.NewDefaultTracer(tracing.Config{OTLPEndpoint: "http://localhost:4318", SamplingRatio: 1})
+	assert.NoError(t, err)
+	f, err := NewDefaultControllerOptionsFactory(ControllerConfig{QueueFairness: QueueFairnessNone, MaxConcurrentReconciles: 1}, nil, nil, nil, tracer, logr.Discard())
+	assert.NoError(t, err)
+
+	var traceIDs []string
+	reconciler := f.Reconciler("virtualnode", reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		traceIDs = append(traceIDs, tracing.TraceIDFromContext(ctx))
+		return reconcile.Result{}, nil
+	}))
+	for i := 0; i < 2; i++ {
+		_, err := reconciler.Reconcile(context.Background(), newTestRequest("ns", "vn"))
+		assert.NoError(t, err)
+	}
+	assert.Len(t, traceIDs, 2)
+	assert.NotEmpty(t, traceIDs[0])
+	assert.NotEmpty(t, traceIDs[1])
+	assert.NotEqual(t, traceIDs[0], traceIDs[1])
+}
@@ -0,0 +1,187 @@
+package runtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	metricShardDroppedRequests = "shard_dropped_requests_total"
+)
+
+// MeshOwnership tells which meshes are owned by this replica when meshes are sharded among replicas.
+type MeshOwnership interface {
+	// OwnsMesh returns whether objects of mesh should be reconciled by this replica.
+	OwnsMesh(meshName string) bool
+	// AddOwnershipChangeHandler registers handler to be invoked after meshes are acquired or dropped by this replica.
+	AddOwnershipChangeHandler(handler func())
+	// AddInFlightMeshesFunc registers inFlightMeshes that returns meshes with requests being reconciled,
+	// meshes dropped by this replica are only released to other replicas once their requests are done.
+	AddInFlightMeshesFunc(inFlightMeshes func() []string)
+}
+
+// NewShardedQueue wraps queue so that only requests for meshes owned by this replica are processed.
+// requests for other meshes are parked, and re-added once their meshes are acquired.
+// requests that cannot be resolved to a mesh are sharded as objects of the mesh with empty name,
+// and dropped instead of parked if not owned, since their objects are usually gone already.
+// dropped requests are logged and counted by droppedRequests if it isn't nil.
+func NewShardedQueue(name string, queue workqueue.TypedRateLimitingInterface[reconcile.Request], meshResolver MeshResolver, meshOwnership MeshOwnership,
+	droppedRequests *prometheus.CounterVec, log logr.Logger) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	q := &shardedQueue{
+		TypedRateLimitingInterface: queue,
+		meshResolver:               meshResolver,
+		meshOwnership:              meshOwnership,
+		parked:                     make(map[reconcile.Request]struct{}),
+		inFlight:                   make(map[reconcile.Request]string),
+		log:                        log,
+	}
+	if droppedRequests != nil {
+		q.droppedRequests = droppedRequests.With(prometheus.Labels{labelName: name})
+	}
+	meshOwnership.AddOwnershipChangeHandler(q.onOwnershipChange)
+	meshOwnership.AddInFlightMeshesFunc(q.inFlightMeshes)
+	return q
+}
+
+// newShardedQueueDroppedRequestsMetric allocates and registers the metric of requests dropped by sharded queues.
+func newShardedQueueDroppedRequestsMetric(registerer prometheus.Registerer) (*prometheus.CounterVec, error) {
+	droppedRequests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metricSubsystemWorkqueue,
+		Name:      metricShardDroppedRequests,
+		Help:      "Total number of requests dropped by sharded workqueues since their meshes cannot be resolved",
+	}, []string{labelName})
+	if err := registerer.Register(droppedRequests); err != nil {
+		return nil, err
+	}
+	return droppedRequests, nil
+}
+
+var _ workqueue.TypedRateLimitingInterface[reconcile.Request] = &shardedQueue{}
+
+// shardedQueue filters requests of an underlying queue by mesh ownership.
+type shardedQueue struct {
+	workqueue.TypedRateLimitingInterface[reconcile.Request]
+	meshResolver  MeshResolver
+	meshOwnership MeshOwnership
+
+	// nil if metrics aren't collected.
+	droppedRequests prometheus.Counter
+	log             logr.Logger
+
+	// mutex serializes ownership checks with parking, so that no request is parked after ownership change handled.
+	mutex  sync.Mutex
+	parked map[reconcile.Request]struct{}
+	// inFlight tracks meshes of requests returned by Get until they're done.
+	inFlight map[reconcile.Request]string
+}
+
+func (q *shardedQueue) Add(req reconcile.Request) {
+	if q.parkIfNotOwned(req) {
+		return
+	}
+	q.TypedRateLimitingInterface.Add(req)
+}
+
+func (q *shardedQueue) AddAfter(req reconcile.Request, duration time.Duration) {
+	if q.parkIfNotOwned(req) {
+		return
+	}
+	q.TypedRateLimitingInterface.AddAfter(req, duration)
+}
+
+func (q *shardedQueue) AddRateLimited(req reconcile.Request) {
+	if q.parkIfNotOwned(req) {
+		return
+	}
+	q.TypedRateLimitingInterface.AddRateLimited(req)
+}
+
+// Get returns the next request for owned meshes, requests for meshes lost after they're added are parked.
+func (q *shardedQueue) Get() (reconcile.Request, bool) {
+	for {
+		req, shutdown := q.TypedRateLimitingInterface.Get()
+		if shutdown {
+			return req, shutdown
+		}
+		if q.startIfOwned(req) {
+			return req, false
+		}
+		q.TypedRateLimitingInterface.Forget(req)
+		q.TypedRateLimitingInterface.Done(req)
+	}
+}
+
+func (q *shardedQueue) Done(req reconcile.Request) {
+	q.mutex.Lock()
+	delete(q.inFlight, req)
+	q.mutex.Unlock()
+	q.TypedRateLimitingInterface.Done(req)
+}
+
+// parkIfNotOwned returns true and parks req if it's not for meshes owned by this replica.
+func (q *shardedQueue) parkIfNotOwned(req reconcile.Request) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	_, parked := q.parkIfNotOwnedLocked(req)
+	return parked
+}
+
+// startIfOwned returns true and tracks req as in flight if it's for meshes owned by this replica, parks req otherwise.
+func (q *shardedQueue) startIfOwned(req reconcile.Request) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	meshName, parked := q.parkIfNotOwnedLocked(req)
+	if parked {
+		return false
+	}
+	q.inFlight[req] = meshName
+	return true
+}
+
+// parkIfNotOwnedLocked returns the mesh of req, and parks req if it's not for meshes owned by this replica.
+// must be called with mutex held.
+func (q *shardedQueue) parkIfNotOwnedLocked(req reconcile.Request) (string, bool) {
+	meshName, resolved := q.meshResolver(req)
+	if q.meshOwnership.OwnsMesh(meshName) {
+		return meshName, false
+	}
+	if resolved {
+		q.parked[req] = struct{}{}
+	} else {
+		q.log.V(1).Info("dropping request whose mesh cannot be resolved", "request", req)
+		if q.droppedRequests != nil {
+			q.droppedRequests.Inc()
+		}
+	}
+	return meshName, true
+}
+
+// inFlightMeshes returns meshes of requests being reconciled.
+func (q *shardedQueue) inFlightMeshes() []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	meshNames := sets.NewString()
+	for _, meshName := range q.inFlight {
+		meshNames.Insert(meshName)
+	}
+	return meshNames.List()
+}
+
+// onOwnershipChange re-adds parked requests for meshes acquired by this replica.
+func (q *shardedQueue) onOwnershipChange() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for req := range q.parked {
+		meshName, _ := q.meshResolver(req)
+		if q.meshOwnership.OwnsMesh(meshName) {
+			delete(q.parked, req)
+			q.TypedRateLimitingInterface.Add(req)
+		}
+	}
+}
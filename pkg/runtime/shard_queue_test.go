@@ -0,0 +1,122 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeMeshOwnership owns a fixed set of meshes.
+type fakeMeshOwnership struct {
+	meshes              sets.String
+	handlers            []func()
+	inFlightMeshesFuncs []func() []string
+}
+
+func (o *fakeMeshOwnership) OwnsMesh(meshName string) bool {
+	return o.meshes.Has(meshName)
+}
+
+func (o *fakeMeshOwnership) AddOwnershipChangeHandler(handler func()) {
+	o.handlers = append(o.handlers, handler)
+}
+
+func (o *fakeMeshOwnership) AddInFlightMeshesFunc(inFlightMeshes func() []string) {
+	o.inFlightMeshesFuncs = append(o.inFlightMeshesFuncs, inFlightMeshes)
+}
+
+func (o *fakeMeshOwnership) acquire(meshName string) {
+	o.meshes.Insert(meshName)
+	for _, handler := range o.handlers {
+		handler()
+	}
+}
+
+// namespaceMeshResolver resolves namespace of requests as their mesh, and treats namespace "unknown" as unresolved.
+func namespaceMeshResolver(req reconcile.Request) (string, bool) {
+	if req.Namespace == "unknown" {
+		return "", false
+	}
+	return req.Namespace, true
+}
+
+func drainQueue(t *testing.T, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) []string {
+	var got []string
+	for queue.Len() > 0 {
+		req, shutdown := queue.Get()
+		assert.False(t, shutdown)
+		got = append(got, req.String())
+		queue.Done(req)
+	}
+	return got
+}
+
+func TestNewShardedQueue(t *testing.T) {
+	ownership := &fakeMeshOwnership{meshes: sets.NewString("mesh-a")}
+	registry := prometheus.NewPedanticRegistry()
+	droppedRequests, err := newShardedQueueDroppedRequestsMetric(registry)
+	assert.NoError(t, err)
+	queue := NewShardedQueue("test", workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]()),
+		namespaceMeshResolver, ownership, droppedRequests, logr.Discard())
+	defer queue.ShutDown()
+
+	queue.Add(newTestRequest("mesh-a", "a-1"))
+	queue.Add(newTestRequest("mesh-b", "b-1"))
+	queue.AddRateLimited(newTestRequest("mesh-b", "b-2"))
+	queue.AddAfter(newTestRequest("mesh-c", "c-1"), 0)
+	queue.Add(newTestRequest("unknown", "u-1"))
+	assert.Equal(t, []string{"mesh-a/a-1"}, drainQueue(t, queue))
+
+	// parked requests are re-added once their meshes are acquired.
+	ownership.acquire("mesh-b")
+	got := drainQueue(t, queue)
+	assert.ElementsMatch(t, []string{"mesh-b/b-1", "mesh-b/b-2"}, got)
+
+	// requests for meshes lost after being added are parked on Get.
+	queue.Add(newTestRequest("mesh-a", "a-2"))
+	queue.Add(newTestRequest("mesh-b", "b-3"))
+	ownership.meshes.Delete("mesh-a")
+	assert.Equal(t, []string{"mesh-b/b-3"}, drainQueue(t, queue))
+	ownership.acquire("mesh-a")
+	ownership.acquire("mesh-c")
+	got = drainQueue(t, queue)
+	assert.ElementsMatch(t, []string{"mesh-a/a-2", "mesh-c/c-1"}, got)
+
+	// unresolved requests are dropped instead of parked.
+	assert.Equal(t, float64(1), testutil.ToFloat64(droppedRequests.WithLabelValues("test")))
+	ownership.acquire("")
+	assert.Empty(t, drainQueue(t, queue))
+	queue.Add(newTestRequest("unknown", "u-2"))
+	got = drainQueue(t, queue)
+	assert.Equal(t, 1, len(got))
+	assert.True(t, strings.HasSuffix(got[0], "u-2"))
+}
+
+func TestNewShardedQueue_inFlightMeshes(t *testing.T) {
+	ownership := &fakeMeshOwnership{meshes: sets.NewString("mesh-a", "mesh-b")}
+	queue := NewShardedQueue("test", workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]()),
+		namespaceMeshResolver, ownership, nil, logr.Discard())
+	defer queue.ShutDown()
+	assert.Equal(t, 1, len(ownership.inFlightMeshesFuncs))
+	inFlightMeshes := ownership.inFlightMeshesFuncs[0]
+
+	queue.Add(newTestRequest("mesh-a", "a-1"))
+	queue.Add(newTestRequest("mesh-b", "b-1"))
+	assert.Empty(t, inFlightMeshes())
+
+	// requests are in flight from Get until Done.
+	req1, _ := queue.Get()
+	req2, _ := queue.Get()
+	assert.Equal(t, []string{"mesh-a", "mesh-b"}, inFlightMeshes())
+	queue.Done(req1)
+	assert.Equal(t, []string{req2.Namespace}, inFlightMeshes())
+	queue.Done(req2)
+	assert.Empty(t, inFlightMeshes())
+}
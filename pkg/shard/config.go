@@ -0,0 +1,65 @@
+package shard
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagShardCount          = "shard-count"
+	flagShardLeaseNamespace = "shard-lease-namespace"
+	flagShardLeaseDuration  = "shard-lease-duration"
+	flagShardRenewInterval  = "shard-renew-interval"
+
+	defaultShardLeaseDuration = 15 * time.Second
+	defaultShardRenewInterval = 5 * time.Second
+)
+
+type Config struct {
+	// Specifies the number of shards meshes are split into, sharding is disabled if zero.
+	ShardCount int
+	// Specifies the namespace of shard and member leases.
+	LeaseNamespace string
+	// Specifies how long a shard is owned by a replica without renewal.
+	LeaseDuration time.Duration
+	// Specifies how frequently leases are renewed and shards are rebalanced.
+	RenewInterval time.Duration
+}
+
+func (cfg *Config) BindFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&cfg.ShardCount, flagShardCount, 0,
+		`The number of shards meshes are split into among active replicas, which replaces leader election if positive`)
+	fs.StringVar(&cfg.LeaseNamespace, flagShardLeaseNamespace, "",
+		`The namespace of shard leases, required if sharding is enabled`)
+	fs.DurationVar(&cfg.LeaseDuration, flagShardLeaseDuration, defaultShardLeaseDuration,
+		`How long a shard is owned by a replica without renewal`)
+	fs.DurationVar(&cfg.RenewInterval, flagShardRenewInterval, defaultShardRenewInterval,
+		`How frequently shard leases are renewed and shards are rebalanced among replicas`)
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.ShardCount < 0 {
+		return errors.Errorf("%v must not be negative, got %v", flagShardCount, cfg.ShardCount)
+	}
+	if !cfg.Enabled() {
+		return nil
+	}
+	if cfg.LeaseNamespace == "" {
+		return errors.Errorf("%v is required if sharding is enabled", flagShardLeaseNamespace)
+	}
+	if cfg.RenewInterval <= 0 {
+		return errors.Errorf("%v must be positive, got %v", flagShardRenewInterval, cfg.RenewInterval)
+	}
+	// leases must be renewed at least twice within their duration to tolerate a failed renewal.
+	if cfg.RenewInterval*2 > cfg.LeaseDuration {
+		return errors.Errorf("%v must be at least twice of %v", flagShardLeaseDuration, flagShardRenewInterval)
+	}
+	return nil
+}
+
+// Enabled returns whether meshes are sharded among replicas.
+func (cfg *Config) Enabled() bool {
+	return cfg.ShardCount > 0
+}
@@ -0,0 +1,48 @@
+package shard
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ShardOf returns the shard of mesh among shardCount shards.
+func ShardOf(meshName string, shardCount int) int {
+	h := fnv.New32a()
+	h.Write([]byte(meshName))
+	return int(h.Sum32() % uint32(shardCount))
+}
+
+// desiredShards returns shards that should be owned by identity among members.
+// each shard is assigned to the member with highest score with rendezvous hashing,
+// so that only shards of joining or leaving members are moved when members change.
+func desiredShards(identity string, members []string, shardCount int) sets.Int {
+	shards := sets.NewInt()
+	for shard := 0; shard < shardCount; shard++ {
+		var owner string
+		var ownerScore uint64
+		for _, member := range members {
+			score := rendezvousScore(member, shard)
+			if owner == "" || score > ownerScore || (score == ownerScore && member < owner) {
+				owner, ownerScore = member, score
+			}
+		}
+		if owner == identity {
+			shards.Insert(shard)
+		}
+	}
+	return shards
+}
+
+func rendezvousScore(member string, shard int) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(member))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(shard)))
+	// fnv alone distributes similar keys poorly, so the hash is mixed with the finalizer of splitmix64.
+	score := h.Sum64()
+	score = (score ^ (score >> 30)) * 0xbf58476d1ce4e5b9
+	score = (score ^ (score >> 27)) * 0x94d049bb133111eb
+	return score ^ (score >> 31)
+}
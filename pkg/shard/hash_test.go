@@ -0,0 +1,57 @@
+package shard
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestShardOf(t *testing.T) {
+	for _, meshName := range []string{"", "mesh-a", "mesh-b", "my-mesh"} {
+		shard := ShardOf(meshName, 8)
+		assert.True(t, shard >= 0 && shard < 8)
+		assert.Equal(t, shard, ShardOf(meshName, 8))
+	}
+	assert.Equal(t, 0, ShardOf("my-mesh", 1))
+}
+
+func Test_desiredShards(t *testing.T) {
+	shardCount := 64
+	members := []string{"controller-0", "controller-1", "controller-2"}
+	shardsByMember := make(map[string]sets.Int)
+	allShards := sets.NewInt()
+	for _, member := range members {
+		shards := desiredShards(member, members, shardCount)
+		shardsByMember[member] = shards
+		// each shard is assigned to exactly one member.
+		assert.Empty(t, allShards.Intersection(shards).List())
+		allShards = allShards.Union(shards)
+		assert.True(t, shards.Len() > shardCount/6, "member %v owns %v shards", member, shards.Len())
+	}
+	assert.Equal(t, shardCount, allShards.Len())
+
+	// shards of other members stay where they are when a member leaves.
+	remaining := []string{"controller-0", "controller-2"}
+	for _, member := range remaining {
+		shards := desiredShards(member, remaining, shardCount)
+		assert.True(t, shards.IsSuperset(shardsByMember[member]))
+	}
+	assert.Equal(t, shardCount, desiredShards("controller-0", remaining, shardCount).Len()+desiredShards("controller-2", remaining, shardCount).Len())
+
+	// a joining member only takes shards from others.
+	joined := append(members, "controller-3")
+	for _, member := range members {
+		shards := desiredShards(member, joined, shardCount)
+		assert.True(t, shardsByMember[member].IsSuperset(shards))
+	}
+}
+
+func Test_desiredShards_singleMember(t *testing.T) {
+	for _, shardCount := range []int{1, 4, 16} {
+		t.Run(fmt.Sprintf("%d shards", shardCount), func(t *testing.T) {
+			assert.Equal(t, shardCount, desiredShards("controller-0", []string{"controller-0"}, shardCount).Len())
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	shardLeaseNamePrefix  = "appmesh-controller-shard-"
+	memberLeaseNamePrefix = "appmesh-controller-member-"
+	// label of member leases.
+	labelShardMember = "appmesh.k8s.aws/shard-member"
+
+	// member leases that have expired for this many lease durations are deleted.
+	memberLeaseGCFactor = 10
+	// timeout to release leases on shutdown.
+	releaseTimeout = 5 * time.Second
+)
+
+// Manager splits ownership of meshes among replicas with per-shard leases.
+type Manager interface {
+	runtime.MeshOwnership
+	manager.Runnable
+}
+
+// NewDefaultManager constructs new defaultManager.
+// identity must be unique among replicas, and valid as part of lease names.
+func NewDefaultManager(cfg Config, identity string, k8sClient client.Client, apiReader client.Reader, log logr.Logger) *defaultManager {
+	return &defaultManager{
+		cfg:         cfg,
+		identity:    identity,
+		k8sClient:   k8sClient,
+		apiReader:   apiReader,
+		log:         log,
+		now:         time.Now,
+		renewedAt:   make(map[int]time.Time),
+		draining:    sets.NewInt(),
+		ownedShards: sets.NewInt(),
+	}
+}
+
+var _ Manager = &defaultManager{}
+var _ manager.LeaderElectionRunnable = &defaultManager{}
+
+// defaultManager implements Manager.
+// Replicas announce themselves with member leases, and each shard is assigned to a member with rendezvous hashing.
+// A replica only owns a shard while holding its shard lease, so a shard is never owned by multiple replicas.
+type defaultManager struct {
+	cfg       Config
+	identity  string
+	k8sClient client.Client
+	// leases are read from API server directly to observe other replicas without delay.
+	apiReader client.Reader
+	log       logr.Logger
+	now       func() time.Time
+
+	// last successful renewal of shard leases held by this replica.
+	renewedAt map[int]time.Time
+	// shards dropped by this replica, whose leases are held until requests of their meshes are done.
+	draining sets.Int
+
+	mutex               sync.RWMutex
+	ownedShards         sets.Int
+	handlers            []func()
+	inFlightMeshesFuncs []func() []string
+}
+
+func (m *defaultManager) OwnsMesh(meshName string) bool {
+	shard := ShardOf(meshName, m.cfg.ShardCount)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.ownedShards.Has(shard)
+}
+
+func (m *defaultManager) AddOwnershipChangeHandler(handler func()) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+func (m *defaultManager) AddInFlightMeshesFunc(inFlightMeshes func() []string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.inFlightMeshesFuncs = append(m.inFlightMeshesFuncs, inFlightMeshes)
+}
+
+// NeedLeaderElection returns false since every replica owns a part of shards.
+func (m *defaultManager) NeedLeaderElection() bool {
+	return false
+}
+
+func (m *defaultManager) Start(ctx context.Context) error {
+	m.log.Info("starting shard manager", "identity", m.identity, "shardCount", m.cfg.ShardCount)
+	ticker := time.NewTicker(m.cfg.RenewInterval)
+	defer ticker.Stop()
+	for {
+		m.sync(ctx)
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+			defer cancel()
+			m.releaseAll(releaseCtx)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync renews membership of this replica, then acquires shards assigned to it and releases others.
+func (m *defaultManager) sync(ctx context.Context) {
+	now := m.now()
+	if err := m.renewMemberLease(ctx, now); err != nil {
+		m.log.Error(err, "failed to renew member lease")
+	}
+	members, err := m.listMembers(ctx, now)
+	if err != nil {
+		m.log.Error(err, "failed to list members")
+		m.updateOwnedShards(m.validShards(now))
+		m.releaseDrainedShards(ctx, now)
+		return
+	}
+	desired := desiredShards(m.identity, members, m.cfg.ShardCount)
+
+	for shard := 0; shard < m.cfg.ShardCount; shard++ {
+		if desired.Has(shard) {
+			m.draining.Delete(shard)
+			acquired, err := m.acquireShardLease(ctx, shard, now)
+			if err != nil {
+				m.log.Error(err, "failed to acquire shard lease", "shard", shard)
+				continue
+			}
+			if acquired {
+				m.renewedAt[shard] = now
+			} else {
+				delete(m.renewedAt, shard)
+			}
+		} else if _, ok := m.renewedAt[shard]; ok {
+			delete(m.renewedAt, shard)
+			m.draining.Insert(shard)
+		}
+	}
+	// shards must be dropped before their leases are released.
+	m.updateOwnedShards(m.validShards(now))
+	m.releaseDrainedShards(ctx, now)
+}
+
+// releaseDrainedShards releases leases of draining shards once no requests of their meshes are in flight.
+// leases of shards with requests in flight are renewed meanwhile, so that other replicas don't reconcile their meshes concurrently.
+func (m *defaultManager) releaseDrainedShards(ctx context.Context, now time.Time) {
+	inFlightShards := m.inFlightShards()
+	for _, shard := range m.draining.List() {
+		if inFlightShards.Has(shard) {
+			m.log.V(1).Info("waiting for in-flight requests before releasing shard", "shard", shard)
+			acquired, err := m.acquireShardLease(ctx, shard, now)
+			if err != nil {
+				m.log.Error(err, "failed to renew lease of draining shard", "shard", shard)
+			} else if !acquired {
+				m.draining.Delete(shard)
+			}
+			continue
+		}
+		m.draining.Delete(shard)
+		if err := m.releaseShardLease(ctx, shard); err != nil {
+			m.log.Error(err, "failed to release shard lease", "shard", shard)
+		}
+	}
+}
+
+// inFlightShards returns shards of meshes with requests being reconciled.
+func (m *defaultManager) inFlightShards() sets.Int {
+	m.mutex.RLock()
+	inFlightMeshesFuncs := m.inFlightMeshesFuncs
+	m.mutex.RUnlock()
+	shards := sets.NewInt()
+	for _, inFlightMeshes := range inFlightMeshesFuncs {
+		for _, meshName := range inFlightMeshes() {
+			shards.Insert(ShardOf(meshName, m.cfg.ShardCount))
+		}
+	}
+	return shards
+}
+
+// validShards returns shards whose leases are still safely held, with one renew interval of margin before their expiry.
+func (m *defaultManager) validShards(now time.Time) sets.Int {
+	shards := sets.NewInt()
+	for shard, renewedAt := range m.renewedAt {
+		if now.Sub(renewedAt) < m.cfg.LeaseDuration-m.cfg.RenewInterval {
+			shards.Insert(shard)
+		}
+	}
+	return shards
+}
+
+// updateOwnedShards updates shards owned by this replica, and notifies handlers if shards are acquired or dropped.
+func (m *defaultManager) updateOwnedShards(shards sets.Int) {
+	m.mutex.Lock()
+	acquired := shards.Difference(m.ownedShards)
+	dropped := m.ownedShards.Difference(shards)
+	m.ownedShards = shards
+	handlers := m.handlers
+	m.mutex.Unlock()
+
+	if dropped.Len() > 0 {
+		m.log.Info("dropped shards", "shards", dropped.List())
+	}
+	if acquired.Len() > 0 {
+		m.log.Info("acquired shards", "shards", acquired.List())
+	}
+	if acquired.Len() > 0 || dropped.Len() > 0 {
+		for _, handler := range handlers {
+			handler()
+		}
+	}
+}
+
+// releaseAll gives up all shards and membership of this replica, so that other replicas take over without waiting for expiry.
+func (m *defaultManager) releaseAll(ctx context.Context) {
+	shards := m.draining.List()
+	for shard := range m.renewedAt {
+		shards = append(shards, shard)
+	}
+	m.renewedAt = make(map[int]time.Time)
+	m.draining = sets.NewInt()
+	m.updateOwnedShards(sets.NewInt())
+	for _, shard := range shards {
+		if err := m.releaseShardLease(ctx, shard); err != nil {
+			m.log.Error(err, "failed to release shard lease", "shard", shard)
+		}
+	}
+	memberLease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: m.cfg.LeaseNamespace, Name: memberLeaseNamePrefix + m.identity}}
+	if err := m.k8sClient.Delete(ctx, memberLease); client.IgnoreNotFound(err) != nil {
+		m.log.Error(err, "failed to delete member lease")
+	}
+}
+
+// renewMemberLease creates or renews the member lease of this replica.
+func (m *defaultManager) renewMemberLease(ctx context.Context, now time.Time) error {
+	lease := &coordinationv1.Lease{}
+	key := client.ObjectKey{Namespace: m.cfg.LeaseNamespace, Name: memberLeaseNamePrefix + m.identity}
+	if err := m.apiReader.Get(ctx, key, lease); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels:    map[string]string{labelShardMember: "true"},
+			},
+			Spec: m.heldLeaseSpec(now, now, 0),
+		}
+		return m.k8sClient.Create(ctx, lease)
+	}
+	lease.Spec = m.heldLeaseSpec(now, now, 0)
+	return m.k8sClient.Update(ctx, lease)
+}
+
+// listMembers returns identities of live members in order, including this replica.
+// member leases that have expired long ago are deleted.
+func (m *defaultManager) listMembers(ctx context.Context, now time.Time) ([]string, error) {
+	leaseList := &coordinationv1.LeaseList{}
+	if err := m.apiReader.List(ctx, leaseList, client.InNamespace(m.cfg.LeaseNamespace), client.MatchingLabels{labelShardMember: "true"}); err != nil {
+		return nil, err
+	}
+	members := sets.NewString(m.identity)
+	for i := range leaseList.Items {
+		lease := &leaseList.Items[i]
+		identity := aws.StringValue(lease.Spec.HolderIdentity)
+		if identity == "" {
+			continue
+		}
+		expiry := leaseExpiry(lease)
+		if now.Before(expiry) {
+			members.Insert(identity)
+			continue
+		}
+		if now.Sub(expiry) > memberLeaseGCFactor*m.cfg.LeaseDuration {
+			if err := m.k8sClient.Delete(ctx, lease); client.IgnoreNotFound(err) != nil {
+				m.log.Error(err, "failed to delete expired member lease", "lease", lease.Name)
+			}
+		}
+	}
+	memberList := members.List()
+	sort.Strings(memberList)
+	return memberList, nil
+}
+
+// acquireShardLease acquires or renews the lease of shard, returns whether it's held by this replica.
+// A lease held by another replica can only be acquired after it's released or expired.
+func (m *defaultManager) acquireShardLease(ctx context.Context, shard int, now time.Time) (bool, error) {
+	lease := &coordinationv1.Lease{}
+	key := client.ObjectKey{Namespace: m.cfg.LeaseNamespace, Name: shardLeaseName(shard)}
+	if err := m.apiReader.Get(ctx, key, lease); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Spec:       m.heldLeaseSpec(now, now, 0),
+		}
+		if err := m.k8sClient.Create(ctx, lease); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	holder := aws.StringValue(lease.Spec.HolderIdentity)
+	switch {
+	case holder == m.identity:
+		acquireTime := now
+		if lease.Spec.AcquireTime != nil {
+			acquireTime = lease.Spec.AcquireTime.Time
+		}
+		lease.Spec = m.heldLeaseSpec(acquireTime, now, int32Value(lease.Spec.LeaseTransitions))
+	case holder == "" || !now.Before(leaseExpiry(lease)):
+		lease.Spec = m.heldLeaseSpec(now, now, int32Value(lease.Spec.LeaseTransitions)+1)
+	default:
+		return false, nil
+	}
+	// updates are rejected with conflict if another replica changed the lease concurrently.
+	if err := m.k8sClient.Update(ctx, lease); err != nil {
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// releaseShardLease releases the lease of shard if it's held by this replica.
+func (m *defaultManager) releaseShardLease(ctx context.Context, shard int) error {
+	lease := &coordinationv1.Lease{}
+	key := client.ObjectKey{Namespace: m.cfg.LeaseNamespace, Name: shardLeaseName(shard)}
+	if err := m.apiReader.Get(ctx, key, lease); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if aws.StringValue(lease.Spec.HolderIdentity) != m.identity {
+		return nil
+	}
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.RenewTime = nil
+	return m.k8sClient.Update(ctx, lease)
+}
+
+func (m *defaultManager) heldLeaseSpec(acquireTime time.Time, renewTime time.Time, transitions int32) coordinationv1.LeaseSpec {
+	return coordinationv1.LeaseSpec{
+		HolderIdentity:       aws.String(m.identity),
+		LeaseDurationSeconds: int32Ptr(int32(m.cfg.LeaseDuration.Seconds())),
+		AcquireTime:          &metav1.MicroTime{Time: acquireTime},
+		RenewTime:            &metav1.MicroTime{Time: renewTime},
+		LeaseTransitions:     int32Ptr(transitions),
+	}
+}
+
+// leaseExpiry returns when lease expires without renewal.
+func leaseExpiry(lease *coordinationv1.Lease) time.Time {
+	if lease.Spec.RenewTime == nil {
+		return time.Time{}
+	}
+	return lease.Spec.RenewTime.Add(time.Duration(int32Value(lease.Spec.LeaseDurationSeconds)) * time.Second)
+}
+
+func shardLeaseName(shard int) string {
+	return fmt.Sprintf("%s%d", shardLeaseNamePrefix, shard)
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func int32Value(value *int32) int32 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -0,0 +1,181 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestManager(k8sClient client.Client, identity string, now *time.Time) *defaultManager {
+	cfg := Config{
+		ShardCount:     8,
+		LeaseNamespace: "appmesh-system",
+		LeaseDuration:  15 * time.Second,
+		RenewInterval:  5 * time.Second,
+	}
+	m := NewDefaultManager(cfg, identity, k8sClient, k8sClient, log.FromContext(context.Background()))
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+func ownedShards(m *defaultManager) sets.Int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return sets.NewInt(m.ownedShards.List()...)
+}
+
+func Test_defaultManager_sync(t *testing.T) {
+	ctx := context.Background()
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+	now := time.Now()
+	m0 := newTestManager(k8sClient, "controller-0", &now)
+	m1 := newTestManager(k8sClient, "controller-1", &now)
+	m1OwnershipChanges := 0
+	m1.AddOwnershipChangeHandler(func() { m1OwnershipChanges++ })
+
+	// the only member owns all shards.
+	m0.sync(ctx)
+	assert.Equal(t, 8, ownedShards(m0).Len())
+	assert.True(t, m0.OwnsMesh("my-mesh"))
+
+	// a joining member waits for shards to be released by their holders.
+	now = now.Add(time.Second)
+	m1.sync(ctx)
+	assert.Equal(t, 0, ownedShards(m1).Len())
+	assert.Equal(t, 0, m1OwnershipChanges)
+
+	now = now.Add(time.Second)
+	m0.sync(ctx)
+	m1.sync(ctx)
+	m0Shards, m1Shards := ownedShards(m0), ownedShards(m1)
+	assert.Equal(t, desiredShards("controller-0", []string{"controller-0", "controller-1"}, 8), m0Shards)
+	assert.Equal(t, desiredShards("controller-1", []string{"controller-0", "controller-1"}, 8), m1Shards)
+	assert.Equal(t, 8, m0Shards.Union(m1Shards).Len())
+	assert.Empty(t, m0Shards.Intersection(m1Shards).List())
+	assert.Equal(t, 1, m1OwnershipChanges)
+	assert.NotEqual(t, m0.OwnsMesh("my-mesh"), m1.OwnsMesh("my-mesh"))
+
+	// shards of a member that stops renewing are taken over after its leases expire.
+	now = now.Add(10 * time.Second)
+	m0.sync(ctx)
+	assert.Equal(t, m0Shards, ownedShards(m0))
+	now = now.Add(10 * time.Second)
+	m0.sync(ctx)
+	assert.Equal(t, 8, ownedShards(m0).Len())
+
+	// leases are released on shutdown.
+	m0.releaseAll(ctx)
+	assert.Equal(t, 0, ownedShards(m0).Len())
+	leaseList := &coordinationv1.LeaseList{}
+	assert.NoError(t, k8sClient.List(ctx, leaseList, client.InNamespace("appmesh-system")))
+	for _, lease := range leaseList.Items {
+		assert.NotEqual(t, "controller-0", aws.StringValue(lease.Spec.HolderIdentity), "lease %v", lease.Name)
+	}
+	assert.False(t, m0.OwnsMesh("my-mesh"))
+}
+
+func Test_defaultManager_syncDrainsInFlightMeshes(t *testing.T) {
+	ctx := context.Background()
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+	now := time.Now()
+	m0 := newTestManager(k8sClient, "controller-0", &now)
+	m1 := newTestManager(k8sClient, "controller-1", &now)
+	m0OwnershipChanges := 0
+	m0.AddOwnershipChangeHandler(func() { m0OwnershipChanges++ })
+
+	// a mesh whose shard moves to controller-1 once it joins.
+	m1Desired := desiredShards("controller-1", []string{"controller-0", "controller-1"}, 8)
+	meshName := ""
+	for i := 0; meshName == ""; i++ {
+		if candidate := fmt.Sprintf("mesh-%d", i); m1Desired.Has(ShardOf(candidate, 8)) {
+			meshName = candidate
+		}
+	}
+	var inFlightMeshes []string
+	m0.AddInFlightMeshesFunc(func() []string { return inFlightMeshes })
+
+	m0.sync(ctx)
+	assert.True(t, m0.OwnsMesh(meshName))
+	assert.Equal(t, 1, m0OwnershipChanges)
+	now = now.Add(time.Second)
+	m1.sync(ctx)
+
+	// the shard is dropped, but its lease is held while requests of its meshes are in flight.
+	inFlightMeshes = []string{meshName}
+	now = now.Add(time.Second)
+	m0.sync(ctx)
+	assert.False(t, m0.OwnsMesh(meshName))
+	assert.Equal(t, 2, m0OwnershipChanges)
+	m1.sync(ctx)
+	assert.False(t, m1.OwnsMesh(meshName))
+
+	// the lease of draining shard is renewed until requests are done.
+	now = now.Add(20 * time.Second)
+	m0.sync(ctx)
+	m1.sync(ctx)
+	assert.False(t, m1.OwnsMesh(meshName))
+
+	inFlightMeshes = nil
+	now = now.Add(time.Second)
+	m0.sync(ctx)
+	m1.sync(ctx)
+	assert.True(t, m1.OwnsMesh(meshName))
+	assert.False(t, m0.OwnsMesh(meshName))
+}
+
+func Test_defaultManager_validShards(t *testing.T) {
+	now := time.Now()
+	m := newTestManager(nil, "controller-0", &now)
+	m.renewedAt = map[int]time.Time{
+		0: now,
+		1: now.Add(-9 * time.Second),
+		2: now.Add(-10 * time.Second),
+		3: now.Add(-time.Minute),
+	}
+	assert.Equal(t, sets.NewInt(0, 1), m.validShards(now))
+}
+
+func Test_defaultManager_listMembers(t *testing.T) {
+	ctx := context.Background()
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).Build()
+	now := time.Now()
+	m := newTestManager(k8sClient, "controller-0", &now)
+	for identity, renewedAgo := range map[string]time.Duration{
+		"controller-1": time.Second,
+		"controller-2": time.Minute,
+		"controller-3": time.Hour,
+	} {
+		peer := newTestManager(k8sClient, identity, &now)
+		assert.NoError(t, peer.renewMemberLease(ctx, now.Add(-renewedAgo)))
+	}
+
+	members, err := m.listMembers(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"controller-0", "controller-1"}, members)
+
+	// member leases expired for long are deleted.
+	leaseList := &coordinationv1.LeaseList{}
+	assert.NoError(t, k8sClient.List(ctx, leaseList, client.InNamespace("appmesh-system")))
+	var leaseNames []string
+	for _, lease := range leaseList.Items {
+		leaseNames = append(leaseNames, lease.Name)
+	}
+	assert.ElementsMatch(t, []string{"appmesh-controller-member-controller-1", "appmesh-controller-member-controller-2"}, leaseNames)
+}
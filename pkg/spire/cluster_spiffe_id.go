@@ -0,0 +1,132 @@
+package spire
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// LabelOwnerKind is the kind of the object a ClusterSPIFFEID is managed for.
+	LabelOwnerKind = "appmesh.k8s.aws/owner-kind"
+	// LabelOwnerUID is the UID of the object a ClusterSPIFFEID is managed for.
+	LabelOwnerUID = "appmesh.k8s.aws/owner-uid"
+	// AnnotationOwner is the namespaced name of the object a ClusterSPIFFEID is managed for.
+	AnnotationOwner = "appmesh.k8s.aws/owner"
+
+	OwnerKindVirtualNode    = "VirtualNode"
+	OwnerKindVirtualGateway = "VirtualGateway"
+
+	// maxNamePrefixLength bounds the readable part of ClusterSPIFFEID name, so that name with hash suffix stays within 253 characters.
+	maxNamePrefixLength = 240
+)
+
+// ClusterSPIFFEIDGVK is the GroupVersionKind of SPIRE ClusterSPIFFEID.
+var ClusterSPIFFEIDGVK = schema.GroupVersionKind{
+	Group:   "spire.spiffe.io",
+	Version: "v1alpha1",
+	Kind:    "ClusterSPIFFEID",
+}
+
+// NewClusterSPIFFEID returns an empty SPIRE ClusterSPIFFEID object.
+func NewClusterSPIFFEID() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ClusterSPIFFEIDGVK)
+	return obj
+}
+
+// NewClusterSPIFFEIDList returns an empty SPIRE ClusterSPIFFEID list.
+func NewClusterSPIFFEIDList() *unstructured.UnstructuredList {
+	objList := &unstructured.UnstructuredList{}
+	objList.SetGroupVersionKind(ClusterSPIFFEIDGVK.GroupVersion().WithKind(ClusterSPIFFEIDGVK.Kind + "List"))
+	return objList
+}
+
+// VirtualNodeSPIFFEIDs returns the SPIFFE IDs of SDS certificates used by vn, sorted.
+func VirtualNodeSPIFFEIDs(vn *appmesh.VirtualNode) []string {
+	spiffeIDs := sets.NewString()
+	for _, listener := range vn.Spec.Listeners {
+		if listener.TLS != nil && listener.TLS.Certificate.SDS != nil {
+			spiffeIDs.Insert(aws.StringValue(listener.TLS.Certificate.SDS.SecretName))
+		}
+	}
+	if vn.Spec.BackendDefaults != nil {
+		insertClientPolicySPIFFEID(spiffeIDs, vn.Spec.BackendDefaults.ClientPolicy)
+	}
+	for _, backend := range vn.Spec.Backends {
+		insertClientPolicySPIFFEID(spiffeIDs, backend.VirtualService.ClientPolicy)
+	}
+	spiffeIDs.Delete("")
+	return spiffeIDs.List()
+}
+
+// VirtualGatewaySPIFFEIDs returns the SPIFFE IDs of SDS certificates used by vg, sorted.
+func VirtualGatewaySPIFFEIDs(vg *appmesh.VirtualGateway) []string {
+	spiffeIDs := sets.NewString()
+	for _, listener := range vg.Spec.Listeners {
+		if listener.TLS != nil && listener.TLS.Certificate.SDS != nil {
+			spiffeIDs.Insert(aws.StringValue(listener.TLS.Certificate.SDS.SecretName))
+		}
+	}
+	if vg.Spec.BackendDefaults != nil && vg.Spec.BackendDefaults.ClientPolicy != nil {
+		tls := vg.Spec.BackendDefaults.ClientPolicy.TLS
+		if tls != nil && tls.Certificate != nil && tls.Certificate.SDS != nil {
+			spiffeIDs.Insert(aws.StringValue(tls.Certificate.SDS.SecretName))
+		}
+	}
+	spiffeIDs.Delete("")
+	return spiffeIDs.List()
+}
+
+func insertClientPolicySPIFFEID(spiffeIDs sets.String, clientPolicy *appmesh.ClientPolicy) {
+	if clientPolicy == nil || clientPolicy.TLS == nil || clientPolicy.TLS.Certificate == nil || clientPolicy.TLS.Certificate.SDS == nil {
+		return
+	}
+	spiffeIDs.Insert(aws.StringValue(clientPolicy.TLS.Certificate.SDS.SecretName))
+}
+
+// clusterSPIFFEIDName returns the name of ClusterSPIFFEID that registers spiffeID for owner.
+// ClusterSPIFFEID is cluster scoped, so the name is prefixed by owner's namespace and name, and suffixed by a hash of owner and spiffeID.
+func clusterSPIFFEIDName(ownerKind string, owner metav1.Object, spiffeID string) string {
+	prefix := fmt.Sprintf("appmesh-%s-%s", owner.GetNamespace(), owner.GetName())
+	if len(prefix) > maxNamePrefixLength {
+		prefix = prefix[:maxNamePrefixLength]
+	}
+	prefix = strings.TrimRight(prefix, ".-")
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", ownerKind, owner.GetUID(), spiffeID)))
+	return prefix + "-" + hex.EncodeToString(hash[:])[:8]
+}
+
+// ownerLabels returns the labels that identify ClusterSPIFFEIDs managed for owner.
+func ownerLabels(ownerKind string, owner metav1.Object) map[string]string {
+	return map[string]string{
+		LabelOwnerKind: ownerKind,
+		LabelOwnerUID:  string(owner.GetUID()),
+	}
+}
+
+// OwnerRequestsForClusterSPIFFEID returns a map function that maps a ClusterSPIFFEID to the reconcile request of its owner of ownerKind.
+func OwnerRequestsForClusterSPIFFEID(ownerKind string) func(ctx context.Context, obj client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		if obj.GetLabels()[LabelOwnerKind] != ownerKind {
+			return nil
+		}
+		namespace, name, found := strings.Cut(obj.GetAnnotations()[AnnotationOwner], "/")
+		if !found {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+	}
+}
@@ -0,0 +1,210 @@
+package spire
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func sdsClientPolicy(spiffeID string) *appmesh.ClientPolicy {
+	return &appmesh.ClientPolicy{
+		TLS: &appmesh.ClientPolicyTLS{
+			Certificate: &appmesh.ClientTLSCertificate{
+				SDS: &appmesh.ListenerTLSSDSCertificate{SecretName: aws.String(spiffeID)},
+			},
+		},
+	}
+}
+
+func TestVirtualNodeSPIFFEIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		vn   *appmesh.VirtualNode
+		want []string
+	}{
+		{
+			name: "virtualNode without TLS",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: []appmesh.Listener{{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"}}},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "virtualNode with file certificate",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: []appmesh.Listener{
+						{
+							PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"},
+							TLS: &appmesh.ListenerTLS{
+								Certificate: appmesh.ListenerTLSCertificate{
+									File: &appmesh.ListenerTLSFileCertificate{CertificateChain: "/certs/tls.crt", PrivateKey: "/certs/tls.key"},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "virtualNode with SDS certificates on listener, backendDefaults and backends",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: []appmesh.Listener{
+						{
+							PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"},
+							TLS: &appmesh.ListenerTLS{
+								Certificate: appmesh.ListenerTLSCertificate{
+									SDS: &appmesh.ListenerTLSSDSCertificate{SecretName: aws.String("spiffe://mesh.local/app")},
+								},
+							},
+						},
+					},
+					BackendDefaults: &appmesh.BackendDefaults{ClientPolicy: sdsClientPolicy("spiffe://mesh.local/app")},
+					Backends: []appmesh.Backend{
+						{
+							VirtualService: appmesh.VirtualServiceBackend{
+								VirtualServiceRef: &appmesh.VirtualServiceReference{Name: "vs-1"},
+								ClientPolicy:      sdsClientPolicy("spiffe://mesh.local/client"),
+							},
+						},
+					},
+				},
+			},
+			want: []string{"spiffe://mesh.local/app", "spiffe://mesh.local/client"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VirtualNodeSPIFFEIDs(tt.vn)
+			assert.Equal(t, tt.want, nilIfEmpty(got))
+		})
+	}
+}
+
+func TestVirtualGatewaySPIFFEIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		vg   *appmesh.VirtualGateway
+		want []string
+	}{
+		{
+			name: "virtualGateway without TLS",
+			vg:   &appmesh.VirtualGateway{},
+			want: nil,
+		},
+		{
+			name: "virtualGateway with SDS certificates on listener and backendDefaults",
+			vg: &appmesh.VirtualGateway{
+				Spec: appmesh.VirtualGatewaySpec{
+					Listeners: []appmesh.VirtualGatewayListener{
+						{
+							PortMapping: appmesh.VirtualGatewayPortMapping{Port: 8443, Protocol: "http"},
+							TLS: &appmesh.VirtualGatewayListenerTLS{
+								Certificate: appmesh.VirtualGatewayListenerTLSCertificate{
+									SDS: &appmesh.VirtualGatewayListenerTLSSDSCertificate{SecretName: aws.String("spiffe://mesh.local/gateway")},
+								},
+							},
+						},
+					},
+					BackendDefaults: &appmesh.VirtualGatewayBackendDefaults{
+						ClientPolicy: &appmesh.VirtualGatewayClientPolicy{
+							TLS: &appmesh.VirtualGatewayClientPolicyTLS{
+								Certificate: &appmesh.VirtualGatewayClientTLSCertificate{
+									SDS: &appmesh.VirtualGatewayListenerTLSSDSCertificate{SecretName: aws.String("spiffe://mesh.local/gateway-client")},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: []string{"spiffe://mesh.local/gateway", "spiffe://mesh.local/gateway-client"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VirtualGatewaySPIFFEIDs(tt.vg)
+			assert.Equal(t, tt.want, nilIfEmpty(got))
+		})
+	}
+}
+
+func Test_clusterSPIFFEIDName(t *testing.T) {
+	vn := &appmesh.VirtualNode{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1", UID: "uid-1"}}
+	longVN := &appmesh.VirtualNode{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: strings.Repeat("a", 250), UID: "uid-2"}}
+
+	name := clusterSPIFFEIDName(OwnerKindVirtualNode, vn, "spiffe://mesh.local/app")
+	assert.True(t, strings.HasPrefix(name, "appmesh-ns-1-vn-1-"))
+	assert.Equal(t, name, clusterSPIFFEIDName(OwnerKindVirtualNode, vn, "spiffe://mesh.local/app"))
+	assert.NotEqual(t, name, clusterSPIFFEIDName(OwnerKindVirtualNode, vn, "spiffe://mesh.local/other"))
+	assert.NotEqual(t, name, clusterSPIFFEIDName(OwnerKindVirtualGateway, vn, "spiffe://mesh.local/app"))
+
+	longName := clusterSPIFFEIDName(OwnerKindVirtualNode, longVN, "spiffe://mesh.local/app")
+	assert.LessOrEqual(t, len(longName), 253)
+}
+
+func TestOwnerRequestsForClusterSPIFFEID(t *testing.T) {
+	tests := []struct {
+		name      string
+		ownerKind string
+		obj       client.Object
+		want      []reconcile.Request
+	}{
+		{
+			name:      "ClusterSPIFFEID managed for virtualNode",
+			ownerKind: OwnerKindVirtualNode,
+			obj: &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "appmesh-ns-1-vn-1-abcdef01",
+					Labels:      map[string]string{LabelOwnerKind: OwnerKindVirtualNode, LabelOwnerUID: "uid-1"},
+					Annotations: map[string]string{AnnotationOwner: "ns-1/vn-1"},
+				},
+			},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "ns-1", Name: "vn-1"}}},
+		},
+		{
+			name:      "ClusterSPIFFEID managed for virtualGateway",
+			ownerKind: OwnerKindVirtualNode,
+			obj: &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "appmesh-ns-1-vg-1-abcdef01",
+					Labels:      map[string]string{LabelOwnerKind: OwnerKindVirtualGateway, LabelOwnerUID: "uid-1"},
+					Annotations: map[string]string{AnnotationOwner: "ns-1/vg-1"},
+				},
+			},
+			want: nil,
+		},
+		{
+			name:      "ClusterSPIFFEID not managed by controller",
+			ownerKind: OwnerKindVirtualNode,
+			obj: &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OwnerRequestsForClusterSPIFFEID(tt.ownerKind)(context.Background(), tt.obj)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func nilIfEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+package spire
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagEnableSPIRERegistration = "enable-spire-registration"
+	flagSPIREClassName          = "spire-class-name"
+)
+
+type Config struct {
+	// If enabled, SPIRE ClusterSPIFFEIDs will be managed for VirtualNodes and VirtualGateways using SDS certificates.
+	EnableRegistration bool
+	// The className of managed ClusterSPIFFEIDs, which designates the SPIRE controller manager that processes them.
+	ClassName string
+}
+
+func (cfg *Config) BindFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&cfg.EnableRegistration, flagEnableSPIRERegistration, false,
+		"If enabled, SPIRE ClusterSPIFFEIDs will be managed for VirtualNodes and VirtualGateways using SDS certificates")
+	fs.StringVar(&cfg.ClassName, flagSPIREClassName, "",
+		"The className of managed ClusterSPIFFEIDs. If empty, they're processed by the default SPIRE controller manager")
+}
+
+func (cfg *Config) BindEnv() error {
+	return nil
+}
+
+func (cfg *Config) Validate() error {
+	return nil
+}
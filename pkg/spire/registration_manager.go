@@ -0,0 +1,309 @@
+package spire
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualgateway"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reasonRegistered          = "Registered"
+	reasonRegistrationPending = "RegistrationPending"
+	reasonRegistrationFailed  = "RegistrationFailed"
+	reasonNotRequired         = "NotRequired"
+)
+
+// RegistrationManager is dedicated to manage SPIRE registrations for VirtualNodes and VirtualGateways.
+type RegistrationManager interface {
+	// ReconcileVirtualNode will register the SPIFFE IDs of SDS certificates used by vn for pods of vn,
+	// and report the registration status as SPIRERegistered condition of vn.
+	ReconcileVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error
+
+	// CleanupVirtualNode will deregister all SPIFFE IDs registered for vn.
+	CleanupVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error
+
+	// ReconcileVirtualGateway will register the SPIFFE IDs of SDS certificates used by vg for pods of vg,
+	// and report the registration status as SPIRERegistered condition of vg.
+	ReconcileVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error
+
+	// CleanupVirtualGateway will deregister all SPIFFE IDs registered for vg.
+	CleanupVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error
+}
+
+func NewDefaultRegistrationManager(k8sClient client.Client, cfg Config, log logr.Logger) RegistrationManager {
+	return &defaultRegistrationManager{
+		k8sClient: k8sClient,
+		cfg:       cfg,
+		log:       log,
+	}
+}
+
+type defaultRegistrationManager struct {
+	k8sClient client.Client
+	cfg       Config
+	log       logr.Logger
+}
+
+// registrationStatus is the aggregated status of ClusterSPIFFEIDs managed for an object.
+type registrationStatus struct {
+	status  corev1.ConditionStatus
+	reason  string
+	message string
+}
+
+// +kubebuilder:rbac:groups=spire.spiffe.io,resources=clusterspiffeids,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualnodes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=appmesh.k8s.aws,resources=virtualgateways/status,verbs=get;update;patch
+
+func (m *defaultRegistrationManager) ReconcileVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error {
+	regStatus, err := m.reconcileClusterSPIFFEIDs(ctx, OwnerKindVirtualNode, vn, vn.Spec.PodSelector, VirtualNodeSPIFFEIDs(vn))
+	if err != nil {
+		return err
+	}
+	return m.updateVirtualNodeStatus(ctx, vn, regStatus)
+}
+
+func (m *defaultRegistrationManager) CleanupVirtualNode(ctx context.Context, vn *appmesh.VirtualNode) error {
+	return m.deleteClusterSPIFFEIDs(ctx, OwnerKindVirtualNode, vn, nil)
+}
+
+func (m *defaultRegistrationManager) ReconcileVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	regStatus, err := m.reconcileClusterSPIFFEIDs(ctx, OwnerKindVirtualGateway, vg, vg.Spec.PodSelector, VirtualGatewaySPIFFEIDs(vg))
+	if err != nil {
+		return err
+	}
+	return m.updateVirtualGatewayStatus(ctx, vg, regStatus)
+}
+
+func (m *defaultRegistrationManager) CleanupVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	return m.deleteClusterSPIFFEIDs(ctx, OwnerKindVirtualGateway, vg, nil)
+}
+
+// reconcileClusterSPIFFEIDs creates or updates a ClusterSPIFFEID per spiffeID for pods selected by podSelector, and deletes stale ones.
+// It returns nil status if there is nothing to register.
+func (m *defaultRegistrationManager) reconcileClusterSPIFFEIDs(ctx context.Context, ownerKind string, owner client.Object,
+	podSelector *metav1.LabelSelector, spiffeIDs []string) (*registrationStatus, error) {
+	// without podSelector, there are no pods to register.
+	if podSelector == nil {
+		spiffeIDs = nil
+	}
+	var desiredObjs []*unstructured.Unstructured
+	for _, spiffeID := range spiffeIDs {
+		desiredObj, err := m.buildClusterSPIFFEID(ownerKind, owner, podSelector, spiffeID)
+		if err != nil {
+			return nil, err
+		}
+		desiredObjs = append(desiredObjs, desiredObj)
+	}
+
+	desiredNames := make(map[string]struct{}, len(desiredObjs))
+	var objs []*unstructured.Unstructured
+	for _, desiredObj := range desiredObjs {
+		obj, err := m.createOrUpdateClusterSPIFFEID(ctx, owner, desiredObj)
+		if err != nil {
+			return nil, err
+		}
+		desiredNames[obj.GetName()] = struct{}{}
+		objs = append(objs, obj)
+	}
+	if err := m.deleteClusterSPIFFEIDs(ctx, ownerKind, owner, desiredNames); err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, nil
+	}
+	return aggregateRegistrationStatus(objs), nil
+}
+
+func (m *defaultRegistrationManager) createOrUpdateClusterSPIFFEID(ctx context.Context, owner client.Object, desiredObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	obj := NewClusterSPIFFEID()
+	if err := m.k8sClient.Get(ctx, types.NamespacedName{Name: desiredObj.GetName()}, obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err := m.k8sClient.Create(ctx, desiredObj); err != nil {
+			return nil, err
+		}
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("created ClusterSPIFFEID",
+			"owner", k8s.NamespacedName(owner),
+			"clusterSPIFFEID", desiredObj.GetName())
+		return desiredObj, nil
+	}
+	if obj.GetLabels()[LabelOwnerUID] != string(owner.GetUID()) {
+		return nil, errors.Errorf("ClusterSPIFFEID %v already exists and isn't managed by %v",
+			obj.GetName(), k8s.NamespacedName(owner))
+	}
+	if equality.Semantic.DeepEqual(obj.Object["spec"], desiredObj.Object["spec"]) {
+		return obj, nil
+	}
+	obj.Object["spec"] = desiredObj.Object["spec"]
+	if err := m.k8sClient.Update(ctx, obj); err != nil {
+		return nil, err
+	}
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("updated ClusterSPIFFEID",
+		"owner", k8s.NamespacedName(owner),
+		"clusterSPIFFEID", obj.GetName())
+	return obj, nil
+}
+
+// deleteClusterSPIFFEIDs deletes ClusterSPIFFEIDs managed for owner, except the ones in retainedNames.
+func (m *defaultRegistrationManager) deleteClusterSPIFFEIDs(ctx context.Context, ownerKind string, owner client.Object, retainedNames map[string]struct{}) error {
+	objList := NewClusterSPIFFEIDList()
+	if err := m.k8sClient.List(ctx, objList, client.MatchingLabels(ownerLabels(ownerKind, owner))); err != nil {
+		// SPIRE CRDs may not be installed when no object uses SDS certificates.
+		if meta.IsNoMatchError(err) && len(retainedNames) == 0 {
+			return nil
+		}
+		return err
+	}
+	for i := range objList.Items {
+		obj := &objList.Items[i]
+		if _, ok := retainedNames[obj.GetName()]; ok {
+			continue
+		}
+		if err := m.k8sClient.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("deleted ClusterSPIFFEID",
+			"owner", k8s.NamespacedName(owner),
+			"clusterSPIFFEID", obj.GetName())
+	}
+	return nil
+}
+
+func (m *defaultRegistrationManager) buildClusterSPIFFEID(ownerKind string, owner client.Object, podSelector *metav1.LabelSelector, spiffeID string) (*unstructured.Unstructured, error) {
+	podSelectorObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(podSelector)
+	if err != nil {
+		return nil, err
+	}
+	spec := map[string]interface{}{
+		"spiffeIDTemplate": spiffeID,
+		"podSelector":      podSelectorObj,
+		"namespaceSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				corev1.LabelMetadataName: owner.GetNamespace(),
+			},
+		},
+	}
+	if m.cfg.ClassName != "" {
+		spec["className"] = m.cfg.ClassName
+	}
+
+	obj := NewClusterSPIFFEID()
+	obj.SetName(clusterSPIFFEIDName(ownerKind, owner, spiffeID))
+	obj.SetLabels(ownerLabels(ownerKind, owner))
+	obj.SetAnnotations(map[string]string{
+		AnnotationOwner: fmt.Sprintf("%s/%s", owner.GetNamespace(), owner.GetName()),
+	})
+	obj.Object["spec"] = spec
+	return obj, nil
+}
+
+// aggregateRegistrationStatus aggregates the status reported by SPIRE controller manager on objs.
+func aggregateRegistrationStatus(objs []*unstructured.Unstructured) *registrationStatus {
+	var pendingNames []string
+	var failures []string
+	for _, obj := range objs {
+		stats, found, _ := unstructured.NestedMap(obj.Object, "status", "stats")
+		if !found {
+			pendingNames = append(pendingNames, obj.GetName())
+			continue
+		}
+		spiffeID, _, _ := unstructured.NestedString(obj.Object, "spec", "spiffeIDTemplate")
+		entryFailures, _, _ := unstructured.NestedInt64(stats, "entryFailures")
+		renderFailures, _, _ := unstructured.NestedInt64(stats, "podEntryRenderFailures")
+		if entryFailures > 0 || renderFailures > 0 {
+			failures = append(failures, fmt.Sprintf("%s: %d entry failures, %d pod entry render failures",
+				spiffeID, entryFailures, renderFailures))
+		}
+	}
+	if len(failures) != 0 {
+		return &registrationStatus{
+			status:  corev1.ConditionFalse,
+			reason:  reasonRegistrationFailed,
+			message: strings.Join(failures, "; "),
+		}
+	}
+	if len(pendingNames) != 0 {
+		return &registrationStatus{
+			status:  corev1.ConditionUnknown,
+			reason:  reasonRegistrationPending,
+			message: fmt.Sprintf("waiting for SPIRE to process ClusterSPIFFEIDs: %s", strings.Join(pendingNames, ", ")),
+		}
+	}
+	return &registrationStatus{
+		status: corev1.ConditionTrue,
+		reason: reasonRegistered,
+	}
+}
+
+func (m *defaultRegistrationManager) updateVirtualNodeStatus(ctx context.Context, vn *appmesh.VirtualNode, regStatus *registrationStatus) error {
+	if regStatus == nil {
+		if !hasVirtualNodeCondition(vn, appmesh.VirtualNodeSPIRERegistered) {
+			return nil
+		}
+		regStatus = &registrationStatus{status: corev1.ConditionFalse, reason: reasonNotRequired}
+	}
+	oldVN := vn.DeepCopy()
+	if !virtualnode.UpdateCondition(vn, appmesh.VirtualNodeSPIRERegistered, regStatus.status, aws.String(regStatus.reason), optionalString(regStatus.message)) {
+		return nil
+	}
+	return m.k8sClient.Status().Patch(ctx, vn, client.MergeFromWithOptions(oldVN, client.MergeFromWithOptimisticLock{}))
+}
+
+func (m *defaultRegistrationManager) updateVirtualGatewayStatus(ctx context.Context, vg *appmesh.VirtualGateway, regStatus *registrationStatus) error {
+	if regStatus == nil {
+		if !hasVirtualGatewayCondition(vg, appmesh.VirtualGatewaySPIRERegistered) {
+			return nil
+		}
+		regStatus = &registrationStatus{status: corev1.ConditionFalse, reason: reasonNotRequired}
+	}
+	oldVG := vg.DeepCopy()
+	if !virtualgateway.UpdateCondition(vg, appmesh.VirtualGatewaySPIRERegistered, regStatus.status, aws.String(regStatus.reason), optionalString(regStatus.message)) {
+		return nil
+	}
+	return m.k8sClient.Status().Patch(ctx, vg, client.MergeFromWithOptions(oldVG, client.MergeFromWithOptimisticLock{}))
+}
+
+func hasVirtualNodeCondition(vn *appmesh.VirtualNode, conditionType appmesh.VirtualNodeConditionType) bool {
+	for _, condition := range vn.Status.Conditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVirtualGatewayCondition(vg *appmesh.VirtualGateway, conditionType appmesh.VirtualGatewayConditionType) bool {
+	for _, condition := range vg.Status.Conditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
@@ -0,0 +1,230 @@
+package spire
+
+import (
+	"context"
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestScheme() *runtime.Scheme {
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	k8sSchema.AddKnownTypeWithName(ClusterSPIFFEIDGVK, &unstructured.Unstructured{})
+	k8sSchema.AddKnownTypeWithName(ClusterSPIFFEIDGVK.GroupVersion().WithKind("ClusterSPIFFEIDList"), &unstructured.UnstructuredList{})
+	return k8sSchema
+}
+
+func Test_defaultRegistrationManager_ReconcileVirtualNode(t *testing.T) {
+	podSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "my-app"}}
+	sdsVN := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1", UID: "uid-1"},
+		Spec: appmesh.VirtualNodeSpec{
+			PodSelector:     podSelector,
+			BackendDefaults: &appmesh.BackendDefaults{ClientPolicy: sdsClientPolicy("spiffe://mesh.local/app")},
+		},
+	}
+	cfg := Config{EnableRegistration: true, ClassName: "mesh"}
+	m := &defaultRegistrationManager{cfg: cfg, log: logr.New(&log.NullLogSink{})}
+	desiredObj, err := m.buildClusterSPIFFEID(OwnerKindVirtualNode, sdsVN, podSelector, "spiffe://mesh.local/app")
+	assert.NoError(t, err)
+	withStats := func(obj *unstructured.Unstructured, stats map[string]interface{}) *unstructured.Unstructured {
+		obj = obj.DeepCopy()
+		obj.Object["status"] = map[string]interface{}{"stats": stats}
+		return obj
+	}
+	staleObj, err := m.buildClusterSPIFFEID(OwnerKindVirtualNode, sdsVN, podSelector, "spiffe://mesh.local/stale")
+	assert.NoError(t, err)
+
+	type wantCondition struct {
+		status corev1.ConditionStatus
+		reason string
+	}
+	tests := []struct {
+		name          string
+		vn            *appmesh.VirtualNode
+		existingObjs  []client.Object
+		wantNames     []string
+		wantCondition *wantCondition
+	}{
+		{
+			name:          "register SPIFFE ID of virtualNode",
+			vn:            sdsVN.DeepCopy(),
+			wantNames:     []string{desiredObj.GetName()},
+			wantCondition: &wantCondition{status: corev1.ConditionUnknown, reason: reasonRegistrationPending},
+		},
+		{
+			name: "registered SPIFFE ID of virtualNode, and deregister stale SPIFFE ID",
+			vn:   sdsVN.DeepCopy(),
+			existingObjs: []client.Object{
+				withStats(desiredObj, map[string]interface{}{"podsSelected": int64(2), "entriesToSet": int64(2)}),
+				staleObj.DeepCopy(),
+			},
+			wantNames:     []string{desiredObj.GetName()},
+			wantCondition: &wantCondition{status: corev1.ConditionTrue, reason: reasonRegistered},
+		},
+		{
+			name: "failed to register SPIFFE ID of virtualNode",
+			vn:   sdsVN.DeepCopy(),
+			existingObjs: []client.Object{
+				withStats(desiredObj, map[string]interface{}{"podsSelected": int64(2), "entryFailures": int64(1)}),
+			},
+			wantNames:     []string{desiredObj.GetName()},
+			wantCondition: &wantCondition{status: corev1.ConditionFalse, reason: reasonRegistrationFailed},
+		},
+		{
+			name: "virtualNode no longer using SDS",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1", UID: "uid-1"},
+				Spec:       appmesh.VirtualNodeSpec{PodSelector: podSelector},
+				Status: appmesh.VirtualNodeStatus{
+					Conditions: []appmesh.VirtualNodeCondition{
+						{Type: appmesh.VirtualNodeSPIRERegistered, Status: corev1.ConditionTrue, Reason: aws.String(reasonRegistered)},
+					},
+				},
+			},
+			existingObjs:  []client.Object{desiredObj.DeepCopy()},
+			wantNames:     nil,
+			wantCondition: &wantCondition{status: corev1.ConditionFalse, reason: reasonNotRequired},
+		},
+		{
+			name: "virtualNode never using SDS",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1", UID: "uid-1"},
+				Spec:       appmesh.VirtualNodeSpec{PodSelector: podSelector},
+			},
+			wantNames:     nil,
+			wantCondition: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sClient := testclient.NewClientBuilder().WithScheme(newTestScheme()).
+				WithStatusSubresource(&appmesh.VirtualNode{}).
+				WithObjects(append(tt.existingObjs, tt.vn)...).Build()
+			m := &defaultRegistrationManager{k8sClient: k8sClient, cfg: cfg, log: logr.New(&log.NullLogSink{})}
+
+			vn := &appmesh.VirtualNode{}
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(tt.vn), vn))
+			err := m.ReconcileVirtualNode(ctx, vn)
+			assert.NoError(t, err)
+
+			objList := NewClusterSPIFFEIDList()
+			assert.NoError(t, k8sClient.List(ctx, objList))
+			var gotNames []string
+			for _, obj := range objList.Items {
+				gotNames = append(gotNames, obj.GetName())
+				assert.Equal(t, desiredObj.Object["spec"], obj.Object["spec"])
+			}
+			assert.Equal(t, tt.wantNames, gotNames)
+
+			gotVN := &appmesh.VirtualNode{}
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(tt.vn), gotVN))
+			var gotCondition *appmesh.VirtualNodeCondition
+			for i := range gotVN.Status.Conditions {
+				if gotVN.Status.Conditions[i].Type == appmesh.VirtualNodeSPIRERegistered {
+					gotCondition = &gotVN.Status.Conditions[i]
+				}
+			}
+			if tt.wantCondition == nil {
+				assert.Nil(t, gotCondition)
+			} else {
+				assert.NotNil(t, gotCondition)
+				assert.Equal(t, tt.wantCondition.status, gotCondition.Status)
+				assert.Equal(t, tt.wantCondition.reason, aws.StringValue(gotCondition.Reason))
+			}
+		})
+	}
+}
+
+func Test_defaultRegistrationManager_CleanupVirtualNode(t *testing.T) {
+	podSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "my-app"}}
+	vn := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1", UID: "uid-1"},
+		Spec: appmesh.VirtualNodeSpec{
+			PodSelector:     podSelector,
+			BackendDefaults: &appmesh.BackendDefaults{ClientPolicy: sdsClientPolicy("spiffe://mesh.local/app")},
+		},
+	}
+	otherVN := &appmesh.VirtualNode{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-2", UID: "uid-2"},
+	}
+	m := &defaultRegistrationManager{log: logr.New(&log.NullLogSink{})}
+	obj, err := m.buildClusterSPIFFEID(OwnerKindVirtualNode, vn, podSelector, "spiffe://mesh.local/app")
+	assert.NoError(t, err)
+	otherObj, err := m.buildClusterSPIFFEID(OwnerKindVirtualNode, otherVN, podSelector, "spiffe://mesh.local/app")
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	k8sClient := testclient.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(obj, otherObj).Build()
+	m.k8sClient = k8sClient
+	assert.NoError(t, m.CleanupVirtualNode(ctx, vn))
+
+	objList := NewClusterSPIFFEIDList()
+	assert.NoError(t, k8sClient.List(ctx, objList))
+	assert.Equal(t, 1, len(objList.Items))
+	assert.Equal(t, otherObj.GetName(), objList.Items[0].GetName())
+}
+
+func Test_defaultRegistrationManager_ReconcileVirtualGateway(t *testing.T) {
+	vg := &appmesh.VirtualGateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vg-1", UID: "uid-1"},
+		Spec: appmesh.VirtualGatewaySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "gateway"}},
+			Listeners: []appmesh.VirtualGatewayListener{
+				{
+					PortMapping: appmesh.VirtualGatewayPortMapping{Port: 8443, Protocol: "http"},
+					TLS: &appmesh.VirtualGatewayListenerTLS{
+						Certificate: appmesh.VirtualGatewayListenerTLSCertificate{
+							SDS: &appmesh.VirtualGatewayListenerTLSSDSCertificate{SecretName: aws.String("spiffe://mesh.local/gateway")},
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	k8sClient := testclient.NewClientBuilder().WithScheme(newTestScheme()).
+		WithStatusSubresource(&appmesh.VirtualGateway{}).
+		WithObjects(vg.DeepCopy()).Build()
+	m := &defaultRegistrationManager{k8sClient: k8sClient, log: logr.New(&log.NullLogSink{})}
+
+	gotVG := &appmesh.VirtualGateway{}
+	assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(vg), gotVG))
+	assert.NoError(t, m.ReconcileVirtualGateway(ctx, gotVG))
+
+	objList := NewClusterSPIFFEIDList()
+	assert.NoError(t, k8sClient.List(ctx, objList))
+	assert.Equal(t, 1, len(objList.Items))
+	obj := objList.Items[0]
+	assert.Equal(t, map[string]string{LabelOwnerKind: OwnerKindVirtualGateway, LabelOwnerUID: "uid-1"}, obj.GetLabels())
+	assert.Equal(t, map[string]string{AnnotationOwner: "ns-1/vg-1"}, obj.GetAnnotations())
+	assert.Equal(t, map[string]interface{}{
+		"spiffeIDTemplate": "spiffe://mesh.local/gateway",
+		"podSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": "gateway"},
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"kubernetes.io/metadata.name": "ns-1"},
+		},
+	}, obj.Object["spec"])
+
+	assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(vg), gotVG))
+	assert.Equal(t, 1, len(gotVG.Status.Conditions))
+	assert.Equal(t, appmesh.VirtualGatewaySPIRERegistered, gotVG.Status.Conditions[0].Type)
+	assert.Equal(t, corev1.ConditionUnknown, gotVG.Status.Conditions[0].Status)
+}
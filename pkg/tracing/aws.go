@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	sdkHandlerStartAPICallSpan = "startAPICallSpan"
+	sdkHandlerStartAPIAttempt  = "startAPIAttempt"
+	sdkHandlerSendAPIAttempt   = "sendAPIAttempt"
+	sdkHandlerRecordAPIAttempt = "recordAPIAttempt"
+	sdkHandlerEndAPICallSpan   = "endAPICallSpan"
+)
+
+type apiCallContextKey struct{}
+
+// apiCall tracks the span of an AWS API call across its attempts.
+type apiCall struct {
+	span trace.Span
+	// when current attempt started signing, which includes waiting for throttling.
+	signStartTime time.Time
+	// how long current attempt waited before being sent.
+	throttleWait time.Duration
+}
+
+// InjectAWSHandlers injects handlers that trace AWS API calls made within traced reconciles.
+// The span of each call records its attempts and time waited for client-side throttling,
+// so handlers should be injected after the throttler's.
+func InjectAWSHandlers(handlers *request.Handlers) {
+	handlers.Validate.PushFrontNamed(request.NamedHandler{
+		Name: sdkHandlerStartAPICallSpan,
+		Fn:   startAPICallSpan,
+	})
+	handlers.Sign.PushFrontNamed(request.NamedHandler{
+		Name: sdkHandlerStartAPIAttempt,
+		Fn:   startAPIAttempt,
+	})
+	handlers.Send.PushFrontNamed(request.NamedHandler{
+		Name: sdkHandlerSendAPIAttempt,
+		Fn:   sendAPIAttempt,
+	})
+	handlers.CompleteAttempt.PushFrontNamed(request.NamedHandler{
+		Name: sdkHandlerRecordAPIAttempt,
+		Fn:   recordAPIAttempt,
+	})
+	handlers.Complete.PushFrontNamed(request.NamedHandler{
+		Name: sdkHandlerEndAPICallSpan,
+		Fn:   endAPICallSpan,
+	})
+}
+
+func startAPICallSpan(r *request.Request) {
+	operation := ""
+	if r.Operation != nil {
+		operation = r.Operation.Name
+	}
+	ctx, span := StartSpan(r.Context(), r.ClientInfo.ServiceID+"/"+operation, trace.SpanKindClient,
+		attribute.String("rpc.system", "aws-api"),
+		attribute.String("rpc.service", r.ClientInfo.ServiceID),
+		attribute.String("rpc.method", operation),
+	)
+	if !span.IsRecording() {
+		return
+	}
+	r.SetContext(context.WithValue(ctx, apiCallContextKey{}, &apiCall{span: span}))
+}
+
+func startAPIAttempt(r *request.Request) {
+	if call := apiCallOf(r); call != nil {
+		call.signStartTime = time.Now()
+		call.throttleWait = 0
+	}
+}
+
+func sendAPIAttempt(r *request.Request) {
+	if call := apiCallOf(r); call != nil && !call.signStartTime.IsZero() {
+		call.throttleWait = time.Since(call.signStartTime)
+	}
+}
+
+func recordAPIAttempt(r *request.Request) {
+	call := apiCallOf(r)
+	if call == nil {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		attribute.Int("aws.attempt", r.RetryCount),
+		attribute.Int64("aws.throttle_wait_ms", call.throttleWait.Milliseconds()),
+	}
+	if r.HTTPResponse != nil {
+		attrs = append(attrs, attribute.Int("http.status_code", r.HTTPResponse.StatusCode))
+	}
+	if code := errorCodeOf(r.Error); code != "" {
+		attrs = append(attrs, attribute.String("aws.error_code", code))
+	}
+	call.span.AddEvent("attempt", trace.WithAttributes(attrs...))
+}
+
+func endAPICallSpan(r *request.Request) {
+	call := apiCallOf(r)
+	if call == nil {
+		return
+	}
+	call.span.SetAttributes(attribute.Int("aws.retries", r.RetryCount))
+	if r.RequestID != "" {
+		call.span.SetAttributes(attribute.String("aws.request_id", r.RequestID))
+	}
+	if r.HTTPResponse != nil {
+		call.span.SetAttributes(attribute.Int("http.status_code", r.HTTPResponse.StatusCode))
+	}
+	if code := errorCodeOf(r.Error); code != "" {
+		call.span.SetAttributes(attribute.String("aws.error_code", code))
+	}
+	RecordError(call.span, r.Error)
+	call.span.End()
+}
+
+func apiCallOf(r *request.Request) *apiCall {
+	call, _ := r.Context().Value(apiCallContextKey{}).(*apiCall)
+	return call
+}
+
+// errorCodeOf returns the AWS error code of err, empty if err isn't an AWS error.
+func errorCodeOf(err error) string {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code()
+	}
+	return ""
+}
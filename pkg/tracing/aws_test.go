@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_InjectAWSHandlers(t *testing.T) {
+	handlers := request.Handlers{}
+	InjectAWSHandlers(&handlers)
+	assert.Equal(t, 1, handlers.Validate.Len())
+	assert.Equal(t, 1, handlers.Sign.Len())
+	assert.Equal(t, 1, handlers.Send.Len())
+	assert.Equal(t, 1, handlers.CompleteAttempt.Len())
+	assert.Equal(t, 1, handlers.Complete.Len())
+}
+
+func Test_awsHandlers(t *testing.T) {
+	newRequest := func(ctx context.Context) *request.Request {
+		r := &request.Request{
+			ClientInfo:  metadata.ClientInfo{ServiceID: "App Mesh"},
+			Operation:   &request.Operation{Name: "DescribeVirtualNode"},
+			HTTPRequest: &http.Request{},
+		}
+		r.SetContext(ctx)
+		return r
+	}
+
+	t.Run("untraced request", func(t *testing.T) {
+		r := newRequest(context.Background())
+		startAPICallSpan(r)
+		assert.Nil(t, apiCallOf(r))
+		assert.False(t, trace.SpanFromContext(r.Context()).IsRecording())
+		startAPIAttempt(r)
+		sendAPIAttempt(r)
+		recordAPIAttempt(r)
+		endAPICallSpan(r)
+	})
+
+	t.Run("traced request with a throttled attempt", func(t *testing.T) {
+		ctx, root, recorder := newTestTrace()
+		r := newRequest(ctx)
+		startAPICallSpan(r)
+		assert.NotNil(t, apiCallOf(r))
+		assert.True(t, trace.SpanFromContext(r.Context()).IsRecording())
+
+		startAPIAttempt(r)
+		sendAPIAttempt(r)
+		r.HTTPResponse = &http.Response{StatusCode: http.StatusBadRequest}
+		r.Error = awserr.New("ThrottlingException", "Rate exceeded", nil)
+		recordAPIAttempt(r)
+		r.RetryCount = 1
+		startAPIAttempt(r)
+		sendAPIAttempt(r)
+		r.HTTPResponse = &http.Response{StatusCode: http.StatusOK}
+		r.Error = nil
+		r.RequestID = "request-id"
+		recordAPIAttempt(r)
+		endAPICallSpan(r)
+
+		ended := recorder.Ended()
+		assert.Len(t, ended, 1)
+		span := ended[0]
+		assert.Equal(t, "App Mesh/DescribeVirtualNode", span.Name())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		assert.Len(t, span.Events(), 2)
+		assert.Contains(t, span.Events()[0].Attributes, attribute.String("aws.error_code", "ThrottlingException"))
+		assert.Contains(t, span.Events()[1].Attributes, attribute.Int("aws.attempt", 1))
+		assert.Contains(t, span.Attributes(), attribute.Int("aws.retries", 1))
+		assert.Contains(t, span.Attributes(), attribute.String("aws.request_id", "request-id"))
+		assert.Contains(t, span.Attributes(), attribute.Int("http.status_code", http.StatusOK))
+	})
+
+	t.Run("traced request failed", func(t *testing.T) {
+		ctx, _, recorder := newTestTrace()
+		r := newRequest(ctx)
+		startAPICallSpan(r)
+		r.Error = awserr.New("NotFoundException", "not found", nil)
+		endAPICallSpan(r)
+		ended := recorder.Ended()
+		assert.Len(t, ended, 1)
+		assert.Equal(t, codes.Error, ended[0].Status().Code)
+		assert.Equal(t, "NotFoundException: not found", ended[0].Status().Description)
+	})
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagTracingOTLPEndpoint  = "tracing-otlp-endpoint"
+	flagTracingSamplingRatio = "tracing-sampling-ratio"
+	flagTracingServiceName   = "tracing-service-name"
+
+	defaultTracingSamplingRatio = 1.0
+	defaultTracingServiceName   = "appmesh-controller"
+)
+
+type Config struct {
+	// Specifies the OTLP/HTTP endpoint traces are exported to, tracing is disabled if empty.
+	OTLPEndpoint string
+	// Specifies the ratio of reconciles that are traced.
+	SamplingRatio float64
+	// Specifies the service name of exported traces.
+	ServiceName string
+}
+
+func (cfg *Config) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&cfg.OTLPEndpoint, flagTracingOTLPEndpoint, "",
+		`The OTLP/HTTP endpoint of an OpenTelemetry collector to export traces of reconciles to, e.g. http://otel-collector:4318. Tracing is disabled if empty`)
+	fs.Float64Var(&cfg.SamplingRatio, flagTracingSamplingRatio, defaultTracingSamplingRatio,
+		`The ratio of reconciles that are traced, between 0 and 1`)
+	fs.StringVar(&cfg.ServiceName, flagTracingServiceName, defaultTracingServiceName,
+		`The service name of exported traces`)
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.SamplingRatio < 0 || cfg.SamplingRatio > 1 {
+		return errors.Errorf("%v must be between 0 and 1, got %v", flagTracingSamplingRatio, cfg.SamplingRatio)
+	}
+	if !cfg.Enabled() {
+		return nil
+	}
+	endpoint, err := url.Parse(cfg.OTLPEndpoint)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %v", flagTracingOTLPEndpoint)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return errors.Errorf("%v must be a http or https URL, got %v", flagTracingOTLPEndpoint, cfg.OTLPEndpoint)
+	}
+	return nil
+}
+
+// Enabled returns whether reconciles are traced.
+func (cfg *Config) Enabled() bool {
+	return cfg.OTLPEndpoint != ""
+}
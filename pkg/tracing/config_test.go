@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{
+			name: "disabled",
+			cfg:  Config{SamplingRatio: 1},
+		},
+		{
+			name: "enabled",
+			cfg:  Config{OTLPEndpoint: "http://otel-collector:4318", SamplingRatio: 0.1},
+		},
+		{
+			name:    "invalid sampling ratio",
+			cfg:     Config{SamplingRatio: 2},
+			wantErr: "tracing-sampling-ratio must be between 0 and 1, got 2",
+		},
+		{
+			name:    "invalid endpoint",
+			cfg:     Config{OTLPEndpoint: "otel-collector:4318", SamplingRatio: 1},
+			wantErr: "tracing-otlp-endpoint must be a http or https URL, got otel-collector:4318",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
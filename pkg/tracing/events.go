@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// AnnotationTraceID annotates events with the ID of trace of the reconcile that recorded them.
+	AnnotationTraceID = "appmesh.k8s.aws/trace-id"
+)
+
+// RecordEvent records event of object with recorder.
+// Events recorded within traced reconciles are annotated with trace ID, so that they can be correlated with traces.
+func RecordEvent(ctx context.Context, recorder record.EventRecorder, object runtime.Object, eventType string, reason string, message string) {
+	traceID := TraceIDFromContext(ctx)
+	if traceID == "" {
+		recorder.Event(object, eventType, reason, message)
+		return
+	}
+	recorder.AnnotatedEventf(object, map[string]string{AnnotationTraceID: traceID}, eventType, reason, "%s", message)
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func Test_RecordEvent(t *testing.T) {
+	tracedCtx, root, _ := newTestTrace()
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantEvent string
+	}{
+		{
+			name:      "untraced event",
+			ctx:       context.Background(),
+			wantEvent: "Warning ReconcileError failed 100%",
+		},
+		{
+			name:      "traced event",
+			ctx:       tracedCtx,
+			wantEvent: "Warning ReconcileError failed 100% map[appmesh.k8s.aws/trace-id:" + root.SpanContext().TraceID().String() + "]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			RecordEvent(tt.ctx, recorder, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod"}}, corev1.EventTypeWarning, "ReconcileError", "failed 100%")
+			assert.Equal(t, tt.wantEvent, <-recorder.Events)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+)
+
+// LoggerFromContext returns log with the ID of trace of ctx, so that logs of traced reconciles can be correlated with traces.
+// It returns log as is if ctx isn't traced.
+func LoggerFromContext(ctx context.Context, log logr.Logger) logr.Logger {
+	traceID := TraceIDFromContext(ctx)
+	if traceID == "" {
+		return log
+	}
+	return log.WithValues("traceID", traceID)
+}
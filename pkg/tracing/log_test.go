@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoggerFromContext(t *testing.T) {
+	tracedCtx, root, _ := newTestTrace()
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantLog string
+	}{
+		{
+			name:    "untraced logger",
+			ctx:     context.Background(),
+			wantLog: `"level"=0 "msg"="reconciled"`,
+		},
+		{
+			name:    "traced logger",
+			ctx:     tracedCtx,
+			wantLog: `"level"=0 "msg"="reconciled" "traceID"="` + root.SpanContext().TraceID().String() + `"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs []string
+			log := funcr.New(func(prefix, args string) {
+				logs = append(logs, args)
+			}, funcr.Options{})
+			LoggerFromContext(tt.ctx, log).Info("reconciled")
+			assert.Equal(t, []string{tt.wantLog}, logs)
+		})
+	}
+}
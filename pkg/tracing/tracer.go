@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	otlpTracesPath = "/v1/traces"
+
+	// instrumentationName is the name of tracers of the controller.
+	instrumentationName = "github.com/aws/aws-app-mesh-controller-for-k8s"
+
+	defaultShutdownTimeout = 10 * time.Second
+)
+
+// Tracer starts traces of reconciles.
+type Tracer interface {
+	// StartTrace starts root span of a new trace named name, which is sampled by the configured sampling ratio.
+	// The returned span isn't recording if the trace isn't sampled.
+	StartTrace(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span)
+}
+
+// NewDefaultTracer constructs new defaultTracer, which exports traces to the OTLP/HTTP endpoint of cfg.
+// It must be started so that remaining spans are exported on shutdown.
+func NewDefaultTracer(cfg Config) (*defaultTracer, error) {
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.OTLPEndpoint, "/")+otlpTracesPath),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newDefaultTracer(cfg, sdktrace.NewBatchSpanProcessor(exporter)), nil
+}
+
+// newDefaultTracer constructs new defaultTracer, whose spans are processed by processor.
+func newDefaultTracer(cfg Config, processor sdktrace.SpanProcessor) *defaultTracer {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))),
+		sdktrace.WithSpanProcessor(processor),
+	)
+	return &defaultTracer{
+		provider: provider,
+		tracer:   provider.Tracer(instrumentationName),
+	}
+}
+
+var _ Tracer = &defaultTracer{}
+var _ manager.Runnable = &defaultTracer{}
+
+// defaultTracer samples traces and exports their spans with an OpenTelemetry tracer provider.
+type defaultTracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+func (t *defaultTracer) StartTrace(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name, trace.WithNewRoot(), trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+}
+
+// Start waits until ctx is done, then shuts down the tracer provider so that remaining spans are exported.
+func (t *defaultTracer) Start(ctx context.Context) error {
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+	return t.provider.Shutdown(shutdownCtx)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, spans are exported by all replicas.
+func (t *defaultTracer) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestTrace returns ctx traced by a root span, whose spans are recorded by returned recorder.
+func newTestTrace() (context.Context, trace.Span, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := newDefaultTracer(Config{SamplingRatio: 1}, recorder)
+	ctx, root := tracer.StartTrace(context.Background(), "root")
+	return ctx, root, recorder
+}
+
+func Test_defaultTracer_StartTrace(t *testing.T) {
+	tests := []struct {
+		name          string
+		samplingRatio float64
+		wantTraced    bool
+	}{
+		{
+			name:          "sampled",
+			samplingRatio: 1,
+			wantTraced:    true,
+		},
+		{
+			name:          "not sampled",
+			samplingRatio: 0,
+			wantTraced:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tracer := newDefaultTracer(Config{SamplingRatio: tt.samplingRatio}, recorder)
+			ctx, span := tracer.StartTrace(context.Background(), "reconcile", attribute.String("controller", "mesh"))
+			assert.Equal(t, tt.wantTraced, span.IsRecording())
+			assert.Equal(t, tt.wantTraced, TraceIDFromContext(ctx) != "")
+
+			childCtx, child := StartSpan(ctx, "child", trace.SpanKindClient)
+			assert.Equal(t, tt.wantTraced, child.IsRecording())
+			child.End()
+			span.End()
+			if !tt.wantTraced {
+				assert.Empty(t, recorder.Ended())
+				return
+			}
+			assert.Equal(t, span.SpanContext().TraceID(), child.SpanContext().TraceID())
+			assert.NotEqual(t, span.SpanContext().SpanID(), child.SpanContext().SpanID())
+			assert.Equal(t, child, trace.SpanFromContext(childCtx))
+			ended := recorder.Ended()
+			assert.Len(t, ended, 2)
+			assert.Equal(t, span.SpanContext().SpanID(), ended[0].Parent().SpanID())
+			assert.Equal(t, trace.SpanKindClient, ended[0].SpanKind())
+		})
+	}
+}
+
+func Test_StartSpan_untraced(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "child", trace.SpanKindInternal)
+	assert.False(t, span.IsRecording())
+	assert.Empty(t, TraceIDFromContext(ctx))
+}
+
+func Test_RecordError(t *testing.T) {
+	ctx, _, recorder := newTestTrace()
+	_, succeeded := StartSpan(ctx, "succeeded", trace.SpanKindInternal)
+	RecordError(succeeded, nil)
+	succeeded.End()
+	_, failed := StartSpan(ctx, "failed", trace.SpanKindInternal)
+	RecordError(failed, errors.New("throttled"))
+	failed.End()
+
+	ended := recorder.Ended()
+	assert.Len(t, ended, 2)
+	assert.Equal(t, sdktrace.Status{Code: codes.Unset}, ended[0].Status())
+	assert.Equal(t, sdktrace.Status{Code: codes.Error, Description: "throttled"}, ended[1].Status())
+	assert.Len(t, ended[1].Events(), 1)
+}
+
+func Test_defaultTracer_Start(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requests <- r
+	}))
+	defer server.Close()
+
+	tracer, err := NewDefaultTracer(Config{OTLPEndpoint: server.URL + "/", SamplingRatio: 1, ServiceName: "appmesh-controller"})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- tracer.Start(ctx)
+	}()
+
+	_, root := tracer.StartTrace(context.Background(), "reconcile mesh")
+	root.End()
+	// remaining spans are exported on shutdown.
+	cancel()
+	assert.NoError(t, <-done)
+
+	request := <-requests
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, otlpTracesPath, request.URL.Path)
+	assert.Equal(t, "application/x-protobuf", request.Header.Get("Content-Type"))
+}
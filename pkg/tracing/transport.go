@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WrapTransport wraps rt to trace kubernetes API requests made within traced reconciles.
+// It's designed to be used with rest.Config.Wrap.
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(rt,
+		otelhttp.WithFilter(isTracedRequest),
+		otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+			return "k8s " + strings.ToUpper(req.Method)
+		}),
+	)
+}
+
+// isTracedRequest returns whether req is made within a traced reconcile.
+func isTracedRequest(req *http.Request) bool {
+	return trace.SpanFromContext(req.Context()).IsRecording()
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_WrapTransport(t *testing.T) {
+	tests := []struct {
+		name        string
+		traced      bool
+		resp        *http.Response
+		err         error
+		wantSpan    bool
+		wantStatus  codes.Code
+		wantHTTPErr bool
+	}{
+		{
+			name:   "untraced request",
+			traced: false,
+			resp:   &http.Response{StatusCode: http.StatusOK, Status: "200 OK"},
+		},
+		{
+			name:       "traced request",
+			traced:     true,
+			resp:       &http.Response{StatusCode: http.StatusOK, Status: "200 OK"},
+			wantSpan:   true,
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "traced request with error status",
+			traced:     true,
+			resp:       &http.Response{StatusCode: http.StatusConflict, Status: "409 Conflict"},
+			wantSpan:   true,
+			wantStatus: codes.Error,
+		},
+		{
+			name:        "traced request failed",
+			traced:      true,
+			err:         errors.New("connection refused"),
+			wantSpan:    true,
+			wantStatus:  codes.Error,
+			wantHTTPErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, root, recorder := newTestTrace()
+			if !tt.traced {
+				ctx = context.Background()
+			}
+			transport := WrapTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				span := trace.SpanFromContext(req.Context())
+				assert.Equal(t, tt.traced, span.IsRecording())
+				if tt.traced {
+					assert.NotEqual(t, root.SpanContext().SpanID(), span.SpanContext().SpanID())
+				}
+				if tt.resp != nil {
+					tt.resp.Body = io.NopCloser(strings.NewReader(""))
+				}
+				return tt.resp, tt.err
+			}))
+			req, err := http.NewRequestWithContext(ctx, http.MethodPatch, "https://kubernetes/apis/appmesh.k8s.aws/v1beta2/meshes/my-mesh/status", nil)
+			assert.NoError(t, err)
+			resp, err := transport.RoundTrip(req)
+			assert.Equal(t, tt.wantHTTPErr, err != nil)
+			if resp != nil {
+				// span of a request ends once its response body is closed.
+				assert.NoError(t, resp.Body.Close())
+			}
+			if !tt.wantSpan {
+				assert.Empty(t, recorder.Ended())
+				return
+			}
+			ended := recorder.Ended()
+			assert.Len(t, ended, 1)
+			span := ended[0]
+			assert.Equal(t, "k8s PATCH", span.Name())
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+			assert.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID())
+			assert.Equal(t, tt.wantStatus, span.Status().Code)
+		})
+	}
+}
@@ -17,8 +17,8 @@ func getCondition(vg *appmesh.VirtualGateway, conditionType appmesh.VirtualGatew
 	return nil
 }
 
-// updateCondition will update virtualGateway's condition. returns whether it's updated.
-func updateCondition(vg *appmesh.VirtualGateway, conditionType appmesh.VirtualGatewayConditionType, status corev1.ConditionStatus, reason *string, message *string) bool {
+// UpdateCondition will update virtualGateway's condition. returns whether it's updated.
+func UpdateCondition(vg *appmesh.VirtualGateway, conditionType appmesh.VirtualGatewayConditionType, status corev1.ConditionStatus, reason *string, message *string) bool {
 	now := metav1.Now()
 	existingCondition := getCondition(vg, conditionType)
 	if existingCondition == nil {
@@ -63,7 +63,7 @@ func Test_getCondition(t *testing.T) {
 	}
 }
 
-func Test_updateCondition(t *testing.T) {
+func TestUpdateCondition(t *testing.T) {
 	type args struct {
 		vg            *appmesh.VirtualGateway
 		conditionType appmesh.VirtualGatewayConditionType
@@ -261,7 +261,7 @@ func Test_updateCondition(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotChanged := updateCondition(tt.args.vg, tt.args.conditionType, tt.args.status, tt.args.reason, tt.args.message)
+			gotChanged := UpdateCondition(tt.args.vg, tt.args.conditionType, tt.args.status, tt.args.reason, tt.args.message)
 			opts := cmpopts.IgnoreTypes((*metav1.Time)(nil))
 			assert.True(t, cmp.Equal(tt.wantVG, tt.args.vg, opts), "diff", cmp.Diff(tt.wantVG, tt.args.vg, opts))
 			assert.Equal(t, tt.wantChanged, gotChanged)
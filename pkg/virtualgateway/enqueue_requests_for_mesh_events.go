@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/util/workqueue"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -34,12 +35,13 @@ func (h *enqueueRequestsForMeshEvents) Create(ctx context.Context, e event.Creat
 
 // Update is called in response to an update event
 func (h *enqueueRequestsForMeshEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
-	// virtualGateway reconcile depends on mesh is active or not.
-	// so we only need to trigger virtualGateway reconcile if mesh's active status changed.
+	// virtualGateway reconcile depends on mesh is active or not, and on mesh's virtualGateway defaults.
+	// so we only need to trigger virtualGateway reconcile if mesh's active status or virtualGateway defaults changed.
 	msOld := e.ObjectOld.(*appmesh.Mesh)
 	msNew := e.ObjectNew.(*appmesh.Mesh)
 
-	if mesh.IsMeshActive(msOld) != mesh.IsMeshActive(msNew) {
+	if mesh.IsMeshActive(msOld) != mesh.IsMeshActive(msNew) ||
+		!reflect.DeepEqual(mesh.VirtualGatewayDefaults(msOld), mesh.VirtualGatewayDefaults(msNew)) {
 		h.enqueueVirtualGatewaysForMesh(ctx, queue, msNew)
 	}
 }
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,10 +26,12 @@ type MembersFinalizer interface {
 
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-func NewPendingMembersFinalizer(k8sClient client.Client, eventRecorder record.EventRecorder, log logr.Logger) MembersFinalizer {
+// pendingMembers is the metric of members that block deletion, nil if metrics are not reported.
+func NewPendingMembersFinalizer(k8sClient client.Client, eventRecorder record.EventRecorder, pendingMembers *prometheus.GaugeVec, log logr.Logger) MembersFinalizer {
 	return &pendingMembersFinalizer{
 		k8sClient:        k8sClient,
 		eventRecorder:    eventRecorder,
+		pendingMembers:   pendingMembers,
 		log:              log,
 		evaluateInterval: pendingMembersFinalizerEvaluateInterval,
 	}
@@ -35,9 +39,10 @@ func NewPendingMembersFinalizer(k8sClient client.Client, eventRecorder record.Ev
 
 // pendingMembersFinalizer is a MembersFinalizer that will pend virtualGateway deletion until all virtualGateway members are deleted.
 type pendingMembersFinalizer struct {
-	k8sClient     client.Client
-	eventRecorder record.EventRecorder
-	log           logr.Logger
+	k8sClient      client.Client
+	eventRecorder  record.EventRecorder
+	pendingMembers *prometheus.GaugeVec
+	log            logr.Logger
 
 	evaluateInterval time.Duration
 }
@@ -47,15 +52,28 @@ func (m *pendingMembersFinalizer) Finalize(ctx context.Context, vg *appmesh.Virt
 	if err != nil {
 		return err
 	}
+	m.reportPendingMembers(vg, len(grMembers))
 	if len(grMembers) == 0 {
 		return nil
 	}
 
 	message := m.buildPendingMembersEventMessage(ctx, grMembers)
-	m.eventRecorder.Eventf(vg, corev1.EventTypeWarning, "PendingMembersDeletion", message)
+	tracing.RecordEvent(ctx, m.eventRecorder, vg, corev1.EventTypeWarning, "PendingMembersDeletion", message)
 	return runtime.NewRequeueAfterError(errors.New("pending members deletion"), m.evaluateInterval)
 }
 
+// reportPendingMembers reports the number of members that block deletion of virtualGateway.
+func (m *pendingMembersFinalizer) reportPendingMembers(vg *appmesh.VirtualGateway, count int) {
+	if m.pendingMembers == nil {
+		return
+	}
+	if count == 0 {
+		m.pendingMembers.DeleteLabelValues("VirtualGateway", vg.Namespace, vg.Name)
+		return
+	}
+	m.pendingMembers.WithLabelValues("VirtualGateway", vg.Namespace, vg.Name).Set(float64(count))
+}
+
 // findGatewayRouteMembers find the GatewayRoute members for this virtualGateway.
 func (m *pendingMembersFinalizer) findGatewayRouteMembers(ctx context.Context, vg *appmesh.VirtualGateway) ([]*appmesh.GatewayRoute, error) {
 	grList := &appmesh.GatewayRouteList{}
@@ -0,0 +1,41 @@
+package virtualgateway
+
+import (
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
+)
+
+// applyMeshDefaults returns a copy of vg with the settings it leaves unspecified populated from mesh defaults,
+// along with the mesh defaults that are in effect for vg. Settings specified on vg always take precedence.
+// The returned defaults is nil if no mesh defaults applies to vg.
+func applyMeshDefaults(vg *appmesh.VirtualGateway, ms *appmesh.Mesh) (*appmesh.VirtualGateway, *appmesh.VirtualGatewayDefaults) {
+	meshDefaults := mesh.VirtualGatewayDefaults(ms)
+	if meshDefaults == nil {
+		return vg, nil
+	}
+	effectiveVG := vg.DeepCopy()
+	applied := &appmesh.VirtualGatewayDefaults{}
+	isApplied := false
+
+	if meshDefaults.BackendDefaults != nil && meshDefaults.BackendDefaults.ClientPolicy != nil {
+		if effectiveVG.Spec.BackendDefaults == nil {
+			effectiveVG.Spec.BackendDefaults = &appmesh.VirtualGatewayBackendDefaults{}
+		}
+		if effectiveVG.Spec.BackendDefaults.ClientPolicy == nil {
+			effectiveVG.Spec.BackendDefaults.ClientPolicy = meshDefaults.BackendDefaults.ClientPolicy.DeepCopy()
+			applied.BackendDefaults = &appmesh.MeshVirtualGatewayBackendDefaults{ClientPolicy: meshDefaults.BackendDefaults.ClientPolicy.DeepCopy()}
+			isApplied = true
+		}
+	}
+
+	if meshDefaults.Logging != nil && effectiveVG.Spec.Logging == nil {
+		effectiveVG.Spec.Logging = meshDefaults.Logging.DeepCopy()
+		applied.Logging = meshDefaults.Logging.DeepCopy()
+		isApplied = true
+	}
+
+	if !isApplied {
+		return vg, nil
+	}
+	return effectiveVG, applied
+}
@@ -0,0 +1,107 @@
+package virtualgateway
+
+import (
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_applyMeshDefaults(t *testing.T) {
+	meshClientPolicy := &appmesh.VirtualGatewayClientPolicy{
+		TLS: &appmesh.VirtualGatewayClientPolicyTLS{
+			Enforce: aws.Bool(true),
+		},
+	}
+	vgClientPolicy := &appmesh.VirtualGatewayClientPolicy{
+		TLS: &appmesh.VirtualGatewayClientPolicyTLS{
+			Enforce: aws.Bool(false),
+		},
+	}
+	meshLogging := &appmesh.VirtualGatewayLogging{
+		AccessLog: &appmesh.VirtualGatewayAccessLog{
+			File: &appmesh.VirtualGatewayFileAccessLog{Path: "/dev/stdout"},
+		},
+	}
+	ms := &appmesh.Mesh{
+		Spec: appmesh.MeshSpec{
+			Defaults: &appmesh.MeshDefaults{
+				VirtualGateway: &appmesh.VirtualGatewayDefaults{
+					BackendDefaults: &appmesh.MeshVirtualGatewayBackendDefaults{ClientPolicy: meshClientPolicy},
+					Logging:         meshLogging,
+				},
+			},
+		},
+	}
+
+	type args struct {
+		vg *appmesh.VirtualGateway
+		ms *appmesh.Mesh
+	}
+	tests := []struct {
+		name            string
+		args            args
+		wantVGSpec      appmesh.VirtualGatewaySpec
+		wantMeshDefault *appmesh.VirtualGatewayDefaults
+	}{
+		{
+			name: "mesh without defaults",
+			args: args{
+				vg: &appmesh.VirtualGateway{
+					Spec: appmesh.VirtualGatewaySpec{AWSName: aws.String("vg")},
+				},
+				ms: &appmesh.Mesh{},
+			},
+			wantVGSpec:      appmesh.VirtualGatewaySpec{AWSName: aws.String("vg")},
+			wantMeshDefault: nil,
+		},
+		{
+			name: "virtualGateway leaves all settings unspecified",
+			args: args{
+				vg: &appmesh.VirtualGateway{
+					Spec: appmesh.VirtualGatewaySpec{AWSName: aws.String("vg")},
+				},
+				ms: ms,
+			},
+			wantVGSpec: appmesh.VirtualGatewaySpec{
+				AWSName:         aws.String("vg"),
+				BackendDefaults: &appmesh.VirtualGatewayBackendDefaults{ClientPolicy: meshClientPolicy},
+				Logging:         meshLogging,
+			},
+			wantMeshDefault: &appmesh.VirtualGatewayDefaults{
+				BackendDefaults: &appmesh.MeshVirtualGatewayBackendDefaults{ClientPolicy: meshClientPolicy},
+				Logging:         meshLogging,
+			},
+		},
+		{
+			name: "virtualGateway specifies client policy",
+			args: args{
+				vg: &appmesh.VirtualGateway{
+					Spec: appmesh.VirtualGatewaySpec{
+						AWSName:         aws.String("vg"),
+						BackendDefaults: &appmesh.VirtualGatewayBackendDefaults{ClientPolicy: vgClientPolicy},
+					},
+				},
+				ms: ms,
+			},
+			wantVGSpec: appmesh.VirtualGatewaySpec{
+				AWSName:         aws.String("vg"),
+				BackendDefaults: &appmesh.VirtualGatewayBackendDefaults{ClientPolicy: vgClientPolicy},
+				Logging:         meshLogging,
+			},
+			wantMeshDefault: &appmesh.VirtualGatewayDefaults{
+				Logging: meshLogging,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalVG := tt.args.vg.DeepCopy()
+			gotVG, gotMeshDefaults := applyMeshDefaults(tt.args.vg, tt.args.ms)
+			assert.Equal(t, tt.wantVGSpec, gotVG.Spec)
+			assert.Equal(t, tt.wantMeshDefault, gotMeshDefaults)
+			assert.Equal(t, originalVG, tt.args.vg)
+		})
+	}
+}
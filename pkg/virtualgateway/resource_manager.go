@@ -2,15 +2,17 @@ package virtualgateway
 
 import (
 	"context"
+	"reflect"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/equality"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
-	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	appmeshsdk "github.com/aws/aws-sdk-go/service/appmesh"
@@ -18,7 +20,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -36,57 +40,85 @@ func NewDefaultResourceManager(
 	appMeshSDK services.AppMesh,
 	referencesResolver references.Resolver,
 	accountID string,
+	defaultDeletionPolicy deletion.Policy,
 	log logr.Logger) ResourceManager {
 
 	return &defaultResourceManager{
-		k8sClient:          k8sClient,
-		appMeshSDK:         appMeshSDK,
-		referencesResolver: referencesResolver,
-		accountID:          accountID,
-		log:                log,
+		k8sClient:             k8sClient,
+		appMeshSDK:            appMeshSDK,
+		referencesResolver:    referencesResolver,
+		accountID:             accountID,
+		defaultDeletionPolicy: defaultDeletionPolicy,
+		log:                   log,
 	}
 }
 
 // defaultResourceManager implements ResourceManager
 type defaultResourceManager struct {
-	k8sClient          client.Client
-	appMeshSDK         services.AppMesh
-	referencesResolver references.Resolver
-	accountID          string
-	log                logr.Logger
+	k8sClient             client.Client
+	appMeshSDK            services.AppMesh
+	referencesResolver    references.Resolver
+	accountID             string
+	defaultDeletionPolicy deletion.Policy
+	log                   logr.Logger
 }
 
 func (m *defaultResourceManager) Reconcile(ctx context.Context, vg *appmesh.VirtualGateway) error {
 	ms, err := m.findMeshDependency(ctx, vg)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return m.waitForDependencies(ctx, vg, []appmesh.DependencyReference{
+				references.NewDependencyReference(references.DependencyKindMesh, types.NamespacedName{Name: vg.Spec.MeshRef.Name}, appmesh.DependencyReasonNotFound),
+			})
+		}
 		return err
 	}
-	if err := m.validateMeshDependencies(ctx, ms); err != nil {
-		return err
+	if waitingFor := m.validateMeshDependencies(ctx, ms); len(waitingFor) != 0 {
+		return m.waitForDependencies(ctx, vg, waitingFor)
 	}
 
+	effectiveVG, appliedMeshDefaults := applyMeshDefaults(vg, ms)
+
 	sdkVG, err := m.findSDKVirtualGateway(ctx, ms, vg)
 	if err != nil {
 		return err
 	}
 	if sdkVG == nil {
-		sdkVG, err = m.createSDKVirtualGateway(ctx, ms, vg)
+		sdkVG, err = m.createSDKVirtualGateway(ctx, ms, effectiveVG)
 		if err != nil {
 			return err
 		}
 	} else {
-		sdkVG, err = m.updateSDKVirtualGateway(ctx, sdkVG, ms, vg)
+		sdkVG, err = m.updateSDKVirtualGateway(ctx, sdkVG, ms, effectiveVG)
 		if err != nil {
 			return err
 		}
 	}
 
-	return m.updateCRDVirtualGateway(ctx, vg, sdkVG)
+	return m.updateCRDVirtualGateway(ctx, vg, sdkVG, appliedMeshDefaults)
 }
 
 func (m *defaultResourceManager) Cleanup(ctx context.Context, vg *appmesh.VirtualGateway) error {
+	deletionPolicy, err := deletion.PolicyOf(vg, m.defaultDeletionPolicy)
+	if err != nil {
+		return err
+	}
+	if deletionPolicy == deletion.PolicyRetain {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain mesh virtualGateway by deletion policy",
+			"virtualGateway", k8s.NamespacedName(vg),
+			"virtualGatewayARN", aws.StringValue(vg.Status.VirtualGatewayARN),
+		)
+		return nil
+	}
 	ms, err := m.findMeshDependency(ctx, vg)
 	if err != nil {
+		// mesh is deleted with Orphan membersDeletionPolicy, the AppMesh virtualGateway is orphaned along with the AppMesh mesh.
+		if apierrors.IsNotFound(err) {
+			tracing.LoggerFromContext(ctx, m.log).Info("skip mesh virtualGateway cleanup since mesh is deleted",
+				"virtualGateway", k8s.NamespacedName(vg),
+			)
+			return nil
+		}
 		return err
 	}
 	sdkVG, err := m.findSDKVirtualGateway(ctx, ms, vg)
@@ -115,14 +147,30 @@ func (m *defaultResourceManager) findMeshDependency(ctx context.Context, vg *app
 	return ms, nil
 }
 
-// validateMeshDependencies validate the Mesh dependency for this virtualGateway.
-func (m *defaultResourceManager) validateMeshDependencies(ctx context.Context, ms *appmesh.Mesh) error {
+// validateMeshDependencies validate the Mesh dependency for this virtualGateway, and returns it if it's unresolved.
+func (m *defaultResourceManager) validateMeshDependencies(ctx context.Context, ms *appmesh.Mesh) []appmesh.DependencyReference {
 	if !mesh.IsMeshActive(ms) {
-		return runtime.NewRequeueError(errors.New("mesh is not active yet"))
+		return []appmesh.DependencyReference{
+			references.NewDependencyReference(references.DependencyKindMesh, k8s.NamespacedName(ms), appmesh.DependencyReasonNotActive),
+		}
 	}
 	return nil
 }
 
+// waitForDependencies records the unresolved dependencies in virtualGateway status, and requeues it until they're resolved.
+// the virtualGateway is enqueued by watches once its dependencies become ready.
+func (m *defaultResourceManager) waitForDependencies(ctx context.Context, vg *appmesh.VirtualGateway, waitingFor []appmesh.DependencyReference) error {
+	waitingFor = references.NormalizeDependencyReferences(waitingFor)
+	if !reflect.DeepEqual(vg.Status.WaitingFor, waitingFor) {
+		oldVG := vg.DeepCopy()
+		vg.Status.WaitingFor = waitingFor
+		if err := m.k8sClient.Status().Patch(ctx, vg, client.MergeFrom(oldVG)); err != nil {
+			return err
+		}
+	}
+	return references.NewWaitingForError(waitingFor)
+}
+
 func (m *defaultResourceManager) findSDKVirtualGateway(ctx context.Context, ms *appmesh.Mesh, vg *appmesh.VirtualGateway) (*appmeshsdk.VirtualGatewayData, error) {
 	resp, err := m.appMeshSDK.DescribeVirtualGatewayWithContext(ctx, &appmeshsdk.DescribeVirtualGatewayInput{
 		MeshName:           ms.Spec.AWSName,
@@ -168,7 +216,7 @@ func (m *defaultResourceManager) updateSDKVirtualGateway(ctx context.Context, sd
 		return sdkVG, nil
 	}
 	if !m.isSDKVirtualGatewayControlledByCRDVirtualGateway(ctx, sdkVG, vg) {
-		m.log.V(2).Info("skip virtualGateway update since it's not controlled",
+		tracing.LoggerFromContext(ctx, m.log).V(2).Info("skip virtualGateway update since it's not controlled",
 			"virtualGateway", k8s.NamespacedName(vg),
 			"virtualGatewayARN", aws.StringValue(sdkVG.Metadata.Arn),
 		)
@@ -176,7 +224,7 @@ func (m *defaultResourceManager) updateSDKVirtualGateway(ctx context.Context, sd
 	}
 
 	diff := cmp.Diff(desiredSDKVGSpec, actualSDKVGSpec, opts)
-	m.log.V(2).Info("virtualGatewaySpec changed",
+	tracing.LoggerFromContext(ctx, m.log).V(2).Info("virtualGatewaySpec changed",
 		"virtualGateway", k8s.NamespacedName(vg),
 		"actualSDKVGSpec", actualSDKVGSpec,
 		"desiredSDKVGSpec", desiredSDKVGSpec,
@@ -196,7 +244,7 @@ func (m *defaultResourceManager) updateSDKVirtualGateway(ctx context.Context, sd
 
 func (m *defaultResourceManager) deleteSDKVirtualGateway(ctx context.Context, sdkVG *appmeshsdk.VirtualGatewayData, ms *appmesh.Mesh, vg *appmesh.VirtualGateway) error {
 	if !m.isSDKVirtualGatewayOwnedByCRDVirtualGateway(ctx, sdkVG, vg) {
-		m.log.V(2).Info("skip mesh virtualGateway since its not owned",
+		tracing.LoggerFromContext(ctx, m.log).V(2).Info("skip mesh virtualGateway since its not owned",
 			"virtualGateway", k8s.NamespacedName(vg),
 			"virtualGatewayARN", aws.StringValue(sdkVG.Metadata.Arn),
 		)
@@ -214,19 +262,27 @@ func (m *defaultResourceManager) deleteSDKVirtualGateway(ctx context.Context, sd
 	return nil
 }
 
-func (m *defaultResourceManager) updateCRDVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway, sdkVG *appmeshsdk.VirtualGatewayData) error {
+func (m *defaultResourceManager) updateCRDVirtualGateway(ctx context.Context, vg *appmesh.VirtualGateway, sdkVG *appmeshsdk.VirtualGatewayData, appliedMeshDefaults *appmesh.VirtualGatewayDefaults) error {
 	oldVG := vg.DeepCopy()
 	needsUpdate := false
 	if aws.StringValue(vg.Status.VirtualGatewayARN) != aws.StringValue(sdkVG.Metadata.Arn) {
 		vg.Status.VirtualGatewayARN = sdkVG.Metadata.Arn
 		needsUpdate = true
 	}
+	if !reflect.DeepEqual(vg.Status.AppliedMeshDefaults, appliedMeshDefaults) {
+		vg.Status.AppliedMeshDefaults = appliedMeshDefaults
+		needsUpdate = true
+	}
+	if len(vg.Status.WaitingFor) != 0 {
+		vg.Status.WaitingFor = nil
+		needsUpdate = true
+	}
 
 	vgActiveConditionStatus := corev1.ConditionFalse
 	if sdkVG.Status != nil && aws.StringValue(sdkVG.Status.Status) == appmeshsdk.VirtualGatewayStatusCodeActive {
 		vgActiveConditionStatus = corev1.ConditionTrue
 	}
-	if updateCondition(vg, appmesh.VirtualGatewayActive, vgActiveConditionStatus, nil, nil) {
+	if UpdateCondition(vg, appmesh.VirtualGatewayActive, vgActiveConditionStatus, nil, nil) {
 		needsUpdate = true
 	}
 
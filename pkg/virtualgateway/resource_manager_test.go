@@ -123,7 +123,7 @@ func Test_defaultResourceManager_updateCRDVirtualGateway(t *testing.T) {
 
 			err := k8sClient.Create(ctx, tt.args.vg.DeepCopy())
 			assert.NoError(t, err)
-			err = m.updateCRDVirtualGateway(ctx, tt.args.vg, tt.args.sdkVG)
+			err = m.updateCRDVirtualGateway(ctx, tt.args.vg, tt.args.sdkVG, nil)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
@@ -386,9 +386,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 		mesh *appmesh.Mesh
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr error
+		name string
+		args args
+		want []appmesh.DependencyReference
 	}{
 		{
 			name: "valid mesh",
@@ -409,7 +409,7 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: nil,
+			want: nil,
 		},
 		{
 			name: "inactive mesh",
@@ -430,7 +430,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: errors.New("mesh is not active yet"),
+			want: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -440,12 +442,8 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				log: logr.New(&log.NullLogSink{}),
 			}
 
-			err := m.validateMeshDependencies(ctx, tt.args.mesh)
-			if tt.wantErr != nil {
-				assert.EqualError(t, err, tt.wantErr.Error())
-			} else {
-				assert.NoError(t, err)
-			}
+			got := m.validateMeshDependencies(ctx, tt.args.mesh)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -0,0 +1,334 @@
+package virtualnode
+
+import (
+	"context"
+	"fmt"
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	appmeshsdk "github.com/aws/aws-sdk-go/service/appmesh"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+const (
+	// awsNameMigrationRecheckInterval is the interval to recheck an awsName migration that waits for references or pods.
+	awsNameMigrationRecheckInterval = 15 * time.Second
+
+	// envVirtualNodeName is the envoy environment variable the sidecar injector sets to the virtualNode it serves.
+	envVirtualNodeName = "APPMESH_VIRTUAL_NODE_NAME"
+)
+
+// IsVirtualNodeAWSNameApplied checks whether the AppMesh virtualNode for vn's current awsName has been created,
+// VirtualServices and VirtualRouters shouldn't point to a new awsName before then.
+func IsVirtualNodeAWSNameApplied(vn *appmesh.VirtualNode) bool {
+	if vn.Status.VirtualNodeARN == nil {
+		return false
+	}
+	return virtualNodeNameFromARN(aws.StringValue(vn.Status.VirtualNodeARN)) == aws.StringValue(vn.Spec.AWSName)
+}
+
+// virtualNodeNameFromARN returns the AppMesh virtualNode name in vnARN, or empty string if vnARN is invalid.
+func virtualNodeNameFromARN(vnARN string) string {
+	var vnName string
+	if err := conversions.Convert_CRD_VirtualNodeARN_To_SDK_VirtualNodeName(&vnARN, &vnName, nil); err != nil {
+		return ""
+	}
+	return vnName
+}
+
+// buildAWSNameMigration builds the awsName migration to start when the AppMesh virtualNode changes from previousARN to sdkVN.
+// it returns nil if the virtualNode name didn't change.
+func buildAWSNameMigration(previousARN *string, sdkVN *appmeshsdk.VirtualNodeData) *appmesh.VirtualNodeAWSNameMigration {
+	if previousARN == nil {
+		return nil
+	}
+	previousAWSName := virtualNodeNameFromARN(aws.StringValue(previousARN))
+	if previousAWSName == "" || previousAWSName == aws.StringValue(sdkVN.VirtualNodeName) {
+		return nil
+	}
+	return &appmesh.VirtualNodeAWSNameMigration{
+		PreviousAWSName:        previousAWSName,
+		PreviousVirtualNodeARN: aws.StringValue(previousARN),
+		Phase:                  appmesh.VirtualNodeAWSNameMigrationUpdatingReferences,
+		StartTime:              metav1.Now(),
+	}
+}
+
+// reconcileAWSNameMigration advances the in-progress awsName migration of vn and records its progress in vn.status.
+// the AppMesh virtualNode under previous awsName is deleted once no VirtualService, VirtualRouter or pod uses it.
+func (m *defaultResourceManager) reconcileAWSNameMigration(ctx context.Context, ms *appmesh.Mesh, vn *appmesh.VirtualNode) error {
+	if vn.Status.AWSNameMigration == nil {
+		return nil
+	}
+	oldVN := vn.DeepCopy()
+	migrationErr := m.advanceAWSNameMigration(ctx, ms, vn)
+	if !reflect.DeepEqual(oldVN.Status, vn.Status) {
+		if err := m.k8sClient.Status().Patch(ctx, vn, client.MergeFrom(oldVN)); err != nil {
+			return err
+		}
+	}
+	return migrationErr
+}
+
+func (m *defaultResourceManager) advanceAWSNameMigration(ctx context.Context, ms *appmesh.Mesh, vn *appmesh.VirtualNode) error {
+	migration := vn.Status.AWSNameMigration
+	if migration.Phase == appmesh.VirtualNodeAWSNameMigrationUpdatingReferences {
+		pendingReferences, err := m.findAWSNameMigrationPendingReferences(ctx, ms, vn, migration.PreviousAWSName)
+		if err != nil {
+			return err
+		}
+		migration.PendingReferences = pendingReferences
+		if len(pendingReferences) != 0 {
+			return runtime.NewRequeueAfterError(errors.Errorf("waiting for %v to stop referencing virtualNode %v",
+				strings.Join(pendingReferences, ","), migration.PreviousAWSName), awsNameMigrationRecheckInterval)
+		}
+		migration.Phase = appmesh.VirtualNodeAWSNameMigrationWaitingForPods
+	}
+	if migration.Phase == appmesh.VirtualNodeAWSNameMigrationWaitingForPods {
+		pendingPods, err := m.countAWSNameMigrationPendingPods(ctx, vn, migration.PreviousAWSName)
+		if err != nil {
+			return err
+		}
+		migration.PendingPods = pendingPods
+		if pendingPods != 0 {
+			return runtime.NewRequeueAfterError(errors.Errorf("waiting for %v pods to be re-injected from virtualNode %v",
+				pendingPods, migration.PreviousAWSName), awsNameMigrationRecheckInterval)
+		}
+		migration.Phase = appmesh.VirtualNodeAWSNameMigrationDeletingPrevious
+	}
+	if err := m.deletePreviousSDKVirtualNode(ctx, ms, vn, migration.PreviousAWSName); err != nil {
+		return err
+	}
+	tracing.LoggerFromContext(ctx, m.log).Info("completed virtualNode awsName migration",
+		"virtualNode", k8s.NamespacedName(vn),
+		"previousAWSName", migration.PreviousAWSName,
+		"awsName", aws.StringValue(vn.Spec.AWSName),
+	)
+	vn.Status.AWSNameMigration = nil
+	return nil
+}
+
+// findAWSNameMigrationPendingReferences finds VirtualServices and VirtualRouters in mesh that reference vn,
+// but whose AppMesh resources still point to the AppMesh virtualNode under previousAWSName.
+// the references are returned as kind/namespace/name.
+func (m *defaultResourceManager) findAWSNameMigrationPendingReferences(ctx context.Context, ms *appmesh.Mesh, vn *appmesh.VirtualNode, previousAWSName string) ([]string, error) {
+	vnKey := k8s.NamespacedName(vn)
+	var pendingReferences []string
+
+	vsList := &appmesh.VirtualServiceList{}
+	if err := m.k8sClient.List(ctx, vsList); err != nil {
+		return nil, errors.Wrap(err, "failed to list VirtualServices in cluster")
+	}
+	for i := range vsList.Items {
+		vs := &vsList.Items[i]
+		if vs.Spec.MeshRef == nil || !mesh.IsMeshReferenced(ms, *vs.Spec.MeshRef) {
+			continue
+		}
+		if vs.Spec.Provider == nil || vs.Spec.Provider.VirtualNode == nil || vs.Spec.Provider.VirtualNode.VirtualNodeRef == nil ||
+			references.ObjectKeyForVirtualNodeReference(vs, *vs.Spec.Provider.VirtualNode.VirtualNodeRef) != vnKey {
+			continue
+		}
+		pending, err := m.isSDKVirtualServiceReferencingVirtualNode(ctx, ms, vs, previousAWSName)
+		if err != nil {
+			return nil, err
+		}
+		if pending {
+			pendingReferences = append(pendingReferences, fmt.Sprintf("VirtualService/%s/%s", vs.Namespace, vs.Name))
+		}
+	}
+
+	vrList := &appmesh.VirtualRouterList{}
+	if err := m.k8sClient.List(ctx, vrList); err != nil {
+		return nil, errors.Wrap(err, "failed to list VirtualRouters in cluster")
+	}
+	for i := range vrList.Items {
+		vr := &vrList.Items[i]
+		if vr.Spec.MeshRef == nil || !mesh.IsMeshReferenced(ms, *vr.Spec.MeshRef) {
+			continue
+		}
+		for _, route := range vr.Spec.Routes {
+			if !isRouteReferencingVirtualNode(vr, route, vnKey) {
+				continue
+			}
+			pending, err := m.isSDKRouteReferencingVirtualNode(ctx, ms, vr, route, previousAWSName)
+			if err != nil {
+				return nil, err
+			}
+			if pending {
+				pendingReferences = append(pendingReferences, fmt.Sprintf("VirtualRouter/%s/%s", vr.Namespace, vr.Name))
+				break
+			}
+		}
+	}
+	return pendingReferences, nil
+}
+
+// isSDKVirtualServiceReferencingVirtualNode checks whether the AppMesh virtualService for vs is provided by virtualNode vnAWSName.
+func (m *defaultResourceManager) isSDKVirtualServiceReferencingVirtualNode(ctx context.Context, ms *appmesh.Mesh, vs *appmesh.VirtualService, vnAWSName string) (bool, error) {
+	resp, err := m.appMeshSDK.DescribeVirtualServiceWithContext(ctx, &appmeshsdk.DescribeVirtualServiceInput{
+		MeshName:           ms.Spec.AWSName,
+		MeshOwner:          ms.Spec.MeshOwner,
+		VirtualServiceName: vs.Spec.AWSName,
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "NotFoundException" {
+			return false, nil
+		}
+		return false, err
+	}
+	sdkVS := resp.VirtualService
+	if sdkVS.Spec == nil || sdkVS.Spec.Provider == nil || sdkVS.Spec.Provider.VirtualNode == nil {
+		return false, nil
+	}
+	return aws.StringValue(sdkVS.Spec.Provider.VirtualNode.VirtualNodeName) == vnAWSName, nil
+}
+
+// isSDKRouteReferencingVirtualNode checks whether the AppMesh route for route in vr targets virtualNode vnAWSName.
+func (m *defaultResourceManager) isSDKRouteReferencingVirtualNode(ctx context.Context, ms *appmesh.Mesh, vr *appmesh.VirtualRouter, route appmesh.Route, vnAWSName string) (bool, error) {
+	resp, err := m.appMeshSDK.DescribeRouteWithContext(ctx, &appmeshsdk.DescribeRouteInput{
+		MeshName:          ms.Spec.AWSName,
+		MeshOwner:         ms.Spec.MeshOwner,
+		VirtualRouterName: vr.Spec.AWSName,
+		RouteName:         aws.String(route.Name),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "NotFoundException" {
+			return false, nil
+		}
+		return false, err
+	}
+	if resp.Route.Spec == nil {
+		return false, nil
+	}
+	for _, target := range sdkRouteWeightedTargets(resp.Route.Spec) {
+		if aws.StringValue(target.VirtualNode) == vnAWSName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isRouteReferencingVirtualNode checks whether route in vr has a weighted target referencing virtualNode vnKey.
+func isRouteReferencingVirtualNode(vr *appmesh.VirtualRouter, route appmesh.Route, vnKey types.NamespacedName) bool {
+	var targets []appmesh.WeightedTarget
+	if route.HTTPRoute != nil {
+		targets = append(targets, route.HTTPRoute.Action.WeightedTargets...)
+	}
+	if route.HTTP2Route != nil {
+		targets = append(targets, route.HTTP2Route.Action.WeightedTargets...)
+	}
+	if route.GRPCRoute != nil {
+		targets = append(targets, route.GRPCRoute.Action.WeightedTargets...)
+	}
+	if route.TCPRoute != nil {
+		targets = append(targets, route.TCPRoute.Action.WeightedTargets...)
+	}
+	for _, target := range targets {
+		if target.VirtualNodeRef != nil && references.ObjectKeyForVirtualNodeReference(vr, *target.VirtualNodeRef) == vnKey {
+			return true
+		}
+	}
+	return false
+}
+
+// sdkRouteWeightedTargets returns the weighted targets of all route types in AppMesh routeSpec.
+func sdkRouteWeightedTargets(routeSpec *appmeshsdk.RouteSpec) []*appmeshsdk.WeightedTarget {
+	var targets []*appmeshsdk.WeightedTarget
+	if routeSpec.HttpRoute != nil && routeSpec.HttpRoute.Action != nil {
+		targets = append(targets, routeSpec.HttpRoute.Action.WeightedTargets...)
+	}
+	if routeSpec.Http2Route != nil && routeSpec.Http2Route.Action != nil {
+		targets = append(targets, routeSpec.Http2Route.Action.WeightedTargets...)
+	}
+	if routeSpec.GrpcRoute != nil && routeSpec.GrpcRoute.Action != nil {
+		targets = append(targets, routeSpec.GrpcRoute.Action.WeightedTargets...)
+	}
+	if routeSpec.TcpRoute != nil && routeSpec.TcpRoute.Action != nil {
+		targets = append(targets, routeSpec.TcpRoute.Action.WeightedTargets...)
+	}
+	return targets
+}
+
+// countAWSNameMigrationPendingPods counts pods selected by vn whose envoy is still injected with virtualNode previousAWSName.
+// pods in cache are stripped of their containers, so they are listed from API server directly.
+func (m *defaultResourceManager) countAWSNameMigrationPendingPods(ctx context.Context, vn *appmesh.VirtualNode, previousAWSName string) (int64, error) {
+	if vn.Spec.PodSelector == nil {
+		return 0, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(vn.Spec.PodSelector)
+	if err != nil {
+		return 0, err
+	}
+	podList := &corev1.PodList{}
+	if err := m.apiReader.List(ctx, podList, client.InNamespace(vn.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, errors.Wrap(err, "failed to list pods for virtualNode")
+	}
+	var pendingPods int64
+	for i := range podList.Items {
+		if isPodInjectedWithVirtualNode(&podList.Items[i], previousAWSName) {
+			pendingPods++
+		}
+	}
+	return pendingPods, nil
+}
+
+// isPodInjectedWithVirtualNode checks whether any container of a running pod is configured for virtualNode vnAWSName.
+func isPodInjectedWithVirtualNode(pod *corev1.Pod, vnAWSName string) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	suffix := "/virtualNode/" + vnAWSName
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		for _, env := range container.Env {
+			if env.Name == envVirtualNodeName && strings.HasSuffix(env.Value, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deletePreviousSDKVirtualNode deletes the AppMesh virtualNode under previousAWSName if it still exists.
+// it's retained instead if vn is retained by its deletion policy.
+func (m *defaultResourceManager) deletePreviousSDKVirtualNode(ctx context.Context, ms *appmesh.Mesh, vn *appmesh.VirtualNode, previousAWSName string) error {
+	deletionPolicy, err := deletion.PolicyOf(vn, m.defaultDeletionPolicy)
+	if err != nil {
+		return err
+	}
+	if deletionPolicy == deletion.PolicyRetain {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain previous mesh virtualNode by deletion policy",
+			"virtualNode", k8s.NamespacedName(vn),
+			"previousAWSName", previousAWSName,
+		)
+		return nil
+	}
+	resp, err := m.appMeshSDK.DescribeVirtualNodeWithContext(ctx, &appmeshsdk.DescribeVirtualNodeInput{
+		MeshName:        ms.Spec.AWSName,
+		MeshOwner:       ms.Spec.MeshOwner,
+		VirtualNodeName: aws.String(previousAWSName),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "NotFoundException" {
+			return nil
+		}
+		return err
+	}
+	if err := m.deleteSDKVirtualNode(ctx, resp.VirtualNode, ms, vn); err != nil {
+		return errors.Wrapf(err, "failed to delete previous virtualNode %v", previousAWSName)
+	}
+	return nil
+}
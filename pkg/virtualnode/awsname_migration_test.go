@@ -0,0 +1,422 @@
+package virtualnode
+
+import (
+	"context"
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	appmeshsdk "github.com/aws/aws-sdk-go/service/appmesh"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func Test_IsVirtualNodeAWSNameApplied(t *testing.T) {
+	tests := []struct {
+		name string
+		vn   *appmesh.VirtualNode
+		want bool
+	}{
+		{
+			name: "virtualNode without ARN",
+			vn: &appmesh.VirtualNode{
+				Spec: appmesh.VirtualNodeSpec{AWSName: aws.String("vn-new")},
+			},
+			want: false,
+		},
+		{
+			name: "virtualNode ARN matches awsName",
+			vn: &appmesh.VirtualNode{
+				Spec:   appmesh.VirtualNodeSpec{AWSName: aws.String("vn-new")},
+				Status: appmesh.VirtualNodeStatus{VirtualNodeARN: aws.String("arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-new")},
+			},
+			want: true,
+		},
+		{
+			name: "virtualNode ARN is for previous awsName",
+			vn: &appmesh.VirtualNode{
+				Spec:   appmesh.VirtualNodeSpec{AWSName: aws.String("vn-new")},
+				Status: appmesh.VirtualNodeStatus{VirtualNodeARN: aws.String("arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-old")},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsVirtualNodeAWSNameApplied(tt.vn))
+		})
+	}
+}
+
+func Test_buildAWSNameMigration(t *testing.T) {
+	sdkVN := &appmeshsdk.VirtualNodeData{
+		VirtualNodeName: aws.String("vn-new"),
+		Metadata:        &appmeshsdk.ResourceMetadata{Arn: aws.String("arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-new")},
+	}
+	tests := []struct {
+		name        string
+		previousARN *string
+		want        *appmesh.VirtualNodeAWSNameMigration
+	}{
+		{
+			name:        "virtualNode created",
+			previousARN: nil,
+			want:        nil,
+		},
+		{
+			name:        "virtualNode recreated with same awsName",
+			previousARN: aws.String("arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-new"),
+			want:        nil,
+		},
+		{
+			name:        "virtualNode awsName changed",
+			previousARN: aws.String("arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-old"),
+			want: &appmesh.VirtualNodeAWSNameMigration{
+				PreviousAWSName:        "vn-old",
+				PreviousVirtualNodeARN: "arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-old",
+				Phase:                  appmesh.VirtualNodeAWSNameMigrationUpdatingReferences,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildAWSNameMigration(tt.previousARN, sdkVN)
+			if got != nil {
+				assert.False(t, got.StartTime.IsZero())
+				got.StartTime = metav1.Time{}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_isPodInjectedWithVirtualNode(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{
+			name: "pod injected with previous virtualNode",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app"},
+						{Name: "envoy", Env: []corev1.EnvVar{{Name: "APPMESH_VIRTUAL_NODE_NAME", Value: "mesh/my-mesh/virtualNode/vn-old"}}},
+					},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+			want: true,
+		},
+		{
+			name: "pod injected with previous virtualNode in shared mesh",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "envoy", Env: []corev1.EnvVar{{Name: "APPMESH_VIRTUAL_NODE_NAME", Value: "mesh/my-mesh@111111111111/virtualNode/vn-old"}}},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "pod injected with new virtualNode",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "envoy", Env: []corev1.EnvVar{{Name: "APPMESH_VIRTUAL_NODE_NAME", Value: "mesh/my-mesh/virtualNode/vn-new"}}},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "completed pod injected with previous virtualNode",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "envoy", Env: []corev1.EnvVar{{Name: "APPMESH_VIRTUAL_NODE_NAME", Value: "mesh/my-mesh/virtualNode/vn-old"}}},
+					},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isPodInjectedWithVirtualNode(tt.pod, "vn-old"))
+		})
+	}
+}
+
+func Test_defaultResourceManager_reconcileAWSNameMigration(t *testing.T) {
+	ms := &appmesh.Mesh{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-mesh", UID: "uid-1"},
+		Spec:       appmesh.MeshSpec{AWSName: aws.String("my-mesh")},
+	}
+	meshRef := &appmesh.MeshReference{Name: "my-mesh", UID: "uid-1"}
+	vs := &appmesh.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vs-1"},
+		Spec: appmesh.VirtualServiceSpec{
+			AWSName: aws.String("vs-1.ns-1"),
+			MeshRef: meshRef,
+			Provider: &appmesh.VirtualServiceProvider{
+				VirtualNode: &appmesh.VirtualNodeServiceProvider{
+					VirtualNodeRef: &appmesh.VirtualNodeReference{Name: "vn-1"},
+				},
+			},
+		},
+	}
+	vr := &appmesh.VirtualRouter{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "vr-1"},
+		Spec: appmesh.VirtualRouterSpec{
+			AWSName: aws.String("vr-1_ns-2"),
+			MeshRef: meshRef,
+			Routes: []appmesh.Route{
+				{
+					Name: "route-1",
+					HTTPRoute: &appmesh.HTTPRoute{
+						Action: appmesh.HTTPRouteAction{
+							WeightedTargets: []appmesh.WeightedTarget{
+								{VirtualNodeRef: &appmesh.VirtualNodeReference{Namespace: aws.String("ns-1"), Name: "vn-1"}, Weight: 100},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	oldPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "pod-1", Labels: map[string]string{"app": "vn-1"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "envoy", Env: []corev1.EnvVar{{Name: "APPMESH_VIRTUAL_NODE_NAME", Value: "mesh/my-mesh/virtualNode/vn-old"}}},
+			},
+		},
+	}
+	newPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "pod-2", Labels: map[string]string{"app": "vn-1"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "envoy", Env: []corev1.EnvVar{{Name: "APPMESH_VIRTUAL_NODE_NAME", Value: "mesh/my-mesh/virtualNode/vn-new"}}},
+			},
+		},
+	}
+
+	type fields struct {
+		sdkVSProvider    string
+		sdkRouteTarget   string
+		pods             []*corev1.Pod
+		previousSDKVNARN string
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		annotations   map[string]string
+		phase         appmesh.VirtualNodeAWSNameMigrationPhase
+		wantMigration *appmesh.VirtualNodeAWSNameMigration
+		wantDeletedVN bool
+		wantErr       string
+		wantRequeue   bool
+	}{
+		{
+			name: "virtualService and virtualRouter still reference previous virtualNode",
+			fields: fields{
+				sdkVSProvider:  "vn-old",
+				sdkRouteTarget: "vn-old",
+			},
+			phase: appmesh.VirtualNodeAWSNameMigrationUpdatingReferences,
+			wantMigration: &appmesh.VirtualNodeAWSNameMigration{
+				PreviousAWSName:   "vn-old",
+				Phase:             appmesh.VirtualNodeAWSNameMigrationUpdatingReferences,
+				PendingReferences: []string{"VirtualService/ns-1/vs-1", "VirtualRouter/ns-2/vr-1"},
+			},
+			wantErr:     "waiting for VirtualService/ns-1/vs-1,VirtualRouter/ns-2/vr-1 to stop referencing virtualNode vn-old",
+			wantRequeue: true,
+		},
+		{
+			name: "references updated, pods still injected with previous virtualNode",
+			fields: fields{
+				sdkVSProvider:  "vn-new",
+				sdkRouteTarget: "vn-new",
+				pods:           []*corev1.Pod{oldPod, newPod},
+			},
+			phase: appmesh.VirtualNodeAWSNameMigrationUpdatingReferences,
+			wantMigration: &appmesh.VirtualNodeAWSNameMigration{
+				PreviousAWSName: "vn-old",
+				Phase:           appmesh.VirtualNodeAWSNameMigrationWaitingForPods,
+				PendingPods:     1,
+			},
+			wantErr:     "waiting for 1 pods to be re-injected from virtualNode vn-old",
+			wantRequeue: true,
+		},
+		{
+			name: "references and pods updated, previous virtualNode deleted",
+			fields: fields{
+				sdkVSProvider:    "vn-new",
+				sdkRouteTarget:   "vn-new",
+				pods:             []*corev1.Pod{newPod},
+				previousSDKVNARN: "arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-old",
+			},
+			phase:         appmesh.VirtualNodeAWSNameMigrationUpdatingReferences,
+			wantMigration: nil,
+			wantDeletedVN: true,
+		},
+		{
+			name: "references and pods updated, previous virtualNode retained by deletion policy",
+			fields: fields{
+				sdkVSProvider:    "vn-new",
+				sdkRouteTarget:   "vn-new",
+				pods:             []*corev1.Pod{newPod},
+				previousSDKVNARN: "arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-old",
+			},
+			annotations:   map[string]string{deletion.AnnotationDeletionPolicy: string(deletion.PolicyRetain)},
+			phase:         appmesh.VirtualNodeAWSNameMigrationUpdatingReferences,
+			wantMigration: nil,
+			wantDeletedVN: false,
+		},
+		{
+			name: "previous virtualNode already deleted",
+			fields: fields{
+				sdkVSProvider:  "vn-new",
+				sdkRouteTarget: "vn-new",
+			},
+			phase:         appmesh.VirtualNodeAWSNameMigrationDeletingPrevious,
+			wantMigration: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithStatusSubresource(&appmesh.VirtualNode{}).Build()
+			vn := &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1", Annotations: tt.annotations},
+				Spec: appmesh.VirtualNodeSpec{
+					AWSName:     aws.String("vn-new"),
+					MeshRef:     meshRef,
+					PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "vn-1"}},
+				},
+				Status: appmesh.VirtualNodeStatus{
+					AWSNameMigration: &appmesh.VirtualNodeAWSNameMigration{
+						PreviousAWSName: "vn-old",
+						Phase:           tt.phase,
+					},
+				},
+			}
+			for _, obj := range []client.Object{vn.DeepCopy(), vs.DeepCopy(), vr.DeepCopy()} {
+				assert.NoError(t, k8sClient.Create(ctx, obj))
+			}
+			for _, pod := range tt.fields.pods {
+				assert.NoError(t, k8sClient.Create(ctx, pod.DeepCopy()))
+			}
+			status := vn.Status
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(vn), vn))
+			vn.Status = status
+			assert.NoError(t, k8sClient.Status().Update(ctx, vn))
+
+			sdk := &fakeMigrationAppMesh{
+				accountID:        "000000000000",
+				vsProvider:       tt.fields.sdkVSProvider,
+				routeTarget:      tt.fields.sdkRouteTarget,
+				previousSDKVNARN: tt.fields.previousSDKVNARN,
+			}
+			m := &defaultResourceManager{
+				k8sClient:             k8sClient,
+				apiReader:             k8sClient,
+				appMeshSDK:            sdk,
+				accountID:             "000000000000",
+				defaultDeletionPolicy: deletion.PolicyDelete,
+				log:                   logr.Discard(),
+			}
+			err := m.reconcileAWSNameMigration(ctx, ms, vn)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRequeue, err != nil)
+			assert.Equal(t, tt.wantDeletedVN, sdk.deletedVN)
+
+			persistedVN := &appmesh.VirtualNode{}
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(vn), persistedVN))
+			gotMigration := persistedVN.Status.AWSNameMigration
+			if gotMigration != nil {
+				gotMigration.StartTime = metav1.Time{}
+			}
+			assert.Equal(t, tt.wantMigration, gotMigration)
+		})
+	}
+}
+
+type fakeMigrationAppMesh struct {
+	services.AppMesh
+
+	accountID        string
+	vsProvider       string
+	routeTarget      string
+	previousSDKVNARN string
+	deletedVN        bool
+}
+
+func (f *fakeMigrationAppMesh) DescribeVirtualServiceWithContext(_ aws.Context, input *appmeshsdk.DescribeVirtualServiceInput, _ ...request.Option) (*appmeshsdk.DescribeVirtualServiceOutput, error) {
+	return &appmeshsdk.DescribeVirtualServiceOutput{
+		VirtualService: &appmeshsdk.VirtualServiceData{
+			VirtualServiceName: input.VirtualServiceName,
+			Spec: &appmeshsdk.VirtualServiceSpec{
+				Provider: &appmeshsdk.VirtualServiceProvider{
+					VirtualNode: &appmeshsdk.VirtualNodeServiceProvider{VirtualNodeName: aws.String(f.vsProvider)},
+				},
+			},
+		},
+	}, nil
+}
+
+func (f *fakeMigrationAppMesh) DescribeRouteWithContext(_ aws.Context, input *appmeshsdk.DescribeRouteInput, _ ...request.Option) (*appmeshsdk.DescribeRouteOutput, error) {
+	return &appmeshsdk.DescribeRouteOutput{
+		Route: &appmeshsdk.RouteData{
+			RouteName: input.RouteName,
+			Spec: &appmeshsdk.RouteSpec{
+				HttpRoute: &appmeshsdk.HttpRoute{
+					Action: &appmeshsdk.HttpRouteAction{
+						WeightedTargets: []*appmeshsdk.WeightedTarget{{VirtualNode: aws.String(f.routeTarget), Weight: aws.Int64(100)}},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (f *fakeMigrationAppMesh) DescribeVirtualNodeWithContext(_ aws.Context, input *appmeshsdk.DescribeVirtualNodeInput, _ ...request.Option) (*appmeshsdk.DescribeVirtualNodeOutput, error) {
+	if f.previousSDKVNARN == "" || f.deletedVN {
+		return nil, awserr.New("NotFoundException", "virtualNode not found", nil)
+	}
+	return &appmeshsdk.DescribeVirtualNodeOutput{
+		VirtualNode: &appmeshsdk.VirtualNodeData{
+			VirtualNodeName: input.VirtualNodeName,
+			Metadata: &appmeshsdk.ResourceMetadata{
+				Arn:           aws.String(f.previousSDKVNARN),
+				ResourceOwner: aws.String(f.accountID),
+			},
+		},
+	}, nil
+}
+
+func (f *fakeMigrationAppMesh) DeleteVirtualNodeWithContext(_ aws.Context, _ *appmeshsdk.DeleteVirtualNodeInput, _ ...request.Option) (*appmeshsdk.DeleteVirtualNodeOutput, error) {
+	f.deletedVN = true
+	return &appmeshsdk.DeleteVirtualNodeOutput{}, nil
+}
@@ -17,8 +17,8 @@ func getCondition(vn *appmesh.VirtualNode, conditionType appmesh.VirtualNodeCond
 	return nil
 }
 
-// updateCondition will update virtualNode's condition. returns whether it's updated.
-func updateCondition(vn *appmesh.VirtualNode, conditionType appmesh.VirtualNodeConditionType, status corev1.ConditionStatus, reason *string, message *string) bool {
+// UpdateCondition will update virtualNode's condition. returns whether it's updated.
+func UpdateCondition(vn *appmesh.VirtualNode, conditionType appmesh.VirtualNodeConditionType, status corev1.ConditionStatus, reason *string, message *string) bool {
 	now := metav1.Now()
 	existingCondition := getCondition(vn, conditionType)
 	if existingCondition == nil {
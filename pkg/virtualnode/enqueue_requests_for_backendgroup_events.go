@@ -37,7 +37,9 @@ func (h *enqueueRequestsForBackendGroupEvents) Create(ctx context.Context, e eve
 func (h *enqueueRequestsForBackendGroupEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
 	bgOld := e.ObjectOld.(*appmesh.BackendGroup)
 	bgNew := e.ObjectNew.(*appmesh.BackendGroup)
-	if !reflect.DeepEqual(bgOld.Spec.VirtualServices, bgNew.Spec.VirtualServices) {
+	// status.virtualServices changes when the virtualServices selected by backendGroup changes.
+	if !reflect.DeepEqual(bgOld.Spec.VirtualServices, bgNew.Spec.VirtualServices) ||
+		!reflect.DeepEqual(bgOld.Status.VirtualServices, bgNew.Status.VirtualServices) {
 		h.enqueueVirtualNodesForMesh(ctx, queue, bgNew.Spec.MeshRef, bgNew)
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/util/workqueue"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -34,12 +35,13 @@ func (h *enqueueRequestsForMeshEvents) Create(ctx context.Context, e event.Creat
 
 // Update is called in response to an update event
 func (h *enqueueRequestsForMeshEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
-	// virtualNode reconcile depends on mesh is active or not.
-	// so we only need to trigger virtualNode reconcile if mesh's active status changed.
+	// virtualNode reconcile depends on mesh is active or not, and on mesh's virtualNode defaults.
+	// so we only need to trigger virtualNode reconcile if mesh's active status or virtualNode defaults changed.
 	msOld := e.ObjectOld.(*appmesh.Mesh)
 	msNew := e.ObjectNew.(*appmesh.Mesh)
 
-	if mesh.IsMeshActive(msOld) != mesh.IsMeshActive(msNew) {
+	if mesh.IsMeshActive(msOld) != mesh.IsMeshActive(msNew) ||
+		!reflect.DeepEqual(mesh.VirtualNodeDefaults(msOld), mesh.VirtualNodeDefaults(msNew)) {
 		h.enqueueVirtualNodesForMesh(ctx, queue, msNew)
 	}
 }
@@ -3,7 +3,9 @@ package virtualnode
 import (
 	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/backendgroup"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -57,17 +59,14 @@ func (h *enqueueRequestsForVirtualServiceEvents) enqueueVirtualNodesForMesh(ctx
 		if vn.Spec.MeshRef == nil || *meshRef != *vn.Spec.MeshRef {
 			continue
 		}
-		for _, bg := range vn.Spec.BackendGroups {
-			if bg.Name == "*" {
-				if bg.Namespace != nil {
-					if *bg.Namespace == vs.Namespace {
-						queue.Add(ctrl.Request{NamespacedName: k8s.NamespacedName(&vn)})
-					}
-				} else {
-					if vs.Namespace == vn.Namespace {
-						queue.Add(ctrl.Request{NamespacedName: k8s.NamespacedName(&vn)})
-					}
-				}
+		for _, bgRef := range vn.Spec.BackendGroups {
+			bgKey := references.ObjectKeyForBackendGroupReference(&vn, bgRef)
+			if bgKey.Name != backendgroup.WildcardName {
+				continue
+			}
+			if backendgroup.MatchesVirtualServiceSelector(backendgroup.NewWildcardBackendGroup(bgKey.Namespace, vn.Spec.MeshRef), vs.Namespace, vs.Labels) {
+				queue.Add(ctrl.Request{NamespacedName: k8s.NamespacedName(&vn)})
+				break
 			}
 		}
 	}
@@ -0,0 +1,100 @@
+package virtualnode
+
+import (
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
+)
+
+// applyMeshDefaults returns a copy of vn with the settings it leaves unspecified populated from mesh defaults,
+// along with the mesh defaults that are in effect for vn. Settings specified on vn always take precedence.
+// The returned defaults is nil if no mesh defaults applies to vn.
+func applyMeshDefaults(vn *appmesh.VirtualNode, ms *appmesh.Mesh) (*appmesh.VirtualNode, *appmesh.VirtualNodeDefaults) {
+	meshDefaults := mesh.VirtualNodeDefaults(ms)
+	if meshDefaults == nil {
+		return vn, nil
+	}
+	effectiveVN := vn.DeepCopy()
+	applied := &appmesh.VirtualNodeDefaults{}
+	isApplied := false
+
+	if meshDefaults.BackendDefaults != nil && meshDefaults.BackendDefaults.ClientPolicy != nil {
+		if effectiveVN.Spec.BackendDefaults == nil {
+			effectiveVN.Spec.BackendDefaults = &appmesh.BackendDefaults{}
+		}
+		if effectiveVN.Spec.BackendDefaults.ClientPolicy == nil {
+			effectiveVN.Spec.BackendDefaults.ClientPolicy = meshDefaults.BackendDefaults.ClientPolicy.DeepCopy()
+			applied.BackendDefaults = &appmesh.MeshVirtualNodeBackendDefaults{ClientPolicy: meshDefaults.BackendDefaults.ClientPolicy.DeepCopy()}
+			isApplied = true
+		}
+	}
+
+	if meshDefaults.ListenerTimeout != nil {
+		for i := range effectiveVN.Spec.Listeners {
+			listener := &effectiveVN.Spec.Listeners[i]
+			if listener.Timeout != nil {
+				continue
+			}
+			timeout := listenerTimeoutForProtocol(meshDefaults.ListenerTimeout, listener.PortMapping.Protocol)
+			if timeout == nil {
+				continue
+			}
+			listener.Timeout = timeout
+			if applied.ListenerTimeout == nil {
+				applied.ListenerTimeout = &appmesh.ListenerTimeout{}
+			}
+			mergeListenerTimeout(applied.ListenerTimeout, timeout)
+			isApplied = true
+		}
+	}
+
+	if meshDefaults.Logging != nil && effectiveVN.Spec.Logging == nil {
+		effectiveVN.Spec.Logging = meshDefaults.Logging.DeepCopy()
+		applied.Logging = meshDefaults.Logging.DeepCopy()
+		isApplied = true
+	}
+
+	if !isApplied {
+		return vn, nil
+	}
+	return effectiveVN, applied
+}
+
+// listenerTimeoutForProtocol returns the part of timeout that applies to listeners of protocol.
+// returns nil if timeout doesn't specify a timeout for protocol.
+func listenerTimeoutForProtocol(timeout *appmesh.ListenerTimeout, protocol appmesh.PortProtocol) *appmesh.ListenerTimeout {
+	switch protocol {
+	case appmesh.PortProtocolTCP:
+		if timeout.TCP != nil {
+			return &appmesh.ListenerTimeout{TCP: timeout.TCP.DeepCopy()}
+		}
+	case appmesh.PortProtocolHTTP:
+		if timeout.HTTP != nil {
+			return &appmesh.ListenerTimeout{HTTP: timeout.HTTP.DeepCopy()}
+		}
+	case appmesh.PortProtocolHTTP2:
+		if timeout.HTTP2 != nil {
+			return &appmesh.ListenerTimeout{HTTP2: timeout.HTTP2.DeepCopy()}
+		}
+	case appmesh.PortProtocolGRPC:
+		if timeout.GRPC != nil {
+			return &appmesh.ListenerTimeout{GRPC: timeout.GRPC.DeepCopy()}
+		}
+	}
+	return nil
+}
+
+// mergeListenerTimeout copies the protocol timeouts specified in src into dst.
+func mergeListenerTimeout(dst *appmesh.ListenerTimeout, src *appmesh.ListenerTimeout) {
+	if src.TCP != nil {
+		dst.TCP = src.TCP.DeepCopy()
+	}
+	if src.HTTP != nil {
+		dst.HTTP = src.HTTP.DeepCopy()
+	}
+	if src.HTTP2 != nil {
+		dst.HTTP2 = src.HTTP2.DeepCopy()
+	}
+	if src.GRPC != nil {
+		dst.GRPC = src.GRPC.DeepCopy()
+	}
+}
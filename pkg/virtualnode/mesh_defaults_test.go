@@ -0,0 +1,194 @@
+package virtualnode
+
+import (
+	"testing"
+
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_applyMeshDefaults(t *testing.T) {
+	meshClientPolicy := &appmesh.ClientPolicy{
+		TLS: &appmesh.ClientPolicyTLS{
+			Enforce: aws.Bool(true),
+			Validation: appmesh.TLSValidationContext{
+				Trust: appmesh.TLSValidationContextTrust{
+					SDS: &appmesh.TLSValidationContextSDSTrust{
+						SecretName: aws.String("spiffe://mesh"),
+					},
+				},
+			},
+		},
+	}
+	vnClientPolicy := &appmesh.ClientPolicy{
+		TLS: &appmesh.ClientPolicyTLS{
+			Enforce: aws.Bool(false),
+			Validation: appmesh.TLSValidationContext{
+				Trust: appmesh.TLSValidationContextTrust{
+					SDS: &appmesh.TLSValidationContextSDSTrust{
+						SecretName: aws.String("spiffe://vn"),
+					},
+				},
+			},
+		},
+	}
+	meshHTTPTimeout := &appmesh.HTTPTimeout{
+		PerRequest: &appmesh.Duration{Unit: appmesh.DurationUnitS, Value: 30},
+	}
+	meshTCPTimeout := &appmesh.TCPTimeout{
+		Idle: &appmesh.Duration{Unit: appmesh.DurationUnitS, Value: 600},
+	}
+	meshLogging := &appmesh.Logging{
+		AccessLog: &appmesh.AccessLog{
+			File: &appmesh.FileAccessLog{Path: "/dev/stdout"},
+		},
+	}
+	ms := &appmesh.Mesh{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mesh",
+		},
+		Spec: appmesh.MeshSpec{
+			Defaults: &appmesh.MeshDefaults{
+				VirtualNode: &appmesh.VirtualNodeDefaults{
+					BackendDefaults: &appmesh.MeshVirtualNodeBackendDefaults{ClientPolicy: meshClientPolicy},
+					ListenerTimeout: &appmesh.ListenerTimeout{
+						HTTP: meshHTTPTimeout,
+						TCP:  meshTCPTimeout,
+					},
+					Logging: meshLogging,
+				},
+			},
+		},
+	}
+
+	type args struct {
+		vn *appmesh.VirtualNode
+		ms *appmesh.Mesh
+	}
+	tests := []struct {
+		name            string
+		args            args
+		wantVNSpec      appmesh.VirtualNodeSpec
+		wantMeshDefault *appmesh.VirtualNodeDefaults
+	}{
+		{
+			name: "mesh without defaults",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("vn"),
+					},
+				},
+				ms: &appmesh.Mesh{},
+			},
+			wantVNSpec: appmesh.VirtualNodeSpec{
+				AWSName: aws.String("vn"),
+			},
+			wantMeshDefault: nil,
+		},
+		{
+			name: "virtualNode leaves all settings unspecified",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("vn"),
+						Listeners: []appmesh.Listener{
+							{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolHTTP}},
+							{PortMapping: appmesh.PortMapping{Port: 9090, Protocol: appmesh.PortProtocolGRPC}},
+						},
+					},
+				},
+				ms: ms,
+			},
+			wantVNSpec: appmesh.VirtualNodeSpec{
+				AWSName: aws.String("vn"),
+				Listeners: []appmesh.Listener{
+					{
+						PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolHTTP},
+						Timeout:     &appmesh.ListenerTimeout{HTTP: meshHTTPTimeout},
+					},
+					{PortMapping: appmesh.PortMapping{Port: 9090, Protocol: appmesh.PortProtocolGRPC}},
+				},
+				BackendDefaults: &appmesh.BackendDefaults{ClientPolicy: meshClientPolicy},
+				Logging:         meshLogging,
+			},
+			wantMeshDefault: &appmesh.VirtualNodeDefaults{
+				BackendDefaults: &appmesh.MeshVirtualNodeBackendDefaults{ClientPolicy: meshClientPolicy},
+				ListenerTimeout: &appmesh.ListenerTimeout{HTTP: meshHTTPTimeout},
+				Logging:         meshLogging,
+			},
+		},
+		{
+			name: "virtualNode specifies all settings",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("vn"),
+						Listeners: []appmesh.Listener{
+							{
+								PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolTCP},
+								Timeout:     &appmesh.ListenerTimeout{TCP: &appmesh.TCPTimeout{}},
+							},
+						},
+						BackendDefaults: &appmesh.BackendDefaults{ClientPolicy: vnClientPolicy},
+						Logging:         &appmesh.Logging{},
+					},
+				},
+				ms: ms,
+			},
+			wantVNSpec: appmesh.VirtualNodeSpec{
+				AWSName: aws.String("vn"),
+				Listeners: []appmesh.Listener{
+					{
+						PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolTCP},
+						Timeout:     &appmesh.ListenerTimeout{TCP: &appmesh.TCPTimeout{}},
+					},
+				},
+				BackendDefaults: &appmesh.BackendDefaults{ClientPolicy: vnClientPolicy},
+				Logging:         &appmesh.Logging{},
+			},
+			wantMeshDefault: nil,
+		},
+		{
+			name: "virtualNode specifies some settings",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("vn"),
+						Listeners: []appmesh.Listener{
+							{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolTCP}},
+						},
+						BackendDefaults: &appmesh.BackendDefaults{ClientPolicy: vnClientPolicy},
+					},
+				},
+				ms: ms,
+			},
+			wantVNSpec: appmesh.VirtualNodeSpec{
+				AWSName: aws.String("vn"),
+				Listeners: []appmesh.Listener{
+					{
+						PortMapping: appmesh.PortMapping{Port: 8080, Protocol: appmesh.PortProtocolTCP},
+						Timeout:     &appmesh.ListenerTimeout{TCP: meshTCPTimeout},
+					},
+				},
+				BackendDefaults: &appmesh.BackendDefaults{ClientPolicy: vnClientPolicy},
+				Logging:         meshLogging,
+			},
+			wantMeshDefault: &appmesh.VirtualNodeDefaults{
+				ListenerTimeout: &appmesh.ListenerTimeout{TCP: meshTCPTimeout},
+				Logging:         meshLogging,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalVN := tt.args.vn.DeepCopy()
+			gotVN, gotMeshDefaults := applyMeshDefaults(tt.args.vn, tt.args.ms)
+			assert.Equal(t, tt.wantVNSpec, gotVN.Spec)
+			assert.Equal(t, tt.wantMeshDefault, gotMeshDefaults)
+			assert.Equal(t, originalVN, tt.args.vn)
+		})
+	}
+}
@@ -2,15 +2,16 @@ package virtualnode
 
 import (
 	"context"
-	"fmt"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/backendgroup"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/equality"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
-	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	appmeshsdk "github.com/aws/aws-sdk-go/service/appmesh"
@@ -18,8 +19,11 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/types"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -34,39 +38,53 @@ type ResourceManager interface {
 
 func NewDefaultResourceManager(
 	k8sClient client.Client,
+	apiReader client.Reader,
 	appMeshSDK services.AppMesh,
 	referencesResolver references.Resolver,
+	bgMembersResolver backendgroup.MembersResolver,
 	accountID string,
+	defaultDeletionPolicy deletion.Policy,
 	log logr.Logger,
 	enableBackendGroups bool) ResourceManager {
 
 	return &defaultResourceManager{
-		k8sClient:           k8sClient,
-		appMeshSDK:          appMeshSDK,
-		referencesResolver:  referencesResolver,
-		accountID:           accountID,
-		log:                 log,
-		enableBackendGroups: enableBackendGroups,
+		k8sClient:             k8sClient,
+		apiReader:             apiReader,
+		appMeshSDK:            appMeshSDK,
+		referencesResolver:    referencesResolver,
+		bgMembersResolver:     bgMembersResolver,
+		accountID:             accountID,
+		defaultDeletionPolicy: defaultDeletionPolicy,
+		log:                   log,
+		enableBackendGroups:   enableBackendGroups,
 	}
 }
 
 // defaultResourceManager implements ResourceManager
 type defaultResourceManager struct {
-	k8sClient           client.Client
-	appMeshSDK          services.AppMesh
-	referencesResolver  references.Resolver
-	accountID           string
-	log                 logr.Logger
-	enableBackendGroups bool
+	k8sClient             client.Client
+	apiReader             client.Reader
+	appMeshSDK            services.AppMesh
+	referencesResolver    references.Resolver
+	bgMembersResolver     backendgroup.MembersResolver
+	accountID             string
+	defaultDeletionPolicy deletion.Policy
+	log                   logr.Logger
+	enableBackendGroups   bool
 }
 
 func (m *defaultResourceManager) Reconcile(ctx context.Context, vn *appmesh.VirtualNode) error {
 	ms, err := m.findMeshDependency(ctx, vn)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return m.waitForDependencies(ctx, vn, []appmesh.DependencyReference{
+				references.NewDependencyReference(references.DependencyKindMesh, types.NamespacedName{Name: vn.Spec.MeshRef.Name}, appmesh.DependencyReasonNotFound),
+			})
+		}
 		return err
 	}
-	if err := m.validateMeshDependencies(ctx, ms); err != nil {
-		return err
+	if waitingFor := m.validateMeshDependencies(ctx, ms); len(waitingFor) != 0 {
+		return m.waitForDependencies(ctx, vn, waitingFor)
 	}
 	vsByKey, err := m.findVirtualServiceDependencies(ctx, vn)
 	if err != nil {
@@ -76,28 +94,51 @@ func (m *defaultResourceManager) Reconcile(ctx context.Context, vn *appmesh.Virt
 		return err
 	}
 
+	effectiveVN, appliedMeshDefaults := applyMeshDefaults(vn, ms)
+
 	sdkVN, err := m.findSDKVirtualNode(ctx, ms, vn)
 	if err != nil {
 		return err
 	}
 	if sdkVN == nil {
-		sdkVN, err = m.createSDKVirtualNode(ctx, ms, vn, vsByKey)
+		sdkVN, err = m.createSDKVirtualNode(ctx, ms, effectiveVN, vsByKey)
 		if err != nil {
 			return err
 		}
 	} else {
-		sdkVN, err = m.updateSDKVirtualNode(ctx, sdkVN, ms, vn, vsByKey)
+		sdkVN, err = m.updateSDKVirtualNode(ctx, sdkVN, ms, effectiveVN, vsByKey)
 		if err != nil {
 			return err
 		}
 	}
 
-	return m.updateCRDVirtualNode(ctx, vn, sdkVN)
+	if err := m.updateCRDVirtualNode(ctx, vn, sdkVN, appliedMeshDefaults); err != nil {
+		return err
+	}
+	return m.reconcileAWSNameMigration(ctx, ms, vn)
 }
 
 func (m *defaultResourceManager) Cleanup(ctx context.Context, vn *appmesh.VirtualNode) error {
+	deletionPolicy, err := deletion.PolicyOf(vn, m.defaultDeletionPolicy)
+	if err != nil {
+		return err
+	}
+	if deletionPolicy == deletion.PolicyRetain {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain mesh virtualNode by deletion policy",
+			"virtualNode", k8s.NamespacedName(vn),
+			"virtualNodeARN", aws.StringValue(vn.Status.VirtualNodeARN),
+		)
+		return nil
+	}
 	ms, err := m.findMeshDependency(ctx, vn)
 	if err != nil {
+		// mesh is deleted with Orphan membersDeletionPolicy, the AppMesh virtualNode is orphaned along with the AppMesh mesh.
+		if apierrors.IsNotFound(err) {
+			tracing.LoggerFromContext(ctx, m.log).Info("skip mesh virtualNode cleanup since mesh is deleted",
+				"virtualNode", k8s.NamespacedName(vn),
+			)
+			return nil
+		}
 		return err
 	}
 	sdkVN, err := m.findSDKVirtualNode(ctx, ms, vn)
@@ -107,6 +148,11 @@ func (m *defaultResourceManager) Cleanup(ctx context.Context, vn *appmesh.Virtua
 		}
 		return err
 	}
+	if migration := vn.Status.AWSNameMigration; migration != nil {
+		if err := m.deletePreviousSDKVirtualNode(ctx, ms, vn, migration.PreviousAWSName); err != nil {
+			return err
+		}
+	}
 	if sdkVN == nil {
 		return nil
 	}
@@ -126,57 +172,87 @@ func (m *defaultResourceManager) findMeshDependency(ctx context.Context, vn *app
 	return ms, nil
 }
 
-// validateMeshDependencies validate the Mesh dependency for this virtualNode.
-func (m *defaultResourceManager) validateMeshDependencies(ctx context.Context, ms *appmesh.Mesh) error {
+// validateMeshDependencies validate the Mesh dependency for this virtualNode, and returns it if it's unresolved.
+func (m *defaultResourceManager) validateMeshDependencies(ctx context.Context, ms *appmesh.Mesh) []appmesh.DependencyReference {
 	if !mesh.IsMeshActive(ms) {
-		return runtime.NewRequeueError(errors.New("mesh is not active yet"))
+		return []appmesh.DependencyReference{
+			references.NewDependencyReference(references.DependencyKindMesh, k8s.NamespacedName(ms), appmesh.DependencyReasonNotActive),
+		}
 	}
 	return nil
 }
 
+// waitForDependencies records the unresolved dependencies in virtualNode status, and requeues it until they're resolved.
+// the virtualNode is enqueued by watches once its dependencies become ready.
+func (m *defaultResourceManager) waitForDependencies(ctx context.Context, vn *appmesh.VirtualNode, waitingFor []appmesh.DependencyReference) error {
+	waitingFor = references.NormalizeDependencyReferences(waitingFor)
+	if !reflect.DeepEqual(vn.Status.WaitingFor, waitingFor) {
+		oldVN := vn.DeepCopy()
+		vn.Status.WaitingFor = waitingFor
+		if err := m.k8sClient.Status().Patch(ctx, vn, client.MergeFrom(oldVN)); err != nil {
+			return err
+		}
+	}
+	return references.NewWaitingForError(waitingFor)
+}
+
 // findVirtualServiceDependencies find the VirtualService dependencies for this virtualNode.
 func (m *defaultResourceManager) findVirtualServiceDependencies(ctx context.Context, vn *appmesh.VirtualNode) (map[types.NamespacedName]*appmesh.VirtualService, error) {
 	vsByKey := make(map[types.NamespacedName]*appmesh.VirtualService)
 	vsRefs := ExtractVirtualServiceReferences(vn)
+	var bgs []*appmesh.BackendGroup
 	if m.enableBackendGroups {
 		for _, backendGroupRef := range vn.Spec.BackendGroups {
-			// Wildcard special case
 			bgKey := references.ObjectKeyForBackendGroupReference(vn, backendGroupRef)
-			if bgKey.Name == "*" {
-				var listOptions client.ListOptions
-				listOptions.Namespace = bgKey.Namespace
-				vsList := &appmesh.VirtualServiceList{}
-				if err := m.k8sClient.List(ctx, vsList, &listOptions); err != nil {
-					return nil, fmt.Errorf("could not list virtualservices for namespace: %s", bgKey.Namespace)
-				}
-				for _, vs := range vsList.Items {
-					vsRef := appmesh.VirtualServiceReference{
-						Namespace: aws.String(vs.Namespace),
-						Name:      vs.Name,
-					}
-					vsRefs = append(vsRefs, vsRef)
-				}
-			} else {
-				bg, err := m.referencesResolver.ResolveBackendGroupReference(ctx, vn, backendGroupRef)
+			// the wildcard is resolved as a backendGroup selecting every virtualService in its namespace and mesh.
+			// its members are referenced by the virtualNode itself, since there is no backendGroup object to grant them to.
+			if bgKey.Name == backendgroup.WildcardName {
+				wildcardVSRefs, err := m.bgMembersResolver.Resolve(ctx, backendgroup.NewWildcardBackendGroup(bgKey.Namespace, vn.Spec.MeshRef))
 				if err != nil {
-					return nil, errors.Wrapf(err, "failed to resolve backendGroupRef")
+					return nil, errors.Wrapf(err, "failed to resolve virtualServices in backendGroup: %v", bgKey)
 				}
-				vsRefs = append(vsRefs, bg.Spec.VirtualServices...)
+				vsRefs = append(vsRefs, wildcardVSRefs...)
+				continue
+			}
+			bg, err := m.referencesResolver.ResolveBackendGroupReference(ctx, vn, backendGroupRef)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to resolve backendGroupRef")
 			}
+			bgs = append(bgs, bg)
+		}
+	}
+	if err := m.resolveVirtualServiceReferences(ctx, vn, vsRefs, vsByKey); err != nil {
+		return nil, err
+	}
+	// members of a backendGroup are referenced by the backendGroup rather than the virtualNode,
+	// so they're authorized with the backendGroup as referrer, same as the webhook and the members resolver.
+	for _, bg := range bgs {
+		bgVSRefs, err := m.bgMembersResolver.Resolve(ctx, bg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve virtualServices in backendGroup: %v", k8s.NamespacedName(bg))
+		}
+		if err := m.resolveVirtualServiceReferences(ctx, bg, bgVSRefs, vsByKey); err != nil {
+			return nil, err
 		}
 	}
+	return vsByKey, nil
+}
+
+// resolveVirtualServiceReferences resolves the virtualServices referenced by obj into vsByKey, skipping those already resolved.
+func (m *defaultResourceManager) resolveVirtualServiceReferences(ctx context.Context, obj metav1.Object, vsRefs []appmesh.VirtualServiceReference,
+	vsByKey map[types.NamespacedName]*appmesh.VirtualService) error {
 	for _, vsRef := range vsRefs {
-		vsKey := references.ObjectKeyForVirtualServiceReference(vn, vsRef)
+		vsKey := references.ObjectKeyForVirtualServiceReference(obj, vsRef)
 		if _, ok := vsByKey[vsKey]; ok {
 			continue
 		}
-		vs, err := m.referencesResolver.ResolveVirtualServiceReference(ctx, vn, vsRef)
+		vs, err := m.referencesResolver.ResolveVirtualServiceReference(ctx, obj, vsRef)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to resolve virtualServiceRef")
+			return errors.Wrapf(err, "failed to resolve virtualServiceRef")
 		}
 		vsByKey[vsKey] = vs
 	}
-	return vsByKey, nil
+	return nil
 }
 
 // validateVirtualServiceDependencies validates the VirtualService dependencies for this virtualNode.
@@ -236,7 +312,7 @@ func (m *defaultResourceManager) updateSDKVirtualNode(ctx context.Context, sdkVN
 		return sdkVN, nil
 	}
 	if !m.isSDKVirtualNodeControlledByCRDVirtualNode(ctx, sdkVN, vn) {
-		m.log.V(1).Info("skip virtualNode update since it's not controlled",
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip virtualNode update since it's not controlled",
 			"virtualNode", k8s.NamespacedName(vn),
 			"virtualNodeARN", aws.StringValue(sdkVN.Metadata.Arn),
 		)
@@ -244,7 +320,7 @@ func (m *defaultResourceManager) updateSDKVirtualNode(ctx context.Context, sdkVN
 	}
 
 	diff := cmp.Diff(desiredSDKVNSpec, actualSDKVNSpec, opts)
-	m.log.V(1).Info("virtualNodeSpec changed",
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("virtualNodeSpec changed",
 		"virtualNode", k8s.NamespacedName(vn),
 		"actualSDKVNSpec", actualSDKVNSpec,
 		"desiredSDKVNSpec", desiredSDKVNSpec,
@@ -264,7 +340,7 @@ func (m *defaultResourceManager) updateSDKVirtualNode(ctx context.Context, sdkVN
 
 func (m *defaultResourceManager) deleteSDKVirtualNode(ctx context.Context, sdkVN *appmeshsdk.VirtualNodeData, ms *appmesh.Mesh, vn *appmesh.VirtualNode) error {
 	if !m.isSDKVirtualNodeOwnedByCRDVirtualNode(ctx, sdkVN, vn) {
-		m.log.V(1).Info("skip mesh virtualNode since its not owned",
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip mesh virtualNode since its not owned",
 			"virtualNode", k8s.NamespacedName(vn),
 			"virtualNodeARN", aws.StringValue(sdkVN.Metadata.Arn),
 		)
@@ -282,13 +358,24 @@ func (m *defaultResourceManager) deleteSDKVirtualNode(ctx context.Context, sdkVN
 	return nil
 }
 
-func (m *defaultResourceManager) updateCRDVirtualNode(ctx context.Context, vn *appmesh.VirtualNode, sdkVN *appmeshsdk.VirtualNodeData) error {
+func (m *defaultResourceManager) updateCRDVirtualNode(ctx context.Context, vn *appmesh.VirtualNode, sdkVN *appmeshsdk.VirtualNodeData, appliedMeshDefaults *appmesh.VirtualNodeDefaults) error {
 	oldVN := vn.DeepCopy()
 	needsUpdate := false
 	if aws.StringValue(vn.Status.VirtualNodeARN) != aws.StringValue(sdkVN.Metadata.Arn) {
+		if vn.Status.AWSNameMigration == nil {
+			vn.Status.AWSNameMigration = buildAWSNameMigration(vn.Status.VirtualNodeARN, sdkVN)
+		}
 		vn.Status.VirtualNodeARN = sdkVN.Metadata.Arn
 		needsUpdate = true
 	}
+	if !reflect.DeepEqual(vn.Status.AppliedMeshDefaults, appliedMeshDefaults) {
+		vn.Status.AppliedMeshDefaults = appliedMeshDefaults
+		needsUpdate = true
+	}
+	if len(vn.Status.WaitingFor) != 0 {
+		vn.Status.WaitingFor = nil
+		needsUpdate = true
+	}
 	if aws.Int64Value(vn.Status.ObservedGeneration) != vn.Generation {
 		vn.Status.ObservedGeneration = aws.Int64(vn.Generation)
 		needsUpdate = true
@@ -298,7 +385,7 @@ func (m *defaultResourceManager) updateCRDVirtualNode(ctx context.Context, vn *a
 	if sdkVN.Status != nil && aws.StringValue(sdkVN.Status.Status) == appmeshsdk.VirtualNodeStatusCodeActive {
 		vnActiveConditionStatus = corev1.ConditionTrue
 	}
-	if updateCondition(vn, appmesh.VirtualNodeActive, vnActiveConditionStatus, nil, nil) {
+	if UpdateCondition(vn, appmesh.VirtualNodeActive, vnActiveConditionStatus, nil, nil) {
 		needsUpdate = true
 	}
 
@@ -3,7 +3,9 @@ package virtualnode
 import (
 	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	mock_backendgroup "github.com/aws/aws-app-mesh-controller-for-k8s/mocks/aws-app-mesh-controller-for-k8s/pkg/backendgroup"
 	mock_resolver "github.com/aws/aws-app-mesh-controller-for-k8s/mocks/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/equality"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-sdk-go/aws"
@@ -15,10 +17,13 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"reflect"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"testing"
@@ -124,7 +129,7 @@ func Test_defaultResourceManager_updateCRDVirtualNode(t *testing.T) {
 
 			err := k8sClient.Create(ctx, tt.args.vn.DeepCopy())
 			assert.NoError(t, err)
-			err = m.updateCRDVirtualNode(ctx, tt.args.vn, tt.args.sdkVN)
+			err = m.updateCRDVirtualNode(ctx, tt.args.vn, tt.args.sdkVN, nil)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
@@ -390,9 +395,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 		mesh *appmesh.Mesh
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr error
+		name string
+		args args
+		want []appmesh.DependencyReference
 	}{
 		{
 			name: "valid mesh",
@@ -413,7 +418,7 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: nil,
+			want: nil,
 		},
 		{
 			name: "inactive mesh",
@@ -434,7 +439,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: errors.New("mesh is not active yet"),
+			want: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -444,12 +451,8 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				log: logr.New(&log.NullLogSink{}),
 			}
 
-			err := m.validateMeshDependencies(ctx, tt.args.mesh)
-			if tt.wantErr != nil {
-				assert.EqualError(t, err, tt.wantErr.Error())
-			} else {
-				assert.NoError(t, err)
-			}
+			got := m.validateMeshDependencies(ctx, tt.args.mesh)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -457,9 +460,12 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 func Test_defaultResourceManager_findVirtualServiceDependencies(t *testing.T) {
 	type fields struct {
 		ResolveVirtualServiceReference func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualServiceReference) (*appmesh.VirtualService, error)
+		ResolveBackendGroupReference   func(ctx context.Context, obj metav1.Object, ref appmesh.BackendGroupReference) (*appmesh.BackendGroup, error)
+		ResolveBackendGroupMembers     func(ctx context.Context, bg *appmesh.BackendGroup) ([]appmesh.VirtualServiceReference, error)
 	}
 	type args struct {
-		vn *appmesh.VirtualNode
+		vn                  *appmesh.VirtualNode
+		enableBackendGroups bool
 	}
 	tests := []struct {
 		name    string
@@ -468,6 +474,130 @@ func Test_defaultResourceManager_findVirtualServiceDependencies(t *testing.T) {
 		want    map[types.NamespacedName]*appmesh.VirtualService
 		wantErr error
 	}{
+		{
+			name: "virtualNode with a backendGroup that selects virtualservices",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "vn-1",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						BackendGroups: []appmesh.BackendGroupReference{
+							{
+								Name: "bg-1",
+							},
+						},
+					},
+				},
+				enableBackendGroups: true,
+			},
+			fields: fields{
+				ResolveBackendGroupReference: func(ctx context.Context, obj metav1.Object, ref appmesh.BackendGroupReference) (*appmesh.BackendGroup, error) {
+					return &appmesh.BackendGroup{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns-1",
+							Name:      "bg-1",
+						},
+						Spec: appmesh.BackendGroupSpec{
+							VirtualServiceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"app": "catalog"},
+							},
+						},
+					}, nil
+				},
+				ResolveBackendGroupMembers: func(ctx context.Context, bg *appmesh.BackendGroup) ([]appmesh.VirtualServiceReference, error) {
+					return []appmesh.VirtualServiceReference{
+						{
+							Namespace: aws.String("ns-1"),
+							Name:      "vs-1",
+						},
+					}, nil
+				},
+				ResolveVirtualServiceReference: func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualServiceReference) (*appmesh.VirtualService, error) {
+					if _, ok := obj.(*appmesh.BackendGroup); !ok {
+						return nil, errors.Errorf("expected backendGroup referrer, got %T", obj)
+					}
+					return &appmesh.VirtualService{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns-1",
+							Name:      "vs-1",
+						},
+					}, nil
+				},
+			},
+			want: map[types.NamespacedName]*appmesh.VirtualService{types.NamespacedName{
+				Namespace: "ns-1", Name: "vs-1"}: {
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns-1",
+					Name:      "vs-1",
+				},
+			}},
+			wantErr: nil,
+		},
+		{
+			name: "virtualNode with the wildcard backendGroup",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "vn-1",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						BackendGroups: []appmesh.BackendGroupReference{
+							{
+								Namespace: aws.String("ns-2"),
+								Name:      "*",
+							},
+						},
+						MeshRef: &appmesh.MeshReference{Name: "mesh-1", UID: "uid-1"},
+					},
+				},
+				enableBackendGroups: true,
+			},
+			fields: fields{
+				ResolveBackendGroupMembers: func(ctx context.Context, bg *appmesh.BackendGroup) ([]appmesh.VirtualServiceReference, error) {
+					want := &appmesh.BackendGroup{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns-2",
+							Name:      "*",
+						},
+						Spec: appmesh.BackendGroupSpec{
+							VirtualServiceSelector: &metav1.LabelSelector{},
+							MeshRef:                &appmesh.MeshReference{Name: "mesh-1", UID: "uid-1"},
+						},
+					}
+					if !reflect.DeepEqual(want, bg) {
+						return nil, errors.Errorf("unexpected wildcard backendGroup: %v", bg)
+					}
+					return []appmesh.VirtualServiceReference{
+						{
+							Namespace: aws.String("ns-2"),
+							Name:      "vs-1",
+						},
+					}, nil
+				},
+				ResolveVirtualServiceReference: func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualServiceReference) (*appmesh.VirtualService, error) {
+					if _, ok := obj.(*appmesh.VirtualNode); !ok {
+						return nil, errors.Errorf("expected virtualNode referrer, got %T", obj)
+					}
+					return &appmesh.VirtualService{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns-2",
+							Name:      "vs-1",
+						},
+					}, nil
+				},
+			},
+			want: map[types.NamespacedName]*appmesh.VirtualService{types.NamespacedName{
+				Namespace: "ns-2", Name: "vs-1"}: {
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "ns-2",
+					Name:      "vs-1",
+				},
+			}},
+			wantErr: nil,
+		},
 		{
 			name: "virtualNode with a virtualservice backend",
 			args: args{
@@ -574,15 +704,24 @@ func Test_defaultResourceManager_findVirtualServiceDependencies(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			resolver := mock_resolver.NewMockResolver(ctrl)
+			bgMembersResolver := mock_backendgroup.NewMockMembersResolver(ctrl)
 
 			m := &defaultResourceManager{
-				referencesResolver: resolver,
-				log:                logr.New(&log.NullLogSink{}),
+				referencesResolver:  resolver,
+				bgMembersResolver:   bgMembersResolver,
+				log:                 logr.New(&log.NullLogSink{}),
+				enableBackendGroups: tt.args.enableBackendGroups,
 			}
 
 			if tt.fields.ResolveVirtualServiceReference != nil {
 				resolver.EXPECT().ResolveVirtualServiceReference(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveVirtualServiceReference)
 			}
+			if tt.fields.ResolveBackendGroupReference != nil {
+				resolver.EXPECT().ResolveBackendGroupReference(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveBackendGroupReference)
+			}
+			if tt.fields.ResolveBackendGroupMembers != nil {
+				bgMembersResolver.EXPECT().Resolve(gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveBackendGroupMembers)
+			}
 
 			vsmap, err := m.findVirtualServiceDependencies(ctx, tt.args.vn)
 			if tt.wantErr != nil {
@@ -756,3 +895,80 @@ func Test_BuildSDKVirtualNodeSpec(t *testing.T) {
 		})
 	}
 }
+
+func Test_defaultResourceManager_Cleanup_deletionPolicy(t *testing.T) {
+	tests := []struct {
+		name                  string
+		annotations           map[string]string
+		defaultDeletionPolicy deletion.Policy
+		wantCleanup           bool
+		meshErr               error
+		wantErr               string
+	}{
+		{
+			name:        "deleted by default",
+			wantCleanup: true,
+			wantErr:     "failed to resolve meshRef: mesh not found",
+		},
+		{
+			name:                  "retained by default policy",
+			defaultDeletionPolicy: deletion.PolicyRetain,
+		},
+		{
+			name:                  "deleted by annotation",
+			annotations:           map[string]string{deletion.AnnotationDeletionPolicy: "Delete"},
+			defaultDeletionPolicy: deletion.PolicyRetain,
+			wantCleanup:           true,
+			wantErr:               "failed to resolve meshRef: mesh not found",
+		},
+		{
+			name:        "retained by annotation",
+			annotations: map[string]string{deletion.AnnotationDeletionPolicy: "Retain"},
+		},
+		{
+			name:        "mesh deleted with orphaned members",
+			wantCleanup: true,
+			meshErr:     apierrors.NewNotFound(schema.GroupResource{Group: "appmesh.k8s.aws", Resource: "meshes"}, "my-mesh"),
+		},
+		{
+			name:        "invalid annotation",
+			annotations: map[string]string{deletion.AnnotationDeletionPolicy: "Orphan"},
+			wantErr:     `invalid appmesh.k8s.aws/deletion-policy annotation: deletion policy must be either Delete or Retain, got "Orphan"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			resolver := mock_resolver.NewMockResolver(ctrl)
+			m := &defaultResourceManager{
+				referencesResolver:    resolver,
+				defaultDeletionPolicy: tt.defaultDeletionPolicy,
+				log:                   logr.New(&log.NullLogSink{}),
+			}
+			if tt.wantCleanup {
+				meshErr := tt.meshErr
+				if meshErr == nil {
+					meshErr = errors.New("mesh not found")
+				}
+				resolver.EXPECT().ResolveMeshReference(gomock.Any(), gomock.Any()).Return(nil, meshErr)
+			}
+			vn := &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "ns-1",
+					Name:        "vn-1",
+					Annotations: tt.annotations,
+				},
+				Spec: appmesh.VirtualNodeSpec{
+					MeshRef: &appmesh.MeshReference{Name: "my-mesh", UID: "uid-1"},
+				},
+			}
+			err := m.Cleanup(context.Background(), vn)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
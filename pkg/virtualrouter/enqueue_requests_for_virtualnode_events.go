@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,12 +35,13 @@ func (h *enqueueRequestsForVirtualNodeEvents) Create(ctx context.Context, e even
 
 // Update is called in response to an update event
 func (h *enqueueRequestsForVirtualNodeEvents) Update(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[ctrl.Request]) {
-	// virtualRouter reconcile depends on virtualNode is active or not.
-	// so we only need to trigger virtualRouter reconcile if virtualNode's active status changed.
+	// virtualRouter reconcile depends on virtualNode is active or not, and the awsName it's applied with.
+	// so we only need to trigger virtualRouter reconcile if virtualNode's active status or ARN changed.
 	vnOld := e.ObjectOld.(*appmesh.VirtualNode)
 	vnNew := e.ObjectNew.(*appmesh.VirtualNode)
 
-	if virtualnode.IsVirtualNodeActive(vnOld) != virtualnode.IsVirtualNodeActive(vnNew) {
+	if virtualnode.IsVirtualNodeActive(vnOld) != virtualnode.IsVirtualNodeActive(vnNew) ||
+		aws.StringValue(vnOld.Status.VirtualNodeARN) != aws.StringValue(vnNew.Status.VirtualNodeARN) {
 		h.enqueueVirtualRoutersForVirtualNode(ctx, queue, vnNew)
 	}
 }
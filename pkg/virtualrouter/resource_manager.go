@@ -6,10 +6,11 @@ import (
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
-	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -19,8 +20,10 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/types"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -34,41 +37,52 @@ type ResourceManager interface {
 }
 
 func NewDefaultResourceManager(k8sClient client.Client, appMeshSDK services.AppMesh, referencesResolver references.Resolver,
-	accountID string, log logr.Logger) ResourceManager {
+	accountID string, defaultDeletionPolicy deletion.Policy, log logr.Logger) ResourceManager {
 	routesManager := newDefaultRoutesManager(appMeshSDK, log)
 	return &defaultResourceManager{
-		k8sClient:          k8sClient,
-		appMeshSDK:         appMeshSDK,
-		referencesResolver: referencesResolver,
-		routesManager:      routesManager,
-		accountID:          accountID,
-		log:                log,
+		k8sClient:             k8sClient,
+		appMeshSDK:            appMeshSDK,
+		referencesResolver:    referencesResolver,
+		routesManager:         routesManager,
+		accountID:             accountID,
+		defaultDeletionPolicy: defaultDeletionPolicy,
+		log:                   log,
 	}
 }
 
 type defaultResourceManager struct {
-	k8sClient          client.Client
-	appMeshSDK         services.AppMesh
-	referencesResolver references.Resolver
-	routesManager      routesManager
-	accountID          string
-	log                logr.Logger
+	k8sClient             client.Client
+	appMeshSDK            services.AppMesh
+	referencesResolver    references.Resolver
+	routesManager         routesManager
+	accountID             string
+	defaultDeletionPolicy deletion.Policy
+	log                   logr.Logger
 }
 
 func (m *defaultResourceManager) Reconcile(ctx context.Context, vr *appmesh.VirtualRouter) error {
 	ms, err := m.findMeshDependency(ctx, vr)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return m.waitForDependencies(ctx, vr, []appmesh.DependencyReference{
+				references.NewDependencyReference(references.DependencyKindMesh, types.NamespacedName{Name: vr.Spec.MeshRef.Name}, appmesh.DependencyReasonNotFound),
+			})
+		}
 		return err
 	}
-	if err := m.validateMeshDependencies(ctx, ms); err != nil {
+	waitingFor := m.validateMeshDependencies(ctx, ms)
+	vnByKey, vnWaitingFor, err := m.findVirtualNodeDependencies(ctx, vr)
+	if err != nil {
 		return err
 	}
-	vnByKey, err := m.findVirtualNodeDependencies(ctx, vr)
+	waitingFor = append(waitingFor, vnWaitingFor...)
+	vnWaitingFor, err = m.validateVirtualNodeDependencies(ctx, ms, vnByKey)
 	if err != nil {
 		return err
 	}
-	if err := m.validateVirtualNodeDependencies(ctx, ms, vnByKey); err != nil {
-		return err
+	waitingFor = append(waitingFor, vnWaitingFor...)
+	if len(waitingFor) != 0 {
+		return m.waitForDependencies(ctx, vr, waitingFor)
 	}
 
 	sdkVR, err := m.findSDKVirtualRouter(ctx, ms, vr)
@@ -104,8 +118,26 @@ func (m *defaultResourceManager) Reconcile(ctx context.Context, vr *appmesh.Virt
 }
 
 func (m *defaultResourceManager) Cleanup(ctx context.Context, vr *appmesh.VirtualRouter) error {
+	deletionPolicy, err := deletion.PolicyOf(vr, m.defaultDeletionPolicy)
+	if err != nil {
+		return err
+	}
+	if deletionPolicy == deletion.PolicyRetain {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain mesh virtualRouter by deletion policy",
+			"virtualRouter", k8s.NamespacedName(vr),
+			"virtualRouterARN", aws.StringValue(vr.Status.VirtualRouterARN),
+		)
+		return nil
+	}
 	ms, err := m.findMeshDependency(ctx, vr)
 	if err != nil {
+		// mesh is deleted with Orphan membersDeletionPolicy, the AppMesh virtualRouter is orphaned along with the AppMesh mesh.
+		if apierrors.IsNotFound(err) {
+			tracing.LoggerFromContext(ctx, m.log).Info("skip mesh virtualRouter cleanup since mesh is deleted",
+				"virtualRouter", k8s.NamespacedName(vr),
+			)
+			return nil
+		}
 		return err
 	}
 	sdkVR, err := m.findSDKVirtualRouter(ctx, ms, vr)
@@ -136,17 +168,21 @@ func (m *defaultResourceManager) findMeshDependency(ctx context.Context, vr *app
 	return ms, nil
 }
 
-// validateMeshDependencies validate the Mesh dependency for this VirtualRouter.
-func (m *defaultResourceManager) validateMeshDependencies(ctx context.Context, ms *appmesh.Mesh) error {
+// validateMeshDependencies validate the Mesh dependency for this VirtualRouter, and returns it if it's unresolved.
+func (m *defaultResourceManager) validateMeshDependencies(ctx context.Context, ms *appmesh.Mesh) []appmesh.DependencyReference {
 	if !mesh.IsMeshActive(ms) {
-		return runtime.NewRequeueError(errors.New("mesh is not active yet"))
+		return []appmesh.DependencyReference{
+			references.NewDependencyReference(references.DependencyKindMesh, k8s.NamespacedName(ms), appmesh.DependencyReasonNotActive),
+		}
 	}
 	return nil
 }
 
-func (m *defaultResourceManager) findVirtualNodeDependencies(ctx context.Context, vr *appmesh.VirtualRouter) (map[types.NamespacedName]*appmesh.VirtualNode, error) {
+// findVirtualNodeDependencies find the VirtualNode dependencies for this VirtualRouter, and the ones not found.
+func (m *defaultResourceManager) findVirtualNodeDependencies(ctx context.Context, vr *appmesh.VirtualRouter) (map[types.NamespacedName]*appmesh.VirtualNode, []appmesh.DependencyReference, error) {
 	vnRefs := ExtractVirtualNodeReferences(vr)
 	vnByKey := make(map[types.NamespacedName]*appmesh.VirtualNode)
+	var waitingFor []appmesh.DependencyReference
 	for _, vnRef := range vnRefs {
 		vnKey := references.ObjectKeyForVirtualNodeReference(vr, vnRef)
 		if _, ok := vnByKey[vnKey]; ok {
@@ -154,23 +190,45 @@ func (m *defaultResourceManager) findVirtualNodeDependencies(ctx context.Context
 		}
 		vn, err := m.referencesResolver.ResolveVirtualNodeReference(ctx, vr, vnRef)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to resolve virtualNodeRef")
+			if apierrors.IsNotFound(err) {
+				waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualNode, vnKey, appmesh.DependencyReasonNotFound))
+				continue
+			}
+			return nil, nil, errors.Wrapf(err, "failed to resolve virtualNodeRef")
 		}
 		vnByKey[vnKey] = vn
 	}
-	return vnByKey, nil
+	return vnByKey, waitingFor, nil
 }
 
-func (m *defaultResourceManager) validateVirtualNodeDependencies(ctx context.Context, ms *appmesh.Mesh, vnByKey map[types.NamespacedName]*appmesh.VirtualNode) error {
-	for _, vn := range vnByKey {
+// validateVirtualNodeDependencies validate the VirtualNode dependencies for this VirtualRouter, and returns the unresolved ones.
+func (m *defaultResourceManager) validateVirtualNodeDependencies(ctx context.Context, ms *appmesh.Mesh, vnByKey map[types.NamespacedName]*appmesh.VirtualNode) ([]appmesh.DependencyReference, error) {
+	var waitingFor []appmesh.DependencyReference
+	for vnKey, vn := range vnByKey {
 		if vn.Spec.MeshRef == nil || !mesh.IsMeshReferenced(ms, *vn.Spec.MeshRef) {
-			return errors.Errorf("virtualNode %v didn't belong to mesh %v", k8s.NamespacedName(vn), k8s.NamespacedName(ms))
+			return nil, errors.Errorf("virtualNode %v didn't belong to mesh %v", k8s.NamespacedName(vn), k8s.NamespacedName(ms))
 		}
 		if !virtualnode.IsVirtualNodeActive(vn) {
-			return runtime.NewRequeueError(errors.New("virtualNode is not active yet"))
+			waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualNode, vnKey, appmesh.DependencyReasonNotActive))
+		} else if !virtualnode.IsVirtualNodeAWSNameApplied(vn) {
+			waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualNode, vnKey, appmesh.DependencyReasonAWSNameNotApplied))
 		}
 	}
-	return nil
+	return waitingFor, nil
+}
+
+// waitForDependencies records the unresolved dependencies in VirtualRouter status, and requeues it until they're resolved.
+// the VirtualRouter is enqueued by watches once its dependencies become ready.
+func (m *defaultResourceManager) waitForDependencies(ctx context.Context, vr *appmesh.VirtualRouter, waitingFor []appmesh.DependencyReference) error {
+	waitingFor = references.NormalizeDependencyReferences(waitingFor)
+	if !reflect.DeepEqual(vr.Status.WaitingFor, waitingFor) {
+		oldVR := vr.DeepCopy()
+		vr.Status.WaitingFor = waitingFor
+		if err := m.k8sClient.Status().Patch(ctx, vr, client.MergeFrom(oldVR)); err != nil {
+			return err
+		}
+	}
+	return references.NewWaitingForError(waitingFor)
 }
 
 func (m *defaultResourceManager) findSDKVirtualRouter(ctx context.Context, ms *appmesh.Mesh, vr *appmesh.VirtualRouter) (*appmeshsdk.VirtualRouterData, error) {
@@ -218,7 +276,7 @@ func (m *defaultResourceManager) updateSDKVirtualRouter(ctx context.Context, sdk
 		return sdkVR, nil
 	}
 	if !m.isSDKVirtualRouterControlledByCRDVirtualRouter(ctx, sdkVR, vr) {
-		m.log.V(1).Info("skip virtualRouter update since it's not controlled",
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip virtualRouter update since it's not controlled",
 			"virtualRouter", k8s.NamespacedName(vr),
 			"virtualRouterARN", aws.StringValue(sdkVR.Metadata.Arn),
 		)
@@ -226,7 +284,7 @@ func (m *defaultResourceManager) updateSDKVirtualRouter(ctx context.Context, sdk
 	}
 
 	diff := cmp.Diff(desiredSDKVRSpec, actualSDKVRSpec, opts)
-	m.log.V(1).Info("virtualRouterSpec changed",
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("virtualRouterSpec changed",
 		"virtualRouter", k8s.NamespacedName(vr),
 		"actualSDKVRSpec", actualSDKVRSpec,
 		"desiredSDKVRSpec", desiredSDKVRSpec,
@@ -246,7 +304,7 @@ func (m *defaultResourceManager) updateSDKVirtualRouter(ctx context.Context, sdk
 
 func (m *defaultResourceManager) deleteSDKVirtualRouter(ctx context.Context, sdkVR *appmeshsdk.VirtualRouterData, vr *appmesh.VirtualRouter) error {
 	if !m.isSDKVirtualRouterOwnedByCRDVirtualRouter(ctx, sdkVR, vr) {
-		m.log.V(1).Info("skip virtualRouter deletion since its not owned",
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip virtualRouter deletion since its not owned",
 			"virtualRouter", k8s.NamespacedName(vr),
 			"virtualRouterARN", aws.StringValue(sdkVR.Metadata.Arn),
 		)
@@ -275,6 +333,10 @@ func (m *defaultResourceManager) updateCRDVirtualRouter(ctx context.Context, vr
 		vr.Status.ObservedGeneration = aws.Int64(vr.Generation)
 		needsUpdate = true
 	}
+	if len(vr.Status.WaitingFor) != 0 {
+		vr.Status.WaitingFor = nil
+		needsUpdate = true
+	}
 
 	routeARNByName := make(map[string]string)
 	for name, sdkRoute := range sdkRouteByName {
@@ -9,6 +9,7 @@ import (
 	mock_resolver "github.com/aws/aws-app-mesh-controller-for-k8s/mocks/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/equality"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-sdk-go/aws"
 	appmeshsdk "github.com/aws/aws-sdk-go/service/appmesh"
 	"github.com/golang/mock/gomock"
@@ -17,8 +18,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -538,9 +541,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 		mesh *appmesh.Mesh
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr error
+		name string
+		args args
+		want []appmesh.DependencyReference
 	}{
 		{
 			name: "valid mesh",
@@ -561,7 +564,7 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: nil,
+			want: nil,
 		},
 		{
 			name: "inactive mesh",
@@ -582,7 +585,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: errors.New("mesh is not active yet"),
+			want: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -592,12 +597,8 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				log: logr.New(&log.NullLogSink{}),
 			}
 
-			err := m.validateMeshDependencies(ctx, tt.args.mesh)
-			if tt.wantErr != nil {
-				assert.EqualError(t, err, tt.wantErr.Error())
-			} else {
-				assert.NoError(t, err)
-			}
+			got := m.validateMeshDependencies(ctx, tt.args.mesh)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -610,11 +611,12 @@ func Test_defaultResourceManager_findVirtualNodeDependencies(t *testing.T) {
 		vr *appmesh.VirtualRouter
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    map[types.NamespacedName]*appmesh.VirtualNode
-		wantErr error
+		name           string
+		fields         fields
+		args           args
+		want           map[types.NamespacedName]*appmesh.VirtualNode
+		wantWaitingFor []appmesh.DependencyReference
+		wantErr        error
 	}{
 		{
 			name: "virtualRouter with a virtualnode backend",
@@ -737,6 +739,50 @@ func Test_defaultResourceManager_findVirtualNodeDependencies(t *testing.T) {
 			want:    map[types.NamespacedName]*appmesh.VirtualNode{},
 			wantErr: errors.New("failed to resolve virtualNodeRef: virtual node not found"),
 		},
+		{
+			name: "virtualRouter with virtualnode not found",
+			args: args{
+				vr: &appmesh.VirtualRouter{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "vr-1",
+					},
+					Spec: appmesh.VirtualRouterSpec{
+						Routes: []appmesh.Route{
+							{
+								Name: "route1",
+								HTTPRoute: &appmesh.HTTPRoute{
+									Match: appmesh.HTTPRouteMatch{
+										Prefix: aws.String("/"),
+									},
+									Action: appmesh.HTTPRouteAction{
+										WeightedTargets: []appmesh.WeightedTarget{
+											{
+												VirtualNodeRef: &appmesh.VirtualNodeReference{
+													Namespace: aws.String("ns-1"),
+													Name:      "vn-1",
+												},
+												Weight: int64(100),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			fields: fields{
+				ResolveVirtualNodeReference: func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualNodeReference) (*appmesh.VirtualNode, error) {
+					return nil, errors.Wrapf(apierrors.NewNotFound(schema.GroupResource{Group: "appmesh.k8s.aws", Resource: "virtualnodes"}, "vn-1"), "unable to fetch virtualNode")
+				},
+			},
+			want: map[types.NamespacedName]*appmesh.VirtualNode{},
+			wantWaitingFor: []appmesh.DependencyReference{
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonNotFound},
+			},
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -754,12 +800,115 @@ func Test_defaultResourceManager_findVirtualNodeDependencies(t *testing.T) {
 				resolver.EXPECT().ResolveVirtualNodeReference(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveVirtualNodeReference)
 			}
 
-			vnmap, err := m.findVirtualNodeDependencies(ctx, tt.args.vr)
+			vnmap, waitingFor, err := m.findVirtualNodeDependencies(ctx, tt.args.vr)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, vnmap)
+				assert.Equal(t, tt.wantWaitingFor, waitingFor)
+			}
+		})
+	}
+}
+
+func Test_defaultResourceManager_validateVirtualNodeDependencies(t *testing.T) {
+	ms := &appmesh.Mesh{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-mesh",
+			UID:  "uid-1",
+		},
+	}
+	activeCondition := []appmesh.VirtualNodeCondition{
+		{
+			Type:   appmesh.VirtualNodeActive,
+			Status: corev1.ConditionTrue,
+		},
+	}
+	type args struct {
+		vnByKey map[types.NamespacedName]*appmesh.VirtualNode
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []appmesh.DependencyReference
+		wantErr error
+	}{
+		{
+			name: "virtualNodes are ready",
+			args: args{
+				vnByKey: map[types.NamespacedName]*appmesh.VirtualNode{
+					{Namespace: "ns-1", Name: "vn-1"}: {
+						ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1"},
+						Spec: appmesh.VirtualNodeSpec{
+							AWSName: aws.String("vn-1_ns-1"),
+							MeshRef: &appmesh.MeshReference{Name: "my-mesh", UID: "uid-1"},
+						},
+						Status: appmesh.VirtualNodeStatus{
+							VirtualNodeARN: aws.String("arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-1_ns-1"),
+							Conditions:     activeCondition,
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "virtualNodes are not active or awsName not applied",
+			args: args{
+				vnByKey: map[types.NamespacedName]*appmesh.VirtualNode{
+					{Namespace: "ns-1", Name: "vn-1"}: {
+						ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1"},
+						Spec: appmesh.VirtualNodeSpec{
+							AWSName: aws.String("vn-1_ns-1"),
+							MeshRef: &appmesh.MeshReference{Name: "my-mesh", UID: "uid-1"},
+						},
+					},
+					{Namespace: "ns-1", Name: "vn-2"}: {
+						ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-2"},
+						Spec: appmesh.VirtualNodeSpec{
+							AWSName: aws.String("vn-2-new_ns-1"),
+							MeshRef: &appmesh.MeshReference{Name: "my-mesh", UID: "uid-1"},
+						},
+						Status: appmesh.VirtualNodeStatus{
+							VirtualNodeARN: aws.String("arn:aws:appmesh:us-west-2:000000000000:mesh/my-mesh/virtualNode/vn-2_ns-1"),
+							Conditions:     activeCondition,
+						},
+					},
+				},
+			},
+			want: []appmesh.DependencyReference{
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonNotActive},
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-2", Reason: appmesh.DependencyReasonAWSNameNotApplied},
+			},
+		},
+		{
+			name: "virtualNode belongs to another mesh",
+			args: args{
+				vnByKey: map[types.NamespacedName]*appmesh.VirtualNode{
+					{Namespace: "ns-1", Name: "vn-1"}: {
+						ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "vn-1"},
+						Spec: appmesh.VirtualNodeSpec{
+							MeshRef: &appmesh.MeshReference{Name: "another-mesh", UID: "uid-2"},
+						},
+					},
+				},
+			},
+			wantErr: errors.New("virtualNode ns-1/vn-1 didn't belong to mesh /my-mesh"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := &defaultResourceManager{
+				log: logr.New(&log.NullLogSink{}),
+			}
+			got, err := m.validateVirtualNodeDependencies(ctx, ms, tt.args.vnByKey)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, references.NormalizeDependencyReferences(got))
 			}
 		})
 	}
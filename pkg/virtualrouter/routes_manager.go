@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	appmeshsdk "github.com/aws/aws-sdk-go/service/appmesh"
@@ -190,7 +191,7 @@ func (m *defaultRoutesManager) updateSDKRoute(ctx context.Context, sdkRoute *app
 		return sdkRoute, nil
 	}
 	diff := cmp.Diff(desiredSDKRouteSpec, actualSDKRouteSpec, opts)
-	m.log.V(1).Info("routeSpec changed",
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("routeSpec changed",
 		"virtualRouter", k8s.NamespacedName(vr),
 		"route", route.Name,
 		"actualSDKRouteSpec", actualSDKRouteSpec,
@@ -6,10 +6,11 @@ import (
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/aws/services"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/conversions"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/k8s"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/mesh"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
-	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/runtime"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/tracing"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualrouter"
 	"github.com/aws/aws-sdk-go/aws"
@@ -20,8 +21,10 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/types"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -39,45 +42,60 @@ func NewDefaultResourceManager(
 	appMeshSDK services.AppMesh,
 	referencesResolver references.Resolver,
 	accountID string,
+	defaultDeletionPolicy deletion.Policy,
 	log logr.Logger) ResourceManager {
 	return &defaultResourceManager{
-		k8sClient:          k8sClient,
-		appMeshSDK:         appMeshSDK,
-		referencesResolver: referencesResolver,
-		accountID:          accountID,
-		log:                log,
+		k8sClient:             k8sClient,
+		appMeshSDK:            appMeshSDK,
+		referencesResolver:    referencesResolver,
+		accountID:             accountID,
+		defaultDeletionPolicy: defaultDeletionPolicy,
+		log:                   log,
 	}
 }
 
 type defaultResourceManager struct {
-	k8sClient          client.Client
-	appMeshSDK         services.AppMesh
-	referencesResolver references.Resolver
-	accountID          string
-	log                logr.Logger
+	k8sClient             client.Client
+	appMeshSDK            services.AppMesh
+	referencesResolver    references.Resolver
+	accountID             string
+	defaultDeletionPolicy deletion.Policy
+	log                   logr.Logger
 }
 
 func (m *defaultResourceManager) Reconcile(ctx context.Context, vs *appmesh.VirtualService) error {
 	ms, err := m.findMeshDependency(ctx, vs)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return m.waitForDependencies(ctx, vs, []appmesh.DependencyReference{
+				references.NewDependencyReference(references.DependencyKindMesh, types.NamespacedName{Name: vs.Spec.MeshRef.Name}, appmesh.DependencyReasonNotFound),
+			})
+		}
 		return err
 	}
-	if err := m.validateMeshDependencies(ctx, ms); err != nil {
+	waitingFor := m.validateMeshDependencies(ctx, ms)
+	vnByKey, vnWaitingFor, err := m.findVirtualNodeDependencies(ctx, vs)
+	if err != nil {
 		return err
 	}
-	vnByKey, err := m.findVirtualNodeDependencies(ctx, vs)
+	waitingFor = append(waitingFor, vnWaitingFor...)
+	vnWaitingFor, err = m.validateVirtualNodeDependencies(ctx, ms, vnByKey)
 	if err != nil {
 		return err
 	}
-	if err := m.validateVirtualNodeDependencies(ctx, ms, vnByKey); err != nil {
+	waitingFor = append(waitingFor, vnWaitingFor...)
+	vrByKey, vrWaitingFor, err := m.findVirtualRouterDependencies(ctx, vs)
+	if err != nil {
 		return err
 	}
-	vrByKey, err := m.findVirtualRouterDependencies(ctx, vs)
+	waitingFor = append(waitingFor, vrWaitingFor...)
+	vrWaitingFor, err = m.validateVirtualRouterDependencies(ctx, ms, vrByKey)
 	if err != nil {
 		return err
 	}
-	if err := m.validateVirtualRouterDependencies(ctx, ms, vrByKey); err != nil {
-		return err
+	waitingFor = append(waitingFor, vrWaitingFor...)
+	if len(waitingFor) != 0 {
+		return m.waitForDependencies(ctx, vs, waitingFor)
 	}
 
 	sdkVS, err := m.findSDKVirtualService(ctx, ms, vs)
@@ -99,8 +117,26 @@ func (m *defaultResourceManager) Reconcile(ctx context.Context, vs *appmesh.Virt
 }
 
 func (m *defaultResourceManager) Cleanup(ctx context.Context, vs *appmesh.VirtualService) error {
+	deletionPolicy, err := deletion.PolicyOf(vs, m.defaultDeletionPolicy)
+	if err != nil {
+		return err
+	}
+	if deletionPolicy == deletion.PolicyRetain {
+		tracing.LoggerFromContext(ctx, m.log).Info("retain mesh virtualService by deletion policy",
+			"virtualService", k8s.NamespacedName(vs),
+			"virtualServiceARN", aws.StringValue(vs.Status.VirtualServiceARN),
+		)
+		return nil
+	}
 	ms, err := m.findMeshDependency(ctx, vs)
 	if err != nil {
+		// mesh is deleted with Orphan membersDeletionPolicy, the AppMesh virtualService is orphaned along with the AppMesh mesh.
+		if apierrors.IsNotFound(err) {
+			tracing.LoggerFromContext(ctx, m.log).Info("skip mesh virtualService cleanup since mesh is deleted",
+				"virtualService", k8s.NamespacedName(vs),
+			)
+			return nil
+		}
 		return err
 	}
 	sdkVS, err := m.findSDKVirtualService(ctx, ms, vs)
@@ -128,17 +164,21 @@ func (m *defaultResourceManager) findMeshDependency(ctx context.Context, vs *app
 	return ms, nil
 }
 
-// validateMeshDependencies validate the Mesh dependency for this VirtualService.
-func (m *defaultResourceManager) validateMeshDependencies(ctx context.Context, ms *appmesh.Mesh) error {
+// validateMeshDependencies validate the Mesh dependency for this VirtualService, and returns it if it's unresolved.
+func (m *defaultResourceManager) validateMeshDependencies(ctx context.Context, ms *appmesh.Mesh) []appmesh.DependencyReference {
 	if !mesh.IsMeshActive(ms) {
-		return runtime.NewRequeueError(errors.New("mesh is not active yet"))
+		return []appmesh.DependencyReference{
+			references.NewDependencyReference(references.DependencyKindMesh, k8s.NamespacedName(ms), appmesh.DependencyReasonNotActive),
+		}
 	}
 	return nil
 }
 
-func (m *defaultResourceManager) findVirtualNodeDependencies(ctx context.Context, vs *appmesh.VirtualService) (map[types.NamespacedName]*appmesh.VirtualNode, error) {
+// findVirtualNodeDependencies find the VirtualNode dependencies for this VirtualService, and the ones not found.
+func (m *defaultResourceManager) findVirtualNodeDependencies(ctx context.Context, vs *appmesh.VirtualService) (map[types.NamespacedName]*appmesh.VirtualNode, []appmesh.DependencyReference, error) {
 	vnRefs := ExtractVirtualNodeReferences(vs)
 	vnByKey := make(map[types.NamespacedName]*appmesh.VirtualNode)
+	var waitingFor []appmesh.DependencyReference
 	for _, vnRef := range vnRefs {
 		vnKey := references.ObjectKeyForVirtualNodeReference(vs, vnRef)
 		if _, ok := vnByKey[vnKey]; ok {
@@ -146,28 +186,38 @@ func (m *defaultResourceManager) findVirtualNodeDependencies(ctx context.Context
 		}
 		vn, err := m.referencesResolver.ResolveVirtualNodeReference(ctx, vs, vnRef)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to resolve virtualNodeRef")
+			if apierrors.IsNotFound(err) {
+				waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualNode, vnKey, appmesh.DependencyReasonNotFound))
+				continue
+			}
+			return nil, nil, errors.Wrapf(err, "failed to resolve virtualNodeRef")
 		}
 		vnByKey[vnKey] = vn
 	}
-	return vnByKey, nil
+	return vnByKey, waitingFor, nil
 }
 
-func (m *defaultResourceManager) validateVirtualNodeDependencies(ctx context.Context, ms *appmesh.Mesh, vnByKey map[types.NamespacedName]*appmesh.VirtualNode) error {
-	for _, vn := range vnByKey {
+// validateVirtualNodeDependencies validate the VirtualNode dependencies for this VirtualService, and returns the unresolved ones.
+func (m *defaultResourceManager) validateVirtualNodeDependencies(ctx context.Context, ms *appmesh.Mesh, vnByKey map[types.NamespacedName]*appmesh.VirtualNode) ([]appmesh.DependencyReference, error) {
+	var waitingFor []appmesh.DependencyReference
+	for vnKey, vn := range vnByKey {
 		if vn.Spec.MeshRef == nil || !mesh.IsMeshReferenced(ms, *vn.Spec.MeshRef) {
-			return errors.Errorf("virtualNode %v didn't belong to mesh %v", k8s.NamespacedName(vn), k8s.NamespacedName(ms))
+			return nil, errors.Errorf("virtualNode %v didn't belong to mesh %v", k8s.NamespacedName(vn), k8s.NamespacedName(ms))
 		}
 		if !virtualnode.IsVirtualNodeActive(vn) {
-			return runtime.NewRequeueError(errors.New("virtualNode is not active yet"))
+			waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualNode, vnKey, appmesh.DependencyReasonNotActive))
+		} else if !virtualnode.IsVirtualNodeAWSNameApplied(vn) {
+			waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualNode, vnKey, appmesh.DependencyReasonAWSNameNotApplied))
 		}
 	}
-	return nil
+	return waitingFor, nil
 }
 
-func (m *defaultResourceManager) findVirtualRouterDependencies(ctx context.Context, vs *appmesh.VirtualService) (map[types.NamespacedName]*appmesh.VirtualRouter, error) {
+// findVirtualRouterDependencies find the VirtualRouter dependencies for this VirtualService, and the ones not found.
+func (m *defaultResourceManager) findVirtualRouterDependencies(ctx context.Context, vs *appmesh.VirtualService) (map[types.NamespacedName]*appmesh.VirtualRouter, []appmesh.DependencyReference, error) {
 	vrRefs := ExtractVirtualRouterReferences(vs)
 	vrByKey := make(map[types.NamespacedName]*appmesh.VirtualRouter)
+	var waitingFor []appmesh.DependencyReference
 	for _, vrRef := range vrRefs {
 		vrKey := references.ObjectKeyForVirtualRouterReference(vs, vrRef)
 		if _, ok := vrByKey[vrKey]; ok {
@@ -175,23 +225,43 @@ func (m *defaultResourceManager) findVirtualRouterDependencies(ctx context.Conte
 		}
 		vr, err := m.referencesResolver.ResolveVirtualRouterReference(ctx, vs, vrRef)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to resolve virtualRouterRef")
+			if apierrors.IsNotFound(err) {
+				waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualRouter, vrKey, appmesh.DependencyReasonNotFound))
+				continue
+			}
+			return nil, nil, errors.Wrapf(err, "failed to resolve virtualRouterRef")
 		}
 		vrByKey[vrKey] = vr
 	}
-	return vrByKey, nil
+	return vrByKey, waitingFor, nil
 }
 
-func (m *defaultResourceManager) validateVirtualRouterDependencies(ctx context.Context, ms *appmesh.Mesh, vrByKey map[types.NamespacedName]*appmesh.VirtualRouter) error {
-	for _, vr := range vrByKey {
+// validateVirtualRouterDependencies validate the VirtualRouter dependencies for this VirtualService, and returns the unresolved ones.
+func (m *defaultResourceManager) validateVirtualRouterDependencies(ctx context.Context, ms *appmesh.Mesh, vrByKey map[types.NamespacedName]*appmesh.VirtualRouter) ([]appmesh.DependencyReference, error) {
+	var waitingFor []appmesh.DependencyReference
+	for vrKey, vr := range vrByKey {
 		if vr.Spec.MeshRef == nil || !mesh.IsMeshReferenced(ms, *vr.Spec.MeshRef) {
-			return errors.Errorf("virtualRouter %v didn't belong to mesh %v", k8s.NamespacedName(vr), k8s.NamespacedName(ms))
+			return nil, errors.Errorf("virtualRouter %v didn't belong to mesh %v", k8s.NamespacedName(vr), k8s.NamespacedName(ms))
 		}
 		if !virtualrouter.IsVirtualRouterActive(vr) {
-			return runtime.NewRequeueError(errors.New("virtualRouter is not active yet"))
+			waitingFor = append(waitingFor, references.NewDependencyReference(references.DependencyKindVirtualRouter, vrKey, appmesh.DependencyReasonNotActive))
 		}
 	}
-	return nil
+	return waitingFor, nil
+}
+
+// waitForDependencies records the unresolved dependencies in VirtualService status, and requeues it until they're resolved.
+// the VirtualService is enqueued by watches once its dependencies become ready.
+func (m *defaultResourceManager) waitForDependencies(ctx context.Context, vs *appmesh.VirtualService, waitingFor []appmesh.DependencyReference) error {
+	waitingFor = references.NormalizeDependencyReferences(waitingFor)
+	if !reflect.DeepEqual(vs.Status.WaitingFor, waitingFor) {
+		oldVS := vs.DeepCopy()
+		vs.Status.WaitingFor = waitingFor
+		if err := m.k8sClient.Status().Patch(ctx, vs, client.MergeFrom(oldVS)); err != nil {
+			return err
+		}
+	}
+	return references.NewWaitingForError(waitingFor)
 }
 
 func (m *defaultResourceManager) findSDKVirtualService(ctx context.Context, ms *appmesh.Mesh, vs *appmesh.VirtualService) (*appmeshsdk.VirtualServiceData, error) {
@@ -241,7 +311,7 @@ func (m *defaultResourceManager) updateSDKVirtualService(ctx context.Context, sd
 		return sdkVS, nil
 	}
 	if !m.isSDKVirtualServiceControlledByCRDVirtualService(ctx, sdkVS, vs) {
-		m.log.V(1).Info("skip virtualService update since it's not controlled",
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip virtualService update since it's not controlled",
 			"virtualService", k8s.NamespacedName(vs),
 			"virtualServiceARN", aws.StringValue(sdkVS.Metadata.Arn),
 		)
@@ -249,7 +319,7 @@ func (m *defaultResourceManager) updateSDKVirtualService(ctx context.Context, sd
 	}
 
 	diff := cmp.Diff(desiredSDKVSSpec, actualSDKVSSpec, opts)
-	m.log.V(1).Info("virtualServiceSpec changed",
+	tracing.LoggerFromContext(ctx, m.log).V(1).Info("virtualServiceSpec changed",
 		"virtualService", k8s.NamespacedName(vs),
 		"actualSDKVRSpec", actualSDKVSSpec,
 		"desiredSDKVRSpec", desiredSDKVSSpec,
@@ -269,7 +339,7 @@ func (m *defaultResourceManager) updateSDKVirtualService(ctx context.Context, sd
 
 func (m *defaultResourceManager) deleteSDKVirtualService(ctx context.Context, sdkVS *appmeshsdk.VirtualServiceData, vs *appmesh.VirtualService) error {
 	if !m.isSDKVirtualServiceOwnedByCRDVirtualService(ctx, sdkVS, vs) {
-		m.log.V(1).Info("skip virtualService deletion since its not owned",
+		tracing.LoggerFromContext(ctx, m.log).V(1).Info("skip virtualService deletion since its not owned",
 			"virtualService", k8s.NamespacedName(vs),
 			"virtualServiceARN", aws.StringValue(sdkVS.Metadata.Arn),
 		)
@@ -297,6 +367,10 @@ func (m *defaultResourceManager) updateCRDVirtualService(ctx context.Context, vs
 		vs.Status.ObservedGeneration = aws.Int64(vs.Generation)
 		needsUpdate = true
 	}
+	if len(vs.Status.WaitingFor) != 0 {
+		vs.Status.WaitingFor = nil
+		needsUpdate = true
+	}
 
 	vsActiveConditionStatus := corev1.ConditionFalse
 	if sdkVS.Status != nil && aws.StringValue(sdkVS.Status.Status) == appmeshsdk.VirtualServiceStatusCodeActive {
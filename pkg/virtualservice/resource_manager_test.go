@@ -15,8 +15,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -109,6 +111,50 @@ func Test_defaultResourceManager_updateCRDVirtualService(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "VirtualService needs clear waitingFor",
+			args: args{
+				vs: &appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "vs-1",
+					},
+					Status: appmesh.VirtualServiceStatus{
+						VirtualServiceARN: aws.String("arn-1"),
+						Conditions: []appmesh.VirtualServiceCondition{
+							{
+								Type:   appmesh.VirtualServiceActive,
+								Status: corev1.ConditionTrue,
+							},
+						},
+						WaitingFor: []appmesh.DependencyReference{
+							{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonNotActive},
+						},
+					},
+				},
+				sdkVS: &appmeshsdk.VirtualServiceData{
+					Metadata: &appmeshsdk.ResourceMetadata{
+						Arn: aws.String("arn-1"),
+					},
+					Status: &appmeshsdk.VirtualServiceStatus{
+						Status: aws.String(appmeshsdk.VirtualServiceStatusCodeActive),
+					},
+				},
+			},
+			wantVS: &appmesh.VirtualService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "vs-1",
+				},
+				Status: appmesh.VirtualServiceStatus{
+					VirtualServiceARN: aws.String("arn-1"),
+					Conditions: []appmesh.VirtualServiceCondition{
+						{
+							Type:   appmesh.VirtualServiceActive,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -142,6 +188,86 @@ func Test_defaultResourceManager_updateCRDVirtualService(t *testing.T) {
 	}
 }
 
+func Test_defaultResourceManager_waitForDependencies(t *testing.T) {
+	type args struct {
+		vs         *appmesh.VirtualService
+		waitingFor []appmesh.DependencyReference
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantWaitingFor []appmesh.DependencyReference
+		wantErr        error
+	}{
+		{
+			name: "record unresolved dependencies",
+			args: args{
+				vs: &appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "vs-1",
+					},
+				},
+				waitingFor: []appmesh.DependencyReference{
+					{Kind: "VirtualRouter", Namespace: "ns-1", Name: "vr-1", Reason: appmesh.DependencyReasonNotActive},
+					{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+				},
+			},
+			wantWaitingFor: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+				{Kind: "VirtualRouter", Namespace: "ns-1", Name: "vr-1", Reason: appmesh.DependencyReasonNotActive},
+			},
+			wantErr: errors.New("waiting for Mesh my-mesh (NotActive), VirtualRouter ns-1/vr-1 (NotActive)"),
+		},
+		{
+			name: "replace previously unresolved dependencies",
+			args: args{
+				vs: &appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "vs-1",
+					},
+					Status: appmesh.VirtualServiceStatus{
+						WaitingFor: []appmesh.DependencyReference{
+							{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonNotFound},
+						},
+					},
+				},
+				waitingFor: []appmesh.DependencyReference{
+					{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonNotActive},
+				},
+			},
+			wantWaitingFor: []appmesh.DependencyReference{
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonNotActive},
+			},
+			wantErr: errors.New("waiting for VirtualNode ns-1/vn-1 (NotActive)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			k8sSchema := runtime.NewScheme()
+			clientgoscheme.AddToScheme(k8sSchema)
+			appmesh.AddToScheme(k8sSchema)
+			k8sClient := testclient.NewClientBuilder().WithScheme(k8sSchema).WithStatusSubresource(&appmesh.VirtualService{}).Build()
+			m := &defaultResourceManager{
+				k8sClient: k8sClient,
+				log:       logr.New(&log.NullLogSink{}),
+			}
+
+			err := k8sClient.Create(ctx, tt.args.vs.DeepCopy())
+			assert.NoError(t, err)
+			vs := &appmesh.VirtualService{}
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(tt.args.vs), vs))
+			err = m.waitForDependencies(ctx, vs, tt.args.waitingFor)
+			assert.EqualError(t, err, tt.wantErr.Error())
+			gotVS := &appmesh.VirtualService{}
+			assert.NoError(t, k8sClient.Get(ctx, k8s.NamespacedName(tt.args.vs), gotVS))
+			assert.Equal(t, tt.wantWaitingFor, gotVS.Status.WaitingFor)
+		})
+	}
+}
+
 func Test_defaultResourceManager_isSDKVirtualServiceControlledByCRDVirtualService(t *testing.T) {
 	type fields struct {
 		accountID string
@@ -554,9 +680,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 		mesh *appmesh.Mesh
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr error
+		name string
+		args args
+		want []appmesh.DependencyReference
 	}{
 		{
 			name: "valid mesh",
@@ -577,7 +703,7 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: nil,
+			want: nil,
 		},
 		{
 			name: "inactive mesh",
@@ -598,7 +724,9 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				},
 			},
 			},
-			wantErr: errors.New("mesh is not active yet"),
+			want: []appmesh.DependencyReference{
+				{Kind: "Mesh", Name: "my-mesh", Reason: appmesh.DependencyReasonNotActive},
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -608,12 +736,8 @@ func Test_defaultResourceManager_validateMeshDependency(t *testing.T) {
 				log: logr.New(&log.NullLogSink{}),
 			}
 
-			err := m.validateMeshDependencies(ctx, tt.args.mesh)
-			if tt.wantErr != nil {
-				assert.EqualError(t, err, tt.wantErr.Error())
-			} else {
-				assert.NoError(t, err)
-			}
+			got := m.validateMeshDependencies(ctx, tt.args.mesh)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -626,11 +750,12 @@ func Test_defaultResourceManager_findVirtualNodeDependencies(t *testing.T) {
 		vs *appmesh.VirtualService
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    map[types.NamespacedName]*appmesh.VirtualNode
-		wantErr error
+		name           string
+		fields         fields
+		args           args
+		want           map[types.NamespacedName]*appmesh.VirtualNode
+		wantWaitingFor []appmesh.DependencyReference
+		wantErr        error
 	}{
 		{
 			name: "virtualService with a virtualnode backend",
@@ -701,6 +826,35 @@ func Test_defaultResourceManager_findVirtualNodeDependencies(t *testing.T) {
 			}},
 			wantErr: errors.New("failed to resolve virtualNodeRef: virtual node not found"),
 		},
+		{
+			name: "virtualService with a virtualnode not found",
+			args: args{
+				vs: &appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "vs-1",
+					},
+					Spec: appmesh.VirtualServiceSpec{
+						AWSName: aws.String("app1"),
+						Provider: &appmesh.VirtualServiceProvider{
+							VirtualNode: &appmesh.VirtualNodeServiceProvider{
+								VirtualNodeRef: &appmesh.VirtualNodeReference{
+									Namespace: aws.String("ns-1"),
+									Name:      "vn-1",
+								},
+							},
+						}}}},
+			fields: fields{
+				ResolveVirtualNodeReference: func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualNodeReference) (*appmesh.VirtualNode, error) {
+					return nil, errors.Wrapf(apierrors.NewNotFound(schema.GroupResource{Group: "appmesh.k8s.aws", Resource: "virtualnodes"}, "vn-1"), "unable to fetch virtualNode")
+				},
+			},
+			want: map[types.NamespacedName]*appmesh.VirtualNode{},
+			wantWaitingFor: []appmesh.DependencyReference{
+				{Kind: "VirtualNode", Namespace: "ns-1", Name: "vn-1", Reason: appmesh.DependencyReasonNotFound},
+			},
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -718,12 +872,13 @@ func Test_defaultResourceManager_findVirtualNodeDependencies(t *testing.T) {
 				resolver.EXPECT().ResolveVirtualNodeReference(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveVirtualNodeReference)
 			}
 
-			vnmap, err := m.findVirtualNodeDependencies(ctx, tt.args.vs)
+			vnmap, waitingFor, err := m.findVirtualNodeDependencies(ctx, tt.args.vs)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, vnmap)
+				assert.Equal(t, tt.wantWaitingFor, waitingFor)
 			}
 		})
 	}
@@ -737,11 +892,12 @@ func Test_defaultResourceManager_findVirtualRouterDependencies(t *testing.T) {
 		vs *appmesh.VirtualService
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    map[types.NamespacedName]*appmesh.VirtualRouter
-		wantErr error
+		name           string
+		fields         fields
+		args           args
+		want           map[types.NamespacedName]*appmesh.VirtualRouter
+		wantWaitingFor []appmesh.DependencyReference
+		wantErr        error
 	}{
 		{
 			name: "virtualService with a virtualrouter backend",
@@ -856,6 +1012,35 @@ func Test_defaultResourceManager_findVirtualRouterDependencies(t *testing.T) {
 			}},
 			wantErr: errors.New("failed to resolve virtualRouterRef: virtual router not found"),
 		},
+		{
+			name: "virtualService with a virtualrouter not found",
+			args: args{
+				vs: &appmesh.VirtualService{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "ns-1",
+						Name:      "vs-1",
+					},
+					Spec: appmesh.VirtualServiceSpec{
+						AWSName: aws.String("app1"),
+						Provider: &appmesh.VirtualServiceProvider{
+							VirtualRouter: &appmesh.VirtualRouterServiceProvider{
+								VirtualRouterRef: &appmesh.VirtualRouterReference{
+									Namespace: aws.String("ns-1"),
+									Name:      "vr-1",
+								},
+							},
+						}}}},
+			fields: fields{
+				ResolveVirtualRouterReference: func(ctx context.Context, obj metav1.Object, ref appmesh.VirtualRouterReference) (*appmesh.VirtualRouter, error) {
+					return nil, errors.Wrapf(apierrors.NewNotFound(schema.GroupResource{Group: "appmesh.k8s.aws", Resource: "virtualrouters"}, "vr-1"), "unable to fetch virtualRouter")
+				},
+			},
+			want: map[types.NamespacedName]*appmesh.VirtualRouter{},
+			wantWaitingFor: []appmesh.DependencyReference{
+				{Kind: "VirtualRouter", Namespace: "ns-1", Name: "vr-1", Reason: appmesh.DependencyReasonNotFound},
+			},
+			wantErr: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -873,12 +1058,13 @@ func Test_defaultResourceManager_findVirtualRouterDependencies(t *testing.T) {
 				resolver.EXPECT().ResolveVirtualRouterReference(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.fields.ResolveVirtualRouterReference)
 			}
 
-			vrmap, err := m.findVirtualRouterDependencies(ctx, tt.args.vs)
+			vrmap, waitingFor, err := m.findVirtualRouterDependencies(ctx, tt.args.vs)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.want, vrmap)
+				assert.Equal(t, tt.wantWaitingFor, waitingFor)
 			}
 		})
 	}
@@ -3,6 +3,7 @@ package appmesh
 import (
 	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -15,13 +16,16 @@ import (
 const apiPathValidateAppMeshBackendGroup = "/validate-appmesh-k8s-aws-v1beta2-backendgroup"
 
 // NewBackendGroupValidator returns a validator for BackendGroup.
-func NewBackendGroupValidator() *backendGroupValidator {
-	return &backendGroupValidator{}
+func NewBackendGroupValidator(referenceGrantChecker references.ReferenceGrantChecker) *backendGroupValidator {
+	return &backendGroupValidator{
+		referenceGrantChecker: referenceGrantChecker,
+	}
 }
 
 var _ webhook.Validator = &backendGroupValidator{}
 
 type backendGroupValidator struct {
+	referenceGrantChecker references.ReferenceGrantChecker
 }
 
 func (v *backendGroupValidator) Prototype(req admission.Request) (runtime.Object, error) {
@@ -29,6 +33,10 @@ func (v *backendGroupValidator) Prototype(req admission.Request) (runtime.Object
 }
 
 func (v *backendGroupValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	bg := obj.(*appmesh.BackendGroup)
+	if err := v.checkCrossNamespaceReferences(ctx, bg); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -38,6 +46,9 @@ func (v *backendGroupValidator) ValidateUpdate(ctx context.Context, obj runtime.
 	if err := v.enforceFieldsImmutability(bg, oldVS); err != nil {
 		return err
 	}
+	if err := v.checkCrossNamespaceReferences(ctx, bg); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -57,6 +68,17 @@ func (v *backendGroupValidator) enforceFieldsImmutability(bg *appmesh.BackendGro
 	return nil
 }
 
+// checkCrossNamespaceReferences checks cross-namespace references of bg are permitted by meshReferenceGrants.
+func (v *backendGroupValidator) checkCrossNamespaceReferences(ctx context.Context, bg *appmesh.BackendGroup) error {
+	for _, vsRef := range bg.Spec.VirtualServices {
+		vsKey := references.ObjectKeyForVirtualServiceReference(bg, vsRef)
+		if err := v.referenceGrantChecker.CheckReference(ctx, bg, appmesh.MeshReferenceGrantToKindVirtualService, vsKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // +kubebuilder:webhook:path=/validate-appmesh-k8s-aws-v1beta2-backendgroup,mutating=false,failurePolicy=fail,groups=appmesh.k8s.aws,resources=backendgroups,verbs=create;update,versions=v1beta2,name=vbackendgroup.appmesh.k8s.aws,sideEffects=None,admissionReviewVersions=v1,webhookVersions=v1
 
 func (v *backendGroupValidator) SetupWithManager(mgr ctrl.Manager) {
@@ -1,7 +1,9 @@
 package appmesh
 
 import (
+	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -106,3 +108,45 @@ func Test_backendGroupValidator_enforceFieldsImmutability(t *testing.T) {
 		})
 	}
 }
+
+func Test_backendGroupValidator_checkCrossNamespaceReferences(t *testing.T) {
+	bg := &appmesh.BackendGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "bg-1"},
+		Spec: appmesh.BackendGroupSpec{
+			VirtualServices: []appmesh.VirtualServiceReference{
+				{Name: "vs-1"},
+				{Namespace: aws.String("backend"), Name: "vs-2"},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		grants  []*appmesh.MeshReferenceGrant
+		wantErr error
+	}{
+		{
+			name: "virtualService in other namespace with grant",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindBackendGroup, "frontend", appmesh.MeshReferenceGrantToKindVirtualService),
+			},
+		},
+		{
+			name: "virtualService in other namespace with grant for virtualNodes",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindVirtualNode, "frontend", appmesh.MeshReferenceGrantToKindVirtualService),
+			},
+			wantErr: errors.New("BackendGroup frontend/bg-1 is not permitted to reference VirtualService backend/vs-2, no meshReferenceGrant in namespace backend allows it"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewBackendGroupValidator(newTestReferenceGrantChecker(tt.grants...))
+			err := v.checkCrossNamespaceReferences(context.Background(), bg)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"strings"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/gatewayroute"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -16,13 +19,16 @@ import (
 const apiPathValidateAppMeshGatewayRoute = "/validate-appmesh-k8s-aws-v1beta2-gatewayroute"
 
 // NewGatewayRouteValidator returns a validator for GatewayRoute.
-func NewGatewayRouteValidator() *gatewayRouteValidator {
-	return &gatewayRouteValidator{}
+func NewGatewayRouteValidator(referenceGrantChecker references.ReferenceGrantChecker) *gatewayRouteValidator {
+	return &gatewayRouteValidator{
+		referenceGrantChecker: referenceGrantChecker,
+	}
 }
 
 var _ webhook.Validator = &gatewayRouteValidator{}
 
 type gatewayRouteValidator struct {
+	referenceGrantChecker references.ReferenceGrantChecker
 }
 
 func (v *gatewayRouteValidator) Prototype(req admission.Request) (runtime.Object, error) {
@@ -51,8 +57,14 @@ func validateInternal(spec appmesh.GatewayRouteSpec) error {
 
 func (v *gatewayRouteValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
 	currGR := obj.(*appmesh.GatewayRoute)
+	if err := deletion.ValidatePolicyAnnotation(currGR); err != nil {
+		return err
+	}
 	spec := currGR.Spec
-	return validateInternal(spec)
+	if err := validateInternal(spec); err != nil {
+		return err
+	}
+	return v.checkCrossNamespaceReferences(ctx, currGR)
 }
 
 func getNumberOfRouteTypes(spec appmesh.GatewayRouteSpec) int {
@@ -166,10 +178,16 @@ func getNumOfMetadataMatchFilters(match *appmesh.GRPCRouteMetadataMatchMethod) i
 func (v *gatewayRouteValidator) ValidateUpdate(ctx context.Context, obj runtime.Object, oldObj runtime.Object) error {
 	newGR := obj.(*appmesh.GatewayRoute)
 	oldGR := oldObj.(*appmesh.GatewayRoute)
+	if err := deletion.ValidatePolicyAnnotation(newGR); err != nil {
+		return err
+	}
 	if err := v.enforceFieldsImmutability(newGR, oldGR); err != nil {
 		return err
 	}
-	return validateInternal(newGR.Spec)
+	if err := validateInternal(newGR.Spec); err != nil {
+		return err
+	}
+	return v.checkCrossNamespaceReferences(ctx, newGR)
 }
 
 func validateHTTPRouteSpec(currRoute *appmesh.HTTPGatewayRoute) error {
@@ -339,6 +357,17 @@ func (v *gatewayRouteValidator) enforceFieldsImmutability(newGR *appmesh.Gateway
 	return nil
 }
 
+// checkCrossNamespaceReferences checks cross-namespace references of gr are permitted by meshReferenceGrants.
+func (v *gatewayRouteValidator) checkCrossNamespaceReferences(ctx context.Context, gr *appmesh.GatewayRoute) error {
+	for _, vsRef := range gatewayroute.ExtractVirtualServiceReferences(gr) {
+		vsKey := references.ObjectKeyForVirtualServiceReference(gr, vsRef)
+		if err := v.referenceGrantChecker.CheckReference(ctx, gr, appmesh.MeshReferenceGrantToKindVirtualService, vsKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // +kubebuilder:webhook:path=/validate-appmesh-k8s-aws-v1beta2-gatewayroute,mutating=false,failurePolicy=fail,groups=appmesh.k8s.aws,resources=gatewayroutes,verbs=create;update,versions=v1beta2,name=vgatewayroute.appmesh.k8s.aws,sideEffects=None,admissionReviewVersions=v1,webhookVersions=v1
 
 func (v *gatewayRouteValidator) SetupWithManager(mgr ctrl.Manager) {
@@ -1,6 +1,7 @@
 package appmesh
 
 import (
+	"context"
 	"testing"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
@@ -921,3 +922,47 @@ func Test_gatewayRouteValidator_enforceFieldsImmutability(t *testing.T) {
 		})
 	}
 }
+
+func Test_gatewayRouteValidator_checkCrossNamespaceReferences(t *testing.T) {
+	gr := &appmesh.GatewayRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gateway", Name: "gr-1"},
+		Spec: appmesh.GatewayRouteSpec{
+			HTTPRoute: &appmesh.HTTPGatewayRoute{
+				Action: appmesh.HTTPGatewayRouteAction{
+					Target: appmesh.GatewayRouteTarget{
+						VirtualService: appmesh.GatewayRouteVirtualService{
+							VirtualServiceRef: &appmesh.VirtualServiceReference{Namespace: aws.String("backend"), Name: "vs-1"},
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		grants  []*appmesh.MeshReferenceGrant
+		wantErr error
+	}{
+		{
+			name: "route target in other namespace with grant",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindGatewayRoute, "gateway", appmesh.MeshReferenceGrantToKindVirtualService),
+			},
+		},
+		{
+			name:    "route target in other namespace without grant",
+			wantErr: errors.New("GatewayRoute gateway/gr-1 is not permitted to reference VirtualService backend/vs-1, no meshReferenceGrant in namespace backend allows it"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewGatewayRouteValidator(newTestReferenceGrantChecker(tt.grants...))
+			err := v.checkCrossNamespaceReferences(context.Background(), gr)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
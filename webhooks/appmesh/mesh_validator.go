@@ -3,6 +3,7 @@ package appmesh
 import (
 	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +34,9 @@ func (v *meshValidator) Prototype(req admission.Request) (runtime.Object, error)
 
 func (v *meshValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
 	mesh := obj.(*appmesh.Mesh)
+	if err := deletion.ValidatePolicyAnnotation(mesh); err != nil {
+		return err
+	}
 	if err := v.checkIpPreference(mesh); err != nil {
 		return err
 	}
@@ -42,6 +46,9 @@ func (v *meshValidator) ValidateCreate(ctx context.Context, obj runtime.Object)
 func (v *meshValidator) ValidateUpdate(ctx context.Context, obj runtime.Object, oldObj runtime.Object) error {
 	mesh := obj.(*appmesh.Mesh)
 	oldMesh := oldObj.(*appmesh.Mesh)
+	if err := deletion.ValidatePolicyAnnotation(mesh); err != nil {
+		return err
+	}
 	if err := v.enforceFieldsImmutability(mesh, oldMesh); err != nil {
 		return err
 	}
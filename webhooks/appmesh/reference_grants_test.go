@@ -0,0 +1,36 @@
+package appmesh
+
+import (
+	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestReferenceGrantChecker constructs an enabled ReferenceGrantChecker backed by grants.
+func newTestReferenceGrantChecker(grants ...*appmesh.MeshReferenceGrant) references.ReferenceGrantChecker {
+	k8sSchema := runtime.NewScheme()
+	clientgoscheme.AddToScheme(k8sSchema)
+	appmesh.AddToScheme(k8sSchema)
+	builder := testclient.NewClientBuilder().WithScheme(k8sSchema)
+	for _, grant := range grants {
+		builder = builder.WithObjects(grant.DeepCopy())
+	}
+	return references.NewDefaultReferenceGrantChecker(builder.Build(), references.Config{EnableReferenceGrants: true})
+}
+
+// newTestReferenceGrant constructs a meshReferenceGrant in namespace that permits fromKind in fromNamespace to reference all objects of toKind.
+func newTestReferenceGrant(namespace string, fromKind appmesh.MeshReferenceGrantFromKind, fromNamespace string, toKind appmesh.MeshReferenceGrantToKind) *appmesh.MeshReferenceGrant {
+	return &appmesh.MeshReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "grant",
+		},
+		Spec: appmesh.MeshReferenceGrantSpec{
+			From: []appmesh.MeshReferenceGrantFrom{{Kind: fromKind, Namespace: fromNamespace}},
+			To:   []appmesh.MeshReferenceGrantTo{{Kind: toKind}},
+		},
+	}
+}
@@ -3,6 +3,7 @@ package appmesh
 import (
 	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -30,16 +31,25 @@ func (v *virtualGatewayValidator) Prototype(req admission.Request) (runtime.Obje
 
 func (v *virtualGatewayValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
 	vg := obj.(*appmesh.VirtualGateway)
+	if err := deletion.ValidatePolicyAnnotation(vg); err != nil {
+		return err
+	}
 
 	if err := v.checkForConnectionPoolProtocols(vg); err != nil {
 		return err
 	}
+	if err := v.checkCloudMapServiceDiscovery(vg); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (v *virtualGatewayValidator) ValidateUpdate(ctx context.Context, obj runtime.Object, oldObj runtime.Object) error {
 	vg := obj.(*appmesh.VirtualGateway)
 	oldVGateway := oldObj.(*appmesh.VirtualGateway)
+	if err := deletion.ValidatePolicyAnnotation(vg); err != nil {
+		return err
+	}
 
 	if err := v.enforceFieldsImmutability(vg, oldVGateway); err != nil {
 		return err
@@ -48,6 +58,9 @@ func (v *virtualGatewayValidator) ValidateUpdate(ctx context.Context, obj runtim
 	if err := v.checkForConnectionPoolProtocols(vg); err != nil {
 		return err
 	}
+	if err := v.checkCloudMapServiceDiscovery(vg); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -64,12 +77,37 @@ func (v *virtualGatewayValidator) enforceFieldsImmutability(newVGateway *appmesh
 	if !reflect.DeepEqual(newVGateway.Spec.MeshRef, oldVGateway.Spec.MeshRef) {
 		changedImmutableFields = append(changedImmutableFields, "spec.meshRef")
 	}
+	if oldAWSCloudMap := virtualGatewayCloudMapServiceDiscovery(oldVGateway); oldAWSCloudMap != nil &&
+		!reflect.DeepEqual(immutableCloudMapServiceDiscovery(virtualGatewayCloudMapServiceDiscovery(newVGateway)), immutableCloudMapServiceDiscovery(oldAWSCloudMap)) {
+		changedImmutableFields = append(changedImmutableFields, "spec.serviceDiscovery.awsCloudMap")
+	}
 	if len(changedImmutableFields) != 0 {
 		return errors.Errorf("%s update may not change these fields: %s", "VirtualGateway", strings.Join(changedImmutableFields, ","))
 	}
 	return nil
 }
 
+// checkCloudMapServiceDiscovery checks the cloudMap serviceDiscovery of VirtualGateway.
+func (v *virtualGatewayValidator) checkCloudMapServiceDiscovery(vg *appmesh.VirtualGateway) error {
+	awsCloudMap := virtualGatewayCloudMapServiceDiscovery(vg)
+	if err := validateCloudMapInstanceAttributes("VirtualGateway", vg.Name, awsCloudMap); err != nil {
+		return err
+	}
+	var listenerPort int64
+	if len(vg.Spec.Listeners) != 0 {
+		listenerPort = int64(vg.Spec.Listeners[0].PortMapping.Port)
+	}
+	return validateCloudMapDNSConfig("VirtualGateway", vg.Name, awsCloudMap, listenerPort)
+}
+
+// virtualGatewayCloudMapServiceDiscovery returns the cloudMap serviceDiscovery of VirtualGateway, nil if not specified.
+func virtualGatewayCloudMapServiceDiscovery(vg *appmesh.VirtualGateway) *appmesh.AWSCloudMapServiceDiscovery {
+	if vg.Spec.ServiceDiscovery == nil {
+		return nil
+	}
+	return vg.Spec.ServiceDiscovery.AWSCloudMap
+}
+
 func (v *virtualGatewayValidator) checkForConnectionPoolProtocols(vg *appmesh.VirtualGateway) error {
 	//App Mesh supports one type of connection pool at a time
 	if vg.Spec.Listeners != nil {
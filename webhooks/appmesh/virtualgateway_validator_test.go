@@ -319,3 +319,69 @@ func Test_virtualGatewayValidator_checkForConnectionPoolProtocols(t *testing.T)
 	}
 
 }
+
+func Test_virtualGatewayValidator_checkCloudMapServiceDiscovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		vg      *appmesh.VirtualGateway
+		wantErr error
+	}{
+		{
+			name: "VirtualGateway without serviceDiscovery",
+			vg: &appmesh.VirtualGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-vg"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "VirtualGateway with SRV record type and listeners",
+			vg: &appmesh.VirtualGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-vg"},
+				Spec: appmesh.VirtualGatewaySpec{
+					Listeners: []appmesh.VirtualGatewayListener{
+						{PortMapping: appmesh.VirtualGatewayPortMapping{Port: 8088, Protocol: "http"}},
+					},
+					ServiceDiscovery: &appmesh.VirtualGatewayServiceDiscovery{
+						AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+							NamespaceName: "my-ns",
+							ServiceName:   "my-gw",
+							DNSConfig: &appmesh.AWSCloudMapDNSConfig{
+								RecordTypes: []appmesh.AWSCloudMapDNSRecordType{appmesh.AWSCloudMapDNSRecordTypeSRV},
+							},
+						},
+					},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "VirtualGateway with SRV record type without listeners",
+			vg: &appmesh.VirtualGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-vg"},
+				Spec: appmesh.VirtualGatewaySpec{
+					ServiceDiscovery: &appmesh.VirtualGatewayServiceDiscovery{
+						AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+							NamespaceName: "my-ns",
+							ServiceName:   "my-gw",
+							DNSConfig: &appmesh.AWSCloudMapDNSConfig{
+								RecordTypes: []appmesh.AWSCloudMapDNSRecordType{appmesh.AWSCloudMapDNSRecordTypeSRV},
+							},
+						},
+					},
+				},
+			},
+			wantErr: errors.New("VirtualGateway-my-vg must have a listener port to use SRV record type in spec.serviceDiscovery.awsCloudMap.dnsConfig.recordTypes"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &virtualGatewayValidator{}
+			err := v.checkCloudMapServiceDiscovery(tt.vg)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,7 +3,11 @@ package appmesh
 import (
 	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/backendgroup"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/cloudmap"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualnode"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -16,13 +20,16 @@ import (
 const apiPathValidateAppMeshVirtualNode = "/validate-appmesh-k8s-aws-v1beta2-virtualnode"
 
 // NewVirtualNodeValidator returns a validator for VirtualNode.
-func NewVirtualNodeValidator() *virtualNodeValidator {
-	return &virtualNodeValidator{}
+func NewVirtualNodeValidator(referenceGrantChecker references.ReferenceGrantChecker) *virtualNodeValidator {
+	return &virtualNodeValidator{
+		referenceGrantChecker: referenceGrantChecker,
+	}
 }
 
 var _ webhook.Validator = &virtualNodeValidator{}
 
 type virtualNodeValidator struct {
+	referenceGrantChecker references.ReferenceGrantChecker
 }
 
 func (v *virtualNodeValidator) Prototype(req admission.Request) (runtime.Object, error) {
@@ -31,6 +38,9 @@ func (v *virtualNodeValidator) Prototype(req admission.Request) (runtime.Object,
 
 func (v *virtualNodeValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
 	vn := obj.(*appmesh.VirtualNode)
+	if err := deletion.ValidatePolicyAnnotation(vn); err != nil {
+		return err
+	}
 	if err := v.checkForRequiredFields(vn); err != nil {
 		return err
 	}
@@ -40,12 +50,24 @@ func (v *virtualNodeValidator) ValidateCreate(ctx context.Context, obj runtime.O
 	if err := v.checkForConnectionPoolProtocols(vn); err != nil {
 		return err
 	}
+	if err := v.checkCloudMapInstanceAttributes(vn); err != nil {
+		return err
+	}
+	if err := v.checkCloudMapDNSConfig(vn); err != nil {
+		return err
+	}
+	if err := v.checkCrossNamespaceReferences(ctx, vn); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (v *virtualNodeValidator) ValidateUpdate(ctx context.Context, obj runtime.Object, oldObj runtime.Object) error {
 	vn := obj.(*appmesh.VirtualNode)
 	oldVN := oldObj.(*appmesh.VirtualNode)
+	if err := deletion.ValidatePolicyAnnotation(vn); err != nil {
+		return err
+	}
 	if err := v.checkForRequiredFields(vn); err != nil {
 		return err
 	}
@@ -58,6 +80,15 @@ func (v *virtualNodeValidator) ValidateUpdate(ctx context.Context, obj runtime.O
 	if err := v.checkForConnectionPoolProtocols(vn); err != nil {
 		return err
 	}
+	if err := v.checkCloudMapInstanceAttributes(vn); err != nil {
+		return err
+	}
+	if err := v.checkCloudMapDNSConfig(vn); err != nil {
+		return err
+	}
+	if err := v.checkCrossNamespaceReferences(ctx, vn); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -67,15 +98,24 @@ func (v *virtualNodeValidator) ValidateDelete(ctx context.Context, obj runtime.O
 
 // enforceFieldsImmutability will enforce immutable fields are not changed.
 func (v *virtualNodeValidator) enforceFieldsImmutability(vn *appmesh.VirtualNode, oldVN *appmesh.VirtualNode) error {
+	// meshRef can't be changed by a migration, since VirtualServices and VirtualRouters referencing the virtualNode must be in the same mesh.
+	if !reflect.DeepEqual(vn.Spec.MeshRef, oldVN.Spec.MeshRef) {
+		return errors.Errorf("%s update may not change spec.meshRef from %s to %s, moving to another mesh isn't supported: "+
+			"create a VirtualNode in the target mesh instead, and delete this one once it's no longer used", "VirtualNode", meshNameOf(oldVN.Spec.MeshRef), meshNameOf(vn.Spec.MeshRef))
+	}
 	var changedImmutableFields []string
 	if !reflect.DeepEqual(vn.Spec.AWSName, oldVN.Spec.AWSName) {
-		changedImmutableFields = append(changedImmutableFields, "spec.awsName")
-	}
-	if !reflect.DeepEqual(vn.Spec.MeshRef, oldVN.Spec.MeshRef) {
-		changedImmutableFields = append(changedImmutableFields, "spec.meshRef")
+		// awsName can be changed by a managed migration, except for cloudMap serviceDiscovery which registers instances by awsName.
+		if virtualNodeCloudMapServiceDiscovery(oldVN) != nil {
+			return errors.Errorf("%s update may not change spec.awsName with spec.serviceDiscovery.awsCloudMap, since cloudMap instances are registered by awsName: "+
+				"create a VirtualNode with the new awsName instead, and delete this one once it's no longer used", "VirtualNode")
+		}
+		if migration := oldVN.Status.AWSNameMigration; migration != nil {
+			return errors.Errorf("%s update may not change spec.awsName while migration from %s is in progress", "VirtualNode", migration.PreviousAWSName)
+		}
 	}
 	if oldVN.Spec.ServiceDiscovery != nil && oldVN.Spec.ServiceDiscovery.AWSCloudMap != nil &&
-		!reflect.DeepEqual(vn.Spec.ServiceDiscovery.AWSCloudMap, oldVN.Spec.ServiceDiscovery.AWSCloudMap) {
+		!reflect.DeepEqual(immutableCloudMapServiceDiscovery(virtualNodeCloudMapServiceDiscovery(vn)), immutableCloudMapServiceDiscovery(oldVN.Spec.ServiceDiscovery.AWSCloudMap)) {
 		changedImmutableFields = append(changedImmutableFields, "spec.serviceDiscovery.awsCloudMap")
 	}
 	if len(changedImmutableFields) != 0 {
@@ -84,6 +124,85 @@ func (v *virtualNodeValidator) enforceFieldsImmutability(vn *appmesh.VirtualNode
 	return nil
 }
 
+// meshNameOf returns the mesh name of meshRef for messages, <none> if it's not specified.
+func meshNameOf(meshRef *appmesh.MeshReference) string {
+	if meshRef == nil {
+		return "<none>"
+	}
+	return meshRef.Name
+}
+
+// virtualNodeCloudMapServiceDiscovery returns the cloudMap serviceDiscovery of VirtualNode, nil if not specified.
+func virtualNodeCloudMapServiceDiscovery(vn *appmesh.VirtualNode) *appmesh.AWSCloudMapServiceDiscovery {
+	if vn.Spec.ServiceDiscovery == nil {
+		return nil
+	}
+	return vn.Spec.ServiceDiscovery.AWSCloudMap
+}
+
+// immutableCloudMapServiceDiscovery returns the immutable part of VirtualNode or VirtualGateway's cloudMap serviceDiscovery.
+// instanceAttributes and podLabels only affect attributes of registered instances, and DNS TTL can be updated on existing services, so they can be changed.
+func immutableCloudMapServiceDiscovery(awsCloudMap *appmesh.AWSCloudMapServiceDiscovery) *appmesh.AWSCloudMapServiceDiscovery {
+	if awsCloudMap == nil {
+		return nil
+	}
+	cloudMapConfig := awsCloudMap.DeepCopy()
+	cloudMapConfig.InstanceAttributes = nil
+	cloudMapConfig.PodLabels = nil
+	if cloudMapConfig.DNSConfig != nil {
+		cloudMapConfig.DNSConfig.TTL = nil
+		if cloudMapConfig.DNSConfig.RoutingPolicy == nil && len(cloudMapConfig.DNSConfig.RecordTypes) == 0 {
+			cloudMapConfig.DNSConfig = nil
+		}
+	}
+	return cloudMapConfig
+}
+
+// checkCloudMapDNSConfig checks the DNS configuration of cloudMap serviceDiscovery.
+func (v *virtualNodeValidator) checkCloudMapDNSConfig(vn *appmesh.VirtualNode) error {
+	var listenerPort int64
+	if len(vn.Spec.Listeners) != 0 {
+		listenerPort = int64(vn.Spec.Listeners[0].PortMapping.Port)
+	}
+	return validateCloudMapDNSConfig("VirtualNode", vn.Name, virtualNodeCloudMapServiceDiscovery(vn), listenerPort)
+}
+
+// checkCloudMapInstanceAttributes checks templated instance attributes and pod label selection of cloudMap serviceDiscovery.
+func (v *virtualNodeValidator) checkCloudMapInstanceAttributes(vn *appmesh.VirtualNode) error {
+	return validateCloudMapInstanceAttributes("VirtualNode", vn.Name, virtualNodeCloudMapServiceDiscovery(vn))
+}
+
+// validateCloudMapDNSConfig checks the DNS configuration of cloudMap serviceDiscovery of VirtualNode or VirtualGateway.
+// listenerPort is the port of the first listener registered as instance port of the cloudMap service, zero if there are no listeners.
+func validateCloudMapDNSConfig(kind string, name string, awsCloudMap *appmesh.AWSCloudMapServiceDiscovery, listenerPort int64) error {
+	if awsCloudMap == nil || awsCloudMap.DNSConfig == nil {
+		return nil
+	}
+	recordTypes := make(map[appmesh.AWSCloudMapDNSRecordType]bool)
+	for _, recordType := range awsCloudMap.DNSConfig.RecordTypes {
+		if recordTypes[recordType] {
+			return errors.Errorf("%s-%s has duplicate record type %s in spec.serviceDiscovery.awsCloudMap.dnsConfig.recordTypes", kind, name, recordType)
+		}
+		recordTypes[recordType] = true
+	}
+	// SRV records are built from the instance port, which is only registered with a listener port.
+	if recordTypes[appmesh.AWSCloudMapDNSRecordTypeSRV] && listenerPort == 0 {
+		return errors.Errorf("%s-%s must have a listener port to use SRV record type in spec.serviceDiscovery.awsCloudMap.dnsConfig.recordTypes", kind, name)
+	}
+	return nil
+}
+
+// validateCloudMapInstanceAttributes checks templated instance attributes and pod label selection of cloudMap serviceDiscovery of VirtualNode or VirtualGateway.
+func validateCloudMapInstanceAttributes(kind string, name string, awsCloudMap *appmesh.AWSCloudMapServiceDiscovery) error {
+	if awsCloudMap == nil {
+		return nil
+	}
+	if err := cloudmap.ValidateInstanceAttributes(awsCloudMap); err != nil {
+		return errors.Wrapf(err, "%s-%s has invalid spec.serviceDiscovery.awsCloudMap", kind, name)
+	}
+	return nil
+}
+
 func (v *virtualNodeValidator) checkVirtualNodeBackendsForDuplicates(vn *appmesh.VirtualNode) error {
 	backends := vn.Spec.Backends
 	backendMap := make(map[string]bool, len(backends))
@@ -162,6 +281,27 @@ func (v *virtualNodeValidator) checkListenerMultipleConnectionPools(ln appmesh.L
 	return nil
 }
 
+// checkCrossNamespaceReferences checks cross-namespace references of vn are permitted by meshReferenceGrants.
+func (v *virtualNodeValidator) checkCrossNamespaceReferences(ctx context.Context, vn *appmesh.VirtualNode) error {
+	for _, vsRef := range virtualnode.ExtractVirtualServiceReferences(vn) {
+		vsKey := references.ObjectKeyForVirtualServiceReference(vn, vsRef)
+		if err := v.referenceGrantChecker.CheckReference(ctx, vn, appmesh.MeshReferenceGrantToKindVirtualService, vsKey); err != nil {
+			return err
+		}
+	}
+	for _, bgRef := range vn.Spec.BackendGroups {
+		bgKey := references.ObjectKeyForBackendGroupReference(vn, bgRef)
+		// the wildcard backendGroup expands to virtualServices, which are checked individually during reconcile.
+		if bgKey.Name == backendgroup.WildcardName {
+			continue
+		}
+		if err := v.referenceGrantChecker.CheckReference(ctx, vn, appmesh.MeshReferenceGrantToKindBackendGroup, bgKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // +kubebuilder:webhook:path=/validate-appmesh-k8s-aws-v1beta2-virtualnode,mutating=false,failurePolicy=fail,groups=appmesh.k8s.aws,resources=virtualnodes,verbs=create;update,versions=v1beta2,name=vvirtualnode.appmesh.k8s.aws,sideEffects=None,admissionReviewVersions=v1,webhookVersions=v1
 
 func (v *virtualNodeValidator) SetupWithManager(mgr ctrl.Manager) {
@@ -1,11 +1,13 @@
 package appmesh
 
 import (
+	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
 	"testing"
 )
 
@@ -175,7 +177,7 @@ func Test_virtualNodeValidator_enforceFieldsImmutability(t *testing.T) {
 			wantErr: nil,
 		},
 		{
-			name: "VirtualNode field awsName changed",
+			name: "VirtualNode field awsName changed with awsCloudMap serviceDiscovery",
 			args: args{
 				vn: &appmesh.VirtualNode{
 					ObjectMeta: metav1.ObjectMeta{
@@ -216,7 +218,84 @@ func Test_virtualNodeValidator_enforceFieldsImmutability(t *testing.T) {
 					},
 				},
 			},
-			wantErr: errors.New("VirtualNode update may not change these fields: spec.awsName"),
+			wantErr: errors.New("VirtualNode update may not change spec.awsName with spec.serviceDiscovery.awsCloudMap, since cloudMap instances are registered by awsName: " +
+				"create a VirtualNode with the new awsName instead, and delete this one once it's no longer used"),
+		},
+		{
+			name: "VirtualNode field awsName changed with DNS serviceDiscovery",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "awesome-ns",
+						Name:      "my-vn",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("my-vn_awesome-ns_my-cluster"),
+						MeshRef: &appmesh.MeshReference{
+							Name: "my-mesh",
+							UID:  "408d3036-7dec-11ea-b156-0e30aabe1ca8",
+						},
+						ServiceDiscovery: &appmesh.ServiceDiscovery{
+							DNS: &appmesh.DNSServiceDiscovery{Hostname: "dns-hostname"},
+						},
+					},
+				},
+				oldVN: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "awesome-ns",
+						Name:      "my-vn",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("my-vn_awesome-ns"),
+						MeshRef: &appmesh.MeshReference{
+							Name: "my-mesh",
+							UID:  "408d3036-7dec-11ea-b156-0e30aabe1ca8",
+						},
+						ServiceDiscovery: &appmesh.ServiceDiscovery{
+							DNS: &appmesh.DNSServiceDiscovery{Hostname: "dns-hostname"},
+						},
+					},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "VirtualNode field awsName changed during awsName migration",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "awesome-ns",
+						Name:      "my-vn",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("my-vn_awesome-ns_v3"),
+						MeshRef: &appmesh.MeshReference{
+							Name: "my-mesh",
+							UID:  "408d3036-7dec-11ea-b156-0e30aabe1ca8",
+						},
+					},
+				},
+				oldVN: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "awesome-ns",
+						Name:      "my-vn",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("my-vn_awesome-ns_v2"),
+						MeshRef: &appmesh.MeshReference{
+							Name: "my-mesh",
+							UID:  "408d3036-7dec-11ea-b156-0e30aabe1ca8",
+						},
+					},
+					Status: appmesh.VirtualNodeStatus{
+						AWSNameMigration: &appmesh.VirtualNodeAWSNameMigration{
+							PreviousAWSName: "my-vn_awesome-ns",
+							Phase:           appmesh.VirtualNodeAWSNameMigrationWaitingForPods,
+						},
+					},
+				},
+			},
+			wantErr: errors.New("VirtualNode update may not change spec.awsName while migration from my-vn_awesome-ns is in progress"),
 		},
 		{
 			name: "VirtualNode field meshRef changed",
@@ -260,7 +339,8 @@ func Test_virtualNodeValidator_enforceFieldsImmutability(t *testing.T) {
 					},
 				},
 			},
-			wantErr: errors.New("VirtualNode update may not change these fields: spec.meshRef"),
+			wantErr: errors.New("VirtualNode update may not change spec.meshRef from my-mesh to another-mesh, moving to another mesh isn't supported: " +
+				"create a VirtualNode in the target mesh instead, and delete this one once it's no longer used"),
 		},
 		{
 			name: "VirtualNode field awsCloudMap changed",
@@ -306,6 +386,56 @@ func Test_virtualNodeValidator_enforceFieldsImmutability(t *testing.T) {
 			},
 			wantErr: errors.New("VirtualNode update may not change these fields: spec.serviceDiscovery.awsCloudMap"),
 		},
+		{
+			name: "VirtualNode fields awsCloudMap instanceAttributes and podLabels changed",
+			args: args{
+				vn: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "awesome-ns",
+						Name:      "my-vn",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("my-vn_awesome-ns"),
+						MeshRef: &appmesh.MeshReference{
+							Name: "my-mesh",
+							UID:  "408d3036-7dec-11ea-b156-0e30aabe1ca8",
+						},
+						ServiceDiscovery: &appmesh.ServiceDiscovery{
+							AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+								NamespaceName: "cloudmap-ns",
+								ServiceName:   "cloudmap-svc",
+								InstanceAttributes: []appmesh.AWSCloudMapInstanceAttributeTemplate{
+									{Key: "version", Value: "{{ .Labels.version }}"},
+								},
+								PodLabels: &appmesh.AWSCloudMapPodLabelsSelection{
+									Include: []string{"app"},
+								},
+							},
+						},
+					},
+				},
+				oldVN: &appmesh.VirtualNode{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "awesome-ns",
+						Name:      "my-vn",
+					},
+					Spec: appmesh.VirtualNodeSpec{
+						AWSName: aws.String("my-vn_awesome-ns"),
+						MeshRef: &appmesh.MeshReference{
+							Name: "my-mesh",
+							UID:  "408d3036-7dec-11ea-b156-0e30aabe1ca8",
+						},
+						ServiceDiscovery: &appmesh.ServiceDiscovery{
+							AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+								NamespaceName: "cloudmap-ns",
+								ServiceName:   "cloudmap-svc",
+							},
+						},
+					},
+				},
+			},
+			wantErr: nil,
+		},
 		{
 			name: "VirtualNode fields awsName, meshRef and awsCloudMap changed",
 			args: args{
@@ -348,7 +478,8 @@ func Test_virtualNodeValidator_enforceFieldsImmutability(t *testing.T) {
 					},
 				},
 			},
-			wantErr: errors.New("VirtualNode update may not change these fields: spec.awsName,spec.meshRef,spec.serviceDiscovery.awsCloudMap"),
+			wantErr: errors.New("VirtualNode update may not change spec.meshRef from my-mesh to another-mesh, moving to another mesh isn't supported: " +
+				"create a VirtualNode in the target mesh instead, and delete this one once it's no longer used"),
 		},
 	}
 	for _, tt := range tests {
@@ -890,3 +1021,241 @@ func Test_virtualNodeValidator_checkForConnectionPoolProtocols(t *testing.T) {
 		})
 	}
 }
+
+func Test_virtualNodeValidator_checkCloudMapInstanceAttributes(t *testing.T) {
+	tests := []struct {
+		name           string
+		cloudMapConfig *appmesh.AWSCloudMapServiceDiscovery
+		wantErr        error
+	}{
+		{
+			name: "valid instance attributes and pod labels",
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				NamespaceName: "cloudmap-ns",
+				ServiceName:   "cloudmap-svc",
+				InstanceAttributes: []appmesh.AWSCloudMapInstanceAttributeTemplate{
+					{Key: "version", Value: "{{ .Labels.version }}"},
+					{Key: "zone", Value: "{{ .Node.AvailabilityZone }}"},
+				},
+				PodLabels: &appmesh.AWSCloudMapPodLabelsSelection{
+					Include: []string{"app.kubernetes.io/*"},
+					Exclude: []string{"pod-template-hash"},
+				},
+			},
+		},
+		{
+			name: "invalid instance attribute template",
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				NamespaceName: "cloudmap-ns",
+				ServiceName:   "cloudmap-svc",
+				InstanceAttributes: []appmesh.AWSCloudMapInstanceAttributeTemplate{
+					{Key: "version", Value: "{{ .Labels.version"},
+				},
+			},
+			wantErr: errors.New("VirtualNode-my-vn has invalid spec.serviceDiscovery.awsCloudMap: invalid template for instance attribute version: template: version:1: unclosed action"),
+		},
+		{
+			name: "invalid pod label key pattern",
+			cloudMapConfig: &appmesh.AWSCloudMapServiceDiscovery{
+				NamespaceName: "cloudmap-ns",
+				ServiceName:   "cloudmap-svc",
+				PodLabels: &appmesh.AWSCloudMapPodLabelsSelection{
+					Exclude: []string{"app["},
+				},
+			},
+			wantErr: errors.New("VirtualNode-my-vn has invalid spec.serviceDiscovery.awsCloudMap: invalid pod label key pattern app[: syntax error in pattern"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vn := &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "awesome-ns", Name: "my-vn"},
+				Spec: appmesh.VirtualNodeSpec{
+					ServiceDiscovery: &appmesh.ServiceDiscovery{AWSCloudMap: tt.cloudMapConfig},
+				},
+			}
+			v := &virtualNodeValidator{}
+			err := v.checkCloudMapInstanceAttributes(vn)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_virtualNodeValidator_checkCloudMapDNSConfig(t *testing.T) {
+	srv := appmesh.AWSCloudMapDNSRecordTypeSRV
+	tests := []struct {
+		name      string
+		dnsConfig *appmesh.AWSCloudMapDNSConfig
+		listeners []appmesh.Listener
+		wantErr   error
+	}{
+		{
+			name: "dual-stack and SRV records with listeners",
+			dnsConfig: &appmesh.AWSCloudMapDNSConfig{
+				RecordTypes: []appmesh.AWSCloudMapDNSRecordType{appmesh.AWSCloudMapDNSRecordTypeA, appmesh.AWSCloudMapDNSRecordTypeAAAA, srv},
+			},
+			listeners: []appmesh.Listener{{PortMapping: appmesh.PortMapping{Port: 8080, Protocol: "http"}}},
+		},
+		{
+			name: "duplicate record types",
+			dnsConfig: &appmesh.AWSCloudMapDNSConfig{
+				RecordTypes: []appmesh.AWSCloudMapDNSRecordType{appmesh.AWSCloudMapDNSRecordTypeA, appmesh.AWSCloudMapDNSRecordTypeA},
+			},
+			wantErr: errors.New("VirtualNode-my-vn has duplicate record type A in spec.serviceDiscovery.awsCloudMap.dnsConfig.recordTypes"),
+		},
+		{
+			name: "SRV records without listeners",
+			dnsConfig: &appmesh.AWSCloudMapDNSConfig{
+				RecordTypes: []appmesh.AWSCloudMapDNSRecordType{srv},
+			},
+			wantErr: errors.New("VirtualNode-my-vn must have a listener port to use SRV record type in spec.serviceDiscovery.awsCloudMap.dnsConfig.recordTypes"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vn := &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "awesome-ns", Name: "my-vn"},
+				Spec: appmesh.VirtualNodeSpec{
+					Listeners: tt.listeners,
+					ServiceDiscovery: &appmesh.ServiceDiscovery{AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+						NamespaceName: "cloudmap-ns",
+						ServiceName:   "cloudmap-svc",
+						DNSConfig:     tt.dnsConfig,
+					}},
+				},
+			}
+			v := &virtualNodeValidator{}
+			err := v.checkCloudMapDNSConfig(vn)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_immutableCloudMapServiceDiscovery(t *testing.T) {
+	newVN := func(dnsConfig *appmesh.AWSCloudMapDNSConfig) *appmesh.VirtualNode {
+		return &appmesh.VirtualNode{
+			Spec: appmesh.VirtualNodeSpec{
+				ServiceDiscovery: &appmesh.ServiceDiscovery{AWSCloudMap: &appmesh.AWSCloudMapServiceDiscovery{
+					NamespaceName: "cloudmap-ns",
+					ServiceName:   "cloudmap-svc",
+					DNSConfig:     dnsConfig,
+				}},
+			},
+		}
+	}
+	weighted := appmesh.AWSCloudMapRoutingPolicyWeighted
+	tests := []struct {
+		name      string
+		vn        *appmesh.VirtualNode
+		oldVN     *appmesh.VirtualNode
+		wantEqual bool
+	}{
+		{
+			name:      "TTL added",
+			vn:        newVN(&appmesh.AWSCloudMapDNSConfig{TTL: aws.Int64(60)}),
+			oldVN:     newVN(nil),
+			wantEqual: true,
+		},
+		{
+			name:      "TTL changed",
+			vn:        newVN(&appmesh.AWSCloudMapDNSConfig{TTL: aws.Int64(60), RoutingPolicy: &weighted}),
+			oldVN:     newVN(&appmesh.AWSCloudMapDNSConfig{TTL: aws.Int64(300), RoutingPolicy: &weighted}),
+			wantEqual: true,
+		},
+		{
+			name:      "routing policy changed",
+			vn:        newVN(&appmesh.AWSCloudMapDNSConfig{RoutingPolicy: &weighted}),
+			oldVN:     newVN(nil),
+			wantEqual: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reflect.DeepEqual(immutableCloudMapServiceDiscovery(virtualNodeCloudMapServiceDiscovery(tt.vn)), immutableCloudMapServiceDiscovery(virtualNodeCloudMapServiceDiscovery(tt.oldVN)))
+			assert.Equal(t, tt.wantEqual, got)
+		})
+	}
+}
+
+func Test_virtualNodeValidator_checkCrossNamespaceReferences(t *testing.T) {
+	vsBackend := func(namespace string) appmesh.Backend {
+		return appmesh.Backend{
+			VirtualService: appmesh.VirtualServiceBackend{
+				VirtualServiceRef: &appmesh.VirtualServiceReference{Namespace: aws.String(namespace), Name: "vs-1"},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		grants  []*appmesh.MeshReferenceGrant
+		vn      *appmesh.VirtualNode
+		wantErr error
+	}{
+		{
+			name: "backend in same namespace",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "vn-1"},
+				Spec:       appmesh.VirtualNodeSpec{Backends: []appmesh.Backend{vsBackend("frontend")}},
+			},
+		},
+		{
+			name: "backend in other namespace with grant",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindVirtualNode, "frontend", appmesh.MeshReferenceGrantToKindVirtualService),
+			},
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "vn-1"},
+				Spec:       appmesh.VirtualNodeSpec{Backends: []appmesh.Backend{vsBackend("backend")}},
+			},
+		},
+		{
+			name: "backend in other namespace without grant",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindVirtualRouter, "frontend", appmesh.MeshReferenceGrantToKindVirtualService),
+			},
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "vn-1"},
+				Spec:       appmesh.VirtualNodeSpec{Backends: []appmesh.Backend{vsBackend("backend")}},
+			},
+			wantErr: errors.New("VirtualNode frontend/vn-1 is not permitted to reference VirtualService backend/vs-1, no meshReferenceGrant in namespace backend allows it"),
+		},
+		{
+			name: "backendGroup in other namespace without grant",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "vn-1"},
+				Spec: appmesh.VirtualNodeSpec{
+					BackendGroups: []appmesh.BackendGroupReference{{Namespace: aws.String("backend"), Name: "bg-1"}},
+				},
+			},
+			wantErr: errors.New("VirtualNode frontend/vn-1 is not permitted to reference BackendGroup backend/bg-1, no meshReferenceGrant in namespace backend allows it"),
+		},
+		{
+			name: "wildcard backendGroup in other namespace is checked during reconcile",
+			vn: &appmesh.VirtualNode{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "vn-1"},
+				Spec: appmesh.VirtualNodeSpec{
+					BackendGroups: []appmesh.BackendGroupReference{{Namespace: aws.String("backend"), Name: "*"}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVirtualNodeValidator(newTestReferenceGrantChecker(tt.grants...))
+			err := v.checkCrossNamespaceReferences(context.Background(), tt.vn)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"strings"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualrouter"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -16,13 +19,16 @@ import (
 const apiPathValidateAppMeshVirtualRouter = "/validate-appmesh-k8s-aws-v1beta2-virtualrouter"
 
 // NewVirtualRouterValidator returns a validator for VirtualRouter.
-func NewVirtualRouterValidator() *virtualRouterValidator {
-	return &virtualRouterValidator{}
+func NewVirtualRouterValidator(referenceGrantChecker references.ReferenceGrantChecker) *virtualRouterValidator {
+	return &virtualRouterValidator{
+		referenceGrantChecker: referenceGrantChecker,
+	}
 }
 
 var _ webhook.Validator = &virtualRouterValidator{}
 
 type virtualRouterValidator struct {
+	referenceGrantChecker references.ReferenceGrantChecker
 }
 
 func (v *virtualRouterValidator) Prototype(req admission.Request) (runtime.Object, error) {
@@ -31,6 +37,9 @@ func (v *virtualRouterValidator) Prototype(req admission.Request) (runtime.Objec
 
 func (v *virtualRouterValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
 	vr := obj.(*appmesh.VirtualRouter)
+	if err := deletion.ValidatePolicyAnnotation(vr); err != nil {
+		return err
+	}
 	if err := v.checkForDuplicateRouteEntries(vr); err != nil {
 		return err
 	}
@@ -39,6 +48,9 @@ func (v *virtualRouterValidator) ValidateCreate(ctx context.Context, obj runtime
 			return err
 		}
 	}
+	if err := v.checkCrossNamespaceReferences(ctx, vr); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -84,6 +96,9 @@ func validatePathForVirtualRoute(path *appmesh.HTTPPathMatch) error {
 func (v *virtualRouterValidator) ValidateUpdate(ctx context.Context, obj runtime.Object, oldObj runtime.Object) error {
 	vr := obj.(*appmesh.VirtualRouter)
 	oldVR := oldObj.(*appmesh.VirtualRouter)
+	if err := deletion.ValidatePolicyAnnotation(vr); err != nil {
+		return err
+	}
 	if err := v.enforceFieldsImmutability(vr, oldVR); err != nil {
 		return err
 	}
@@ -95,6 +110,9 @@ func (v *virtualRouterValidator) ValidateUpdate(ctx context.Context, obj runtime
 			return err
 		}
 	}
+	if err := v.checkCrossNamespaceReferences(ctx, vr); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -130,6 +148,17 @@ func (v *virtualRouterValidator) checkForDuplicateRouteEntries(vr *appmesh.Virtu
 	return nil
 }
 
+// checkCrossNamespaceReferences checks cross-namespace references of vr are permitted by meshReferenceGrants.
+func (v *virtualRouterValidator) checkCrossNamespaceReferences(ctx context.Context, vr *appmesh.VirtualRouter) error {
+	for _, vnRef := range virtualrouter.ExtractVirtualNodeReferences(vr) {
+		vnKey := references.ObjectKeyForVirtualNodeReference(vr, vnRef)
+		if err := v.referenceGrantChecker.CheckReference(ctx, vr, appmesh.MeshReferenceGrantToKindVirtualNode, vnKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // +kubebuilder:webhook:path=/validate-appmesh-k8s-aws-v1beta2-virtualrouter,mutating=false,failurePolicy=fail,groups=appmesh.k8s.aws,resources=virtualrouters,verbs=create;update,versions=v1beta2,name=vvirtualrouter.appmesh.k8s.aws,sideEffects=None,admissionReviewVersions=v1,webhookVersions=v1
 
 func (v *virtualRouterValidator) SetupWithManager(mgr ctrl.Manager) {
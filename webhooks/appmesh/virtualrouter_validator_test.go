@@ -1,6 +1,7 @@
 package appmesh
 
 import (
+	"context"
 	"testing"
 
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
@@ -360,3 +361,53 @@ func Test_virtualRouterValidator_validateRoute(t *testing.T) {
 		})
 	}
 }
+
+func Test_virtualRouterValidator_checkCrossNamespaceReferences(t *testing.T) {
+	vr := &appmesh.VirtualRouter{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "vr-1"},
+		Spec: appmesh.VirtualRouterSpec{
+			Routes: []appmesh.Route{
+				{
+					Name: "route",
+					HTTPRoute: &appmesh.HTTPRoute{
+						Action: appmesh.HTTPRouteAction{
+							WeightedTargets: []appmesh.WeightedTarget{
+								{VirtualNodeRef: &appmesh.VirtualNodeReference{Namespace: aws.String("backend"), Name: "vn-1"}, Weight: 100},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		grants  []*appmesh.MeshReferenceGrant
+		wantErr error
+	}{
+		{
+			name: "route target in other namespace with grant",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindVirtualRouter, "frontend", appmesh.MeshReferenceGrantToKindVirtualNode),
+			},
+		},
+		{
+			name: "route target in other namespace with grant from other namespace",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindVirtualRouter, "checkout", appmesh.MeshReferenceGrantToKindVirtualNode),
+			},
+			wantErr: errors.New("VirtualRouter frontend/vr-1 is not permitted to reference VirtualNode backend/vn-1, no meshReferenceGrant in namespace backend allows it"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVirtualRouterValidator(newTestReferenceGrantChecker(tt.grants...))
+			err := v.checkCrossNamespaceReferences(context.Background(), vr)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package appmesh
 import (
 	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/deletion"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/references"
+	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/virtualservice"
 	"github.com/aws/aws-app-mesh-controller-for-k8s/pkg/webhook"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -15,13 +18,16 @@ import (
 const apiPathValidateAppMeshVirtualService = "/validate-appmesh-k8s-aws-v1beta2-virtualservice"
 
 // NewVirtualServiceValidator returns a validator for VirtualService.
-func NewVirtualServiceValidator() *virtualServiceValidator {
-	return &virtualServiceValidator{}
+func NewVirtualServiceValidator(referenceGrantChecker references.ReferenceGrantChecker) *virtualServiceValidator {
+	return &virtualServiceValidator{
+		referenceGrantChecker: referenceGrantChecker,
+	}
 }
 
 var _ webhook.Validator = &virtualServiceValidator{}
 
 type virtualServiceValidator struct {
+	referenceGrantChecker references.ReferenceGrantChecker
 }
 
 func (v *virtualServiceValidator) Prototype(req admission.Request) (runtime.Object, error) {
@@ -29,15 +35,28 @@ func (v *virtualServiceValidator) Prototype(req admission.Request) (runtime.Obje
 }
 
 func (v *virtualServiceValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	vs := obj.(*appmesh.VirtualService)
+	if err := deletion.ValidatePolicyAnnotation(vs); err != nil {
+		return err
+	}
+	if err := v.checkCrossNamespaceReferences(ctx, vs); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (v *virtualServiceValidator) ValidateUpdate(ctx context.Context, obj runtime.Object, oldObj runtime.Object) error {
 	vs := obj.(*appmesh.VirtualService)
 	oldVS := oldObj.(*appmesh.VirtualService)
+	if err := deletion.ValidatePolicyAnnotation(vs); err != nil {
+		return err
+	}
 	if err := v.enforceFieldsImmutability(vs, oldVS); err != nil {
 		return err
 	}
+	if err := v.checkCrossNamespaceReferences(ctx, vs); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -60,6 +79,23 @@ func (v *virtualServiceValidator) enforceFieldsImmutability(vs *appmesh.VirtualS
 	return nil
 }
 
+// checkCrossNamespaceReferences checks cross-namespace references of vs are permitted by meshReferenceGrants.
+func (v *virtualServiceValidator) checkCrossNamespaceReferences(ctx context.Context, vs *appmesh.VirtualService) error {
+	for _, vnRef := range virtualservice.ExtractVirtualNodeReferences(vs) {
+		vnKey := references.ObjectKeyForVirtualNodeReference(vs, vnRef)
+		if err := v.referenceGrantChecker.CheckReference(ctx, vs, appmesh.MeshReferenceGrantToKindVirtualNode, vnKey); err != nil {
+			return err
+		}
+	}
+	for _, vrRef := range virtualservice.ExtractVirtualRouterReferences(vs) {
+		vrKey := references.ObjectKeyForVirtualRouterReference(vs, vrRef)
+		if err := v.referenceGrantChecker.CheckReference(ctx, vs, appmesh.MeshReferenceGrantToKindVirtualRouter, vrKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // +kubebuilder:webhook:path=/validate-appmesh-k8s-aws-v1beta2-virtualservice,mutating=false,failurePolicy=fail,groups=appmesh.k8s.aws,resources=virtualservices,verbs=create;update,versions=v1beta2,name=vvirtualservice.appmesh.k8s.aws,sideEffects=None,admissionReviewVersions=v1,webhookVersions=v1
 
 func (v *virtualServiceValidator) SetupWithManager(mgr ctrl.Manager) {
@@ -1,6 +1,7 @@
 package appmesh
 
 import (
+	"context"
 	appmesh "github.com/aws/aws-app-mesh-controller-for-k8s/apis/appmesh/v1beta2"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/pkg/errors"
@@ -160,3 +161,67 @@ func Test_virtualServiceValidator_enforceFieldsImmutability(t *testing.T) {
 		})
 	}
 }
+
+func Test_virtualServiceValidator_checkCrossNamespaceReferences(t *testing.T) {
+	vsWithProvider := func(provider *appmesh.VirtualServiceProvider) *appmesh.VirtualService {
+		return &appmesh.VirtualService{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "frontend", Name: "vs-1"},
+			Spec:       appmesh.VirtualServiceSpec{Provider: provider},
+		}
+	}
+	vnProvider := &appmesh.VirtualServiceProvider{
+		VirtualNode: &appmesh.VirtualNodeServiceProvider{
+			VirtualNodeRef: &appmesh.VirtualNodeReference{Namespace: aws.String("backend"), Name: "vn-1"},
+		},
+	}
+	vrProvider := &appmesh.VirtualServiceProvider{
+		VirtualRouter: &appmesh.VirtualRouterServiceProvider{
+			VirtualRouterRef: &appmesh.VirtualRouterReference{Namespace: aws.String("backend"), Name: "vr-1"},
+		},
+	}
+	tests := []struct {
+		name    string
+		grants  []*appmesh.MeshReferenceGrant
+		vs      *appmesh.VirtualService
+		wantErr error
+	}{
+		{
+			name: "virtualNode provider in other namespace with grant",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindVirtualService, "frontend", appmesh.MeshReferenceGrantToKindVirtualNode),
+			},
+			vs: vsWithProvider(vnProvider),
+		},
+		{
+			name:    "virtualNode provider in other namespace without grant",
+			vs:      vsWithProvider(vnProvider),
+			wantErr: errors.New("VirtualService frontend/vs-1 is not permitted to reference VirtualNode backend/vn-1, no meshReferenceGrant in namespace backend allows it"),
+		},
+		{
+			name: "virtualRouter provider in other namespace with grant",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindVirtualService, "frontend", appmesh.MeshReferenceGrantToKindVirtualRouter),
+			},
+			vs: vsWithProvider(vrProvider),
+		},
+		{
+			name: "virtualRouter provider in other namespace with grant to other kind",
+			grants: []*appmesh.MeshReferenceGrant{
+				newTestReferenceGrant("backend", appmesh.MeshReferenceGrantFromKindVirtualService, "frontend", appmesh.MeshReferenceGrantToKindVirtualNode),
+			},
+			vs:      vsWithProvider(vrProvider),
+			wantErr: errors.New("VirtualService frontend/vs-1 is not permitted to reference VirtualRouter backend/vr-1, no meshReferenceGrant in namespace backend allows it"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVirtualServiceValidator(newTestReferenceGrantChecker(tt.grants...))
+			err := v.checkCrossNamespaceReferences(context.Background(), tt.vs)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}